@@ -0,0 +1,241 @@
+package main
+
+import (
+	"fmt"
+	"log/slog"
+	"os"
+	"os/user"
+	"strings"
+	"time"
+
+	"github.com/spf13/cobra"
+
+	"github.com/BadgerOps/airgap/internal/audit"
+	"github.com/BadgerOps/airgap/internal/store"
+)
+
+var (
+	auditActor   string
+	auditAction  string
+	auditTarget  string
+	auditOutcome string
+	auditSince   string
+	auditUntil   string
+	auditLimit   int
+	auditVerbose bool
+)
+
+func newAuditCmd() *cobra.Command {
+	cmd := &cobra.Command{
+		Use:   "audit",
+		Short: "Show the audit log of mutating actions",
+		Long: `List audit events, newest first. Every provider config change, sync, scan,
+failure resolution, import, export, registry push and database restore made
+through the API, web UI or CLI is recorded with the acting user, source IP,
+outcome and, for provider changes, the config before and after with
+credentials redacted.
+
+--action matches exactly, or as a prefix when it ends in "." (provider.).
+--since and --until take RFC 3339 times or durations back from now (24h).`,
+		Example: `  airgap audit
+  airgap audit --action provider. --since 168h
+  airgap audit --actor alice --outcome failure -v
+  airgap audit verify`,
+		Args:        cobra.NoArgs,
+		Annotations: map[string]string{outputAnnotation: structuredFormats},
+		RunE:        auditRun,
+	}
+
+	cmd.Flags().StringVar(&auditActor, "actor", "", "only show events by this user")
+	cmd.Flags().StringVar(&auditAction, "action", "", "only show this action, or actions under a prefix ending in \".\"")
+	cmd.Flags().StringVar(&auditTarget, "target", "", "only show events on this target")
+	cmd.Flags().StringVar(&auditOutcome, "outcome", "", "only show this outcome (success, failure)")
+	cmd.Flags().StringVar(&auditSince, "since", "", "only show events at or after this time or duration ago")
+	cmd.Flags().StringVar(&auditUntil, "until", "", "only show events before this time or duration ago")
+	cmd.Flags().IntVar(&auditLimit, "limit", 50, "maximum number of events (0 for all)")
+	cmd.Flags().BoolVarP(&auditVerbose, "verbose", "v", false, "show config before and after each change")
+
+	cmd.AddCommand(newAuditVerifyCmd())
+	return cmd
+}
+
+func newAuditVerifyCmd() *cobra.Command {
+	return &cobra.Command{
+		Use:   "verify",
+		Short: "Check the audit log's hash chain for tampering",
+		Long: `Recompute the hash of every event recorded with audit.hash_chain enabled and
+check each links to the event before it. Edited or deleted events break the
+chain and are reported.`,
+		Args:        cobra.NoArgs,
+		Annotations: map[string]string{outputAnnotation: structuredFormats},
+		RunE:        auditVerifyRun,
+	}
+}
+
+func auditRun(cmd *cobra.Command, args []string) error {
+	if globalStore == nil {
+		return fmt.Errorf("store not initialized")
+	}
+
+	f := store.AuditFilter{
+		Actor:   auditActor,
+		Action:  auditAction,
+		Target:  auditTarget,
+		Outcome: auditOutcome,
+		Limit:   auditLimit,
+	}
+	var err error
+	if f.Since, err = parseAuditTime(auditSince); err != nil {
+		return fmt.Errorf("invalid --since: %w", err)
+	}
+	if f.Until, err = parseAuditTime(auditUntil); err != nil {
+		return fmt.Errorf("invalid --until: %w", err)
+	}
+
+	events, err := globalStore.ListAuditEvents(f)
+	if err != nil {
+		return err
+	}
+	if structuredOutput() {
+		doc := &auditDocument{Version: outputVersion, Events: make([]auditEventEntry, 0, len(events))}
+		for _, e := range events {
+			doc.Events = append(doc.Events, auditEventEntry(e))
+		}
+		return writeStructured(outputFormat, doc)
+	}
+	if len(events) == 0 {
+		fmt.Println("No audit events.")
+		return nil
+	}
+
+	fmt.Printf("%-20s %-12s %-4s %-18s %-24s %-8s %s\n", "Time", "Actor", "Via", "Action", "Target", "Outcome", "Detail")
+	fmt.Println(strings.Repeat("-", 120))
+	for _, e := range events {
+		fmt.Printf("%-20s %-12s %-4s %-18s %-24s %-8s %s\n",
+			e.Time.Local().Format("2006-01-02 15:04:05"), e.Actor, e.Source, e.Action,
+			valueOr(e.Target, "-"), e.Outcome, e.Detail)
+		if auditVerbose {
+			if e.SourceIP != "" {
+				fmt.Printf("    source ip: %s\n", e.SourceIP)
+			}
+			if e.Before != "" {
+				fmt.Printf("    before:    %s\n", e.Before)
+			}
+			if e.After != "" {
+				fmt.Printf("    after:     %s\n", e.After)
+			}
+		}
+	}
+	return nil
+}
+
+func auditVerifyRun(cmd *cobra.Command, args []string) error {
+	if globalStore == nil {
+		return fmt.Errorf("store not initialized")
+	}
+
+	result, err := audit.Verify(globalStore)
+	if err != nil {
+		return err
+	}
+	if structuredOutput() {
+		if err := writeStructured(outputFormat, &auditVerifyDocument{Version: outputVersion, OK: result.OK(), VerifyResult: result}); err != nil {
+			return err
+		}
+		if !result.OK() {
+			return fmt.Errorf("audit log failed verification with %d problem(s)", len(result.Problems))
+		}
+		return nil
+	}
+	fmt.Printf("Events:    %d\n", result.Events)
+	fmt.Printf("Chained:   %d\n", result.Chained)
+	fmt.Printf("Unchained: %d\n", result.Unchained)
+	if !result.OK() {
+		for _, p := range result.Problems {
+			fmt.Printf("  - %s\n", p)
+		}
+		return fmt.Errorf("audit log failed verification with %d problem(s)", len(result.Problems))
+	}
+	fmt.Println("Audit log hash chain verified.")
+	return nil
+}
+
+// auditDocument is the --output json|yaml form of airgap audit.
+type auditDocument struct {
+	Version int               `json:"version"`
+	Events  []auditEventEntry `json:"events"`
+}
+
+// auditEventEntry is an audit event in the shape the API returns it.
+type auditEventEntry struct {
+	ID       int64     `json:"id"`
+	Time     time.Time `json:"time"`
+	Actor    string    `json:"actor"`
+	SourceIP string    `json:"source_ip,omitempty"`
+	Source   string    `json:"source"`
+	Action   string    `json:"action"`
+	Target   string    `json:"target,omitempty"`
+	Before   string    `json:"before,omitempty"`
+	After    string    `json:"after,omitempty"`
+	Outcome  string    `json:"outcome"`
+	Detail   string    `json:"detail,omitempty"`
+	PrevHash string    `json:"prev_hash,omitempty"`
+	Hash     string    `json:"hash,omitempty"`
+}
+
+// auditVerifyDocument is the --output json|yaml form of airgap audit verify.
+type auditVerifyDocument struct {
+	Version int  `json:"version"`
+	OK      bool `json:"ok"`
+	*audit.VerifyResult
+}
+
+// parseAuditTime parses an RFC 3339 time or a duration before now. An
+// empty value is the zero time.
+func parseAuditTime(v string) (time.Time, error) {
+	if v == "" {
+		return time.Time{}, nil
+	}
+	if d, err := time.ParseDuration(v); err == nil {
+		return time.Now().Add(-d), nil
+	}
+	return time.Parse(time.RFC3339, v)
+}
+
+// recordAudit records a CLI action on target in the audit log. detail
+// describes a successful action; a failure records the error instead.
+func recordAudit(action, target, detail string, err error) {
+	ev := cliAuditEvent(action, target)
+	ev.Outcome, ev.Detail = audit.Outcome(err)
+	if err == nil {
+		ev.Detail = detail
+	}
+	cliAuditRecorder().Record(ev)
+}
+
+// cliAuditEvent starts an audit event for action on target by the user
+// running the CLI.
+func cliAuditEvent(action, target string) store.AuditEvent {
+	return store.AuditEvent{
+		Actor:  cliActor(),
+		Source: audit.SourceCLI,
+		Action: action,
+		Target: target,
+	}
+}
+
+func cliAuditRecorder() *audit.Recorder {
+	hashChain := globalCfg != nil && globalCfg.Audit.HashChain
+	return audit.NewRecorder(globalStore, hashChain, slog.Default())
+}
+
+// cliActor names the OS user running the CLI.
+func cliActor() string {
+	if u, err := user.Current(); err == nil && u.Username != "" {
+		return u.Username
+	}
+	if name := os.Getenv("USER"); name != "" {
+		return name
+	}
+	return "unknown"
+}
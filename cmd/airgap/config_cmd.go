@@ -3,6 +3,12 @@ package main
 import (
 	"fmt"
 	"log/slog"
+	"os"
+	"strconv"
+	"strings"
+
+	"github.com/BadgerOps/airgap/internal/audit"
+	"github.com/BadgerOps/airgap/internal/config"
 
 	"github.com/spf13/cobra"
 	"gopkg.in/yaml.v3"
@@ -66,16 +72,22 @@ func newConfigSetCmd() *cobra.Command {
 	cmd := &cobra.Command{
 		Use:   "set KEY VALUE",
 		Short: "Set a configuration value",
-		Long: `Set a configuration value using dot-notation for nested keys.
-Changes are written back to the config file.
-
-Examples:
-  server.listen 127.0.0.1:9000
-  server.data_dir /var/lib/airgap
-  export.split_size 10GB
-  export.compression gzip`,
+		Long: `Set a configuration value using dot-notation for nested keys and [N] for
+list items. Keys and values are checked against the typed configuration, and
+unknown keys are rejected with a suggestion.
+
+Global sections (server, export, schedule, database, audit, boot) are written
+back to the config file, keeping its comments and key order. Keys under
+providers.<name> update the provider config stored in the database and save a
+new revision. Restart "airgap serve" to apply file changes.
+
+Lists of strings take a comma-separated value; other lists and sections take
+a YAML value such as '[{name: a, base_url: https://...}]'. Use index N equal
+to the list length to append an item.`,
 		Example: `  airgap config set server.listen 127.0.0.1:9000
-  airgap config set export.split_size 10GB`,
+  airgap config set export.split_size 10GB
+  airgap config set providers.epel.repos[0].base_url https://mirror.example.com/epel/9/
+  airgap config set providers.ocp_binaries.versions 4.17.2,4.18.1`,
 		Args: cobra.ExactArgs(2),
 		RunE: configSetRun,
 	}
@@ -84,18 +96,110 @@ Examples:
 }
 
 func configSetRun(cmd *cobra.Command, args []string) error {
-	log := slog.Default()
-
 	if globalCfg == nil {
 		return fmt.Errorf("config not loaded")
 	}
 
 	key := args[0]
 	value := args[1]
+	if key == "providers" || strings.HasPrefix(key, "providers.") {
+		return configSetProvider(key, value)
+	}
+	return configSetFile(key, value)
+}
+
+// configSetFile writes key to the loaded config file.
+func configSetFile(key, value string) error {
+	if cfgPath == "" {
+		return fmt.Errorf("no config file loaded; pass --config to choose one")
+	}
+	info, err := os.Stat(cfgPath)
+	if err != nil {
+		return fmt.Errorf("reading config file: %w", err)
+	}
+	data, err := os.ReadFile(cfgPath)
+	if err != nil {
+		return fmt.Errorf("reading config file: %w", err)
+	}
+
+	updated, err := config.SetValue(data, key, value)
+	if err != nil {
+		return err
+	}
+	err = os.WriteFile(cfgPath, updated, info.Mode().Perm())
+	recordAudit("config.set", key, fmt.Sprintf("%s = %s in %s", key, displayConfigValue(key, value), cfgPath), err)
+	if err != nil {
+		return fmt.Errorf("writing config file: %w", err)
+	}
+
+	slog.Default().Info("set configuration", "key", key, "path", cfgPath)
+	fmt.Printf("Set %s = %s in %s\n", key, displayConfigValue(key, value), cfgPath)
+	fmt.Println("Restart airgap serve to apply the change.")
+	return nil
+}
+
+// configSetProvider updates a key under providers.<name> in the provider
+// config stored in the database.
+func configSetProvider(key, value string) error {
+	parts := strings.SplitN(key, ".", 3)
+	if len(parts) < 3 || parts[1] == "" || parts[2] == "" {
+		return fmt.Errorf("provider keys take the form providers.<name>.<key>")
+	}
+	name, subKey := parts[1], parts[2]
 
-	log.Info("set configuration", "key", key, "value", value)
+	if globalStore == nil {
+		return fmt.Errorf("store not initialized")
+	}
+	pc, err := globalStore.GetProviderConfig(name)
+	if err != nil {
+		configs, _ := globalStore.ListProviderConfigs()
+		names := make([]string, 0, len(configs))
+		for _, c := range configs {
+			names = append(names, c.Name)
+		}
+		if s := config.Suggest(name, names); s != "" {
+			return fmt.Errorf("provider %q not found (did you mean %q?)", name, s)
+		}
+		return fmt.Errorf("provider %q not found", name)
+	}
+
+	updated, err := config.SetProviderValue(pc.Type, pc.ConfigJSON, subKey, value)
+	if err != nil {
+		return fmt.Errorf("providers.%s: %w", name, err)
+	}
 
-	fmt.Printf("STUB: Would set config key '%s' to '%s'\n", key, value)
+	ev := cliAuditEvent("provider.update", name)
+	ev.Before = audit.ProviderState(pc)
+	pc.ConfigJSON = updated
+	if subKey == "enabled" {
+		pc.Enabled, _ = strconv.ParseBool(value)
+	}
+	err = globalStore.UpdateProviderConfig(pc)
+	ev.Outcome, ev.Detail = audit.Outcome(err)
+	if err == nil {
+		ev.After, ev.Detail = audit.ProviderState(pc), "config set "+subKey
+	}
+	cliAuditRecorder().Record(ev)
+	if err != nil {
+		return fmt.Errorf("updating provider %s: %w", name, err)
+	}
+	if _, err := globalStore.AddProviderConfigRevision(name, cliActor(), "config set "+subKey); err != nil {
+		return fmt.Errorf("recording revision of %s: %w", name, err)
+	}
 
+	slog.Default().Info("set provider configuration", "provider", name, "key", subKey)
+	fmt.Printf("Set %s = %s\n", key, displayConfigValue(subKey, value))
+	fmt.Println("A running airgap serve picks up the change on its next provider reload or restart.")
 	return nil
 }
+
+// displayConfigValue hides values of credential keys.
+func displayConfigValue(key, value string) string {
+	if i := strings.LastIndex(key, "."); i >= 0 {
+		key = key[i+1:]
+	}
+	if config.IsSensitiveKey(key) {
+		return config.RedactedValue
+	}
+	return value
+}
@@ -0,0 +1,61 @@
+package main
+
+import (
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+
+	"github.com/BadgerOps/airgap/internal/config"
+)
+
+func TestConfigSetRun(t *testing.T) {
+	st := newTestStore(t)
+	mustCreateProviderConfig(t, st, "epel-9", "epel", true)
+
+	path := filepath.Join(t.TempDir(), "airgap.yaml")
+	if err := os.WriteFile(path, []byte("# comment kept\nserver:\n  listen: \"0.0.0.0:8080\"\n"), 0o600); err != nil {
+		t.Fatal(err)
+	}
+
+	origStore, origCfg, origPath := globalStore, globalCfg, cfgPath
+	globalStore, globalCfg, cfgPath = st, config.DefaultConfig(), path
+	t.Cleanup(func() {
+		globalStore, globalCfg, cfgPath = origStore, origCfg, origPath
+	})
+
+	captureStdout(t, func() {
+		if err := configSetRun(nil, []string{"server.listen", "127.0.0.1:9000"}); err != nil {
+			t.Fatalf("config set server.listen: %v", err)
+		}
+		if err := configSetRun(nil, []string{"providers.epel-9.repos[0].base_url", "https://mirror.example.com/"}); err != nil {
+			t.Fatalf("config set provider key: %v", err)
+		}
+		if err := configSetRun(nil, []string{"providers.epel-9.enabled", "false"}); err != nil {
+			t.Fatalf("config set provider enabled: %v", err)
+		}
+	})
+
+	data, err := os.ReadFile(path)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if got := string(data); !strings.Contains(got, "# comment kept") || !strings.Contains(got, `listen: "127.0.0.1:9000"`) {
+		t.Errorf("config file = %q", got)
+	}
+
+	pc, err := st.GetProviderConfig("epel-9")
+	if err != nil {
+		t.Fatal(err)
+	}
+	if pc.Enabled || !strings.Contains(pc.ConfigJSON, `"base_url":"https://mirror.example.com/"`) {
+		t.Errorf("provider config = %+v", pc)
+	}
+	if revs, _ := st.ListProviderConfigRevisions("epel-9"); len(revs) != 2 || revs[0].Comment != "config set enabled" {
+		t.Errorf("revisions = %+v", revs)
+	}
+
+	if err := configSetRun(nil, []string{"providers.epel9.enabled", "true"}); err == nil || !strings.Contains(err.Error(), `did you mean "epel-9"`) {
+		t.Errorf("unknown provider error = %v", err)
+	}
+}
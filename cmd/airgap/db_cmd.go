@@ -0,0 +1,208 @@
+package main
+
+import (
+	"context"
+	"fmt"
+	"os"
+	"path/filepath"
+	"time"
+
+	"github.com/BadgerOps/airgap/internal/store"
+	"github.com/spf13/cobra"
+)
+
+func newDBCmd() *cobra.Command {
+	cmd := &cobra.Command{
+		Use:   "db",
+		Short: "Manage the airgap database",
+		Long:  `Manage the database that records sync runs, files, failures and transfers.`,
+		Example: `  airgap db maintain
+  airgap db backup /mnt/backups/airgap.db
+  airgap db restore /mnt/backups/airgap.db`,
+	}
+
+	cmd.AddCommand(newDBMaintainCmd(), newDBBackupCmd(), newDBRestoreCmd())
+
+	return cmd
+}
+
+func newDBMaintainCmd() *cobra.Command {
+	cmd := &cobra.Command{
+		Use:   "maintain",
+		Short: "Prune old history and compact the database",
+		Long: `Delete history outside database.retention (sync runs beyond the newest N per
+provider, resolved failed files, finished transfers and jobs older than the
+configured number of days), then run VACUUM and ANALYZE and report the space
+reclaimed.
+
+airgap serve runs the same task every database.maintenance_interval.`,
+		Example: `  airgap db maintain`,
+		Args:    cobra.NoArgs,
+		RunE:    dbMaintainRun,
+	}
+
+	return cmd
+}
+
+func dbMaintainRun(cmd *cobra.Command, args []string) error {
+	if globalCfg == nil {
+		return fmt.Errorf("config not loaded")
+	}
+	if globalEngine == nil {
+		return fmt.Errorf("sync engine not initialized")
+	}
+
+	report, err := globalEngine.Maintain(context.Background())
+	var detail string
+	if report != nil {
+		detail = fmt.Sprintf("pruned %d sync runs, %d failed files, %d transfers, %d jobs",
+			report.Pruned.SyncRuns, report.Pruned.FailedFiles, report.Pruned.Transfers, report.Pruned.Jobs)
+	}
+	recordAudit("db.maintain", "", detail, err)
+	if err != nil {
+		return fmt.Errorf("database maintenance failed: %w", err)
+	}
+
+	r := globalCfg.Database.Retention
+	fmt.Println("Database maintenance complete:")
+	fmt.Printf("  Sync runs pruned:         %d (keeping %s per provider)\n", report.Pruned.SyncRuns, retentionLimit(r.SyncRunsPerProvider, ""))
+	fmt.Printf("  Resolved failures pruned: %d (older than %s)\n", report.Pruned.FailedFiles, retentionLimit(r.ResolvedFailuresDays, " days"))
+	fmt.Printf("  Transfers pruned:         %d, %d archives (older than %s)\n", report.Pruned.Transfers, report.Pruned.TransferArchives, retentionLimit(r.TransfersDays, " days"))
+	fmt.Printf("  Jobs pruned:              %d (older than %s)\n", report.Pruned.Jobs, retentionLimit(r.JobsDays, " days"))
+	fmt.Printf("  Database size:            %s -> %s (%s reclaimed)\n",
+		formatBytes(report.SizeBefore), formatBytes(report.SizeAfter), formatBytes(report.Reclaimed()))
+	fmt.Printf("  Duration:                 %s\n", report.Duration.Round(time.Millisecond))
+
+	return nil
+}
+
+// retentionLimit formats a retention setting, where zero keeps everything.
+func retentionLimit(n int, unit string) string {
+	if n <= 0 {
+		return "all"
+	}
+	return fmt.Sprintf("%d%s", n, unit)
+}
+
+func newDBBackupCmd() *cobra.Command {
+	cmd := &cobra.Command{
+		Use:   "backup [path]",
+		Short: "Back up the database",
+		Long: `Write a consistent copy of the database using the SQLite online backup API.
+It is safe to run while airgap serve or a sync is using the database.
+
+Without a path the backup is written to <data_dir>/backups/airgap-<timestamp>.db.
+An existing file is never overwritten.`,
+		Example: `  airgap db backup
+  airgap db backup /mnt/backups/airgap.db`,
+		Args: cobra.MaximumNArgs(1),
+		RunE: dbBackupRun,
+	}
+
+	return cmd
+}
+
+func dbBackupRun(cmd *cobra.Command, args []string) error {
+	if globalCfg == nil {
+		return fmt.Errorf("config not loaded")
+	}
+	if globalStore == nil {
+		return fmt.Errorf("store not initialized")
+	}
+
+	backuper, err := dbBackuper()
+	if err != nil {
+		return err
+	}
+
+	dest := filepath.Join(globalCfg.Server.DataDir, "backups",
+		fmt.Sprintf("airgap-%s.db", time.Now().UTC().Format("20060102T150405Z")))
+	if len(args) == 1 {
+		dest = args[0]
+	}
+
+	if err := backuper.Backup(context.Background(), dest); err != nil {
+		return err
+	}
+
+	info, err := os.Stat(dest)
+	if err != nil {
+		return fmt.Errorf("backup written but not readable: %w", err)
+	}
+	fmt.Printf("Database backed up to %s (%s)\n", dest, formatBytes(info.Size()))
+	return nil
+}
+
+var dbRestoreForce bool
+
+func newDBRestoreCmd() *cobra.Command {
+	cmd := &cobra.Command{
+		Use:   "restore <path>",
+		Short: "Restore the database from a backup",
+		Long: `Replace the contents of the database with a backup made by airgap db backup.
+The backup is integrity-checked first and migrated to the current schema after
+the restore. Backups from a newer airgap version are rejected.
+
+Everything recorded since the backup is lost; take a fresh backup first if in
+doubt, and restart airgap serve afterwards so it reloads provider configs.
+
+A restore that would discard audit events recorded since the backup, or replace
+an audit log the backup does not match, is refused unless --force is given. The
+restore is recorded in the restored audit log with the number of events
+discarded.`,
+		Example: `  airgap db restore /mnt/backups/airgap.db`,
+		Args:    cobra.ExactArgs(1),
+		RunE:    dbRestoreRun,
+	}
+
+	cmd.Flags().BoolVar(&dbRestoreForce, "force", false, "restore even if audit events would be discarded")
+
+	return cmd
+}
+
+func dbRestoreRun(cmd *cobra.Command, args []string) error {
+	if globalStore == nil {
+		return fmt.Errorf("store not initialized")
+	}
+
+	backuper, err := dbBackuper()
+	if err != nil {
+		return err
+	}
+	if cmd != nil {
+		cmd.SilenceUsage = true
+	}
+
+	ctx := context.Background()
+	discarded, err := backuper.DiscardedAuditEvents(ctx, args[0])
+	if err == nil && discarded > 0 && !dbRestoreForce {
+		err = fmt.Errorf("restoring %s would discard %d audit events that are not in the backup; use --force to restore anyway", args[0], discarded)
+	}
+	if err != nil {
+		recordAudit("db.restore", args[0], "", err)
+		return err
+	}
+
+	// Recorded after the restore so the event lands in the restored log.
+	err = backuper.Restore(ctx, args[0])
+	recordAudit("db.restore", args[0], fmt.Sprintf("discarded %d audit events", discarded), err)
+	if err != nil {
+		return err
+	}
+
+	if discarded > 0 {
+		fmt.Fprintf(os.Stderr, "Warning: discarded %d audit events that were not in the backup\n", discarded)
+	}
+	fmt.Printf("Database restored from %s\n", args[0])
+	return nil
+}
+
+// dbBackuper returns the store as a store.Backuper, or an error for drivers
+// backed up with their own tooling.
+func dbBackuper() (store.Backuper, error) {
+	backuper, ok := globalStore.(store.Backuper)
+	if !ok {
+		return nil, fmt.Errorf("the %s driver does not support airgap db backup/restore; use pg_dump and pg_restore instead", globalCfg.Database.Driver)
+	}
+	return backuper, nil
+}
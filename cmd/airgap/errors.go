@@ -0,0 +1,100 @@
+package main
+
+import (
+	"errors"
+	"fmt"
+	"os"
+)
+
+// Exit codes of the airgap command. Scripts may depend on them, so existing
+// values must not change.
+const (
+	exitOK                = 0
+	exitFailure           = 1 // the command failed
+	exitUsage             = 2 // invalid arguments, flags or output format
+	exitConfig            = 3 // config, database or providers could not be loaded
+	exitPartial           = 4 // the command ran but providers, files or archives failed
+	exitInsufficientSpace = 5 // the data directory lacks the space a sync needs
+)
+
+// Error codes of structured error documents, one per exit code.
+const (
+	errCodeFailure           = "failure"
+	errCodeUsage             = "usage"
+	errCodeConfig            = "config"
+	errCodePartial           = "partial_failure"
+	errCodeInsufficientSpace = "insufficient_space"
+)
+
+// cliError attaches an error code and exit status to an error.
+type cliError struct {
+	code string
+	exit int
+	err  error
+}
+
+func (e *cliError) Error() string { return e.err.Error() }
+
+func (e *cliError) Unwrap() error { return e.err }
+
+func usageError(err error) error {
+	return &cliError{code: errCodeUsage, exit: exitUsage, err: err}
+}
+
+func configError(err error) error {
+	return &cliError{code: errCodeConfig, exit: exitConfig, err: err}
+}
+
+func partialError(err error) error {
+	return &cliError{code: errCodePartial, exit: exitPartial, err: err}
+}
+
+func insufficientSpaceError(err error) error {
+	return &cliError{code: errCodeInsufficientSpace, exit: exitInsufficientSpace, err: err}
+}
+
+// commandStarted is set once the root PersistentPreRunE runs. Errors cobra
+// returns before that come from parsing arguments and flags.
+var commandStarted bool
+
+// classifyError returns the error code and exit status for err.
+func classifyError(err error) (string, int) {
+	var ce *cliError
+	if errors.As(err, &ce) {
+		return ce.code, ce.exit
+	}
+	if !commandStarted {
+		return errCodeUsage, exitUsage
+	}
+	return errCodeFailure, exitFailure
+}
+
+// errorDocument is what a failed command prints with --output json or yaml.
+type errorDocument struct {
+	Version int         `json:"version"`
+	Error   outputError `json:"error"`
+}
+
+type outputError struct {
+	Code     string `json:"code"`
+	Message  string `json:"message"`
+	ExitCode int    `json:"exit_code"`
+}
+
+// reportError prints err and returns the exit status for it. The message
+// always goes to stderr; with --output json or yaml an error document is
+// also written to stdout unless the command already printed its result.
+func reportError(err error) int {
+	if err == nil {
+		return exitOK
+	}
+	code, exit := classifyError(err)
+	fmt.Fprintln(os.Stderr, "Error:", err)
+	if structuredOutput() && !outputWritten {
+		_ = writeStructured(outputFormat, &errorDocument{
+			Version: outputVersion,
+			Error:   outputError{Code: code, Message: err.Error(), ExitCode: exit},
+		})
+	}
+	return exit
+}
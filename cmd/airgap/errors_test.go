@@ -0,0 +1,59 @@
+package main
+
+import (
+	"encoding/json"
+	"errors"
+	"fmt"
+	"testing"
+)
+
+func TestReportError(t *testing.T) {
+	origStarted := commandStarted
+	t.Cleanup(func() { commandStarted = origStarted })
+
+	tests := []struct {
+		name     string
+		started  bool
+		written  bool
+		err      error
+		wantExit int
+		wantCode string
+	}{
+		{"parse error", false, false, errors.New(`unknown flag: --bogus`), exitUsage, errCodeUsage},
+		{"failure", true, false, errors.New("export failed"), exitFailure, errCodeFailure},
+		{"config", true, false, configError(errors.New("failed to load config")), exitConfig, errCodeConfig},
+		{"wrapped partial", true, false, fmt.Errorf("sync: %w", partialError(errors.New("1 failures"))), exitPartial, errCodePartial},
+		{"after result", true, true, insufficientSpaceError(errors.New("not enough disk space")), exitInsufficientSpace, ""},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			setOutputFormat(t, outputJSON)
+			commandStarted, outputWritten = tt.started, tt.written
+
+			var exit int
+			out := captureStdout(t, func() { exit = reportError(tt.err) })
+			if exit != tt.wantExit {
+				t.Errorf("exit = %d, want %d", exit, tt.wantExit)
+			}
+			if tt.wantCode == "" {
+				if out != "" {
+					t.Errorf("error document written after result: %s", out)
+				}
+				return
+			}
+			var doc errorDocument
+			if err := json.Unmarshal([]byte(out), &doc); err != nil {
+				t.Fatalf("output is not JSON: %v\n%s", err, out)
+			}
+			want := outputError{Code: tt.wantCode, Message: tt.err.Error(), ExitCode: tt.wantExit}
+			if doc.Version != outputVersion || doc.Error != want {
+				t.Errorf("document = %+v, want error %+v", doc, want)
+			}
+		})
+	}
+
+	setOutputFormat(t, outputTable)
+	if out := captureStdout(t, func() { reportError(errors.New("boom")) }); out != "" {
+		t.Errorf("table output wrote to stdout: %s", out)
+	}
+}
@@ -3,6 +3,7 @@ package main
 import (
 	"fmt"
 	"log/slog"
+	"sort"
 	"strings"
 	"time"
 
@@ -15,6 +16,7 @@ var (
 	exportProvider    string
 	exportSplitSize   string
 	exportCompression string
+	exportState       bool
 )
 
 func newExportCmd() *cobra.Command {
@@ -28,18 +30,26 @@ The --to flag is required and specifies the output directory. By default,
 exports all enabled providers; use --provider to export specific ones.
 
 Supports configurable split size (for multi-volume exports) and compression
-formats (none, gzip, zstd).`,
+formats (none, gzip, zstd).
+
+With --include-state the export also carries airgap-state.json, a snapshot of
+the exported providers' configs (credentials removed) and sync history, so the
+low side can show when and by which sync run each file was fetched upstream.`,
 		Example: `  airgap export --to /mnt/transfer-disk --all
   airgap export --to /mnt/usb --provider epel
   airgap export --to /mnt/transfer --provider container-images --split-size 4GB --compression zstd
-  airgap export --to /mnt/external --provider rhcos --compression gzip`,
-		RunE: exportRun,
+  airgap export --to /mnt/external --provider rhcos --compression gzip
+  airgap export --to /mnt/transfer-disk --include-state
+  airgap export --to /mnt/usb --provider epel --output json`,
+		Annotations: map[string]string{outputAnnotation: structuredFormats},
+		RunE:        exportRun,
 	}
 
 	cmd.Flags().StringVar(&exportTo, "to", "", "output directory for exported content (required)")
 	cmd.Flags().StringVar(&exportProvider, "provider", "", "comma-separated list of providers to export")
 	cmd.Flags().StringVar(&exportSplitSize, "split-size", "25GB", "split large archives into chunks of this size")
 	cmd.Flags().StringVar(&exportCompression, "compression", "zstd", "compression format (none, gzip, zstd)")
+	cmd.Flags().BoolVar(&exportState, "include-state", false, "include a sanitized snapshot of provider configs and sync history")
 
 	if err := cmd.MarkFlagRequired("to"); err != nil {
 		panic(err)
@@ -67,10 +77,16 @@ func exportRun(cmd *cobra.Command, args []string) error {
 				providers = append(providers, name)
 			}
 		}
+		sort.Strings(providers)
 	}
 
+	structured := structuredOutput()
+
 	if len(providers) == 0 {
 		log.Warn("no providers to export")
+		if structured {
+			return writeStructured(outputFormat, &exportDocument{Version: outputVersion, Providers: []string{}, ExportReport: &engine.ExportReport{}})
+		}
 		return nil
 	}
 
@@ -79,28 +95,43 @@ func exportRun(cmd *cobra.Command, args []string) error {
 		return fmt.Errorf("invalid split size %q: %w", exportSplitSize, err)
 	}
 
-	fmt.Printf("Exporting to %s...\n", exportTo)
-	fmt.Printf("  Providers: %v\n", providers)
-	fmt.Printf("  Split size: %s\n", exportSplitSize)
-	fmt.Printf("  Compression: %s\n", exportCompression)
-	fmt.Println()
+	if !structured {
+		fmt.Printf("Exporting to %s...\n", exportTo)
+		fmt.Printf("  Providers: %v\n", providers)
+		fmt.Printf("  Split size: %s\n", exportSplitSize)
+		fmt.Printf("  Compression: %s\n", exportCompression)
+		fmt.Println()
+	}
 
 	report, err := globalEngine.Export(cmd.Context(), engine.ExportOptions{
-		OutputDir:   exportTo,
-		Providers:   providers,
-		SplitSize:   splitSize,
-		Compression: exportCompression,
+		OutputDir:    exportTo,
+		Providers:    providers,
+		SplitSize:    splitSize,
+		Compression:  exportCompression,
+		IncludeState: exportState,
 	})
+	var detail string
+	if report != nil {
+		detail = fmt.Sprintf("providers=%s archives=%d files=%d", strings.Join(providers, ","), len(report.Archives), report.TotalFiles)
+	}
+	recordAudit("transfer.export", exportTo, detail, err)
 	if err != nil {
 		return fmt.Errorf("export failed: %w", err)
 	}
 
+	if structured {
+		return writeStructured(outputFormat, &exportDocument{Version: outputVersion, Providers: providers, ExportReport: report})
+	}
+
 	fmt.Printf("Export complete:\n")
 	fmt.Printf("  Archives: %d\n", len(report.Archives))
 	fmt.Printf("  Files: %d\n", report.TotalFiles)
 	fmt.Printf("  Total size: %s\n", formatBytes(report.TotalSize))
 	fmt.Printf("  Duration: %s\n", report.Duration.Round(time.Second))
 	fmt.Printf("  Manifest: %s\n", report.ManifestPath)
+	if report.StatePath != "" {
+		fmt.Printf("  State snapshot: %s\n", report.StatePath)
+	}
 
 	for _, arch := range report.Archives {
 		fmt.Printf("  - %s (%s)\n", arch.Name, formatBytes(arch.Size))
@@ -108,3 +139,10 @@ func exportRun(cmd *cobra.Command, args []string) error {
 
 	return nil
 }
+
+// exportDocument is the --output json|yaml form of airgap export.
+type exportDocument struct {
+	Version   int      `json:"version"`
+	Providers []string `json:"providers"`
+	*engine.ExportReport
+}
@@ -2,6 +2,7 @@ package main
 
 import (
 	"fmt"
+	"sort"
 	"time"
 
 	"github.com/BadgerOps/airgap/internal/engine"
@@ -27,8 +28,10 @@ Use --force to overwrite existing files during import.
 Use --skip-validated to skip re-validation of previously validated archives.`,
 		Example: `  airgap import --from /mnt/usb
   airgap import --from /mnt/transfer-disk --verify-only
-  airgap import --from /media/offline-backup --force`,
-		RunE: importRun,
+  airgap import --from /media/offline-backup --force
+  airgap import --from /mnt/usb --output json`,
+		Annotations: map[string]string{outputAnnotation: structuredFormats},
+		RunE:        importRun,
 	}
 
 	cmd.Flags().StringVar(&importFrom, "from", "", "source directory containing exported content (required)")
@@ -48,17 +51,20 @@ func importRun(cmd *cobra.Command, args []string) error {
 		return fmt.Errorf("engine not initialized")
 	}
 
-	fmt.Printf("Importing from %s...\n", importFrom)
-	if importVerifyOnly {
-		fmt.Println("  Mode: verify only")
-	}
-	if importForce {
-		fmt.Println("  Mode: force (skip checksum verification)")
-	}
-	if importSkipValidated {
-		fmt.Println("  Mode: skip previously validated archives")
+	structured := structuredOutput()
+	if !structured {
+		fmt.Printf("Importing from %s...\n", importFrom)
+		if importVerifyOnly {
+			fmt.Println("  Mode: verify only")
+		}
+		if importForce {
+			fmt.Println("  Mode: force (skip checksum verification)")
+		}
+		if importSkipValidated {
+			fmt.Println("  Mode: skip previously validated archives")
+		}
+		fmt.Println()
 	}
-	fmt.Println()
 
 	report, err := globalEngine.Import(cmd.Context(), engine.ImportOptions{
 		SourceDir:     importFrom,
@@ -66,14 +72,43 @@ func importRun(cmd *cobra.Command, args []string) error {
 		Force:         importForce,
 		SkipValidated: importSkipValidated,
 	})
+	var detail string
+	if report != nil {
+		detail = fmt.Sprintf("verify_only=%t force=%t validated=%d files=%d",
+			importVerifyOnly, importForce, report.ArchivesValidated, report.FilesExtracted)
+	}
+	recordAudit("transfer.import", importFrom, detail, err)
 	if err != nil {
-		// Still print partial report if available
-		if report != nil {
-			printImportReport(report)
+		if report == nil {
+			return fmt.Errorf("import failed: %w", err)
 		}
-		return fmt.Errorf("import failed: %w", err)
+		// Still print partial report
+		if err := writeImportReport(report, structured); err != nil {
+			return err
+		}
+		return partialError(fmt.Errorf("import failed: %w", err))
 	}
 
+	return writeImportReport(report, structured)
+}
+
+// importDocument is the --output json|yaml form of airgap import.
+type importDocument struct {
+	Version    int    `json:"version"`
+	Source     string `json:"source"`
+	VerifyOnly bool   `json:"verify_only"`
+	*engine.ImportReport
+}
+
+func writeImportReport(report *engine.ImportReport, structured bool) error {
+	if structured {
+		return writeStructured(outputFormat, &importDocument{
+			Version:      outputVersion,
+			Source:       importFrom,
+			VerifyOnly:   importVerifyOnly,
+			ImportReport: report,
+		})
+	}
 	printImportReport(report)
 	return nil
 }
@@ -88,6 +123,20 @@ func printImportReport(report *engine.ImportReport) {
 	fmt.Printf("  Files extracted: %d\n", report.FilesExtracted)
 	fmt.Printf("  Total size: %s\n", formatBytes(report.TotalSize))
 	fmt.Printf("  Duration: %s\n", report.Duration.Round(time.Second))
+	if len(report.Signatures) > 0 {
+		names := make([]string, 0, len(report.Signatures))
+		for name := range report.Signatures {
+			names = append(names, name)
+		}
+		sort.Strings(names)
+		fmt.Println("  Upstream signatures:")
+		for _, name := range names {
+			fmt.Printf("    - %s: %s\n", name, report.Signatures[name])
+		}
+	}
+	if report.SourceHost != "" {
+		fmt.Printf("  Upstream state: %d sync run(s) from %s\n", report.UpstreamSyncRuns, report.SourceHost)
+	}
 	if len(report.Errors) > 0 {
 		fmt.Println("  Errors:")
 		for _, e := range report.Errors {
@@ -6,7 +6,5 @@ import (
 
 func main() {
 	cmd := NewRootCmd()
-	if err := cmd.Execute(); err != nil {
-		os.Exit(1)
-	}
+	os.Exit(reportError(cmd.Execute()))
 }
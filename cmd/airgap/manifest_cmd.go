@@ -0,0 +1,256 @@
+package main
+
+import (
+	"fmt"
+	"os"
+	"strconv"
+	"strings"
+	"time"
+
+	"github.com/spf13/cobra"
+
+	"github.com/BadgerOps/airgap/internal/engine"
+)
+
+var (
+	manifestDiffTransfers bool
+	manifestDiffFiles     bool
+	manifestListLimit     int
+)
+
+func newManifestCmd() *cobra.Command {
+	cmd := &cobra.Command{
+		Use:   "manifest",
+		Short: "Inspect and compare transfer manifests",
+	}
+
+	diffCmd := &cobra.Command{
+		Use:   "diff OLD NEW",
+		Short: "Compare the file inventories of two transfers",
+		Long: `Compare the file inventories of two transfer manifests and report the files
+added, removed and changed per provider with their size deltas. RPMs are
+interpreted by name and arch, so a replaced package shows as an upgrade or
+downgrade, and container image manifests are grouped per mirrored image.
+
+OLD and NEW are airgap-manifest.json files or transfer directories. With
+--transfers they are IDs of recorded exports and imports instead; see
+'airgap manifest list'.
+
+The table lists packages, images and other changed files; --files lists every
+changed file instead. JSON carries a "version" field that changes only when
+fields are renamed or removed, and CSV has one row per file, package and image.`,
+		Example: `  airgap manifest diff /mnt/usb-2026-09 /mnt/usb-2026-10
+  airgap manifest diff --transfers 3 7
+  airgap manifest diff --transfers 3 7 --output csv > changes.csv`,
+		Annotations: map[string]string{outputAnnotation: outputTable + "," + outputJSON + "," + outputCSV},
+		Args:        cobra.ExactArgs(2),
+		RunE:        manifestDiffRun,
+	}
+	diffCmd.Flags().BoolVar(&manifestDiffTransfers, "transfers", false, "treat OLD and NEW as recorded transfer IDs")
+	diffCmd.Flags().BoolVar(&manifestDiffFiles, "files", false, "list every changed file in the table")
+
+	listCmd := &cobra.Command{
+		Use:         "list",
+		Short:       "List recorded transfers and their IDs",
+		Args:        cobra.NoArgs,
+		Annotations: map[string]string{outputAnnotation: structuredFormats},
+		RunE:        manifestListRun,
+	}
+	listCmd.Flags().IntVar(&manifestListLimit, "limit", 20, "maximum number of transfers")
+
+	cmd.AddCommand(diffCmd, listCmd)
+	return cmd
+}
+
+func manifestDiffRun(cmd *cobra.Command, args []string) error {
+	if cmd != nil {
+		cmd.SilenceUsage = true
+	}
+
+	var manifests [2]*engine.TransferManifest
+	var labels [2]string
+	for i, arg := range args {
+		if manifestDiffTransfers {
+			if globalEngine == nil {
+				return fmt.Errorf("sync engine not initialized")
+			}
+			id, err := strconv.ParseInt(arg, 10, 64)
+			if err != nil {
+				return fmt.Errorf("invalid transfer ID %q", arg)
+			}
+			manifests[i], labels[i], err = globalEngine.TransferManifest(id)
+			if err != nil {
+				return err
+			}
+			continue
+		}
+		m, err := engine.LoadManifestFile(arg)
+		if err != nil {
+			return err
+		}
+		manifests[i], labels[i] = m, arg
+	}
+
+	diff := engine.DiffManifests(manifests[0], manifests[1], labels[0], labels[1])
+	switch outputFormat {
+	case outputJSON:
+		return writeStructured(outputJSON, diff)
+	case outputCSV:
+		return diff.WriteCSV(os.Stdout)
+	}
+	printManifestDiff(diff)
+	return nil
+}
+
+func printManifestDiff(diff *engine.ManifestDiff) {
+	for _, side := range []struct {
+		name string
+		s    engine.ManifestDiffSide
+	}{{"From", diff.From}, {"To", diff.To}} {
+		fmt.Printf("%-5s %s: %d files, %s", side.name, side.s.Label, side.s.Files, formatBytes(side.s.TotalSize))
+		if !side.s.Created.IsZero() {
+			fmt.Printf(", created %s", side.s.Created.Format("2006-01-02 15:04"))
+		}
+		if side.s.SourceHost != "" {
+			fmt.Printf(" on %s", side.s.SourceHost)
+		}
+		fmt.Println()
+	}
+
+	if len(diff.Providers) == 0 {
+		fmt.Println("\nNo differences.")
+	}
+
+	for _, pd := range diff.Providers {
+		t := pd.Totals
+		fmt.Printf("\n%s: %d added, %d removed, %d changed (%s)\n",
+			pd.Provider, t.Added, t.Removed, t.Changed, formatSignedBytes(t.SizeDelta))
+
+		files := pd.Files
+		if !manifestDiffFiles {
+			files = pd.OtherFiles()
+			if len(pd.Packages) > 0 {
+				fmt.Println("  Packages:")
+				for _, p := range pd.Packages {
+					fmt.Printf("    %-11s %-40s %s\n", p.Change, p.Name+"."+p.Arch, versionChange(p.OldVersion, p.NewVersion))
+				}
+			}
+			if len(pd.Images) > 0 {
+				fmt.Println("  Images:")
+				for _, img := range pd.Images {
+					fmt.Printf("    %-11s %-40s %s\n", img.Change, img.Image,
+						versionChange(strings.Join(img.OldDigests, ","), strings.Join(img.NewDigests, ",")))
+				}
+			}
+		}
+		if len(files) > 0 {
+			fmt.Println("  Files:")
+			for _, f := range files {
+				var size string
+				switch f.Change {
+				case engine.ChangeAdded:
+					size = formatBytes(f.NewSize)
+				case engine.ChangeRemoved:
+					size = formatBytes(f.OldSize)
+				default:
+					size = fmt.Sprintf("%s -> %s", formatBytes(f.OldSize), formatBytes(f.NewSize))
+				}
+				fmt.Printf("    %-11s %-60s %s\n", f.Change, f.Path, size)
+			}
+		}
+	}
+
+	t := diff.Totals
+	fmt.Println("\n=== DIFF SUMMARY ===")
+	fmt.Printf("Added:      %d (%s)\n", t.Added, formatBytes(t.AddedBytes))
+	fmt.Printf("Removed:    %d (%s)\n", t.Removed, formatBytes(t.RemovedBytes))
+	fmt.Printf("Changed:    %d\n", t.Changed)
+	fmt.Printf("Unchanged:  %d\n", t.Unchanged)
+	fmt.Printf("Size delta: %s\n", formatSignedBytes(t.SizeDelta))
+}
+
+// versionChange formats the old and new side of a package or image.
+func versionChange(oldV, newV string) string {
+	switch {
+	case oldV == "":
+		return newV
+	case newV == "" || newV == oldV:
+		return oldV
+	}
+	return oldV + " -> " + newV
+}
+
+// formatSignedBytes formats a size delta with its sign.
+func formatSignedBytes(delta int64) string {
+	if delta < 0 {
+		return "-" + formatBytes(-delta)
+	}
+	return "+" + formatBytes(delta)
+}
+
+func manifestListRun(cmd *cobra.Command, args []string) error {
+	if globalStore == nil {
+		return fmt.Errorf("store not initialized")
+	}
+	transfers, err := globalStore.ListTransfers(manifestListLimit)
+	if err != nil {
+		return fmt.Errorf("listing transfers: %w", err)
+	}
+	if structuredOutput() {
+		doc := &transfersDocument{Version: outputVersion, Transfers: make([]transferEntry, 0, len(transfers))}
+		for _, t := range transfers {
+			entry := transferEntry{
+				ID:           t.ID,
+				Direction:    t.Direction,
+				Path:         t.Path,
+				Providers:    []string{},
+				ArchiveCount: t.ArchiveCount,
+				TotalSize:    t.TotalSize,
+				ManifestHash: t.ManifestHash,
+				Status:       t.Status,
+				Error:        t.ErrorMessage,
+				StartTime:    t.StartTime,
+			}
+			if t.Providers != "" {
+				entry.Providers = strings.Split(t.Providers, ",")
+			}
+			if !t.EndTime.IsZero() {
+				end := t.EndTime
+				entry.EndTime = &end
+			}
+			doc.Transfers = append(doc.Transfers, entry)
+		}
+		return writeStructured(outputFormat, doc)
+	}
+	if len(transfers) == 0 {
+		fmt.Println("No transfers recorded.")
+		return nil
+	}
+
+	fmt.Printf("%-6s %-9s %-17s %-10s %s\n", "ID", "Direction", "Started", "Status", "Path")
+	fmt.Println(strings.Repeat("-", 80))
+	for _, t := range transfers {
+		fmt.Printf("%-6d %-9s %-17s %-10s %s\n", t.ID, t.Direction, t.StartTime.Local().Format("2006-01-02 15:04"), t.Status, t.Path)
+	}
+	return nil
+}
+
+// transfersDocument is the --output json|yaml form of airgap manifest list.
+type transfersDocument struct {
+	Version   int             `json:"version"`
+	Transfers []transferEntry `json:"transfers"`
+}
+
+type transferEntry struct {
+	ID           int64      `json:"id"`
+	Direction    string     `json:"direction"`
+	Path         string     `json:"path"`
+	Providers    []string   `json:"providers"`
+	ArchiveCount int        `json:"archive_count"`
+	TotalSize    int64      `json:"total_size"`
+	ManifestHash string     `json:"manifest_hash,omitempty"`
+	Status       string     `json:"status"`
+	Error        string     `json:"error,omitempty"`
+	StartTime    time.Time  `json:"start_time"`
+	EndTime      *time.Time `json:"end_time,omitempty"`
+}
@@ -0,0 +1,146 @@
+package main
+
+import (
+	"bytes"
+	"encoding/json"
+	"fmt"
+	"io"
+	"os"
+	"strings"
+
+	"github.com/spf13/cobra"
+	"gopkg.in/yaml.v3"
+)
+
+// Output formats accepted by --output.
+const (
+	outputTable = "table"
+	outputJSON  = "json"
+	outputYAML  = "yaml"
+	outputCSV   = "csv"
+)
+
+// outputVersion is the "version" of the documents commands print with
+// --output json or yaml. It changes only when fields are renamed or removed.
+const outputVersion = 1
+
+// outputAnnotation lists the --output formats a command supports, comma
+// separated. Commands without it only print tables.
+const outputAnnotation = "airgap/output"
+
+// structuredFormats is the outputAnnotation value of the read-only commands.
+const structuredFormats = outputTable + "," + outputJSON + "," + outputYAML
+
+var (
+	// outputFormat is the global --output flag.
+	outputFormat string
+	// outputWritten records that a command printed its result document, so a
+	// failure afterwards is not reported with a second document.
+	outputWritten bool
+)
+
+// structuredOutput reports whether --output asks for JSON or YAML.
+func structuredOutput() bool {
+	return outputFormat == outputJSON || outputFormat == outputYAML
+}
+
+// checkOutputFormat rejects a --output value that is not one of formats.
+func checkOutputFormat(format string, formats ...string) error {
+	for _, f := range formats {
+		if format == f {
+			return nil
+		}
+	}
+	want := formats[0]
+	if len(formats) > 1 {
+		want = strings.Join(formats[:len(formats)-1], ", ") + " or " + formats[len(formats)-1]
+	}
+	return fmt.Errorf("unknown output format %q (want %s)", format, want)
+}
+
+// checkCommandOutput rejects a --output value the command does not support.
+func checkCommandOutput(cmd *cobra.Command) error {
+	formats, ok := cmd.Annotations[outputAnnotation]
+	if !ok {
+		if outputFormat == outputTable {
+			return nil
+		}
+		return fmt.Errorf("%s does not support --output %s", cmd.CommandPath(), outputFormat)
+	}
+	return checkOutputFormat(outputFormat, strings.Split(formats, ",")...)
+}
+
+// describeOutputFlag adjusts the help of --output to the formats cmd
+// supports, and hides it on subcommands that only print tables.
+func describeOutputFlag(cmd *cobra.Command) {
+	f := cmd.Flag("output")
+	if f == nil {
+		return
+	}
+	formats, ok := cmd.Annotations[outputAnnotation]
+	if !ok {
+		formats = structuredFormats
+	}
+	f.Hidden = !ok && cmd.HasParent()
+	list := strings.Split(formats, ",")
+	f.Usage = fmt.Sprintf("output format (%s or %s)", strings.Join(list[:len(list)-1], ", "), list[len(list)-1])
+}
+
+// writeStructured writes v to stdout as indented JSON or as YAML.
+func writeStructured(format string, v interface{}) error {
+	outputWritten = true
+	return encodeStructured(os.Stdout, format, v)
+}
+
+// encodeStructured writes v as indented JSON or as YAML. YAML is converted
+// from the JSON encoding so both formats share field names and value types.
+func encodeStructured(w io.Writer, format string, v interface{}) error {
+	switch format {
+	case outputJSON:
+		enc := json.NewEncoder(w)
+		enc.SetIndent("", "  ")
+		return enc.Encode(v)
+	case outputYAML:
+		data, err := json.Marshal(v)
+		if err != nil {
+			return err
+		}
+		var doc yaml.Node
+		if err := yaml.NewDecoder(bytes.NewReader(data)).Decode(&doc); err != nil {
+			return err
+		}
+		blockStyle(&doc)
+		enc := yaml.NewEncoder(w)
+		enc.SetIndent(2)
+		if err := enc.Encode(&doc); err != nil {
+			return err
+		}
+		return enc.Close()
+	}
+	return fmt.Errorf("output format %q is not structured", format)
+}
+
+// blockStyle drops the flow and quoting styles JSON decodes with, so the
+// encoder writes block YAML and quotes only strings that need it. Strings
+// YAML 1.1 parsers such as PyYAML read as booleans stay quoted.
+func blockStyle(n *yaml.Node) {
+	n.Style = 0
+	if n.Kind == yaml.ScalarNode && n.Tag == "!!str" && yaml11Bools[n.Value] {
+		n.Style = yaml.DoubleQuotedStyle
+	}
+	for _, c := range n.Content {
+		blockStyle(c)
+	}
+}
+
+var yaml11Bools = map[string]bool{
+	"y": true, "Y": true, "yes": true, "Yes": true, "YES": true,
+	"n": true, "N": true, "no": true, "No": true, "NO": true,
+	"on": true, "On": true, "ON": true, "off": true, "Off": true, "OFF": true,
+}
+
+// providerError is a provider a command could not process.
+type providerError struct {
+	Provider string `json:"provider"`
+	Error    string `json:"error"`
+}
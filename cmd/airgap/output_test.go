@@ -0,0 +1,190 @@
+package main
+
+import (
+	"bytes"
+	"encoding/json"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/BadgerOps/airgap/internal/engine"
+	"github.com/BadgerOps/airgap/internal/provider"
+	"github.com/BadgerOps/airgap/internal/store"
+	"github.com/spf13/cobra"
+)
+
+func setOutputFormat(t *testing.T, format string) {
+	t.Helper()
+	origFormat, origWritten := outputFormat, outputWritten
+	outputFormat, outputWritten = format, false
+	t.Cleanup(func() { outputFormat, outputWritten = origFormat, origWritten })
+}
+
+func TestProvidersListRun_JSON(t *testing.T) {
+	setOutputFormat(t, outputJSON)
+	st := newTestStore(t)
+	mustCreateProviderConfig(t, st, "rhcos-main", "rhcos", true)
+	mustCreateProviderConfig(t, st, "container-set", "container_images", false)
+
+	reg := provider.NewRegistry()
+	reg.RegisterAs("rhcos-main", &stubProvider{name: "rhcos-main"})
+
+	origStore, origRegistry := globalStore, globalRegistry
+	globalStore, globalRegistry = st, reg
+	t.Cleanup(func() { globalStore, globalRegistry = origStore, origRegistry })
+
+	out := captureStdout(t, func() {
+		if err := providersListRun(nil, nil); err != nil {
+			t.Fatalf("providersListRun returned error: %v", err)
+		}
+	})
+
+	var doc providersDocument
+	if err := json.Unmarshal([]byte(out), &doc); err != nil {
+		t.Fatalf("output is not JSON: %v\n%s", err, out)
+	}
+	want := []providerEntry{
+		{Name: "container-set", Type: "container_images"},
+		{Name: "rhcos-main", Type: "rhcos", Enabled: true, Loaded: true},
+	}
+	if doc.Version != outputVersion || len(doc.Providers) != 2 || doc.Providers[0] != want[0] || doc.Providers[1] != want[1] {
+		t.Errorf("document = %+v", doc)
+	}
+}
+
+func TestProvidersHistoryRun_JSON(t *testing.T) {
+	setOutputFormat(t, outputJSON)
+	st := newTestStore(t)
+	mustCreateProviderConfig(t, st, "epel", "epel", true)
+	if _, err := st.AddProviderConfigRevision("epel", "alice", "initial"); err != nil {
+		t.Fatal(err)
+	}
+
+	origStore := globalStore
+	globalStore = st
+	t.Cleanup(func() { globalStore = origStore })
+
+	out := captureStdout(t, func() {
+		if err := providersHistoryRun("epel"); err != nil {
+			t.Fatalf("providersHistoryRun returned error: %v", err)
+		}
+	})
+
+	var doc historyDocument
+	if err := json.Unmarshal([]byte(out), &doc); err != nil {
+		t.Fatalf("output is not JSON: %v\n%s", err, out)
+	}
+	if doc.Version != outputVersion || doc.Provider != "epel" || len(doc.Revisions) != 1 ||
+		doc.Revisions[0].Revision != 1 || doc.Revisions[0].Author != "alice" || doc.Revisions[0].Comment != "initial" {
+		t.Errorf("document = %+v", doc)
+	}
+}
+
+func TestManifestListRun_JSON(t *testing.T) {
+	setOutputFormat(t, outputJSON)
+	st := newTestStore(t)
+	if err := st.CreateTransfer(&store.Transfer{
+		Direction: "export",
+		Path:      "/mnt/usb",
+		Providers: "epel,rhcos",
+		Status:    "completed",
+		StartTime: time.Now(),
+	}); err != nil {
+		t.Fatal(err)
+	}
+
+	origStore := globalStore
+	globalStore = st
+	t.Cleanup(func() { globalStore = origStore })
+
+	out := captureStdout(t, func() {
+		if err := manifestListRun(nil, nil); err != nil {
+			t.Fatalf("manifestListRun returned error: %v", err)
+		}
+	})
+
+	var doc transfersDocument
+	if err := json.Unmarshal([]byte(out), &doc); err != nil {
+		t.Fatalf("output is not JSON: %v\n%s", err, out)
+	}
+	if doc.Version != outputVersion || len(doc.Transfers) != 1 {
+		t.Fatalf("document = %+v", doc)
+	}
+	got := doc.Transfers[0]
+	if got.Direction != "export" || got.Path != "/mnt/usb" || strings.Join(got.Providers, ",") != "epel,rhcos" || got.EndTime != nil {
+		t.Errorf("transfer = %+v", got)
+	}
+}
+
+func TestEncodeStructuredYAML(t *testing.T) {
+	report := &engine.ImportReport{
+		FilesExtracted: 3,
+		Duration:       2 * time.Second,
+		Errors:         []string{"yes"},
+		Signatures:     map[string]string{"epel": "verified"},
+	}
+
+	var buf bytes.Buffer
+	if err := encodeStructured(&buf, outputYAML, &importDocument{Version: outputVersion, Source: "/mnt/usb", ImportReport: report}); err != nil {
+		t.Fatal(err)
+	}
+	out := buf.String()
+	for _, line := range []string{
+		"version: 1\n",
+		"source: /mnt/usb\n",
+		"files_extracted: 3\n",
+		"duration_ns: 2000000000\n",
+		"errors:\n  - \"yes\"\n",
+		"signatures:\n  epel: verified\n",
+	} {
+		if !strings.Contains(out, line) {
+			t.Errorf("YAML missing %q:\n%s", line, out)
+		}
+	}
+}
+
+func TestCheckCommandOutput(t *testing.T) {
+	structured := &cobra.Command{Use: "status", Annotations: map[string]string{outputAnnotation: structuredFormats}}
+	plain := &cobra.Command{Use: "serve"}
+
+	tests := []struct {
+		cmd     *cobra.Command
+		format  string
+		wantErr string
+	}{
+		{structured, outputYAML, ""},
+		{structured, outputCSV, `unknown output format "csv" (want table, json or yaml)`},
+		{plain, outputTable, ""},
+		{plain, outputJSON, "serve does not support --output json"},
+	}
+	for _, tt := range tests {
+		setOutputFormat(t, tt.format)
+		err := checkCommandOutput(tt.cmd)
+		if (tt.wantErr == "" && err != nil) || (tt.wantErr != "" && (err == nil || err.Error() != tt.wantErr)) {
+			t.Errorf("%s --output %s: error = %v, want %q", tt.cmd.Name(), tt.format, err, tt.wantErr)
+		}
+	}
+}
+
+func TestDescribeOutputFlag(t *testing.T) {
+	root := NewRootCmd()
+	for _, tt := range []struct {
+		args   []string
+		hidden bool
+		usage  string
+	}{
+		{[]string{"serve"}, true, "output format (table, json or yaml)"},
+		{[]string{"audit"}, false, "output format (table, json or yaml)"},
+		{[]string{"manifest", "diff"}, false, "output format (table, json or csv)"},
+	} {
+		cmd, _, err := root.Find(tt.args)
+		if err != nil {
+			t.Fatal(err)
+		}
+		describeOutputFlag(cmd)
+		f := cmd.Flag("output")
+		if f.Hidden != tt.hidden || f.Usage != tt.usage {
+			t.Errorf("%s: hidden = %v, usage = %q", cmd.CommandPath(), f.Hidden, f.Usage)
+		}
+	}
+}
@@ -0,0 +1,199 @@
+package main
+
+import (
+	"context"
+	"fmt"
+	"sort"
+	"strings"
+
+	"github.com/spf13/cobra"
+
+	"github.com/BadgerOps/airgap/internal/engine"
+	"github.com/BadgerOps/airgap/internal/provider"
+)
+
+var (
+	planProvider    string
+	planShowSkipped bool
+	planProbeSizes  bool
+)
+
+func newPlanCmd() *cobra.Command {
+	cmd := &cobra.Command{
+		Use:   "plan",
+		Short: "Show what a sync would download, update and delete",
+		Long: `Plan a sync without downloading content, writing files the providers
+generate or recording a sync run. Providers still query upstream metadata;
+epel refreshes its cached repository metadata while doing so. For each
+provider the plan lists every download, update and delete with its size and
+reason, followed by the total download size and the free space of the data
+directory.
+
+Files a provider builds itself, such as update graphs and pruned operator
+catalogs, are counted in the disk estimate. An operator catalog whose index
+has not been downloaded yet plans only the index; its bundle and related
+images are planned by the sync once the index is local, so that plan is
+marked incomplete.
+
+Without --provider, all enabled providers are planned. Files that are already
+up to date are counted but only listed with --show-skipped. Some providers,
+such as ocp_binaries, do not know file sizes before downloading; --probe-sizes
+sends a HEAD request for each of those files to complete the estimate.
+
+The command exits with 4 when a provider cannot be planned and with 5 when
+the data directory lacks the space the downloads need, so the JSON output can
+gate a CI job. The JSON and YAML documents carry a "version" field that
+changes only when fields are renamed or removed.`,
+		Example: `  airgap plan
+  airgap plan --provider epel,ocp-binaries
+  airgap plan --provider ocp-binaries --probe-sizes
+  airgap plan --provider rhcos --output json | jq '.disk.sufficient'`,
+		Annotations: map[string]string{outputAnnotation: structuredFormats},
+		RunE:        planRun,
+	}
+
+	cmd.Flags().StringVar(&planProvider, "provider", "", "comma-separated list of providers to plan")
+	cmd.Flags().BoolVar(&planShowSkipped, "show-skipped", false, "also list files that are already up to date")
+	cmd.Flags().BoolVar(&planProbeSizes, "probe-sizes", false, "ask upstream for the size of files the provider does not size")
+
+	return cmd
+}
+
+func planRun(cmd *cobra.Command, args []string) error {
+	if globalCfg == nil {
+		return fmt.Errorf("config not loaded")
+	}
+	if globalEngine == nil {
+		return fmt.Errorf("sync engine not initialized")
+	}
+	if cmd != nil {
+		cmd.SilenceUsage = true
+	}
+
+	var providers []string
+	if planProvider != "" {
+		for _, p := range strings.Split(planProvider, ",") {
+			providers = append(providers, strings.TrimSpace(p))
+		}
+	} else {
+		for name := range globalCfg.Providers {
+			if globalCfg.ProviderEnabled(name) {
+				providers = append(providers, name)
+			}
+		}
+		sort.Strings(providers)
+	}
+
+	report := globalEngine.PlanProviders(context.Background(), providers, engine.PlanOptions{ProbeSizes: planProbeSizes})
+	if !planShowSkipped {
+		for i := range report.Providers {
+			report.Providers[i].Actions = withoutSkips(report.Providers[i].Actions)
+		}
+	}
+
+	if !structuredOutput() {
+		printPlanTable(report)
+	} else if err := writeStructured(outputFormat, report); err != nil {
+		return err
+	}
+
+	if report.Errors > 0 {
+		return partialError(fmt.Errorf("%d provider(s) could not be planned", report.Errors))
+	}
+	if report.Disk.Error == "" && !report.Disk.Sufficient {
+		return insufficientSpaceError(fmt.Errorf("not enough disk space in %s: %s required, %s free",
+			report.Disk.Path, formatBytes(report.Disk.RequiredBytes), formatBytes(report.Disk.FreeBytes)))
+	}
+	return nil
+}
+
+func withoutSkips(actions []engine.PlanAction) []engine.PlanAction {
+	kept := actions[:0]
+	for _, a := range actions {
+		if a.Action != string(provider.ActionSkip) {
+			kept = append(kept, a)
+		}
+	}
+	return kept
+}
+
+func printPlanTable(report *engine.PlanReport) {
+	if len(report.Providers) == 0 {
+		fmt.Println("No providers to plan.")
+	}
+
+	for _, pp := range report.Providers {
+		fmt.Printf("\n%s:\n", pp.Provider)
+		if pp.Error != "" {
+			fmt.Printf("  ERROR: %s\n", pp.Error)
+			continue
+		}
+		if pp.SignatureStatus != "" {
+			fmt.Printf("  Signatures: %s\n", pp.SignatureStatus)
+		}
+		exprs := make([]string, 0, len(pp.Resolved))
+		for expr := range pp.Resolved {
+			exprs = append(exprs, expr)
+		}
+		sort.Strings(exprs)
+		for _, expr := range exprs {
+			fmt.Printf("  Resolved %s: %s\n", expr, strings.Join(pp.Resolved[expr], ", "))
+		}
+
+		if len(pp.Actions) == 0 {
+			fmt.Println("  Nothing to do.")
+		} else {
+			fmt.Printf("  %-9s %10s  %-60s %s\n", "ACTION", "SIZE", "PATH", "REASON")
+			fmt.Printf("  %s\n", strings.Repeat("-", 100))
+			for _, a := range pp.Actions {
+				fmt.Printf("  %-9s %10s  %-60s %s\n", a.Action, planSize(a.Size), a.Path, a.Reason)
+			}
+		}
+		for _, r := range pp.Rejected {
+			fmt.Printf("  REJECTED  %s: %s\n", r.Path, r.Error)
+		}
+		t := pp.Totals
+		fmt.Printf("  %d download(s), %d update(s), %d delete(s), %d up to date; %s to download\n",
+			t.Downloads, t.Updates, t.Deletes, t.Skips, formatBytes(t.DownloadBytes))
+		if t.Generated > 0 {
+			fmt.Printf("  %d generated file(s); %s to write\n", t.Generated, formatBytes(t.GeneratedBytes))
+		}
+		if pp.Incomplete {
+			fmt.Println("  Incomplete: more downloads are planned once these are done.")
+		}
+	}
+
+	t := report.Totals
+	fmt.Println("\n=== PLAN SUMMARY ===")
+	fmt.Printf("Downloads:   %d\n", t.Downloads)
+	fmt.Printf("Updates:     %d\n", t.Updates)
+	fmt.Printf("Deletes:     %d (%s freed)\n", t.Deletes, formatBytes(t.DeleteBytes))
+	fmt.Printf("Up to date:  %d\n", t.Skips)
+	if t.Generated > 0 {
+		fmt.Printf("Generated:   %d (%s)\n", t.Generated, formatBytes(t.GeneratedBytes))
+	}
+	fmt.Printf("To download: %s", formatBytes(t.DownloadBytes))
+	if t.UnknownSize > 0 {
+		fmt.Printf(" (+%d file(s) of unknown size)", t.UnknownSize)
+	}
+	fmt.Println()
+
+	d := report.Disk
+	if d.Error != "" {
+		fmt.Printf("Disk free:   unknown (%s)\n", d.Error)
+		return
+	}
+	status := "OK"
+	if !d.Sufficient {
+		status = "INSUFFICIENT"
+	}
+	fmt.Printf("Disk free:   %s in %s (%s)\n", formatBytes(d.FreeBytes), d.Path, status)
+}
+
+// planSize formats an action size; upstream does not always report one.
+func planSize(size int64) string {
+	if size <= 0 {
+		return "-"
+	}
+	return formatBytes(size)
+}
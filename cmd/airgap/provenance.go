@@ -0,0 +1,96 @@
+package main
+
+import (
+	"fmt"
+	"time"
+
+	"github.com/spf13/cobra"
+
+	"github.com/BadgerOps/airgap/internal/engine"
+)
+
+func newProvenanceCmd() *cobra.Command {
+	cmd := &cobra.Command{
+		Use:   "provenance <path>",
+		Short: "Show where a mirrored file came from",
+		Long: `Show the provenance recorded for a mirrored file: the URL it was requested
+from, the mirror host that served it, the upstream ETag and Last-Modified
+headers, the response time, when it was fetched and by which sync run.
+
+The path may be relative to its provider directory, prefixed with the provider
+name, or an absolute path under the data directory. Files that arrived by
+import show the provenance recorded on the exporting host.`,
+		Example: `  airgap provenance epel/9/Everything/x86_64/Packages/z/zsh-5.8-9.el9.x86_64.rpm
+  airgap provenance /var/lib/airgap/rhcos/4.17/rhcos-live.x86_64.iso`,
+		Args:        cobra.ExactArgs(1),
+		Annotations: map[string]string{outputAnnotation: structuredFormats},
+		RunE:        provenanceRun,
+	}
+
+	return cmd
+}
+
+func provenanceRun(cmd *cobra.Command, args []string) error {
+	if globalCfg == nil {
+		return fmt.Errorf("config not loaded")
+	}
+	if globalEngine == nil {
+		return fmt.Errorf("sync engine not initialized")
+	}
+
+	records, err := globalEngine.Provenance(args[0])
+	if err != nil {
+		return err
+	}
+	if structuredOutput() {
+		return writeStructured(outputFormat, &provenanceDocument{Version: outputVersion, Files: records})
+	}
+
+	for i, p := range records {
+		if i > 0 {
+			fmt.Println("")
+		}
+		fmt.Printf("%s/%s\n", p.Provider, p.Path)
+		fmt.Printf("  Size:           %s\n", formatBytes(p.Size))
+		fmt.Printf("  SHA256:         %s\n", p.SHA256)
+		if p.SourceURL == "" {
+			fmt.Println("  Source:         not downloaded (generated, scanned or imported without provenance)")
+		} else {
+			fmt.Printf("  Source URL:     %s\n", p.SourceURL)
+			fmt.Printf("  Mirror host:    %s\n", valueOr(p.MirrorHost, "unknown"))
+			fmt.Printf("  ETag:           %s\n", valueOr(p.ETag, "none"))
+			fmt.Printf("  Last-Modified:  %s\n", valueOr(p.UpstreamLastModified, "none"))
+			fmt.Printf("  Response time:  %s\n", time.Duration(p.ResponseTimeMS)*time.Millisecond)
+		}
+		fmt.Printf("  Fetched:        %s\n", p.FetchedAt.Format(time.RFC3339))
+		fmt.Printf("  Last verified:  %s\n", p.LastVerified.Format(time.RFC3339))
+		if p.SyncRunID != 0 {
+			run := fmt.Sprintf("#%d", p.SyncRunID)
+			if p.SyncStatus != "" {
+				run += " (" + p.SyncStatus + ")"
+			}
+			if p.SourceHost != "" {
+				run += " on " + p.SourceHost
+			}
+			fmt.Printf("  Sync run:       %s\n", run)
+		}
+		if p.SignatureStatus != "" {
+			fmt.Printf("  Signatures:     %s\n", p.SignatureStatus)
+		}
+	}
+	return nil
+}
+
+// provenanceDocument is the --output json|yaml form of airgap provenance.
+type provenanceDocument struct {
+	Version int                     `json:"version"`
+	Files   []engine.FileProvenance `json:"files"`
+}
+
+// valueOr returns v, or fallback when v is empty.
+func valueOr(v, fallback string) string {
+	if v == "" {
+		return fallback
+	}
+	return v
+}
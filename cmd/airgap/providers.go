@@ -1,11 +1,26 @@
 package main
 
 import (
+	"bytes"
+	"encoding/json"
+	"errors"
 	"fmt"
+	"net"
+	"net/http"
+	"net/url"
+	"os"
 	"sort"
+	"strconv"
 	"strings"
+	"syscall"
+	"time"
 
+	"github.com/BadgerOps/airgap/internal/audit"
+	"github.com/BadgerOps/airgap/internal/engine"
+	"github.com/BadgerOps/airgap/internal/imageset"
+	"github.com/BadgerOps/airgap/internal/store"
 	"github.com/spf13/cobra"
+	"gopkg.in/yaml.v3"
 )
 
 func newProvidersCmd() *cobra.Command {
@@ -14,20 +29,26 @@ func newProvidersCmd() *cobra.Command {
 		Short: "Inspect configured providers",
 		Long: `Inspect configured provider definitions stored in the local database.
 Use "providers list" to see names, types, and enabled state.`,
-		RunE: providersListRun,
+		Annotations: map[string]string{outputAnnotation: structuredFormats},
+		RunE:        providersListRun,
 	}
 
 	cmd.AddCommand(newProvidersListCmd())
+	cmd.AddCommand(newProvidersImportImagesetCmd())
+	cmd.AddCommand(newProvidersHistoryCmd())
+	cmd.AddCommand(newProvidersDiffCmd())
+	cmd.AddCommand(newProvidersRollbackCmd())
 	return cmd
 }
 
 func newProvidersListCmd() *cobra.Command {
 	return &cobra.Command{
-		Use:     "list",
-		Aliases: []string{"ls"},
-		Short:   "List configured providers",
-		Long:    "List all configured providers, including provider type and whether each one is enabled.",
-		RunE:    providersListRun,
+		Use:         "list",
+		Aliases:     []string{"ls"},
+		Short:       "List configured providers",
+		Long:        "List all configured providers, including provider type and whether each one is enabled.",
+		Annotations: map[string]string{outputAnnotation: structuredFormats},
+		RunE:        providersListRun,
 	}
 }
 
@@ -41,15 +62,27 @@ func providersListRun(cmd *cobra.Command, args []string) error {
 		return fmt.Errorf("listing provider configs: %w", err)
 	}
 
+	sort.Slice(configs, func(i, j int) bool {
+		return configs[i].Name < configs[j].Name
+	})
+
+	if structuredOutput() {
+		doc := &providersDocument{Version: outputVersion, Providers: make([]providerEntry, 0, len(configs))}
+		for _, pc := range configs {
+			entry := providerEntry{Name: pc.Name, Type: pc.Type, Enabled: pc.Enabled}
+			if globalRegistry != nil {
+				_, entry.Loaded = globalRegistry.Get(pc.Name)
+			}
+			doc.Providers = append(doc.Providers, entry)
+		}
+		return writeStructured(outputFormat, doc)
+	}
+
 	if len(configs) == 0 {
 		fmt.Println("No providers configured.")
 		return nil
 	}
 
-	sort.Slice(configs, func(i, j int) bool {
-		return configs[i].Name < configs[j].Name
-	})
-
 	fmt.Println("Configured Providers")
 	fmt.Println("====================")
 	fmt.Println("")
@@ -75,3 +108,381 @@ func providersListRun(cmd *cobra.Command, args []string) error {
 
 	return nil
 }
+
+// providersDocument is the --output json|yaml form of airgap providers list.
+type providersDocument struct {
+	Version   int             `json:"version"`
+	Providers []providerEntry `json:"providers"`
+}
+
+type providerEntry struct {
+	Name    string `json:"name"`
+	Type    string `json:"type"`
+	Enabled bool   `json:"enabled"`
+	Loaded  bool   `json:"loaded"`
+}
+
+func newProvidersImportImagesetCmd() *cobra.Command {
+	var prefix string
+	var dryRun bool
+
+	cmd := &cobra.Command{
+		Use:   "import-imageset FILE",
+		Short: "Create providers from an oc-mirror ImageSetConfiguration",
+		Long: `Translate an oc-mirror ImageSetConfiguration (mirror.openshift.io/v1alpha2 or
+v2alpha1) into airgap providers and store them in the local database.
+
+Platform channels become an ocp_clients provider, operators an operator_catalog
+provider, and additionalImages plus pinned release payloads a container_images
+provider. Every entry is listed with its support status; entries marked
+unsupported are not mirrored. Run "airgap sync --dry-run" afterwards to see the plans.`,
+		Example: `  airgap providers import-imageset imageset-config.yaml --dry-run
+  airgap providers import-imageset imageset-config.yaml --prefix prod`,
+		Args: cobra.ExactArgs(1),
+		RunE: func(cmd *cobra.Command, args []string) error {
+			return providersImportImagesetRun(args[0], prefix, dryRun)
+		},
+	}
+
+	cmd.Flags().StringVar(&prefix, "prefix", "imageset", "name prefix for created providers")
+	cmd.Flags().BoolVar(&dryRun, "dry-run", false, "print the translated providers without saving them")
+	return cmd
+}
+
+func providersImportImagesetRun(path, prefix string, dryRun bool) error {
+	data, err := os.ReadFile(path)
+	if err != nil {
+		return fmt.Errorf("reading %s: %w", path, err)
+	}
+	isc, err := imageset.Parse(data)
+	if err != nil {
+		return err
+	}
+	t := imageset.Translate(isc, prefix)
+
+	fmt.Println("ImageSetConfiguration Entries")
+	fmt.Println("=============================")
+	fmt.Println("")
+	fmt.Printf("%-22s %-48s %-12s %s\n", "Section", "Entry", "Status", "Note")
+	fmt.Println(strings.Repeat("-", 100))
+	for _, e := range t.Entries {
+		fmt.Printf("%-22s %-48s %-12s %s\n", e.Section, e.Name, e.Status, e.Note)
+	}
+	fmt.Println("")
+
+	if len(t.Providers) == 0 {
+		fmt.Println("No supported entries; no providers created.")
+		return nil
+	}
+
+	if dryRun {
+		out, err := yaml.Marshal(t.Providers)
+		if err != nil {
+			return fmt.Errorf("marshaling providers: %w", err)
+		}
+		fmt.Print(string(out))
+		return nil
+	}
+
+	if globalStore == nil {
+		return fmt.Errorf("store not initialized")
+	}
+	for _, p := range t.Providers {
+		configJSON, err := json.Marshal(p.Config)
+		if err != nil {
+			return fmt.Errorf("marshaling config for %s: %w", p.Name, err)
+		}
+		pc := &store.ProviderConfig{
+			Name:       p.Name,
+			Type:       p.Type,
+			Enabled:    true,
+			ConfigJSON: string(configJSON),
+		}
+		err = globalStore.CreateProviderConfig(pc)
+		ev := cliAuditEvent("provider.create", p.Name)
+		ev.Outcome, ev.Detail = audit.Outcome(err)
+		if err == nil {
+			ev.After, ev.Detail = audit.ProviderState(pc), "imported from "+path
+		}
+		cliAuditRecorder().Record(ev)
+		if err != nil {
+			return fmt.Errorf("creating provider %s: %w", p.Name, err)
+		}
+		if _, err := globalStore.AddProviderConfigRevision(p.Name, cliActor(), "imported from "+path); err != nil {
+			return fmt.Errorf("recording revision of %s: %w", p.Name, err)
+		}
+		fmt.Printf("Created provider %s (%s)\n", p.Name, p.Type)
+	}
+	return nil
+}
+
+func newProvidersHistoryCmd() *cobra.Command {
+	return &cobra.Command{
+		Use:   "history NAME",
+		Short: "Show the config revision history of a provider",
+		Long: `List every saved revision of a provider config, newest first, with the
+author and change note. Use "providers diff" to compare two and
+"providers rollback" to restore one.`,
+		Args:        cobra.ExactArgs(1),
+		Annotations: map[string]string{outputAnnotation: structuredFormats},
+		RunE: func(cmd *cobra.Command, args []string) error {
+			return providersHistoryRun(args[0])
+		},
+	}
+}
+
+func providersHistoryRun(name string) error {
+	if globalStore == nil {
+		return fmt.Errorf("store not initialized")
+	}
+	revs, err := globalStore.ListProviderConfigRevisions(name)
+	if err != nil {
+		return fmt.Errorf("listing revisions: %w", err)
+	}
+	if len(revs) == 0 {
+		return fmt.Errorf("no revisions for provider %s", name)
+	}
+	if structuredOutput() {
+		doc := &historyDocument{Version: outputVersion, Provider: name, Revisions: make([]revisionEntry, 0, len(revs))}
+		for _, rev := range revs {
+			doc.Revisions = append(doc.Revisions, revisionEntry{
+				Revision:  rev.Revision,
+				Type:      rev.Type,
+				Enabled:   rev.Enabled,
+				Author:    rev.Author,
+				Comment:   rev.Comment,
+				CreatedAt: rev.CreatedAt,
+			})
+		}
+		return writeStructured(outputFormat, doc)
+	}
+
+	fmt.Printf("Config History: %s\n", name)
+	fmt.Println(strings.Repeat("=", 16+len(name)))
+	fmt.Println("")
+	fmt.Printf("%-5s %-20s %-16s %-9s %s\n", "Rev", "Saved", "Author", "State", "Comment")
+	fmt.Println(strings.Repeat("-", 80))
+	for _, rev := range revs {
+		state := "disabled"
+		if rev.Enabled {
+			state = "enabled"
+		}
+		fmt.Printf("%-5d %-20s %-16s %-9s %s\n", rev.Revision,
+			rev.CreatedAt.Local().Format("2006-01-02 15:04:05"), rev.Author, state, rev.Comment)
+	}
+	fmt.Println("")
+	return nil
+}
+
+// historyDocument is the --output json|yaml form of airgap providers history.
+type historyDocument struct {
+	Version   int             `json:"version"`
+	Provider  string          `json:"provider"`
+	Revisions []revisionEntry `json:"revisions"`
+}
+
+type revisionEntry struct {
+	Revision  int       `json:"revision"`
+	Type      string    `json:"type"`
+	Enabled   bool      `json:"enabled"`
+	Author    string    `json:"author"`
+	Comment   string    `json:"comment,omitempty"`
+	CreatedAt time.Time `json:"created_at"`
+}
+
+func newProvidersDiffCmd() *cobra.Command {
+	return &cobra.Command{
+		Use:   "diff NAME FROM TO",
+		Short: "Compare two revisions of a provider config",
+		Long: `List the settings that changed between two revisions of a provider config.
+Credential values are redacted.`,
+		Example: `  airgap providers diff epel 3 5
+  airgap providers diff epel 3 5 --output json`,
+		Args:        cobra.ExactArgs(3),
+		Annotations: map[string]string{outputAnnotation: structuredFormats},
+		RunE: func(cmd *cobra.Command, args []string) error {
+			from, err := strconv.Atoi(args[1])
+			if err != nil {
+				return usageError(fmt.Errorf("invalid revision %q", args[1]))
+			}
+			to, err := strconv.Atoi(args[2])
+			if err != nil {
+				return usageError(fmt.Errorf("invalid revision %q", args[2]))
+			}
+			return providersDiffRun(args[0], from, to)
+		},
+	}
+}
+
+func providersDiffRun(name string, from, to int) error {
+	if globalEngine == nil {
+		return fmt.Errorf("sync engine not initialized")
+	}
+	diff, err := globalEngine.DiffProviderConfigRevisions(name, from, to)
+	if err != nil {
+		return fmt.Errorf("comparing revisions: %w", err)
+	}
+	if structuredOutput() {
+		return writeStructured(outputFormat, &revisionDiffDocument{Version: outputVersion, RevisionDiff: diff})
+	}
+
+	if len(diff.Changes) == 0 {
+		fmt.Printf("No changes between revisions %d and %d of %s.\n", from, to, name)
+		return nil
+	}
+	fmt.Printf("%s: revision %d -> %d\n", name, from, to)
+	for _, c := range diff.Changes {
+		fmt.Printf("  %s: %s -> %s\n", c.Path, diffValue(c.Before), diffValue(c.After))
+	}
+	return nil
+}
+
+// revisionDiffDocument is the --output json|yaml form of airgap providers diff.
+type revisionDiffDocument struct {
+	Version int `json:"version"`
+	*engine.RevisionDiff
+}
+
+// diffValue formats a changed config value as JSON; absent values print
+// as "(unset)".
+func diffValue(v interface{}) string {
+	if v == nil {
+		return "(unset)"
+	}
+	data, err := json.Marshal(v)
+	if err != nil {
+		return fmt.Sprint(v)
+	}
+	return string(data)
+}
+
+func newProvidersRollbackCmd() *cobra.Command {
+	var revision int
+	var comment, server string
+	var local bool
+
+	cmd := &cobra.Command{
+		Use:   "rollback NAME --to REVISION",
+		Short: "Restore a provider config to an earlier revision",
+		Long: `Restore a provider config to an earlier revision. The rollback is saved as a
+new revision, so it can itself be undone. A deleted provider is recreated.
+
+The rollback is sent to the running "airgap serve" at --server (default: the
+configured server.listen address), which saves it and reloads its providers.
+The server records the request's actor like any API call. Without --server,
+when nothing listens on the configured address, the rollback is saved to the
+database directly and a server started later loads it. Any other failure to
+reach the server is an error. --local always saves directly.`,
+		Example: `  airgap providers history epel
+  airgap providers rollback epel --to 3 --comment "mirror went offline"
+  airgap providers rollback epel --to 3 --server http://mirror.example.com:8080`,
+		Args: cobra.ExactArgs(1),
+		RunE: func(cmd *cobra.Command, args []string) error {
+			cmd.SilenceUsage = true
+			if local {
+				return providersRollbackRun(args[0], revision, comment)
+			}
+			if server == "" {
+				return providersRollbackViaServer(localServerURL(), args[0], revision, comment, true)
+			}
+			return providersRollbackViaServer(server, args[0], revision, comment, false)
+		},
+	}
+
+	cmd.Flags().IntVar(&revision, "to", 0, "revision number to restore (required)")
+	cmd.Flags().StringVar(&comment, "comment", "", "change note for the rollback revision")
+	cmd.Flags().StringVar(&server, "server", "", "URL of the running airgap serve (default: from server.listen)")
+	cmd.Flags().BoolVar(&local, "local", false, "save to the database without contacting a running server")
+	_ = cmd.MarkFlagRequired("to")
+	return cmd
+}
+
+// localServerURL returns the URL of the server configured by server.listen,
+// reached over loopback when it listens on all addresses.
+func localServerURL() string {
+	listen := "0.0.0.0:8080"
+	if globalCfg != nil && globalCfg.Server.Listen != "" {
+		listen = globalCfg.Server.Listen
+	}
+	host, port, err := net.SplitHostPort(listen)
+	if err != nil {
+		return "http://" + listen
+	}
+	if host == "" || host == "0.0.0.0" || host == "::" {
+		host = "127.0.0.1"
+	}
+	return "http://" + net.JoinHostPort(host, port)
+}
+
+// providersRollbackViaServer asks the airgap serve at serverURL to roll
+// back provider name, so it reloads its providers. With localFallback, a
+// refused connection means no server is running and the rollback is made
+// with providersRollbackRun instead.
+func providersRollbackViaServer(serverURL, name string, revision int, comment string, localFallback bool) error {
+	body, err := json.Marshal(map[string]interface{}{"revision": revision, "comment": comment})
+	if err != nil {
+		return err
+	}
+	endpoint := strings.TrimRight(serverURL, "/") + "/api/providers/config/" + url.PathEscape(name) + "/rollback"
+	req, err := http.NewRequest(http.MethodPost, endpoint, bytes.NewReader(body))
+	if err != nil {
+		return fmt.Errorf("invalid --server: %w", err)
+	}
+	req.Header.Set("Content-Type", "application/json")
+
+	client := &http.Client{Timeout: 60 * time.Second}
+	resp, err := client.Do(req)
+	if localFallback && errors.Is(err, syscall.ECONNREFUSED) {
+		fmt.Printf("No airgap serve is listening at %s; rolling back locally in the database.\n", serverURL)
+		return providersRollbackRun(name, revision, comment)
+	}
+	if err != nil {
+		return fmt.Errorf("rolling back %s via %s: %w", name, serverURL, err)
+	}
+	defer func() { _ = resp.Body.Close() }()
+
+	var result struct {
+		Revision int    `json:"revision"`
+		Error    string `json:"error"`
+	}
+	if err := json.NewDecoder(resp.Body).Decode(&result); err != nil || resp.StatusCode != http.StatusOK {
+		if result.Error == "" {
+			result.Error = resp.Status
+		}
+		return fmt.Errorf("rolling back %s via %s: %s", name, serverURL, result.Error)
+	}
+
+	fmt.Printf("Restored provider %s to revision %d (saved as revision %d); %s reloaded its providers\n",
+		name, revision, result.Revision, serverURL)
+	return nil
+}
+
+// providersRollbackRun rolls back provider name in the database directly.
+func providersRollbackRun(name string, revision int, comment string) error {
+	if globalStore == nil {
+		return fmt.Errorf("store not initialized")
+	}
+	if globalEngine == nil {
+		return fmt.Errorf("sync engine not initialized")
+	}
+
+	ev := cliAuditEvent("provider.rollback", name)
+	if existing, err := globalStore.GetProviderConfig(name); err == nil {
+		ev.Before = audit.ProviderState(existing)
+	}
+	rev, err := globalEngine.RollbackProviderConfig(name, revision, cliActor(), comment)
+	ev.Outcome, ev.Detail = audit.Outcome(err)
+	if err == nil {
+		ev.Detail = fmt.Sprintf("to revision %d", revision)
+		if pc, err := globalStore.GetProviderConfig(name); err == nil {
+			ev.After = audit.ProviderState(pc)
+		}
+	}
+	cliAuditRecorder().Record(ev)
+	if err != nil {
+		return fmt.Errorf("rolling back %s: %w", name, err)
+	}
+
+	fmt.Printf("Restored provider %s to revision %d (saved as revision %d)\n", name, revision, rev.Revision)
+	return nil
+}
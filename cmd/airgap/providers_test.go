@@ -2,12 +2,18 @@ package main
 
 import (
 	"context"
+	"encoding/json"
 	"io"
 	"log/slog"
+	"net/http"
+	"net/http/httptest"
 	"os"
 	"strings"
 	"testing"
 
+	"github.com/BadgerOps/airgap/internal/config"
+	"github.com/BadgerOps/airgap/internal/download"
+	"github.com/BadgerOps/airgap/internal/engine"
 	"github.com/BadgerOps/airgap/internal/provider"
 	"github.com/BadgerOps/airgap/internal/store"
 )
@@ -69,7 +75,7 @@ func TestProvidersListRun_ShowsConfiguredProviders(t *testing.T) {
 	}
 }
 
-func newTestStore(t *testing.T) *store.Store {
+func newTestStore(t *testing.T) store.Store {
 	t.Helper()
 	st, err := store.New(":memory:", slog.New(slog.NewTextHandler(io.Discard, nil)))
 	if err != nil {
@@ -79,7 +85,7 @@ func newTestStore(t *testing.T) *store.Store {
 	return st
 }
 
-func mustCreateProviderConfig(t *testing.T, st *store.Store, name, typ string, enabled bool) {
+func mustCreateProviderConfig(t *testing.T, st store.Store, name, typ string, enabled bool) {
 	t.Helper()
 	pc := &store.ProviderConfig{
 		Name:       name,
@@ -134,3 +140,109 @@ func (s *stubProvider) Sync(ctx context.Context, plan *provider.SyncPlan, opts p
 func (s *stubProvider) Validate(ctx context.Context) (*provider.ValidationReport, error) {
 	return nil, nil
 }
+
+func TestProvidersImportImagesetRun(t *testing.T) {
+	st := newTestStore(t)
+	origStore := globalStore
+	globalStore = st
+	t.Cleanup(func() { globalStore = origStore })
+
+	path := t.TempDir() + "/imageset-config.yaml"
+	isc := "kind: ImageSetConfiguration\napiVersion: mirror.openshift.io/v2alpha1\nmirror:\n  additionalImages:\n  - name: registry.access.redhat.com/ubi9/ubi:latest\n"
+	if err := os.WriteFile(path, []byte(isc), 0o644); err != nil {
+		t.Fatal(err)
+	}
+
+	out := captureStdout(t, func() {
+		if err := providersImportImagesetRun(path, "team", true); err != nil {
+			t.Fatalf("dry run returned error: %v", err)
+		}
+	})
+	if !strings.Contains(out, "team-images") || !strings.Contains(out, "supported") {
+		t.Fatalf("unexpected dry-run output: %s", out)
+	}
+	if n, _ := st.CountProviderConfigs(); n != 0 {
+		t.Fatalf("dry run created %d providers", n)
+	}
+
+	captureStdout(t, func() {
+		if err := providersImportImagesetRun(path, "team", false); err != nil {
+			t.Fatalf("import returned error: %v", err)
+		}
+	})
+	pc, err := st.GetProviderConfig("team-images")
+	if err != nil {
+		t.Fatalf("provider not created: %v", err)
+	}
+	if pc.Type != "container_images" {
+		t.Errorf("unexpected type %q", pc.Type)
+	}
+}
+
+func TestProvidersRollbackViaServer(t *testing.T) {
+	var gotPath, gotActor string
+	var gotBody map[string]interface{}
+	srv := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		gotPath, gotActor = r.URL.Path, r.Header.Get("X-Remote-User")
+		_ = json.NewDecoder(r.Body).Decode(&gotBody)
+		if gotBody["revision"] != float64(3) {
+			w.WriteHeader(http.StatusNotFound)
+			_, _ = w.Write([]byte(`{"error":"revision not found"}`))
+			return
+		}
+		_, _ = w.Write([]byte(`{"provider":"epel","revision":5}`))
+	}))
+	defer srv.Close()
+
+	out := captureStdout(t, func() {
+		if err := providersRollbackViaServer(srv.URL, "epel", 3, "bad mirror", false); err != nil {
+			t.Fatalf("providersRollbackViaServer returned error: %v", err)
+		}
+	})
+	// The server decides the actor; the CLI must not assert one.
+	if gotPath != "/api/providers/config/epel/rollback" || gotActor != "" || gotBody["comment"] != "bad mirror" {
+		t.Errorf("request = %s as %q with %v", gotPath, gotActor, gotBody)
+	}
+	if !strings.Contains(out, "saved as revision 5") || !strings.Contains(out, "reloaded its providers") {
+		t.Errorf("unexpected output: %s", out)
+	}
+
+	err := providersRollbackViaServer(srv.URL, "epel", 9, "", false)
+	if err == nil || !strings.Contains(err.Error(), "revision not found") {
+		t.Errorf("expected the server error, got %v", err)
+	}
+}
+
+func TestProvidersRollbackViaServerFallsBack(t *testing.T) {
+	st := newTestStore(t)
+	mustCreateProviderConfig(t, st, "epel", "epel", true)
+	if _, err := st.AddProviderConfigRevision("epel", "alice", ""); err != nil {
+		t.Fatal(err)
+	}
+
+	origStore, origEngine := globalStore, globalEngine
+	globalStore = st
+	globalEngine = engine.NewSyncManager(provider.NewRegistry(), st, download.NewClient(slog.Default()), config.DefaultConfig(), slog.Default())
+	t.Cleanup(func() { globalStore, globalEngine = origStore, origEngine })
+
+	// Nothing listens on a closed test server's address.
+	srv := httptest.NewServer(http.NotFoundHandler())
+	srv.Close()
+
+	// An explicit --server that is down is an error, not a local write.
+	if err := providersRollbackViaServer(srv.URL, "epel", 1, "", false); err == nil {
+		t.Error("expected an error for an unreachable --server")
+	}
+	if revs, err := st.ListProviderConfigRevisions("epel"); err != nil || len(revs) != 1 {
+		t.Errorf("revisions after failed rollback = %d, %v; want 1", len(revs), err)
+	}
+
+	out := captureStdout(t, func() {
+		if err := providersRollbackViaServer(srv.URL, "epel", 1, "", true); err != nil {
+			t.Fatalf("providersRollbackViaServer returned error: %v", err)
+		}
+	})
+	if !strings.Contains(out, "saved as revision 2") || !strings.Contains(out, "rolling back locally") {
+		t.Errorf("unexpected output: %s", out)
+	}
+}
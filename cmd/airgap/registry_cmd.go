@@ -29,13 +29,18 @@ func newRegistryPushCmd() *cobra.Command {
 		Use:   "push",
 		Short: "Push mirrored container images to a destination registry",
 		Long: `Pushes images mirrored by a container_images provider into a target registry
-provider definition (credentials and endpoint configured in provider settings).`,
+provider definition (credentials and endpoint configured in provider settings).
+
+An operator_catalog provider can also be used as the source: its pruned catalog
+images are pushed under the original index reference, followed by every
+bundle and related image selected by the last sync.`,
 		Example: `  airgap registry push --source-provider container-images --target-provider quay-prod
+  airgap registry push --source-provider redhat-operators --target-provider quay-prod
   airgap registry push --source-provider container-images --target-provider lab-registry --dry-run`,
 		RunE: registryPushRun,
 	}
 
-	cmd.Flags().StringVar(&registryPushSource, "source-provider", "", "container_images provider name to push from (required)")
+	cmd.Flags().StringVar(&registryPushSource, "source-provider", "", "container_images or operator_catalog provider name to push from (required)")
 	cmd.Flags().StringVar(&registryPushTarget, "target-provider", "", "registry provider name to push to (required)")
 	cmd.Flags().BoolVar(&registryPushDryRun, "dry-run", false, "plan the push without executing skopeo copy")
 	_ = cmd.MarkFlagRequired("source-provider")
@@ -60,9 +65,12 @@ func registryPushRun(cmd *cobra.Command, args []string) error {
 		TargetProvider: registryPushTarget,
 		DryRun:         registryPushDryRun,
 	})
+	var detail string
 	if report != nil {
 		printRegistryPushReport(report)
+		detail = fmt.Sprintf("dry_run=%t pushed %d/%d images", registryPushDryRun, report.ImagesPushed, report.ImagesTotal)
 	}
+	recordAudit("registry.push", registryPushSource+" -> "+registryPushTarget, detail, err)
 	if err != nil {
 		return fmt.Errorf("registry push failed: %w", err)
 	}
@@ -77,7 +85,16 @@ func printRegistryPushReport(report *engine.RegistryPushReport) {
 	fmt.Printf("  Images pushed: %d\n", report.ImagesPushed)
 	fmt.Printf("  Blobs processed: %d\n", report.BlobsProcessed)
 	fmt.Printf("  Manifests pushed: %d\n", report.ManifestsPushed)
+	if report.ArtifactsPushed > 0 {
+		fmt.Printf("  Signatures/referrers pushed: %d\n", report.ArtifactsPushed)
+	}
 	fmt.Printf("  Duration: %s\n", report.Duration.Round(time.Second))
+	if len(report.MirrorConfigFiles) > 0 {
+		fmt.Printf("  Mirror config: %s\n", report.MirrorConfigDir)
+		for _, f := range report.MirrorConfigFiles {
+			fmt.Printf("    - %s\n", f)
+		}
+	}
 	if len(report.Failures) > 0 {
 		fmt.Printf("  Failures: %d\n", len(report.Failures))
 		for _, f := range report.Failures {
@@ -15,6 +15,7 @@ import (
 	"github.com/BadgerOps/airgap/internal/provider/containerimages"
 	"github.com/BadgerOps/airgap/internal/provider/epel"
 	"github.com/BadgerOps/airgap/internal/provider/ocp"
+	"github.com/BadgerOps/airgap/internal/provider/operatorcatalog"
 	registryprovider "github.com/BadgerOps/airgap/internal/provider/registry"
 	"github.com/BadgerOps/airgap/internal/store"
 	"github.com/spf13/cobra"
@@ -31,7 +32,7 @@ var (
 	logger    *slog.Logger
 
 	// Global components
-	globalStore    *store.Store
+	globalStore    store.Store
 	globalEngine   *engine.SyncManager
 	globalRegistry *provider.Registry
 )
@@ -48,14 +49,17 @@ func initializeComponents() error {
 	}
 
 	// Initialize store
-	dbPath := globalCfg.Server.DBPath
-	if dbPath == "" {
-		dbPath = filepath.Join(globalCfg.Server.DataDir, "airgap.db")
-	}
-	if err := os.MkdirAll(filepath.Dir(dbPath), 0o755); err != nil {
-		return fmt.Errorf("failed to create database directory: %w", err)
+	dsn := globalCfg.Database.DSN
+	if globalCfg.Database.Driver != store.DriverPostgres {
+		dsn = globalCfg.Server.DBPath
+		if dsn == "" {
+			dsn = filepath.Join(globalCfg.Server.DataDir, "airgap.db")
+		}
+		if err := os.MkdirAll(filepath.Dir(dsn), 0o755); err != nil {
+			return fmt.Errorf("failed to create database directory: %w", err)
+		}
 	}
-	st, err := store.New(dbPath, logger)
+	st, err := store.Open(globalCfg.Database.Driver, dsn, logger)
 	if err != nil {
 		return fmt.Errorf("failed to initialize store: %w", err)
 	}
@@ -160,6 +164,8 @@ func createProvider(typeName, dataDir string, log *slog.Logger) (provider.Provid
 		return ocp.NewRHCOSProvider(dataDir, log), nil
 	case "container_images":
 		return containerimages.NewProvider(dataDir, log), nil
+	case "operator_catalog":
+		return operatorcatalog.NewProvider(dataDir, log), nil
 	case "registry":
 		return registryprovider.NewProvider(dataDir, log), nil
 	case "custom_files":
@@ -185,10 +191,22 @@ registries, and custom file sources.`,
   airgap export --to /mnt/transfer --provider container-images
   airgap status --provider epel`,
 		Version: fmt.Sprintf("%s (commit %s, built %s)", version, commit, buildTime),
+		// main prints errors so it can add structured error documents.
+		SilenceErrors: true,
 		PersistentPreRunE: func(cmd *cobra.Command, args []string) error {
+			commandStarted = true
+
 			// Initialize logging
 			setupLogging()
 
+			if err := checkCommandOutput(cmd); err != nil {
+				return usageError(err)
+			}
+			if structuredOutput() {
+				// Keep stderr to the error message for scripts.
+				cmd.SilenceUsage = true
+			}
+
 			// Skip config loading for commands that don't need it
 			if shouldSkipConfig(cmd.Name()) {
 				return nil
@@ -207,7 +225,7 @@ registries, and custom file sources.`,
 				var err error
 				globalCfg, err = config.Load(cfgPath)
 				if err != nil {
-					return fmt.Errorf("failed to load config: %w", err)
+					return configError(fmt.Errorf("failed to load config: %w", err))
 				}
 			} else {
 				globalCfg = config.DefaultConfig()
@@ -225,7 +243,7 @@ registries, and custom file sources.`,
 			// Initialize components after config is loaded
 			if !shouldSkipComponentInit(cmd.Name()) {
 				if err := initializeComponents(); err != nil {
-					return fmt.Errorf("failed to initialize components: %w", err)
+					return configError(fmt.Errorf("failed to initialize components: %w", err))
 				}
 			}
 
@@ -242,10 +260,22 @@ registries, and custom file sources.`,
 	cmd.PersistentFlags().StringVar(&logLevel, "log-level", "info", "log level (debug, info, warn, error)")
 	cmd.PersistentFlags().StringVar(&logFormat, "log-format", "text", "log format (text or json)")
 	cmd.PersistentFlags().BoolVar(&quiet, "quiet", false, "suppress non-error output")
+	cmd.PersistentFlags().StringVarP(&outputFormat, "output", "o", outputTable, "output format (table, json or yaml)")
+
+	defaultHelp, defaultUsage := cmd.HelpFunc(), cmd.UsageFunc()
+	cmd.SetHelpFunc(func(c *cobra.Command, args []string) {
+		describeOutputFlag(c)
+		defaultHelp(c, args)
+	})
+	cmd.SetUsageFunc(func(c *cobra.Command) error {
+		describeOutputFlag(c)
+		return defaultUsage(c)
+	})
 
 	// Add subcommands
 	cmd.AddCommand(
 		newSyncCmd(),
+		newPlanCmd(),
 		newValidateCmd(),
 		newServeCmd(),
 		newStatusCmd(),
@@ -254,6 +284,11 @@ registries, and custom file sources.`,
 		newExportCmd(),
 		newImportCmd(),
 		newConfigCmd(),
+		newDBCmd(),
+		newProvenanceCmd(),
+		newManifestCmd(),
+		newSearchCmd(),
+		newAuditCmd(),
 	)
 
 	return cmd
@@ -0,0 +1,157 @@
+package main
+
+import (
+	"fmt"
+	"strings"
+
+	"github.com/spf13/cobra"
+
+	"github.com/BadgerOps/airgap/internal/engine"
+	"github.com/BadgerOps/airgap/internal/store"
+)
+
+var (
+	searchProvider string
+	searchType     string
+	searchVersion  string
+	searchLimit    int
+	searchReindex  bool
+)
+
+func newSearchCmd() *cobra.Command {
+	cmd := &cobra.Command{
+		Use:   "search [query]",
+		Short: "Search mirrored packages, images and artifacts",
+		Long: `Search the index of mirrored content: RPMs by name, version and arch,
+container images by repository, tag and digest, and OpenShift binaries, clients
+and RHCOS artifacts by name and version. Each word of the query matches the
+start of a word in the indexed fields.
+
+The index is updated after every sync and import. Use --reindex to rebuild it
+for all providers from the data on disk, for example after an upgrade.`,
+		Example: `  airgap search zsh
+  airgap search openshift-install --version 4.17
+  airgap search --type image --provider container-images
+  airgap search --reindex`,
+		Args:        cobra.MaximumNArgs(1),
+		Annotations: map[string]string{outputAnnotation: structuredFormats},
+		RunE:        searchRun,
+	}
+
+	cmd.Flags().StringVar(&searchProvider, "provider", "", "only search this provider")
+	cmd.Flags().StringVar(&searchType, "type", "", "only search this type (rpm, image, ocp_binary, ocp_client, rhcos)")
+	cmd.Flags().StringVar(&searchVersion, "version", "", "only match this version or versions under it (4.17 matches 4.17.3)")
+	cmd.Flags().IntVar(&searchLimit, "limit", 50, "maximum number of results")
+	cmd.Flags().BoolVar(&searchReindex, "reindex", false, "rebuild the search index before searching")
+
+	return cmd
+}
+
+func searchRun(cmd *cobra.Command, args []string) error {
+	if globalCfg == nil {
+		return fmt.Errorf("config not loaded")
+	}
+	if globalEngine == nil {
+		return fmt.Errorf("sync engine not initialized")
+	}
+	if globalStore == nil {
+		return fmt.Errorf("store not initialized")
+	}
+
+	q := store.SearchQuery{
+		Provider: searchProvider,
+		Type:     searchType,
+		Version:  searchVersion,
+		Limit:    searchLimit,
+	}
+	if len(args) == 1 {
+		q.Text = args[0]
+	}
+
+	hasQuery := q.Text != "" || q.Provider != "" || q.Type != "" || q.Version != ""
+	if !hasQuery && !searchReindex {
+		return fmt.Errorf("a query or a --provider, --type or --version filter is required")
+	}
+
+	doc := &searchDocument{Version: outputVersion, Results: []engine.SearchResult{}}
+	if searchReindex {
+		configs, err := globalStore.ListProviderConfigs()
+		if err != nil {
+			return fmt.Errorf("listing providers: %w", err)
+		}
+		failed := 0
+		for _, pc := range configs {
+			n, err := globalEngine.RebuildSearchIndex(pc.Name)
+			entry := reindexEntry{Provider: pc.Name, Entries: n}
+			if err != nil {
+				entry.Error = err.Error()
+				failed++
+			}
+			doc.Reindexed = append(doc.Reindexed, entry)
+			if structuredOutput() {
+				continue
+			}
+			if err != nil {
+				fmt.Printf("Indexing %s failed: %v\n", pc.Name, err)
+				continue
+			}
+			fmt.Printf("Indexed %s: %d entries\n", pc.Name, n)
+		}
+		if failed > 0 {
+			if structuredOutput() {
+				if err := writeStructured(outputFormat, doc); err != nil {
+					return err
+				}
+			}
+			return partialError(fmt.Errorf("%d provider(s) failed to index", failed))
+		}
+		if !hasQuery {
+			if structuredOutput() {
+				return writeStructured(outputFormat, doc)
+			}
+			return nil
+		}
+		if !structuredOutput() {
+			fmt.Println("")
+		}
+	}
+
+	results, err := globalEngine.Search(q)
+	if err != nil {
+		return err
+	}
+	if structuredOutput() {
+		doc.Results = append(doc.Results, results...)
+		return writeStructured(outputFormat, doc)
+	}
+	if len(results) == 0 {
+		fmt.Println("No matches.")
+		return nil
+	}
+
+	fmt.Printf("%-40s %-24s %-8s %-10s %-18s %s\n", "Name", "Version", "Arch", "Type", "Provider", "Path / Digest")
+	fmt.Println(strings.Repeat("-", 120))
+	for _, r := range results {
+		location := r.Path
+		if r.Digest != "" {
+			location = r.Digest
+		}
+		fmt.Printf("%-40s %-24s %-8s %-10s %-18s %s\n",
+			r.Name, valueOr(r.Version, "-"), valueOr(r.Arch, "-"), r.Type, r.Provider, location)
+	}
+	return nil
+}
+
+// searchDocument is the --output json|yaml form of airgap search.
+type searchDocument struct {
+	Version   int                   `json:"version"`
+	Reindexed []reindexEntry        `json:"reindexed,omitempty"`
+	Results   []engine.SearchResult `json:"results"`
+}
+
+// reindexEntry is a provider whose search index --reindex rebuilt.
+type reindexEntry struct {
+	Provider string `json:"provider"`
+	Entries  int    `json:"entries"`
+	Error    string `json:"error,omitempty"`
+}
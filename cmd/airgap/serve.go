@@ -9,6 +9,8 @@ import (
 	"syscall"
 	"time"
 
+	"github.com/BadgerOps/airgap/internal/engine"
+	"github.com/BadgerOps/airgap/internal/pxe"
 	"github.com/BadgerOps/airgap/internal/server"
 	"github.com/spf13/cobra"
 )
@@ -26,7 +28,10 @@ func newServeCmd() *cobra.Command {
 a web UI dashboard and REST API endpoints for managing offline content.
 
 By default, the server listens on the address configured in the config file
-(default: 0.0.0.0:8080). Use --listen to override.`,
+(default: 0.0.0.0:8080). Use --listen to override.
+
+With boot.enabled set, mirrored RHCOS images are also served for PXE/iPXE
+booting, and boot.tftp starts a TFTP server for chainloading iPXE.`,
 		Example: `  airgap serve
   airgap serve --listen 127.0.0.1:9000
   airgap serve --dev`,
@@ -64,6 +69,33 @@ func serveRun(cmd *cobra.Command, args []string) error {
 	// Channel to listen for errors from server
 	errChan := make(chan error, 1)
 
+	// Optional TFTP server for chainloading iPXE
+	tftp, err := newBootTFTPServer()
+	if err != nil {
+		return err
+	}
+	if tftp != nil {
+		go func() {
+			if err := tftp.ListenAndServe(globalCfg.Boot.TFTP.Listen); err != nil {
+				errChan <- fmt.Errorf("tftp: %w", err)
+			}
+		}()
+		defer func() {
+			_ = tftp.Close()
+		}()
+	}
+
+	// Scheduled database maintenance
+	interval, err := engine.MaintenanceInterval(globalCfg.Database)
+	if err != nil {
+		return err
+	}
+	if interval > 0 {
+		maintCtx, stopMaintenance := context.WithCancel(context.Background())
+		defer stopMaintenance()
+		go globalEngine.RunMaintenance(maintCtx, interval)
+	}
+
 	// Start the server in a goroutine
 	go func() {
 		fmt.Printf("Starting server on %s...\n", serveListen)
@@ -96,3 +128,24 @@ func serveRun(cmd *cobra.Command, args []string) error {
 
 	return nil
 }
+
+// newBootTFTPServer returns the TFTP server configured under boot.tftp, or
+// nil when it is disabled. Besides the files in boot.tftp.root it serves
+// autoexec.ipxe and boot.ipxe scripts chaining to the HTTP boot menu.
+func newBootTFTPServer() (*pxe.TFTPServer, error) {
+	boot := globalCfg.Boot
+	if !boot.Enabled || !boot.TFTP.Enabled {
+		return nil, nil
+	}
+	if boot.BaseURL == "" {
+		return nil, fmt.Errorf("boot.base_url is required when boot.tftp is enabled")
+	}
+	if boot.TFTP.Listen == "" {
+		return nil, fmt.Errorf("boot.tftp.listen is required when boot.tftp is enabled")
+	}
+	chain := []byte(pxe.ChainScript(boot.BaseURL))
+	return pxe.NewTFTPServer(boot.TFTP.Root, map[string][]byte{
+		"autoexec.ipxe": chain,
+		"boot.ipxe":     chain,
+	}, logger), nil
+}
@@ -3,8 +3,10 @@ package main
 import (
 	"fmt"
 	"log/slog"
+	"sort"
 	"strings"
 
+	"github.com/BadgerOps/airgap/internal/engine"
 	"github.com/spf13/cobra"
 )
 
@@ -25,8 +27,10 @@ providers with failures.`,
 		Example: `  airgap status
   airgap status --provider epel
   airgap status --provider ocp-binaries,rhcos
-  airgap status --failed`,
-		RunE: statusRun,
+  airgap status --failed
+  airgap status --output json`,
+		Annotations: map[string]string{outputAnnotation: structuredFormats},
+		RunE:        statusRun,
 	}
 
 	cmd.Flags().StringVar(&statusProvider, "provider", "", "comma-separated list of providers to show status for")
@@ -57,10 +61,14 @@ func statusRun(cmd *cobra.Command, args []string) error {
 		for name := range globalCfg.Providers {
 			providers = append(providers, name)
 		}
+		sort.Strings(providers)
 	}
 
 	if len(providers) == 0 {
 		log.Warn("no providers found")
+		if structuredOutput() {
+			return writeStructured(outputFormat, &statusDocument{Version: outputVersion, Providers: []engine.ProviderStatus{}})
+		}
 		return nil
 	}
 
@@ -80,6 +88,14 @@ func statusRun(cmd *cobra.Command, args []string) error {
 		}
 	}
 
+	if structuredOutput() {
+		doc := &statusDocument{Version: outputVersion, Providers: make([]engine.ProviderStatus, 0, len(filteredStatuses))}
+		for _, p := range filteredStatuses {
+			doc.Providers = append(doc.Providers, statuses[p])
+		}
+		return writeStructured(outputFormat, doc)
+	}
+
 	if len(filteredStatuses) == 0 {
 		fmt.Println("No providers found matching criteria")
 		return nil
@@ -116,6 +132,12 @@ func statusRun(cmd *cobra.Command, args []string) error {
 	return nil
 }
 
+// statusDocument is the --output json|yaml form of airgap status.
+type statusDocument struct {
+	Version   int                     `json:"version"`
+	Providers []engine.ProviderStatus `json:"providers"`
+}
+
 // formatBytes formats a byte count into human-readable format
 func formatBytes(bytes int64) string {
 	const unit = 1024
@@ -4,6 +4,7 @@ import (
 	"context"
 	"fmt"
 	"log/slog"
+	"sort"
 	"strings"
 
 	"github.com/BadgerOps/airgap/internal/provider"
@@ -31,12 +32,15 @@ The sync command will:
   4. Validate checksums for all downloaded files
   5. Retry failed downloads based on configuration
 
-Without --all or --provider, all enabled providers are synced.`,
+Without --all or --provider, all enabled providers are synced. Use
+'airgap plan' to list every download, update and delete before syncing.`,
 		Example: `  airgap sync --all
   airgap sync --provider epel,ocp-binaries
   airgap sync --provider rhcos --dry-run
-  airgap sync --provider container-images --force`,
-		RunE: syncRun,
+  airgap sync --provider container-images --force
+  airgap sync --all --output json`,
+		Annotations: map[string]string{outputAnnotation: structuredFormats},
+		RunE:        syncRun,
 	}
 
 	cmd.Flags().BoolVar(&syncAll, "all", false, "sync all enabled providers")
@@ -64,6 +68,7 @@ func syncRun(cmd *cobra.Command, args []string) error {
 		for name := range globalCfg.Providers {
 			providers = append(providers, name)
 		}
+		sort.Strings(providers)
 	} else if syncProvider != "" {
 		providers = strings.Split(syncProvider, ",")
 		for i, p := range providers {
@@ -76,10 +81,22 @@ func syncRun(cmd *cobra.Command, args []string) error {
 				providers = append(providers, name)
 			}
 		}
+		sort.Strings(providers)
+	}
+
+	structured := structuredOutput()
+	doc := &syncDocument{
+		Version: outputVersion,
+		DryRun:  syncDryRun,
+		Reports: []*provider.SyncReport{},
+		Errors:  []providerError{},
 	}
 
 	if len(providers) == 0 {
 		log.Warn("no providers to sync")
+		if structured {
+			return writeStructured(outputFormat, doc)
+		}
 		return nil
 	}
 
@@ -95,30 +112,44 @@ func syncRun(cmd *cobra.Command, args []string) error {
 	ctx := context.Background()
 
 	// Display results
-	if syncDryRun {
+	if syncDryRun && !structured {
 		fmt.Println("DRY RUN: Sync will perform the following actions:")
 	}
 
-	totalDownloaded := 0
-	totalSkipped := 0
-	totalFailed := 0
-	totalDeleted := 0
+	totals := &doc.Totals
 
 	// Sync each provider
 	for _, providerName := range providers {
 		log.Info("syncing provider", "provider", providerName)
 
 		report, err := globalEngine.SyncProvider(ctx, providerName, opts)
+		// Dry runs change nothing, so they are not audited.
+		if !syncDryRun {
+			var detail string
+			if report != nil {
+				detail = fmt.Sprintf("force=%t downloaded=%d failed=%d",
+					syncForce, report.Downloaded, len(report.Failed))
+			}
+			recordAudit("sync.run", providerName, detail, err)
+		}
 		if err != nil {
-			fmt.Printf("  ERROR: %s - %v\n", providerName, err)
-			totalFailed++
+			doc.Errors = append(doc.Errors, providerError{Provider: providerName, Error: err.Error()})
+			totals.Failed++
+			if !structured {
+				fmt.Printf("  ERROR: %s - %v\n", providerName, err)
+			}
 			continue
 		}
 
-		totalDownloaded += report.Downloaded
-		totalSkipped += report.Skipped
-		totalDeleted += report.Deleted
-		totalFailed += len(report.Failed)
+		doc.Reports = append(doc.Reports, report)
+		totals.Downloaded += report.Downloaded
+		totals.Skipped += report.Skipped
+		totals.Deleted += report.Deleted
+		totals.Failed += len(report.Failed)
+		totals.BytesTransferred += report.BytesTransferred
+		if structured {
+			continue
+		}
 
 		// Print provider report
 		fmt.Printf("\n%s:\n", providerName)
@@ -136,16 +167,39 @@ func syncRun(cmd *cobra.Command, args []string) error {
 		}
 	}
 
-	// Print summary
-	fmt.Println("\n=== SYNC SUMMARY ===")
-	fmt.Printf("Total Downloaded: %d\n", totalDownloaded)
-	fmt.Printf("Total Skipped:    %d\n", totalSkipped)
-	fmt.Printf("Total Deleted:    %d\n", totalDeleted)
-	fmt.Printf("Total Failed:     %d\n", totalFailed)
+	if structured {
+		if err := writeStructured(outputFormat, doc); err != nil {
+			return err
+		}
+	} else {
+		// Print summary
+		fmt.Println("\n=== SYNC SUMMARY ===")
+		fmt.Printf("Total Downloaded: %d\n", totals.Downloaded)
+		fmt.Printf("Total Skipped:    %d\n", totals.Skipped)
+		fmt.Printf("Total Deleted:    %d\n", totals.Deleted)
+		fmt.Printf("Total Failed:     %d\n", totals.Failed)
+	}
 
-	if totalFailed > 0 {
-		return fmt.Errorf("sync completed with %d failures", totalFailed)
+	if totals.Failed > 0 {
+		return partialError(fmt.Errorf("sync completed with %d failures", totals.Failed))
 	}
 
 	return nil
 }
+
+// syncDocument is the --output json|yaml form of airgap sync.
+type syncDocument struct {
+	Version int                    `json:"version"`
+	DryRun  bool                   `json:"dry_run"`
+	Reports []*provider.SyncReport `json:"reports"`
+	Errors  []providerError        `json:"errors"`
+	Totals  syncTotals             `json:"totals"`
+}
+
+type syncTotals struct {
+	Downloaded       int   `json:"downloaded"`
+	Skipped          int   `json:"skipped"`
+	Deleted          int   `json:"deleted"`
+	Failed           int   `json:"failed"`
+	BytesTransferred int64 `json:"bytes_transferred"`
+}
@@ -4,8 +4,10 @@ import (
 	"context"
 	"fmt"
 	"log/slog"
+	"sort"
 	"strings"
 
+	"github.com/BadgerOps/airgap/internal/provider"
 	"github.com/spf13/cobra"
 )
 
@@ -25,8 +27,10 @@ missing or corrupted files.
 Without --provider or --all, validates all enabled providers.`,
 		Example: `  airgap validate --all
   airgap validate --provider epel
-  airgap validate --provider ocp-binaries,rhcos`,
-		RunE: validateRun,
+  airgap validate --provider ocp-binaries,rhcos
+  airgap validate --all --output json`,
+		Annotations: map[string]string{outputAnnotation: structuredFormats},
+		RunE:        validateRun,
 	}
 
 	cmd.Flags().BoolVar(&validateAll, "all", false, "validate all enabled providers")
@@ -52,6 +56,7 @@ func validateRun(cmd *cobra.Command, args []string) error {
 		for name := range globalCfg.Providers {
 			providers = append(providers, name)
 		}
+		sort.Strings(providers)
 	} else if validateProvider != "" {
 		providers = strings.Split(validateProvider, ",")
 		for i, p := range providers {
@@ -64,34 +69,52 @@ func validateRun(cmd *cobra.Command, args []string) error {
 				providers = append(providers, name)
 			}
 		}
+		sort.Strings(providers)
+	}
+
+	structured := structuredOutput()
+	doc := &validateDocument{
+		Version: outputVersion,
+		Reports: []*provider.ValidationReport{},
+		Errors:  []providerError{},
 	}
 
 	if len(providers) == 0 {
 		log.Warn("no providers to validate")
+		if structured {
+			return writeStructured(outputFormat, doc)
+		}
 		return nil
 	}
 
 	log.Info("validate operation", "providers", providers)
 
 	ctx := context.Background()
-	totalValid := 0
-	totalInvalid := 0
 
-	fmt.Println("Validating providers...")
-	fmt.Println()
+	if !structured {
+		fmt.Println("Validating providers...")
+		fmt.Println()
+	}
 
 	for _, providerName := range providers {
 		log.Info("validating provider", "provider", providerName)
 
 		report, err := globalEngine.ValidateProvider(ctx, providerName)
 		if err != nil {
-			fmt.Printf("%s: ERROR - %v\n", providerName, err)
-			totalInvalid++
+			doc.Errors = append(doc.Errors, providerError{Provider: providerName, Error: err.Error()})
+			doc.Totals.Invalid++
+			if !structured {
+				fmt.Printf("%s: ERROR - %v\n", providerName, err)
+			}
 			continue
 		}
 
-		totalValid += report.ValidFiles
-		totalInvalid += len(report.InvalidFiles)
+		doc.Reports = append(doc.Reports, report)
+		doc.Totals.Valid += report.ValidFiles
+		doc.Totals.Invalid += len(report.InvalidFiles)
+		if structured {
+			continue
+		}
 
 		// Print provider report
 		fmt.Printf("%s:\n", providerName)
@@ -103,7 +126,9 @@ func validateRun(cmd *cobra.Command, args []string) error {
 			fmt.Println("  Invalid files:")
 			for _, inv := range report.InvalidFiles {
 				fmt.Printf("    - %s\n", inv.Path)
-				if inv.Expected != "" && inv.Actual != "" {
+				if inv.Reason != "" {
+					fmt.Printf("      Reason:   %s\n", inv.Reason)
+				} else if inv.Expected != "" && inv.Actual != "" {
 					fmt.Printf("      Expected: %s\n", inv.Expected)
 					fmt.Printf("      Actual:   %s\n", inv.Actual)
 				}
@@ -112,14 +137,33 @@ func validateRun(cmd *cobra.Command, args []string) error {
 		fmt.Println()
 	}
 
-	// Print summary
-	fmt.Println("=== VALIDATION SUMMARY ===")
-	fmt.Printf("Total Valid:   %d\n", totalValid)
-	fmt.Printf("Total Invalid: %d\n", totalInvalid)
+	if structured {
+		if err := writeStructured(outputFormat, doc); err != nil {
+			return err
+		}
+	} else {
+		// Print summary
+		fmt.Println("=== VALIDATION SUMMARY ===")
+		fmt.Printf("Total Valid:   %d\n", doc.Totals.Valid)
+		fmt.Printf("Total Invalid: %d\n", doc.Totals.Invalid)
+	}
 
-	if totalInvalid > 0 {
-		return fmt.Errorf("validation failed: %d invalid files", totalInvalid)
+	if doc.Totals.Invalid > 0 {
+		return partialError(fmt.Errorf("validation failed: %d invalid files", doc.Totals.Invalid))
 	}
 
 	return nil
 }
+
+// validateDocument is the --output json|yaml form of airgap validate.
+type validateDocument struct {
+	Version int                          `json:"version"`
+	Reports []*provider.ValidationReport `json:"reports"`
+	Errors  []providerError              `json:"errors"`
+	Totals  validateTotals               `json:"totals"`
+}
+
+type validateTotals struct {
+	Valid   int `json:"valid"`
+	Invalid int `json:"invalid"`
+}
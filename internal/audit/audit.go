@@ -0,0 +1,157 @@
+// Package audit records mutating actions taken through the API, UI and CLI
+// in the store's append-only audit log.
+package audit
+
+import (
+	"encoding/json"
+	"fmt"
+	"log/slog"
+	"time"
+
+	"github.com/BadgerOps/airgap/internal/config"
+	"github.com/BadgerOps/airgap/internal/store"
+)
+
+// Event sources.
+const (
+	SourceAPI = "api"
+	SourceCLI = "cli"
+)
+
+// Event outcomes.
+const (
+	OutcomeSuccess = "success"
+	OutcomeFailure = "failure"
+)
+
+// Recorder appends events to the audit log.
+type Recorder struct {
+	store     store.Store
+	hashChain bool
+	logger    *slog.Logger
+}
+
+// NewRecorder creates a Recorder writing to st. With hashChain set, each
+// event is linked to the previous one so tampering is detectable.
+func NewRecorder(st store.Store, hashChain bool, logger *slog.Logger) *Recorder {
+	return &Recorder{store: st, hashChain: hashChain, logger: logger}
+}
+
+// Record appends ev, stamping its time if unset. Failures are logged rather
+// than returned so a broken audit log never blocks the action itself. A nil
+// Recorder or one without a store records nothing.
+func (r *Recorder) Record(ev store.AuditEvent) {
+	if r == nil || r.store == nil {
+		return
+	}
+	if ev.Time.IsZero() {
+		ev.Time = time.Now()
+	}
+	if err := r.store.AppendAuditEvent(&ev, r.hashChain); err != nil {
+		r.logger.Error("failed to record audit event", "action", ev.Action, "target", ev.Target, "error", err)
+	}
+}
+
+// Outcome returns OutcomeFailure and the error text when err is non-nil and
+// OutcomeSuccess otherwise.
+func Outcome(err error) (outcome, detail string) {
+	if err != nil {
+		return OutcomeFailure, err.Error()
+	}
+	return OutcomeSuccess, ""
+}
+
+// RedactConfig returns configJSON with the values of credential keys
+// replaced by "[REDACTED]". Invalid JSON is returned as an empty object.
+func RedactConfig(configJSON string) string {
+	var cfg map[string]interface{}
+	if err := json.Unmarshal([]byte(configJSON), &cfg); err != nil || cfg == nil {
+		return "{}"
+	}
+	data, _ := json.Marshal(redactValue(cfg))
+	return string(data)
+}
+
+// ProviderState returns the redacted JSON state of pc recorded before and
+// after provider config changes, or "" for a nil config.
+func ProviderState(pc *store.ProviderConfig) string {
+	if pc == nil {
+		return ""
+	}
+	state := map[string]interface{}{
+		"type":    pc.Type,
+		"enabled": pc.Enabled,
+		"config":  json.RawMessage(RedactConfig(pc.ConfigJSON)),
+	}
+	data, _ := json.Marshal(state)
+	return string(data)
+}
+
+func redactValue(v interface{}) interface{} {
+	switch val := v.(type) {
+	case map[string]interface{}:
+		out := make(map[string]interface{}, len(val))
+		for k, item := range val {
+			if config.IsSensitiveKey(k) {
+				out[k] = config.RedactedValue
+				continue
+			}
+			out[k] = redactValue(item)
+		}
+		return out
+	case []interface{}:
+		out := make([]interface{}, len(val))
+		for i, item := range val {
+			out[i] = redactValue(item)
+		}
+		return out
+	default:
+		return v
+	}
+}
+
+// VerifyResult summarizes a check of the audit log's hash chain.
+type VerifyResult struct {
+	Events    int      `json:"events"`
+	Chained   int      `json:"chained"`
+	Unchained int      `json:"unchained"`
+	Problems  []string `json:"problems,omitempty"`
+}
+
+// OK reports whether the chain verified without problems.
+func (v *VerifyResult) OK() bool {
+	return len(v.Problems) == 0
+}
+
+// Verify walks the audit log oldest first, recomputing each chained
+// event's hash and checking it links to the event before it. Events
+// recorded while hash chaining was disabled are counted but not checked.
+func Verify(st store.Store) (*VerifyResult, error) {
+	events, err := st.ListAuditEvents(store.AuditFilter{})
+	if err != nil {
+		return nil, fmt.Errorf("listing audit events: %w", err)
+	}
+
+	result := &VerifyResult{Events: len(events)}
+	var prevHash string
+	// Events are listed newest first.
+	for i := len(events) - 1; i >= 0; i-- {
+		ev := events[i]
+		if ev.Hash == "" {
+			result.Unchained++
+			prevHash = ""
+			continue
+		}
+		result.Chained++
+		if ev.PrevHash != prevHash {
+			result.Problems = append(result.Problems,
+				fmt.Sprintf("event %d: previous hash %q does not match preceding event %q", ev.ID, ev.PrevHash, prevHash))
+		}
+		if ev.ComputeHash() != ev.Hash {
+			result.Problems = append(result.Problems,
+				fmt.Sprintf("event %d: contents do not match hash", ev.ID))
+		}
+		prevHash = ev.Hash
+	}
+	return result, nil
+}
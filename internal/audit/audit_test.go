@@ -0,0 +1,77 @@
+package audit
+
+import (
+	"database/sql"
+	"log/slog"
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+
+	"github.com/BadgerOps/airgap/internal/store"
+)
+
+func TestRedactConfig(t *testing.T) {
+	in := `{"base_url":"https://example.com","password":"hunter2","repos":[{"name":"a","auth_token":"abc"}],"registry":{"credentials":{"user":"x"}}}`
+	got := RedactConfig(in)
+
+	for _, secret := range []string{"hunter2", "abc", `"user"`} {
+		if strings.Contains(got, secret) {
+			t.Errorf("RedactConfig leaked %s: %s", secret, got)
+		}
+	}
+	for _, keep := range []string{`"base_url":"https://example.com"`, `"password":"[REDACTED]"`, `"auth_token":"[REDACTED]"`, `"name":"a"`} {
+		if !strings.Contains(got, keep) {
+			t.Errorf("RedactConfig(%s) = %s, missing %s", in, got, keep)
+		}
+	}
+	if got := RedactConfig("not json"); got != "{}" {
+		t.Errorf("RedactConfig(invalid) = %q, want {}", got)
+	}
+}
+
+func TestVerify(t *testing.T) {
+	logger := slog.New(slog.NewTextHandler(os.Stderr, &slog.HandlerOptions{Level: slog.LevelError}))
+	dbPath := filepath.Join(t.TempDir(), "airgap.db")
+	st, err := store.New(dbPath, logger)
+	if err != nil {
+		t.Fatalf("store.New failed: %v", err)
+	}
+	t.Cleanup(func() { _ = st.Close() })
+
+	rec := NewRecorder(st, true, logger)
+	for _, action := range []string{"provider.create", "sync.start", "provider.delete"} {
+		rec.Record(store.AuditEvent{Actor: "alice", Source: SourceAPI, Action: action, Target: "epel", Outcome: OutcomeSuccess})
+	}
+
+	result, err := Verify(st)
+	if err != nil {
+		t.Fatalf("Verify failed: %v", err)
+	}
+	if !result.OK() || result.Chained != 3 {
+		t.Fatalf("Verify = %+v, want 3 chained events and no problems", result)
+	}
+
+	// Tamper with the middle event behind the store's back.
+	db, err := sql.Open("sqlite", dbPath)
+	if err != nil {
+		t.Fatalf("open database: %v", err)
+	}
+	defer func() { _ = db.Close() }()
+	for _, q := range []string{
+		"DROP TRIGGER audit_events_no_update",
+		"UPDATE audit_events SET actor = 'mallory' WHERE action = 'sync.start'",
+	} {
+		if _, err := db.Exec(q); err != nil {
+			t.Fatalf("%s: %v", q, err)
+		}
+	}
+
+	result, err = Verify(st)
+	if err != nil {
+		t.Fatalf("Verify failed: %v", err)
+	}
+	if result.OK() || !strings.Contains(result.Problems[0], "do not match hash") {
+		t.Errorf("Verify after tampering = %+v, want hash mismatch", result)
+	}
+}
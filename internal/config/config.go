@@ -4,6 +4,7 @@ import (
 	"fmt"
 	"os"
 	"path/filepath"
+	"strings"
 
 	"gopkg.in/yaml.v3"
 )
@@ -13,6 +14,9 @@ type Config struct {
 	Server    ServerConfig              `yaml:"server"`
 	Export    ExportConfig              `yaml:"export"`
 	Schedule  ScheduleConfig            `yaml:"schedule"`
+	Database  DatabaseConfig            `yaml:"database"`
+	Audit     AuditConfig               `yaml:"audit"`
+	Boot      BootConfig                `yaml:"boot"`
 	Providers map[string]ProviderConfig `yaml:"providers"`
 }
 
@@ -37,66 +41,215 @@ type ScheduleConfig struct {
 	DefaultCron string `yaml:"default_cron"`
 }
 
+// DatabaseConfig holds store backend and maintenance settings
+type DatabaseConfig struct {
+	// Driver is "sqlite" (default, stored at server.db_path) or "postgres".
+	Driver string `yaml:"driver"`
+	// DSN is the PostgreSQL connection string; unused for sqlite.
+	DSN string `yaml:"dsn"`
+	// MaintenanceInterval is how often airgap serve prunes and vacuums the
+	// store, as a Go duration (e.g. "24h"); empty or "0" disables it.
+	MaintenanceInterval string          `yaml:"maintenance_interval"`
+	Retention           RetentionConfig `yaml:"retention"`
+}
+
+// RetentionConfig bounds the history kept in the store. Zero keeps everything.
+type RetentionConfig struct {
+	SyncRunsPerProvider  int `yaml:"sync_runs_per_provider"` // newest runs kept per provider
+	ResolvedFailuresDays int `yaml:"resolved_failures_days"` // resolved failed_files, by last failure
+	TransfersDays        int `yaml:"transfers_days"`         // finished exports/imports and their archives
+	JobsDays             int `yaml:"jobs_days"`              // completed or failed jobs
+}
+
+// AuditConfig controls the audit log of mutating actions.
+type AuditConfig struct {
+	// HashChain links each audit event to the previous one by SHA-256 so
+	// edits to the log are detectable with airgap audit verify.
+	HashChain bool `yaml:"hash_chain"`
+	// TrustedProxies are the addresses or CIDRs of authenticating reverse
+	// proxies. Only requests from them may name the actor with X-Remote-User,
+	// X-Forwarded-User or basic auth; others are recorded as anonymous.
+	TrustedProxies []string `yaml:"trusted_proxies"`
+}
+
+// BootConfig enables PXE/iPXE booting of mirrored RHCOS artifacts from
+// airgap serve.
+type BootConfig struct {
+	Enabled bool `yaml:"enabled"`
+	// BaseURL is the airgap URL booting nodes reach, e.g.
+	// "http://10.0.0.5:8080". Defaults to the host of each request; required
+	// for TFTP.
+	BaseURL string `yaml:"base_url"`
+	// Default is the image booted by the menu timeout and by hosts without
+	// an image; the first image when empty.
+	Default    string     `yaml:"default"`
+	KernelArgs string     `yaml:"kernel_args"`
+	Hosts      []BootHost `yaml:"hosts"`
+	TFTP       TFTPConfig `yaml:"tftp"`
+}
+
+// BootHost is the per-MAC boot configuration of one node.
+type BootHost struct {
+	MAC         string `yaml:"mac"`
+	Image       string `yaml:"image"`
+	IgnitionURL string `yaml:"ignition_url"`
+	// InstallDev installs RHCOS to this disk with coreos-installer instead
+	// of running live.
+	InstallDev string `yaml:"install_dev"`
+	KernelArgs string `yaml:"kernel_args"`
+}
+
+// TFTPConfig configures the embedded TFTP server that hands out iPXE
+// binaries (undionly.kpxe, ipxe.efi) for chainloading.
+type TFTPConfig struct {
+	Enabled bool   `yaml:"enabled"`
+	Listen  string `yaml:"listen"`
+	Root    string `yaml:"root"`
+}
+
 // ProviderConfig is the raw YAML config for a provider
 type ProviderConfig map[string]interface{}
 
+// Provider config fields carry a desc tag and an optional schema tag
+// (required, writeOnly, default=..., enum=a|b, pattern=..., minimum=N,
+// minItems=N) from which ProviderSchema builds the JSON Schema served to
+// the UI and enforced by the API.
+
 // EPELRepoConfig represents a single EPEL repo definition
 type EPELRepoConfig struct {
-	Name      string `yaml:"name"`
-	BaseURL   string `yaml:"base_url"`
-	OutputDir string `yaml:"output_dir"`
+	Name      string `yaml:"name" desc:"Repository name, used in logs and as the default output directory" schema:"required"`
+	BaseURL   string `yaml:"base_url" desc:"Repository URL containing repodata/" schema:"required,pattern=^https?://"`
+	OutputDir string `yaml:"output_dir" desc:"Directory under the data directory for this repo"`
+	// GPGKeys are trusted OpenPGP public keys: armored key files or inline
+	// armored key blocks.
+	GPGKeys []string `yaml:"gpg_keys" desc:"Trusted OpenPGP public keys: armored key files or inline armored key blocks"`
+	// RepoGPGCheck verifies repodata/repomd.xml.asc while planning.
+	RepoGPGCheck bool `yaml:"repo_gpgcheck" desc:"Verify repodata/repomd.xml.asc while planning"`
+	// GPGCheck verifies RPM header signatures during validation.
+	GPGCheck bool `yaml:"gpgcheck" desc:"Verify RPM header signatures during validation"`
 }
 
 // EPELProviderConfig is the typed config for the EPEL provider
 type EPELProviderConfig struct {
-	Enabled                bool             `yaml:"enabled"`
-	Repos                  []EPELRepoConfig `yaml:"repos"`
-	MaxConcurrentDownloads int              `yaml:"max_concurrent_downloads"`
-	RetryAttempts          int              `yaml:"retry_attempts"`
-	CleanupRemovedPackages bool             `yaml:"cleanup_removed_packages"`
+	Enabled                bool             `yaml:"enabled" desc:"Whether the provider is synced"`
+	Repos                  []EPELRepoConfig `yaml:"repos" desc:"Repositories to mirror"`
+	MaxConcurrentDownloads int              `yaml:"max_concurrent_downloads" desc:"Parallel package downloads" schema:"minimum=0"`
+	RetryAttempts          int              `yaml:"retry_attempts" desc:"Attempts per failed download" schema:"minimum=0"`
+	CleanupRemovedPackages bool             `yaml:"cleanup_removed_packages" desc:"Delete local packages that are no longer in the repo metadata"`
 }
 
 // OCPBinariesProviderConfig is the typed config for OCP binaries
 type OCPBinariesProviderConfig struct {
-	Enabled         bool     `yaml:"enabled"`
-	BaseURL         string   `yaml:"base_url"`
-	Versions        []string `yaml:"versions"`
-	IgnoredPatterns []string `yaml:"ignored_patterns"`
-	OutputDir       string   `yaml:"output_dir"`
-	RetryAttempts   int      `yaml:"retry_attempts"`
+	Enabled         bool     `yaml:"enabled" desc:"Whether the provider is synced"`
+	BaseURL         string   `yaml:"base_url" desc:"Mirror directory holding one subdirectory per version; {arch} is replaced per architecture" schema:"pattern=^https?://"`
+	Versions        []string `yaml:"versions" desc:"Versions, channels or expressions such as '>=4.16.0 <4.19.0', 'all 4.17.z' or 'latest 3 of stable-4.18'"`
+	IgnoredPatterns []string `yaml:"ignored_patterns" desc:"Glob patterns of file names to skip"`
+	OutputDir       string   `yaml:"output_dir" desc:"Directory under the data directory"`
+	RetryAttempts   int      `yaml:"retry_attempts" desc:"Attempts per failed download" schema:"minimum=0"`
+	// Architectures expands base_url ({arch} or its arch path segment) per
+	// architecture and stores files under <output_dir>/<arch>/<version>.
+	Architectures []string `yaml:"architectures" desc:"Architectures to mirror, such as x86_64 or aarch64; files go under <output_dir>/<arch>/<version>" schema:"pattern=^[A-Za-z0-9_]+$"`
+	// GPGKeys are trusted release keys (file paths or armored blocks). When
+	// set, each sha256sum.txt must verify against its sha256sum.txt.gpg.
+	GPGKeys []string `yaml:"gpg_keys" desc:"Trusted release keys; each sha256sum.txt must then verify against sha256sum.txt.gpg"`
 }
 
 // RHCOSProviderConfig is the typed config for RHCOS images
 type RHCOSProviderConfig struct {
-	Enabled         bool     `yaml:"enabled"`
-	BaseURL         string   `yaml:"base_url"`
-	Versions        []string `yaml:"versions"`
-	IgnoredPatterns []string `yaml:"ignored_patterns"`
-	OutputDir       string   `yaml:"output_dir"`
-	RetryAttempts   int      `yaml:"retry_attempts"`
+	Enabled         bool     `yaml:"enabled" desc:"Whether the provider is synced"`
+	BaseURL         string   `yaml:"base_url" desc:"Mirror directory holding one subdirectory per version; {arch} is replaced per architecture" schema:"pattern=^https?://"`
+	Versions        []string `yaml:"versions" desc:"RHCOS versions or minor streams to mirror"`
+	IgnoredPatterns []string `yaml:"ignored_patterns" desc:"Glob patterns of file names to skip"`
+	OutputDir       string   `yaml:"output_dir" desc:"Directory under the data directory"`
+	RetryAttempts   int      `yaml:"retry_attempts" desc:"Attempts per failed download" schema:"minimum=0"`
+	// StreamURL selects artifacts from CoreOS stream metadata (rhcos.json or
+	// openshift-install coreos print-stream-json) instead of sha256sum.txt.
+	// It is a URL or local file path; "{version}" is replaced per version.
+	StreamURL string `yaml:"stream_url" desc:"CoreOS stream metadata URL or file; {version} is replaced per version"`
+	// Architectures expands base_url like OCPBinariesProviderConfig and
+	// selects stream architectures (default ["x86_64"] in stream mode).
+	// Files are then stored under <output_dir>/<arch>/<version>.
+	Architectures []string                `yaml:"architectures" desc:"Architectures to mirror, such as x86_64 or aarch64" schema:"pattern=^[A-Za-z0-9_]+$"`
+	Artifacts     []RHCOSArtifactSelector `yaml:"artifacts" desc:"Stream artifacts to mirror (stream mode); default every metal format"` // stream mode, default all metal formats
+	// GPGKeys verifies sha256sum.txt like OCPBinariesProviderConfig.
+	GPGKeys []string `yaml:"gpg_keys" desc:"Trusted release keys; each sha256sum.txt must then verify against sha256sum.txt.gpg"`
+}
+
+// RHCOSArtifactSelector picks stream metadata artifacts of a platform, e.g.
+// metal with formats ["iso", "pxe"] or vmware with ["ova"].
+type RHCOSArtifactSelector struct {
+	Platform string   `yaml:"platform" desc:"Stream platform such as metal, vmware or qemu" schema:"required"`
+	Formats  []string `yaml:"formats" desc:"Formats of the platform, such as iso or pxe; empty selects every format"` // empty = every format of the platform
 }
 
 // OCPClientsProviderConfig is the typed config for OCP client binaries (oc + openshift-install)
 // with channel-based auto-discovery and platform filtering.
 type OCPClientsProviderConfig struct {
-	Enabled   bool     `yaml:"enabled"`
-	Channels  []string `yaml:"channels"`   // e.g. ["stable-4.21", "fast-4.22"] — auto-discover releases
-	Versions  []string `yaml:"versions"`   // pinned versions e.g. ["4.21.1", "4.20.5"]
-	Platforms []string `yaml:"platforms"`  // ["linux", "linux-arm64", "mac", "mac-arm64", "windows"]
-	OutputDir string   `yaml:"output_dir"` // default "ocp-clients"
+	Enabled   bool     `yaml:"enabled" desc:"Whether the provider is synced"`
+	Channels  []string `yaml:"channels" desc:"Update channels whose releases are discovered, such as stable-4.18"`                                          // e.g. ["stable-4.21", "fast-4.22"] — auto-discover releases
+	Versions  []string `yaml:"versions" desc:"Pinned release versions such as 4.18.1"`                                                                      // pinned versions e.g. ["4.21.1", "4.20.5"]
+	Platforms []string `yaml:"platforms" desc:"Client platforms to mirror" schema:"default=linux|linux-arm64,enum=linux|linux-arm64|mac|mac-arm64|windows"` // ["linux", "linux-arm64", "mac", "mac-arm64", "windows"]
+	OutputDir string   `yaml:"output_dir" desc:"Directory under the data directory" schema:"default=ocp-clients"`                                           // default "ocp-clients"
+	// UpdateGraph captures the Cincinnati update graph for each channel so
+	// airgap serve can answer cluster update requests on the low side.
+	UpdateGraph        bool     `yaml:"update_graph" desc:"Capture the Cincinnati update graph of each channel for airgap serve"`
+	GraphArchitectures []string `yaml:"graph_architectures" desc:"Update graph architectures" schema:"default=amd64,enum=amd64|arm64|ppc64le|s390x|multi"` // default ["amd64"]
 }
 
 // ContainerImagesProviderConfig is the typed config for container images
 type ContainerImagesProviderConfig struct {
-	Enabled bool `yaml:"enabled"`
+	Enabled bool `yaml:"enabled" desc:"Whether the provider is synced"`
 	// Images is a list of newline-friendly image references such as:
 	// docker://quay.io/org/repo:tag
 	// oci://registry.example.com/ns/repo@sha256:...
-	Images []string `yaml:"images"`
-	// Legacy fields kept for backward compatibility with existing configs.
-	OCMirrorBinary string `yaml:"oc_mirror_binary"`
-	ImagesetConfig string `yaml:"imageset_config"`
-	OutputDir      string `yaml:"output_dir"`
+	Images []string `yaml:"images" desc:"Image references such as docker://quay.io/org/repo:tag or oci://registry/ns/repo@sha256:..."`
+	// ImagesetConfig is a path to an oc-mirror ImageSetConfiguration whose
+	// additionalImages and pinned release payloads are added to Images.
+	ImagesetConfig string `yaml:"imageset_config" desc:"Path to an oc-mirror ImageSetConfiguration whose additionalImages and pinned releases are added"`
+	// Platforms limits multi-arch image indexes to the listed "os/arch[/variant]"
+	// children. The original index is kept; registry push rewrites it to the
+	// mirrored subset. Empty mirrors every platform.
+	Platforms []string `yaml:"platforms" desc:"os/arch[/variant] children of multi-arch images to mirror; empty mirrors every platform" schema:"pattern=^[a-z0-9]+/[a-z0-9_]+(/[a-z0-9]+)?$"`
+	// Referrers also mirrors cosign signatures, attestations and SBOMs and
+	// OCI 1.1 referrers of every mirrored manifest.
+	Referrers bool `yaml:"referrers" desc:"Also mirror cosign signatures, attestations, SBOMs and OCI referrers"`
+	// AuthFile is a containers auth.json, Docker config.json or pull secret
+	// used for image pulls (default $REGISTRY_AUTH_FILE).
+	AuthFile    string               `yaml:"auth_file" desc:"Pull secret, containers auth.json or Docker config.json (default $REGISTRY_AUTH_FILE)"`
+	Credentials []RegistryCredential `yaml:"credentials" desc:"Per-registry pull credentials"`
+	// OCMirrorBinary is a legacy field kept for backward compatibility.
+	OCMirrorBinary string `yaml:"oc_mirror_binary" desc:"Legacy field kept for backward compatibility; ignored"`
+	OutputDir      string `yaml:"output_dir" desc:"Directory under the data directory" schema:"default=images"`
+}
+
+// OperatorCatalogProviderConfig is the typed config for operator catalog mirroring.
+// Each catalog index image is pulled, its file-based catalog is pruned to the
+// selected packages, and the bundle and related images are mirrored.
+type OperatorCatalogProviderConfig struct {
+	Enabled  bool                   `yaml:"enabled" desc:"Whether the provider is synced"`
+	Catalogs []OperatorCatalogEntry `yaml:"catalogs" desc:"Catalog index images and the packages to keep"`
+	// Platform selects which child of a multi-arch index image is read and
+	// used as the base of the pruned catalog image (default "linux/amd64").
+	Platform    string               `yaml:"platform" desc:"Index image platform read and used as the base of the pruned catalog" schema:"default=linux/amd64,pattern=^[a-z0-9]+/[a-z0-9_]+(/[a-z0-9]+)?$"`
+	AuthFile    string               `yaml:"auth_file" desc:"Pull secret, containers auth.json or Docker config.json (default $REGISTRY_AUTH_FILE)"`
+	Credentials []RegistryCredential `yaml:"credentials" desc:"Per-registry pull credentials"`
+	OutputDir   string               `yaml:"output_dir" desc:"Directory under the data directory" schema:"default=operators"` // default "operators"
+}
+
+// OperatorCatalogEntry is a single catalog index image and its package filters.
+type OperatorCatalogEntry struct {
+	Index    string                  `yaml:"index" desc:"Catalog index image by tag, such as registry.redhat.io/redhat/redhat-operator-index:v4.16" schema:"required"` // e.g. registry.redhat.io/redhat/redhat-operator-index:v4.16
+	Packages []OperatorPackageFilter `yaml:"packages" desc:"Packages to keep" schema:"required,minItems=1"`
+}
+
+// OperatorPackageFilter selects bundles from one operator package.
+// When Channels is empty the package's default channel is used. When neither
+// MinVersion nor MaxVersion is set only the head of each channel is kept.
+type OperatorPackageFilter struct {
+	Name       string   `yaml:"name" desc:"Operator package name" schema:"required"`
+	Channels   []string `yaml:"channels" desc:"Channels to keep; empty keeps the default channel"`
+	MinVersion string   `yaml:"min_version" desc:"Lowest bundle version to keep"`
+	MaxVersion string   `yaml:"max_version" desc:"Highest bundle version to keep"`
 }
 
 // RegistryProviderConfig is the typed config for mirror-registry.
@@ -105,32 +258,49 @@ type ContainerImagesProviderConfig struct {
 // manifests/blobs locally. When Repositories is empty it is a push-only
 // target used by `airgap registry push`.
 type RegistryProviderConfig struct {
-	Enabled              bool     `yaml:"enabled"`
-	MirrorRegistryBinary string   `yaml:"mirror_registry_binary"`
-	QuayRoot             string   `yaml:"quay_root"`
-	Endpoint             string   `yaml:"endpoint"`
-	RepositoryPrefix     string   `yaml:"repository_prefix"`
-	Username             string   `yaml:"username"`
-	Password             string   `yaml:"password"`
-	InsecureSkipTLS      bool     `yaml:"insecure_skip_tls"`
-	SkopeoBinary         string   `yaml:"skopeo_binary"`
-	Repositories         []string `yaml:"repositories"`
-	Tags                 []string `yaml:"tags"`
-	OutputDir            string   `yaml:"output_dir"`
+	Enabled              bool     `yaml:"enabled" desc:"Whether the provider is synced"`
+	MirrorRegistryBinary string   `yaml:"mirror_registry_binary" desc:"Path to the mirror-registry installer"`
+	QuayRoot             string   `yaml:"quay_root" desc:"Quay installation root of mirror-registry"`
+	Endpoint             string   `yaml:"endpoint" desc:"Registry host[:port]"`
+	RepositoryPrefix     string   `yaml:"repository_prefix" desc:"Namespace prepended to pushed repositories"`
+	Username             string   `yaml:"username" desc:"Registry user"`
+	Password             string   `yaml:"password" desc:"Registry password" schema:"writeOnly"`
+	InsecureSkipTLS      bool     `yaml:"insecure_skip_tls" desc:"Skip TLS certificate verification"`
+	CABundle             string   `yaml:"ca_bundle" desc:"PEM CA bundle path, used for install-config additionalTrustBundle"` // PEM CA path used for install-config additionalTrustBundle
+	SkopeoBinary         string   `yaml:"skopeo_binary" desc:"skopeo executable used by registry push" schema:"default=skopeo"`
+	Repositories         []string `yaml:"repositories" desc:"Repositories to sync from the registry; empty makes it a push-only target"`
+	Tags                 []string `yaml:"tags" desc:"Tag glob patterns to sync, such as 4.16.* or latest"`
+	Platforms            []string `yaml:"platforms" desc:"os/arch[/variant] children of multi-arch images to sync; empty syncs every platform" schema:"pattern=^[a-z0-9]+/[a-z0-9_]+(/[a-z0-9]+)?$"` // "os/arch[/variant]" index children to sync; empty syncs all
+	OutputDir            string   `yaml:"output_dir" desc:"Directory under the data directory" schema:"default=registry-images"`
+	// AuthFile and Credentials add pull credentials for the sync source on
+	// top of Username/Password.
+	AuthFile    string               `yaml:"auth_file" desc:"Pull secret, containers auth.json or Docker config.json for the sync source"`
+	Credentials []RegistryCredential `yaml:"credentials" desc:"Per-registry pull credentials for the sync source"`
+}
+
+// RegistryCredential is a pull credential for a registry. Registry is a host
+// or a host/namespace prefix; the longest match wins. Helper names a
+// docker-credential-<helper> executable (or a path to one) that is asked for
+// the credential instead of Username/Password.
+type RegistryCredential struct {
+	Registry string `yaml:"registry" desc:"Registry host or host/namespace prefix; the longest match wins" schema:"required"`
+	Username string `yaml:"username" desc:"User name"`
+	Password string `yaml:"password" desc:"Password or token" schema:"writeOnly"`
+	Helper   string `yaml:"helper" desc:"docker-credential-<helper> executable asked instead of username/password"`
 }
 
 // CustomFilesProviderConfig is the typed config for custom file sources
 type CustomFilesProviderConfig struct {
-	Enabled bool               `yaml:"enabled"`
-	Sources []CustomFileSource `yaml:"sources"`
+	Enabled bool               `yaml:"enabled" desc:"Whether the provider is synced"`
+	Sources []CustomFileSource `yaml:"sources" desc:"Files to mirror"`
 }
 
 // CustomFileSource is a single custom file source
 type CustomFileSource struct {
-	Name        string `yaml:"name"`
-	URL         string `yaml:"url"`
-	ChecksumURL string `yaml:"checksum_url"`
-	OutputDir   string `yaml:"output_dir"`
+	Name        string `yaml:"name" desc:"Source name" schema:"required"`
+	URL         string `yaml:"url" desc:"File URL" schema:"required,pattern=^https?://"`
+	ChecksumURL string `yaml:"checksum_url" desc:"URL of a checksum file for the source"`
+	OutputDir   string `yaml:"output_dir" desc:"Directory under the data directory"`
 }
 
 // DefaultConfig returns a config with sensible defaults
@@ -151,6 +321,19 @@ func DefaultConfig() *Config {
 			Enabled:     true,
 			DefaultCron: "0 2 * * 0",
 		},
+		Database: DatabaseConfig{
+			Driver:              "sqlite",
+			MaintenanceInterval: "24h",
+			Retention: RetentionConfig{
+				SyncRunsPerProvider:  100,
+				ResolvedFailuresDays: 30,
+				TransfersDays:        365,
+				JobsDays:             90,
+			},
+		},
+		Boot: BootConfig{
+			TFTP: TFTPConfig{Listen: "0.0.0.0:69"},
+		},
 		Providers: make(map[string]ProviderConfig),
 	}
 }
@@ -225,3 +408,18 @@ func ParseProviderConfig[T any](raw ProviderConfig) (*T, error) {
 	}
 	return &typed, nil
 }
+
+// sensitiveKeys are substrings of config keys that hold credentials.
+var sensitiveKeys = []string{"password", "secret", "token", "credential", "auth"}
+
+// IsSensitiveKey reports whether a config key names a credential that must
+// not be exported or logged.
+func IsSensitiveKey(key string) bool {
+	key = strings.ToLower(key)
+	for _, s := range sensitiveKeys {
+		if strings.Contains(key, s) {
+			return true
+		}
+	}
+	return false
+}
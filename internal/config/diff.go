@@ -0,0 +1,85 @@
+package config
+
+import (
+	"fmt"
+	"reflect"
+	"sort"
+)
+
+// RedactedValue replaces credential values in diffs and audit records.
+const RedactedValue = "[REDACTED]"
+
+// Change is one difference between two decoded configs. Path is dotted
+// with list indexes, e.g. repos[0].base_url. Before is nil for added
+// values and After is nil for removed ones.
+type Change struct {
+	Path   string      `json:"path"`
+	Before interface{} `json:"before,omitempty"`
+	After  interface{} `json:"after,omitempty"`
+}
+
+// Diff lists the differences between two decoded JSON or YAML configs,
+// sorted by path. Values under credential keys are reported as changed
+// but replaced with RedactedValue.
+func Diff(before, after map[string]interface{}) []Change {
+	var changes []Change
+	diffValue("", before, after, false, &changes)
+	sort.Slice(changes, func(i, j int) bool { return changes[i].Path < changes[j].Path })
+	return changes
+}
+
+func diffValue(path string, before, after interface{}, sensitive bool, changes *[]Change) {
+	bm, bIsMap := before.(map[string]interface{})
+	am, aIsMap := after.(map[string]interface{})
+	if bIsMap && aIsMap {
+		keys := make(map[string]bool, len(bm)+len(am))
+		for k := range bm {
+			keys[k] = true
+		}
+		for k := range am {
+			keys[k] = true
+		}
+		for k := range keys {
+			diffValue(joinPath(path, k), bm[k], am[k], sensitive || IsSensitiveKey(k), changes)
+		}
+		return
+	}
+
+	bl, bIsList := before.([]interface{})
+	al, aIsList := after.([]interface{})
+	if bIsList && aIsList {
+		for i := 0; i < len(bl) || i < len(al); i++ {
+			var b, a interface{}
+			if i < len(bl) {
+				b = bl[i]
+			}
+			if i < len(al) {
+				a = al[i]
+			}
+			diffValue(fmt.Sprintf("%s[%d]", path, i), b, a, sensitive, changes)
+		}
+		return
+	}
+
+	if reflect.DeepEqual(before, after) {
+		return
+	}
+	if sensitive {
+		before, after = redact(before), redact(after)
+	}
+	*changes = append(*changes, Change{Path: path, Before: before, After: after})
+}
+
+func joinPath(path, key string) string {
+	if path == "" {
+		return key
+	}
+	return path + "." + key
+}
+
+func redact(v interface{}) interface{} {
+	if v == nil {
+		return nil
+	}
+	return RedactedValue
+}
@@ -0,0 +1,41 @@
+package config
+
+import (
+	"reflect"
+	"testing"
+)
+
+func TestDiff(t *testing.T) {
+	before := map[string]interface{}{
+		"enabled": true,
+		"repos": []interface{}{
+			map[string]interface{}{"name": "a", "base_url": "https://old"},
+			map[string]interface{}{"name": "b"},
+		},
+		"auth": map[string]interface{}{"password": "hunter2"},
+		"keep": "same",
+	}
+	after := map[string]interface{}{
+		"enabled": false,
+		"repos": []interface{}{
+			map[string]interface{}{"name": "a", "base_url": "https://new"},
+		},
+		"auth":    map[string]interface{}{"password": "hunter3"},
+		"keep":    "same",
+		"timeout": 30.0,
+	}
+
+	want := []Change{
+		{Path: "auth.password", Before: RedactedValue, After: RedactedValue},
+		{Path: "enabled", Before: true, After: false},
+		{Path: "repos[0].base_url", Before: "https://old", After: "https://new"},
+		{Path: "repos[1]", Before: map[string]interface{}{"name": "b"}},
+		{Path: "timeout", After: 30.0},
+	}
+	if got := Diff(before, after); !reflect.DeepEqual(got, want) {
+		t.Errorf("Diff() = %#v\nwant %#v", got, want)
+	}
+	if got := Diff(before, before); len(got) != 0 {
+		t.Errorf("Diff(same) = %v, want none", got)
+	}
+}
@@ -0,0 +1,312 @@
+package config
+
+import (
+	"fmt"
+	"reflect"
+	"regexp"
+	"sort"
+	"strconv"
+	"strings"
+)
+
+// SchemaDraft is the JSON Schema dialect of ProviderSchema.
+const SchemaDraft = "https://json-schema.org/draft/2020-12/schema"
+
+// FieldError is a validation problem with one field of a provider config.
+// Field is a dotted path such as repos[0].base_url.
+type FieldError struct {
+	Field   string `json:"field"`
+	Message string `json:"message"`
+}
+
+func (e FieldError) Error() string {
+	if e.Field == "" {
+		return e.Message
+	}
+	return e.Field + ": " + e.Message
+}
+
+// ProviderTypes lists the provider types that have a config schema.
+func ProviderTypes() []string {
+	types := make([]string, 0, len(providerConfigTypes))
+	for t := range providerConfigTypes {
+		types = append(types, t)
+	}
+	sort.Strings(types)
+	return types
+}
+
+// ProviderSchema returns the JSON Schema of the config of providerType,
+// built from the desc and schema tags of its typed config struct.
+func ProviderSchema(providerType string) (map[string]interface{}, bool) {
+	t, ok := providerConfigTypes[providerType]
+	if !ok {
+		return nil, false
+	}
+	s := typeSchema(t, reflect.StructField{})
+	s["$schema"] = SchemaDraft
+	s["$id"] = "/api/providers/schema/" + providerType
+	s["title"] = providerType + " provider config"
+	return s, true
+}
+
+// schemaTag holds the options of a field's schema tag.
+type schemaTag struct {
+	required  bool
+	writeOnly bool
+	def       string
+	enum      []string
+	pattern   string
+	minimum   *int
+	minItems  *int
+}
+
+func parseSchemaTag(f reflect.StructField) schemaTag {
+	var st schemaTag
+	for _, opt := range strings.Split(f.Tag.Get("schema"), ",") {
+		key, val, _ := strings.Cut(opt, "=")
+		switch key {
+		case "required":
+			st.required = true
+		case "writeOnly":
+			st.writeOnly = true
+		case "default":
+			st.def = val
+		case "enum":
+			st.enum = strings.Split(val, "|")
+		case "pattern":
+			st.pattern = val
+		case "minimum", "minItems":
+			n, err := strconv.Atoi(val)
+			if err != nil {
+				panic(fmt.Sprintf("config: bad %s in schema tag of %s", key, f.Name))
+			}
+			if key == "minimum" {
+				st.minimum = &n
+			} else {
+				st.minItems = &n
+			}
+		}
+	}
+	return st
+}
+
+// typeSchema builds the schema of t; f is the struct field holding it, if
+// any, whose tags add the description and constraints.
+func typeSchema(t reflect.Type, f reflect.StructField) map[string]interface{} {
+	for t.Kind() == reflect.Ptr {
+		t = t.Elem()
+	}
+	st := parseSchemaTag(f)
+	s := map[string]interface{}{}
+	if desc := f.Tag.Get("desc"); desc != "" {
+		s["description"] = desc
+	}
+
+	switch t.Kind() {
+	case reflect.String:
+		s["type"] = "string"
+		if st.def != "" {
+			s["default"] = st.def
+		}
+		addStringConstraints(s, st)
+		if st.writeOnly {
+			s["writeOnly"] = true
+		}
+	case reflect.Bool:
+		s["type"] = "boolean"
+		if b, err := strconv.ParseBool(st.def); err == nil {
+			s["default"] = b
+		}
+	case reflect.Int, reflect.Int8, reflect.Int16, reflect.Int32, reflect.Int64:
+		s["type"] = "integer"
+		if n, err := strconv.Atoi(st.def); err == nil {
+			s["default"] = n
+		}
+		if st.minimum != nil {
+			s["minimum"] = *st.minimum
+		}
+	case reflect.Slice:
+		s["type"] = "array"
+		items := typeSchema(t.Elem(), reflect.StructField{})
+		if t.Elem().Kind() == reflect.String {
+			addStringConstraints(items, st)
+			if st.def != "" {
+				s["default"] = strings.Split(st.def, "|")
+			}
+		}
+		s["items"] = items
+		if st.minItems != nil {
+			s["minItems"] = *st.minItems
+		}
+	case reflect.Map:
+		s["type"] = "object"
+		s["additionalProperties"] = typeSchema(t.Elem(), reflect.StructField{})
+	case reflect.Struct:
+		s["type"] = "object"
+		props := map[string]interface{}{}
+		var order, required []string
+		for i := 0; i < t.NumField(); i++ {
+			field := t.Field(i)
+			name := yamlName(field)
+			if name == "" || name == "-" {
+				continue
+			}
+			props[name] = typeSchema(field.Type, field)
+			order = append(order, name)
+			if parseSchemaTag(field).required {
+				required = append(required, name)
+			}
+		}
+		s["properties"] = props
+		s["x-order"] = order
+		s["additionalProperties"] = false
+		if len(required) > 0 {
+			s["required"] = required
+		}
+	}
+	return s
+}
+
+func addStringConstraints(s map[string]interface{}, st schemaTag) {
+	if len(st.enum) > 0 {
+		s["enum"] = st.enum
+	}
+	if st.pattern != "" {
+		s["pattern"] = st.pattern
+	}
+}
+
+// ValidateProviderConfig checks cfg against the schema of providerType and
+// returns one error per offending field.
+func ValidateProviderConfig(providerType string, cfg map[string]interface{}) []FieldError {
+	s, ok := ProviderSchema(providerType)
+	if !ok {
+		return []FieldError{{Field: "type", Message: fmt.Sprintf("unknown provider type %q", providerType)}}
+	}
+	var errs []FieldError
+	validateValue(s, cfg, "", &errs)
+	return errs
+}
+
+func validateValue(s map[string]interface{}, v interface{}, path string, errs *[]FieldError) {
+	fail := func(format string, args ...interface{}) {
+		*errs = append(*errs, FieldError{Field: path, Message: fmt.Sprintf(format, args...)})
+	}
+	if v == nil {
+		return
+	}
+
+	switch s["type"] {
+	case "string":
+		str, ok := v.(string)
+		if !ok {
+			fail("must be a string")
+			return
+		}
+		if enum, ok := s["enum"].([]string); ok && !containsString(enum, str) {
+			fail("must be one of %s", strings.Join(enum, ", "))
+		}
+		if pattern, ok := s["pattern"].(string); ok && str != "" && !regexp.MustCompile(pattern).MatchString(str) {
+			fail("%q does not match %s", str, pattern)
+		}
+
+	case "boolean":
+		if _, ok := v.(bool); !ok {
+			fail("must be true or false")
+		}
+
+	case "integer":
+		n, ok := asInt(v)
+		if !ok {
+			fail("must be an integer")
+			return
+		}
+		if min, ok := s["minimum"].(int); ok && n < min {
+			fail("must be at least %d", min)
+		}
+
+	case "array":
+		list, ok := v.([]interface{})
+		if !ok {
+			fail("must be a list")
+			return
+		}
+		if min, ok := s["minItems"].(int); ok && len(list) < min {
+			fail("needs at least %d item(s)", min)
+		}
+		items, _ := s["items"].(map[string]interface{})
+		for i, item := range list {
+			validateValue(items, item, path+"["+strconv.Itoa(i)+"]", errs)
+		}
+
+	case "object":
+		obj, ok := v.(map[string]interface{})
+		if !ok {
+			fail("must be an object")
+			return
+		}
+		props, _ := s["properties"].(map[string]interface{})
+		if required, ok := s["required"].([]string); ok {
+			for _, name := range required {
+				if val, present := obj[name]; !present || val == nil || val == "" {
+					*errs = append(*errs, FieldError{Field: joinField(path, name), Message: "is required"})
+				}
+			}
+		}
+		keys := make([]string, 0, len(obj))
+		for k := range obj {
+			keys = append(keys, k)
+		}
+		sort.Strings(keys)
+		for _, k := range keys {
+			if props == nil {
+				if extra, ok := s["additionalProperties"].(map[string]interface{}); ok {
+					validateValue(extra, obj[k], joinField(path, k), errs)
+				}
+				continue
+			}
+			ps, ok := props[k].(map[string]interface{})
+			if !ok {
+				msg := "unknown field"
+				if sug := Suggest(k, s["x-order"].([]string)); sug != "" {
+					msg += fmt.Sprintf(" (did you mean %q?)", sug)
+				}
+				*errs = append(*errs, FieldError{Field: joinField(path, k), Message: msg})
+				continue
+			}
+			validateValue(ps, obj[k], joinField(path, k), errs)
+		}
+	}
+}
+
+func joinField(path, name string) string {
+	if path == "" {
+		return name
+	}
+	return path + "." + name
+}
+
+// asInt accepts the integer forms JSON and YAML decoding produce.
+func asInt(v interface{}) (int, bool) {
+	switch n := v.(type) {
+	case int:
+		return n, true
+	case int64:
+		return int(n), true
+	case float64:
+		if n == float64(int(n)) {
+			return int(n), true
+		}
+	}
+	return 0, false
+}
+
+func containsString(list []string, s string) bool {
+	for _, v := range list {
+		if v == s {
+			return true
+		}
+	}
+	return false
+}
@@ -0,0 +1,119 @@
+package config
+
+import (
+	"encoding/json"
+	"reflect"
+	"strings"
+	"testing"
+)
+
+func TestProviderSchema(t *testing.T) {
+	for _, typ := range ProviderTypes() {
+		s, ok := ProviderSchema(typ)
+		if !ok {
+			t.Fatalf("no schema for %s", typ)
+		}
+		if _, err := json.Marshal(s); err != nil {
+			t.Errorf("schema of %s does not marshal: %v", typ, err)
+		}
+		props := s["properties"].(map[string]interface{})
+		if _, ok := props["enabled"]; !ok {
+			t.Errorf("schema of %s has no enabled property", typ)
+		}
+	}
+
+	s, _ := ProviderSchema("epel")
+	repo := s["properties"].(map[string]interface{})["repos"].(map[string]interface{})["items"].(map[string]interface{})
+	if !reflect.DeepEqual(repo["required"], []string{"name", "base_url"}) {
+		t.Errorf("epel repo required = %v", repo["required"])
+	}
+	baseURL := repo["properties"].(map[string]interface{})["base_url"].(map[string]interface{})
+	if baseURL["description"] == "" || baseURL["pattern"] != "^https?://" {
+		t.Errorf("base_url schema = %v", baseURL)
+	}
+
+	s, _ = ProviderSchema("ocp_clients")
+	platforms := s["properties"].(map[string]interface{})["platforms"].(map[string]interface{})
+	if !reflect.DeepEqual(platforms["default"], []string{"linux", "linux-arm64"}) || platforms["items"].(map[string]interface{})["enum"] == nil {
+		t.Errorf("ocp_clients platforms schema = %v", platforms)
+	}
+
+	s, _ = ProviderSchema("registry")
+	password := s["properties"].(map[string]interface{})["password"].(map[string]interface{})
+	if password["writeOnly"] != true {
+		t.Errorf("registry password schema = %v", password)
+	}
+	if authFile := s["properties"].(map[string]interface{})["auth_file"].(map[string]interface{}); authFile["writeOnly"] != nil {
+		t.Errorf("registry auth_file schema = %v", authFile)
+	}
+
+	if _, ok := ProviderSchema("nope"); ok {
+		t.Error("ProviderSchema of an unknown type should fail")
+	}
+}
+
+func TestValidateProviderConfig(t *testing.T) {
+	decode := func(s string) map[string]interface{} {
+		var m map[string]interface{}
+		if err := json.Unmarshal([]byte(s), &m); err != nil {
+			t.Fatal(err)
+		}
+		return m
+	}
+
+	if errs := ValidateProviderConfig("epel", decode(`{"enabled":true,"repos":[{"name":"epel-9","base_url":"https://example.com/"}],"retry_attempts":3}`)); len(errs) != 0 {
+		t.Errorf("valid config rejected: %v", errs)
+	}
+
+	tests := []struct {
+		typ, cfg string
+		want     []string
+	}{
+		{"epel", `{"repos":[{"name":"a","base_url":"ftp://x","gpgchek":true}]}`, []string{"repos[0].base_url:", "repos[0].gpgchek: unknown field (did you mean \"gpgcheck\"?)"}},
+		{"epel", `{"repos":[{"name":"a"}],"retry_attempts":-1}`, []string{"repos[0].base_url: is required", "retry_attempts: must be at least 0"}},
+		{"epel", `{"repos":"https://x","max_concurrent_downloads":1.5}`, []string{"max_concurrent_downloads: must be an integer", "repos: must be a list"}},
+		{"ocp_clients", `{"platforms":["linux","solaris"]}`, []string{"platforms[1]: must be one of"}},
+		{"operator_catalog", `{"catalogs":[{"index":"x","packages":[]}]}`, []string{"catalogs[0].packages: needs at least 1 item(s)"}},
+		{"container_images", `{"platforms":["amd64"]}`, []string{"platforms[0]:"}},
+		{"bogus", `{}`, []string{"type: unknown provider type"}},
+	}
+	for _, tt := range tests {
+		errs := ValidateProviderConfig(tt.typ, decode(tt.cfg))
+		var got []string
+		for _, e := range errs {
+			got = append(got, e.Error())
+		}
+		joined := strings.Join(got, "\n")
+		for _, want := range tt.want {
+			if !strings.Contains(joined, want) {
+				t.Errorf("%s %s: errors %q missing %q", tt.typ, tt.cfg, got, want)
+			}
+		}
+		if len(got) != len(tt.want) {
+			t.Errorf("%s %s: got %d errors %q, want %d", tt.typ, tt.cfg, len(got), got, len(tt.want))
+		}
+	}
+}
+
+func TestExampleConfigMatchesProviderSchemas(t *testing.T) {
+	cfg, err := Load("../../configs/airgap.example.yaml")
+	if err != nil {
+		t.Fatal(err)
+	}
+	for name, raw := range cfg.Providers {
+		if _, ok := providerConfigTypes[name]; !ok {
+			continue
+		}
+		data, err := json.Marshal(raw)
+		if err != nil {
+			t.Fatal(err)
+		}
+		var generic map[string]interface{}
+		if err := json.Unmarshal(data, &generic); err != nil {
+			t.Fatal(err)
+		}
+		if errs := ValidateProviderConfig(name, generic); len(errs) > 0 {
+			t.Errorf("example provider %s: %v", name, errs)
+		}
+	}
+}
@@ -0,0 +1,425 @@
+package config
+
+import (
+	"bytes"
+	"encoding/json"
+	"fmt"
+	"reflect"
+	"regexp"
+	"sort"
+	"strconv"
+	"strings"
+
+	"gopkg.in/yaml.v3"
+)
+
+// PathElem is one step of a dotted config path: a mapping key, or a list
+// index when Index is not negative.
+type PathElem struct {
+	Key   string
+	Index int
+}
+
+func (e PathElem) String() string {
+	if e.Index >= 0 {
+		return "[" + strconv.Itoa(e.Index) + "]"
+	}
+	return e.Key
+}
+
+var pathSegment = regexp.MustCompile(`^([^\[\]]+)((?:\[\d+\])*)$`)
+
+// ParsePath splits a dotted path such as providers.epel.repos[0].base_url
+// into keys and list indexes.
+func ParsePath(path string) ([]PathElem, error) {
+	if path == "" {
+		return nil, fmt.Errorf("empty config path")
+	}
+	var elems []PathElem
+	for _, seg := range strings.Split(path, ".") {
+		m := pathSegment.FindStringSubmatch(seg)
+		if m == nil {
+			return nil, fmt.Errorf("invalid config path %q: bad segment %q", path, seg)
+		}
+		elems = append(elems, PathElem{Key: m[1], Index: -1})
+		for _, idx := range strings.Split(strings.Trim(m[2], "[]"), "][") {
+			if idx == "" {
+				continue
+			}
+			n, _ := strconv.Atoi(idx)
+			elems = append(elems, PathElem{Index: n})
+		}
+	}
+	return elems, nil
+}
+
+func formatPath(elems []PathElem) string {
+	var b strings.Builder
+	for i, e := range elems {
+		if i > 0 && e.Index < 0 {
+			b.WriteByte('.')
+		}
+		b.WriteString(e.String())
+	}
+	return b.String()
+}
+
+// providerConfigTypes maps provider types to their typed config structs.
+var providerConfigTypes = map[string]reflect.Type{
+	"epel":             reflect.TypeOf(EPELProviderConfig{}),
+	"ocp_binaries":     reflect.TypeOf(OCPBinariesProviderConfig{}),
+	"ocp_clients":      reflect.TypeOf(OCPClientsProviderConfig{}),
+	"rhcos":            reflect.TypeOf(RHCOSProviderConfig{}),
+	"container_images": reflect.TypeOf(ContainerImagesProviderConfig{}),
+	"operator_catalog": reflect.TypeOf(OperatorCatalogProviderConfig{}),
+	"registry":         reflect.TypeOf(RegistryProviderConfig{}),
+	"custom_files":     reflect.TypeOf(CustomFilesProviderConfig{}),
+}
+
+// SetValue sets the dotted path in the YAML config document data to value
+// and returns the updated document. The path and value are checked against
+// Config; comments and key order are kept. Provider configs live in the
+// database and are set with SetProviderValue.
+func SetValue(data []byte, path, value string) ([]byte, error) {
+	elems, err := ParsePath(path)
+	if err != nil {
+		return nil, err
+	}
+	if elems[0].Key == "providers" {
+		return nil, fmt.Errorf("provider configs are stored in the database, not the config file")
+	}
+
+	var doc yaml.Node
+	if err := yaml.Unmarshal(data, &doc); err != nil {
+		return nil, fmt.Errorf("parsing config file: %w", err)
+	}
+	if doc.Kind == 0 {
+		doc = yaml.Node{Kind: yaml.DocumentNode, Content: []*yaml.Node{{Kind: yaml.MappingNode, Tag: "!!map"}}}
+	}
+	if err := setNode(doc.Content[0], reflect.TypeOf(Config{}), elems, nil, value); err != nil {
+		return nil, err
+	}
+
+	var buf bytes.Buffer
+	enc := yaml.NewEncoder(&buf)
+	enc.SetIndent(2)
+	if err := enc.Encode(&doc); err != nil {
+		return nil, fmt.Errorf("encoding config file: %w", err)
+	}
+	if err := enc.Close(); err != nil {
+		return nil, fmt.Errorf("encoding config file: %w", err)
+	}
+	if err := yaml.Unmarshal(buf.Bytes(), DefaultConfig()); err != nil {
+		return nil, fmt.Errorf("updated config does not parse: %w", err)
+	}
+	return restoreBlankLines(data, buf.Bytes()), nil
+}
+
+// restoreBlankLines puts back the blank lines the YAML encoder drops: a line
+// of out gets a blank line before it when the same occurrence of that line,
+// ignoring spacing, had one in orig.
+func restoreBlankLines(orig, out []byte) []byte {
+	normalize := func(line string) string { return strings.Join(strings.Fields(line), " ") }
+
+	blankBefore := map[string]map[int]bool{}
+	seen := map[string]int{}
+	prevBlank := false
+	for _, line := range strings.Split(string(orig), "\n") {
+		n := normalize(line)
+		if n == "" {
+			prevBlank = true
+			continue
+		}
+		if prevBlank {
+			if blankBefore[n] == nil {
+				blankBefore[n] = map[int]bool{}
+			}
+			blankBefore[n][seen[n]] = true
+		}
+		seen[n]++
+		prevBlank = false
+	}
+
+	var b strings.Builder
+	seen = map[string]int{}
+	prevBlank = true
+	for _, line := range strings.Split(strings.TrimSuffix(string(out), "\n"), "\n") {
+		n := normalize(line)
+		if !prevBlank && n != "" && blankBefore[n][seen[n]] {
+			b.WriteByte('\n')
+		}
+		seen[n]++
+		prevBlank = n == ""
+		b.WriteString(line)
+		b.WriteByte('\n')
+	}
+	return []byte(b.String())
+}
+
+// SetProviderValue sets path, relative to the provider (for example
+// repos[0].base_url), in the JSON config of a provider of providerType and
+// returns the updated JSON.
+func SetProviderValue(providerType, configJSON, path, value string) (string, error) {
+	t, ok := providerConfigTypes[providerType]
+	if !ok {
+		return "", fmt.Errorf("unknown provider type %q", providerType)
+	}
+	elems, err := ParsePath(path)
+	if err != nil {
+		return "", err
+	}
+
+	raw := map[string]interface{}{}
+	if configJSON != "" {
+		if err := json.Unmarshal([]byte(configJSON), &raw); err != nil {
+			return "", fmt.Errorf("parsing provider config: %w", err)
+		}
+	}
+	var root yaml.Node
+	if err := root.Encode(raw); err != nil {
+		return "", fmt.Errorf("encoding provider config: %w", err)
+	}
+	if err := setNode(&root, t, elems, nil, value); err != nil {
+		return "", err
+	}
+
+	updated := map[string]interface{}{}
+	if err := root.Decode(&updated); err != nil {
+		return "", fmt.Errorf("decoding provider config: %w", err)
+	}
+	typed := reflect.New(t).Interface()
+	if err := root.Decode(typed); err != nil {
+		return "", fmt.Errorf("updated provider config does not parse: %w", err)
+	}
+	out, err := json.Marshal(updated)
+	if err != nil {
+		return "", fmt.Errorf("marshaling provider config: %w", err)
+	}
+
+	// Check the schema constraints of the value that was set; problems
+	// elsewhere in an existing config do not block the change.
+	var generic map[string]interface{}
+	if err := json.Unmarshal(out, &generic); err != nil {
+		return "", fmt.Errorf("parsing provider config: %w", err)
+	}
+	set := formatPath(elems)
+	for _, fe := range ValidateProviderConfig(providerType, generic) {
+		if fe.Field == set || strings.HasPrefix(fe.Field, set+".") || strings.HasPrefix(fe.Field, set+"[") {
+			return "", fe
+		}
+	}
+	return string(out), nil
+}
+
+// setNode sets path below node, which holds a value of type t, to value.
+// done is the part of the path already walked, for error messages.
+func setNode(node *yaml.Node, t reflect.Type, path, done []PathElem, value string) error {
+	for t.Kind() == reflect.Ptr {
+		t = t.Elem()
+	}
+	if len(path) == 0 {
+		return setScalar(node, t, formatPath(done), value)
+	}
+
+	elem := path[0]
+	here := formatPath(done)
+	if here == "" {
+		here = "the config root"
+	}
+
+	switch t.Kind() {
+	case reflect.Struct:
+		if elem.Index >= 0 {
+			return fmt.Errorf("%s is not a list", here)
+		}
+		field, ok := structFieldByTag(t, elem.Key)
+		if !ok {
+			return unknownKeyError(elem.Key, here, structKeys(t))
+		}
+		return setNode(mappingValue(node, elem.Key), field.Type, path[1:], append(done, elem), value)
+
+	case reflect.Map:
+		if elem.Index >= 0 {
+			return fmt.Errorf("%s is not a list", here)
+		}
+		return setNode(mappingValue(node, elem.Key), t.Elem(), path[1:], append(done, elem), value)
+
+	case reflect.Slice:
+		if elem.Index < 0 {
+			return fmt.Errorf("%s is a list; use %s[N]", here, here)
+		}
+		if node.Kind != yaml.SequenceNode {
+			*node = yaml.Node{Kind: yaml.SequenceNode, Tag: "!!seq", HeadComment: node.HeadComment, LineComment: node.LineComment}
+		}
+		switch {
+		case elem.Index < len(node.Content):
+		case elem.Index == len(node.Content):
+			node.Content = append(node.Content, &yaml.Node{Kind: yaml.ScalarNode, Tag: "!!null"})
+		default:
+			return fmt.Errorf("index %d of %s out of range (%d items; use %d to append)", elem.Index, here, len(node.Content), len(node.Content))
+		}
+		return setNode(node.Content[elem.Index], t.Elem(), path[1:], append(done, elem), value)
+	}
+	return fmt.Errorf("%s is a %s and has no key %q", here, t.Kind(), elem.String())
+}
+
+// setScalar replaces node with value converted to type t, keeping the
+// node's comments and quoting style.
+func setScalar(node *yaml.Node, t reflect.Type, path, value string) error {
+	switch t.Kind() {
+	case reflect.String:
+		setScalarNode(node, "!!str", value)
+		return nil
+	case reflect.Bool:
+		b, err := strconv.ParseBool(value)
+		if err != nil {
+			return fmt.Errorf("%s must be true or false, got %q", path, value)
+		}
+		setScalarNode(node, "!!bool", strconv.FormatBool(b))
+		return nil
+	case reflect.Int, reflect.Int8, reflect.Int16, reflect.Int32, reflect.Int64:
+		n, err := strconv.ParseInt(value, 10, t.Bits())
+		if err != nil {
+			return fmt.Errorf("%s must be an integer, got %q", path, value)
+		}
+		setScalarNode(node, "!!int", strconv.FormatInt(n, 10))
+		return nil
+	}
+
+	// Lists and sections take a YAML value; a plain comma-separated value
+	// is accepted for lists of strings.
+	var parsed yaml.Node
+	if t.Kind() == reflect.Slice && t.Elem().Kind() == reflect.String && !strings.HasPrefix(strings.TrimSpace(value), "[") {
+		parsed = yaml.Node{Kind: yaml.SequenceNode, Tag: "!!seq", Style: yaml.FlowStyle}
+		for _, item := range strings.Split(value, ",") {
+			if item = strings.TrimSpace(item); item != "" {
+				parsed.Content = append(parsed.Content, &yaml.Node{Kind: yaml.ScalarNode, Tag: "!!str", Value: item})
+			}
+		}
+	} else {
+		var doc yaml.Node
+		if err := yaml.Unmarshal([]byte(value), &doc); err != nil || len(doc.Content) == 0 {
+			return fmt.Errorf("%s needs a YAML %s value: %v", path, t.Kind(), err)
+		}
+		parsed = *doc.Content[0]
+	}
+	if err := checkNode(&parsed, t); err != nil {
+		return fmt.Errorf("invalid value for %s: %w", path, err)
+	}
+	parsed.HeadComment, parsed.LineComment, parsed.FootComment = node.HeadComment, node.LineComment, node.FootComment
+	*node = parsed
+	return nil
+}
+
+func setScalarNode(node *yaml.Node, tag, value string) {
+	style := node.Style
+	if node.Kind != yaml.ScalarNode || node.Tag != tag {
+		style = 0
+	}
+	node.Kind, node.Tag, node.Value, node.Content, node.Style = yaml.ScalarNode, tag, value, nil, style
+}
+
+// checkNode decodes n into a new value of type t, rejecting unknown keys.
+func checkNode(n *yaml.Node, t reflect.Type) error {
+	data, err := yaml.Marshal(n)
+	if err != nil {
+		return err
+	}
+	dec := yaml.NewDecoder(bytes.NewReader(data))
+	dec.KnownFields(true)
+	return dec.Decode(reflect.New(t).Interface())
+}
+
+// mappingValue returns the value node of key in node, adding the key when
+// it is missing. A null node becomes an empty mapping.
+func mappingValue(node *yaml.Node, key string) *yaml.Node {
+	if node.Kind != yaml.MappingNode {
+		*node = yaml.Node{Kind: yaml.MappingNode, Tag: "!!map", HeadComment: node.HeadComment, LineComment: node.LineComment}
+	}
+	for i := 0; i+1 < len(node.Content); i += 2 {
+		if node.Content[i].Value == key {
+			return node.Content[i+1]
+		}
+	}
+	k := &yaml.Node{Kind: yaml.ScalarNode, Tag: "!!str", Value: key}
+	v := &yaml.Node{Kind: yaml.ScalarNode, Tag: "!!null"}
+	node.Content = append(node.Content, k, v)
+	return v
+}
+
+func structFieldByTag(t reflect.Type, key string) (reflect.StructField, bool) {
+	for i := 0; i < t.NumField(); i++ {
+		f := t.Field(i)
+		if yamlName(f) == key {
+			return f, true
+		}
+	}
+	return reflect.StructField{}, false
+}
+
+func structKeys(t reflect.Type) []string {
+	var keys []string
+	for i := 0; i < t.NumField(); i++ {
+		if name := yamlName(t.Field(i)); name != "" && name != "-" {
+			keys = append(keys, name)
+		}
+	}
+	sort.Strings(keys)
+	return keys
+}
+
+func yamlName(f reflect.StructField) string {
+	name, _, _ := strings.Cut(f.Tag.Get("yaml"), ",")
+	if name == "" {
+		return strings.ToLower(f.Name)
+	}
+	return name
+}
+
+func unknownKeyError(key, where string, valid []string) error {
+	if s := Suggest(key, valid); s != "" {
+		return fmt.Errorf("unknown key %q in %s (did you mean %q?)", key, where, s)
+	}
+	return fmt.Errorf("unknown key %q in %s (valid keys: %s)", key, where, strings.Join(valid, ", "))
+}
+
+// Suggest returns the candidate closest to name by edit distance, or "" when
+// none is close enough to be a likely typo.
+func Suggest(name string, candidates []string) string {
+	best, bestDist := "", -1
+	for _, c := range candidates {
+		d := editDistance(strings.ToLower(name), strings.ToLower(c))
+		if bestDist < 0 || d < bestDist {
+			best, bestDist = c, d
+		}
+	}
+	limit := len(name) / 3
+	if limit < 2 {
+		limit = 2
+	}
+	if bestDist < 0 || bestDist > limit {
+		return ""
+	}
+	return best
+}
+
+// editDistance is the Levenshtein distance between a and b.
+func editDistance(a, b string) int {
+	prev := make([]int, len(b)+1)
+	cur := make([]int, len(b)+1)
+	for j := range prev {
+		prev[j] = j
+	}
+	for i := 1; i <= len(a); i++ {
+		cur[0] = i
+		for j := 1; j <= len(b); j++ {
+			cost := 1
+			if a[i-1] == b[j-1] {
+				cost = 0
+			}
+			cur[j] = min(prev[j]+1, cur[j-1]+1, prev[j-1]+cost)
+		}
+		prev, cur = cur, prev
+	}
+	return prev[len(b)]
+}
@@ -0,0 +1,128 @@
+package config
+
+import (
+	"strings"
+	"testing"
+
+	"gopkg.in/yaml.v3"
+)
+
+const setTestConfig = `# airgap config
+server:
+  listen: "0.0.0.0:8080"  # all interfaces
+  data_dir: "/var/lib/airgap"
+
+export:
+  split_size: "25GB"
+
+schedule:
+  enabled: true
+`
+
+func TestParsePath(t *testing.T) {
+	elems, err := ParsePath("providers.epel-9.repos[0].base_url")
+	if err != nil {
+		t.Fatal(err)
+	}
+	if got := formatPath(elems); got != "providers.epel-9.repos[0].base_url" || len(elems) != 5 || elems[3].Index != 0 {
+		t.Errorf("ParsePath = %+v (%s)", elems, got)
+	}
+	for _, bad := range []string{"", "server..listen", "repos[x]", "[0]"} {
+		if _, err := ParsePath(bad); err == nil {
+			t.Errorf("ParsePath(%q) should fail", bad)
+		}
+	}
+}
+
+func TestSetValue(t *testing.T) {
+	out, err := SetValue([]byte(setTestConfig), "server.listen", "127.0.0.1:9000")
+	if err != nil {
+		t.Fatalf("SetValue failed: %v", err)
+	}
+	s := string(out)
+	for _, want := range []string{"# airgap config", "\n\nexport:\n", `listen: "127.0.0.1:9000" # all interfaces`, `data_dir: "/var/lib/airgap"`} {
+		if !strings.Contains(s, want) {
+			t.Errorf("output missing %q:\n%s", want, s)
+		}
+	}
+	if strings.Index(s, "server:") > strings.Index(s, "export:") || strings.Index(s, "export:") > strings.Index(s, "schedule:") {
+		t.Errorf("section order changed:\n%s", s)
+	}
+
+	out, err = SetValue(out, "database.retention.jobs_days", "30")
+	if err != nil {
+		t.Fatalf("SetValue of a new section failed: %v", err)
+	}
+	out, err = SetValue(out, "boot.hosts[0].mac", "52:54:00:aa:bb:cc")
+	if err != nil {
+		t.Fatalf("SetValue appending a list item failed: %v", err)
+	}
+	cfg := DefaultConfig()
+	if err := yaml.Unmarshal(out, cfg); err != nil {
+		t.Fatal(err)
+	}
+	if cfg.Server.Listen != "127.0.0.1:9000" || cfg.Database.Retention.JobsDays != 30 || len(cfg.Boot.Hosts) != 1 || cfg.Boot.Hosts[0].MAC != "52:54:00:aa:bb:cc" {
+		t.Errorf("config after SetValue = %+v", cfg)
+	}
+
+	errTests := []struct {
+		path, value, want string
+	}{
+		{"server.lisen", "x", `did you mean "listen"`},
+		{"bogus.key", "x", "valid keys:"},
+		{"schedule.enabled", "sometimes", "must be true or false"},
+		{"database.retention.jobs_days", "ten", "must be an integer"},
+		{"boot.hosts[3].mac", "x", "out of range"},
+		{"server.listen.port", "1", "has no key"},
+		{"providers.epel.enabled", "true", "stored in the database"},
+	}
+	for _, tt := range errTests {
+		_, err := SetValue([]byte(setTestConfig), tt.path, tt.value)
+		if err == nil || !strings.Contains(err.Error(), tt.want) {
+			t.Errorf("SetValue(%s, %s) error = %v, want %q", tt.path, tt.value, err, tt.want)
+		}
+	}
+}
+
+func TestSetProviderValue(t *testing.T) {
+	in := `{"enabled":true,"repos":[{"name":"epel-9","base_url":"https://old"}]}`
+
+	out, err := SetProviderValue("epel", in, "repos[0].base_url", "https://new")
+	if err != nil {
+		t.Fatalf("SetProviderValue failed: %v", err)
+	}
+	if !strings.Contains(out, `"base_url":"https://new"`) || !strings.Contains(out, `"name":"epel-9"`) {
+		t.Errorf("updated config = %s", out)
+	}
+
+	out, err = SetProviderValue("epel", out, "repos[0].gpg_keys", "/etc/pki/a.key, /etc/pki/b.key")
+	if err != nil {
+		t.Fatalf("SetProviderValue of a list failed: %v", err)
+	}
+	if !strings.Contains(out, `"gpg_keys":["/etc/pki/a.key","/etc/pki/b.key"]`) {
+		t.Errorf("updated config = %s", out)
+	}
+
+	if _, err := SetProviderValue("epel", in, "repos[0].baseurl", "x"); err == nil || !strings.Contains(err.Error(), `did you mean "base_url"`) {
+		t.Errorf("unknown key error = %v", err)
+	}
+	if _, err := SetProviderValue("epel", in, "max_concurrent_downloads", "many"); err == nil {
+		t.Error("non-integer value should be rejected")
+	}
+	if _, err := SetProviderValue("ocp_clients", `{}`, "platforms", "linux,solaris"); err == nil || !strings.Contains(err.Error(), "platforms[1]: must be one of") {
+		t.Errorf("enum error = %v", err)
+	}
+	if _, err := SetProviderValue("operator_catalog", `{}`, "catalogs", `[{index: x, packages: [{name: a, chanels: [s]}]}]`); err == nil || !strings.Contains(err.Error(), "chanels") {
+		t.Errorf("unknown nested key error = %v", err)
+	}
+}
+
+func TestSuggest(t *testing.T) {
+	keys := []string{"listen", "data_dir", "db_path"}
+	if got := Suggest("data-dir", keys); got != "data_dir" {
+		t.Errorf("Suggest(data-dir) = %q", got)
+	}
+	if got := Suggest("compression", keys); got != "" {
+		t.Errorf("Suggest(compression) = %q, want none", got)
+	}
+}
@@ -29,7 +29,8 @@ type DownloadOptions struct {
 	ExpectedChecksum string // SHA256 hex string, empty to skip validation
 	ExpectedSize     int64  // 0 to skip size check
 	Headers          map[string]string
-	RetryCount       int // 0 defaults to 3
+	Authorize        func(*http.Request) // sets credentials on each attempt
+	RetryCount       int                 // 0 defaults to 3
 	OnProgress       ProgressFunc
 }
 
@@ -41,6 +42,12 @@ type DownloadResult struct {
 	Resumed  bool          // Whether the download was resumed
 	Attempts int           // Number of attempts made
 	Duration time.Duration // Total download duration
+
+	// Provenance of the successful attempt
+	URL          string        // Final URL after redirects
+	ETag         string        // Upstream ETag header, if any
+	LastModified string        // Upstream Last-Modified header, if any
+	ResponseTime time.Duration // Time until response headers arrived
 }
 
 // BackoffFunc calculates the delay before a retry attempt.
@@ -75,6 +82,38 @@ func NewClient(logger *slog.Logger) *Client {
 	}
 }
 
+// Size asks upstream for the size of url with a HEAD request. It returns 0
+// when the server does not send a Content-Length.
+func (c *Client) Size(ctx context.Context, url string, headers map[string]string, authorize func(*http.Request)) (int64, error) {
+	req, err := http.NewRequestWithContext(ctx, http.MethodHead, url, nil)
+	if err != nil {
+		return 0, fmt.Errorf("failed to create request: %w", err)
+	}
+	req.Header.Set("User-Agent", c.userAgent)
+	for k, v := range headers {
+		if k == "" || v == "" {
+			continue
+		}
+		req.Header.Set(k, v)
+	}
+	if authorize != nil {
+		authorize(req)
+	}
+
+	resp, err := c.httpClient.Do(req)
+	if err != nil {
+		return 0, fmt.Errorf("http request failed: %w", err)
+	}
+	_ = resp.Body.Close()
+	if resp.StatusCode < 200 || resp.StatusCode >= 300 {
+		return 0, &HTTPError{StatusCode: resp.StatusCode, Status: resp.Status}
+	}
+	if resp.ContentLength < 0 {
+		return 0, nil
+	}
+	return resp.ContentLength, nil
+}
+
 // Download downloads a file from the given URL to the destination path.
 // It supports resumable downloads, retries with exponential backoff, and checksum validation.
 func (c *Client) Download(ctx context.Context, opts DownloadOptions) (*DownloadResult, error) {
@@ -192,16 +231,21 @@ func (c *Client) downloadAttempt(ctx context.Context, file *os.File, opts Downlo
 		}
 		req.Header.Set(k, v)
 	}
+	if opts.Authorize != nil {
+		opts.Authorize(req)
+	}
 
 	// Set Range header if we're resuming
 	if fileSize > 0 {
 		req.Header.Set("Range", fmt.Sprintf("bytes=%d-", fileSize))
 	}
 
+	requestStart := time.Now()
 	resp, err := c.httpClient.Do(req)
 	if err != nil {
 		return nil, fmt.Errorf("http request failed: %w", err)
 	}
+	responseTime := time.Since(requestStart)
 	defer func() {
 		if closeErr := resp.Body.Close(); closeErr != nil {
 			c.logger.Warn("failed to close response body", "url", opts.URL, "error", closeErr)
@@ -287,10 +331,14 @@ func (c *Client) downloadAttempt(ctx context.Context, file *os.File, opts Downlo
 	}
 
 	return &DownloadResult{
-		Path:     opts.DestPath,
-		Size:     finalSize,
-		SHA256:   sha256Hex,
-		Attempts: attempt,
+		Path:         opts.DestPath,
+		Size:         finalSize,
+		SHA256:       sha256Hex,
+		Attempts:     attempt,
+		URL:          resp.Request.URL.String(),
+		ETag:         resp.Header.Get("ETag"),
+		LastModified: resp.Header.Get("Last-Modified"),
+		ResponseTime: responseTime,
 	}, nil
 }
 
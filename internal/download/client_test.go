@@ -141,6 +141,42 @@ func TestDownloadFileWithHeaders(t *testing.T) {
 	}
 }
 
+// TestDownloadFileProvenance follows a mirror redirect and records where the bytes came from
+func TestDownloadFileProvenance(t *testing.T) {
+	mirror := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		w.Header().Set("ETag", `"abc123"`)
+		w.Header().Set("Last-Modified", "Tue, 01 Jul 2025 10:00:00 GMT")
+		_, _ = w.Write([]byte("mirrored content"))
+	}))
+	defer mirror.Close()
+	origin := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		http.Redirect(w, r, mirror.URL+"/pub/file.rpm", http.StatusFound)
+	}))
+	defer origin.Close()
+
+	client := newTestClient(slog.New(slog.NewTextHandler(io.Discard, nil)))
+	result, err := client.Download(context.Background(), DownloadOptions{
+		URL:      origin.URL + "/file.rpm",
+		DestPath: filepath.Join(t.TempDir(), "file.rpm"),
+	})
+	if err != nil {
+		t.Fatalf("expected no error, got %v", err)
+	}
+
+	if result.URL != mirror.URL+"/pub/file.rpm" {
+		t.Errorf("expected final URL %s, got %s", mirror.URL+"/pub/file.rpm", result.URL)
+	}
+	if result.ETag != `"abc123"` {
+		t.Errorf("expected ETag \"abc123\", got %s", result.ETag)
+	}
+	if result.LastModified != "Tue, 01 Jul 2025 10:00:00 GMT" {
+		t.Errorf("unexpected Last-Modified %q", result.LastModified)
+	}
+	if result.ResponseTime <= 0 {
+		t.Errorf("expected positive response time, got %v", result.ResponseTime)
+	}
+}
+
 // TestDownloadFileWithChecksum downloads with expected SHA256, verify it validates
 func TestDownloadFileWithChecksum(t *testing.T) {
 	testContent := []byte("Content with checksum validation")
@@ -589,3 +625,26 @@ func TestDownloadFileHTTPError(t *testing.T) {
 		t.Errorf("expected error message %s, got %s", expectedMsg, httpErr.Error())
 	}
 }
+
+// TestSize reads the Content-Length of a HEAD response
+func TestSize(t *testing.T) {
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		if r.Method != http.MethodHead || r.Header.Get("Authorization") != "Bearer x" {
+			w.WriteHeader(http.StatusUnauthorized)
+			return
+		}
+		w.Header().Set("Content-Length", "1234")
+	}))
+	defer server.Close()
+
+	client := newTestClient(slog.New(slog.NewTextHandler(io.Discard, nil)))
+	size, err := client.Size(context.Background(), server.URL, nil, func(r *http.Request) {
+		r.Header.Set("Authorization", "Bearer x")
+	})
+	if err != nil || size != 1234 {
+		t.Errorf("Size = %d, %v; want 1234", size, err)
+	}
+	if _, err := client.Size(context.Background(), server.URL, nil, nil); err == nil {
+		t.Error("expected an error for an unauthorized HEAD")
+	}
+}
@@ -3,6 +3,7 @@ package download
 import (
 	"context"
 	"log/slog"
+	"net/http"
 	"path/filepath"
 	"sort"
 	"sync"
@@ -15,6 +16,7 @@ type Job struct {
 	ExpectedChecksum string
 	ExpectedSize     int64
 	Headers          map[string]string
+	Authorize        func(*http.Request)
 }
 
 // Result represents the result of a download job.
@@ -133,6 +135,7 @@ func (p *Pool) worker(ctx context.Context, jobsChan <-chan jobWithIndex, results
 			ExpectedChecksum: jobWithIdx.job.ExpectedChecksum,
 			ExpectedSize:     jobWithIdx.job.ExpectedSize,
 			Headers:          jobWithIdx.job.Headers,
+			Authorize:        jobWithIdx.job.Authorize,
 			RetryCount:       3,
 		}
 
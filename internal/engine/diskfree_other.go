@@ -0,0 +1,9 @@
+//go:build !unix
+
+package engine
+
+import "errors"
+
+func diskFree(dir string) (int64, error) {
+	return 0, errors.New("free space check is not supported on this platform")
+}
@@ -13,6 +13,7 @@ import (
 	"strings"
 	"time"
 
+	"github.com/BadgerOps/airgap/internal/provider"
 	"github.com/BadgerOps/airgap/internal/store"
 	"github.com/klauspost/compress/zstd"
 )
@@ -23,23 +24,27 @@ type ExportOptions struct {
 	Providers   []string
 	SplitSize   int64
 	Compression string
+	// IncludeState writes a sanitized snapshot of provider configs and sync
+	// history next to the manifest.
+	IncludeState bool
 }
 
 // ExportReport summarizes a completed export.
 type ExportReport struct {
-	Archives     []ArchiveInfo
-	TotalFiles   int
-	TotalSize    int64
-	ManifestPath string
-	Duration     time.Duration
+	Archives     []ArchiveInfo `json:"archives"`
+	TotalFiles   int           `json:"total_files"`
+	TotalSize    int64         `json:"total_size"`
+	ManifestPath string        `json:"manifest_path"`
+	StatePath    string        `json:"state_path"`
+	Duration     time.Duration `json:"duration_ns"`
 }
 
 // ArchiveInfo describes one split archive.
 type ArchiveInfo struct {
-	Name   string
-	Size   int64
-	SHA256 string
-	Files  []string
+	Name   string   `json:"name"`
+	Size   int64    `json:"size"`
+	SHA256 string   `json:"sha256"`
+	Files  []string `json:"files"`
 }
 
 // Export creates split tar.zst archives of synced content for air-gapped transfer.
@@ -65,10 +70,12 @@ func (m *SyncManager) Export(ctx context.Context, opts ExportOptions) (*ExportRe
 		absPath  string // absolute on disk
 		size     int64
 		sha256   string
+		prov     *ManifestProvenance
 	}
 
 	var allFiles []fileEntry
 	providerSummary := make(map[string]ManifestProvider)
+	exportedRecords := make(map[string][]store.FileRecord)
 
 	for _, provName := range opts.Providers {
 		records, err := m.store.ListFileRecords(provName)
@@ -94,10 +101,13 @@ func (m *SyncManager) Export(ctx context.Context, opts ExportOptions) (*ExportRe
 				absPath:  absPath,
 				size:     rec.Size,
 				sha256:   rec.SHA256,
+				prov:     manifestProvenance(rec),
 			})
+			exportedRecords[provName] = append(exportedRecords[provName], rec)
 			mp.FileCount++
 			mp.TotalSize += rec.Size
 		}
+		mp.SignatureStatus = m.exportSignatureStatus(exportedRecords[provName])
 		providerSummary[provName] = mp
 	}
 
@@ -233,10 +243,11 @@ func (m *SyncManager) Export(ctx context.Context, opts ExportOptions) (*ExportRe
 	var totalSize int64
 	for _, f := range allFiles {
 		fileInventory = append(fileInventory, ManifestFile{
-			Provider: f.provider,
-			Path:     f.relPath,
-			Size:     f.size,
-			SHA256:   f.sha256,
+			Provider:   f.provider,
+			Path:       f.relPath,
+			Size:       f.size,
+			SHA256:     f.sha256,
+			Provenance: f.prov,
 		})
 		totalSize += f.size
 	}
@@ -252,6 +263,16 @@ func (m *SyncManager) Export(ctx context.Context, opts ExportOptions) (*ExportRe
 		FileInventory: fileInventory,
 	}
 
+	var statePath string
+	if opts.IncludeState {
+		ref, err := writeStateSnapshot(opts.OutputDir, m.buildStateSnapshot(exportedRecords, hostname))
+		if err != nil {
+			return nil, err
+		}
+		manifest.State = ref
+		statePath = filepath.Join(opts.OutputDir, ref.Name)
+	}
+
 	// Write manifest JSON
 	manifestPath := filepath.Join(opts.OutputDir, "airgap-manifest.json")
 	manifestData, err := json.MarshalIndent(manifest, "", "  ")
@@ -288,6 +309,7 @@ func (m *SyncManager) Export(ctx context.Context, opts ExportOptions) (*ExportRe
 		ArchiveCount: len(archives),
 		TotalSize:    totalSize,
 		ManifestHash: manifestHash,
+		Manifest:     string(manifestData),
 		Status:       "completed",
 		StartTime:    startTime,
 		EndTime:      time.Now(),
@@ -309,6 +331,7 @@ func (m *SyncManager) Export(ctx context.Context, opts ExportOptions) (*ExportRe
 		TotalFiles:   len(allFiles),
 		TotalSize:    totalSize,
 		ManifestPath: manifestPath,
+		StatePath:    statePath,
 		Duration:     duration,
 	}, nil
 }
@@ -405,3 +428,36 @@ func generateTransferReadme(m *TransferManifest) string {
 	b.WriteString("- Re-run: airgap import --from /mnt/usb\n")
 	return b.String()
 }
+
+// exportSignatureStatus summarizes the upstream signature status of the sync
+// runs that downloaded records. It is verified only when every file came
+// from a verified run, and unverified when any came from an unverified run
+// or from a run without a status alongside verified ones.
+func (m *SyncManager) exportSignatureStatus(records []store.FileRecord) string {
+	statuses := make(map[int64]string)
+	var verified, unverified, unknown int
+	for _, rec := range records {
+		status, ok := statuses[rec.SyncRunID]
+		if !ok && rec.SyncRunID != 0 {
+			if run, err := m.store.GetSyncRun(rec.SyncRunID); err == nil {
+				status = run.SignatureStatus
+			}
+			statuses[rec.SyncRunID] = status
+		}
+		switch status {
+		case provider.SignatureVerified:
+			verified++
+		case provider.SignatureUnverified:
+			unverified++
+		default:
+			unknown++
+		}
+	}
+	switch {
+	case unverified > 0 || (verified > 0 && unknown > 0):
+		return provider.SignatureUnverified
+	case verified > 0:
+		return provider.SignatureVerified
+	}
+	return ""
+}
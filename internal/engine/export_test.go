@@ -143,6 +143,9 @@ func TestExportCreatesArchivesAndManifest(t *testing.T) {
 	if len(manifest.FileInventory) != 3 {
 		t.Errorf("manifest file_inventory count = %d, want 3", len(manifest.FileInventory))
 	}
+	if recorded, _, err := mgr.TransferManifest(1); err != nil || len(recorded.FileInventory) != 3 {
+		t.Errorf("recorded transfer manifest = %+v, %v", recorded, err)
+	}
 
 	// Verify TRANSFER-README.txt exists
 	readmePath := filepath.Join(outputDir, "TRANSFER-README.txt")
@@ -376,6 +379,77 @@ func TestExportManifestIncludesProviderType(t *testing.T) {
 	}
 }
 
+func TestExportManifestRecordsSignatureStatus(t *testing.T) {
+	mgr, _, outputDir := setupExportTest(t)
+
+	// The run that downloaded the oc binary was verified; a later run that
+	// downloaded nothing was not, and must not change the exported status.
+	verifiedRun := &store.SyncRun{Provider: "ocp_binaries", StartTime: time.Now().Add(-2 * time.Hour), Status: "completed", SignatureStatus: provider.SignatureVerified}
+	runs := []*store.SyncRun{
+		verifiedRun,
+		{Provider: "ocp_binaries", StartTime: time.Now().Add(-time.Hour), Status: "completed", SignatureStatus: provider.SignatureUnverified},
+		{Provider: "epel", StartTime: time.Now(), Status: "completed"},
+	}
+	for _, run := range runs {
+		if err := mgr.store.CreateSyncRun(run); err != nil {
+			t.Fatal(err)
+		}
+	}
+	records, err := mgr.store.ListFileRecords("ocp_binaries")
+	if err != nil || len(records) != 1 {
+		t.Fatalf("ListFileRecords() = %v, %v", records, err)
+	}
+	records[0].SyncRunID = verifiedRun.ID
+	if err := mgr.store.UpsertFileRecord(&records[0]); err != nil {
+		t.Fatal(err)
+	}
+
+	report, err := mgr.Export(context.Background(), ExportOptions{
+		OutputDir:   outputDir,
+		Providers:   []string{"epel", "ocp_binaries"},
+		SplitSize:   1024 * 1024 * 1024,
+		Compression: "zstd",
+	})
+	if err != nil {
+		t.Fatalf("Export() error: %v", err)
+	}
+	manifestData, err := os.ReadFile(report.ManifestPath)
+	if err != nil {
+		t.Fatalf("read manifest: %v", err)
+	}
+	var manifest TransferManifest
+	if err := json.Unmarshal(manifestData, &manifest); err != nil {
+		t.Fatalf("unmarshal manifest: %v", err)
+	}
+	if got := manifest.Providers["ocp_binaries"].SignatureStatus; got != provider.SignatureVerified {
+		t.Errorf("ocp_binaries signature status = %q, want %q", got, provider.SignatureVerified)
+	}
+	if got := manifest.Providers["epel"].SignatureStatus; got != "" {
+		t.Errorf("epel signature status = %q, want empty", got)
+	}
+
+	mgr.config.Server.DataDir = t.TempDir()
+	importReport, err := mgr.Import(context.Background(), ImportOptions{SourceDir: outputDir, VerifyOnly: true})
+	if err != nil {
+		t.Fatalf("Import() error: %v", err)
+	}
+	if len(importReport.Signatures) != 1 || importReport.Signatures["ocp_binaries"] != provider.SignatureVerified {
+		t.Errorf("import signatures = %v, want ocp_binaries verified", importReport.Signatures)
+	}
+
+	// Files from an unverified run, or of unknown origin alongside verified
+	// ones, make the provider unverified.
+	unverifiedRun := runs[1]
+	for _, recs := range [][]store.FileRecord{
+		{{SyncRunID: verifiedRun.ID}, {SyncRunID: unverifiedRun.ID}},
+		{{SyncRunID: verifiedRun.ID}, {}},
+	} {
+		if got := mgr.exportSignatureStatus(recs); got != provider.SignatureUnverified {
+			t.Errorf("exportSignatureStatus(%+v) = %q, want %q", recs, got, provider.SignatureUnverified)
+		}
+	}
+}
+
 func TestImportSkipValidatedArchives(t *testing.T) {
 	mgr, _, outputDir := setupExportTest(t)
 
@@ -11,6 +11,7 @@ import (
 	"strings"
 	"time"
 
+	"github.com/BadgerOps/airgap/internal/provider"
 	"github.com/BadgerOps/airgap/internal/safety"
 	"github.com/BadgerOps/airgap/internal/store"
 	"github.com/klauspost/compress/zstd"
@@ -26,13 +27,20 @@ type ImportOptions struct {
 
 // ImportReport summarizes a completed import.
 type ImportReport struct {
-	ArchivesValidated int
-	ArchivesFailed    int
-	ArchivesSkipped   int
-	FilesExtracted    int
-	TotalSize         int64
-	Duration          time.Duration
-	Errors            []string
+	ArchivesValidated int           `json:"archives_validated"`
+	ArchivesFailed    int           `json:"archives_failed"`
+	ArchivesSkipped   int           `json:"archives_skipped"`
+	FilesExtracted    int           `json:"files_extracted"`
+	TotalSize         int64         `json:"total_size"`
+	Duration          time.Duration `json:"duration_ns"`
+	Errors            []string      `json:"errors"`
+	// Signatures maps providers to the upstream signature status recorded
+	// in the manifest.
+	Signatures map[string]string `json:"signatures"`
+	// SourceHost and UpstreamSyncRuns describe the high-side state snapshot
+	// imported with the transfer, if it had one.
+	SourceHost       string `json:"source_host"`
+	UpstreamSyncRuns int    `json:"upstream_sync_runs"`
 }
 
 // Import reads an airgap transfer package and extracts its contents.
@@ -69,6 +77,7 @@ func (m *SyncManager) Import(ctx context.Context, opts ImportOptions) (*ImportRe
 	transfer := &store.Transfer{
 		Direction: "import",
 		Path:      opts.SourceDir,
+		Manifest:  string(manifestData),
 		Status:    "running",
 		StartTime: startTime,
 	}
@@ -76,7 +85,18 @@ func (m *SyncManager) Import(ctx context.Context, opts ImportOptions) (*ImportRe
 		m.logger.Warn("failed to record transfer", "error", err)
 	}
 
-	report := &ImportReport{}
+	report := &ImportReport{Signatures: make(map[string]string)}
+	for name, prov := range manifest.Providers {
+		switch prov.SignatureStatus {
+		case provider.SignatureVerified:
+			m.logger.Info("provider content was signature-checked upstream", "provider", name)
+		case provider.SignatureUnverified:
+			m.logger.Warn("provider content was not signature-checked upstream", "provider", name)
+		default:
+			continue
+		}
+		report.Signatures[name] = prov.SignatureStatus
+	}
 	skippedArchives := make(map[string]bool)
 
 	// Validate archives
@@ -140,6 +160,22 @@ func (m *SyncManager) Import(ctx context.Context, opts ImportOptions) (*ImportRe
 		}
 	}
 
+	var state *StateSnapshot
+	if manifest.State != nil {
+		state, err = readStateSnapshot(opts.SourceDir, manifest.State)
+		if err != nil {
+			report.Errors = append(report.Errors, err.Error())
+			report.Duration = time.Since(startTime)
+			if transfer.ID != 0 {
+				transfer.Status = "failed"
+				transfer.ErrorMessage = err.Error()
+				transfer.EndTime = time.Now()
+				_ = m.store.UpdateTransfer(transfer)
+			}
+			return report, err
+		}
+	}
+
 	// If any archives failed, stop
 	if report.ArchivesFailed > 0 {
 		report.Duration = time.Since(startTime)
@@ -206,6 +242,14 @@ func (m *SyncManager) Import(ctx context.Context, opts ImportOptions) (*ImportRe
 		}
 	}
 
+	// Record upstream sync history so files keep their fetch time and run
+	var upstreamFiles map[string]map[string]StateFile
+	if state != nil {
+		upstreamFiles, report.UpstreamSyncRuns = m.importStateSnapshot(state)
+		report.SourceHost = state.SourceHost
+		m.logger.Info("imported upstream state", "source_host", state.SourceHost, "sync_runs", report.UpstreamSyncRuns)
+	}
+
 	// Upsert file records from manifest inventory
 	for _, f := range manifest.FileInventory {
 		absPath, err := safety.SafeJoinUnder(m.config.Server.DataDir, filepath.Join(f.Provider, f.Path))
@@ -225,11 +269,32 @@ func (m *SyncManager) Import(ctx context.Context, opts ImportOptions) (*ImportRe
 			LastModified: time.Now(),
 			LastVerified: time.Now(),
 		}
+		if f.Provenance != nil {
+			applyManifestProvenance(rec, f.Provenance)
+		}
+		if uf, ok := upstreamFiles[f.Provider][f.Path]; ok {
+			rec.SyncRunID = uf.SyncRunID
+			if !uf.FetchedAt.IsZero() {
+				rec.LastModified = uf.FetchedAt
+			}
+		}
 		if err := m.store.UpsertFileRecord(rec); err != nil {
 			m.logger.Warn("failed to upsert file record", "path", f.Path, "error", err)
 		}
 	}
 
+	// Index imported content for search with the local provider config, or
+	// the one shipped in the state snapshot when there is none
+	for name := range manifest.Providers {
+		if pc, err := m.store.GetProviderConfig(name); err == nil {
+			m.refreshSearchIndex(name, pc.Type, pc.ConfigJSON)
+		} else if state != nil {
+			if up, err := m.store.GetUpstreamProviderConfig(state.SourceHost, name); err == nil {
+				m.refreshSearchIndex(name, up.Type, up.ConfigJSON)
+			}
+		}
+	}
+
 	report.Duration = time.Since(startTime)
 
 	// Update transfer record
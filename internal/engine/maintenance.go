@@ -0,0 +1,116 @@
+package engine
+
+import (
+	"context"
+	"fmt"
+	"time"
+
+	"github.com/BadgerOps/airgap/internal/config"
+	"github.com/BadgerOps/airgap/internal/store"
+)
+
+// MaintenanceReport summarizes one store maintenance run.
+type MaintenanceReport struct {
+	Pruned     store.PruneResult
+	SizeBefore int64
+	SizeAfter  int64
+	Duration   time.Duration
+}
+
+// Reclaimed returns the bytes freed in the database file.
+func (r *MaintenanceReport) Reclaimed() int64 {
+	if r.SizeAfter >= r.SizeBefore {
+		return 0
+	}
+	return r.SizeBefore - r.SizeAfter
+}
+
+// retentionPolicy converts the configured retention to a store policy.
+func retentionPolicy(cfg config.RetentionConfig) store.RetentionPolicy {
+	day := 24 * time.Hour
+	return store.RetentionPolicy{
+		SyncRunsPerProvider: cfg.SyncRunsPerProvider,
+		ResolvedFailuresAge: time.Duration(cfg.ResolvedFailuresDays) * day,
+		TransfersAge:        time.Duration(cfg.TransfersDays) * day,
+		JobsAge:             time.Duration(cfg.JobsDays) * day,
+	}
+}
+
+// Maintain prunes store history per database.retention, then runs VACUUM
+// and ANALYZE.
+func (m *SyncManager) Maintain(ctx context.Context) (*MaintenanceReport, error) {
+	start := time.Now()
+	report := &MaintenanceReport{}
+
+	sizeBefore, err := m.store.Size()
+	if err != nil {
+		return nil, err
+	}
+	report.SizeBefore = sizeBefore
+
+	pruned, err := m.store.Prune(retentionPolicy(m.config.Database.Retention), start)
+	if err != nil {
+		return nil, err
+	}
+	report.Pruned = *pruned
+
+	if err := ctx.Err(); err != nil {
+		return nil, err
+	}
+	if err := m.store.Optimize(); err != nil {
+		return nil, err
+	}
+
+	sizeAfter, err := m.store.Size()
+	if err != nil {
+		return nil, err
+	}
+	report.SizeAfter = sizeAfter
+	report.Duration = time.Since(start)
+
+	m.logger.Info("database maintenance complete",
+		"sync_runs", pruned.SyncRuns,
+		"failed_files", pruned.FailedFiles,
+		"transfers", pruned.Transfers,
+		"transfer_archives", pruned.TransferArchives,
+		"jobs", pruned.Jobs,
+		"size_before", report.SizeBefore,
+		"size_after", report.SizeAfter,
+		"reclaimed", report.Reclaimed(),
+		"duration", report.Duration)
+	return report, nil
+}
+
+// MaintenanceInterval parses database.maintenance_interval; zero means
+// scheduled maintenance is disabled.
+func MaintenanceInterval(cfg config.DatabaseConfig) (time.Duration, error) {
+	if cfg.MaintenanceInterval == "" || cfg.MaintenanceInterval == "0" {
+		return 0, nil
+	}
+	interval, err := time.ParseDuration(cfg.MaintenanceInterval)
+	if err != nil {
+		return 0, fmt.Errorf("invalid database.maintenance_interval %q: %w", cfg.MaintenanceInterval, err)
+	}
+	if interval < 0 {
+		return 0, fmt.Errorf("invalid database.maintenance_interval %q: must not be negative", cfg.MaintenanceInterval)
+	}
+	return interval, nil
+}
+
+// RunMaintenance runs Maintain every interval until ctx is cancelled.
+// Failures are logged and retried at the next interval.
+func (m *SyncManager) RunMaintenance(ctx context.Context, interval time.Duration) {
+	ticker := time.NewTicker(interval)
+	defer ticker.Stop()
+	m.logger.Info("scheduled database maintenance enabled", "interval", interval)
+	for {
+		select {
+		case <-ctx.Done():
+			return
+		case <-ticker.C:
+			if _, err := m.Maintain(ctx); err != nil && ctx.Err() == nil {
+				m.logger.Error("database maintenance failed", "error", err)
+			}
+		}
+	}
+}
@@ -0,0 +1,74 @@
+package engine
+
+import (
+	"context"
+	"testing"
+	"time"
+
+	"github.com/BadgerOps/airgap/internal/config"
+	"github.com/BadgerOps/airgap/internal/provider"
+	"github.com/BadgerOps/airgap/internal/store"
+)
+
+func TestMaintainPrunesPerRetention(t *testing.T) {
+	manager, st := newTestSyncManager(t, provider.NewRegistry())
+	manager.config.Database.Retention = config.RetentionConfig{SyncRunsPerProvider: 1, JobsDays: 30}
+
+	now := time.Now()
+	for i := 0; i < 3; i++ {
+		if err := st.CreateSyncRun(&store.SyncRun{Provider: "epel", StartTime: now.Add(time.Duration(-i) * time.Hour), Status: "success"}); err != nil {
+			t.Fatalf("CreateSyncRun() failed: %v", err)
+		}
+	}
+	old := now.Add(-60 * 24 * time.Hour)
+	if err := st.CreateJob(&store.Job{Type: "sync", Status: "completed", CreatedAt: old, UpdatedAt: old}); err != nil {
+		t.Fatalf("CreateJob() failed: %v", err)
+	}
+
+	report, err := manager.Maintain(context.Background())
+	if err != nil {
+		t.Fatalf("Maintain() failed: %v", err)
+	}
+	if report.Pruned.SyncRuns != 2 || report.Pruned.Jobs != 1 {
+		t.Errorf("Pruned = %+v, want 2 sync runs and 1 job", report.Pruned)
+	}
+	if report.SizeBefore <= 0 || report.SizeAfter <= 0 {
+		t.Errorf("sizes = %d -> %d, want both > 0", report.SizeBefore, report.SizeAfter)
+	}
+	if report.Reclaimed() < 0 {
+		t.Errorf("Reclaimed() = %d, want >= 0", report.Reclaimed())
+	}
+
+	runs, err := st.ListSyncRuns("epel", 0)
+	if err != nil {
+		t.Fatalf("ListSyncRuns() failed: %v", err)
+	}
+	if len(runs) != 1 {
+		t.Errorf("sync runs = %d, want 1", len(runs))
+	}
+}
+
+func TestMaintenanceInterval(t *testing.T) {
+	tests := []struct {
+		value   string
+		want    time.Duration
+		wantErr bool
+	}{
+		{value: "", want: 0},
+		{value: "0", want: 0},
+		{value: "24h", want: 24 * time.Hour},
+		{value: "90m", want: 90 * time.Minute},
+		{value: "daily", wantErr: true},
+		{value: "-1h", wantErr: true},
+	}
+	for _, tt := range tests {
+		got, err := MaintenanceInterval(config.DatabaseConfig{MaintenanceInterval: tt.value})
+		if (err != nil) != tt.wantErr {
+			t.Errorf("MaintenanceInterval(%q) error = %v, wantErr %v", tt.value, err, tt.wantErr)
+			continue
+		}
+		if got != tt.want {
+			t.Errorf("MaintenanceInterval(%q) = %v, want %v", tt.value, got, tt.want)
+		}
+	}
+}
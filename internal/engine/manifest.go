@@ -12,6 +12,8 @@ type TransferManifest struct {
 	TotalArchives int                         `json:"total_archives"`
 	TotalSize     int64                       `json:"total_size"`
 	FileInventory []ManifestFile              `json:"file_inventory"`
+	// State is the high-side state snapshot, when the export included one.
+	State *ManifestState `json:"state,omitempty"`
 }
 
 // ManifestProvider summarizes one provider's contribution to the export.
@@ -19,6 +21,10 @@ type ManifestProvider struct {
 	Type      string `json:"type,omitempty"`
 	FileCount int    `json:"file_count"`
 	TotalSize int64  `json:"total_size"`
+	// SignatureStatus is the upstream signature status of the sync runs
+	// that downloaded the exported files ("verified" or "unverified"), when
+	// they have one.
+	SignatureStatus string `json:"signature_status,omitempty"`
 }
 
 // ManifestArchive describes a single split archive in the export.
@@ -35,4 +41,6 @@ type ManifestFile struct {
 	Path     string `json:"path"`
 	Size     int64  `json:"size"`
 	SHA256   string `json:"sha256"`
+	// Provenance is where the exporting host downloaded the file from.
+	Provenance *ManifestProvenance `json:"provenance,omitempty"`
 }
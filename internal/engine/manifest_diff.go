@@ -0,0 +1,509 @@
+package engine
+
+import (
+	"encoding/csv"
+	"encoding/json"
+	"fmt"
+	"io"
+	"os"
+	"path/filepath"
+	"sort"
+	"strconv"
+	"strings"
+	"time"
+	"unicode"
+)
+
+// ManifestDiffVersion is the schema version of the JSON form of
+// ManifestDiff. It is bumped whenever a field is renamed or removed.
+const ManifestDiffVersion = 1
+
+// Change kinds of a manifest diff.
+const (
+	ChangeAdded      = "added"
+	ChangeRemoved    = "removed"
+	ChangeChanged    = "changed"
+	ChangeUpgraded   = "upgraded"
+	ChangeDowngraded = "downgraded"
+)
+
+// ManifestDiff is the difference between the file inventories of two
+// transfer manifests.
+type ManifestDiff struct {
+	Version   int                    `json:"version"`
+	From      ManifestDiffSide       `json:"from"`
+	To        ManifestDiffSide       `json:"to"`
+	Providers []ProviderManifestDiff `json:"providers"`
+	Totals    ManifestDiffTotals     `json:"totals"`
+}
+
+// ManifestDiffSide describes one of the compared manifests.
+type ManifestDiffSide struct {
+	Label      string    `json:"label"`
+	Created    time.Time `json:"created"`
+	SourceHost string    `json:"source_host,omitempty"`
+	Files      int       `json:"files"`
+	TotalSize  int64     `json:"total_size"`
+}
+
+// ProviderManifestDiff lists the changed files of one provider, with RPM
+// and container image changes interpreted from their paths.
+type ProviderManifestDiff struct {
+	Provider string             `json:"provider"`
+	Files    []FileDiff         `json:"files"`
+	Packages []PackageDiff      `json:"packages,omitempty"`
+	Images   []ImageDiff        `json:"images,omitempty"`
+	Totals   ManifestDiffTotals `json:"totals"`
+}
+
+// FileDiff is an added, removed or changed file. Sizes of the missing side
+// are zero.
+type FileDiff struct {
+	Change    string `json:"change"`
+	Path      string `json:"path"`
+	OldSize   int64  `json:"old_size"`
+	NewSize   int64  `json:"new_size"`
+	SizeDelta int64  `json:"size_delta"`
+	OldSHA256 string `json:"old_sha256,omitempty"`
+	NewSHA256 string `json:"new_sha256,omitempty"`
+}
+
+// PackageDiff is an RPM that was added, removed, upgraded, downgraded or
+// changed in place. Versions are version-release; epochs are not part of
+// RPM file names.
+type PackageDiff struct {
+	Change     string `json:"change"`
+	Name       string `json:"name"`
+	Arch       string `json:"arch"`
+	OldVersion string `json:"old_version,omitempty"`
+	NewVersion string `json:"new_version,omitempty"`
+}
+
+// ImageDiff is a mirrored image reference, identified by its local image
+// directory, whose manifests were added, removed or replaced. A changed
+// tag keeps its directory and gets new manifest digests.
+type ImageDiff struct {
+	Change     string   `json:"change"`
+	Image      string   `json:"image"`
+	OldDigests []string `json:"old_digests,omitempty"`
+	NewDigests []string `json:"new_digests,omitempty"`
+}
+
+// ManifestDiffTotals counts the files of a diff.
+type ManifestDiffTotals struct {
+	Added        int   `json:"added"`
+	Removed      int   `json:"removed"`
+	Changed      int   `json:"changed"`
+	Unchanged    int   `json:"unchanged"`
+	AddedBytes   int64 `json:"added_bytes"`
+	RemovedBytes int64 `json:"removed_bytes"`
+	SizeDelta    int64 `json:"size_delta"`
+}
+
+func (t *ManifestDiffTotals) add(o ManifestDiffTotals) {
+	t.Added += o.Added
+	t.Removed += o.Removed
+	t.Changed += o.Changed
+	t.Unchanged += o.Unchanged
+	t.AddedBytes += o.AddedBytes
+	t.RemovedBytes += o.RemovedBytes
+	t.SizeDelta += o.SizeDelta
+}
+
+// LoadManifestFile reads a transfer manifest from path, which is either
+// the manifest itself or a transfer directory holding airgap-manifest.json.
+func LoadManifestFile(path string) (*TransferManifest, error) {
+	if info, err := os.Stat(path); err == nil && info.IsDir() {
+		path = filepath.Join(path, "airgap-manifest.json")
+	}
+	data, err := os.ReadFile(path)
+	if err != nil {
+		return nil, fmt.Errorf("reading manifest: %w", err)
+	}
+	var manifest TransferManifest
+	if err := json.Unmarshal(data, &manifest); err != nil {
+		return nil, fmt.Errorf("parsing manifest %s: %w", path, err)
+	}
+	return &manifest, nil
+}
+
+// TransferManifest returns the manifest of the recorded transfer id and a
+// label describing it. Transfers recorded before manifests were stored
+// fall back to the manifest in the transfer directory, if it still exists.
+func (m *SyncManager) TransferManifest(id int64) (*TransferManifest, string, error) {
+	if m.store == nil {
+		return nil, "", fmt.Errorf("store not initialized")
+	}
+	t, err := m.store.GetTransfer(id)
+	if err != nil {
+		return nil, "", err
+	}
+	label := fmt.Sprintf("transfer %d (%s %s)", t.ID, t.Direction, t.StartTime.UTC().Format("2006-01-02"))
+
+	if t.Manifest == "" {
+		manifest, err := LoadManifestFile(t.Path)
+		if err != nil {
+			return nil, "", fmt.Errorf("transfer %d has no stored manifest: %w", id, err)
+		}
+		return manifest, label, nil
+	}
+	var manifest TransferManifest
+	if err := json.Unmarshal([]byte(t.Manifest), &manifest); err != nil {
+		return nil, "", fmt.Errorf("parsing manifest of transfer %d: %w", id, err)
+	}
+	return &manifest, label, nil
+}
+
+// DiffManifests compares the file inventories of two manifests by provider
+// and path, using checksums to detect changed files.
+func DiffManifests(from, to *TransferManifest, fromLabel, toLabel string) *ManifestDiff {
+	diff := &ManifestDiff{
+		Version:   ManifestDiffVersion,
+		From:      diffSide(from, fromLabel),
+		To:        diffSide(to, toLabel),
+		Providers: []ProviderManifestDiff{},
+	}
+
+	oldFiles := inventoryByProvider(from)
+	newFiles := inventoryByProvider(to)
+	names := make(map[string]bool)
+	for name := range oldFiles {
+		names[name] = true
+	}
+	for name := range newFiles {
+		names[name] = true
+	}
+	sorted := make([]string, 0, len(names))
+	for name := range names {
+		sorted = append(sorted, name)
+	}
+	sort.Strings(sorted)
+
+	for _, name := range sorted {
+		pd := diffProvider(name, oldFiles[name], newFiles[name])
+		diff.Totals.add(pd.Totals)
+		if len(pd.Files) > 0 {
+			diff.Providers = append(diff.Providers, pd)
+		}
+	}
+	return diff
+}
+
+func diffSide(m *TransferManifest, label string) ManifestDiffSide {
+	side := ManifestDiffSide{
+		Label:      label,
+		Created:    m.Created,
+		SourceHost: m.SourceHost,
+		Files:      len(m.FileInventory),
+	}
+	for _, f := range m.FileInventory {
+		side.TotalSize += f.Size
+	}
+	return side
+}
+
+func inventoryByProvider(m *TransferManifest) map[string]map[string]ManifestFile {
+	out := make(map[string]map[string]ManifestFile)
+	for _, f := range m.FileInventory {
+		if out[f.Provider] == nil {
+			out[f.Provider] = make(map[string]ManifestFile)
+		}
+		out[f.Provider][f.Path] = f
+	}
+	return out
+}
+
+func diffProvider(name string, oldFiles, newFiles map[string]ManifestFile) ProviderManifestDiff {
+	pd := ProviderManifestDiff{Provider: name, Files: []FileDiff{}}
+
+	for path, nf := range newFiles {
+		of, ok := oldFiles[path]
+		switch {
+		case !ok:
+			pd.Files = append(pd.Files, FileDiff{Change: ChangeAdded, Path: path, NewSize: nf.Size, SizeDelta: nf.Size, NewSHA256: nf.SHA256})
+			pd.Totals.Added++
+			pd.Totals.AddedBytes += nf.Size
+		case of.SHA256 != nf.SHA256 || of.Size != nf.Size:
+			pd.Files = append(pd.Files, FileDiff{
+				Change: ChangeChanged, Path: path,
+				OldSize: of.Size, NewSize: nf.Size, SizeDelta: nf.Size - of.Size,
+				OldSHA256: of.SHA256, NewSHA256: nf.SHA256,
+			})
+			pd.Totals.Changed++
+		default:
+			pd.Totals.Unchanged++
+		}
+	}
+	for path, of := range oldFiles {
+		if _, ok := newFiles[path]; !ok {
+			pd.Files = append(pd.Files, FileDiff{Change: ChangeRemoved, Path: path, OldSize: of.Size, SizeDelta: -of.Size, OldSHA256: of.SHA256})
+			pd.Totals.Removed++
+			pd.Totals.RemovedBytes += of.Size
+		}
+	}
+	for _, f := range pd.Files {
+		pd.Totals.SizeDelta += f.SizeDelta
+	}
+
+	sort.Slice(pd.Files, func(i, j int) bool { return pd.Files[i].Path < pd.Files[j].Path })
+	pd.Packages = packageDiffs(pd.Files)
+	pd.Images = imageDiffs(pd.Files, oldFiles, newFiles)
+	return pd
+}
+
+// OtherFiles returns the changed files that are neither RPMs nor container
+// image content, which Packages and Images already describe.
+func (pd ProviderManifestDiff) OtherFiles() []FileDiff {
+	var out []FileDiff
+	for _, f := range pd.Files {
+		if _, ok := parseRPMFileName(f.Path); ok || isImageContentPath(f.Path) {
+			continue
+		}
+		out = append(out, f)
+	}
+	return out
+}
+
+// rpmFile is an RPM identified from its file name.
+type rpmFile struct {
+	name, version, arch string
+}
+
+// parseRPMFileName splits name-version-release.arch.rpm.
+func parseRPMFileName(path string) (rpmFile, bool) {
+	base := filepath.Base(path)
+	if !strings.HasSuffix(base, ".rpm") {
+		return rpmFile{}, false
+	}
+	base = strings.TrimSuffix(base, ".rpm")
+	dot := strings.LastIndex(base, ".")
+	if dot <= 0 {
+		return rpmFile{}, false
+	}
+	nvr, arch := base[:dot], base[dot+1:]
+	rel := strings.LastIndex(nvr, "-")
+	if rel <= 0 {
+		return rpmFile{}, false
+	}
+	ver := strings.LastIndex(nvr[:rel], "-")
+	if ver <= 0 {
+		return rpmFile{}, false
+	}
+	return rpmFile{name: nvr[:ver], version: nvr[ver+1:], arch: arch}, true
+}
+
+// packageDiffs interprets RPM file changes, pairing a removed and an added
+// file of the same name and arch into an upgrade or downgrade.
+func packageDiffs(files []FileDiff) []PackageDiff {
+	type key struct{ name, arch string }
+	added := make(map[key][]string)
+	removed := make(map[key][]string)
+	var out []PackageDiff
+
+	for _, f := range files {
+		rpm, ok := parseRPMFileName(f.Path)
+		if !ok {
+			continue
+		}
+		k := key{rpm.name, rpm.arch}
+		switch f.Change {
+		case ChangeAdded:
+			added[k] = append(added[k], rpm.version)
+		case ChangeRemoved:
+			removed[k] = append(removed[k], rpm.version)
+		case ChangeChanged:
+			out = append(out, PackageDiff{Change: ChangeChanged, Name: rpm.name, Arch: rpm.arch, OldVersion: rpm.version, NewVersion: rpm.version})
+		}
+	}
+
+	for k, newVersions := range added {
+		oldVersions := removed[k]
+		delete(removed, k)
+		if len(oldVersions) == 0 {
+			for _, v := range newVersions {
+				out = append(out, PackageDiff{Change: ChangeAdded, Name: k.name, Arch: k.arch, NewVersion: v})
+			}
+			continue
+		}
+		sort.Slice(oldVersions, func(i, j int) bool { return rpmVerCmp(oldVersions[i], oldVersions[j]) < 0 })
+		sort.Slice(newVersions, func(i, j int) bool { return rpmVerCmp(newVersions[i], newVersions[j]) < 0 })
+		oldV, newV := oldVersions[len(oldVersions)-1], newVersions[len(newVersions)-1]
+		change := ChangeUpgraded
+		if rpmVerCmp(newV, oldV) < 0 {
+			change = ChangeDowngraded
+		}
+		out = append(out, PackageDiff{Change: change, Name: k.name, Arch: k.arch, OldVersion: oldV, NewVersion: newV})
+	}
+	for k, oldVersions := range removed {
+		for _, v := range oldVersions {
+			out = append(out, PackageDiff{Change: ChangeRemoved, Name: k.name, Arch: k.arch, OldVersion: v})
+		}
+	}
+
+	sort.Slice(out, func(i, j int) bool {
+		if out[i].Name != out[j].Name {
+			return out[i].Name < out[j].Name
+		}
+		if out[i].Arch != out[j].Arch {
+			return out[i].Arch < out[j].Arch
+		}
+		return out[i].NewVersion+out[i].OldVersion < out[j].NewVersion+out[j].OldVersion
+	})
+	return out
+}
+
+// rpmVerCmp compares RPM version strings the way rpmvercmp does: runs of
+// digits compare numerically, runs of letters lexically, digits beat
+// letters, and ~ sorts before anything.
+func rpmVerCmp(a, b string) int {
+	for a != "" || b != "" {
+		a = strings.TrimLeftFunc(a, func(r rune) bool { return !isAlnum(r) && r != '~' })
+		b = strings.TrimLeftFunc(b, func(r rune) bool { return !isAlnum(r) && r != '~' })
+
+		if strings.HasPrefix(a, "~") || strings.HasPrefix(b, "~") {
+			if !strings.HasPrefix(a, "~") {
+				return 1
+			}
+			if !strings.HasPrefix(b, "~") {
+				return -1
+			}
+			a, b = a[1:], b[1:]
+			continue
+		}
+		if a == "" || b == "" {
+			break
+		}
+
+		numeric := unicode.IsDigit(rune(a[0]))
+		segA, restA := splitSegment(a, numeric)
+		segB, restB := splitSegment(b, numeric)
+		if segB == "" {
+			// Segment types differ; numbers are newer than letters.
+			if numeric {
+				return 1
+			}
+			return -1
+		}
+		if numeric {
+			segA = strings.TrimLeft(segA, "0")
+			segB = strings.TrimLeft(segB, "0")
+			if len(segA) != len(segB) {
+				if len(segA) > len(segB) {
+					return 1
+				}
+				return -1
+			}
+		}
+		if c := strings.Compare(segA, segB); c != 0 {
+			return c
+		}
+		a, b = restA, restB
+	}
+	switch {
+	case a == "" && b == "":
+		return 0
+	case a == "":
+		return -1
+	default:
+		return 1
+	}
+}
+
+func isAlnum(r rune) bool {
+	return r < unicode.MaxASCII && (unicode.IsDigit(r) || unicode.IsLetter(r))
+}
+
+func splitSegment(s string, numeric bool) (string, string) {
+	i := 0
+	for i < len(s) {
+		r := rune(s[i])
+		if numeric && !unicode.IsDigit(r) || !numeric && !(r < unicode.MaxASCII && unicode.IsLetter(r)) {
+			break
+		}
+		i++
+	}
+	return s[:i], s[i:]
+}
+
+// imageManifestPath returns the image directory and manifest digest of a
+// container image manifest stored as <image>/manifests/<algo>/<hash>.json.
+func imageManifestPath(path string) (string, string, bool) {
+	parts := strings.Split(path, "/")
+	n := len(parts)
+	if n < 4 || parts[n-3] != "manifests" || !strings.HasSuffix(parts[n-1], ".json") {
+		return "", "", false
+	}
+	return strings.Join(parts[:n-3], "/"), parts[n-2] + ":" + strings.TrimSuffix(parts[n-1], ".json"), true
+}
+
+// isImageContentPath reports whether path is a manifest or blob of a
+// mirrored image.
+func isImageContentPath(path string) bool {
+	parts := strings.Split(path, "/")
+	n := len(parts)
+	return n >= 4 && (parts[n-3] == "manifests" || parts[n-3] == "blobs")
+}
+
+// imageDiffs interprets manifest changes per image directory. The digests
+// listed are those of all manifests of the image on each side.
+func imageDiffs(files []FileDiff, oldFiles, newFiles map[string]ManifestFile) []ImageDiff {
+	touched := make(map[string]bool)
+	for _, f := range files {
+		if image, _, ok := imageManifestPath(f.Path); ok {
+			touched[image] = true
+		}
+	}
+	if len(touched) == 0 {
+		return nil
+	}
+
+	digests := func(inv map[string]ManifestFile, image string) []string {
+		var out []string
+		for path := range inv {
+			if img, digest, ok := imageManifestPath(path); ok && img == image {
+				out = append(out, digest)
+			}
+		}
+		sort.Strings(out)
+		return out
+	}
+
+	var out []ImageDiff
+	for image := range touched {
+		d := ImageDiff{Image: image, OldDigests: digests(oldFiles, image), NewDigests: digests(newFiles, image)}
+		switch {
+		case len(d.OldDigests) == 0:
+			d.Change = ChangeAdded
+		case len(d.NewDigests) == 0:
+			d.Change = ChangeRemoved
+		default:
+			d.Change = ChangeChanged
+		}
+		out = append(out, d)
+	}
+	sort.Slice(out, func(i, j int) bool { return out[i].Image < out[j].Image })
+	return out
+}
+
+// WriteCSV writes one row per changed file, package and image.
+func (d *ManifestDiff) WriteCSV(w io.Writer) error {
+	cw := csv.NewWriter(w)
+	_ = cw.Write([]string{"provider", "kind", "change", "name", "arch", "old_version", "new_version",
+		"old_size", "new_size", "size_delta", "old_sha256", "new_sha256"})
+	for _, pd := range d.Providers {
+		for _, f := range pd.Files {
+			_ = cw.Write([]string{pd.Provider, "file", f.Change, f.Path, "", "", "",
+				strconv.FormatInt(f.OldSize, 10), strconv.FormatInt(f.NewSize, 10), strconv.FormatInt(f.SizeDelta, 10),
+				f.OldSHA256, f.NewSHA256})
+		}
+		for _, p := range pd.Packages {
+			_ = cw.Write([]string{pd.Provider, "rpm", p.Change, p.Name, p.Arch, p.OldVersion, p.NewVersion, "", "", "", "", ""})
+		}
+		for _, img := range pd.Images {
+			_ = cw.Write([]string{pd.Provider, "image", img.Change, img.Image, "",
+				strings.Join(img.OldDigests, " "), strings.Join(img.NewDigests, " "), "", "", "", "", ""})
+		}
+	}
+	cw.Flush()
+	return cw.Error()
+}
@@ -0,0 +1,128 @@
+package engine
+
+import (
+	"bytes"
+	"encoding/json"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/BadgerOps/airgap/internal/provider"
+	"github.com/BadgerOps/airgap/internal/store"
+)
+
+func TestDiffManifests(t *testing.T) {
+	from := &TransferManifest{
+		Created: time.Date(2026, 9, 1, 0, 0, 0, 0, time.UTC),
+		FileInventory: []ManifestFile{
+			{Provider: "epel", Path: "9/Packages/z/zsh-5.8-9.el9.x86_64.rpm", Size: 100, SHA256: "a"},
+			{Provider: "epel", Path: "9/Packages/g/git-2.40.1-1.el9.x86_64.rpm", Size: 300, SHA256: "g"},
+			{Provider: "epel", Path: "9/repodata/repomd.xml", Size: 10, SHA256: "r1"},
+			{Provider: "epel", Path: "9/Packages/h/htop-3.2.2-1.el9.x86_64.rpm", Size: 50, SHA256: "h"},
+			{Provider: "images", Path: "images/quay.io_app_v1/manifests/sha256/aaa.json", Size: 5, SHA256: "aaa"},
+			{Provider: "images", Path: "images/quay.io_app_v1/blobs/sha256/b1", Size: 1000, SHA256: "b1"},
+		},
+	}
+	to := &TransferManifest{
+		Created: time.Date(2026, 10, 1, 0, 0, 0, 0, time.UTC),
+		FileInventory: []ManifestFile{
+			{Provider: "epel", Path: "9/Packages/z/zsh-5.8-10.el9.x86_64.rpm", Size: 120, SHA256: "b"},
+			{Provider: "epel", Path: "9/Packages/g/git-2.40.1-1.el9.x86_64.rpm", Size: 300, SHA256: "g"},
+			{Provider: "epel", Path: "9/repodata/repomd.xml", Size: 12, SHA256: "r2"},
+			{Provider: "epel", Path: "9/Packages/t/tmux-3.3a-1.el9.x86_64.rpm", Size: 70, SHA256: "t"},
+			{Provider: "images", Path: "images/quay.io_app_v1/manifests/sha256/bbb.json", Size: 5, SHA256: "bbb"},
+			{Provider: "images", Path: "images/quay.io_app_v1/blobs/sha256/b1", Size: 1000, SHA256: "b1"},
+			{Provider: "images", Path: "images/quay.io_db_v2/manifests/sha256/ccc.json", Size: 5, SHA256: "ccc"},
+		},
+	}
+
+	diff := DiffManifests(from, to, "august", "september")
+	if diff.Version != ManifestDiffVersion || diff.From.Label != "august" || diff.To.Files != 7 || len(diff.Providers) != 2 {
+		t.Fatalf("diff = %+v", diff)
+	}
+
+	epel := diff.Providers[0]
+	if got := epel.Totals; got != (ManifestDiffTotals{Added: 2, Removed: 2, Changed: 1, Unchanged: 1, AddedBytes: 190, RemovedBytes: 150, SizeDelta: 42}) {
+		t.Errorf("epel totals = %+v", got)
+	}
+	want := []PackageDiff{
+		{Change: ChangeRemoved, Name: "htop", Arch: "x86_64", OldVersion: "3.2.2-1.el9"},
+		{Change: ChangeAdded, Name: "tmux", Arch: "x86_64", NewVersion: "3.3a-1.el9"},
+		{Change: ChangeUpgraded, Name: "zsh", Arch: "x86_64", OldVersion: "5.8-9.el9", NewVersion: "5.8-10.el9"},
+	}
+	if len(epel.Packages) != len(want) {
+		t.Fatalf("packages = %+v", epel.Packages)
+	}
+	for i := range want {
+		if epel.Packages[i] != want[i] {
+			t.Errorf("packages[%d] = %+v, want %+v", i, epel.Packages[i], want[i])
+		}
+	}
+
+	images := diff.Providers[1]
+	if len(images.Images) != 2 ||
+		images.Images[0].Change != ChangeChanged || images.Images[0].OldDigests[0] != "sha256:aaa" || images.Images[0].NewDigests[0] != "sha256:bbb" ||
+		images.Images[1].Change != ChangeAdded || images.Images[1].Image != "images/quay.io_db_v2" {
+		t.Errorf("images = %+v", images.Images)
+	}
+
+	var buf bytes.Buffer
+	if err := diff.WriteCSV(&buf); err != nil {
+		t.Fatal(err)
+	}
+	out := buf.String()
+	for _, line := range []string{
+		"provider,kind,change,name,arch,old_version,new_version,old_size,new_size,size_delta,old_sha256,new_sha256",
+		"epel,file,changed,9/repodata/repomd.xml,,,,10,12,2,r1,r2",
+		"epel,rpm,upgraded,zsh,x86_64,5.8-9.el9,5.8-10.el9,,,,,",
+		"images,image,changed,images/quay.io_app_v1,,sha256:aaa,sha256:bbb,,,,,",
+	} {
+		if !strings.Contains(out, line+"\n") {
+			t.Errorf("CSV missing %q:\n%s", line, out)
+		}
+	}
+}
+
+func TestRPMVerCmp(t *testing.T) {
+	tests := []struct {
+		a, b string
+		want int
+	}{
+		{"1.0", "1.0", 0},
+		{"1.0", "1.1", -1},
+		{"1.10", "1.9", 1},
+		{"5.8-10.el9", "5.8-9.el9", 1},
+		{"1.0a", "1.0", 1},
+		{"1.0~rc1", "1.0", -1},
+		{"2.0", "2a", 1},
+		{"1.01", "1.1", 0},
+	}
+	for _, tt := range tests {
+		if got := rpmVerCmp(tt.a, tt.b); got != tt.want {
+			t.Errorf("rpmVerCmp(%q, %q) = %d, want %d", tt.a, tt.b, got, tt.want)
+		}
+	}
+}
+
+func TestTransferManifest(t *testing.T) {
+	manager, st := newTestSyncManager(t, provider.NewRegistry())
+	defer func() { _ = st.Close() }()
+
+	data, _ := json.Marshal(&TransferManifest{Version: "1.0", FileInventory: []ManifestFile{{Provider: "epel", Path: "a.rpm"}}})
+	tr := &store.Transfer{Direction: "export", Path: t.TempDir(), Manifest: string(data), Status: "completed", StartTime: time.Now()}
+	if err := st.CreateTransfer(tr); err != nil {
+		t.Fatal(err)
+	}
+	manifest, label, err := manager.TransferManifest(tr.ID)
+	if err != nil || len(manifest.FileInventory) != 1 || !strings.HasPrefix(label, "transfer 1 (export ") {
+		t.Errorf("TransferManifest = %+v, %q, %v", manifest, label, err)
+	}
+
+	old := &store.Transfer{Direction: "import", Path: t.TempDir(), Status: "completed", StartTime: time.Now()}
+	if err := st.CreateTransfer(old); err != nil {
+		t.Fatal(err)
+	}
+	if _, _, err := manager.TransferManifest(old.ID); err == nil || !strings.Contains(err.Error(), "no stored manifest") {
+		t.Errorf("transfer without manifest error = %v", err)
+	}
+}
@@ -0,0 +1,250 @@
+package engine
+
+import (
+	"bytes"
+	"fmt"
+	"os"
+	"path"
+	"path/filepath"
+	"sort"
+	"strings"
+
+	"github.com/BadgerOps/airgap/internal/safety"
+	"gopkg.in/yaml.v3"
+)
+
+// Mirror config file names written after a registry push.
+const (
+	mirrorConfigRoot          = "mirror-config"
+	ImageDigestMirrorSetFile  = "imagedigestmirrorset.yaml"
+	ImageTagMirrorSetFile     = "imagetagmirrorset.yaml"
+	CatalogSourceFile         = "catalogsource.yaml"
+	InstallConfigSnippetFile  = "install-config-snippet.yaml"
+	catalogSourceNamespace    = "openshift-marketplace"
+	maxKubernetesNameLength   = 63
+	mirrorConfigNamePrefix    = "airgap-"
+	installConfigTrustComment = "# additionalTrustBundle: add the PEM CA of the mirror registry here, or set ca_bundle on the registry provider\n"
+)
+
+// RepositoryMapping maps a source repository to the repository it was pushed to.
+type RepositoryMapping struct {
+	Source      string `json:"source"`
+	Destination string `json:"destination"`
+	// ByTag is set when at least one image was referenced by tag, so the
+	// mapping also belongs in an ImageTagMirrorSet.
+	ByTag bool `json:"by_tag"`
+}
+
+type mirrorSource struct {
+	Source  string   `yaml:"source"`
+	Mirrors []string `yaml:"mirrors"`
+}
+
+type objectMeta struct {
+	Name      string `yaml:"name"`
+	Namespace string `yaml:"namespace,omitempty"`
+}
+
+type imageDigestMirrorSet struct {
+	APIVersion string     `yaml:"apiVersion"`
+	Kind       string     `yaml:"kind"`
+	Metadata   objectMeta `yaml:"metadata"`
+	Spec       struct {
+		ImageDigestMirrors []mirrorSource `yaml:"imageDigestMirrors"`
+	} `yaml:"spec"`
+}
+
+type imageTagMirrorSet struct {
+	APIVersion string     `yaml:"apiVersion"`
+	Kind       string     `yaml:"kind"`
+	Metadata   objectMeta `yaml:"metadata"`
+	Spec       struct {
+		ImageTagMirrors []mirrorSource `yaml:"imageTagMirrors"`
+	} `yaml:"spec"`
+}
+
+type catalogSource struct {
+	APIVersion string     `yaml:"apiVersion"`
+	Kind       string     `yaml:"kind"`
+	Metadata   objectMeta `yaml:"metadata"`
+	Spec       struct {
+		SourceType  string `yaml:"sourceType"`
+		Image       string `yaml:"image"`
+		DisplayName string `yaml:"displayName"`
+		Publisher   string `yaml:"publisher"`
+	} `yaml:"spec"`
+}
+
+type installConfigSnippet struct {
+	ImageContentSources   []mirrorSource `yaml:"imageContentSources"`
+	AdditionalTrustBundle string         `yaml:"additionalTrustBundle,omitempty"`
+}
+
+// MirrorConfigDir returns the directory holding the cluster mirror
+// configuration generated by pushing source to target.
+func (m *SyncManager) MirrorConfigDir(source, target string) (string, error) {
+	return safety.SafeJoinUnder(m.config.Server.DataDir, path.Join(mirrorConfigRoot, source, target))
+}
+
+// writeMirrorConfig writes ImageDigestMirrorSet, ImageTagMirrorSet,
+// CatalogSource and install-config manifests for the pushed repositories and
+// returns the names of the files written. Stale files from earlier pushes are
+// removed so the directory always matches the latest push.
+func writeMirrorConfig(dir, name string, mappings []RepositoryMapping, catalogs []string, trustBundle string) ([]string, error) {
+	if err := os.MkdirAll(dir, 0o755); err != nil {
+		return nil, fmt.Errorf("creating mirror config dir: %w", err)
+	}
+	for _, f := range []string{ImageDigestMirrorSetFile, ImageTagMirrorSetFile, CatalogSourceFile, InstallConfigSnippetFile} {
+		if err := os.Remove(filepath.Join(dir, f)); err != nil && !os.IsNotExist(err) {
+			return nil, fmt.Errorf("removing stale %s: %w", f, err)
+		}
+	}
+
+	mappings = mergeMappings(mappings)
+	metaName := kubernetesName(mirrorConfigNamePrefix + name)
+	var written []string
+	write := func(file string, data []byte) error {
+		if err := os.WriteFile(filepath.Join(dir, file), data, 0o644); err != nil {
+			return fmt.Errorf("writing %s: %w", file, err)
+		}
+		written = append(written, file)
+		return nil
+	}
+
+	var digestSources, tagSources []mirrorSource
+	for _, mp := range mappings {
+		src := mirrorSource{Source: mp.Source, Mirrors: []string{mp.Destination}}
+		digestSources = append(digestSources, src)
+		if mp.ByTag {
+			tagSources = append(tagSources, src)
+		}
+	}
+
+	if len(digestSources) > 0 {
+		idms := imageDigestMirrorSet{APIVersion: "config.openshift.io/v1", Kind: "ImageDigestMirrorSet", Metadata: objectMeta{Name: metaName}}
+		idms.Spec.ImageDigestMirrors = digestSources
+		data, err := marshalYAMLDocs(idms)
+		if err != nil {
+			return nil, err
+		}
+		if err := write(ImageDigestMirrorSetFile, data); err != nil {
+			return nil, err
+		}
+	}
+
+	if len(tagSources) > 0 {
+		itms := imageTagMirrorSet{APIVersion: "config.openshift.io/v1", Kind: "ImageTagMirrorSet", Metadata: objectMeta{Name: metaName}}
+		itms.Spec.ImageTagMirrors = tagSources
+		data, err := marshalYAMLDocs(itms)
+		if err != nil {
+			return nil, err
+		}
+		if err := write(ImageTagMirrorSetFile, data); err != nil {
+			return nil, err
+		}
+	}
+
+	if len(catalogs) > 0 {
+		var docs []interface{}
+		for _, image := range catalogs {
+			cs := catalogSource{APIVersion: "operators.coreos.com/v1alpha1", Kind: "CatalogSource",
+				Metadata: objectMeta{Name: catalogSourceName(image), Namespace: catalogSourceNamespace}}
+			cs.Spec.SourceType = "grpc"
+			cs.Spec.Image = image
+			cs.Spec.DisplayName = catalogSourceName(image)
+			cs.Spec.Publisher = "airgap"
+			docs = append(docs, cs)
+		}
+		data, err := marshalYAMLDocs(docs...)
+		if err != nil {
+			return nil, err
+		}
+		if err := write(CatalogSourceFile, data); err != nil {
+			return nil, err
+		}
+	}
+
+	if len(digestSources) > 0 {
+		snippet := installConfigSnippet{ImageContentSources: digestSources, AdditionalTrustBundle: trustBundle}
+		data, err := marshalYAMLDocs(snippet)
+		if err != nil {
+			return nil, err
+		}
+		if trustBundle == "" {
+			data = append(data, installConfigTrustComment...)
+		}
+		if err := write(InstallConfigSnippetFile, data); err != nil {
+			return nil, err
+		}
+	}
+
+	return written, nil
+}
+
+// mergeMappings deduplicates mappings by source and sorts them.
+func mergeMappings(mappings []RepositoryMapping) []RepositoryMapping {
+	bySource := make(map[string]*RepositoryMapping, len(mappings))
+	var order []string
+	for _, mp := range mappings {
+		if existing, ok := bySource[mp.Source]; ok {
+			existing.ByTag = existing.ByTag || mp.ByTag
+			continue
+		}
+		cp := mp
+		bySource[mp.Source] = &cp
+		order = append(order, mp.Source)
+	}
+	sort.Strings(order)
+	out := make([]RepositoryMapping, 0, len(order))
+	for _, src := range order {
+		out = append(out, *bySource[src])
+	}
+	return out
+}
+
+// catalogSourceName derives a CatalogSource name from a catalog image, e.g.
+// "reg/redhat/redhat-operator-index:v4.16" -> "redhat-operator-index-v4-16".
+func catalogSourceName(image string) string {
+	repo, tag := image, ""
+	if slash, colon := strings.LastIndex(image, "/"), strings.LastIndex(image, ":"); colon > slash {
+		repo, tag = image[:colon], image[colon+1:]
+	}
+	name := path.Base(repo)
+	if tag != "" {
+		name += "-" + tag
+	}
+	return kubernetesName(name)
+}
+
+// kubernetesName lowercases s and replaces characters not allowed in a
+// DNS-1123 label.
+func kubernetesName(s string) string {
+	var b strings.Builder
+	for _, r := range strings.ToLower(s) {
+		if (r >= 'a' && r <= 'z') || (r >= '0' && r <= '9') {
+			b.WriteRune(r)
+		} else {
+			b.WriteByte('-')
+		}
+	}
+	name := b.String()
+	if len(name) > maxKubernetesNameLength {
+		name = name[:maxKubernetesNameLength]
+	}
+	return strings.Trim(name, "-")
+}
+
+func marshalYAMLDocs(docs ...interface{}) ([]byte, error) {
+	var buf bytes.Buffer
+	enc := yaml.NewEncoder(&buf)
+	enc.SetIndent(2)
+	for _, doc := range docs {
+		if err := enc.Encode(doc); err != nil {
+			return nil, fmt.Errorf("marshaling mirror config: %w", err)
+		}
+	}
+	if err := enc.Close(); err != nil {
+		return nil, fmt.Errorf("marshaling mirror config: %w", err)
+	}
+	return buf.Bytes(), nil
+}
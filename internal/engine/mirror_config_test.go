@@ -0,0 +1,104 @@
+package engine
+
+import (
+	"os"
+	"path/filepath"
+	"reflect"
+	"strings"
+	"testing"
+
+	"gopkg.in/yaml.v3"
+)
+
+func TestWriteMirrorConfig(t *testing.T) {
+	dir := t.TempDir()
+	if err := os.WriteFile(filepath.Join(dir, ImageTagMirrorSetFile), []byte("stale"), 0o644); err != nil {
+		t.Fatal(err)
+	}
+
+	mappings := []RepositoryMapping{
+		{Source: "registry.redhat.io/redhat/redhat-operator-index", Destination: "mirror.example.com:8443/mirror/redhat/redhat-operator-index", ByTag: true},
+		{Source: "quay.io/openshift-release-dev/ocp-release", Destination: "mirror.example.com:8443/mirror/openshift-release-dev/ocp-release"},
+		{Source: "quay.io/openshift-release-dev/ocp-release", Destination: "mirror.example.com:8443/mirror/openshift-release-dev/ocp-release"},
+	}
+	catalogs := []string{"mirror.example.com:8443/mirror/redhat/redhat-operator-index:v4.16"}
+
+	files, err := writeMirrorConfig(dir, "Redhat_Operators", mappings, catalogs, "")
+	if err != nil {
+		t.Fatalf("writeMirrorConfig: %v", err)
+	}
+	want := []string{ImageDigestMirrorSetFile, ImageTagMirrorSetFile, CatalogSourceFile, InstallConfigSnippetFile}
+	if !reflect.DeepEqual(files, want) {
+		t.Fatalf("files = %v, want %v", files, want)
+	}
+
+	var idms imageDigestMirrorSet
+	readYAML(t, filepath.Join(dir, ImageDigestMirrorSetFile), &idms)
+	if idms.Kind != "ImageDigestMirrorSet" || idms.Metadata.Name != "airgap-redhat-operators" {
+		t.Fatalf("unexpected IDMS: %+v", idms)
+	}
+	if len(idms.Spec.ImageDigestMirrors) != 2 || idms.Spec.ImageDigestMirrors[0].Source != "quay.io/openshift-release-dev/ocp-release" {
+		t.Fatalf("unexpected IDMS mirrors: %+v", idms.Spec.ImageDigestMirrors)
+	}
+
+	var itms imageTagMirrorSet
+	readYAML(t, filepath.Join(dir, ImageTagMirrorSetFile), &itms)
+	if len(itms.Spec.ImageTagMirrors) != 1 || itms.Spec.ImageTagMirrors[0].Source != "registry.redhat.io/redhat/redhat-operator-index" {
+		t.Fatalf("unexpected ITMS mirrors: %+v", itms.Spec.ImageTagMirrors)
+	}
+
+	var cs catalogSource
+	readYAML(t, filepath.Join(dir, CatalogSourceFile), &cs)
+	if cs.Metadata.Name != "redhat-operator-index-v4-16" || cs.Metadata.Namespace != "openshift-marketplace" || cs.Spec.Image != catalogs[0] {
+		t.Fatalf("unexpected CatalogSource: %+v", cs)
+	}
+
+	snippet, err := os.ReadFile(filepath.Join(dir, InstallConfigSnippetFile))
+	if err != nil {
+		t.Fatal(err)
+	}
+	if !strings.Contains(string(snippet), "imageContentSources:") || !strings.Contains(string(snippet), "# additionalTrustBundle") {
+		t.Fatalf("unexpected install-config snippet:\n%s", snippet)
+	}
+
+	// A later push without tags or catalogs drops the stale files.
+	files, err = writeMirrorConfig(dir, "x", mappings[1:2], nil, "-----BEGIN CERTIFICATE-----\n")
+	if err != nil {
+		t.Fatal(err)
+	}
+	if !reflect.DeepEqual(files, []string{ImageDigestMirrorSetFile, InstallConfigSnippetFile}) {
+		t.Fatalf("files = %v", files)
+	}
+	if _, err := os.Stat(filepath.Join(dir, CatalogSourceFile)); !os.IsNotExist(err) {
+		t.Error("expected stale CatalogSource to be removed")
+	}
+	var ic installConfigSnippet
+	readYAML(t, filepath.Join(dir, InstallConfigSnippetFile), &ic)
+	if ic.AdditionalTrustBundle == "" {
+		t.Error("expected additionalTrustBundle to be set")
+	}
+}
+
+func TestKubernetesName(t *testing.T) {
+	tests := map[string]string{
+		"airgap-Container_Images": "airgap-container-images",
+		"--x--":                   "x",
+		strings.Repeat("a", 70):   strings.Repeat("a", 63),
+	}
+	for in, want := range tests {
+		if got := kubernetesName(in); got != want {
+			t.Errorf("kubernetesName(%q) = %q, want %q", in, got, want)
+		}
+	}
+}
+
+func readYAML(t *testing.T, path string, out interface{}) {
+	t.Helper()
+	data, err := os.ReadFile(path)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if err := yaml.Unmarshal(data, out); err != nil {
+		t.Fatalf("parsing %s: %v", path, err)
+	}
+}
@@ -0,0 +1,273 @@
+package engine
+
+import (
+	"context"
+	"fmt"
+	"os"
+	"path/filepath"
+	"sort"
+	"sync"
+	"time"
+
+	"github.com/BadgerOps/airgap/internal/provider"
+)
+
+// PlanReportVersion is the schema version of the JSON and YAML form of
+// PlanReport. It is bumped whenever a field is renamed or removed.
+const PlanReportVersion = 1
+
+// PlanReport describes what a sync of one or more providers would do,
+// without downloading or deleting anything.
+type PlanReport struct {
+	Version     int            `json:"version" yaml:"version"`
+	GeneratedAt time.Time      `json:"generated_at" yaml:"generated_at"`
+	Providers   []ProviderPlan `json:"providers" yaml:"providers"`
+	Totals      PlanTotals     `json:"totals" yaml:"totals"`
+	Disk        DiskEstimate   `json:"disk" yaml:"disk"`
+	// Errors counts providers that could not be planned.
+	Errors int `json:"errors" yaml:"errors"`
+}
+
+// ProviderPlan is the plan of a single provider. Error is set instead of
+// Actions when planning failed.
+type ProviderPlan struct {
+	Provider        string `json:"provider" yaml:"provider"`
+	Error           string `json:"error,omitempty" yaml:"error,omitempty"`
+	SignatureStatus string `json:"signature_status,omitempty" yaml:"signature_status,omitempty"`
+	// Incomplete is set when the provider plans more downloads once the
+	// listed ones are done, such as operator bundle images, so the totals
+	// are a lower bound.
+	Incomplete bool                `json:"incomplete,omitempty" yaml:"incomplete,omitempty"`
+	Resolved   map[string][]string `json:"resolved,omitempty" yaml:"resolved,omitempty"`
+	Actions    []PlanAction        `json:"actions" yaml:"actions"`
+	Rejected   []PlanRejection     `json:"rejected,omitempty" yaml:"rejected,omitempty"`
+	Totals     PlanTotals          `json:"totals" yaml:"totals"`
+}
+
+// PlanAction is one download, update, delete or skip of a provider plan.
+type PlanAction struct {
+	Action string `json:"action" yaml:"action"`
+	Path   string `json:"path" yaml:"path"`
+	Size   int64  `json:"size" yaml:"size"`
+	Reason string `json:"reason,omitempty" yaml:"reason,omitempty"`
+}
+
+// PlanRejection is upstream content the provider refused while planning,
+// such as metadata with a bad signature.
+type PlanRejection struct {
+	Path  string `json:"path" yaml:"path"`
+	Error string `json:"error" yaml:"error"`
+}
+
+// PlanTotals counts the actions of a plan. DownloadBytes covers downloads
+// and updates; UnknownSize counts those whose size upstream did not report.
+// Generated counts the files the provider builds itself, such as update
+// graphs or pruned catalogs, which the sync writes at the end.
+type PlanTotals struct {
+	Downloads      int   `json:"downloads" yaml:"downloads"`
+	Updates        int   `json:"updates" yaml:"updates"`
+	Deletes        int   `json:"deletes" yaml:"deletes"`
+	Skips          int   `json:"skips" yaml:"skips"`
+	Generated      int   `json:"generated" yaml:"generated"`
+	DownloadBytes  int64 `json:"download_bytes" yaml:"download_bytes"`
+	GeneratedBytes int64 `json:"generated_bytes" yaml:"generated_bytes"`
+	DeleteBytes    int64 `json:"delete_bytes" yaml:"delete_bytes"`
+	UnknownSize    int   `json:"unknown_size" yaml:"unknown_size"`
+}
+
+func (t *PlanTotals) add(o PlanTotals) {
+	t.Downloads += o.Downloads
+	t.Updates += o.Updates
+	t.Deletes += o.Deletes
+	t.Skips += o.Skips
+	t.Generated += o.Generated
+	t.DownloadBytes += o.DownloadBytes
+	t.GeneratedBytes += o.GeneratedBytes
+	t.DeleteBytes += o.DeleteBytes
+	t.UnknownSize += o.UnknownSize
+}
+
+// DiskEstimate compares the free space of the data directory with the
+// bytes a sync would download or generate. Deleted files are not
+// subtracted, since deletes run after downloads.
+type DiskEstimate struct {
+	Path          string `json:"path" yaml:"path"`
+	FreeBytes     int64  `json:"free_bytes" yaml:"free_bytes"`
+	RequiredBytes int64  `json:"required_bytes" yaml:"required_bytes"`
+	Sufficient    bool   `json:"sufficient" yaml:"sufficient"`
+	Error         string `json:"error,omitempty" yaml:"error,omitempty"`
+}
+
+// PlanOptions controls PlanProviders.
+type PlanOptions struct {
+	// ProbeSizes asks upstream with a HEAD request for the size of each
+	// download or update the provider did not size itself.
+	ProbeSizes bool
+}
+
+// probeWorkers bounds the concurrent HEAD requests of a size probe.
+const probeWorkers = 8
+
+// PlanProviders plans a sync of each named provider and estimates the disk
+// space it needs. Like a dry-run sync it downloads and writes no content;
+// unlike one it records no sync run. A provider
+// that fails to plan is reported in its ProviderPlan and counted in Errors.
+func (m *SyncManager) PlanProviders(ctx context.Context, names []string, opts PlanOptions) *PlanReport {
+	report := &PlanReport{
+		Version:     PlanReportVersion,
+		GeneratedAt: time.Now().UTC(),
+		Providers:   []ProviderPlan{},
+	}
+
+	for _, name := range names {
+		pp := m.planProvider(ctx, name, opts)
+		if pp.Error != "" {
+			report.Errors++
+		}
+		report.Totals.add(pp.Totals)
+		report.Providers = append(report.Providers, pp)
+	}
+
+	report.Disk = DiskEstimate{
+		Path:          m.config.Server.DataDir,
+		RequiredBytes: report.Totals.DownloadBytes + report.Totals.GeneratedBytes,
+	}
+	free, err := DiskFree(m.config.Server.DataDir)
+	if err != nil {
+		report.Disk.Error = err.Error()
+	} else {
+		report.Disk.FreeBytes = free
+		report.Disk.Sufficient = free >= report.Disk.RequiredBytes
+	}
+	return report
+}
+
+// actionOrder sorts plan actions so the changes come first.
+var actionOrder = map[string]int{
+	string(provider.ActionDownload): 0,
+	string(provider.ActionUpdate):   1,
+	string(provider.ActionDelete):   2,
+	string(provider.ActionSkip):     3,
+}
+
+func (m *SyncManager) planProvider(ctx context.Context, name string, opts PlanOptions) ProviderPlan {
+	m.mu.RLock()
+	defer m.mu.RUnlock()
+
+	pp := ProviderPlan{Provider: name, Actions: []PlanAction{}}
+	p, ok := m.registry.Get(name)
+	if !ok {
+		pp.Error = fmt.Sprintf("provider not found: %s", name)
+		return pp
+	}
+
+	plan, err := p.Plan(ctx)
+	if err != nil {
+		m.logger.Error("failed to plan sync", "provider", name, "error", err)
+		pp.Error = err.Error()
+		return pp
+	}
+	pp.SignatureStatus = plan.SignatureStatus
+	pp.Resolved = plan.Resolved
+	pp.Incomplete = plan.Incomplete
+	if opts.ProbeSizes {
+		m.probeSizes(ctx, name, plan.Actions)
+	}
+
+	for _, a := range plan.Actions {
+		pp.Actions = append(pp.Actions, PlanAction{
+			Action: string(a.Action),
+			Path:   a.Path,
+			Size:   a.Size,
+			Reason: a.Reason,
+		})
+		switch a.Action {
+		case provider.ActionDownload, provider.ActionUpdate:
+			if a.Action == provider.ActionDownload {
+				pp.Totals.Downloads++
+			} else {
+				pp.Totals.Updates++
+			}
+			pp.Totals.DownloadBytes += a.Size
+			if a.Size <= 0 {
+				pp.Totals.UnknownSize++
+			}
+		case provider.ActionDelete:
+			pp.Totals.Deletes++
+			pp.Totals.DeleteBytes += a.Size
+		case provider.ActionSkip:
+			pp.Totals.Skips++
+		}
+	}
+
+	sort.SliceStable(pp.Actions, func(i, j int) bool {
+		a, b := pp.Actions[i], pp.Actions[j]
+		if actionOrder[a.Action] != actionOrder[b.Action] {
+			return actionOrder[a.Action] < actionOrder[b.Action]
+		}
+		return a.Path < b.Path
+	})
+
+	if gen, ok := p.(provider.FileGenerator); ok {
+		for _, f := range gen.GeneratedFiles() {
+			pp.Totals.Generated++
+			pp.Totals.GeneratedBytes += int64(len(f.Data))
+		}
+	}
+	if pf, ok := p.(provider.PlanFailureReporter); ok {
+		for _, f := range pf.PlanFailures() {
+			pp.Rejected = append(pp.Rejected, PlanRejection{Path: f.Path, Error: f.Error})
+		}
+	}
+	return pp
+}
+
+// probeSizes fills in the size of unsized downloads and updates from
+// upstream. Failed probes are logged and leave the size unknown.
+func (m *SyncManager) probeSizes(ctx context.Context, name string, actions []provider.SyncAction) {
+	var wg sync.WaitGroup
+	sem := make(chan struct{}, probeWorkers)
+	for i := range actions {
+		a := &actions[i]
+		if a.Size > 0 || a.URL == "" || (a.Action != provider.ActionDownload && a.Action != provider.ActionUpdate) {
+			continue
+		}
+		wg.Add(1)
+		sem <- struct{}{}
+		go func() {
+			defer func() { <-sem; wg.Done() }()
+			size, err := m.client.Size(ctx, a.URL, a.Headers, a.Authorize)
+			if err != nil {
+				m.logger.Warn("failed to probe download size", "provider", name, "path", a.Path, "error", err)
+				return
+			}
+			a.Size = size
+		}()
+	}
+	wg.Wait()
+}
+
+// DiskFree returns the bytes available to unprivileged users on the
+// filesystem holding path. If path does not exist yet, its nearest existing
+// parent is used.
+func DiskFree(path string) (int64, error) {
+	dir, err := filepath.Abs(path)
+	if err != nil {
+		return 0, fmt.Errorf("resolving %s: %w", path, err)
+	}
+	for {
+		if _, err := os.Stat(dir); err == nil {
+			break
+		}
+		parent := filepath.Dir(dir)
+		if parent == dir {
+			return 0, fmt.Errorf("no existing directory for %s", path)
+		}
+		dir = parent
+	}
+	free, err := diskFree(dir)
+	if err != nil {
+		return 0, fmt.Errorf("checking free space of %s: %w", dir, err)
+	}
+	return free, nil
+}
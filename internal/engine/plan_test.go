@@ -0,0 +1,152 @@
+package engine
+
+import (
+	"context"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+	"time"
+
+	"github.com/BadgerOps/airgap/internal/provider"
+)
+
+func TestPlanProviders(t *testing.T) {
+	registry := provider.NewRegistry()
+	registry.Register(&planFailureProvider{
+		mockProvider: mockProvider{
+			name: "epel",
+			planFunc: func(ctx context.Context) (*provider.SyncPlan, error) {
+				return &provider.SyncPlan{
+					Provider: "epel",
+					Actions: []provider.SyncAction{
+						{Path: "Packages/old.rpm", Action: provider.ActionDelete, Size: 50, Reason: "removed upstream"},
+						{Path: "Packages/b.rpm", Action: provider.ActionDownload, Size: 100, Reason: "new file"},
+						{Path: "Packages/c.rpm", Action: provider.ActionSkip, Size: 10},
+						{Path: "repodata/repomd.xml", Action: provider.ActionUpdate, Size: 0, Reason: "checksum mismatch"},
+						{Path: "Packages/a.rpm", Action: provider.ActionDownload, Size: 200, Reason: "new file"},
+					},
+					Resolved:  map[string][]string{"stable-4.17": {"4.17.3"}},
+					Timestamp: time.Now(),
+				}, nil
+			},
+		},
+		failures: []provider.FailedFile{{Path: "repodata/bad.xml", Error: "bad signature"}},
+	})
+
+	manager, st := newTestSyncManager(t, registry)
+	defer func() { _ = st.Close() }()
+
+	report := manager.PlanProviders(context.Background(), []string{"epel", "missing"}, PlanOptions{})
+
+	if report.Version != PlanReportVersion || len(report.Providers) != 2 || report.Errors != 1 {
+		t.Fatalf("report = %+v", report)
+	}
+	epel := report.Providers[0]
+	var order []string
+	for _, a := range epel.Actions {
+		order = append(order, a.Action+":"+a.Path)
+	}
+	want := []string{"download:Packages/a.rpm", "download:Packages/b.rpm", "update:repodata/repomd.xml", "delete:Packages/old.rpm", "skip:Packages/c.rpm"}
+	if len(order) != len(want) {
+		t.Fatalf("actions = %v", order)
+	}
+	for i := range want {
+		if order[i] != want[i] {
+			t.Errorf("actions = %v, want %v", order, want)
+			break
+		}
+	}
+	if got := epel.Totals; got != (PlanTotals{Downloads: 2, Updates: 1, Deletes: 1, Skips: 1, DownloadBytes: 300, DeleteBytes: 50, UnknownSize: 1}) {
+		t.Errorf("totals = %+v", got)
+	}
+	if len(epel.Rejected) != 1 || epel.Rejected[0].Path != "repodata/bad.xml" || epel.Resolved["stable-4.17"][0] != "4.17.3" {
+		t.Errorf("provider plan = %+v", epel)
+	}
+	if report.Providers[1].Error == "" {
+		t.Error("expected an error for the missing provider")
+	}
+
+	if report.Totals.DownloadBytes != 300 || report.Disk.RequiredBytes != 300 {
+		t.Errorf("report totals = %+v, disk = %+v", report.Totals, report.Disk)
+	}
+	if report.Disk.Error != "" || report.Disk.FreeBytes <= 0 || !report.Disk.Sufficient {
+		t.Errorf("disk = %+v", report.Disk)
+	}
+
+	runs, err := st.ListSyncRuns("epel", 10)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if len(runs) != 0 {
+		t.Errorf("planning recorded %d sync run(s)", len(runs))
+	}
+}
+
+func TestPlanProvidersGeneratedFiles(t *testing.T) {
+	registry := provider.NewRegistry()
+	registry.Register(&postSyncProvider{
+		mockProvider: mockProvider{
+			name: "operators",
+			planFunc: func(ctx context.Context) (*provider.SyncPlan, error) {
+				return &provider.SyncPlan{
+					Provider:   "operators",
+					Actions:    []provider.SyncAction{{Path: "index/blobs/sha256/abc", Action: provider.ActionDownload, Size: 1000}},
+					Incomplete: true,
+				}, nil
+			},
+		},
+		generated: []provider.GeneratedFile{{Path: "graph/amd64/stable-4.16.json", Data: make([]byte, 24)}},
+	})
+
+	manager, st := newTestSyncManager(t, registry)
+	defer func() { _ = st.Close() }()
+
+	report := manager.PlanProviders(context.Background(), []string{"operators"}, PlanOptions{})
+	pp := report.Providers[0]
+	if !pp.Incomplete || pp.Totals.Generated != 1 || pp.Totals.GeneratedBytes != 24 {
+		t.Errorf("provider plan = %+v", pp)
+	}
+	if report.Disk.RequiredBytes != 1024 {
+		t.Errorf("expected downloads and generated files in the disk estimate, got %+v", report.Disk)
+	}
+}
+
+func TestDiskFreeMissingDir(t *testing.T) {
+	free, err := DiskFree(t.TempDir() + "/not/created/yet")
+	if err != nil || free <= 0 {
+		t.Errorf("DiskFree = %d, %v", free, err)
+	}
+}
+
+func TestPlanProvidersProbeSizes(t *testing.T) {
+	srv := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		if r.Method != http.MethodHead {
+			t.Errorf("probe used %s", r.Method)
+		}
+		if r.URL.Path == "/missing" {
+			http.NotFound(w, r)
+			return
+		}
+		w.Header().Set("Content-Length", "4096")
+	}))
+	defer srv.Close()
+
+	registry := provider.NewRegistry()
+	registry.Register(&mockProvider{
+		name: "ocp",
+		planFunc: func(ctx context.Context) (*provider.SyncPlan, error) {
+			return &provider.SyncPlan{Actions: []provider.SyncAction{
+				{Path: "a.tar.gz", Action: provider.ActionDownload, URL: srv.URL + "/a.tar.gz"},
+				{Path: "b.tar.gz", Action: provider.ActionDownload, URL: srv.URL + "/missing"},
+				{Path: "c.tar.gz", Action: provider.ActionDownload, URL: srv.URL + "/c.tar.gz", Size: 10},
+			}}, nil
+		},
+	})
+	manager, st := newTestSyncManager(t, registry)
+	defer func() { _ = st.Close() }()
+
+	report := manager.PlanProviders(context.Background(), []string{"ocp"}, PlanOptions{ProbeSizes: true})
+	if got := report.Totals; got.DownloadBytes != 4106 || got.UnknownSize != 1 {
+		t.Errorf("totals = %+v", got)
+	}
+}
@@ -0,0 +1,144 @@
+package engine
+
+import (
+	"errors"
+	"fmt"
+	"net/url"
+	"path/filepath"
+	"strings"
+	"time"
+
+	"github.com/BadgerOps/airgap/internal/store"
+)
+
+// ErrNoFileRecord reports that no file record matches a path.
+var ErrNoFileRecord = errors.New("no file record")
+
+// FileProvenance records where a mirrored file came from and when.
+type FileProvenance struct {
+	Provider             string    `json:"provider"`
+	Path                 string    `json:"path"`
+	Size                 int64     `json:"size"`
+	SHA256               string    `json:"sha256"`
+	SourceURL            string    `json:"source_url,omitempty"`
+	MirrorHost           string    `json:"mirror_host,omitempty"`
+	ETag                 string    `json:"etag,omitempty"`
+	UpstreamLastModified string    `json:"upstream_last_modified,omitempty"`
+	ResponseTimeMS       int64     `json:"response_time_ms,omitempty"`
+	FetchedAt            time.Time `json:"fetched_at"`
+	LastVerified         time.Time `json:"last_verified"`
+	SyncRunID            int64     `json:"sync_run_id,omitempty"`
+	// SourceHost is the exporting host when the file arrived by import.
+	SourceHost      string `json:"source_host,omitempty"`
+	SyncStatus      string `json:"sync_status,omitempty"`
+	SignatureStatus string `json:"signature_status,omitempty"`
+}
+
+// ManifestProvenance is the provenance of a file as recorded on the
+// exporting host.
+type ManifestProvenance struct {
+	SourceURL            string    `json:"source_url"`
+	MirrorHost           string    `json:"mirror_host,omitempty"`
+	ETag                 string    `json:"etag,omitempty"`
+	UpstreamLastModified string    `json:"upstream_last_modified,omitempty"`
+	ResponseTimeMS       int64     `json:"response_time_ms,omitempty"`
+	FetchedAt            time.Time `json:"fetched_at"`
+	// SyncRunID is the run's ID on the exporting host.
+	SyncRunID int64 `json:"sync_run_id,omitempty"`
+}
+
+// Provenance looks up the file records matching path, which may be relative
+// to a provider directory, prefixed with the provider name, or an absolute
+// path under the data directory. Unknown paths wrap ErrNoFileRecord.
+func (m *SyncManager) Provenance(path string) ([]FileProvenance, error) {
+	lookup := filepath.ToSlash(filepath.Clean(path))
+	if filepath.IsAbs(path) {
+		rel, err := filepath.Rel(m.config.Server.DataDir, path)
+		if err != nil || strings.HasPrefix(rel, "..") {
+			return nil, fmt.Errorf("%s is not under the data directory %s", path, m.config.Server.DataDir)
+		}
+		lookup = filepath.ToSlash(rel)
+	}
+
+	records, err := m.store.FindFileRecords(lookup)
+	if err != nil {
+		return nil, err
+	}
+	if len(records) == 0 {
+		return nil, fmt.Errorf("%w for %s", ErrNoFileRecord, path)
+	}
+
+	result := make([]FileProvenance, 0, len(records))
+	for _, rec := range records {
+		fp := FileProvenance{
+			Provider:             rec.Provider,
+			Path:                 rec.Path,
+			Size:                 rec.Size,
+			SHA256:               rec.SHA256,
+			SourceURL:            rec.SourceURL,
+			MirrorHost:           rec.MirrorHost,
+			ETag:                 rec.ETag,
+			UpstreamLastModified: rec.UpstreamLastModified,
+			ResponseTimeMS:       rec.ResponseTimeMS,
+			FetchedAt:            rec.LastModified,
+			LastVerified:         rec.LastVerified,
+			SyncRunID:            rec.SyncRunID,
+		}
+		if rec.SyncRunID != 0 {
+			if run, err := m.store.GetSyncRun(rec.SyncRunID); err == nil {
+				fp.SourceHost = run.SourceHost
+				fp.SyncStatus = run.Status
+				fp.SignatureStatus = run.SignatureStatus
+			}
+		}
+		result = append(result, fp)
+	}
+	return result, nil
+}
+
+// manifestProvenance returns rec's provenance for the transfer manifest, or
+// nil when the file was not downloaded.
+func manifestProvenance(rec store.FileRecord) *ManifestProvenance {
+	if rec.SourceURL == "" {
+		return nil
+	}
+	return &ManifestProvenance{
+		SourceURL:            rec.SourceURL,
+		MirrorHost:           rec.MirrorHost,
+		ETag:                 rec.ETag,
+		UpstreamLastModified: rec.UpstreamLastModified,
+		ResponseTimeMS:       rec.ResponseTimeMS,
+		FetchedAt:            rec.LastModified,
+		SyncRunID:            rec.SyncRunID,
+	}
+}
+
+// applyManifestProvenance copies p into rec.
+func applyManifestProvenance(rec *store.FileRecord, p *ManifestProvenance) {
+	rec.SourceURL = p.SourceURL
+	rec.MirrorHost = p.MirrorHost
+	rec.ETag = p.ETag
+	rec.UpstreamLastModified = p.UpstreamLastModified
+	rec.ResponseTimeMS = p.ResponseTimeMS
+	if !p.FetchedAt.IsZero() {
+		rec.LastModified = p.FetchedAt
+	}
+}
+
+// copyProvenance copies the download provenance of src into dst.
+func copyProvenance(dst, src *store.FileRecord) {
+	dst.SourceURL = src.SourceURL
+	dst.MirrorHost = src.MirrorHost
+	dst.ETag = src.ETag
+	dst.UpstreamLastModified = src.UpstreamLastModified
+	dst.ResponseTimeMS = src.ResponseTimeMS
+}
+
+// urlHost returns the host of rawURL, or "" when it does not parse.
+func urlHost(rawURL string) string {
+	u, err := url.Parse(rawURL)
+	if err != nil {
+		return ""
+	}
+	return u.Host
+}
@@ -0,0 +1,112 @@
+package engine
+
+import (
+	"context"
+	"errors"
+	"io"
+	"log/slog"
+	"path/filepath"
+	"testing"
+
+	"github.com/BadgerOps/airgap/internal/download"
+	"github.com/BadgerOps/airgap/internal/provider"
+	"github.com/BadgerOps/airgap/internal/store"
+)
+
+func TestProvenanceLookup(t *testing.T) {
+	mgr, dataDir, _ := setupExportTest(t)
+
+	rec, err := mgr.store.GetFileRecord("epel", "9/Packages/foo.rpm")
+	if err != nil {
+		t.Fatal(err)
+	}
+	rec.SourceURL = "https://dl.fedoraproject.org/pub/epel/9/Packages/foo.rpm"
+	rec.MirrorHost = "mirror.example.com"
+	if err := mgr.store.UpsertFileRecord(rec); err != nil {
+		t.Fatal(err)
+	}
+
+	for _, path := range []string{
+		"9/Packages/foo.rpm",
+		"epel/9/Packages/foo.rpm",
+		filepath.Join(dataDir, "epel/9/Packages/foo.rpm"),
+	} {
+		got, err := mgr.Provenance(path)
+		if err != nil {
+			t.Fatalf("Provenance(%q) error: %v", path, err)
+		}
+		if len(got) != 1 || got[0].MirrorHost != "mirror.example.com" {
+			t.Errorf("Provenance(%q) = %+v, want the foo.rpm record", path, got)
+		}
+	}
+
+	if _, err := mgr.Provenance("epel/missing.rpm"); !errors.Is(err, ErrNoFileRecord) {
+		t.Errorf("Provenance(missing) error = %v, want ErrNoFileRecord", err)
+	}
+	if _, err := mgr.Provenance("/elsewhere/foo.rpm"); err == nil {
+		t.Error("Provenance() outside the data dir should fail")
+	}
+}
+
+func TestProvenanceCarriedThroughTransfer(t *testing.T) {
+	mgr, _, outputDir := setupExportTest(t)
+
+	rec, err := mgr.store.GetFileRecord("epel", "9/Packages/foo.rpm")
+	if err != nil {
+		t.Fatal(err)
+	}
+	rec.SourceURL = "https://dl.fedoraproject.org/pub/epel/9/Packages/foo.rpm"
+	rec.MirrorHost = "mirror.example.com"
+	rec.ETag = `"abc"`
+	rec.UpstreamLastModified = "Tue, 01 Jul 2025 10:00:00 GMT"
+	rec.ResponseTimeMS = 120
+	if err := mgr.store.UpsertFileRecord(rec); err != nil {
+		t.Fatal(err)
+	}
+
+	if _, err := mgr.Export(context.Background(), ExportOptions{
+		OutputDir:   outputDir,
+		Providers:   []string{"epel"},
+		SplitSize:   1024 * 1024 * 1024,
+		Compression: "zstd",
+	}); err != nil {
+		t.Fatalf("Export() error: %v", err)
+	}
+
+	// Import into a fresh store and data dir
+	logger := slog.New(slog.NewTextHandler(io.Discard, nil))
+	lowStore, err := store.New(filepath.Join(t.TempDir(), "low.db"), logger)
+	if err != nil {
+		t.Fatal(err)
+	}
+	t.Cleanup(func() { _ = lowStore.Close() })
+	lowCfg := *mgr.config
+	lowCfg.Server.DataDir = t.TempDir()
+	low := NewSyncManager(provider.NewRegistry(), lowStore, download.NewClient(logger), &lowCfg, logger)
+
+	if _, err := low.Import(context.Background(), ImportOptions{SourceDir: outputDir}); err != nil {
+		t.Fatalf("Import() error: %v", err)
+	}
+
+	got, err := low.Provenance("epel/9/Packages/foo.rpm")
+	if err != nil {
+		t.Fatalf("Provenance() error: %v", err)
+	}
+	p := got[0]
+	if p.SourceURL != rec.SourceURL || p.MirrorHost != rec.MirrorHost || p.ETag != rec.ETag ||
+		p.UpstreamLastModified != rec.UpstreamLastModified || p.ResponseTimeMS != 120 {
+		t.Errorf("imported provenance = %+v, want the exported record's", p)
+	}
+	if !p.FetchedAt.Equal(rec.LastModified) {
+		t.Errorf("FetchedAt = %v, want %v", p.FetchedAt, rec.LastModified)
+	}
+
+	// Files without provenance stay without it
+	bar, err := low.Provenance("epel/9/Packages/bar.rpm")
+	if err != nil {
+		t.Fatalf("Provenance() error: %v", err)
+	}
+	if bar[0].SourceURL != "" {
+		t.Errorf("bar.rpm SourceURL = %q, want empty", bar[0].SourceURL)
+	}
+}
@@ -0,0 +1,213 @@
+package engine
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"log/slog"
+	"path"
+	"sort"
+	"strings"
+
+	"github.com/BadgerOps/airgap/internal/config"
+	"github.com/BadgerOps/airgap/internal/provider/containerimages"
+)
+
+// artifactPush is one signature or referrer copy to the target registry.
+type artifactPush struct {
+	bundle  *localImageBundle
+	refName string
+	dest    string
+	count   int
+}
+
+// pushImageArtifacts pushes the signatures and referrers mirrored with an
+// image. Cosign artifacts keep their sha256-<hex>.sig/.att/.sbom tags.
+// Referrers are pushed through a sha256-<hex> referrers tag index, which
+// copies each referrer by digest so registries with the referrers API index
+// them by subject, and serves clients of registries without it. Artifacts
+// of an index rewritten by a platforms filter are skipped: their subject
+// digest no longer exists on the target.
+func pushImageArtifacts(
+	ctx context.Context,
+	bundle *localImageBundle,
+	targetCfg *config.RegistryProviderConfig,
+	destRepo string,
+	dryRun bool,
+	logger *slog.Logger,
+) (int, error) {
+	pushes, err := planArtifactPushes(bundle, targetCfg, destRepo, logger)
+	if err != nil {
+		return 0, err
+	}
+
+	pushed := 0
+	var errs []error
+	for _, ap := range pushes {
+		if !dryRun {
+			if err := skopeoCopyLayout(ctx, ap.bundle, targetCfg, ap.refName, ap.dest, true, logger); err != nil {
+				errs = append(errs, fmt.Errorf("%s: %w", ap.dest, err))
+				continue
+			}
+		}
+		pushed += ap.count
+	}
+	return pushed, errors.Join(errs...)
+}
+
+func planArtifactPushes(
+	bundle *localImageBundle,
+	targetCfg *config.RegistryProviderConfig,
+	destRepo string,
+	logger *slog.Logger,
+) ([]artifactPush, error) {
+	a := bundle.Artifacts
+	if a == nil {
+		return nil, nil
+	}
+	base := fmt.Sprintf("docker://%s/%s", normalizeRegistryEndpoint(targetCfg.Endpoint), strings.Trim(destRepo, "/"))
+	skip := func(subject string) bool {
+		if bundle.FilteredFrom == "" || subject != bundle.FilteredFrom {
+			return false
+		}
+		if logger != nil {
+			logger.Warn("skipping artifacts of filtered image index", "subject", subject)
+		}
+		return true
+	}
+
+	var pushes []artifactPush
+
+	tags := make([]string, 0, len(a.Tags))
+	for tag := range a.Tags {
+		tags = append(tags, tag)
+	}
+	sort.Strings(tags)
+	for _, tag := range tags {
+		if skip(subjectFromArtifactTag(tag)) {
+			continue
+		}
+		sub, err := artifactBundle(bundle, a.Tags[tag], nil)
+		if err != nil {
+			return nil, err
+		}
+		pushes = append(pushes, artifactPush{bundle: sub, refName: tag, dest: base + ":" + tag, count: 1})
+	}
+
+	bySubject := make(map[string][]containerimages.Referrer)
+	for _, r := range a.Referrers {
+		if !skip(r.Subject) {
+			bySubject[r.Subject] = append(bySubject[r.Subject], r)
+		}
+	}
+	subjects := make([]string, 0, len(bySubject))
+	for subject := range bySubject {
+		subjects = append(subjects, subject)
+	}
+	sort.Strings(subjects)
+	for _, subject := range subjects {
+		algo, hash, ok := splitDigest(subject)
+		if !ok {
+			return nil, fmt.Errorf("invalid referrer subject %s", subject)
+		}
+		index, err := referrersTagIndex(bySubject[subject])
+		if err != nil {
+			return nil, err
+		}
+		sub, err := artifactBundle(bundle, digestBytes(index.Bytes), index)
+		if err != nil {
+			return nil, err
+		}
+		tag := algo + "-" + hash
+		pushes = append(pushes, artifactPush{bundle: sub, refName: tag, dest: base + ":" + tag, count: len(bySubject[subject])})
+	}
+	return pushes, nil
+}
+
+// artifactBundle returns the part of bundle rooted at digest. extra, when
+// set, is an additional manifest (the root) that only exists for this push.
+func artifactBundle(bundle *localImageBundle, digest string, extra *localManifest) (*localImageBundle, error) {
+	manifests := bundle.Manifests
+	if extra != nil {
+		manifests = make(map[string]*localManifest, len(bundle.Manifests)+1)
+		for d, m := range bundle.Manifests {
+			manifests[d] = m
+		}
+		manifests[digest] = extra
+	}
+	root := manifests[digest]
+	if root == nil {
+		return nil, fmt.Errorf("artifact manifest %s not found in local cache", digest)
+	}
+	if missing := missingChildManifests(digest, manifests); len(missing) > 0 {
+		return nil, fmt.Errorf("artifact manifests not found in local cache: %s", strings.Join(flattenMissing(missing), ", "))
+	}
+
+	order := buildManifestPostOrder(digest, manifests)
+	blobs := collectRequiredBlobDigests(order, manifests)
+	for _, d := range blobs {
+		if _, ok := bundle.BlobSourcePath[d]; !ok {
+			return nil, fmt.Errorf("artifact blob %s not found in local cache", d)
+		}
+	}
+	return &localImageBundle{
+		ImageRoot:      bundle.ImageRoot,
+		SourceRef:      bundle.SourceRef,
+		RootDigest:     digest,
+		RootMediaType:  root.MediaType,
+		Manifests:      manifests,
+		ManifestOrder:  order,
+		RequiredBlobs:  blobs,
+		BlobSourcePath: bundle.BlobSourcePath,
+	}, nil
+}
+
+// referrersTagIndex builds the OCI referrers tag schema index listing refs.
+func referrersTagIndex(refs []containerimages.Referrer) (*localManifest, error) {
+	type entry struct {
+		MediaType    string `json:"mediaType"`
+		Digest       string `json:"digest"`
+		Size         int64  `json:"size"`
+		ArtifactType string `json:"artifactType,omitempty"`
+	}
+	sorted := append([]containerimages.Referrer(nil), refs...)
+	sort.Slice(sorted, func(i, j int) bool { return sorted[i].Digest < sorted[j].Digest })
+
+	entries := make([]entry, 0, len(sorted))
+	var children []string
+	seen := make(map[string]bool)
+	for _, r := range sorted {
+		if seen[r.Digest] {
+			continue
+		}
+		seen[r.Digest] = true
+		entries = append(entries, entry{MediaType: r.MediaType, Digest: r.Digest, Size: r.Size, ArtifactType: r.ArtifactType})
+		children = append(children, r.Digest)
+	}
+	data, err := json.Marshal(map[string]interface{}{
+		"schemaVersion": 2,
+		"mediaType":     "application/vnd.oci.image.index.v1+json",
+		"manifests":     entries,
+	})
+	if err != nil {
+		return nil, fmt.Errorf("marshaling referrers index: %w", err)
+	}
+	return &localManifest{
+		Digest:         digestBytes(data),
+		MediaType:      "application/vnd.oci.image.index.v1+json",
+		Bytes:          data,
+		ChildManifests: children,
+	}, nil
+}
+
+// subjectFromArtifactTag maps a cosign tag such as sha256-<hex>.sig back to
+// the digest it is attached to.
+func subjectFromArtifactTag(tag string) string {
+	base := strings.TrimSuffix(tag, path.Ext(tag))
+	algo, hash, ok := strings.Cut(base, "-")
+	if !ok {
+		return ""
+	}
+	return algo + ":" + hash
+}
@@ -2,6 +2,8 @@ package engine
 
 import (
 	"context"
+	"crypto/sha256"
+	"encoding/hex"
 	"encoding/json"
 	"fmt"
 	"io"
@@ -15,6 +17,7 @@ import (
 
 	"github.com/BadgerOps/airgap/internal/config"
 	"github.com/BadgerOps/airgap/internal/provider/containerimages"
+	"github.com/BadgerOps/airgap/internal/provider/operatorcatalog"
 	"github.com/BadgerOps/airgap/internal/safety"
 )
 
@@ -33,8 +36,16 @@ type RegistryPushReport struct {
 	ImagesPushed    int
 	BlobsProcessed  int
 	ManifestsPushed int
+	// ArtifactsPushed counts signature and referrer manifests pushed.
+	ArtifactsPushed int
 	Failures        []string
 	Duration        time.Duration
+	// Mappings are the source -> destination repositories of pushed images.
+	Mappings []RepositoryMapping
+	// MirrorConfigDir holds the cluster manifests generated from Mappings;
+	// MirrorConfigFiles lists the file names written there.
+	MirrorConfigDir   string
+	MirrorConfigFiles []string
 }
 
 type localManifest struct {
@@ -54,10 +65,15 @@ type localImageBundle struct {
 	ManifestOrder  []string
 	RequiredBlobs  []string
 	BlobSourcePath map[string]string
+	// FilteredFrom is the original index digest when RootDigest is an index
+	// rewritten to the platforms mirrored locally.
+	FilteredFrom string
+	// Artifacts are the signatures and referrers mirrored with the image.
+	Artifacts *containerimages.ImageArtifacts
 }
 
-// PushContainerImages pushes mirrored container images from a container_images provider
-// to a configured registry target.
+// PushContainerImages pushes mirrored container images from a container_images or
+// operator_catalog provider to a configured registry target.
 func (m *SyncManager) PushContainerImages(ctx context.Context, opts RegistryPushOptions) (*RegistryPushReport, error) {
 	start := time.Now()
 	if opts.SourceProvider == "" {
@@ -74,8 +90,8 @@ func (m *SyncManager) PushContainerImages(ctx context.Context, opts RegistryPush
 	if err != nil {
 		return nil, fmt.Errorf("reading source provider config %q: %w", opts.SourceProvider, err)
 	}
-	if sourcePC.Type != "container_images" {
-		return nil, fmt.Errorf("provider %q is type %q, expected container_images", opts.SourceProvider, sourcePC.Type)
+	if sourcePC.Type != "container_images" && sourcePC.Type != "operator_catalog" {
+		return nil, fmt.Errorf("provider %q is type %q, expected container_images or operator_catalog", opts.SourceProvider, sourcePC.Type)
 	}
 
 	targetPC, err := m.store.GetProviderConfig(opts.TargetProvider)
@@ -86,12 +102,30 @@ func (m *SyncManager) PushContainerImages(ctx context.Context, opts RegistryPush
 		return nil, fmt.Errorf("provider %q is type %q, expected registry", opts.TargetProvider, targetPC.Type)
 	}
 
-	sourceCfg, err := parseProviderConfigJSON[config.ContainerImagesProviderConfig](sourcePC.ConfigJSON)
+	sourceRoot, err := safety.SafeJoinUnder(m.config.Server.DataDir, opts.SourceProvider)
+	if err != nil {
+		return nil, fmt.Errorf("invalid source provider root: %w", err)
+	}
+	sourceImages, sourceOutputDir, err := loadSourceImages(sourcePC.Type, sourcePC.ConfigJSON, sourceRoot)
 	if err != nil {
-		return nil, fmt.Errorf("parsing container images config: %w", err)
+		return nil, err
+	}
+	// A platforms filter leaves multi-arch indexes partially mirrored.
+	sparse := false
+	if sourcePC.Type == "container_images" {
+		if cfg, err := parseProviderConfigJSON[config.ContainerImagesProviderConfig](sourcePC.ConfigJSON); err == nil {
+			sparse = len(cfg.Platforms) > 0
+		}
 	}
-	if sourceCfg.OutputDir == "" {
-		sourceCfg.OutputDir = "images"
+	catalogs := make(map[string]bool)
+	if sourcePC.Type == "operator_catalog" {
+		refs, err := operatorcatalog.MirroredCatalogs(sourceRoot, sourceOutputDir)
+		if err != nil {
+			return nil, err
+		}
+		for _, ref := range refs {
+			catalogs[ref] = true
+		}
 	}
 
 	targetCfg, err := parseProviderConfigJSON[config.RegistryProviderConfig](targetPC.ConfigJSON)
@@ -112,20 +146,16 @@ func (m *SyncManager) PushContainerImages(ctx context.Context, opts RegistryPush
 	report := &RegistryPushReport{
 		SourceProvider: opts.SourceProvider,
 		TargetProvider: opts.TargetProvider,
-		ImagesTotal:    len(sourceCfg.Images),
+		ImagesTotal:    len(sourceImages),
 	}
 
-	if len(sourceCfg.Images) == 0 {
+	if len(sourceImages) == 0 {
 		report.Duration = time.Since(start)
 		return report, nil
 	}
 
-	sourceRoot, err := safety.SafeJoinUnder(m.config.Server.DataDir, opts.SourceProvider)
-	if err != nil {
-		return nil, fmt.Errorf("invalid source provider root: %w", err)
-	}
-
-	for _, raw := range sourceCfg.Images {
+	var pushedCatalogs []string
+	for _, raw := range sourceImages {
 		select {
 		case <-ctx.Done():
 			report.Duration = time.Since(start)
@@ -139,14 +169,14 @@ func (m *SyncManager) PushContainerImages(ctx context.Context, opts RegistryPush
 			continue
 		}
 
-		imageDirRel := filepath.Join(sourceCfg.OutputDir, containerimages.LocalImageID(ref))
+		imageDirRel := filepath.Join(sourceOutputDir, containerimages.LocalImageID(ref))
 		imageRoot, err := safety.SafeJoinUnder(sourceRoot, imageDirRel)
 		if err != nil {
 			report.Failures = append(report.Failures, fmt.Sprintf("%s: invalid local image path: %v", raw, err))
 			continue
 		}
 
-		bundle, err := loadLocalImageBundle(imageRoot, ref)
+		bundle, err := loadLocalImageBundle(imageRoot, ref, sparse)
 		if err != nil {
 			report.Failures = append(report.Failures, fmt.Sprintf("%s: load failed: %v", raw, err))
 			continue
@@ -162,6 +192,30 @@ func (m *SyncManager) PushContainerImages(ctx context.Context, opts RegistryPush
 		report.ImagesPushed++
 		report.BlobsProcessed += stats.Blobs
 		report.ManifestsPushed += stats.Manifests
+
+		artifacts, err := pushImageArtifacts(ctx, bundle, targetCfg, destRepo, opts.DryRun, m.logger)
+		report.ArtifactsPushed += artifacts
+		if err != nil {
+			report.Failures = append(report.Failures, fmt.Sprintf("%s -> %s/%s: artifacts: %v", raw, targetCfg.Endpoint, destRepo, err))
+		}
+
+		destination := normalizeRegistryEndpoint(targetCfg.Endpoint) + "/" + destRepo
+		report.Mappings = append(report.Mappings, RepositoryMapping{
+			Source:      ref.Registry + "/" + ref.Repository,
+			Destination: destination,
+			ByTag:       !ref.IsDigest,
+		})
+		if catalogs[raw] {
+			pushedCatalogs = append(pushedCatalogs, destination+":"+ref.Reference)
+		}
+	}
+
+	report.Mappings = mergeMappings(report.Mappings)
+	if !opts.DryRun && len(report.Mappings) > 0 {
+		if err := m.writePushMirrorConfig(report, targetCfg, pushedCatalogs); err != nil {
+			m.logger.Error("failed to write mirror config", "source_provider", opts.SourceProvider, "error", err)
+			report.Failures = append(report.Failures, fmt.Sprintf("mirror config: %v", err))
+		}
 	}
 
 	report.Duration = time.Since(start)
@@ -171,6 +225,59 @@ func (m *SyncManager) PushContainerImages(ctx context.Context, opts RegistryPush
 	return report, nil
 }
 
+// writePushMirrorConfig generates the cluster mirror manifests for a push.
+func (m *SyncManager) writePushMirrorConfig(report *RegistryPushReport, targetCfg *config.RegistryProviderConfig, catalogs []string) error {
+	dir, err := m.MirrorConfigDir(report.SourceProvider, report.TargetProvider)
+	if err != nil {
+		return fmt.Errorf("invalid mirror config dir: %w", err)
+	}
+	var trustBundle string
+	if targetCfg.CABundle != "" {
+		data, err := os.ReadFile(targetCfg.CABundle)
+		if err != nil {
+			return fmt.Errorf("reading ca_bundle: %w", err)
+		}
+		trustBundle = string(data)
+	}
+	files, err := writeMirrorConfig(dir, report.SourceProvider, report.Mappings, catalogs, trustBundle)
+	if err != nil {
+		return err
+	}
+	report.MirrorConfigDir = dir
+	report.MirrorConfigFiles = files
+	m.logger.Info("wrote mirror config", "dir", dir, "files", len(files))
+	return nil
+}
+
+// loadSourceImages returns the image references and output directory of a
+// push source. Operator catalogs list the images chosen by their last plan.
+func loadSourceImages(providerType, cfgJSON, sourceRoot string) ([]string, string, error) {
+	switch providerType {
+	case "operator_catalog":
+		cfg, err := parseProviderConfigJSON[config.OperatorCatalogProviderConfig](cfgJSON)
+		if err != nil {
+			return nil, "", fmt.Errorf("parsing operator catalog config: %w", err)
+		}
+		if cfg.OutputDir == "" {
+			cfg.OutputDir = "operators"
+		}
+		images, err := operatorcatalog.MirroredImages(sourceRoot, cfg.OutputDir)
+		if err != nil {
+			return nil, "", err
+		}
+		return images, cfg.OutputDir, nil
+	default:
+		cfg, err := parseProviderConfigJSON[config.ContainerImagesProviderConfig](cfgJSON)
+		if err != nil {
+			return nil, "", fmt.Errorf("parsing container images config: %w", err)
+		}
+		if cfg.OutputDir == "" {
+			cfg.OutputDir = "images"
+		}
+		return cfg.Images, cfg.OutputDir, nil
+	}
+}
+
 func parseProviderConfigJSON[T any](cfgJSON string) (*T, error) {
 	var raw map[string]interface{}
 	if strings.TrimSpace(cfgJSON) != "" {
@@ -193,7 +300,10 @@ func buildDestinationRepository(sourceRepo, prefix string) string {
 	return prefix + "/" + sourceRepo
 }
 
-func loadLocalImageBundle(imageRoot string, ref containerimages.ImageReference) (*localImageBundle, error) {
+// loadLocalImageBundle reads a mirrored image from imageRoot. When sparse is
+// set, a root index whose children were not all mirrored is rewritten to the
+// children present locally; otherwise missing children are an error.
+func loadLocalImageBundle(imageRoot string, ref containerimages.ImageReference, sparse bool) (*localImageBundle, error) {
 	manifestFiles, err := filepath.Glob(filepath.Join(imageRoot, "manifests", "*", "*.json"))
 	if err != nil {
 		return nil, fmt.Errorf("listing manifest files: %w", err)
@@ -223,7 +333,11 @@ func loadLocalImageBundle(imageRoot string, ref containerimages.ImageReference)
 		}
 	}
 
-	rootDigest, err := chooseRootDigest(ref, manifests)
+	artifacts, err := containerimages.ReadImageArtifacts(imageRoot)
+	if err != nil {
+		return nil, err
+	}
+	rootDigest, err := chooseRootDigest(ref, withoutArtifacts(manifests, artifacts.Digests()))
 	if err != nil {
 		return nil, err
 	}
@@ -232,6 +346,30 @@ func loadLocalImageBundle(imageRoot string, ref containerimages.ImageReference)
 		return nil, fmt.Errorf("root manifest %s not found", rootDigest)
 	}
 
+	var filteredFrom string
+	if missing := missingChildManifests(rootDigest, manifests); len(missing) > 0 {
+		if !sparse || len(missing[rootDigest]) == 0 || len(missing) > 1 {
+			return nil, fmt.Errorf("manifest children not found in local cache: %s", strings.Join(flattenMissing(missing), ", "))
+		}
+		filtered, err := filterIndexManifests(rootManifest.Bytes, func(d string) bool {
+			return manifests[d] != nil
+		})
+		if err != nil {
+			return nil, fmt.Errorf("filtering index %s: %w", rootDigest, err)
+		}
+		filteredFrom = rootDigest
+		rootDigest = digestBytes(filtered)
+		mediaType, childDigests, blobDigests := parseManifestDetails(filtered)
+		rootManifest = &localManifest{
+			Digest:          rootDigest,
+			MediaType:       mediaType,
+			Bytes:           filtered,
+			ChildManifests:  childDigests,
+			ReferencedBlobs: blobDigests,
+		}
+		manifests[rootDigest] = rootManifest
+	}
+
 	manifestOrder := buildManifestPostOrder(rootDigest, manifests)
 	requiredBlobs := collectRequiredBlobDigests(manifestOrder, manifests)
 	blobPaths := mapLocalBlobPaths(filepath.Join(imageRoot, "blobs"))
@@ -251,9 +389,104 @@ func loadLocalImageBundle(imageRoot string, ref containerimages.ImageReference)
 		ManifestOrder:  manifestOrder,
 		RequiredBlobs:  requiredBlobs,
 		BlobSourcePath: blobPaths,
+		FilteredFrom:   filteredFrom,
+		Artifacts:      artifacts,
 	}, nil
 }
 
+// withoutArtifacts returns manifests minus the artifact manifests and the
+// manifests they reference, leaving the image graph.
+func withoutArtifacts(manifests map[string]*localManifest, artifactDigests []string) map[string]*localManifest {
+	if len(artifactDigests) == 0 {
+		return manifests
+	}
+	drop := make(map[string]bool)
+	for _, d := range artifactDigests {
+		for _, m := range buildManifestPostOrder(d, manifests) {
+			drop[m] = true
+		}
+	}
+	out := make(map[string]*localManifest, len(manifests))
+	for d, m := range manifests {
+		if !drop[d] {
+			out[d] = m
+		}
+	}
+	return out
+}
+
+// missingChildManifests returns, per manifest reachable from root, the
+// child digests that are not present locally.
+func missingChildManifests(root string, manifests map[string]*localManifest) map[string][]string {
+	missing := make(map[string][]string)
+	seen := make(map[string]bool)
+	queue := []string{root}
+	for len(queue) > 0 {
+		d := queue[0]
+		queue = queue[1:]
+		if seen[d] {
+			continue
+		}
+		seen[d] = true
+		for _, child := range manifests[d].ChildManifests {
+			if manifests[child] == nil {
+				missing[d] = append(missing[d], child)
+				continue
+			}
+			queue = append(queue, child)
+		}
+	}
+	return missing
+}
+
+func flattenMissing(missing map[string][]string) []string {
+	var out []string
+	for _, children := range missing {
+		out = append(out, children...)
+	}
+	sort.Strings(out)
+	return out
+}
+
+// filterIndexManifests returns index with only the manifests entries whose
+// digest satisfies keep. Other fields are preserved as-is.
+func filterIndexManifests(index []byte, keep func(string) bool) ([]byte, error) {
+	var doc map[string]json.RawMessage
+	if err := json.Unmarshal(index, &doc); err != nil {
+		return nil, err
+	}
+	var entries []json.RawMessage
+	if err := json.Unmarshal(doc["manifests"], &entries); err != nil {
+		return nil, fmt.Errorf("parsing manifests: %w", err)
+	}
+	kept := make([]json.RawMessage, 0, len(entries))
+	for _, raw := range entries {
+		var desc struct {
+			Digest string `json:"digest"`
+		}
+		if err := json.Unmarshal(raw, &desc); err != nil {
+			return nil, fmt.Errorf("parsing manifest descriptor: %w", err)
+		}
+		if keep(strings.TrimSpace(desc.Digest)) {
+			kept = append(kept, raw)
+		}
+	}
+	if len(kept) == 0 {
+		return nil, fmt.Errorf("no platform manifests mirrored")
+	}
+	manifestsJSON, err := json.Marshal(kept)
+	if err != nil {
+		return nil, err
+	}
+	doc["manifests"] = manifestsJSON
+	return json.Marshal(doc)
+}
+
+func digestBytes(data []byte) string {
+	sum := sha256.Sum256(data)
+	return "sha256:" + hex.EncodeToString(sum[:])
+}
+
 func parseManifestDetails(data []byte) (mediaType string, childDigests []string, blobDigests []string) {
 	var probe struct {
 		MediaType string `json:"mediaType"`
@@ -435,28 +668,60 @@ func pushImageBundleWithSkopeo(
 		return stats, nil
 	}
 
+	endpoint := normalizeRegistryEndpoint(targetCfg.Endpoint)
+	dest := fmt.Sprintf("docker://%s/%s", endpoint, strings.Trim(destRepo, "/"))
+	if bundle.SourceRef.IsDigest && bundle.FilteredFrom != "" {
+		// The filtered index no longer matches the requested digest, so it
+		// is published under the digest tag alias instead.
+		dest += ":" + layoutRefName
+	} else if bundle.SourceRef.IsDigest {
+		dest += "@" + bundle.SourceRef.Reference
+	} else {
+		dest += ":" + bundle.SourceRef.Reference
+	}
+
+	if err := skopeoCopyLayout(ctx, bundle, targetCfg, layoutRefName, dest, false, logger); err != nil {
+		return nil, err
+	}
+	if logger != nil && bundle.FilteredFrom != "" {
+		logger.Info("pushed filtered image index",
+			"destination", dest,
+			"original_digest", bundle.FilteredFrom,
+			"digest", bundle.RootDigest,
+		)
+	}
+	return stats, nil
+}
+
+// skopeoCopyLayout writes bundle to a temporary OCI layout and copies it to
+// dest. preserveDigests makes skopeo refuse any manifest conversion, which
+// signatures and referrers rely on.
+func skopeoCopyLayout(
+	ctx context.Context,
+	bundle *localImageBundle,
+	targetCfg *config.RegistryProviderConfig,
+	layoutRefName string,
+	dest string,
+	preserveDigests bool,
+	logger *slog.Logger,
+) error {
 	layoutDir, err := os.MkdirTemp("", "airgap-oci-layout-*")
 	if err != nil {
-		return nil, fmt.Errorf("creating temporary OCI layout dir: %w", err)
+		return fmt.Errorf("creating temporary OCI layout dir: %w", err)
 	}
 	defer func() {
 		_ = os.RemoveAll(layoutDir)
 	}()
 
 	if err := writeOCILayout(layoutDir, bundle, layoutRefName); err != nil {
-		return nil, err
-	}
-
-	endpoint := normalizeRegistryEndpoint(targetCfg.Endpoint)
-	dest := fmt.Sprintf("docker://%s/%s", endpoint, strings.Trim(destRepo, "/"))
-	if bundle.SourceRef.IsDigest {
-		dest += "@" + bundle.SourceRef.Reference
-	} else {
-		dest += ":" + bundle.SourceRef.Reference
+		return err
 	}
 
 	src := fmt.Sprintf("oci:%s:%s", layoutDir, layoutRefName)
 	args := []string{"copy", "--all"}
+	if preserveDigests {
+		args = append(args, "--preserve-digests")
+	}
 	if targetCfg.InsecureSkipTLS {
 		args = append(args, "--dest-tls-verify=false")
 	}
@@ -476,12 +741,12 @@ func pushImageBundleWithSkopeo(
 				"output", string(out),
 			)
 		}
-		return nil, fmt.Errorf("skopeo copy failed: %w: %s", err, strings.TrimSpace(string(out)))
+		return fmt.Errorf("skopeo copy failed: %w: %s", err, strings.TrimSpace(string(out)))
 	}
 	if logger != nil {
 		logger.Info("image pushed to registry", "destination", dest)
 	}
-	return stats, nil
+	return nil
 }
 
 func normalizeRegistryEndpoint(endpoint string) string {
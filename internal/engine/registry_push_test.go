@@ -1,9 +1,12 @@
 package engine
 
 import (
+	"os"
+	"path/filepath"
 	"strings"
 	"testing"
 
+	"github.com/BadgerOps/airgap/internal/config"
 	"github.com/BadgerOps/airgap/internal/provider/containerimages"
 )
 
@@ -96,3 +99,155 @@ func TestChooseRootDigestMultipleCandidatesError(t *testing.T) {
 		t.Fatal("expected error for ambiguous root candidates")
 	}
 }
+
+func TestLoadSourceImagesOperatorCatalog(t *testing.T) {
+	root := t.TempDir()
+	listDir := filepath.Join(root, "ops", "catalogs", "quay.io_org_index_v1")
+	if err := os.MkdirAll(listDir, 0o755); err != nil {
+		t.Fatal(err)
+	}
+	list := `{"catalog":"quay.io/org/index:v1","images":["quay.io/org/bundle@sha256:aa","quay.io/org/operator:1.0"]}`
+	if err := os.WriteFile(filepath.Join(listDir, "images.json"), []byte(list), 0o644); err != nil {
+		t.Fatal(err)
+	}
+
+	images, outputDir, err := loadSourceImages("operator_catalog", `{"output_dir":"ops"}`, root)
+	if err != nil {
+		t.Fatalf("loadSourceImages failed: %v", err)
+	}
+	if outputDir != "ops" {
+		t.Fatalf("expected output dir ops, got %q", outputDir)
+	}
+	want := "quay.io/org/index:v1,quay.io/org/bundle@sha256:aa,quay.io/org/operator:1.0"
+	if got := strings.Join(images, ","); got != want {
+		t.Fatalf("images = %s, want %s", got, want)
+	}
+
+	images, outputDir, err = loadSourceImages("container_images", `{"images":["quay.io/org/app:1"]}`, root)
+	if err != nil {
+		t.Fatalf("loadSourceImages failed: %v", err)
+	}
+	if outputDir != "images" || len(images) != 1 {
+		t.Fatalf("unexpected container_images source: %v %q", images, outputDir)
+	}
+}
+
+func TestLoadLocalImageBundleFiltersSparseIndex(t *testing.T) {
+	imageRoot := t.TempDir()
+	write := func(kind, digest string, data []byte) {
+		algo, hash, _ := splitDigest(digest)
+		name := hash
+		if kind == "manifests" {
+			name += ".json"
+		}
+		p := filepath.Join(imageRoot, kind, algo, name)
+		if err := os.MkdirAll(filepath.Dir(p), 0o755); err != nil {
+			t.Fatal(err)
+		}
+		if err := os.WriteFile(p, data, 0o644); err != nil {
+			t.Fatal(err)
+		}
+	}
+
+	configBlob := []byte(`{"architecture":"amd64","os":"linux"}`)
+	amd64 := []byte(`{"schemaVersion":2,"mediaType":"application/vnd.oci.image.manifest.v1+json","config":{"digest":"` + digestBytes(configBlob) + `"},"layers":[]}`)
+	arm64Digest := digestBytes([]byte("arm64"))
+	index := []byte(`{"schemaVersion":2,"mediaType":"application/vnd.oci.image.index.v1+json","manifests":[` +
+		`{"digest":"` + digestBytes(amd64) + `","platform":{"os":"linux","architecture":"amd64"}},` +
+		`{"digest":"` + arm64Digest + `","platform":{"os":"linux","architecture":"arm64"}}]}`)
+	write("manifests", digestBytes(index), index)
+	write("manifests", digestBytes(amd64), amd64)
+	write("blobs", digestBytes(configBlob), configBlob)
+
+	ref := containerimages.ImageReference{Reference: digestBytes(index), IsDigest: true}
+	if _, err := loadLocalImageBundle(imageRoot, ref, false); err == nil || !strings.Contains(err.Error(), arm64Digest) {
+		t.Fatalf("expected missing child error, got %v", err)
+	}
+
+	bundle, err := loadLocalImageBundle(imageRoot, ref, true)
+	if err != nil {
+		t.Fatalf("loadLocalImageBundle failed: %v", err)
+	}
+	if bundle.FilteredFrom != digestBytes(index) || bundle.RootDigest == digestBytes(index) {
+		t.Fatalf("expected rewritten root, got root %s filtered from %s", bundle.RootDigest, bundle.FilteredFrom)
+	}
+	root := bundle.Manifests[bundle.RootDigest]
+	if digestBytes(root.Bytes) != bundle.RootDigest {
+		t.Fatal("root digest does not match filtered bytes")
+	}
+	if len(root.ChildManifests) != 1 || root.ChildManifests[0] != digestBytes(amd64) {
+		t.Fatalf("expected only the amd64 child, got %v", root.ChildManifests)
+	}
+	if strings.Contains(string(root.Bytes), arm64Digest) || !strings.Contains(string(root.Bytes), `"platform"`) {
+		t.Fatalf("unexpected filtered index: %s", root.Bytes)
+	}
+	if len(bundle.ManifestOrder) != 2 || bundle.ManifestOrder[1] != bundle.RootDigest {
+		t.Fatalf("unexpected manifest order: %v", bundle.ManifestOrder)
+	}
+}
+
+func TestPlanArtifactPushes(t *testing.T) {
+	imageRoot := t.TempDir()
+	write := func(kind string, data []byte) string {
+		digest := digestBytes(data)
+		algo, hash, _ := splitDigest(digest)
+		name := hash
+		if kind == "manifests" {
+			name += ".json"
+		}
+		p := filepath.Join(imageRoot, kind, algo, name)
+		if err := os.MkdirAll(filepath.Dir(p), 0o755); err != nil {
+			t.Fatal(err)
+		}
+		if err := os.WriteFile(p, data, 0o644); err != nil {
+			t.Fatal(err)
+		}
+		return digest
+	}
+	manifest := func(config string) []byte {
+		configDigest := write("blobs", []byte(config))
+		return []byte(`{"schemaVersion":2,"mediaType":"application/vnd.oci.image.manifest.v1+json","config":{"digest":"` + configDigest + `"},"layers":[]}`)
+	}
+
+	image := write("manifests", manifest(`{"os":"linux"}`))
+	sig := write("manifests", manifest(`{"sig":true}`))
+	sbom := write("manifests", manifest(`{"sbom":true}`))
+	_, imageHash, _ := splitDigest(image)
+	sigTag := "sha256-" + imageHash + ".sig"
+	artifacts := `{"tags":{"` + sigTag + `":"` + sig + `"},"referrers":[{"subject":"` + image + `","digest":"` + sbom + `","mediaType":"application/vnd.oci.image.manifest.v1+json","artifactType":"application/spdx+json","size":10}]}`
+	if err := os.WriteFile(filepath.Join(imageRoot, containerimages.ArtifactsFile), []byte(artifacts), 0o644); err != nil {
+		t.Fatal(err)
+	}
+
+	bundle, err := loadLocalImageBundle(imageRoot, containerimages.ImageReference{Reference: "1.0"}, false)
+	if err != nil {
+		t.Fatalf("loadLocalImageBundle failed: %v", err)
+	}
+	if bundle.RootDigest != image {
+		t.Fatalf("root = %s, want image manifest %s", bundle.RootDigest, image)
+	}
+
+	target := &config.RegistryProviderConfig{Endpoint: "https://quay.example.com:8443/"}
+	pushes, err := planArtifactPushes(bundle, target, "mirror/org/app", nil)
+	if err != nil {
+		t.Fatalf("planArtifactPushes failed: %v", err)
+	}
+	if len(pushes) != 2 {
+		t.Fatalf("expected 2 pushes, got %d", len(pushes))
+	}
+	if pushes[0].dest != "docker://quay.example.com:8443/mirror/org/app:"+sigTag || pushes[0].bundle.RootDigest != sig {
+		t.Fatalf("unexpected signature push: %s root %s", pushes[0].dest, pushes[0].bundle.RootDigest)
+	}
+	refIndex := pushes[1].bundle.Manifests[pushes[1].bundle.RootDigest]
+	if pushes[1].dest != "docker://quay.example.com:8443/mirror/org/app:sha256-"+imageHash || !strings.Contains(string(refIndex.Bytes), sbom) {
+		t.Fatalf("unexpected referrers push: %s %s", pushes[1].dest, refIndex.Bytes)
+	}
+	if len(pushes[1].bundle.ManifestOrder) != 2 || len(pushes[1].bundle.RequiredBlobs) != 1 {
+		t.Fatalf("referrers push should carry the referrer manifest and its config: %v %v", pushes[1].bundle.ManifestOrder, pushes[1].bundle.RequiredBlobs)
+	}
+
+	bundle.FilteredFrom = image
+	if pushes, _ := planArtifactPushes(bundle, target, "mirror/org/app", nil); len(pushes) != 0 {
+		t.Fatalf("expected artifacts of a filtered index to be skipped, got %d", len(pushes))
+	}
+}
@@ -0,0 +1,87 @@
+package engine
+
+import (
+	"encoding/json"
+	"fmt"
+
+	"github.com/BadgerOps/airgap/internal/config"
+	"github.com/BadgerOps/airgap/internal/store"
+)
+
+// RevisionDiff lists the changes between two revisions of a provider config.
+// Type and enabled changes are reported as the "type" and "enabled" paths;
+// config changes are prefixed with "config.".
+type RevisionDiff struct {
+	Provider string          `json:"provider"`
+	From     int             `json:"from"`
+	To       int             `json:"to"`
+	Changes  []config.Change `json:"changes"`
+}
+
+// DiffProviderConfigRevisions compares revisions from and to of provider
+// name. Credential values are redacted.
+func (m *SyncManager) DiffProviderConfigRevisions(name string, from, to int) (*RevisionDiff, error) {
+	if m.store == nil {
+		return nil, fmt.Errorf("store not initialized")
+	}
+	a, err := m.store.GetProviderConfigRevision(name, from)
+	if err != nil {
+		return nil, err
+	}
+	b, err := m.store.GetProviderConfigRevision(name, to)
+	if err != nil {
+		return nil, err
+	}
+
+	changes := config.Diff(revisionState(a), revisionState(b))
+	if changes == nil {
+		changes = []config.Change{}
+	}
+	return &RevisionDiff{Provider: name, From: from, To: to, Changes: changes}, nil
+}
+
+// revisionState decodes a revision into the map compared by
+// DiffProviderConfigRevisions.
+func revisionState(rev *store.ProviderConfigRevision) map[string]interface{} {
+	var cfg map[string]interface{}
+	if err := json.Unmarshal([]byte(rev.ConfigJSON), &cfg); err != nil || cfg == nil {
+		cfg = map[string]interface{}{}
+	}
+	return map[string]interface{}{
+		"type":    rev.Type,
+		"enabled": rev.Enabled,
+		"config":  cfg,
+	}
+}
+
+// RollbackProviderConfig restores provider name to revision and records the
+// result as a new revision by author. A deleted provider is recreated. The
+// caller reloads providers afterwards.
+func (m *SyncManager) RollbackProviderConfig(name string, revision int, author, comment string) (*store.ProviderConfigRevision, error) {
+	if m.store == nil {
+		return nil, fmt.Errorf("store not initialized")
+	}
+	target, err := m.store.GetProviderConfigRevision(name, revision)
+	if err != nil {
+		return nil, err
+	}
+
+	pc, err := m.store.GetProviderConfig(name)
+	if err != nil {
+		pc = &store.ProviderConfig{Name: name, Type: target.Type, Enabled: target.Enabled, ConfigJSON: target.ConfigJSON}
+		if err := m.store.CreateProviderConfig(pc); err != nil {
+			return nil, fmt.Errorf("recreating provider %s: %w", name, err)
+		}
+	} else {
+		pc.Type, pc.Enabled, pc.ConfigJSON = target.Type, target.Enabled, target.ConfigJSON
+		if err := m.store.UpdateProviderConfig(pc); err != nil {
+			return nil, fmt.Errorf("updating provider %s: %w", name, err)
+		}
+	}
+
+	msg := fmt.Sprintf("rollback to revision %d", revision)
+	if comment != "" {
+		msg += ": " + comment
+	}
+	return m.store.AddProviderConfigRevision(name, author, msg)
+}
@@ -0,0 +1,74 @@
+package engine
+
+import (
+	"reflect"
+	"testing"
+
+	"github.com/BadgerOps/airgap/internal/provider"
+	"github.com/BadgerOps/airgap/internal/store"
+)
+
+func TestRollbackProviderConfig(t *testing.T) {
+	m, st := newTestSyncManager(t, provider.NewRegistry())
+
+	pc := &store.ProviderConfig{Name: "epel", Type: "epel", Enabled: true, ConfigJSON: `{"repos":[{"name":"a","base_url":"https://good"}],"token":"t1"}`}
+	if err := st.CreateProviderConfig(pc); err != nil {
+		t.Fatal(err)
+	}
+	if _, err := st.AddProviderConfigRevision("epel", "alice", "initial"); err != nil {
+		t.Fatal(err)
+	}
+	pc.ConfigJSON = `{"repos":[{"name":"a","base_url":"https://broken"}],"token":"t2"}`
+	pc.Enabled = false
+	if err := st.UpdateProviderConfig(pc); err != nil {
+		t.Fatal(err)
+	}
+	if _, err := st.AddProviderConfigRevision("epel", "bob", "oops"); err != nil {
+		t.Fatal(err)
+	}
+
+	diff, err := m.DiffProviderConfigRevisions("epel", 1, 2)
+	if err != nil {
+		t.Fatalf("DiffProviderConfigRevisions failed: %v", err)
+	}
+	var paths []string
+	for _, c := range diff.Changes {
+		paths = append(paths, c.Path)
+		if c.Path == "config.token" && (c.Before != "[REDACTED]" || c.After != "[REDACTED]") {
+			t.Errorf("token change not redacted: %+v", c)
+		}
+	}
+	if want := []string{"config.repos[0].base_url", "config.token", "enabled"}; !reflect.DeepEqual(paths, want) {
+		t.Errorf("diff paths = %v, want %v", paths, want)
+	}
+
+	rev, err := m.RollbackProviderConfig("epel", 1, "carol", "restore mirror")
+	if err != nil {
+		t.Fatalf("RollbackProviderConfig failed: %v", err)
+	}
+	if rev.Revision != 3 || rev.Author != "carol" || rev.Comment != "rollback to revision 1: restore mirror" {
+		t.Errorf("rollback revision = %+v", rev)
+	}
+	got, err := st.GetProviderConfig("epel")
+	if err != nil {
+		t.Fatal(err)
+	}
+	if !got.Enabled || got.ConfigJSON != `{"repos":[{"name":"a","base_url":"https://good"}],"token":"t1"}` {
+		t.Errorf("config after rollback = %+v", got)
+	}
+
+	// A deleted provider is recreated from its history.
+	if err := st.DeleteProviderConfig("epel"); err != nil {
+		t.Fatal(err)
+	}
+	if _, err := m.RollbackProviderConfig("epel", 2, "carol", ""); err != nil {
+		t.Fatalf("RollbackProviderConfig of deleted provider failed: %v", err)
+	}
+	if got, err := st.GetProviderConfig("epel"); err != nil || got.Enabled {
+		t.Errorf("recreated provider = %+v, %v; want revision 2 (disabled)", got, err)
+	}
+
+	if _, err := m.RollbackProviderConfig("epel", 9, "carol", ""); err == nil {
+		t.Error("RollbackProviderConfig to a missing revision should fail")
+	}
+}
@@ -0,0 +1,215 @@
+package engine
+
+import (
+	"errors"
+	"fmt"
+	"os"
+	"path/filepath"
+	"strings"
+
+	"github.com/BadgerOps/airgap/internal/config"
+	"github.com/BadgerOps/airgap/internal/provider/containerimages"
+	"github.com/BadgerOps/airgap/internal/provider/epel"
+	"github.com/BadgerOps/airgap/internal/safety"
+	"github.com/BadgerOps/airgap/internal/store"
+)
+
+// Search entry types.
+const (
+	SearchTypeRPM       = "rpm"
+	SearchTypeImage     = "image"
+	SearchTypeOCPBinary = "ocp_binary"
+	SearchTypeOCPClient = "ocp_client"
+	SearchTypeRHCOS     = "rhcos"
+)
+
+// SearchResult is one item of mirrored inventory matching a search.
+type SearchResult struct {
+	Provider string `json:"provider"`
+	Type     string `json:"type"`
+	Name     string `json:"name"`
+	Version  string `json:"version,omitempty"`
+	Arch     string `json:"arch,omitempty"`
+	Path     string `json:"path,omitempty"`
+	Digest   string `json:"digest,omitempty"`
+}
+
+// Search queries the inventory index built by RebuildSearchIndex.
+func (m *SyncManager) Search(q store.SearchQuery) ([]SearchResult, error) {
+	if m.store == nil {
+		return nil, fmt.Errorf("store not initialized")
+	}
+	entries, err := m.store.Search(q)
+	if err != nil {
+		return nil, err
+	}
+	results := make([]SearchResult, 0, len(entries))
+	for _, e := range entries {
+		results = append(results, SearchResult(e))
+	}
+	return results, nil
+}
+
+// RebuildSearchIndex re-indexes the mirrored content of provider name and
+// returns the number of entries indexed.
+func (m *SyncManager) RebuildSearchIndex(name string) (int, error) {
+	if m.store == nil {
+		return 0, fmt.Errorf("store not initialized")
+	}
+	pc, err := m.store.GetProviderConfig(name)
+	if err != nil {
+		return 0, fmt.Errorf("reading provider config %q: %w", name, err)
+	}
+	return m.indexProvider(name, pc.Type, pc.ConfigJSON)
+}
+
+// refreshSearchIndex re-indexes provider name after its content changed,
+// logging rather than returning failures.
+func (m *SyncManager) refreshSearchIndex(name, providerType, cfgJSON string) {
+	n, err := m.indexProvider(name, providerType, cfgJSON)
+	if err != nil {
+		m.logger.Warn("failed to update search index", "provider", name, "error", err)
+		return
+	}
+	m.logger.Debug("updated search index", "provider", name, "entries", n)
+}
+
+func (m *SyncManager) indexProvider(name, providerType, cfgJSON string) (int, error) {
+	entries, err := m.searchEntries(name, providerType, cfgJSON)
+	if err != nil {
+		return 0, err
+	}
+	if err := m.store.ReplaceSearchEntries(name, entries); err != nil {
+		return 0, err
+	}
+	return len(entries), nil
+}
+
+// searchEntries lists the searchable items mirrored by provider name.
+// Provider types without inventory to index return no entries.
+func (m *SyncManager) searchEntries(name, providerType, cfgJSON string) ([]store.SearchEntry, error) {
+	switch providerType {
+	case "epel":
+		return m.rpmSearchEntries(cfgJSON)
+	case "container_images", "operator_catalog":
+		return m.imageSearchEntries(name, providerType, cfgJSON)
+	case "ocp_binaries":
+		return m.artifactSearchEntries(name, SearchTypeOCPBinary)
+	case "ocp_clients":
+		return m.artifactSearchEntries(name, SearchTypeOCPClient)
+	case "rhcos":
+		return m.artifactSearchEntries(name, SearchTypeRHCOS)
+	default:
+		return nil, nil
+	}
+}
+
+// rpmSearchEntries indexes the packages in each repo's local primary
+// metadata. Repos that have not been synced yet are skipped.
+func (m *SyncManager) rpmSearchEntries(cfgJSON string) ([]store.SearchEntry, error) {
+	cfg, err := parseProviderConfigJSON[config.EPELProviderConfig](cfgJSON)
+	if err != nil {
+		return nil, fmt.Errorf("parsing epel config: %w", err)
+	}
+
+	var entries []store.SearchEntry
+	for _, repo := range cfg.Repos {
+		repoDir, err := safety.SafeJoinUnder(m.config.Server.DataDir, repo.OutputDir)
+		if err != nil {
+			return nil, fmt.Errorf("invalid repo output_dir %q: %w", repo.OutputDir, err)
+		}
+		packages, err := epel.LocalPackages(repoDir, m.logger)
+		if err != nil {
+			if errors.Is(err, os.ErrNotExist) {
+				continue
+			}
+			return nil, fmt.Errorf("repo %s: %w", repo.Name, err)
+		}
+		for _, pkg := range packages {
+			entries = append(entries, store.SearchEntry{
+				Type:    SearchTypeRPM,
+				Name:    pkg.Name,
+				Version: pkg.EVR(),
+				Arch:    pkg.Arch,
+				Path:    pkg.Location,
+			})
+		}
+	}
+	return entries, nil
+}
+
+// imageSearchEntries indexes each mirrored image reference with the digest
+// of its root manifest. Images that are not mirrored yet are skipped.
+func (m *SyncManager) imageSearchEntries(name, providerType, cfgJSON string) ([]store.SearchEntry, error) {
+	sourceRoot, err := safety.SafeJoinUnder(m.config.Server.DataDir, name)
+	if err != nil {
+		return nil, fmt.Errorf("invalid provider root: %w", err)
+	}
+	images, outputDir, err := loadSourceImages(providerType, cfgJSON, sourceRoot)
+	if err != nil {
+		return nil, err
+	}
+
+	var entries []store.SearchEntry
+	for _, raw := range images {
+		ref, err := containerimages.ParseReference(raw)
+		if err != nil {
+			continue
+		}
+		imageRoot, err := safety.SafeJoinUnder(sourceRoot, filepath.Join(outputDir, containerimages.LocalImageID(ref)))
+		if err != nil {
+			continue
+		}
+		bundle, err := loadLocalImageBundle(imageRoot, ref, true)
+		if err != nil {
+			m.logger.Debug("skipping image missing from search index", "image", raw, "error", err)
+			continue
+		}
+		entry := store.SearchEntry{
+			Type:   SearchTypeImage,
+			Name:   ref.Registry + "/" + ref.Repository,
+			Digest: bundle.RootDigest,
+		}
+		if bundle.FilteredFrom != "" {
+			entry.Digest = bundle.FilteredFrom
+		}
+		if !ref.IsDigest {
+			entry.Version = ref.Reference
+		}
+		entries = append(entries, entry)
+	}
+	return entries, nil
+}
+
+// artifactSearchEntries indexes the files recorded for an OCP binaries,
+// clients or RHCOS provider, which are stored as <version>/<name> or
+// <arch>/<version>/<name> under output_dir.
+func (m *SyncManager) artifactSearchEntries(name, entryType string) ([]store.SearchEntry, error) {
+	records, err := m.store.ListFileRecords(name)
+	if err != nil {
+		return nil, fmt.Errorf("listing file records: %w", err)
+	}
+
+	var entries []store.SearchEntry
+	for _, rec := range records {
+		parts := strings.Split(rec.Path, "/")
+		if parts[0] == "graph" || parts[0] == "streams" {
+			continue // update graphs and stream metadata
+		}
+		var arch string
+		if len(parts) == 3 {
+			arch, parts = parts[0], parts[1:]
+		}
+		if len(parts) != 2 || strings.HasPrefix(parts[1], "sha256sum.txt") {
+			continue
+		}
+		entries = append(entries, store.SearchEntry{
+			Type:    entryType,
+			Name:    parts[1],
+			Version: parts[0],
+			Arch:    arch,
+			Path:    rec.Path,
+		})
+	}
+	return entries, nil
+}
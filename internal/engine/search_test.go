@@ -0,0 +1,111 @@
+package engine
+
+import (
+	"bytes"
+	"compress/gzip"
+	"os"
+	"path/filepath"
+	"testing"
+
+	"github.com/BadgerOps/airgap/internal/provider"
+	"github.com/BadgerOps/airgap/internal/provider/containerimages"
+	"github.com/BadgerOps/airgap/internal/store"
+)
+
+func TestRebuildSearchIndex(t *testing.T) {
+	m, st := newTestSyncManager(t, provider.NewRegistry())
+	dataDir := m.config.Server.DataDir
+	t.Cleanup(func() { _ = os.RemoveAll(dataDir) })
+
+	writeFile := func(path string, data []byte) {
+		t.Helper()
+		if err := os.MkdirAll(filepath.Dir(path), 0o755); err != nil {
+			t.Fatal(err)
+		}
+		if err := os.WriteFile(path, data, 0o644); err != nil {
+			t.Fatal(err)
+		}
+	}
+
+	// EPEL repo metadata as synced to <data_dir>/epel/9
+	var primary bytes.Buffer
+	gz := gzip.NewWriter(&primary)
+	_, _ = gz.Write([]byte(`<metadata packages="2">
+<package type="rpm"><name>zsh</name><arch>x86_64</arch><version epoch="0" ver="5.8" rel="9.el9"/><location href="Packages/z/zsh-5.8-9.el9.x86_64.rpm"/></package>
+<package type="rpm"><name>git-lfs</name><arch>aarch64</arch><version epoch="2" ver="3.4.1" rel="1.el9"/><location href="Packages/g/git-lfs-3.4.1-1.el9.aarch64.rpm"/></package>
+</metadata>`))
+	_ = gz.Close()
+	repoDir := filepath.Join(dataDir, "epel", "9")
+	writeFile(filepath.Join(repoDir, "repodata", "repomd.xml"),
+		[]byte(`<repomd><data type="primary"><location href="repodata/primary.xml.gz"/></data></repomd>`))
+	writeFile(filepath.Join(repoDir, "repodata", "primary.xml.gz"), primary.Bytes())
+
+	// A mirrored image and one that was never synced
+	ref, err := containerimages.ParseReference("quay.io/openshift/origin-cli:4.17")
+	if err != nil {
+		t.Fatal(err)
+	}
+	manifest := []byte(`{"schemaVersion":2,"mediaType":"application/vnd.oci.image.manifest.v1+json","layers":[]}`)
+	algo, hash, _ := splitDigest(digestBytes(manifest))
+	writeFile(filepath.Join(dataDir, "images", "images", containerimages.LocalImageID(ref), "manifests", algo, hash+".json"), manifest)
+
+	for _, pc := range []store.ProviderConfig{
+		{Name: "epel", Type: "epel", ConfigJSON: `{"repos":[{"name":"epel-9","output_dir":"epel/9"},{"name":"epel-8","output_dir":"epel/8"}]}`},
+		{Name: "images", Type: "container_images", ConfigJSON: `{"images":["quay.io/openshift/origin-cli:4.17","quay.io/missing/image:1"],"output_dir":"images"}`},
+		{Name: "ocp", Type: "ocp_binaries", ConfigJSON: `{}`},
+	} {
+		pc.Enabled = true
+		if err := st.CreateProviderConfig(&pc); err != nil {
+			t.Fatal(err)
+		}
+	}
+	for _, path := range []string{
+		"4.17.3/openshift-client-linux.tar.gz",
+		"4.17.3/sha256sum.txt",
+		"x86_64/4.18.1/openshift-install-linux.tar.gz",
+	} {
+		if err := st.UpsertFileRecord(&store.FileRecord{Provider: "ocp", Path: path}); err != nil {
+			t.Fatal(err)
+		}
+	}
+
+	for name, want := range map[string]int{"epel": 2, "images": 1, "ocp": 2} {
+		n, err := m.RebuildSearchIndex(name)
+		if err != nil {
+			t.Fatalf("RebuildSearchIndex(%s) failed: %v", name, err)
+		}
+		if n != want {
+			t.Errorf("RebuildSearchIndex(%s) indexed %d entries, want %d", name, n, want)
+		}
+	}
+
+	results, err := m.Search(store.SearchQuery{Text: "git-lfs"})
+	if err != nil {
+		t.Fatal(err)
+	}
+	if len(results) != 1 || results[0].Version != "2:3.4.1-1.el9" || results[0].Arch != "aarch64" ||
+		results[0].Path != "Packages/g/git-lfs-3.4.1-1.el9.aarch64.rpm" {
+		t.Errorf("unexpected rpm results: %+v", results)
+	}
+
+	results, err = m.Search(store.SearchQuery{Type: SearchTypeImage})
+	if err != nil {
+		t.Fatal(err)
+	}
+	if len(results) != 1 || results[0].Name != "quay.io/openshift/origin-cli" || results[0].Version != "4.17" ||
+		results[0].Digest != digestBytes(manifest) {
+		t.Errorf("unexpected image results: %+v", results)
+	}
+
+	results, err = m.Search(store.SearchQuery{Text: "openshift", Version: "4.18"})
+	if err != nil {
+		t.Fatal(err)
+	}
+	if len(results) != 1 || results[0].Name != "openshift-install-linux.tar.gz" || results[0].Arch != "x86_64" {
+		t.Errorf("unexpected artifact results: %+v", results)
+	}
+
+	if _, err := m.RebuildSearchIndex("missing"); err == nil {
+		t.Error("RebuildSearchIndex(missing) should fail")
+	}
+}
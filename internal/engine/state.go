@@ -0,0 +1,264 @@
+package engine
+
+import (
+	"encoding/json"
+	"fmt"
+	"os"
+	"path/filepath"
+	"time"
+
+	"github.com/BadgerOps/airgap/internal/config"
+	"github.com/BadgerOps/airgap/internal/store"
+)
+
+// stateSnapshotName is the file an export writes its state snapshot to.
+const stateSnapshotName = "airgap-state.json"
+
+// stateSyncRunLimit caps the recent sync runs included per provider, in
+// addition to the runs that fetched exported files.
+const stateSyncRunLimit = 50
+
+// StateSnapshot is the sanitized high-side state shipped in a transfer: the
+// provider configs and sync history behind the exported files.
+type StateSnapshot struct {
+	Version    string                   `json:"version"`
+	Created    time.Time                `json:"created"`
+	SourceHost string                   `json:"source_host"`
+	Providers  map[string]StateProvider `json:"providers"`
+}
+
+// StateProvider is one provider's config and history in a StateSnapshot.
+type StateProvider struct {
+	Type     string                 `json:"type,omitempty"`
+	Config   map[string]interface{} `json:"config,omitempty"`
+	SyncRuns []StateSyncRun         `json:"sync_runs"`
+	Files    []StateFile            `json:"files"`
+}
+
+// StateSyncRun is a high-side sync run.
+type StateSyncRun struct {
+	ID               int64     `json:"id"`
+	StartTime        time.Time `json:"start_time"`
+	EndTime          time.Time `json:"end_time"`
+	Status           string    `json:"status"`
+	FilesDownloaded  int       `json:"files_downloaded"`
+	FilesDeleted     int       `json:"files_deleted"`
+	FilesSkipped     int       `json:"files_skipped"`
+	FilesFailed      int       `json:"files_failed"`
+	BytesTransferred int64     `json:"bytes_transferred"`
+	ErrorMessage     string    `json:"error_message,omitempty"`
+	SignatureStatus  string    `json:"signature_status,omitempty"`
+}
+
+// StateFile records which sync run fetched an exported file, and when.
+type StateFile struct {
+	Path      string    `json:"path"`
+	SyncRunID int64     `json:"sync_run_id"`
+	FetchedAt time.Time `json:"fetched_at"`
+}
+
+// ManifestState points a manifest at its state snapshot.
+type ManifestState struct {
+	Name   string `json:"name"`
+	SHA256 string `json:"sha256"`
+}
+
+// sanitizeConfig returns a deep copy of cfg without credential fields.
+func sanitizeConfig(cfg map[string]interface{}) map[string]interface{} {
+	out := make(map[string]interface{}, len(cfg))
+	for k, v := range cfg {
+		if config.IsSensitiveKey(k) {
+			continue
+		}
+		out[k] = sanitizeValue(v)
+	}
+	return out
+}
+
+func sanitizeValue(v interface{}) interface{} {
+	switch val := v.(type) {
+	case map[string]interface{}:
+		return sanitizeConfig(val)
+	case map[interface{}]interface{}:
+		m := make(map[string]interface{}, len(val))
+		for k, item := range val {
+			m[fmt.Sprint(k)] = item
+		}
+		return sanitizeConfig(m)
+	case []interface{}:
+		out := make([]interface{}, len(val))
+		for i, item := range val {
+			out[i] = sanitizeValue(item)
+		}
+		return out
+	default:
+		return v
+	}
+}
+
+// buildStateSnapshot collects the sanitized config and sync history of each
+// exported provider. files maps providers to their exported file records.
+func (m *SyncManager) buildStateSnapshot(files map[string][]store.FileRecord, hostname string) *StateSnapshot {
+	snap := &StateSnapshot{
+		Version:    "1.0",
+		Created:    time.Now().UTC(),
+		SourceHost: hostname,
+		Providers:  make(map[string]StateProvider),
+	}
+
+	for provName, records := range files {
+		sp := StateProvider{SyncRuns: []StateSyncRun{}, Files: []StateFile{}}
+		if p, ok := m.registry.Get(provName); ok {
+			sp.Type = p.Type()
+		}
+		if cfg, ok := m.config.Providers[provName]; ok {
+			sp.Config = sanitizeConfig(cfg)
+		}
+
+		seen := make(map[int64]bool)
+		addRun := func(run store.SyncRun) {
+			if seen[run.ID] || run.SourceHost != "" {
+				return
+			}
+			seen[run.ID] = true
+			sp.SyncRuns = append(sp.SyncRuns, StateSyncRun{
+				ID:               run.ID,
+				StartTime:        run.StartTime,
+				EndTime:          run.EndTime,
+				Status:           run.Status,
+				FilesDownloaded:  run.FilesDownloaded,
+				FilesDeleted:     run.FilesDeleted,
+				FilesSkipped:     run.FilesSkipped,
+				FilesFailed:      run.FilesFailed,
+				BytesTransferred: run.BytesTransferred,
+				ErrorMessage:     run.ErrorMessage,
+				SignatureStatus:  run.SignatureStatus,
+			})
+		}
+
+		if runs, err := m.store.ListSyncRuns(provName, stateSyncRunLimit); err == nil {
+			for _, run := range runs {
+				addRun(run)
+			}
+		} else {
+			m.logger.Warn("failed to list sync runs for state snapshot", "provider", provName, "error", err)
+		}
+
+		for _, rec := range records {
+			if rec.SyncRunID != 0 && !seen[rec.SyncRunID] {
+				if run, err := m.store.GetSyncRun(rec.SyncRunID); err == nil {
+					addRun(*run)
+				}
+			}
+			sp.Files = append(sp.Files, StateFile{
+				Path:      rec.Path,
+				SyncRunID: rec.SyncRunID,
+				FetchedAt: rec.LastModified,
+			})
+		}
+		snap.Providers[provName] = sp
+	}
+	return snap
+}
+
+// writeStateSnapshot writes snap to dir and returns its manifest entry.
+func writeStateSnapshot(dir string, snap *StateSnapshot) (*ManifestState, error) {
+	data, err := json.MarshalIndent(snap, "", "  ")
+	if err != nil {
+		return nil, fmt.Errorf("marshaling state snapshot: %w", err)
+	}
+	path := filepath.Join(dir, stateSnapshotName)
+	if err := os.WriteFile(path, data, 0o644); err != nil {
+		return nil, fmt.Errorf("writing state snapshot: %w", err)
+	}
+	hash, _, err := hashFile(path)
+	if err != nil {
+		return nil, fmt.Errorf("hashing state snapshot: %w", err)
+	}
+	return &ManifestState{Name: stateSnapshotName, SHA256: hash}, nil
+}
+
+// readStateSnapshot reads and verifies the snapshot a manifest points at.
+func readStateSnapshot(dir string, ref *ManifestState) (*StateSnapshot, error) {
+	if ref.Name != stateSnapshotName {
+		return nil, fmt.Errorf("unexpected state snapshot name %q", ref.Name)
+	}
+	path := filepath.Join(dir, ref.Name)
+	hash, _, err := hashFile(path)
+	if err != nil {
+		return nil, fmt.Errorf("hashing state snapshot: %w", err)
+	}
+	if hash != ref.SHA256 {
+		return nil, fmt.Errorf("state snapshot checksum mismatch: expected %s, got %s", ref.SHA256, hash)
+	}
+	data, err := os.ReadFile(path)
+	if err != nil {
+		return nil, fmt.Errorf("reading state snapshot: %w", err)
+	}
+	var snap StateSnapshot
+	if err := json.Unmarshal(data, &snap); err != nil {
+		return nil, fmt.Errorf("parsing state snapshot: %w", err)
+	}
+	return &snap, nil
+}
+
+// importStateSnapshot records the snapshot's sync runs and provider configs
+// and returns, per provider and path, the local ID of the sync run that
+// fetched each file and when.
+func (m *SyncManager) importStateSnapshot(snap *StateSnapshot) (map[string]map[string]StateFile, int) {
+	files := make(map[string]map[string]StateFile)
+	imported := 0
+	if snap.SourceHost == "" {
+		m.logger.Warn("state snapshot has no source host, ignoring it")
+		return files, 0
+	}
+
+	for provName, sp := range snap.Providers {
+		cfgJSON, err := json.Marshal(sp.Config)
+		if err != nil {
+			cfgJSON = []byte("{}")
+		}
+		if err := m.store.UpsertUpstreamProviderConfig(&store.UpstreamProviderConfig{
+			SourceHost: snap.SourceHost,
+			Name:       provName,
+			Type:       sp.Type,
+			ConfigJSON: string(cfgJSON),
+			ImportedAt: time.Now(),
+		}); err != nil {
+			m.logger.Warn("failed to record upstream provider config", "provider", provName, "error", err)
+		}
+
+		localIDs := make(map[int64]int64)
+		for _, r := range sp.SyncRuns {
+			run := &store.SyncRun{
+				Provider:         provName,
+				StartTime:        r.StartTime,
+				EndTime:          r.EndTime,
+				FilesDownloaded:  r.FilesDownloaded,
+				FilesDeleted:     r.FilesDeleted,
+				FilesSkipped:     r.FilesSkipped,
+				FilesFailed:      r.FilesFailed,
+				BytesTransferred: r.BytesTransferred,
+				Status:           r.Status,
+				ErrorMessage:     r.ErrorMessage,
+				SignatureStatus:  r.SignatureStatus,
+				SourceHost:       snap.SourceHost,
+				UpstreamID:       r.ID,
+			}
+			if err := m.store.ImportSyncRun(run); err != nil {
+				m.logger.Warn("failed to import upstream sync run", "provider", provName, "id", r.ID, "error", err)
+				continue
+			}
+			localIDs[r.ID] = run.ID
+			imported++
+		}
+
+		byPath := make(map[string]StateFile, len(sp.Files))
+		for _, f := range sp.Files {
+			f.SyncRunID = localIDs[f.SyncRunID]
+			byPath[f.Path] = f
+		}
+		files[provName] = byPath
+	}
+	return files, imported
+}
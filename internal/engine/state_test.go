@@ -0,0 +1,180 @@
+package engine
+
+import (
+	"context"
+	"encoding/json"
+	"io"
+	"log/slog"
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/BadgerOps/airgap/internal/config"
+	"github.com/BadgerOps/airgap/internal/download"
+	"github.com/BadgerOps/airgap/internal/provider"
+	"github.com/BadgerOps/airgap/internal/store"
+)
+
+// exportWithState exports the setupExportTest content with a state snapshot
+// in which epel/9/Packages/foo.rpm was fetched by a recorded sync run.
+func exportWithState(t *testing.T) (*SyncManager, string, *store.SyncRun, time.Time) {
+	t.Helper()
+	mgr, _, outputDir := setupExportTest(t)
+
+	run := &store.SyncRun{Provider: "epel", StartTime: time.Now().Add(-time.Hour), EndTime: time.Now(), Status: "success", FilesDownloaded: 1}
+	if err := mgr.store.CreateSyncRun(run); err != nil {
+		t.Fatal(err)
+	}
+	rec, err := mgr.store.GetFileRecord("epel", "9/Packages/foo.rpm")
+	if err != nil {
+		t.Fatal(err)
+	}
+	fetched := time.Date(2026, 3, 1, 12, 0, 0, 0, time.UTC)
+	rec.SyncRunID = run.ID
+	rec.LastModified = fetched
+	if err := mgr.store.UpsertFileRecord(rec); err != nil {
+		t.Fatal(err)
+	}
+
+	mgr.config.Providers = map[string]config.ProviderConfig{
+		"epel": {
+			"enabled":  true,
+			"base_url": "https://mirror.example.com/epel/9/",
+			"password": "hunter2",
+			"repos": []interface{}{
+				map[string]interface{}{"name": "epel-9", "auth_token": "s3cret"},
+			},
+		},
+	}
+
+	report, err := mgr.Export(context.Background(), ExportOptions{
+		OutputDir:    outputDir,
+		Providers:    []string{"epel", "ocp_binaries"},
+		SplitSize:    1024 * 1024 * 1024,
+		Compression:  "zstd",
+		IncludeState: true,
+	})
+	if err != nil {
+		t.Fatalf("Export() error: %v", err)
+	}
+	if report.StatePath != filepath.Join(outputDir, stateSnapshotName) {
+		t.Fatalf("StatePath = %q", report.StatePath)
+	}
+	return mgr, outputDir, run, fetched
+}
+
+func TestExportIncludesSanitizedState(t *testing.T) {
+	_, outputDir, run, _ := exportWithState(t)
+
+	data, err := os.ReadFile(filepath.Join(outputDir, stateSnapshotName))
+	if err != nil {
+		t.Fatalf("read state snapshot: %v", err)
+	}
+	for _, secret := range []string{"hunter2", "s3cret", "password", "auth_token"} {
+		if strings.Contains(string(data), secret) {
+			t.Errorf("state snapshot contains %q", secret)
+		}
+	}
+
+	var snap StateSnapshot
+	if err := json.Unmarshal(data, &snap); err != nil {
+		t.Fatalf("unmarshal state snapshot: %v", err)
+	}
+	epel := snap.Providers["epel"]
+	if epel.Config["base_url"] != "https://mirror.example.com/epel/9/" {
+		t.Errorf("epel config = %v, want base_url kept", epel.Config)
+	}
+	if len(epel.SyncRuns) != 1 || epel.SyncRuns[0].ID != run.ID {
+		t.Errorf("epel sync runs = %+v, want run %d", epel.SyncRuns, run.ID)
+	}
+	if len(epel.Files) != 2 {
+		t.Errorf("epel files = %d, want 2", len(epel.Files))
+	}
+
+	manifestData, err := os.ReadFile(filepath.Join(outputDir, "airgap-manifest.json"))
+	if err != nil {
+		t.Fatal(err)
+	}
+	var manifest TransferManifest
+	if err := json.Unmarshal(manifestData, &manifest); err != nil {
+		t.Fatal(err)
+	}
+	if manifest.State == nil || manifest.State.Name != stateSnapshotName || manifest.State.SHA256 == "" {
+		t.Errorf("manifest state = %+v", manifest.State)
+	}
+}
+
+func TestImportRecordsUpstreamState(t *testing.T) {
+	_, outputDir, run, fetched := exportWithState(t)
+
+	// Import into a separate low-side store
+	logger := slog.New(slog.NewTextHandler(io.Discard, nil))
+	low, err := store.New(filepath.Join(t.TempDir(), "low.db"), logger)
+	if err != nil {
+		t.Fatal(err)
+	}
+	t.Cleanup(func() { _ = low.Close() })
+	lowCfg := &config.Config{Server: config.ServerConfig{DataDir: t.TempDir()}}
+	lowMgr := NewSyncManager(provider.NewRegistry(), low, download.NewClient(logger), lowCfg, logger)
+
+	report, err := lowMgr.Import(context.Background(), ImportOptions{SourceDir: outputDir})
+	if err != nil {
+		t.Fatalf("Import() error: %v", err)
+	}
+	hostname, _ := os.Hostname()
+	if report.SourceHost != hostname || report.UpstreamSyncRuns != 1 {
+		t.Errorf("report upstream = %q/%d, want %q/1", report.SourceHost, report.UpstreamSyncRuns, hostname)
+	}
+
+	rec, err := low.GetFileRecord("epel", "9/Packages/foo.rpm")
+	if err != nil {
+		t.Fatal(err)
+	}
+	if !rec.LastModified.Equal(fetched) {
+		t.Errorf("LastModified = %v, want upstream fetch time %v", rec.LastModified, fetched)
+	}
+	upstream, err := low.GetSyncRun(rec.SyncRunID)
+	if err != nil {
+		t.Fatalf("GetSyncRun(%d) error: %v", rec.SyncRunID, err)
+	}
+	if upstream.SourceHost != hostname || upstream.UpstreamID != run.ID || upstream.Status != "success" {
+		t.Errorf("upstream run = %+v", upstream)
+	}
+
+	cfg, err := low.GetUpstreamProviderConfig(hostname, "epel")
+	if err != nil {
+		t.Fatal(err)
+	}
+	if !strings.Contains(cfg.ConfigJSON, "mirror.example.com") || strings.Contains(cfg.ConfigJSON, "hunter2") {
+		t.Errorf("upstream config = %s", cfg.ConfigJSON)
+	}
+
+	// Importing the same transfer again does not duplicate the history
+	if _, err := lowMgr.Import(context.Background(), ImportOptions{SourceDir: outputDir}); err != nil {
+		t.Fatalf("second Import() error: %v", err)
+	}
+	runs, err := low.ListSyncRuns("epel", 0)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if len(runs) != 1 {
+		t.Errorf("sync runs after re-import = %d, want 1", len(runs))
+	}
+}
+
+func TestImportRejectsTamperedState(t *testing.T) {
+	mgr, outputDir, _, _ := exportWithState(t)
+
+	statePath := filepath.Join(outputDir, stateSnapshotName)
+	if err := os.WriteFile(statePath, []byte(`{"source_host":"evil"}`), 0o644); err != nil {
+		t.Fatal(err)
+	}
+
+	mgr.config.Server.DataDir = t.TempDir()
+	_, err := mgr.Import(context.Background(), ImportOptions{SourceDir: outputDir})
+	if err == nil || !strings.Contains(err.Error(), "state snapshot checksum mismatch") {
+		t.Fatalf("Import() error = %v, want state checksum mismatch", err)
+	}
+}
@@ -10,6 +10,8 @@ import (
 	"log/slog"
 	"os"
 	"path/filepath"
+	"sort"
+	"strings"
 	"sync"
 	"time"
 
@@ -26,7 +28,7 @@ type ProviderFactory func(typeName, dataDir string, logger *slog.Logger) (provid
 // SyncManager orchestrates providers and connects them to the download client and store.
 type SyncManager struct {
 	registry        *provider.Registry
-	store           *store.Store
+	store           store.Store
 	client          *download.Client
 	config          *config.Config
 	logger          *slog.Logger
@@ -41,19 +43,19 @@ type SyncManager struct {
 
 // ProviderStatus summarizes a provider's state.
 type ProviderStatus struct {
-	Name        string
-	Enabled     bool
-	FileCount   int
-	TotalSize   int64
-	LastSync    time.Time
-	LastStatus  string
-	FailedFiles int
+	Name        string    `json:"name"`
+	Enabled     bool      `json:"enabled"`
+	FileCount   int       `json:"file_count"`
+	TotalSize   int64     `json:"total_size"`
+	LastSync    time.Time `json:"last_sync"`
+	LastStatus  string    `json:"last_status"`
+	FailedFiles int       `json:"failed_files"`
 }
 
 // NewSyncManager creates a new SyncManager.
 func NewSyncManager(
 	registry *provider.Registry,
-	st *store.Store,
+	st store.Store,
 	client *download.Client,
 	cfg *config.Config,
 	logger *slog.Logger,
@@ -153,6 +155,18 @@ func (m *SyncManager) SyncProvider(ctx context.Context, name string, opts provid
 	}
 
 	m.logger.Info("sync plan generated", "provider", name, "actions", len(plan.Actions), "total_size", plan.TotalSize)
+	syncRun.SignatureStatus = plan.SignatureStatus
+	if plan.SignatureStatus != "" {
+		m.logger.Info("upstream checksum signatures", "provider", name, "status", plan.SignatureStatus)
+	}
+	exprs := make([]string, 0, len(plan.Resolved))
+	for expr := range plan.Resolved {
+		exprs = append(exprs, expr)
+	}
+	sort.Strings(exprs)
+	for _, expr := range exprs {
+		m.logger.Info("versions resolved", "provider", name, "expression", expr, "versions", strings.Join(plan.Resolved[expr], ","))
+	}
 
 	// Update tracker with plan totals
 	downloadCount := 0
@@ -165,6 +179,14 @@ func (m *SyncManager) SyncProvider(ctx context.Context, name string, opts provid
 			skipCount++
 		}
 	}
+	var planFailures []provider.FailedFile
+	if pf, ok := p.(provider.PlanFailureReporter); ok {
+		planFailures = pf.PlanFailures()
+		for _, f := range planFailures {
+			m.logger.Warn("content rejected while planning", "provider", name, "path", f.Path, "error", f.Error)
+		}
+	}
+
 	tracker.SetTotals(len(plan.Actions), plan.TotalSize)
 	if skipCount > 0 {
 		tracker.SetSkippedFiles(skipCount)
@@ -179,7 +201,7 @@ func (m *SyncManager) SyncProvider(ctx context.Context, name string, opts provid
 		m.logger.Info("dry run mode: not executing sync", "provider", name, "actions", len(plan.Actions))
 		syncRun.Status = "completed"
 		syncRun.EndTime = time.Now()
-		syncRun.FilesFailed = 0
+		syncRun.FilesFailed = len(planFailures)
 		for _, action := range plan.Actions {
 			switch action.Action {
 			case provider.ActionDownload, provider.ActionUpdate:
@@ -199,28 +221,149 @@ func (m *SyncManager) SyncProvider(ctx context.Context, name string, opts provid
 			Downloaded:       syncRun.FilesDownloaded,
 			Deleted:          syncRun.FilesDeleted,
 			Skipped:          syncRun.FilesSkipped,
-			Failed:           []provider.FailedFile{},
+			Failed:           append([]provider.FailedFile{}, planFailures...),
 			BytesTransferred: 0,
 		}, nil
 	}
 
-	// Build download.Job slice from the plan's Download/Update actions
-	var downloadJobs []download.Job
 	providerRoot, err := safety.SafeJoinUnder(m.config.Server.DataDir, name)
 	if err != nil {
 		return nil, fmt.Errorf("invalid provider root for %q: %w", name, err)
 	}
+
+	// Determine worker count
+	workers := 4
+	if opts.MaxWorkers > 0 {
+		workers = opts.MaxWorkers
+	}
+
+	res := &syncResult{failedFiles: []provider.FailedFile{}}
+
+	// Content rejected while planning counts as failed
+	for _, f := range planFailures {
+		res.failed++
+		res.failedFiles = append(res.failedFiles, f)
+		failedRec := &store.FailedFileRecord{
+			Provider:     name,
+			FilePath:     f.Path,
+			URL:          f.URL,
+			Error:        f.Error,
+			FirstFailure: time.Now(),
+			LastFailure:  time.Now(),
+		}
+		if err := m.store.AddFailedFile(failedRec); err != nil {
+			m.logger.Error("failed to add failed file record", "provider", name, "path", f.Path, "error", err)
+		}
+	}
+
+	if err := m.executeActions(ctx, name, providerRoot, syncRun.ID, plan.Actions, workers, tracker, res); err != nil {
+		return nil, err
+	}
+
+	// Let the provider build content from what was downloaded and fetch
+	// whatever that content references.
+	if ps, ok := p.(provider.PostSyncer); ok {
+		tracker.SetMessage("Processing downloaded content for " + name)
+		followUp, err := ps.PostSync(ctx)
+		if err != nil {
+			res.failed++
+			res.failedFiles = append(res.failedFiles, provider.FailedFile{Path: name, Error: err.Error()})
+			m.logger.Warn("post-sync processing failed", "provider", name, "error", err)
+		}
+		if followUp != nil && len(followUp.Actions) > 0 {
+			m.logger.Info("follow-up plan generated", "provider", name, "actions", len(followUp.Actions), "total_size", followUp.TotalSize)
+			tracker.SetTotals(len(plan.Actions)+len(followUp.Actions), plan.TotalSize+followUp.TotalSize)
+			if err := m.executeActions(ctx, name, providerRoot, syncRun.ID, followUp.Actions, workers, tracker, res); err != nil {
+				return nil, err
+			}
+		}
+	}
+
+	m.recordGeneratedFiles(name, p, providerRoot, syncRun.ID, res)
+
+	// Set final tracker phase
+	if res.failed > 0 {
+		tracker.SetPhase(PhaseFailed)
+		tracker.SetMessage(fmt.Sprintf("Completed with %d failures", res.failed))
+	} else {
+		tracker.SetPhase(PhaseComplete)
+		tracker.SetMessage(fmt.Sprintf("Sync complete: %d files downloaded", res.downloaded))
+	}
+
+	// Update the SyncRun record with final status
+	syncRun.FilesDownloaded = res.downloaded
+	syncRun.FilesDeleted = res.deleted
+	syncRun.FilesSkipped = res.skipped
+	syncRun.FilesFailed = res.failed
+	syncRun.BytesTransferred = res.bytes
+	syncRun.EndTime = time.Now()
+
+	if res.failed > 0 {
+		syncRun.Status = "partial"
+	} else {
+		syncRun.Status = "success"
+	}
+
+	if err := m.store.UpdateSyncRun(syncRun); err != nil {
+		m.logger.Error("failed to update sync run record", "provider", name, "error", err)
+	}
+
+	if pc, err := m.store.GetProviderConfig(name); err == nil {
+		m.refreshSearchIndex(name, pc.Type, pc.ConfigJSON)
+	}
+
+	// Build and return SyncReport
+	report := &provider.SyncReport{
+		Provider:         name,
+		StartTime:        startTime,
+		EndTime:          time.Now(),
+		Downloaded:       res.downloaded,
+		Deleted:          res.deleted,
+		Skipped:          res.skipped,
+		Failed:           res.failedFiles,
+		BytesTransferred: res.bytes,
+	}
+
+	m.logger.Info("sync completed",
+		"provider", name,
+		"downloaded", res.downloaded,
+		"deleted", res.deleted,
+		"skipped", res.skipped,
+		"failed", res.failed,
+		"bytes_transferred", res.bytes,
+		"duration", report.EndTime.Sub(report.StartTime),
+	)
+
+	return report, nil
+}
+
+// syncResult accumulates the outcome of the actions executed by one sync.
+type syncResult struct {
+	downloaded  int
+	deleted     int
+	skipped     int
+	failed      int
+	bytes       int64
+	failedFiles []provider.FailedFile
+}
+
+// executeActions downloads, deletes and skips the files of actions and
+// records the outcome in the store and in res.
+func (m *SyncManager) executeActions(ctx context.Context, name, providerRoot string, syncRunID int64, actions []provider.SyncAction, workers int, tracker *SyncTracker, res *syncResult) error {
 	resolveActionDestPath := func(action provider.SyncAction) (string, error) {
 		if action.LocalPath == "" {
 			return safety.SafeJoinUnder(providerRoot, action.Path)
 		}
 		return safety.EnsureUnderRoot(providerRoot, action.LocalPath)
 	}
-	for _, action := range plan.Actions {
+
+	// Build download.Job slice from the Download/Update actions
+	var downloadJobs []download.Job
+	for _, action := range actions {
 		if action.Action == provider.ActionDownload || action.Action == provider.ActionUpdate {
 			destPath, err := resolveActionDestPath(action)
 			if err != nil {
-				return nil, fmt.Errorf("unsafe download path for %q: %w", action.Path, err)
+				return fmt.Errorf("unsafe download path for %q: %w", action.Path, err)
 			}
 			downloadJobs = append(downloadJobs, download.Job{
 				URL:              action.URL,
@@ -228,16 +371,11 @@ func (m *SyncManager) SyncProvider(ctx context.Context, name string, opts provid
 				ExpectedChecksum: action.Checksum,
 				ExpectedSize:     action.Size,
 				Headers:          action.Headers,
+				Authorize:        action.Authorize,
 			})
 		}
 	}
 
-	// Determine worker count
-	workers := 4
-	if opts.MaxWorkers > 0 {
-		workers = opts.MaxWorkers
-	}
-
 	// Execute the download pool
 	var downloadResults []download.Result
 	if len(downloadJobs) > 0 {
@@ -255,13 +393,6 @@ func (m *SyncManager) SyncProvider(ctx context.Context, name string, opts provid
 		downloadResults = pool.Execute(ctx, downloadJobs)
 	}
 
-	// Track results
-	downloadedCount := 0
-	failedCount := 0
-	skippedCount := 0
-	totalBytesTransferred := int64(0)
-	failedFiles := []provider.FailedFile{}
-
 	// Create a map of download results for quick lookup.
 	// Key by both DestPath and the action Path so lookups work regardless
 	// of whether LocalPath or Path was used as the job DestPath.
@@ -271,14 +402,14 @@ func (m *SyncManager) SyncProvider(ctx context.Context, name string, opts provid
 	}
 
 	// Process results: upsert successful downloads, track failed ones
-	for _, action := range plan.Actions {
+	for _, action := range actions {
 		switch action.Action {
 		case provider.ActionDownload, provider.ActionUpdate:
 			destPath, err := resolveActionDestPath(action)
 			if err != nil {
-				failedCount++
+				res.failed++
 				errMsg := fmt.Sprintf("unsafe resolved destination path: %v", err)
-				failedFiles = append(failedFiles, provider.FailedFile{
+				res.failedFiles = append(res.failedFiles, provider.FailedFile{
 					Path:     action.Path,
 					URL:      action.URL,
 					Error:    errMsg,
@@ -289,28 +420,33 @@ func (m *SyncManager) SyncProvider(ctx context.Context, name string, opts provid
 			}
 			result, ok := downloadResultMap[destPath]
 			if ok && result.Success {
-				downloadedCount++
-				totalBytesTransferred += result.Download.Size
+				res.downloaded++
+				res.bytes += result.Download.Size
 				// Note: tracker.FileCompleted already called by pool.OnComplete
 
 				// Upsert FileRecord in the store
 				fileRec := &store.FileRecord{
-					Provider:     name,
-					Path:         action.Path,
-					Size:         result.Download.Size,
-					SHA256:       result.Download.SHA256,
-					LastModified: time.Now(),
-					LastVerified: time.Now(),
-					SyncRunID:    syncRun.ID,
+					Provider:             name,
+					Path:                 action.Path,
+					Size:                 result.Download.Size,
+					SHA256:               result.Download.SHA256,
+					LastModified:         time.Now(),
+					LastVerified:         time.Now(),
+					SyncRunID:            syncRunID,
+					SourceURL:            action.URL,
+					MirrorHost:           urlHost(result.Download.URL),
+					ETag:                 result.Download.ETag,
+					UpstreamLastModified: result.Download.LastModified,
+					ResponseTimeMS:       result.Download.ResponseTime.Milliseconds(),
 				}
 
 				if err := m.store.UpsertFileRecord(fileRec); err != nil {
 					m.logger.Error("failed to upsert file record", "provider", name, "path", action.Path, "error", err)
 				}
 			} else if ok && !result.Success {
-				failedCount++
+				res.failed++
 				// Note: tracker.FileFailed already called by pool.OnComplete
-				failedFiles = append(failedFiles, provider.FailedFile{
+				res.failedFiles = append(res.failedFiles, provider.FailedFile{
 					Path:     action.Path,
 					URL:      action.URL,
 					Error:    result.Error.Error(),
@@ -338,9 +474,9 @@ func (m *SyncManager) SyncProvider(ctx context.Context, name string, opts provid
 
 				m.logger.Warn("download failed", "provider", name, "path", action.Path, "url", action.URL, "error", result.Error)
 			} else {
-				failedCount++
+				res.failed++
 				errMsg := "missing download result"
-				failedFiles = append(failedFiles, provider.FailedFile{
+				res.failedFiles = append(res.failedFiles, provider.FailedFile{
 					Path:     action.Path,
 					URL:      action.URL,
 					Error:    errMsg,
@@ -350,8 +486,10 @@ func (m *SyncManager) SyncProvider(ctx context.Context, name string, opts provid
 			}
 
 		case provider.ActionDelete:
+			res.deleted++
 			// Remove local file and delete FileRecord from store
 			filePath := action.LocalPath
+			var err error
 			if filePath == "" {
 				filePath, err = safety.SafeJoinUnder(providerRoot, action.Path)
 				if err != nil {
@@ -374,66 +512,69 @@ func (m *SyncManager) SyncProvider(ctx context.Context, name string, opts provid
 			}
 
 		case provider.ActionSkip:
-			skippedCount++
+			res.skipped++
 			// Skips already counted in bulk during plan phase via SetSkippedFiles
 		}
 	}
+	return nil
+}
 
-	// Set final tracker phase
-	if failedCount > 0 {
-		tracker.SetPhase(PhaseFailed)
-		tracker.SetMessage(fmt.Sprintf("Completed with %d failures", failedCount))
-	} else {
-		tracker.SetPhase(PhaseComplete)
-		tracker.SetMessage(fmt.Sprintf("Sync complete: %d files downloaded", downloadedCount))
+// recordGeneratedFiles writes the files a provider built during the sync and
+// records them so they are exported.
+func (m *SyncManager) recordGeneratedFiles(name string, p provider.Provider, providerRoot string, syncRunID int64, res *syncResult) {
+	gen, ok := p.(provider.FileGenerator)
+	if !ok {
+		return
 	}
-
-	// Update the SyncRun record with final status
-	syncRun.FilesDownloaded = downloadedCount
-	syncRun.FilesDeleted = 0
-	for _, action := range plan.Actions {
-		if action.Action == provider.ActionDelete {
-			syncRun.FilesDeleted++
+	for _, f := range gen.GeneratedFiles() {
+		sum, err := writeGeneratedFile(providerRoot, f)
+		if err != nil {
+			res.failed++
+			res.failedFiles = append(res.failedFiles, provider.FailedFile{Path: f.Path, Error: err.Error()})
+			m.logger.Warn("failed to write generated file", "provider", name, "path", f.Path, "error", err)
+			continue
+		}
+		fileRec := &store.FileRecord{
+			Provider:     name,
+			Path:         f.Path,
+			Size:         int64(len(f.Data)),
+			SHA256:       sum,
+			LastModified: time.Now(),
+			LastVerified: time.Now(),
+			SyncRunID:    syncRunID,
+		}
+		if err := m.store.UpsertFileRecord(fileRec); err != nil {
+			m.logger.Error("failed to upsert generated file record", "provider", name, "path", f.Path, "error", err)
 		}
 	}
-	syncRun.FilesSkipped = skippedCount
-	syncRun.FilesFailed = failedCount
-	syncRun.BytesTransferred = totalBytesTransferred
-	syncRun.EndTime = time.Now()
+}
 
-	if failedCount > 0 {
-		syncRun.Status = "partial"
+// writeGeneratedFile replaces the file of f under providerRoot and returns
+// its SHA-256.
+func writeGeneratedFile(providerRoot string, f provider.GeneratedFile) (string, error) {
+	var dest string
+	var err error
+	if f.LocalPath == "" {
+		dest, err = safety.SafeJoinUnder(providerRoot, f.Path)
 	} else {
-		syncRun.Status = "success"
+		dest, err = safety.EnsureUnderRoot(providerRoot, f.LocalPath)
 	}
-
-	if err := m.store.UpdateSyncRun(syncRun); err != nil {
-		m.logger.Error("failed to update sync run record", "provider", name, "error", err)
+	if err != nil {
+		return "", fmt.Errorf("unsafe generated file path: %w", err)
 	}
-
-	// Build and return SyncReport
-	report := &provider.SyncReport{
-		Provider:         name,
-		StartTime:        startTime,
-		EndTime:          time.Now(),
-		Downloaded:       downloadedCount,
-		Deleted:          syncRun.FilesDeleted,
-		Skipped:          skippedCount,
-		Failed:           failedFiles,
-		BytesTransferred: totalBytesTransferred,
+	if err := os.MkdirAll(filepath.Dir(dest), 0o755); err != nil {
+		return "", fmt.Errorf("creating directory: %w", err)
 	}
-
-	m.logger.Info("sync completed",
-		"provider", name,
-		"downloaded", downloadedCount,
-		"deleted", syncRun.FilesDeleted,
-		"skipped", skippedCount,
-		"failed", failedCount,
-		"bytes_transferred", totalBytesTransferred,
-		"duration", report.EndTime.Sub(report.StartTime),
-	)
-
-	return report, nil
+	tmp := dest + ".partial"
+	if err := os.WriteFile(tmp, f.Data, 0o644); err != nil {
+		return "", fmt.Errorf("writing file: %w", err)
+	}
+	if err := os.Rename(tmp, dest); err != nil {
+		_ = os.Remove(tmp)
+		return "", fmt.Errorf("replacing file: %w", err)
+	}
+	sum := sha256.Sum256(f.Data)
+	return hex.EncodeToString(sum[:]), nil
 }
 
 // SyncAll synchronizes all enabled providers.
@@ -750,15 +891,6 @@ func (m *SyncManager) ScanLocal(ctx context.Context, providerName string) (*Scan
 
 		report.Found++
 
-		// Determine if new or updated
-		if existing, ok := existingMap[relPath]; ok {
-			if existing.SHA256 != checksum || existing.Size != info.Size() {
-				report.Updated++
-			}
-		} else {
-			report.New++
-		}
-
 		// Upsert file record
 		rec := &store.FileRecord{
 			Provider:     providerName,
@@ -768,6 +900,18 @@ func (m *SyncManager) ScanLocal(ctx context.Context, providerName string) (*Scan
 			LastModified: info.ModTime(),
 			LastVerified: time.Now(),
 		}
+
+		// Determine if new or updated; unchanged files keep their provenance
+		if existing, ok := existingMap[relPath]; ok {
+			if existing.SHA256 != checksum || existing.Size != info.Size() {
+				report.Updated++
+			} else {
+				rec.SyncRunID = existing.SyncRunID
+				copyProvenance(rec, existing)
+			}
+		} else {
+			report.New++
+		}
 		if err := m.store.UpsertFileRecord(rec); err != nil {
 			m.logger.Warn("failed to upsert file record", "path", relPath, "error", err)
 		}
@@ -83,7 +83,7 @@ func (m *mockProvider) Validate(ctx context.Context) (*provider.ValidationReport
 }
 
 // newTestSyncManager creates a SyncManager with an in-memory SQLite store
-func newTestSyncManager(t *testing.T, registry *provider.Registry) (*SyncManager, *store.Store) {
+func newTestSyncManager(t *testing.T, registry *provider.Registry) (*SyncManager, store.Store) {
 	st, err := store.New(":memory:", slog.Default())
 	if err != nil {
 		t.Fatalf("failed to create in-memory store: %v", err)
@@ -144,10 +144,11 @@ func TestSyncProviderDryRun(t *testing.T) {
 		name: "test-provider",
 		planFunc: func(ctx context.Context) (*provider.SyncPlan, error) {
 			return &provider.SyncPlan{
-				Provider:   "test-provider",
-				TotalSize:  1024,
-				TotalFiles: 1,
-				Timestamp:  time.Now(),
+				Provider:        "test-provider",
+				TotalSize:       1024,
+				TotalFiles:      1,
+				Timestamp:       time.Now(),
+				SignatureStatus: provider.SignatureVerified,
 				Actions: []provider.SyncAction{
 					{
 						Path:     "test-file.txt",
@@ -199,6 +200,9 @@ func TestSyncProviderDryRun(t *testing.T) {
 	if runs[0].Status != "completed" {
 		t.Errorf("expected status 'completed', got %s", runs[0].Status)
 	}
+	if runs[0].SignatureStatus != provider.SignatureVerified {
+		t.Errorf("expected signature status %q, got %q", provider.SignatureVerified, runs[0].SignatureStatus)
+	}
 }
 
 // TestSyncProviderSuccess verifies successful sync with actual downloads
@@ -213,6 +217,7 @@ func TestSyncProviderSuccess(t *testing.T) {
 	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
 		if r.URL.Path == "/test-file.txt" {
 			w.Header().Set("Content-Length", fmt.Sprintf("%d", len(fileContent)))
+			w.Header().Set("ETag", `"v1"`)
 			w.WriteHeader(http.StatusOK)
 			if _, err := w.Write([]byte(fileContent)); err != nil {
 				t.Fatalf("failed to write test response: %v", err)
@@ -310,6 +315,21 @@ func TestSyncProviderSuccess(t *testing.T) {
 	if fileRecords[0].SHA256 != checksumHex {
 		t.Errorf("expected checksum %s, got %s", checksumHex, fileRecords[0].SHA256)
 	}
+
+	// Verify provenance was recorded
+	rec := fileRecords[0]
+	if rec.SourceURL != server.URL+"/test-file.txt" {
+		t.Errorf("expected source URL %s, got %s", server.URL+"/test-file.txt", rec.SourceURL)
+	}
+	if rec.MirrorHost != strings.TrimPrefix(server.URL, "http://") {
+		t.Errorf("expected mirror host %s, got %s", strings.TrimPrefix(server.URL, "http://"), rec.MirrorHost)
+	}
+	if rec.ETag != `"v1"` {
+		t.Errorf("expected ETag \"v1\", got %s", rec.ETag)
+	}
+	if rec.SyncRunID != runs[0].ID {
+		t.Errorf("expected sync run %d, got %d", runs[0].ID, rec.SyncRunID)
+	}
 }
 
 // TestSyncProviderNotFound verifies error when syncing a non-existent provider
@@ -687,6 +707,141 @@ func TestSyncProviderPlanError(t *testing.T) {
 	}
 }
 
+// planFailureProvider is a mockProvider that rejects content while planning
+type planFailureProvider struct {
+	mockProvider
+	failures []provider.FailedFile
+}
+
+func (p *planFailureProvider) PlanFailures() []provider.FailedFile {
+	return p.failures
+}
+
+// TestSyncProviderRecordsPlanFailures verifies that content rejected during
+// planning is reported and stored as failed files
+func TestSyncProviderRecordsPlanFailures(t *testing.T) {
+	registry := provider.NewRegistry()
+
+	mockProv := &planFailureProvider{
+		mockProvider: mockProvider{name: "test-provider"},
+		failures: []provider.FailedFile{
+			{Path: "repodata/repomd.xml", URL: "http://example.com/repodata/repomd.xml", Error: "repomd.xml signature: bad signature"},
+		},
+	}
+	registry.Register(mockProv)
+
+	manager, st := newTestSyncManager(t, registry)
+	defer func() { _ = st.Close() }()
+
+	manager.config.Providers["test-provider"] = map[string]interface{}{"enabled": true}
+
+	report, err := manager.SyncProvider(context.Background(), "test-provider", provider.SyncOptions{MaxWorkers: 1})
+	if err != nil {
+		t.Fatalf("sync failed: %v", err)
+	}
+	if len(report.Failed) != 1 || report.Failed[0].Path != "repodata/repomd.xml" {
+		t.Errorf("expected repomd.xml in failed files, got %+v", report.Failed)
+	}
+
+	failed, err := st.ListFailedFiles("test-provider")
+	if err != nil {
+		t.Fatalf("failed to list failed files: %v", err)
+	}
+	if len(failed) != 1 || failed[0].Error != "repomd.xml signature: bad signature" {
+		t.Errorf("expected stored plan failure, got %+v", failed)
+	}
+}
+
+// postSyncProvider is a mockProvider that plans more files after the sync
+// and generates a file
+type postSyncProvider struct {
+	mockProvider
+	followUp  *provider.SyncPlan
+	postErr   error
+	generated []provider.GeneratedFile
+	postCalls int
+}
+
+func (p *postSyncProvider) PostSync(ctx context.Context) (*provider.SyncPlan, error) {
+	p.postCalls++
+	return p.followUp, p.postErr
+}
+
+func (p *postSyncProvider) GeneratedFiles() []provider.GeneratedFile {
+	return p.generated
+}
+
+// TestSyncProviderPostSyncAndGeneratedFiles verifies that follow-up plans
+// are executed and generated files written only when not a dry run
+func TestSyncProviderPostSyncAndGeneratedFiles(t *testing.T) {
+	registry := provider.NewRegistry()
+
+	fileContent := "follow-up content"
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		_, _ = w.Write([]byte(fileContent))
+	}))
+	defer server.Close()
+
+	mockProv := &postSyncProvider{
+		mockProvider: mockProvider{name: "test-provider"},
+		followUp: &provider.SyncPlan{
+			Provider:   "test-provider",
+			TotalFiles: 1,
+			TotalSize:  int64(len(fileContent)),
+			Actions: []provider.SyncAction{
+				{Path: "images/follow-up.bin", Action: provider.ActionDownload, Size: int64(len(fileContent)), URL: server.URL + "/follow-up.bin"},
+			},
+		},
+		postErr:   fmt.Errorf("catalog broken"),
+		generated: []provider.GeneratedFile{{Path: "catalogs/index.json", Data: []byte(`{}`)}},
+	}
+	registry.Register(mockProv)
+
+	manager, st := newTestSyncManager(t, registry)
+	defer func() { _ = st.Close() }()
+	manager.config.Providers["test-provider"] = map[string]interface{}{"enabled": true}
+	providerRoot := filepath.Join(manager.config.Server.DataDir, "test-provider")
+
+	if _, err := manager.SyncProvider(context.Background(), "test-provider", provider.SyncOptions{DryRun: true}); err != nil {
+		t.Fatalf("dry run failed: %v", err)
+	}
+	if mockProv.postCalls != 0 {
+		t.Error("expected PostSync not to run for a dry run")
+	}
+	if _, err := os.Stat(filepath.Join(providerRoot, "catalogs", "index.json")); !os.IsNotExist(err) {
+		t.Errorf("expected dry run not to write generated files, got %v", err)
+	}
+
+	report, err := manager.SyncProvider(context.Background(), "test-provider", provider.SyncOptions{MaxWorkers: 1})
+	if err != nil {
+		t.Fatalf("sync failed: %v", err)
+	}
+	if report.Downloaded != 1 {
+		t.Errorf("expected follow-up file downloaded, got %d", report.Downloaded)
+	}
+	if len(report.Failed) != 1 || report.Failed[0].Error != "catalog broken" {
+		t.Errorf("expected post-sync error as failed file, got %+v", report.Failed)
+	}
+
+	data, err := os.ReadFile(filepath.Join(providerRoot, "catalogs", "index.json"))
+	if err != nil || string(data) != `{}` {
+		t.Fatalf("generated file not written: %q, %v", data, err)
+	}
+	rec, err := st.GetFileRecord("test-provider", "catalogs/index.json")
+	if err != nil {
+		t.Fatalf("generated file not recorded: %v", err)
+	}
+	sum := sha256.Sum256([]byte(`{}`))
+	if rec.SHA256 != hex.EncodeToString(sum[:]) || rec.Size != 2 {
+		t.Errorf("unexpected generated file record %+v", rec)
+	}
+
+	runs, err := st.ListSyncRuns("test-provider", 1)
+	if err != nil || len(runs) != 1 || runs[0].Status != "partial" {
+		t.Fatalf("expected a partial sync run, got %+v, %v", runs, err)
+	}
+}
+
 // TestSyncProviderWithSkipAndDeleteActions verifies handling of skip and delete actions
 func TestSyncProviderWithSkipAndDeleteActions(t *testing.T) {
 	registry := provider.NewRegistry()
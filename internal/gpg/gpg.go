@@ -0,0 +1,72 @@
+// Package gpg loads trusted OpenPGP keys from provider config and verifies
+// detached signatures of upstream metadata against them.
+package gpg
+
+import (
+	"bytes"
+	"errors"
+	"fmt"
+	"os"
+	"strings"
+
+	"github.com/ProtonMail/go-crypto/openpgp"
+	pgperrors "github.com/ProtonMail/go-crypto/openpgp/errors"
+)
+
+// LoadKeyring reads the trusted keys of a gpg_keys setting. Each entry is an
+// armored key block or the path of a file holding one (armored or binary).
+func LoadKeyring(keys []string) (openpgp.EntityList, error) {
+	var ring openpgp.EntityList
+	for _, k := range keys {
+		k = strings.TrimSpace(k)
+		if k == "" {
+			continue
+		}
+		data := []byte(k)
+		source := "inline key"
+		if !strings.HasPrefix(k, "-----BEGIN PGP") {
+			var err error
+			data, err = os.ReadFile(k)
+			if err != nil {
+				return nil, fmt.Errorf("reading GPG key: %w", err)
+			}
+			source = k
+		}
+		var entities openpgp.EntityList
+		var err error
+		if bytes.Contains(data, []byte("-----BEGIN PGP")) {
+			entities, err = openpgp.ReadArmoredKeyRing(bytes.NewReader(data))
+		} else {
+			entities, err = openpgp.ReadKeyRing(bytes.NewReader(data))
+		}
+		if err != nil {
+			return nil, fmt.Errorf("parsing GPG key %s: %w", source, err)
+		}
+		ring = append(ring, entities...)
+	}
+	return ring, nil
+}
+
+// VerifyDetached checks an armored or binary detached signature of data and
+// returns the signing key ID.
+func VerifyDetached(ring openpgp.EntityList, data, sig []byte) (string, error) {
+	var signer *openpgp.Entity
+	var err error
+	if bytes.Contains(sig, []byte("-----BEGIN PGP SIGNATURE")) {
+		signer, err = openpgp.CheckArmoredDetachedSignature(ring, bytes.NewReader(data), bytes.NewReader(sig), nil)
+	} else {
+		signer, err = openpgp.CheckDetachedSignature(ring, bytes.NewReader(data), bytes.NewReader(sig), nil)
+	}
+	if err != nil {
+		return "", DescribeError(err)
+	}
+	return signer.PrimaryKey.KeyIdString(), nil
+}
+
+// DescribeError turns OpenPGP verification errors into failure reasons.
+func DescribeError(err error) error {
+	if errors.Is(err, pgperrors.ErrUnknownIssuer) {
+		return fmt.Errorf("signed by a key that is not in gpg_keys")
+	}
+	return fmt.Errorf("bad signature: %w", err)
+}
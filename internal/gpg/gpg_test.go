@@ -0,0 +1,97 @@
+package gpg
+
+import (
+	"bytes"
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+
+	"github.com/ProtonMail/go-crypto/openpgp"
+	"github.com/ProtonMail/go-crypto/openpgp/armor"
+	"github.com/ProtonMail/go-crypto/openpgp/packet"
+)
+
+func newTestKey(t *testing.T) (*openpgp.Entity, string) {
+	t.Helper()
+	e, err := openpgp.NewEntity("Test", "", "test@example.com", &packet.Config{Algorithm: packet.PubKeyAlgoEdDSA})
+	if err != nil {
+		t.Fatalf("NewEntity: %v", err)
+	}
+	var buf bytes.Buffer
+	w, err := armor.Encode(&buf, openpgp.PublicKeyType, nil)
+	if err != nil {
+		t.Fatalf("armor.Encode: %v", err)
+	}
+	if err := e.Serialize(w); err != nil {
+		t.Fatalf("Serialize: %v", err)
+	}
+	_ = w.Close()
+	return e, buf.String()
+}
+
+func TestLoadKeyring(t *testing.T) {
+	_, inline := newTestKey(t)
+	fileKey, _ := newTestKey(t)
+
+	var binaryKey bytes.Buffer
+	if err := fileKey.Serialize(&binaryKey); err != nil {
+		t.Fatal(err)
+	}
+	keyPath := filepath.Join(t.TempDir(), "RPM-GPG-KEY-test")
+	if err := os.WriteFile(keyPath, binaryKey.Bytes(), 0644); err != nil {
+		t.Fatal(err)
+	}
+
+	ring, err := LoadKeyring([]string{inline, keyPath, "  "})
+	if err != nil {
+		t.Fatalf("LoadKeyring: %v", err)
+	}
+	if len(ring) != 2 {
+		t.Fatalf("keyring has %d keys, want 2", len(ring))
+	}
+
+	if _, err := LoadKeyring([]string{filepath.Join(t.TempDir(), "missing")}); err == nil {
+		t.Error("expected error for missing key file")
+	}
+	if _, err := LoadKeyring([]string{"-----BEGIN PGP PUBLIC KEY BLOCK-----\ngarbage"}); err == nil {
+		t.Error("expected error for malformed key")
+	}
+}
+
+func TestVerifyDetached(t *testing.T) {
+	key, armored := newTestKey(t)
+	other, _ := newTestKey(t)
+	ring, err := LoadKeyring([]string{armored})
+	if err != nil {
+		t.Fatal(err)
+	}
+	data := []byte("abc123  openshift-client-linux.tar.gz\n")
+
+	var binarySig, armoredSig, otherSig bytes.Buffer
+	if err := openpgp.DetachSign(&binarySig, key, bytes.NewReader(data), nil); err != nil {
+		t.Fatal(err)
+	}
+	if err := openpgp.ArmoredDetachSign(&armoredSig, key, bytes.NewReader(data), nil); err != nil {
+		t.Fatal(err)
+	}
+	if err := openpgp.DetachSign(&otherSig, other, bytes.NewReader(data), nil); err != nil {
+		t.Fatal(err)
+	}
+
+	for name, sig := range map[string][]byte{"binary": binarySig.Bytes(), "armored": armoredSig.Bytes()} {
+		keyID, err := VerifyDetached(ring, data, sig)
+		if err != nil {
+			t.Errorf("%s signature rejected: %v", name, err)
+		} else if keyID != key.PrimaryKey.KeyIdString() {
+			t.Errorf("%s signature key ID = %s, want %s", name, keyID, key.PrimaryKey.KeyIdString())
+		}
+	}
+
+	if _, err := VerifyDetached(ring, []byte("tampered"), binarySig.Bytes()); err == nil || !strings.HasPrefix(err.Error(), "bad signature") {
+		t.Errorf("tampered data: err = %v", err)
+	}
+	if _, err := VerifyDetached(ring, data, otherSig.Bytes()); err == nil || !strings.Contains(err.Error(), "not in gpg_keys") {
+		t.Errorf("unknown key: err = %v", err)
+	}
+}
@@ -0,0 +1,126 @@
+// Package imageset reads oc-mirror ImageSetConfiguration documents and
+// translates them into airgap provider configs.
+package imageset
+
+import (
+	"fmt"
+	"strings"
+
+	"gopkg.in/yaml.v3"
+)
+
+const (
+	// Kind is the only document kind accepted by Parse.
+	Kind = "ImageSetConfiguration"
+
+	APIVersionV1Alpha2 = "mirror.openshift.io/v1alpha2"
+	APIVersionV2Alpha1 = "mirror.openshift.io/v2alpha1"
+)
+
+// ImageSetConfiguration is the subset of the oc-mirror v1alpha2/v2alpha1
+// schema that airgap understands. Unknown fields are ignored.
+type ImageSetConfiguration struct {
+	APIVersion    string         `yaml:"apiVersion"`
+	Kind          string         `yaml:"kind"`
+	ArchiveSize   int            `yaml:"archiveSize"`
+	StorageConfig map[string]any `yaml:"storageConfig"`
+	Mirror        Mirror         `yaml:"mirror"`
+}
+
+// Mirror lists the content to mirror.
+type Mirror struct {
+	Platform         Platform   `yaml:"platform"`
+	Operators        []Operator `yaml:"operators"`
+	AdditionalImages []Image    `yaml:"additionalImages"`
+	BlockedImages    []Image    `yaml:"blockedImages"`
+	Helm             Helm       `yaml:"helm"`
+	Samples          []Image    `yaml:"samples"`
+}
+
+// Platform selects OpenShift releases.
+type Platform struct {
+	Architectures []string          `yaml:"architectures"`
+	Channels      []PlatformChannel `yaml:"channels"`
+	Graph         bool              `yaml:"graph"`
+	Release       string            `yaml:"release"`
+}
+
+// PlatformChannel is a release channel with an optional version range.
+type PlatformChannel struct {
+	Name         string `yaml:"name"`
+	Type         string `yaml:"type"`
+	MinVersion   string `yaml:"minVersion"`
+	MaxVersion   string `yaml:"maxVersion"`
+	ShortestPath bool   `yaml:"shortestPath"`
+	Full         bool   `yaml:"full"`
+}
+
+// Operator is a catalog index and the packages to keep from it.
+type Operator struct {
+	Catalog       string    `yaml:"catalog"`
+	Full          bool      `yaml:"full"`
+	Packages      []Package `yaml:"packages"`
+	TargetCatalog string    `yaml:"targetCatalog"`
+	TargetTag     string    `yaml:"targetTag"`
+}
+
+// Package selects bundles from one operator package.
+type Package struct {
+	Name            string           `yaml:"name"`
+	DefaultChannel  string           `yaml:"defaultChannel"`
+	MinVersion      string           `yaml:"minVersion"`
+	MaxVersion      string           `yaml:"maxVersion"`
+	MinBundle       string           `yaml:"minBundle"`
+	Channels        []PackageChannel `yaml:"channels"`
+	SelectedBundles []Image          `yaml:"selectedBundles"`
+}
+
+// PackageChannel is a package channel with an optional version range.
+type PackageChannel struct {
+	Name       string `yaml:"name"`
+	MinVersion string `yaml:"minVersion"`
+	MaxVersion string `yaml:"maxVersion"`
+	MinBundle  string `yaml:"minBundle"`
+}
+
+// Image is a single image reference.
+type Image struct {
+	Name string `yaml:"name"`
+}
+
+// Helm lists Helm chart sources.
+type Helm struct {
+	Repositories []HelmRepository `yaml:"repositories"`
+	Local        []HelmChart      `yaml:"local"`
+}
+
+// HelmRepository is a remote chart repository.
+type HelmRepository struct {
+	Name   string      `yaml:"name"`
+	URL    string      `yaml:"url"`
+	Charts []HelmChart `yaml:"charts"`
+}
+
+// HelmChart is a chart reference.
+type HelmChart struct {
+	Name    string `yaml:"name"`
+	Version string `yaml:"version"`
+	Path    string `yaml:"path"`
+}
+
+// Parse decodes an ImageSetConfiguration and checks its apiVersion and kind.
+func Parse(data []byte) (*ImageSetConfiguration, error) {
+	var isc ImageSetConfiguration
+	if err := yaml.Unmarshal(data, &isc); err != nil {
+		return nil, fmt.Errorf("parsing ImageSetConfiguration: %w", err)
+	}
+	if isc.Kind != Kind {
+		return nil, fmt.Errorf("unexpected kind %q: expected %s", isc.Kind, Kind)
+	}
+	switch strings.TrimSpace(isc.APIVersion) {
+	case APIVersionV1Alpha2, APIVersionV2Alpha1:
+	default:
+		return nil, fmt.Errorf("unsupported apiVersion %q: expected %s or %s", isc.APIVersion, APIVersionV1Alpha2, APIVersionV2Alpha1)
+	}
+	return &isc, nil
+}
@@ -0,0 +1,174 @@
+package imageset
+
+import (
+	"reflect"
+	"testing"
+)
+
+const v2Config = `
+kind: ImageSetConfiguration
+apiVersion: mirror.openshift.io/v2alpha1
+mirror:
+  platform:
+    architectures: ["amd64"]
+    graph: true
+    channels:
+    - name: stable-4.16
+      minVersion: 4.16.10
+      maxVersion: 4.16.20
+    - name: stable-4.15
+      minVersion: 4.15.30
+      maxVersion: 4.15.30
+    - name: okd-4.16
+      type: okd
+  operators:
+  - catalog: registry.redhat.io/redhat/redhat-operator-index:v4.16
+    packages:
+    - name: openshift-gitops-operator
+    - name: advanced-cluster-management
+      channels:
+      - name: release-2.10
+        minVersion: 2.10.0
+        maxVersion: 2.10.5
+      - name: release-2.11
+        minVersion: 2.11.0
+        maxVersion: 2.11.2
+  - catalog: registry.redhat.io/redhat/certified-operator-index:v4.16
+    full: true
+  additionalImages:
+  - name: registry.redhat.io/ubi9/ubi:latest
+  - name: quay.io/example/blocked:1.0
+  blockedImages:
+  - name: quay.io/example/blocked:1.0
+  helm:
+    repositories:
+    - name: podinfo
+      url: https://stefanprodan.github.io/podinfo
+      charts:
+      - name: podinfo
+        version: 5.0.0
+`
+
+func TestParse(t *testing.T) {
+	isc, err := Parse([]byte(v2Config))
+	if err != nil {
+		t.Fatalf("Parse: %v", err)
+	}
+	if len(isc.Mirror.Platform.Channels) != 3 || len(isc.Mirror.Operators) != 2 || len(isc.Mirror.AdditionalImages) != 2 {
+		t.Fatalf("unexpected parse result: %+v", isc.Mirror)
+	}
+
+	for _, bad := range []string{
+		"kind: Something\napiVersion: mirror.openshift.io/v2alpha1\n",
+		"kind: ImageSetConfiguration\napiVersion: mirror.openshift.io/v1\n",
+		"kind: [",
+	} {
+		if _, err := Parse([]byte(bad)); err == nil {
+			t.Errorf("expected error for %q", bad)
+		}
+	}
+}
+
+func TestTranslate(t *testing.T) {
+	isc, err := Parse([]byte(v2Config))
+	if err != nil {
+		t.Fatal(err)
+	}
+	tr := Translate(isc, "prod")
+
+	byName := make(map[string]Provider)
+	for _, p := range tr.Providers {
+		byName[p.Name] = p
+	}
+	if len(byName) != 3 {
+		t.Fatalf("expected 3 providers, got %+v", tr.Providers)
+	}
+
+	clients := byName["prod-ocp-clients"]
+	if clients.Type != "ocp_clients" {
+		t.Fatalf("unexpected clients provider: %+v", clients)
+	}
+	if got := clients.Config["channels"]; !reflect.DeepEqual(got, []string{"stable-4.16"}) {
+		t.Errorf("channels = %v", got)
+	}
+	if got := clients.Config["versions"]; !reflect.DeepEqual(got, []string{"4.15.30"}) {
+		t.Errorf("versions = %v", got)
+	}
+	if clients.Config["update_graph"] != true {
+		t.Error("expected update_graph to be set")
+	}
+
+	images := byName["prod-images"]
+	wantImages := []string{
+		"docker://quay.io/openshift-release-dev/ocp-release:4.15.30-x86_64",
+		"docker://registry.redhat.io/ubi9/ubi:latest",
+	}
+	if got := images.Config["images"]; !reflect.DeepEqual(got, wantImages) {
+		t.Errorf("images = %v, want %v", got, wantImages)
+	}
+
+	ops := byName["prod-operators"]
+	catalogs := ops.Config["catalogs"].([]interface{})
+	if len(catalogs) != 1 {
+		t.Fatalf("expected only the package-filtered catalog, got %v", catalogs)
+	}
+	packages := catalogs[0].(map[string]interface{})["packages"].([]interface{})
+	acm := packages[1].(map[string]interface{})
+	if acm["min_version"] != "2.10.0" || acm["max_version"] != "2.11.2" {
+		t.Errorf("expected widened range 2.10.0-2.11.2, got %v", acm)
+	}
+	if ops.Config["platform"] != "linux/amd64" {
+		t.Errorf("platform = %v", ops.Config["platform"])
+	}
+
+	status := make(map[string]string)
+	for _, e := range tr.Entries {
+		status[e.Section+" "+e.Name] = e.Status
+	}
+	want := map[string]string{
+		"platform.channels stable-4.16": StatusPartial,
+		"platform.channels stable-4.15": StatusSupported,
+		"platform.channels okd-4.16":    StatusUnsupported,
+		"platform.graph graph":          StatusSupported,
+		"operators registry.redhat.io/redhat/redhat-operator-index:v4.16 openshift-gitops-operator":   StatusSupported,
+		"operators registry.redhat.io/redhat/redhat-operator-index:v4.16 advanced-cluster-management": StatusPartial,
+		"operators registry.redhat.io/redhat/certified-operator-index:v4.16":                          StatusUnsupported,
+		"additionalImages registry.redhat.io/ubi9/ubi:latest":                                         StatusSupported,
+		"helm podinfo/podinfo": StatusUnsupported,
+	}
+	for key, s := range want {
+		if status[key] != s {
+			t.Errorf("%s: status %q, want %q", key, status[key], s)
+		}
+	}
+}
+
+func TestTranslateV1Alpha2(t *testing.T) {
+	isc, err := Parse([]byte(`
+apiVersion: mirror.openshift.io/v1alpha2
+kind: ImageSetConfiguration
+archiveSize: 4
+storageConfig:
+  local:
+    path: ./metadata
+mirror:
+  additionalImages:
+  - name: registry.redhat.io/ubi8/ubi:latest
+`))
+	if err != nil {
+		t.Fatalf("Parse: %v", err)
+	}
+	tr := Translate(isc, "")
+	if len(tr.Providers) != 1 || tr.Providers[0].Name != "imageset-images" {
+		t.Fatalf("unexpected providers: %+v", tr.Providers)
+	}
+	var unsupported int
+	for _, e := range tr.Entries {
+		if e.Status == StatusUnsupported {
+			unsupported++
+		}
+	}
+	if unsupported != 2 {
+		t.Errorf("expected archiveSize and storageConfig to be reported unsupported, got %d", unsupported)
+	}
+}
@@ -0,0 +1,381 @@
+package imageset
+
+import (
+	"fmt"
+	"strconv"
+	"strings"
+)
+
+// Entry support levels reported by Translate.
+const (
+	StatusSupported   = "supported"
+	StatusPartial     = "partial"
+	StatusUnsupported = "unsupported"
+)
+
+// releaseRepository is where OpenShift release payload images are published.
+const releaseRepository = "quay.io/openshift-release-dev/ocp-release"
+
+// Provider is an airgap provider config produced from an ImageSetConfiguration.
+type Provider struct {
+	Name   string                 `json:"name" yaml:"name"`
+	Type   string                 `json:"type" yaml:"type"`
+	Config map[string]interface{} `json:"config" yaml:"config"`
+}
+
+// Entry records how one ImageSetConfiguration entry was translated.
+type Entry struct {
+	Section  string `json:"section"`
+	Name     string `json:"name"`
+	Status   string `json:"status"`
+	Provider string `json:"provider,omitempty"`
+	Note     string `json:"note,omitempty"`
+}
+
+// Translation is the result of Translate.
+type Translation struct {
+	Providers []Provider `json:"providers"`
+	Entries   []Entry    `json:"entries"`
+}
+
+// Translate maps an ImageSetConfiguration onto ocp_clients, operator_catalog
+// and container_images providers whose names start with prefix. Every entry is
+// reported with its support status so nothing is dropped silently.
+func Translate(isc *ImageSetConfiguration, prefix string) *Translation {
+	prefix = strings.TrimSpace(prefix)
+	if prefix == "" {
+		prefix = "imageset"
+	}
+	t := &Translation{Providers: []Provider{}, Entries: []Entry{}}
+
+	imagesName := prefix + "-images"
+	var images []string
+	addImage := func(ref string) {
+		if !strings.Contains(ref, "://") {
+			ref = "docker://" + ref
+		}
+		for _, existing := range images {
+			if existing == ref {
+				return
+			}
+		}
+		images = append(images, ref)
+	}
+
+	translatePlatform(t, isc.Mirror.Platform, prefix+"-ocp-clients", imagesName, addImage)
+	translateOperators(t, isc.Mirror.Operators, isc.Mirror.Platform.Architectures, prefix+"-operators")
+
+	blocked := make(map[string]bool, len(isc.Mirror.BlockedImages))
+	for _, img := range isc.Mirror.BlockedImages {
+		blocked[strings.TrimSpace(img.Name)] = true
+	}
+	for _, img := range isc.Mirror.AdditionalImages {
+		name := strings.TrimSpace(img.Name)
+		if name == "" {
+			continue
+		}
+		if blocked[name] {
+			t.Entries = append(t.Entries, Entry{Section: "additionalImages", Name: name, Status: StatusSupported, Note: "blocked by blockedImages"})
+			continue
+		}
+		addImage(name)
+		t.Entries = append(t.Entries, Entry{Section: "additionalImages", Name: name, Status: StatusSupported, Provider: imagesName})
+	}
+	for _, img := range isc.Mirror.BlockedImages {
+		t.Entries = append(t.Entries, Entry{Section: "blockedImages", Name: img.Name, Status: StatusPartial,
+			Note: "only exact additionalImages references are excluded"})
+	}
+	if len(images) > 0 {
+		t.Providers = append(t.Providers, Provider{
+			Name: imagesName,
+			Type: "container_images",
+			Config: map[string]interface{}{
+				"images":     images,
+				"output_dir": imagesName,
+			},
+		})
+	}
+
+	for _, repo := range isc.Mirror.Helm.Repositories {
+		for _, chart := range repo.Charts {
+			t.Entries = append(t.Entries, Entry{Section: "helm", Name: repo.Name + "/" + chart.Name, Status: StatusUnsupported,
+				Note: "Helm charts are not mirrored; list chart images in additionalImages"})
+		}
+	}
+	for _, chart := range isc.Mirror.Helm.Local {
+		t.Entries = append(t.Entries, Entry{Section: "helm", Name: chart.Name, Status: StatusUnsupported,
+			Note: "Helm charts are not mirrored; list chart images in additionalImages"})
+	}
+	for _, img := range isc.Mirror.Samples {
+		t.Entries = append(t.Entries, Entry{Section: "samples", Name: img.Name, Status: StatusUnsupported})
+	}
+	if isc.ArchiveSize > 0 {
+		t.Entries = append(t.Entries, Entry{Section: "archiveSize", Name: strconv.Itoa(isc.ArchiveSize), Status: StatusUnsupported,
+			Note: "set export.split_size instead"})
+	}
+	if len(isc.StorageConfig) > 0 {
+		t.Entries = append(t.Entries, Entry{Section: "storageConfig", Name: "storageConfig", Status: StatusUnsupported,
+			Note: "airgap keeps its state in its own database"})
+	}
+
+	return t
+}
+
+func translatePlatform(t *Translation, platform Platform, clientsName, imagesName string, addImage func(string)) {
+	arches := platform.Architectures
+	if len(arches) == 0 {
+		arches = []string{"amd64"}
+	}
+
+	var channels, versions []string
+	for _, ch := range platform.Channels {
+		entry := Entry{Section: "platform.channels", Name: ch.Name, Provider: clientsName}
+		if ch.Type != "" && ch.Type != "ocp" {
+			entry.Status = StatusUnsupported
+			entry.Provider = ""
+			entry.Note = fmt.Sprintf("channel type %q is not supported", ch.Type)
+			t.Entries = append(t.Entries, entry)
+			continue
+		}
+
+		if ch.MinVersion != "" && ch.MinVersion == ch.MaxVersion {
+			// A pinned release: sync its clients and mirror its payload.
+			versions = append(versions, ch.MinVersion)
+			for _, arch := range arches {
+				addImage(fmt.Sprintf("%s:%s-%s", releaseRepository, ch.MinVersion, releaseArch(arch)))
+			}
+			entry.Status = StatusSupported
+			entry.Note = "pinned release " + ch.MinVersion + "; payload mirrored by " + imagesName
+			t.Entries = append(t.Entries, entry)
+			continue
+		}
+
+		channels = append(channels, ch.Name)
+		entry.Status = StatusPartial
+		var notes []string
+		if ch.MinVersion != "" || ch.MaxVersion != "" {
+			notes = append(notes, "minVersion/maxVersion ignored; every release in the channel is synced")
+		}
+		if ch.ShortestPath {
+			notes = append(notes, "shortestPath ignored")
+		}
+		notes = append(notes, "release payloads are not resolved from channels; pin versions to mirror them")
+		entry.Note = strings.Join(notes, "; ")
+		t.Entries = append(t.Entries, entry)
+	}
+
+	if platform.Release != "" {
+		addImage(platform.Release)
+		t.Entries = append(t.Entries, Entry{Section: "platform.release", Name: platform.Release, Status: StatusSupported, Provider: imagesName})
+	}
+
+	if len(channels) == 0 && len(versions) == 0 {
+		if platform.Graph {
+			t.Entries = append(t.Entries, Entry{Section: "platform.graph", Name: "graph", Status: StatusUnsupported,
+				Note: "update graphs are captured per channel; no channels configured"})
+		}
+		return
+	}
+
+	var platforms []string
+	for _, arch := range arches {
+		if arch == "amd64" {
+			platforms = append(platforms, "linux")
+		} else {
+			platforms = append(platforms, "linux-"+arch)
+		}
+	}
+	cfg := map[string]interface{}{
+		"platforms":  platforms,
+		"output_dir": clientsName,
+	}
+	if len(channels) > 0 {
+		cfg["channels"] = channels
+	}
+	if len(versions) > 0 {
+		cfg["versions"] = versions
+	}
+	if platform.Graph {
+		cfg["update_graph"] = true
+		cfg["graph_architectures"] = arches
+		status, note := StatusSupported, ""
+		if len(channels) == 0 {
+			status, note = StatusUnsupported, "update graphs are captured per channel; only pinned releases configured"
+		}
+		t.Entries = append(t.Entries, Entry{Section: "platform.graph", Name: "graph", Status: status, Provider: clientsName, Note: note})
+	}
+	t.Providers = append(t.Providers, Provider{Name: clientsName, Type: "ocp_clients", Config: cfg})
+}
+
+func translateOperators(t *Translation, operators []Operator, arches []string, name string) {
+	var catalogs []interface{}
+	for _, op := range operators {
+		catalog := strings.TrimPrefix(strings.TrimSpace(op.Catalog), "docker://")
+		if strings.Contains(catalog, "@") {
+			t.Entries = append(t.Entries, Entry{Section: "operators", Name: op.Catalog, Status: StatusUnsupported,
+				Note: "catalogs must be referenced by tag"})
+			continue
+		}
+		if strings.HasPrefix(catalog, "oci://") || strings.HasPrefix(catalog, "file://") {
+			t.Entries = append(t.Entries, Entry{Section: "operators", Name: op.Catalog, Status: StatusUnsupported,
+				Note: "only registry catalogs are supported"})
+			continue
+		}
+		if len(op.Packages) == 0 {
+			t.Entries = append(t.Entries, Entry{Section: "operators", Name: op.Catalog, Status: StatusUnsupported,
+				Note: "mirroring a full catalog is not supported; list packages"})
+			continue
+		}
+
+		var catalogNotes []string
+		if op.Full {
+			catalogNotes = append(catalogNotes, "full ignored; only listed packages are kept")
+		}
+		if op.TargetCatalog != "" || op.TargetTag != "" {
+			catalogNotes = append(catalogNotes, "targetCatalog/targetTag ignored; the pruned catalog replaces the source tag")
+		}
+		if len(catalogNotes) > 0 {
+			t.Entries = append(t.Entries, Entry{Section: "operators", Name: op.Catalog, Status: StatusPartial, Provider: name,
+				Note: strings.Join(catalogNotes, "; ")})
+		}
+
+		var packages []interface{}
+		for _, pkg := range op.Packages {
+			filter, status, note := translatePackage(pkg)
+			t.Entries = append(t.Entries, Entry{Section: "operators", Name: op.Catalog + " " + pkg.Name, Status: status, Provider: name, Note: note})
+			packages = append(packages, filter)
+		}
+		catalogs = append(catalogs, map[string]interface{}{
+			"index":    catalog,
+			"packages": packages,
+		})
+	}
+	if len(catalogs) == 0 {
+		return
+	}
+
+	cfg := map[string]interface{}{
+		"catalogs":   catalogs,
+		"output_dir": name,
+	}
+	if len(arches) > 0 {
+		cfg["platform"] = "linux/" + arches[0]
+		if len(arches) > 1 {
+			t.Entries = append(t.Entries, Entry{Section: "platform.architectures", Name: strings.Join(arches, ","), Status: StatusPartial, Provider: name,
+				Note: "operator catalogs are read for linux/" + arches[0] + " only"})
+		}
+	}
+	t.Providers = append(t.Providers, Provider{Name: name, Type: "operator_catalog", Config: cfg})
+}
+
+// translatePackage converts a package selection into an operator_catalog
+// package filter. Per-channel version ranges are widened to one range
+// covering every channel because the filter has a single range.
+func translatePackage(pkg Package) (map[string]interface{}, string, string) {
+	filter := map[string]interface{}{"name": pkg.Name}
+	minV, maxV := pkg.MinVersion, pkg.MaxVersion
+	var notes []string
+
+	if len(pkg.Channels) > 0 {
+		var names []string
+		for _, ch := range pkg.Channels {
+			names = append(names, ch.Name)
+		}
+		filter["channels"] = names
+
+		if minV == "" && maxV == "" {
+			minV, maxV = pkg.Channels[0].MinVersion, pkg.Channels[0].MaxVersion
+			for _, ch := range pkg.Channels[1:] {
+				if ch.MinVersion != minV || ch.MaxVersion != maxV {
+					notes = append(notes, "per-channel version ranges merged into one range")
+				}
+				minV = widen(minV, ch.MinVersion, true)
+				maxV = widen(maxV, ch.MaxVersion, false)
+			}
+		}
+		for _, ch := range pkg.Channels {
+			if ch.MinBundle != "" {
+				notes = append(notes, "minBundle ignored")
+				break
+			}
+		}
+	}
+	if minV != "" {
+		filter["min_version"] = minV
+	}
+	if maxV != "" {
+		filter["max_version"] = maxV
+	}
+	if pkg.MinBundle != "" {
+		notes = append(notes, "minBundle ignored")
+	}
+	if pkg.DefaultChannel != "" {
+		notes = append(notes, "defaultChannel ignored; it follows the selected channels")
+	}
+	if len(pkg.SelectedBundles) > 0 {
+		notes = append(notes, "selectedBundles ignored")
+	}
+
+	if len(notes) > 0 {
+		return filter, StatusPartial, strings.Join(dedupe(notes), "; ")
+	}
+	return filter, StatusSupported, ""
+}
+
+// widen returns the looser of two bounds; an empty bound is unbounded.
+func widen(a, b string, lower bool) string {
+	if a == "" || b == "" {
+		return ""
+	}
+	if lessVersion(a, b) == lower {
+		return a
+	}
+	return b
+}
+
+// lessVersion compares dotted numeric versions; pre-release suffixes are ignored.
+func lessVersion(a, b string) bool {
+	pa, pb := versionParts(a), versionParts(b)
+	for i := 0; i < 3; i++ {
+		if pa[i] != pb[i] {
+			return pa[i] < pb[i]
+		}
+	}
+	return false
+}
+
+func versionParts(v string) [3]int {
+	var out [3]int
+	v = strings.TrimPrefix(v, "v")
+	if i := strings.IndexAny(v, "-+"); i >= 0 {
+		v = v[:i]
+	}
+	for i, part := range strings.SplitN(v, ".", 3) {
+		out[i], _ = strconv.Atoi(part)
+	}
+	return out
+}
+
+// releaseArch maps an architecture name to the release payload tag suffix.
+func releaseArch(arch string) string {
+	switch arch {
+	case "amd64":
+		return "x86_64"
+	case "arm64":
+		return "aarch64"
+	default:
+		return arch
+	}
+}
+
+func dedupe(values []string) []string {
+	seen := make(map[string]bool, len(values))
+	var out []string
+	for _, v := range values {
+		if !seen[v] {
+			seen[v] = true
+			out = append(out, v)
+		}
+	}
+	return out
+}
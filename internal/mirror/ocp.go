@@ -102,6 +102,18 @@ func parseOCPDirectoryListing(data []byte) []OCPVersion {
 	return versions
 }
 
+// OCPReleases returns the release versions (X.Y.Z) of an OCP mirror
+// directory listing.
+func OCPReleases(listing []byte) []string {
+	var releases []string
+	for _, v := range parseOCPDirectoryListing(listing) {
+		if v.Channel == "release" {
+			releases = append(releases, v.Version)
+		}
+	}
+	return releases
+}
+
 // parseRHCOSMinorVersions extracts minor versions like "4.17" from a RHCOS
 // directory listing. Non-version directories like "latest" are skipped.
 // Results are sorted ascending.
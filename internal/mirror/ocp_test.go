@@ -116,3 +116,16 @@ func TestParseRHCOSBuilds(t *testing.T) {
 		}
 	}
 }
+
+func TestOCPReleases(t *testing.T) {
+	releases := OCPReleases([]byte(ocpTestHTML))
+	want := map[string]bool{"4.14.41": true, "4.17.48": true, "4.18.3": true}
+	if len(releases) != len(want) {
+		t.Fatalf("expected %d releases, got %v", len(want), releases)
+	}
+	for _, r := range releases {
+		if !want[r] {
+			t.Errorf("unexpected release %q", r)
+		}
+	}
+}
@@ -10,6 +10,7 @@ import (
 	"io"
 	"log/slog"
 	"net/http"
+	"net/url"
 	"path"
 	"sort"
 	"strconv"
@@ -86,6 +87,7 @@ type graphCacheEntry struct {
 type ClientService struct {
 	httpClient *http.Client
 	logger     *slog.Logger
+	graphAPI   string
 
 	tracksMu    sync.RWMutex
 	tracksCache *TracksResult
@@ -100,6 +102,7 @@ func NewClientService(logger *slog.Logger) *ClientService {
 	return &ClientService{
 		httpClient: &http.Client{Timeout: 60 * time.Second},
 		logger:     logger,
+		graphAPI:   graphAPIURL,
 		graphCache: make(map[string]*graphCacheEntry),
 	}
 }
@@ -157,22 +160,37 @@ func (s *ClientService) FetchTracks(ctx context.Context) (*TracksResult, error)
 // FetchReleases queries the graph API for a specific channel and returns
 // the available patch versions sorted by semver.
 func (s *ClientService) FetchReleases(ctx context.Context, channel string) (*ReleasesResult, error) {
+	return s.FetchArchReleases(ctx, channel, "")
+}
+
+// FetchArchReleases is FetchReleases for the graph of one architecture
+// (amd64, arm64, ppc64le, s390x or multi). An empty arch uses the graph
+// API's default.
+func (s *ClientService) FetchArchReleases(ctx context.Context, channel, arch string) (*ReleasesResult, error) {
 	if channel == "" {
 		return nil, fmt.Errorf("channel is required")
 	}
 
+	cacheKey := channel
+	if arch != "" {
+		cacheKey = channel + "/" + arch
+	}
 	s.graphMu.RLock()
-	if entry, ok := s.graphCache[channel]; ok && time.Now().Before(entry.expiry) {
+	if entry, ok := s.graphCache[cacheKey]; ok && time.Now().Before(entry.expiry) {
 		cached := entry.result
 		s.graphMu.RUnlock()
 		return cached, nil
 	}
 	s.graphMu.RUnlock()
 
-	s.logger.Info("fetching OCP releases", "channel", channel)
+	s.logger.Info("fetching OCP releases", "channel", channel, "arch", arch)
 
-	url := fmt.Sprintf("%s?channel=%s", graphAPIURL, channel)
-	req, err := http.NewRequestWithContext(ctx, http.MethodGet, url, nil)
+	q := url.Values{}
+	q.Set("channel", channel)
+	if arch != "" {
+		q.Set("arch", arch)
+	}
+	req, err := http.NewRequestWithContext(ctx, http.MethodGet, s.graphAPI+"?"+q.Encode(), nil)
 	if err != nil {
 		return nil, fmt.Errorf("creating graph request: %w", err)
 	}
@@ -204,7 +222,7 @@ func (s *ClientService) FetchReleases(ctx context.Context, channel string) (*Rel
 		"latest", result.Latest)
 
 	s.graphMu.Lock()
-	s.graphCache[channel] = &graphCacheEntry{
+	s.graphCache[cacheKey] = &graphCacheEntry{
 		result: result,
 		expiry: time.Now().Add(graphCacheTTL),
 	}
@@ -527,39 +545,114 @@ func filterAndSortReleases(channel string, nodes []graphNode) *ReleasesResult {
 }
 
 // SortVersions sorts a slice of semver strings in ascending order
-// using numeric comparison (4.9.1 < 4.10.0 < 4.21.3).
 func SortVersions(versions []string) {
 	sort.Slice(versions, func(i, j int) bool {
-		return semverLess(versions[i], versions[j])
+		return CompareVersions(versions[i], versions[j]) < 0
 	})
 }
 
-// semverLess compares two semver strings numerically.
-func semverLess(a, b string) bool {
-	aMaj, aMin, aPatch := parseSemver(a)
-	bMaj, bMin, bPatch := parseSemver(b)
-
-	if aMaj != bMaj {
-		return aMaj < bMaj
+// CompareVersions compares two semver strings numerically, returning -1, 0
+// or 1. A leading "v" and build metadata are ignored, a prerelease sorts
+// before its release, and unparseable versions sort before parseable ones.
+func CompareVersions(a, b string) int {
+	va, okA := parseSemver(a)
+	vb, okB := parseSemver(b)
+	switch {
+	case !okA && !okB:
+		return strings.Compare(a, b)
+	case !okA:
+		return -1
+	case !okB:
+		return 1
+	}
+	for i := range va.nums {
+		if va.nums[i] != vb.nums[i] {
+			if va.nums[i] < vb.nums[i] {
+				return -1
+			}
+			return 1
+		}
 	}
-	if aMin != bMin {
-		return aMin < bMin
+	return comparePrerelease(va.pre, vb.pre)
+}
+
+// ValidVersion reports whether v is a version CompareVersions can parse.
+func ValidVersion(v string) bool {
+	_, ok := parseSemver(v)
+	return ok
+}
+
+type semver struct {
+	nums [3]int
+	pre  string
+}
+
+// parseSemver parses "major[.minor[.patch]][-pre][+build]", with an optional
+// leading "v". Missing minor and patch numbers are zero.
+func parseSemver(s string) (semver, bool) {
+	s = strings.TrimPrefix(strings.TrimSpace(s), "v")
+	if i := strings.Index(s, "+"); i >= 0 {
+		s = s[:i]
+	}
+	var v semver
+	if i := strings.Index(s, "-"); i >= 0 {
+		v.pre = s[i+1:]
+		s = s[:i]
+	}
+	parts := strings.Split(s, ".")
+	if len(parts) > 3 || parts[0] == "" {
+		return semver{}, false
+	}
+	for i, p := range parts {
+		n, err := strconv.Atoi(p)
+		if err != nil || n < 0 {
+			return semver{}, false
+		}
+		v.nums[i] = n
 	}
-	return aPatch < bPatch
+	return v, true
 }
 
-// parseSemver splits a version string into numeric major, minor, patch.
-// Returns (0,0,0) for unparseable input.
-func parseSemver(v string) (major, minor, patch int) {
-	parts := strings.SplitN(v, ".", 3)
-	if len(parts) >= 1 {
-		major, _ = strconv.Atoi(parts[0])
+// comparePrerelease orders prerelease identifiers: a release sorts after any
+// prerelease, numeric identifiers compare numerically and before textual ones.
+func comparePrerelease(a, b string) int {
+	if a == b {
+		return 0
 	}
-	if len(parts) >= 2 {
-		minor, _ = strconv.Atoi(parts[1])
+	if a == "" {
+		return 1
+	}
+	if b == "" {
+		return -1
+	}
+	pa := strings.Split(a, ".")
+	pb := strings.Split(b, ".")
+	for i := 0; i < len(pa) && i < len(pb); i++ {
+		na, errA := strconv.Atoi(pa[i])
+		nb, errB := strconv.Atoi(pb[i])
+		switch {
+		case errA == nil && errB == nil:
+			if na != nb {
+				if na < nb {
+					return -1
+				}
+				return 1
+			}
+		case errA == nil:
+			return -1
+		case errB == nil:
+			return 1
+		default:
+			if c := strings.Compare(pa[i], pb[i]); c != 0 {
+				return c
+			}
+		}
 	}
-	if len(parts) >= 3 {
-		patch, _ = strconv.Atoi(parts[2])
+	switch {
+	case len(pa) < len(pb):
+		return -1
+	case len(pa) > len(pb):
+		return 1
 	}
-	return
+	return 0
 }
@@ -9,44 +9,26 @@ import (
 
 func TestParseSemver(t *testing.T) {
 	tests := []struct {
-		input               string
-		major, minor, patch int
+		input string
+		nums  [3]int
+		pre   string
+		ok    bool
 	}{
-		{"4.21.3", 4, 21, 3},
-		{"4.9.0", 4, 9, 0},
-		{"4.10.15", 4, 10, 15},
-		{"3.11.2", 3, 11, 2},
-		{"4.21", 4, 21, 0},
-		{"invalid", 0, 0, 0},
-		{"", 0, 0, 0},
+		{"4.21.3", [3]int{4, 21, 3}, "", true},
+		{"4.10.15", [3]int{4, 10, 15}, "", true},
+		{"4.21", [3]int{4, 21, 0}, "", true},
+		{"v1.2.3+build.5", [3]int{1, 2, 3}, "", true},
+		{"4.16.0-rc.1", [3]int{4, 16, 0}, "rc.1", true},
+		{"invalid", [3]int{}, "", false},
+		{"1.2.3.4", [3]int{}, "", false},
+		{"", [3]int{}, "", false},
 	}
 
 	for _, tt := range tests {
-		maj, min, patch := parseSemver(tt.input)
-		if maj != tt.major || min != tt.minor || patch != tt.patch {
-			t.Errorf("parseSemver(%q) = (%d,%d,%d), want (%d,%d,%d)",
-				tt.input, maj, min, patch, tt.major, tt.minor, tt.patch)
-		}
-	}
-}
-
-func TestSemverLess(t *testing.T) {
-	tests := []struct {
-		a, b string
-		want bool
-	}{
-		{"4.9.0", "4.10.0", true},
-		{"4.10.0", "4.9.0", false},
-		{"4.21.0", "4.21.1", true},
-		{"4.21.3", "4.21.3", false},
-		{"3.11.0", "4.1.0", true},
-		{"4.21.9", "4.21.10", true},
-	}
-
-	for _, tt := range tests {
-		got := semverLess(tt.a, tt.b)
-		if got != tt.want {
-			t.Errorf("semverLess(%q, %q) = %v, want %v", tt.a, tt.b, got, tt.want)
+		v, ok := parseSemver(tt.input)
+		if ok != tt.ok || v.nums != tt.nums || v.pre != tt.pre {
+			t.Errorf("parseSemver(%q) = (%v, %q, %v), want (%v, %q, %v)",
+				tt.input, v.nums, v.pre, ok, tt.nums, tt.pre, tt.ok)
 		}
 	}
 }
@@ -66,6 +48,29 @@ func TestSortVersions(t *testing.T) {
 	}
 }
 
+func TestCompareVersions(t *testing.T) {
+	tests := []struct {
+		a, b string
+		want int
+	}{
+		{"4.9.1", "4.10.0", -1},
+		{"4.18.3", "4.18.3", 0},
+		{"4.18.10", "4.18.9", 1},
+		{"4.18", "4.18.0", 0},
+		{"3.11.0", "4.1.0", -1},
+		{"v2.0.0", "1.9.9", 1},
+		{"4.16.0-rc.1", "4.16.0", -1},
+		{"1.0.0-rc.2", "1.0.0-rc.10", -1},
+		{"1.0.0-alpha", "1.0.0-1", 1},
+		{"invalid", "0.0.1", -1},
+	}
+	for _, tt := range tests {
+		if got := CompareVersions(tt.a, tt.b); got != tt.want {
+			t.Errorf("CompareVersions(%q, %q) = %d, want %d", tt.a, tt.b, got, tt.want)
+		}
+	}
+}
+
 func TestGroupChannels(t *testing.T) {
 	channels := []string{
 		"stable-4.21", "stable-4.20", "stable-4.18",
@@ -0,0 +1,179 @@
+package ocp
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"net/http"
+	"net/url"
+	"regexp"
+	"strings"
+
+	"github.com/BadgerOps/airgap/internal/safety"
+)
+
+const maxGraphBytes int64 = 32 * 1024 * 1024
+
+// Graph is a Cincinnati update graph as served by upgrades_info/v1/graph.
+// Edges are pairs of node indices (from, to).
+type Graph struct {
+	Version          int                `json:"version,omitempty"`
+	Nodes            []GraphNode        `json:"nodes"`
+	Edges            [][2]int           `json:"edges"`
+	ConditionalEdges []ConditionalEdges `json:"conditionalEdges,omitempty"`
+}
+
+// GraphNode is a single release in the update graph.
+type GraphNode struct {
+	Version  string            `json:"version"`
+	Payload  string            `json:"payload"`
+	Metadata map[string]string `json:"metadata,omitempty"`
+}
+
+// ConditionalEdges groups update edges that share the same set of risks.
+type ConditionalEdges struct {
+	Edges []ConditionalEdge `json:"edges"`
+	Risks []json.RawMessage `json:"risks"`
+}
+
+// ConditionalEdge is an update recommended only when its risks do not apply.
+type ConditionalEdge struct {
+	From string `json:"from"`
+	To   string `json:"to"`
+}
+
+// FetchGraph downloads the raw Cincinnati graph for a channel and architecture.
+// The body is validated as a graph but returned unmodified so it can be
+// stored and served later exactly as upstream produced it.
+func (s *ClientService) FetchGraph(ctx context.Context, channel, arch string) ([]byte, error) {
+	if channel == "" {
+		return nil, fmt.Errorf("channel is required")
+	}
+	if arch == "" {
+		arch = "amd64"
+	}
+
+	q := url.Values{}
+	q.Set("channel", channel)
+	q.Set("arch", arch)
+	endpoint := s.graphAPI + "?" + q.Encode()
+
+	s.logger.Info("fetching OCP update graph", "channel", channel, "arch", arch)
+
+	req, err := http.NewRequestWithContext(ctx, http.MethodGet, endpoint, nil)
+	if err != nil {
+		return nil, fmt.Errorf("creating graph request: %w", err)
+	}
+	req.Header.Set("User-Agent", "airgap/1.0")
+	req.Header.Set("Accept", "application/json")
+
+	resp, err := s.httpClient.Do(req)
+	if err != nil {
+		return nil, fmt.Errorf("fetching graph for %s/%s: %w", channel, arch, err)
+	}
+	defer func() {
+		_ = resp.Body.Close()
+	}()
+
+	if resp.StatusCode != http.StatusOK {
+		return nil, fmt.Errorf("graph API returned status %d for channel %s arch %s", resp.StatusCode, channel, arch)
+	}
+
+	body, err := safety.ReadAllWithLimit(resp.Body, maxGraphBytes)
+	if err != nil {
+		if errors.Is(err, safety.ErrBodyTooLarge) {
+			return nil, fmt.Errorf("graph exceeded %d bytes for channel %s: %w", maxGraphBytes, channel, err)
+		}
+		return nil, fmt.Errorf("reading graph body: %w", err)
+	}
+	if _, err := ParseGraph(body); err != nil {
+		return nil, err
+	}
+	return body, nil
+}
+
+// ParseGraph decodes a Cincinnati graph and checks that every edge refers
+// to an existing node.
+func ParseGraph(data []byte) (*Graph, error) {
+	var g Graph
+	if err := json.Unmarshal(data, &g); err != nil {
+		return nil, fmt.Errorf("decoding graph: %w", err)
+	}
+	for _, e := range g.Edges {
+		if e[0] < 0 || e[0] >= len(g.Nodes) || e[1] < 0 || e[1] >= len(g.Nodes) {
+			return nil, fmt.Errorf("graph edge %v references a missing node", e)
+		}
+	}
+	return &g, nil
+}
+
+// FilterGraph returns a copy of g containing only the nodes whose version is
+// kept. Edge indices are remapped and edges touching removed nodes are dropped,
+// as are conditional edges between removed versions.
+func FilterGraph(g *Graph, keep func(version string) bool) *Graph {
+	out := &Graph{
+		Version: g.Version,
+		Nodes:   []GraphNode{},
+		Edges:   [][2]int{},
+	}
+
+	index := make(map[int]int, len(g.Nodes))
+	kept := make(map[string]bool, len(g.Nodes))
+	for i, n := range g.Nodes {
+		if !keep(n.Version) {
+			continue
+		}
+		index[i] = len(out.Nodes)
+		kept[n.Version] = true
+		out.Nodes = append(out.Nodes, n)
+	}
+
+	for _, e := range g.Edges {
+		from, okFrom := index[e[0]]
+		to, okTo := index[e[1]]
+		if okFrom && okTo {
+			out.Edges = append(out.Edges, [2]int{from, to})
+		}
+	}
+
+	for _, ce := range g.ConditionalEdges {
+		var edges []ConditionalEdge
+		for _, e := range ce.Edges {
+			if kept[e.From] && kept[e.To] {
+				edges = append(edges, e)
+			}
+		}
+		if len(edges) > 0 {
+			out.ConditionalEdges = append(out.ConditionalEdges, ConditionalEdges{Edges: edges, Risks: ce.Risks})
+		}
+	}
+
+	return out
+}
+
+// releaseTagPattern matches release payload tags such as "4.16.35-x86_64".
+var releaseTagPattern = regexp.MustCompile(`^(\d+\.\d+\.\d+(?:-[0-9A-Za-z.]+?)?)-(x86_64|aarch64|ppc64le|s390x|multi)$`)
+
+// ReleaseVersionFromImage extracts the OCP version from a release payload
+// reference such as "quay.io/openshift-release-dev/ocp-release:4.16.35-x86_64".
+func ReleaseVersionFromImage(ref string) (string, bool) {
+	ref = strings.TrimPrefix(ref, "docker://")
+	if at := strings.Index(ref, "@"); at >= 0 {
+		ref = ref[:at]
+	}
+	slash := strings.LastIndex(ref, "/")
+	colon := strings.LastIndex(ref, ":")
+	if colon <= slash {
+		return "", false
+	}
+	repo, tag := ref[slash+1:colon], ref[colon+1:]
+	if repo != "ocp-release" {
+		return "", false
+	}
+	m := releaseTagPattern.FindStringSubmatch(tag)
+	if m == nil {
+		return "", false
+	}
+	return m[1], true
+}
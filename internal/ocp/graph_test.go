@@ -0,0 +1,148 @@
+package ocp
+
+import (
+	"context"
+	"fmt"
+	"io"
+	"log/slog"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+)
+
+const testGraph = `{
+  "version": 1,
+  "nodes": [
+    {"version": "4.16.1", "payload": "quay.io/openshift-release-dev/ocp-release@sha256:aaa", "metadata": {"io.openshift.upgrades.graph.release.channels": "stable-4.16"}},
+    {"version": "4.16.2", "payload": "quay.io/openshift-release-dev/ocp-release@sha256:bbb"},
+    {"version": "4.16.3", "payload": "quay.io/openshift-release-dev/ocp-release@sha256:ccc"}
+  ],
+  "edges": [[0, 1], [1, 2], [0, 2]],
+  "conditionalEdges": [
+    {"edges": [{"from": "4.16.1", "to": "4.16.3"}, {"from": "4.16.2", "to": "4.16.3"}], "risks": [{"name": "SomeRisk", "url": "https://example.com", "message": "m", "matchingRules": [{"type": "Always"}]}]}
+  ]
+}`
+
+func TestParseGraph(t *testing.T) {
+	g, err := ParseGraph([]byte(testGraph))
+	if err != nil {
+		t.Fatalf("ParseGraph: %v", err)
+	}
+	if len(g.Nodes) != 3 || len(g.Edges) != 3 || len(g.ConditionalEdges) != 1 {
+		t.Fatalf("unexpected graph: %+v", g)
+	}
+	if g.Nodes[0].Payload == "" {
+		t.Error("expected payload to be parsed")
+	}
+
+	if _, err := ParseGraph([]byte(`{"nodes":[{"version":"4.16.1"}],"edges":[[0,3]]}`)); err == nil {
+		t.Error("expected error for edge referencing a missing node")
+	}
+}
+
+func TestFilterGraph(t *testing.T) {
+	g, err := ParseGraph([]byte(testGraph))
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	keep := map[string]bool{"4.16.1": true, "4.16.3": true}
+	out := FilterGraph(g, func(v string) bool { return keep[v] })
+
+	if len(out.Nodes) != 2 || out.Nodes[0].Version != "4.16.1" || out.Nodes[1].Version != "4.16.3" {
+		t.Fatalf("unexpected nodes: %+v", out.Nodes)
+	}
+	if len(out.Edges) != 1 || out.Edges[0] != [2]int{0, 1} {
+		t.Fatalf("unexpected edges: %v", out.Edges)
+	}
+	if len(out.ConditionalEdges) != 1 || len(out.ConditionalEdges[0].Edges) != 1 {
+		t.Fatalf("unexpected conditional edges: %+v", out.ConditionalEdges)
+	}
+	if ce := out.ConditionalEdges[0].Edges[0]; ce.From != "4.16.1" || ce.To != "4.16.3" {
+		t.Errorf("unexpected conditional edge: %+v", ce)
+	}
+	if len(out.ConditionalEdges[0].Risks) != 1 {
+		t.Error("expected risks to be preserved")
+	}
+
+	empty := FilterGraph(g, func(string) bool { return false })
+	if empty.Nodes == nil || empty.Edges == nil {
+		t.Error("expected empty, non-nil nodes and edges")
+	}
+	if len(empty.ConditionalEdges) != 0 {
+		t.Errorf("expected no conditional edges, got %d", len(empty.ConditionalEdges))
+	}
+}
+
+func TestReleaseVersionFromImage(t *testing.T) {
+	tests := []struct {
+		ref  string
+		want string
+		ok   bool
+	}{
+		{"docker://quay.io/openshift-release-dev/ocp-release:4.16.35-x86_64", "4.16.35", true},
+		{"quay.io/openshift-release-dev/ocp-release:4.17.0-rc.1-aarch64", "4.17.0-rc.1", true},
+		{"quay.io/openshift-release-dev/ocp-release:4.16.35-multi", "4.16.35", true},
+		{"quay.io/openshift-release-dev/ocp-release@sha256:abc", "", false},
+		{"registry.access.redhat.com/ubi9/ubi:latest", "", false},
+		{"quay.io/openshift-release-dev/ocp-v4.0-art-dev:4.16.35-x86_64", "", false},
+	}
+	for _, tt := range tests {
+		got, ok := ReleaseVersionFromImage(tt.ref)
+		if got != tt.want || ok != tt.ok {
+			t.Errorf("ReleaseVersionFromImage(%q) = %q, %v; want %q, %v", tt.ref, got, ok, tt.want, tt.ok)
+		}
+	}
+}
+
+func TestFetchGraph(t *testing.T) {
+	var gotQuery string
+	srv := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		gotQuery = r.URL.RawQuery
+		w.Header().Set("Content-Type", "application/json")
+		_, _ = io.WriteString(w, testGraph)
+	}))
+	defer srv.Close()
+
+	svc := NewClientService(slog.New(slog.NewTextHandler(io.Discard, nil)))
+	svc.graphAPI = srv.URL
+
+	data, err := svc.FetchGraph(context.Background(), "stable-4.16", "")
+	if err != nil {
+		t.Fatalf("FetchGraph: %v", err)
+	}
+	if string(data) != testGraph {
+		t.Error("expected the upstream body to be returned unmodified")
+	}
+	if gotQuery != "arch=amd64&channel=stable-4.16" {
+		t.Errorf("unexpected query %q", gotQuery)
+	}
+
+	if _, err := svc.FetchGraph(context.Background(), "", "amd64"); err == nil {
+		t.Error("expected error for empty channel")
+	}
+}
+
+func TestFetchArchReleases(t *testing.T) {
+	var queries []string
+	srv := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		queries = append(queries, r.URL.RawQuery)
+		w.Header().Set("Content-Type", "application/json")
+		_, _ = io.WriteString(w, testGraph)
+	}))
+	defer srv.Close()
+
+	svc := NewClientService(slog.New(slog.NewTextHandler(io.Discard, nil)))
+	svc.graphAPI = srv.URL
+
+	for _, arch := range []string{"arm64", "arm64", ""} {
+		if _, err := svc.FetchArchReleases(context.Background(), "stable-4.16", arch); err != nil {
+			t.Fatalf("FetchArchReleases(%q): %v", arch, err)
+		}
+	}
+	// Each architecture is fetched once and cached separately.
+	want := []string{"arch=arm64&channel=stable-4.16", "channel=stable-4.16"}
+	if fmt.Sprint(queries) != fmt.Sprint(want) {
+		t.Errorf("queries = %v, want %v", queries, want)
+	}
+}
@@ -0,0 +1,70 @@
+package containerimages
+
+import (
+	"fmt"
+	"strings"
+)
+
+// platform is the platform object of an image index child descriptor.
+type platform struct {
+	OS           string `json:"os"`
+	Architecture string `json:"architecture"`
+	Variant      string `json:"variant,omitempty"`
+}
+
+// NormalizePlatforms validates "os/arch[/variant]" filters and returns them
+// lower-cased and de-duplicated.
+func NormalizePlatforms(raw []string) ([]string, error) {
+	seen := make(map[string]struct{})
+	out := make([]string, 0, len(raw))
+	for _, r := range raw {
+		p := strings.ToLower(strings.TrimSpace(r))
+		if p == "" {
+			continue
+		}
+		parts := strings.Split(p, "/")
+		if len(parts) < 2 || len(parts) > 3 {
+			return nil, fmt.Errorf("invalid platform %q: expected os/arch[/variant]", r)
+		}
+		for _, part := range parts {
+			if part == "" {
+				return nil, fmt.Errorf("invalid platform %q: expected os/arch[/variant]", r)
+			}
+		}
+		if _, ok := seen[p]; ok {
+			continue
+		}
+		seen[p] = struct{}{}
+		out = append(out, p)
+	}
+	return out, nil
+}
+
+// MatchPlatform reports whether os/arch/variant matches any of filters. A
+// filter without a variant matches every variant of its architecture, and an
+// empty filter list matches everything.
+func MatchPlatform(filters []string, os, arch, variant string) bool {
+	if len(filters) == 0 {
+		return true
+	}
+	base := strings.ToLower(os + "/" + arch)
+	full := base
+	if variant != "" {
+		full += "/" + strings.ToLower(variant)
+	}
+	for _, f := range filters {
+		if f == base || f == full {
+			return true
+		}
+	}
+	return false
+}
+
+// keepIndexChild reports whether planning follows an index child. Children
+// without a platform object cannot be classified and are kept.
+func keepIndexChild(filters []string, d descriptor) bool {
+	if d.Platform == nil {
+		return true
+	}
+	return MatchPlatform(filters, d.Platform.OS, d.Platform.Architecture, d.Platform.Variant)
+}
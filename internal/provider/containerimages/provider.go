@@ -19,15 +19,16 @@ import (
 	"time"
 
 	"github.com/BadgerOps/airgap/internal/config"
+	"github.com/BadgerOps/airgap/internal/imageset"
 	"github.com/BadgerOps/airgap/internal/provider"
+	"github.com/BadgerOps/airgap/internal/registryauth"
 	"github.com/BadgerOps/airgap/internal/safety"
 )
 
 const (
-	maxManifestBytes  int64 = 16 * 1024 * 1024
-	maxTokenBodyBytes int64 = 1 * 1024 * 1024
-	defaultOutputDir        = "images"
-	defaultImageTag         = "latest"
+	maxManifestBytes int64 = 16 * 1024 * 1024
+	defaultOutputDir       = "images"
+	defaultImageTag        = "latest"
 )
 
 var (
@@ -38,18 +39,19 @@ var (
 		"application/vnd.docker.distribution.manifest.v2+json",
 		"application/vnd.docker.distribution.manifest.v1+json",
 	}, ", ")
-	authParamRegexp = regexp.MustCompile(`([a-zA-Z_]+)="([^"]*)"`)
-	slugRegexp      = regexp.MustCompile(`[^a-zA-Z0-9._-]+`)
+	slugRegexp = regexp.MustCompile(`[^a-zA-Z0-9._-]+`)
 )
 
 // Provider mirrors OCI/Docker images by downloading manifests and blobs.
 type Provider struct {
-	name       string
-	cfg        *config.ContainerImagesProviderConfig
-	dataDir    string
-	logger     *slog.Logger
-	http       *http.Client
-	tokenByKey map[string]string
+	name      string
+	cfg       *config.ContainerImagesProviderConfig
+	dataDir   string
+	logger    *slog.Logger
+	http      *http.Client
+	auth      *registryauth.Resolver
+	authByKey map[string]string // Authorization header per registry host and scope
+	generated []provider.GeneratedFile
 }
 
 // NewProvider creates a new container images provider.
@@ -58,11 +60,11 @@ func NewProvider(dataDir string, logger *slog.Logger) *Provider {
 		logger = slog.Default()
 	}
 	return &Provider{
-		name:       "container_images",
-		dataDir:    dataDir,
-		logger:     logger,
-		http:       safety.NewHTTPClient(90 * time.Second),
-		tokenByKey: make(map[string]string),
+		name:      "container_images",
+		dataDir:   dataDir,
+		logger:    logger,
+		http:      safety.NewHTTPClient(90 * time.Second),
+		authByKey: make(map[string]string),
 	}
 }
 
@@ -72,6 +74,9 @@ func (p *Provider) SetName(name string) { p.name = name }
 
 func (p *Provider) Type() string { return "container_images" }
 
+// SetHTTPClient overrides the HTTP client used for registry requests.
+func (p *Provider) SetHTTPClient(c *http.Client) { p.http = c }
+
 func (p *Provider) Configure(rawCfg provider.ProviderConfig) error {
 	cfg, err := config.ParseProviderConfig[config.ContainerImagesProviderConfig](rawCfg)
 	if err != nil {
@@ -85,9 +90,29 @@ func (p *Provider) Configure(rawCfg provider.ProviderConfig) error {
 		return fmt.Errorf("invalid output_dir: %w", err)
 	}
 
+	platforms, err := NormalizePlatforms(cfg.Platforms)
+	if err != nil {
+		return err
+	}
+	cfg.Platforms = platforms
+
+	auth := registryauth.NewResolver(cfg.Credentials, cfg.AuthFile)
+	if err := auth.Validate(); err != nil {
+		return err
+	}
+
+	refs := cfg.Images
+	if cfg.ImagesetConfig != "" {
+		extra, err := imagesetImages(cfg.ImagesetConfig)
+		if err != nil {
+			return err
+		}
+		refs = append(refs, extra...)
+	}
+
 	seen := make(map[string]struct{})
-	normalized := make([]string, 0, len(cfg.Images))
-	for _, raw := range cfg.Images {
+	normalized := make([]string, 0, len(refs))
+	for _, raw := range refs {
 		ref := strings.TrimSpace(raw)
 		if ref == "" {
 			continue
@@ -104,13 +129,40 @@ func (p *Provider) Configure(rawCfg provider.ProviderConfig) error {
 	cfg.Images = normalized
 
 	p.cfg = cfg
+	p.auth = auth
 	p.logger.Debug("configured container images provider",
 		slog.Int("images", len(cfg.Images)),
+		slog.Any("platforms", cfg.Platforms),
+		slog.Bool("referrers", cfg.Referrers),
+		slog.Int("credentials", len(cfg.Credentials)),
+		slog.Bool("auth_file", cfg.AuthFile != ""),
 		slog.String("output_dir", cfg.OutputDir),
 	)
 	return nil
 }
 
+// imagesetImages reads an oc-mirror ImageSetConfiguration file and returns
+// the image references a container_images provider can mirror from it:
+// additionalImages (minus blockedImages) and pinned release payloads.
+func imagesetImages(path string) ([]string, error) {
+	data, err := os.ReadFile(path)
+	if err != nil {
+		return nil, fmt.Errorf("reading imageset_config: %w", err)
+	}
+	isc, err := imageset.Parse(data)
+	if err != nil {
+		return nil, fmt.Errorf("imageset_config %s: %w", path, err)
+	}
+	for _, p := range imageset.Translate(isc, "").Providers {
+		if p.Type != "container_images" {
+			continue
+		}
+		images, _ := p.Config["images"].([]string)
+		return images, nil
+	}
+	return nil, nil
+}
+
 func (p *Provider) Plan(ctx context.Context) (*provider.SyncPlan, error) {
 	if p.cfg == nil {
 		return nil, fmt.Errorf("provider not configured")
@@ -121,6 +173,7 @@ func (p *Provider) Plan(ctx context.Context) (*provider.SyncPlan, error) {
 		Actions:   []provider.SyncAction{},
 		Timestamp: time.Now(),
 	}
+	p.generated = nil
 
 	actionsByPath := make(map[string]provider.SyncAction)
 	for _, raw := range p.cfg.Images {
@@ -160,6 +213,11 @@ func (p *Provider) Plan(ctx context.Context) (*provider.SyncPlan, error) {
 	return plan, nil
 }
 
+// GeneratedFiles returns the artifacts files built by the last Plan.
+func (p *Provider) GeneratedFiles() []provider.GeneratedFile {
+	return p.generated
+}
+
 func (p *Provider) Sync(ctx context.Context, plan *provider.SyncPlan, opts provider.SyncOptions) (*provider.SyncReport, error) {
 	report := &provider.SyncReport{
 		Provider:  p.Name(),
@@ -294,9 +352,10 @@ type ImageReference struct {
 }
 
 type descriptor struct {
-	MediaType string `json:"mediaType"`
-	Digest    string `json:"digest"`
-	Size      int64  `json:"size"`
+	MediaType string    `json:"mediaType"`
+	Digest    string    `json:"digest"`
+	Size      int64     `json:"size"`
+	Platform  *platform `json:"platform,omitempty"`
 }
 
 type imageIndexManifest struct {
@@ -466,33 +525,60 @@ func (p *Provider) planImage(ctx context.Context, ref ImageReference) ([]provide
 		return nil, fmt.Errorf("manifest digest missing for %s", ref.Raw)
 	}
 
+	g := &manifestGraph{
+		ref:          ref,
+		imageID:      imagePathID(ref),
+		seenManifest: make(map[string]struct{}),
+		seenBlob:     make(map[string]struct{}),
+	}
+	if err := p.walkManifests(ctx, g, rootDesc, rootBody, authHeader); err != nil {
+		return nil, err
+	}
+	if p.cfg.Referrers {
+		if err := p.planArtifacts(ctx, g); err != nil {
+			return nil, err
+		}
+	}
+	return g.actions, nil
+}
+
+// manifestGraph accumulates the download actions for one mirrored image.
+type manifestGraph struct {
+	ref          ImageReference
+	imageID      string
+	seenManifest map[string]struct{}
+	seenBlob     map[string]struct{}
+	// manifests lists visited manifest digests in walk order.
+	manifests []string
+	actions   []provider.SyncAction
+}
+
+// walkManifests plans root and every manifest and blob it references.
+func (p *Provider) walkManifests(ctx context.Context, g *manifestGraph, rootDesc descriptor, rootBody []byte, authHeader string) error {
 	type queueItem struct {
 		desc       descriptor
 		body       []byte
 		authHeader string
 	}
 
+	ref := g.ref
 	queue := []queueItem{{desc: rootDesc, body: rootBody, authHeader: authHeader}}
-	seenManifest := make(map[string]struct{})
-	seenBlob := make(map[string]struct{})
-	var actions []provider.SyncAction
-
-	imageID := imagePathID(ref)
 
 	for len(queue) > 0 {
 		item := queue[0]
 		queue = queue[1:]
 
-		if _, ok := seenManifest[item.desc.Digest]; ok {
+		if _, ok := g.seenManifest[item.desc.Digest]; ok {
 			continue
 		}
-		seenManifest[item.desc.Digest] = struct{}{}
+		g.seenManifest[item.desc.Digest] = struct{}{}
+		g.manifests = append(g.manifests, item.desc.Digest)
 
-		action, err := p.newManifestAction(ref, imageID, item.desc, item.authHeader)
+		action, err := p.newManifestAction(ref, g.imageID, item.desc, item.authHeader)
 		if err != nil {
-			return nil, err
+			return err
 		}
-		actions = append(actions, action)
+		g.actions = append(g.actions, action)
 
 		childManifests, childBlobs, err := parseManifestDependencies(item.desc.MediaType, item.body)
 		if err != nil {
@@ -505,12 +591,18 @@ func (p *Provider) planImage(ctx context.Context, ref ImageReference) ([]provide
 			if child.Digest == "" {
 				continue
 			}
-			if _, ok := seenManifest[child.Digest]; ok {
+			if _, ok := g.seenManifest[child.Digest]; ok {
+				continue
+			}
+			if !keepIndexChild(p.cfg.Platforms, child) {
+				p.logger.Debug("skipping index child for unselected platform",
+					"image", ref.Raw, "digest", child.Digest,
+					"platform", child.Platform.OS+"/"+child.Platform.Architecture)
 				continue
 			}
 			childDesc, childBody, childAuth, err := p.fetchManifest(ctx, ref, child.Digest)
 			if err != nil {
-				return nil, fmt.Errorf("fetching child manifest %s: %w", child.Digest, err)
+				return fmt.Errorf("fetching child manifest %s: %w", child.Digest, err)
 			}
 			queue = append(queue, queueItem{desc: childDesc, body: childBody, authHeader: childAuth})
 		}
@@ -519,19 +611,19 @@ func (p *Provider) planImage(ctx context.Context, ref ImageReference) ([]provide
 			if blob.Digest == "" {
 				continue
 			}
-			if _, ok := seenBlob[blob.Digest]; ok {
+			if _, ok := g.seenBlob[blob.Digest]; ok {
 				continue
 			}
-			seenBlob[blob.Digest] = struct{}{}
-			blobAction, err := p.newBlobAction(ref, imageID, blob, item.authHeader)
+			g.seenBlob[blob.Digest] = struct{}{}
+			blobAction, err := p.newBlobAction(ref, g.imageID, blob, item.authHeader)
 			if err != nil {
-				return nil, err
+				return err
 			}
-			actions = append(actions, blobAction)
+			g.actions = append(g.actions, blobAction)
 		}
 	}
 
-	return actions, nil
+	return nil
 }
 
 func parseManifestDependencies(mediaType string, body []byte) ([]descriptor, []descriptor, error) {
@@ -590,7 +682,7 @@ func manifestKind(mediaType string) string {
 func (p *Provider) fetchManifest(ctx context.Context, ref ImageReference, manifestRef string) (descriptor, []byte, string, error) {
 	scope := fmt.Sprintf("repository:%s:pull", ref.Repository)
 	manifestURL := buildManifestURL(ref, manifestRef)
-	body, headers, authHeader, err := p.registryGET(ctx, manifestURL, manifestAcceptHeader, scope)
+	body, headers, authHeader, err := p.registryGET(ctx, ref, manifestURL, manifestAcceptHeader, scope)
 	if err != nil {
 		return descriptor{}, nil, "", err
 	}
@@ -613,17 +705,49 @@ func (p *Provider) fetchManifest(ctx context.Context, ref ImageReference, manife
 	return desc, body, authHeader, nil
 }
 
-func (p *Provider) registryGET(ctx context.Context, endpoint, accept, scope string) ([]byte, http.Header, string, error) {
-	tokenKey := endpoint + "|" + scope
-	var authHeader string
-	if token := p.tokenByKey[tokenKey]; token != "" {
-		authHeader = "Bearer " + token
+// FetchManifest fetches a manifest by tag or digest and returns its media
+// type, digest and raw body.
+func (p *Provider) FetchManifest(ctx context.Context, ref ImageReference, manifestRef string) (string, string, []byte, error) {
+	desc, body, _, err := p.fetchManifest(ctx, ref, manifestRef)
+	if err != nil {
+		return "", "", nil, err
 	}
+	return desc.MediaType, desc.Digest, body, nil
+}
+
+// BlobAction plans the download of a blob of ref into the image directory
+// imageID, authorized like the last manifest fetched from ref.
+func (p *Provider) BlobAction(ref ImageReference, imageID, digest string, size int64) (provider.SyncAction, error) {
+	scope := fmt.Sprintf("repository:%s:pull", ref.Repository)
+	return p.newBlobAction(ref, imageID, descriptor{Digest: digest, Size: size}, p.authByKey[ref.EndpointHost+"|"+scope])
+}
+
+func (p *Provider) registryGET(ctx context.Context, ref ImageReference, endpoint, accept, scope string) ([]byte, http.Header, string, error) {
+	resp, authHeader, err := p.registryDo(ctx, ref, endpoint, accept, scope)
+	if err != nil {
+		return nil, nil, "", err
+	}
+	data, err := safety.ReadAllWithLimit(resp.Body, maxManifestBytes)
+	if closeErr := resp.Body.Close(); closeErr != nil {
+		p.logger.Warn("failed to close response body", "error", closeErr)
+	}
+	if err != nil {
+		return nil, nil, "", fmt.Errorf("reading response body: %w", err)
+	}
+	return data, resp.Header.Clone(), authHeader, nil
+}
+
+// registryDo issues an authenticated GET, answering the first 401 challenge
+// with the credential configured for ref (or anonymously). On success the
+// caller owns the response body.
+func (p *Provider) registryDo(ctx context.Context, ref ImageReference, endpoint, accept, scope string) (*http.Response, string, error) {
+	authKey := ref.EndpointHost + "|" + scope
+	authHeader := p.authByKey[authKey]
 
 	for attempt := 0; attempt < 2; attempt++ {
 		req, err := http.NewRequestWithContext(ctx, http.MethodGet, endpoint, nil)
 		if err != nil {
-			return nil, nil, "", fmt.Errorf("creating request: %w", err)
+			return nil, "", fmt.Errorf("creating request: %w", err)
 		}
 		if accept != "" {
 			req.Header.Set("Accept", accept)
@@ -634,7 +758,7 @@ func (p *Provider) registryGET(ctx context.Context, endpoint, accept, scope stri
 
 		resp, err := p.http.Do(req)
 		if err != nil {
-			return nil, nil, "", fmt.Errorf("executing request: %w", err)
+			return nil, "", fmt.Errorf("executing request: %w", err)
 		}
 
 		if resp.StatusCode == http.StatusUnauthorized && attempt == 0 {
@@ -642,12 +766,15 @@ func (p *Provider) registryGET(ctx context.Context, endpoint, accept, scope stri
 			if closeErr := resp.Body.Close(); closeErr != nil {
 				p.logger.Warn("failed to close unauthorized response body", "error", closeErr)
 			}
-			token, err := p.fetchBearerToken(ctx, challenge, scope)
+			cred, err := p.auth.Lookup(ctx, ref.Registry, ref.Repository)
 			if err != nil {
-				return nil, nil, "", fmt.Errorf("fetching bearer token: %w", err)
+				return nil, "", fmt.Errorf("resolving credentials for %s: %w", ref.Registry, err)
 			}
-			authHeader = "Bearer " + token
-			p.tokenByKey[tokenKey] = token
+			authHeader, err = registryauth.Authenticate(ctx, p.http, challenge, scope, cred)
+			if err != nil {
+				return nil, "", fmt.Errorf("authenticating to %s: %w", ref.Registry, err)
+			}
+			p.authByKey[authKey] = authHeader
 			continue
 		}
 
@@ -656,108 +783,13 @@ func (p *Provider) registryGET(ctx context.Context, endpoint, accept, scope stri
 			if closeErr := resp.Body.Close(); closeErr != nil {
 				p.logger.Warn("failed to close error response body", "error", closeErr)
 			}
-			return nil, nil, "", fmt.Errorf("registry returned %s: %s", resp.Status, strings.TrimSpace(string(body)))
+			return nil, "", &registryError{StatusCode: resp.StatusCode, Status: resp.Status, Body: strings.TrimSpace(string(body))}
 		}
 
-		data, err := safety.ReadAllWithLimit(resp.Body, maxManifestBytes)
-		if closeErr := resp.Body.Close(); closeErr != nil {
-			p.logger.Warn("failed to close response body", "error", closeErr)
-		}
-		if err != nil {
-			return nil, nil, "", fmt.Errorf("reading response body: %w", err)
-		}
-		return data, resp.Header.Clone(), authHeader, nil
+		return resp, authHeader, nil
 	}
 
-	return nil, nil, "", fmt.Errorf("registry authentication failed")
-}
-
-func (p *Provider) fetchBearerToken(ctx context.Context, challenge, scope string) (string, error) {
-	if challenge == "" {
-		return "", fmt.Errorf("missing WWW-Authenticate challenge")
-	}
-	if !strings.HasPrefix(strings.ToLower(challenge), "bearer ") {
-		return "", fmt.Errorf("unsupported auth challenge: %q", challenge)
-	}
-
-	params := parseAuthParams(challenge)
-	realm := params["realm"]
-	if realm == "" {
-		return "", fmt.Errorf("bearer challenge missing realm")
-	}
-
-	values := url.Values{}
-	if service := params["service"]; service != "" {
-		values.Set("service", service)
-	}
-	tokenScope := params["scope"]
-	if tokenScope == "" {
-		tokenScope = scope
-	}
-	if tokenScope != "" {
-		values.Set("scope", tokenScope)
-	}
-
-	tokenURL := realm
-	if encoded := values.Encode(); encoded != "" {
-		if strings.Contains(tokenURL, "?") {
-			tokenURL += "&" + encoded
-		} else {
-			tokenURL += "?" + encoded
-		}
-	}
-
-	req, err := http.NewRequestWithContext(ctx, http.MethodGet, tokenURL, nil)
-	if err != nil {
-		return "", fmt.Errorf("creating token request: %w", err)
-	}
-	resp, err := p.http.Do(req)
-	if err != nil {
-		return "", fmt.Errorf("executing token request: %w", err)
-	}
-	defer func() {
-		_ = resp.Body.Close()
-	}()
-
-	if resp.StatusCode < 200 || resp.StatusCode > 299 {
-		body, _ := io.ReadAll(io.LimitReader(resp.Body, 4096))
-		return "", fmt.Errorf("token endpoint returned %s: %s", resp.Status, strings.TrimSpace(string(body)))
-	}
-
-	data, err := safety.ReadAllWithLimit(resp.Body, maxTokenBodyBytes)
-	if err != nil {
-		return "", fmt.Errorf("reading token response: %w", err)
-	}
-	var tokenResp struct {
-		Token       string `json:"token"`
-		AccessToken string `json:"access_token"`
-	}
-	if err := json.Unmarshal(data, &tokenResp); err != nil {
-		return "", fmt.Errorf("parsing token response: %w", err)
-	}
-	token := tokenResp.Token
-	if token == "" {
-		token = tokenResp.AccessToken
-	}
-	if token == "" {
-		return "", fmt.Errorf("token response did not include token")
-	}
-	return token, nil
-}
-
-func parseAuthParams(challenge string) map[string]string {
-	result := make(map[string]string)
-	trimmed := strings.TrimSpace(challenge)
-	if strings.HasPrefix(strings.ToLower(trimmed), "bearer ") {
-		trimmed = strings.TrimSpace(trimmed[len("bearer "):])
-	}
-	matches := authParamRegexp.FindAllStringSubmatch(trimmed, -1)
-	for _, m := range matches {
-		if len(m) == 3 {
-			result[strings.ToLower(m[1])] = m[2]
-		}
-	}
-	return result
+	return nil, "", fmt.Errorf("registry authentication failed")
 }
 
 func (p *Provider) newManifestAction(ref ImageReference, imageID string, desc descriptor, authHeader string) (provider.SyncAction, error) {
@@ -772,13 +804,11 @@ func (p *Provider) newManifestAction(ref ImageReference, imageID string, desc de
 		algo,
 		hash+".json",
 	))
-	headers := make(map[string]string)
-	if authHeader != "" {
-		headers["Authorization"] = authHeader
-	}
-	headers["Accept"] = manifestAcceptHeader
+	headers := map[string]string{"Accept": manifestAcceptHeader}
 	expectedChecksum := expectedChecksumForDigest(algo, hash)
-	return p.buildAction(relPath, buildManifestURL(ref, desc.Digest), expectedChecksum, desc.Size, headers), nil
+	action := p.buildAction(relPath, buildManifestURL(ref, desc.Digest), expectedChecksum, desc.Size, headers)
+	action.Authorize = registryauth.Authorizer(authHeader)
+	return action, nil
 }
 
 func (p *Provider) newBlobAction(ref ImageReference, imageID string, desc descriptor, authHeader string) (provider.SyncAction, error) {
@@ -793,12 +823,10 @@ func (p *Provider) newBlobAction(ref ImageReference, imageID string, desc descri
 		algo,
 		hash,
 	))
-	headers := make(map[string]string)
-	if authHeader != "" {
-		headers["Authorization"] = authHeader
-	}
 	expectedChecksum := expectedChecksumForDigest(algo, hash)
-	return p.buildAction(relPath, buildBlobURL(ref, desc.Digest), expectedChecksum, desc.Size, headers), nil
+	action := p.buildAction(relPath, buildBlobURL(ref, desc.Digest), expectedChecksum, desc.Size, map[string]string{})
+	action.Authorize = registryauth.Authorizer(authHeader)
+	return action, nil
 }
 
 func expectedChecksumForDigest(algo, hexPart string) string {
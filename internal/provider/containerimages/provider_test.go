@@ -10,6 +10,7 @@ import (
 	"net/http"
 	"net/http/httptest"
 	"net/url"
+	"os"
 	"path/filepath"
 	"strings"
 	"testing"
@@ -43,6 +44,38 @@ func TestConfigureRejectsInvalidImageReference(t *testing.T) {
 	}
 }
 
+func TestConfigureReadsImagesetConfig(t *testing.T) {
+	dir := t.TempDir()
+	iscPath := filepath.Join(dir, "imageset-config.yaml")
+	isc := `kind: ImageSetConfiguration
+apiVersion: mirror.openshift.io/v2alpha1
+mirror:
+  additionalImages:
+  - name: registry.access.redhat.com/ubi9/ubi:latest
+  - name: quay.io/example/app:1.0
+  blockedImages:
+  - name: quay.io/example/app:1.0
+`
+	if err := os.WriteFile(iscPath, []byte(isc), 0o644); err != nil {
+		t.Fatal(err)
+	}
+
+	p := NewProvider(dir, slog.Default())
+	if err := p.Configure(provider.ProviderConfig{
+		"images":          []interface{}{"docker://registry.access.redhat.com/ubi9/ubi:latest"},
+		"imageset_config": iscPath,
+	}); err != nil {
+		t.Fatalf("configure: %v", err)
+	}
+	if len(p.cfg.Images) != 1 || p.cfg.Images[0] != "docker://registry.access.redhat.com/ubi9/ubi:latest" {
+		t.Fatalf("expected deduplicated images without blocked entries, got %v", p.cfg.Images)
+	}
+
+	if err := p.Configure(provider.ProviderConfig{"imageset_config": filepath.Join(dir, "missing.yaml")}); err == nil {
+		t.Fatal("expected error for missing imageset_config")
+	}
+}
+
 func TestParseImageReferenceDockerHubNormalization(t *testing.T) {
 	ref, err := parseImageReference("docker://docker.io/alpine:latest")
 	if err != nil {
@@ -161,8 +194,16 @@ func TestPlanResolvesManifestsAndBlobs(t *testing.T) {
 		if action.Action != provider.ActionDownload {
 			t.Fatalf("expected action download for new files, got %s", action.Action)
 		}
-		if action.Headers["Authorization"] != "Bearer "+tokenValue {
-			t.Fatalf("expected authorization header, got %q", action.Headers["Authorization"])
+		if _, ok := action.Headers["Authorization"]; ok {
+			t.Fatal("credentials must not be stored in action headers")
+		}
+		if action.Authorize == nil {
+			t.Fatal("expected Authorize hook on action")
+		}
+		req := httptest.NewRequest(http.MethodGet, "https://example.invalid/", nil)
+		action.Authorize(req)
+		if got := req.Header.Get("Authorization"); got != "Bearer "+tokenValue {
+			t.Fatalf("expected authorization header, got %q", got)
 		}
 		if strings.Contains(action.Path, "/manifests/") && action.Headers["Accept"] == "" {
 			t.Fatal("expected Accept header for manifest downloads")
@@ -176,6 +217,215 @@ func TestPlanResolvesManifestsAndBlobs(t *testing.T) {
 	}
 }
 
+func TestPlanPrunesIndexChildrenByPlatform(t *testing.T) {
+	manifestFor := func(arch string) ([]byte, []byte) {
+		cfg := []byte(`{"architecture":"` + arch + `","os":"linux"}`)
+		m, _ := json.Marshal(map[string]interface{}{
+			"schemaVersion": 2,
+			"mediaType":     "application/vnd.oci.image.manifest.v1+json",
+			"config":        map[string]interface{}{"mediaType": "application/vnd.oci.image.config.v1+json", "digest": digestOf(cfg), "size": len(cfg)},
+			"layers":        []map[string]interface{}{},
+		})
+		return m, cfg
+	}
+	amd64, amd64Config := manifestFor("amd64")
+	arm64, _ := manifestFor("arm64")
+	index, _ := json.Marshal(map[string]interface{}{
+		"schemaVersion": 2,
+		"mediaType":     "application/vnd.oci.image.index.v1+json",
+		"manifests": []map[string]interface{}{
+			{"mediaType": "application/vnd.oci.image.manifest.v1+json", "digest": digestOf(amd64), "size": len(amd64),
+				"platform": map[string]string{"os": "linux", "architecture": "amd64"}},
+			{"mediaType": "application/vnd.oci.image.manifest.v1+json", "digest": digestOf(arm64), "size": len(arm64),
+				"platform": map[string]string{"os": "linux", "architecture": "arm64", "variant": "v8"}},
+		},
+	})
+
+	fetchedArm64 := false
+	server := httptest.NewTLSServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		switch r.URL.Path {
+		case "/v2/org/app/manifests/1.0":
+			w.Header().Set("Content-Type", "application/vnd.oci.image.index.v1+json")
+			_, _ = w.Write(index)
+		case "/v2/org/app/manifests/" + digestOf(amd64):
+			w.Header().Set("Content-Type", "application/vnd.oci.image.manifest.v1+json")
+			_, _ = w.Write(amd64)
+		case "/v2/org/app/manifests/" + digestOf(arm64):
+			fetchedArm64 = true
+			w.Header().Set("Content-Type", "application/vnd.oci.image.manifest.v1+json")
+			_, _ = w.Write(arm64)
+		case "/v2/org/app/blobs/" + digestOf(amd64Config):
+			_, _ = w.Write(amd64Config)
+		default:
+			w.WriteHeader(http.StatusNotFound)
+		}
+	}))
+	defer server.Close()
+
+	u, _ := url.Parse(server.URL)
+	p := NewProvider(t.TempDir(), slog.New(slog.NewTextHandler(io.Discard, nil)))
+	p.http = server.Client()
+	if err := p.Configure(provider.ProviderConfig{
+		"images":    []interface{}{"docker://" + u.Host + "/org/app:1.0"},
+		"platforms": []interface{}{"Linux/AMD64"},
+	}); err != nil {
+		t.Fatalf("configure failed: %v", err)
+	}
+
+	plan, err := p.Plan(context.Background())
+	if err != nil {
+		t.Fatalf("plan failed: %v", err)
+	}
+	if fetchedArm64 {
+		t.Fatal("arm64 manifest should not be fetched")
+	}
+	if len(plan.Actions) != 3 {
+		t.Fatalf("expected 3 actions (index, amd64 manifest, config), got %d", len(plan.Actions))
+	}
+	for _, action := range plan.Actions {
+		if strings.Contains(action.Path, strings.TrimPrefix(digestOf(arm64), "sha256:")) {
+			t.Fatalf("unexpected arm64 action %s", action.Path)
+		}
+	}
+
+	if err := p.Configure(provider.ProviderConfig{"platforms": []interface{}{"amd64"}}); err == nil {
+		t.Fatal("expected error for platform without os")
+	}
+}
+
+func TestPlanMirrorsSignaturesAndReferrers(t *testing.T) {
+	manifestWith := func(configBody, layerBody []byte, extra map[string]interface{}) []byte {
+		m := map[string]interface{}{
+			"schemaVersion": 2,
+			"mediaType":     "application/vnd.oci.image.manifest.v1+json",
+			"config":        map[string]interface{}{"mediaType": "application/vnd.oci.image.config.v1+json", "digest": digestOf(configBody), "size": len(configBody)},
+			"layers":        []map[string]interface{}{{"mediaType": "application/vnd.oci.image.layer.v1.tar", "digest": digestOf(layerBody), "size": len(layerBody)}},
+		}
+		for k, v := range extra {
+			m[k] = v
+		}
+		data, _ := json.Marshal(m)
+		return data
+	}
+	imageConfig, imageLayer := []byte(`{"os":"linux"}`), []byte("rootfs")
+	image := manifestWith(imageConfig, imageLayer, nil)
+	imageDigest := digestOf(image)
+	sigConfig, sigLayer := []byte(`{}`), []byte(`{"critical":{}}`)
+	sig := manifestWith(sigConfig, sigLayer, nil)
+	sbomConfig, sbomLayer := []byte(`{"sbom":true}`), []byte(`{"spdxVersion":"SPDX-2.3"}`)
+	sbom := manifestWith(sbomConfig, sbomLayer, map[string]interface{}{
+		"artifactType": "application/spdx+json",
+		"subject":      map[string]interface{}{"mediaType": "application/vnd.oci.image.manifest.v1+json", "digest": imageDigest, "size": len(image)},
+	})
+	referrers, _ := json.Marshal(map[string]interface{}{
+		"schemaVersion": 2,
+		"mediaType":     "application/vnd.oci.image.index.v1+json",
+		"manifests": []map[string]interface{}{
+			{"mediaType": "application/vnd.oci.image.manifest.v1+json", "digest": digestOf(sbom), "size": len(sbom), "artifactType": "application/spdx+json"},
+		},
+	})
+	sigTag := "sha256-" + strings.TrimPrefix(imageDigest, "sha256:") + ".sig"
+
+	blobs := map[string][]byte{}
+	for _, b := range [][]byte{imageConfig, imageLayer, sigConfig, sigLayer, sbomConfig, sbomLayer} {
+		blobs[digestOf(b)] = b
+	}
+	manifests := map[string][]byte{"1.0": image, imageDigest: image, sigTag: sig, digestOf(sbom): sbom}
+
+	server := httptest.NewTLSServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		switch {
+		case r.URL.Path == "/v2/org/app/referrers/"+imageDigest:
+			w.Header().Set("Content-Type", "application/vnd.oci.image.index.v1+json")
+			_, _ = w.Write(referrers)
+		case strings.HasPrefix(r.URL.Path, "/v2/org/app/manifests/"):
+			body, ok := manifests[strings.TrimPrefix(r.URL.Path, "/v2/org/app/manifests/")]
+			if !ok {
+				w.WriteHeader(http.StatusNotFound)
+				return
+			}
+			w.Header().Set("Content-Type", "application/vnd.oci.image.manifest.v1+json")
+			_, _ = w.Write(body)
+		case strings.HasPrefix(r.URL.Path, "/v2/org/app/blobs/"):
+			body, ok := blobs[strings.TrimPrefix(r.URL.Path, "/v2/org/app/blobs/")]
+			if !ok {
+				w.WriteHeader(http.StatusNotFound)
+				return
+			}
+			_, _ = w.Write(body)
+		default:
+			w.WriteHeader(http.StatusNotFound)
+		}
+	}))
+	defer server.Close()
+
+	dataDir := t.TempDir()
+	u, _ := url.Parse(server.URL)
+	p := NewProvider(dataDir, slog.New(slog.NewTextHandler(io.Discard, nil)))
+	p.http = server.Client()
+	if err := p.Configure(provider.ProviderConfig{
+		"images":    []interface{}{"docker://" + u.Host + "/org/app:1.0"},
+		"referrers": true,
+	}); err != nil {
+		t.Fatalf("configure failed: %v", err)
+	}
+
+	plan, err := p.Plan(context.Background())
+	if err != nil {
+		t.Fatalf("plan failed: %v", err)
+	}
+	if len(plan.Actions) != 9 {
+		t.Fatalf("expected 9 actions (3 manifests + 6 blobs), got %d", len(plan.Actions))
+	}
+
+	gen := p.GeneratedFiles()
+	if len(gen) != 1 || !strings.HasSuffix(gen[0].Path, "/"+ArtifactsFile) {
+		t.Fatalf("expected one generated artifacts file, got %+v", gen)
+	}
+	if entries, _ := os.ReadDir(p.dataDir); len(entries) != 0 {
+		t.Fatalf("expected Plan not to write the artifacts file, got %v", entries)
+	}
+	imageRoot := filepath.Join(p.dataDir, p.Name(), filepath.Dir(gen[0].Path))
+	if err := os.MkdirAll(imageRoot, 0o755); err != nil {
+		t.Fatal(err)
+	}
+	if err := os.WriteFile(filepath.Join(imageRoot, ArtifactsFile), gen[0].Data, 0o644); err != nil {
+		t.Fatal(err)
+	}
+	artifacts, err := ReadImageArtifacts(imageRoot)
+	if err != nil {
+		t.Fatalf("ReadImageArtifacts: %v", err)
+	}
+	if artifacts.Tags[sigTag] != digestOf(sig) {
+		t.Fatalf("expected signature tag %s -> %s, got %v", sigTag, digestOf(sig), artifacts.Tags)
+	}
+	if len(artifacts.Referrers) != 1 || artifacts.Referrers[0].Subject != imageDigest || artifacts.Referrers[0].ArtifactType != "application/spdx+json" {
+		t.Fatalf("unexpected referrers: %+v", artifacts.Referrers)
+	}
+	if got := artifacts.Digests(); len(got) != 2 {
+		t.Fatalf("expected 2 artifact digests, got %v", got)
+	}
+}
+
+func TestMatchPlatform(t *testing.T) {
+	tests := []struct {
+		filters           []string
+		os, arch, variant string
+		want              bool
+	}{
+		{nil, "linux", "s390x", "", true},
+		{[]string{"linux/amd64"}, "linux", "amd64", "", true},
+		{[]string{"linux/amd64"}, "linux", "arm64", "v8", false},
+		{[]string{"linux/arm64"}, "linux", "arm64", "v8", true},
+		{[]string{"linux/arm/v7"}, "linux", "arm", "v6", false},
+		{[]string{"linux/arm/v7"}, "linux", "arm", "v7", true},
+	}
+	for _, tt := range tests {
+		if got := MatchPlatform(tt.filters, tt.os, tt.arch, tt.variant); got != tt.want {
+			t.Errorf("MatchPlatform(%v, %s/%s/%s) = %v, want %v", tt.filters, tt.os, tt.arch, tt.variant, got, tt.want)
+		}
+	}
+}
+
 func digestOf(data []byte) string {
 	sum := sha256.Sum256(data)
 	return "sha256:" + hex.EncodeToString(sum[:])
@@ -0,0 +1,226 @@
+package containerimages
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"net/http"
+	"os"
+	"path/filepath"
+	"sort"
+	"strings"
+
+	"github.com/BadgerOps/airgap/internal/provider"
+	"github.com/BadgerOps/airgap/internal/safety"
+)
+
+// ArtifactsFile is written in an image directory when signatures or
+// referrers were mirrored for it.
+const ArtifactsFile = "artifacts.json"
+
+const referrersAcceptHeader = "application/vnd.oci.image.index.v1+json"
+
+// cosignSuffixes are the tag suffixes cosign uses for signatures,
+// attestations and SBOMs attached to sha256-<hex>.
+var cosignSuffixes = []string{".sig", ".att", ".sbom"}
+
+// ImageArtifacts lists the signature and referrer manifests mirrored next
+// to an image. Their manifests and blobs live in the image directory.
+type ImageArtifacts struct {
+	// Tags maps cosign tags (sha256-<hex>.sig, .att, .sbom) to manifest digests.
+	Tags map[string]string `json:"tags,omitempty"`
+	// Referrers are OCI 1.1 referrers of the image's manifests.
+	Referrers []Referrer `json:"referrers,omitempty"`
+}
+
+// Referrer is a manifest whose subject is one of the image's manifests.
+type Referrer struct {
+	Subject      string `json:"subject"`
+	Digest       string `json:"digest"`
+	MediaType    string `json:"mediaType"`
+	ArtifactType string `json:"artifactType,omitempty"`
+	Size         int64  `json:"size"`
+}
+
+// Digests returns every artifact manifest digest, sorted.
+func (a *ImageArtifacts) Digests() []string {
+	seen := make(map[string]struct{})
+	var out []string
+	add := func(d string) {
+		if _, ok := seen[d]; ok || d == "" {
+			return
+		}
+		seen[d] = struct{}{}
+		out = append(out, d)
+	}
+	for _, d := range a.Tags {
+		add(d)
+	}
+	for _, r := range a.Referrers {
+		add(r.Digest)
+	}
+	sort.Strings(out)
+	return out
+}
+
+// ReadImageArtifacts reads the artifacts file of an image directory. A
+// missing file yields an empty list.
+func ReadImageArtifacts(imageRoot string) (*ImageArtifacts, error) {
+	data, err := os.ReadFile(filepath.Join(imageRoot, ArtifactsFile))
+	if os.IsNotExist(err) {
+		return &ImageArtifacts{}, nil
+	}
+	if err != nil {
+		return nil, fmt.Errorf("reading %s: %w", ArtifactsFile, err)
+	}
+	var a ImageArtifacts
+	if err := json.Unmarshal(data, &a); err != nil {
+		return nil, fmt.Errorf("parsing %s: %w", ArtifactsFile, err)
+	}
+	return &a, nil
+}
+
+// registryError is a non-2xx registry response.
+type registryError struct {
+	StatusCode int
+	Status     string
+	Body       string
+}
+
+func (e *registryError) Error() string {
+	return fmt.Sprintf("registry returned %s: %s", e.Status, e.Body)
+}
+
+func isNotFound(err error) bool {
+	var re *registryError
+	return errors.As(err, &re) && re.StatusCode == http.StatusNotFound
+}
+
+// planArtifacts discovers cosign signatures and OCI referrers of every
+// manifest in g, plans their manifests and blobs, and records them in the
+// image's artifacts file, which the engine writes after the sync. Lookup failures are logged and skipped so a
+// registry without signatures never blocks the image itself.
+func (p *Provider) planArtifacts(ctx context.Context, g *manifestGraph) error {
+	artifacts := &ImageArtifacts{Tags: make(map[string]string)}
+	subjects := append([]string(nil), g.manifests...)
+
+	for _, subject := range subjects {
+		algo, hash, err := parseDigest(subject)
+		if err != nil {
+			continue
+		}
+		for _, suffix := range cosignSuffixes {
+			tag := algo + "-" + hash + suffix
+			desc, body, auth, err := p.fetchManifest(ctx, g.ref, tag)
+			if err != nil {
+				if !isNotFound(err) {
+					p.logger.Warn("cosign artifact lookup failed", "image", g.ref.Raw, "tag", tag, "error", err)
+				}
+				continue
+			}
+			if err := p.walkManifests(ctx, g, desc, body, auth); err != nil {
+				return err
+			}
+			artifacts.Tags[tag] = desc.Digest
+		}
+
+		referrers, err := p.listReferrers(ctx, g.ref, subject)
+		if err != nil {
+			p.logger.Warn("referrers lookup failed", "image", g.ref.Raw, "subject", subject, "error", err)
+			continue
+		}
+		for _, r := range referrers {
+			if _, ok := g.seenManifest[r.Digest]; !ok {
+				desc, body, auth, err := p.fetchManifest(ctx, g.ref, r.Digest)
+				if err != nil {
+					p.logger.Warn("fetching referrer failed", "image", g.ref.Raw, "digest", r.Digest, "error", err)
+					continue
+				}
+				if err := p.walkManifests(ctx, g, desc, body, auth); err != nil {
+					return err
+				}
+			}
+			artifacts.Referrers = append(artifacts.Referrers, r)
+		}
+	}
+
+	return p.planImageArtifacts(g, artifacts)
+}
+
+// listReferrers queries the OCI 1.1 referrers API, falling back to the
+// sha256-<hex> referrers tag for registries that do not implement it.
+func (p *Provider) listReferrers(ctx context.Context, ref ImageReference, subject string) ([]Referrer, error) {
+	scope := fmt.Sprintf("repository:%s:pull", ref.Repository)
+	endpoint := fmt.Sprintf("https://%s/v2/%s/referrers/%s", ref.EndpointHost, strings.Trim(ref.Repository, "/"), subject)
+	body, _, _, err := p.registryGET(ctx, ref, endpoint, referrersAcceptHeader, scope)
+	if isNotFound(err) {
+		algo, hash, _ := parseDigest(subject)
+		body, _, _, err = p.registryGET(ctx, ref, buildManifestURL(ref, algo+"-"+hash), referrersAcceptHeader, scope)
+		if isNotFound(err) {
+			return nil, nil
+		}
+	}
+	if err != nil {
+		return nil, err
+	}
+
+	var idx struct {
+		Manifests []struct {
+			MediaType    string `json:"mediaType"`
+			Digest       string `json:"digest"`
+			Size         int64  `json:"size"`
+			ArtifactType string `json:"artifactType"`
+		} `json:"manifests"`
+	}
+	if err := json.Unmarshal(body, &idx); err != nil {
+		return nil, fmt.Errorf("parsing referrers index: %w", err)
+	}
+	out := make([]Referrer, 0, len(idx.Manifests))
+	for _, m := range idx.Manifests {
+		if _, _, err := parseDigest(m.Digest); err != nil {
+			continue
+		}
+		out = append(out, Referrer{
+			Subject:      subject,
+			Digest:       m.Digest,
+			MediaType:    m.MediaType,
+			ArtifactType: m.ArtifactType,
+			Size:         m.Size,
+		})
+	}
+	return out, nil
+}
+
+// planImageArtifacts adds the artifacts file of the image in g to the
+// generated files, or plans the removal of a stale one when nothing was
+// found.
+func (p *Provider) planImageArtifacts(g *manifestGraph, artifacts *ImageArtifacts) error {
+	relPath := filepath.ToSlash(filepath.Join(p.cfg.OutputDir, g.imageID, ArtifactsFile))
+	localPath, err := safety.SafeJoinUnder(filepath.Join(p.dataDir, p.Name()), relPath)
+	if err != nil {
+		return fmt.Errorf("invalid artifacts path: %w", err)
+	}
+	if len(artifacts.Tags) == 0 && len(artifacts.Referrers) == 0 {
+		if _, err := os.Stat(localPath); err == nil {
+			g.actions = append(g.actions, provider.SyncAction{
+				Path:      relPath,
+				LocalPath: localPath,
+				Action:    provider.ActionDelete,
+				Reason:    "no signatures or referrers upstream",
+			})
+		}
+		return nil
+	}
+
+	data, err := json.MarshalIndent(artifacts, "", "  ")
+	if err != nil {
+		return fmt.Errorf("marshaling %s: %w", ArtifactsFile, err)
+	}
+	p.generated = append(p.generated, provider.GeneratedFile{
+		Path:   relPath,
+		Data:   data,
+		Reason: "image signatures and referrers",
+	})
+	return nil
+}
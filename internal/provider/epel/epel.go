@@ -16,10 +16,12 @@ import (
 	"strings"
 	"time"
 
+	"github.com/ProtonMail/go-crypto/openpgp"
 	"github.com/klauspost/compress/zstd"
 	"github.com/ulikunitz/xz"
 
 	"github.com/BadgerOps/airgap/internal/config"
+	"github.com/BadgerOps/airgap/internal/gpg"
 	"github.com/BadgerOps/airgap/internal/provider"
 	"github.com/BadgerOps/airgap/internal/safety"
 )
@@ -31,6 +33,8 @@ type EPELProvider struct {
 	dataDir            string
 	logger             *slog.Logger
 	ValidationProgress provider.ValidationProgressFn
+	keyrings           map[string]openpgp.EntityList // trusted keys by repo name
+	planFailures       []provider.FailedFile
 }
 
 const (
@@ -72,12 +76,28 @@ func (p *EPELProvider) Configure(rawCfg provider.ProviderConfig) error {
 	if err != nil {
 		return fmt.Errorf("parsing EPEL config: %w", err)
 	}
-	p.cfg = cfg
-	for _, repo := range p.cfg.Repos {
+	keyrings := make(map[string]openpgp.EntityList)
+	for _, repo := range cfg.Repos {
 		if _, err := safety.ValidateHTTPURL(repo.BaseURL); err != nil {
 			return fmt.Errorf("invalid base_url for repo %q: %w", repo.Name, err)
 		}
+		if !repo.GPGCheck && !repo.RepoGPGCheck {
+			continue
+		}
+		if len(repo.GPGKeys) == 0 {
+			return fmt.Errorf("repo %q: gpg_keys are required with gpgcheck or repo_gpgcheck", repo.Name)
+		}
+		ring, err := gpg.LoadKeyring(repo.GPGKeys)
+		if err != nil {
+			return fmt.Errorf("repo %q: %w", repo.Name, err)
+		}
+		if len(ring) == 0 {
+			return fmt.Errorf("repo %q: gpg_keys contain no keys", repo.Name)
+		}
+		keyrings[repo.Name] = ring
 	}
+	p.cfg = cfg
+	p.keyrings = keyrings
 
 	p.logger.Debug("configured EPEL provider",
 		slog.Int("repos", len(p.cfg.Repos)),
@@ -100,6 +120,7 @@ func (p *EPELProvider) Plan(ctx context.Context) (*provider.SyncPlan, error) {
 		Actions:   []provider.SyncAction{},
 		Timestamp: time.Now(),
 	}
+	p.planFailures = nil
 
 	for _, repo := range p.cfg.Repos {
 		p.logger.Debug("planning sync for repo",
@@ -152,6 +173,15 @@ func (p *EPELProvider) planRepo(ctx context.Context, repo config.EPELRepoConfig)
 		return nil, fmt.Errorf("fetching repomd.xml: %w", err)
 	}
 
+	if repo.RepoGPGCheck {
+		if err := p.verifyRepomd(ctx, repo, repomdResult.Data); err != nil {
+			// Drop the cached copy so the next run refetches it
+			_ = os.Remove(repomdCachePath)
+			p.recordPlanFailure("repodata/repomd.xml", repomdURL, err)
+			return nil, err
+		}
+	}
+
 	// Parse repomd.xml
 	repomd, err := ParseRepomd(repomdResult.Data)
 	if err != nil {
@@ -200,6 +230,14 @@ func (p *EPELProvider) planRepo(ctx context.Context, repo config.EPELRepoConfig)
 		}
 	}
 
+	if repo.RepoGPGCheck {
+		if err := verifyMetadataChecksum(primaryRepomdData(repomd), primaryGzData); err != nil {
+			_ = os.Remove(primaryCachePath)
+			p.recordPlanFailure(filepath.ToSlash(primaryLocation), primaryURL, err)
+			return nil, err
+		}
+	}
+
 	// Decompress primary metadata (may be .gz, .xz, or already decompressed)
 	primaryData, err := p.decompress(primaryGzData)
 	if err != nil {
@@ -462,6 +500,22 @@ func (p *EPELProvider) Validate(ctx context.Context) (*provider.ValidationReport
 		if err != nil {
 			return nil, fmt.Errorf("fetching repomd.xml for validation: %w", err)
 		}
+		if repo.RepoGPGCheck {
+			if err := p.verifyRepomd(ctx, repo, repomdResult.Data); err != nil {
+				// Without trusted metadata the packages cannot be checked
+				_ = os.Remove(repomdCachePath)
+				report.TotalFiles++
+				report.InvalidFiles = append(report.InvalidFiles, provider.ValidationResult{
+					Path:      "repodata/repomd.xml",
+					LocalPath: repomdCachePath,
+					Actual:    "error: " + err.Error(),
+					Valid:     false,
+					URL:       repomdURL,
+					Reason:    err.Error(),
+				})
+				continue
+			}
+		}
 
 		repomd, err := ParseRepomd(repomdResult.Data)
 		if err != nil {
@@ -495,6 +549,21 @@ func (p *EPELProvider) Validate(ctx context.Context) (*provider.ValidationReport
 				return nil, fmt.Errorf("fetching primary metadata for validation: %w", err)
 			}
 		}
+		if repo.RepoGPGCheck {
+			if err := verifyMetadataChecksum(primaryRepomdData(repomd), primaryGzData); err != nil {
+				_ = os.Remove(primaryCachePath)
+				report.TotalFiles++
+				report.InvalidFiles = append(report.InvalidFiles, provider.ValidationResult{
+					Path:      filepath.ToSlash(primaryLocation),
+					LocalPath: primaryCachePath,
+					Actual:    "error: " + err.Error(),
+					Valid:     false,
+					URL:       primaryURL,
+					Reason:    err.Error(),
+				})
+				continue
+			}
+		}
 
 		primaryData, err := p.decompress(primaryGzData)
 		if err != nil {
@@ -592,12 +661,37 @@ func (p *EPELProvider) Validate(ctx context.Context) (*provider.ValidationReport
 				continue
 			}
 
-			if actualHash == pkg.Checksum {
+			valid := actualHash == pkg.Checksum
+			if valid && repo.GPGCheck {
+				keyID, sigErr := verifyRPMSignature(p.keyrings[repo.Name], localPath)
+				if sigErr != nil {
+					valid = false
+					p.logger.Warn("validation: package signature rejected",
+						slog.String("file", relPath),
+						slog.String("error", sigErr.Error()))
+					report.InvalidFiles = append(report.InvalidFiles, provider.ValidationResult{
+						Path:      relPath,
+						LocalPath: localPath,
+						Expected:  pkg.Checksum,
+						Actual:    "signature: " + sigErr.Error(),
+						Valid:     false,
+						Size:      info.Size(),
+						URL:       downloadURL,
+						Reason:    "signature: " + sigErr.Error(),
+					})
+				} else {
+					p.logger.Debug("validation: signature OK",
+						slog.String("file", relPath),
+						slog.String("key_id", keyID))
+				}
+			}
+
+			if valid {
 				p.logger.Debug("validation: checksum OK",
 					slog.String("file", relPath),
 					slog.String("checksum", actualHash[:12]+"..."))
 				report.ValidFiles++
-			} else {
+			} else if actualHash != pkg.Checksum {
 				p.logger.Debug("validation: checksum MISMATCH",
 					slog.String("file", relPath),
 					slog.String("expected", pkg.Checksum),
@@ -614,7 +708,7 @@ func (p *EPELProvider) Validate(ctx context.Context) (*provider.ValidationReport
 			}
 
 			if p.ValidationProgress != nil {
-				p.ValidationProgress(i+1, len(packages), relPath, actualHash == pkg.Checksum)
+				p.ValidationProgress(i+1, len(packages), relPath, valid)
 			}
 		}
 	}
@@ -837,6 +931,50 @@ func (p *EPELProvider) decompress(data []byte) ([]byte, error) {
 	return data, nil
 }
 
+// LocalPackages lists the packages in the repository metadata mirrored under
+// repoDir.
+func LocalPackages(repoDir string, logger *slog.Logger) ([]PackageInfo, error) {
+	repomdPath, err := safety.SafeJoinUnder(repoDir, filepath.Join("repodata", "repomd.xml"))
+	if err != nil {
+		return nil, fmt.Errorf("invalid repomd path: %w", err)
+	}
+	repomdData, err := os.ReadFile(repomdPath)
+	if err != nil {
+		return nil, fmt.Errorf("reading repomd.xml: %w", err)
+	}
+	repomd, err := ParseRepomd(repomdData)
+	if err != nil {
+		return nil, err
+	}
+	primaryLocation, err := repomd.FindPrimaryLocation()
+	if err != nil {
+		return nil, fmt.Errorf("finding primary location: %w", err)
+	}
+	primaryLocation, err = safety.CleanRelativePath(primaryLocation)
+	if err != nil {
+		return nil, fmt.Errorf("unsafe primary location in repomd metadata: %w", err)
+	}
+	primaryPath, err := safety.SafeJoinUnder(repoDir, primaryLocation)
+	if err != nil {
+		return nil, fmt.Errorf("unsafe primary metadata path: %w", err)
+	}
+	primaryGzData, err := os.ReadFile(primaryPath)
+	if err != nil {
+		return nil, fmt.Errorf("reading primary metadata: %w", err)
+	}
+
+	p := &EPELProvider{logger: logger}
+	primaryData, err := p.decompress(primaryGzData)
+	if err != nil {
+		return nil, fmt.Errorf("decompressing primary metadata: %w", err)
+	}
+	primaryXML, err := ParsePrimary(primaryData)
+	if err != nil {
+		return nil, err
+	}
+	return primaryXML.ExtractPackages(), nil
+}
+
 // checksumLocalFile computes the SHA256 checksum of a local file
 func checksumLocalFile(path string) (string, error) {
 	file, err := os.Open(path)
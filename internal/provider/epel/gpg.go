@@ -0,0 +1,67 @@
+package epel
+
+import (
+	"context"
+	"crypto/sha256"
+	"crypto/sha512"
+	"encoding/hex"
+	"fmt"
+	"log/slog"
+	"strings"
+
+	"github.com/BadgerOps/airgap/internal/config"
+	"github.com/BadgerOps/airgap/internal/gpg"
+	"github.com/BadgerOps/airgap/internal/provider"
+)
+
+// verifyRepomd checks repomd.xml against the repo's detached
+// repodata/repomd.xml.asc signature.
+func (p *EPELProvider) verifyRepomd(ctx context.Context, repo config.EPELRepoConfig, repomd []byte) error {
+	sigURL := strings.TrimRight(repo.BaseURL, "/") + "/repodata/repomd.xml.asc"
+	sig, err := p.fetchURL(ctx, sigURL)
+	if err != nil {
+		return fmt.Errorf("repomd.xml is not signed (fetching repomd.xml.asc: %v)", err)
+	}
+	keyID, err := gpg.VerifyDetached(p.keyrings[repo.Name], repomd, sig)
+	if err != nil {
+		return fmt.Errorf("repomd.xml signature: %w", err)
+	}
+	p.logger.Info("repomd signature verified",
+		slog.String("repo", repo.Name),
+		slog.String("key_id", keyID))
+	return nil
+}
+
+// verifyMetadataChecksum checks downloaded metadata against its repomd
+// checksum, extending the repomd signature to it.
+func verifyMetadataChecksum(data RepomdData, content []byte) error {
+	var sum []byte
+	switch strings.ToLower(data.Checksum.Type) {
+	case "sha256":
+		h := sha256.Sum256(content)
+		sum = h[:]
+	case "sha512":
+		h := sha512.Sum512(content)
+		sum = h[:]
+	default:
+		return fmt.Errorf("unsupported %s checksum type %q", data.Type, data.Checksum.Type)
+	}
+	if hex.EncodeToString(sum) != strings.TrimSpace(data.Checksum.Value) {
+		return fmt.Errorf("%s metadata does not match the signed repomd.xml checksum", data.Type)
+	}
+	return nil
+}
+
+// recordPlanFailure reports content rejected while planning.
+func (p *EPELProvider) recordPlanFailure(path, url string, err error) {
+	p.planFailures = append(p.planFailures, provider.FailedFile{
+		Path:  path,
+		URL:   url,
+		Error: err.Error(),
+	})
+}
+
+// PlanFailures returns the metadata rejected by the last Plan.
+func (p *EPELProvider) PlanFailures() []provider.FailedFile {
+	return p.planFailures
+}
@@ -0,0 +1,334 @@
+package epel
+
+import (
+	"bytes"
+	"compress/gzip"
+	"context"
+	"crypto/sha256"
+	"encoding/binary"
+	"encoding/hex"
+	"errors"
+	"fmt"
+	"io"
+	"log/slog"
+	"net/http"
+	"net/http/httptest"
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+
+	"github.com/ProtonMail/go-crypto/openpgp"
+	"github.com/ProtonMail/go-crypto/openpgp/armor"
+	"github.com/ProtonMail/go-crypto/openpgp/packet"
+
+	"github.com/BadgerOps/airgap/internal/provider"
+)
+
+func newTestKey(t *testing.T) (*openpgp.Entity, string) {
+	t.Helper()
+	e, err := openpgp.NewEntity("Test Repo", "", "repo@example.com", &packet.Config{Algorithm: packet.PubKeyAlgoEdDSA})
+	if err != nil {
+		t.Fatalf("NewEntity: %v", err)
+	}
+	var buf bytes.Buffer
+	w, err := armor.Encode(&buf, openpgp.PublicKeyType, nil)
+	if err != nil {
+		t.Fatalf("armor.Encode: %v", err)
+	}
+	if err := e.Serialize(w); err != nil {
+		t.Fatalf("Serialize: %v", err)
+	}
+	_ = w.Close()
+	return e, buf.String()
+}
+
+type rpmTestEntry struct {
+	tag, typ uint32
+	data     []byte
+	count    uint32
+}
+
+// rpmTestHeader encodes a header structure. Entries are stored in order, so
+// INT32 entries must come first to stay aligned.
+func rpmTestHeader(entries []rpmTestEntry) []byte {
+	var index, store bytes.Buffer
+	for _, e := range entries {
+		_ = binary.Write(&index, binary.BigEndian, []uint32{e.tag, e.typ, uint32(store.Len()), e.count})
+		store.Write(e.data)
+	}
+	var h bytes.Buffer
+	h.Write(rpmHeaderMagic)
+	_ = binary.Write(&h, binary.BigEndian, []uint32{uint32(len(entries)), uint32(store.Len())})
+	h.Write(index.Bytes())
+	h.Write(store.Bytes())
+	return h.Bytes()
+}
+
+// buildTestRPM writes a minimal package whose main header carries a SHA-256
+// payload digest, signed by signer when it is not nil.
+func buildTestRPM(t *testing.T, signer *openpgp.Entity, payload []byte) []byte {
+	t.Helper()
+	digest := sha256.Sum256(payload)
+	algo := make([]byte, 4)
+	binary.BigEndian.PutUint32(algo, 8)
+	main := rpmTestHeader([]rpmTestEntry{
+		{tag: rpmTagPayloadDigestAlgo, typ: rpmTypeInt32, data: algo, count: 1},
+		{tag: rpmTagPayloadDigest, typ: rpmTypeStringArray, data: []byte(hex.EncodeToString(digest[:]) + "\x00"), count: 1},
+	})
+
+	sigEntries := []rpmTestEntry{
+		{tag: 1000, typ: rpmTypeInt32, data: make([]byte, 4), count: 1},
+	}
+	if signer != nil {
+		var sig bytes.Buffer
+		if err := openpgp.DetachSign(&sig, signer, bytes.NewReader(main), nil); err != nil {
+			t.Fatalf("DetachSign: %v", err)
+		}
+		sigEntries = append(sigEntries, rpmTestEntry{tag: rpmSigTagRSA, typ: rpmTypeBin, data: sig.Bytes(), count: uint32(sig.Len())})
+	}
+	sigHeader := rpmTestHeader(sigEntries)
+
+	var out bytes.Buffer
+	lead := make([]byte, rpmLeadSize)
+	copy(lead, rpmLeadMagic)
+	out.Write(lead)
+	out.Write(sigHeader)
+	out.Write(make([]byte, (8-len(sigHeader)%8)%8))
+	out.Write(main)
+	out.Write(payload)
+	return out.Bytes()
+}
+
+func writeTestFile(t *testing.T, data []byte) string {
+	t.Helper()
+	path := filepath.Join(t.TempDir(), "test.rpm")
+	if err := os.WriteFile(path, data, 0644); err != nil {
+		t.Fatal(err)
+	}
+	return path
+}
+
+func TestVerifyRPMSignature(t *testing.T) {
+	key, _ := newTestKey(t)
+	other, _ := newTestKey(t)
+	ring := openpgp.EntityList{key}
+	payload := []byte("cpio payload")
+
+	keyID, err := verifyRPMSignature(ring, writeTestFile(t, buildTestRPM(t, key, payload)))
+	if err != nil {
+		t.Fatalf("signed package rejected: %v", err)
+	}
+	if keyID != key.PrimaryKey.KeyIdString() {
+		t.Errorf("key ID = %s, want %s", keyID, key.PrimaryKey.KeyIdString())
+	}
+
+	tampered := buildTestRPM(t, key, payload)
+	tampered[len(tampered)-1] ^= 0xff
+	if _, err := verifyRPMSignature(ring, writeTestFile(t, tampered)); err == nil || !strings.Contains(err.Error(), "payload digest mismatch") {
+		t.Errorf("tampered payload: err = %v", err)
+	}
+
+	if _, err := verifyRPMSignature(ring, writeTestFile(t, buildTestRPM(t, nil, payload))); !errors.Is(err, errRPMUnsigned) {
+		t.Errorf("unsigned package: err = %v, want errRPMUnsigned", err)
+	}
+
+	if _, err := verifyRPMSignature(ring, writeTestFile(t, buildTestRPM(t, other, payload))); err == nil || !strings.Contains(err.Error(), "not in gpg_keys") {
+		t.Errorf("unknown key: err = %v", err)
+	}
+
+	if _, err := verifyRPMSignature(ring, writeTestFile(t, []byte("not a package"))); err == nil {
+		t.Error("expected non-RPM file to be rejected")
+	}
+}
+
+// signedTestRepo serves a one-package repository. repomd.xml is signed by
+// signer, or left unsigned when signer is nil.
+type signedTestRepo struct {
+	repomd, sig, primary, rpm []byte
+}
+
+func newSignedTestRepo(t *testing.T, signer *openpgp.Entity, rpm []byte) *signedTestRepo {
+	t.Helper()
+	rpmSum := sha256.Sum256(rpm)
+	primaryXML := fmt.Sprintf(`<?xml version="1.0" encoding="UTF-8"?>
+<metadata packages="1">
+<package type="rpm">
+  <name>test</name><arch>noarch</arch>
+  <version epoch="0" ver="1.0" rel="1"/>
+  <checksum type="sha256" pkgid="YES">%s</checksum>
+  <size package="%d" installed="0"/>
+  <location href="Packages/t/test-1.0-1.noarch.rpm"/>
+</package>
+</metadata>`, hex.EncodeToString(rpmSum[:]), len(rpm))
+	var gz bytes.Buffer
+	zw := gzip.NewWriter(&gz)
+	_, _ = zw.Write([]byte(primaryXML))
+	_ = zw.Close()
+
+	primarySum := sha256.Sum256(gz.Bytes())
+	repomd := fmt.Sprintf(`<?xml version="1.0" encoding="UTF-8"?>
+<repomd>
+  <data type="primary">
+    <checksum type="sha256">%s</checksum>
+    <location href="repodata/primary.xml.gz"/>
+    <size>%d</size>
+  </data>
+</repomd>`, hex.EncodeToString(primarySum[:]), gz.Len())
+
+	repo := &signedTestRepo{repomd: []byte(repomd), primary: gz.Bytes(), rpm: rpm}
+	if signer != nil {
+		var sig bytes.Buffer
+		if err := openpgp.ArmoredDetachSign(&sig, signer, bytes.NewReader(repo.repomd), nil); err != nil {
+			t.Fatalf("ArmoredDetachSign: %v", err)
+		}
+		repo.sig = sig.Bytes()
+	}
+	return repo
+}
+
+func (r *signedTestRepo) serve(t *testing.T) string {
+	t.Helper()
+	srv := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, req *http.Request) {
+		switch req.URL.Path {
+		case "/repodata/repomd.xml":
+			_, _ = w.Write(r.repomd)
+		case "/repodata/repomd.xml.asc":
+			if r.sig == nil {
+				http.NotFound(w, req)
+				return
+			}
+			_, _ = w.Write(r.sig)
+		case "/repodata/primary.xml.gz":
+			_, _ = w.Write(r.primary)
+		case "/Packages/t/test-1.0-1.noarch.rpm":
+			_, _ = w.Write(r.rpm)
+		default:
+			http.NotFound(w, req)
+		}
+	}))
+	t.Cleanup(srv.Close)
+	return srv.URL
+}
+
+func newSignedTestProvider(t *testing.T, baseURL, armoredKey string, repoCheck, pkgCheck bool) *EPELProvider {
+	t.Helper()
+	p := NewEPELProvider(t.TempDir(), slog.New(slog.NewTextHandler(io.Discard, nil)))
+	err := p.Configure(provider.ProviderConfig{
+		"repos": []interface{}{
+			map[string]interface{}{
+				"name":          "test",
+				"base_url":      baseURL,
+				"output_dir":    "epel/test",
+				"gpg_keys":      []interface{}{armoredKey},
+				"repo_gpgcheck": repoCheck,
+				"gpgcheck":      pkgCheck,
+			},
+		},
+	})
+	if err != nil {
+		t.Fatalf("Configure: %v", err)
+	}
+	return p
+}
+
+func TestConfigureRequiresGPGKeys(t *testing.T) {
+	p := NewEPELProvider(t.TempDir(), slog.New(slog.NewTextHandler(io.Discard, nil)))
+	err := p.Configure(provider.ProviderConfig{
+		"repos": []interface{}{
+			map[string]interface{}{"name": "test", "base_url": "https://example.com/epel", "gpgcheck": true},
+		},
+	})
+	if err == nil || !strings.Contains(err.Error(), "gpg_keys") {
+		t.Fatalf("err = %v, want missing gpg_keys error", err)
+	}
+}
+
+func TestPlanVerifiesRepomdSignature(t *testing.T) {
+	key, armored := newTestKey(t)
+	other, _ := newTestKey(t)
+	rpm := buildTestRPM(t, key, []byte("payload"))
+	tampered := newSignedTestRepo(t, key, rpm)
+	tampered.primary = append(append([]byte(nil), tampered.primary...), 0)
+
+	tests := []struct {
+		name        string
+		repo        *signedTestRepo
+		wantActions int
+		wantFailure string
+	}{
+		{name: "trusted signature", repo: newSignedTestRepo(t, key, rpm), wantActions: 1},
+		{name: "unknown key", repo: newSignedTestRepo(t, other, rpm), wantFailure: "repodata/repomd.xml"},
+		{name: "unsigned", repo: newSignedTestRepo(t, nil, rpm), wantFailure: "repodata/repomd.xml"},
+		{name: "primary not matching repomd", repo: tampered, wantFailure: "repodata/primary.xml.gz"},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			p := newSignedTestProvider(t, tt.repo.serve(t), armored, true, false)
+			plan, err := p.Plan(context.Background())
+			if err != nil {
+				t.Fatalf("Plan: %v", err)
+			}
+			if len(plan.Actions) != tt.wantActions {
+				t.Errorf("actions = %d, want %d", len(plan.Actions), tt.wantActions)
+			}
+			failures := p.PlanFailures()
+			if tt.wantFailure == "" {
+				if len(failures) != 0 {
+					t.Errorf("unexpected plan failures: %+v", failures)
+				}
+				return
+			}
+			if len(failures) != 1 || failures[0].Path != tt.wantFailure || failures[0].Error == "" {
+				t.Errorf("plan failures = %+v, want one for %s", failures, tt.wantFailure)
+			}
+		})
+	}
+}
+
+func TestValidateChecksPackageSignatures(t *testing.T) {
+	key, armored := newTestKey(t)
+	other, _ := newTestKey(t)
+
+	tests := []struct {
+		name       string
+		signer     *openpgp.Entity
+		wantReason string
+	}{
+		{name: "trusted", signer: key},
+		{name: "unknown key", signer: other, wantReason: "signature: signed by a key that is not in gpg_keys"},
+		{name: "unsigned", signer: nil, wantReason: "signature: package is not signed"},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			repo := newSignedTestRepo(t, key, buildTestRPM(t, tt.signer, []byte("payload")))
+			p := newSignedTestProvider(t, repo.serve(t), armored, true, true)
+
+			pkgPath := filepath.Join(p.dataDir, "epel/test/Packages/t/test-1.0-1.noarch.rpm")
+			if err := os.MkdirAll(filepath.Dir(pkgPath), 0755); err != nil {
+				t.Fatal(err)
+			}
+			if err := os.WriteFile(pkgPath, repo.rpm, 0644); err != nil {
+				t.Fatal(err)
+			}
+
+			report, err := p.Validate(context.Background())
+			if err != nil {
+				t.Fatalf("Validate: %v", err)
+			}
+			if tt.wantReason == "" {
+				if report.ValidFiles != 1 || len(report.InvalidFiles) != 0 {
+					t.Errorf("valid = %d, invalid = %+v", report.ValidFiles, report.InvalidFiles)
+				}
+				return
+			}
+			if report.ValidFiles != 0 || len(report.InvalidFiles) != 1 {
+				t.Fatalf("valid = %d, invalid = %+v", report.ValidFiles, report.InvalidFiles)
+			}
+			if got := report.InvalidFiles[0].Reason; got != tt.wantReason {
+				t.Errorf("reason = %q, want %q", got, tt.wantReason)
+			}
+		})
+	}
+}
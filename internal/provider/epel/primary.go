@@ -53,6 +53,7 @@ type Location struct {
 type PackageInfo struct {
 	Name     string
 	Arch     string
+	Epoch    string
 	Version  string
 	Release  string
 	Checksum string
@@ -84,6 +85,7 @@ func (p *PrimaryXML) ExtractPackages() []PackageInfo {
 		packages = append(packages, PackageInfo{
 			Name:     pkg.Name,
 			Arch:     pkg.Arch,
+			Epoch:    pkg.Version.Epoch,
 			Version:  pkg.Version.Ver,
 			Release:  pkg.Version.Rel,
 			Checksum: pkg.Checksum.Value,
@@ -93,3 +95,12 @@ func (p *PrimaryXML) ExtractPackages() []PackageInfo {
 	}
 	return packages
 }
+
+// EVR returns the package's [epoch:]version-release, omitting a zero epoch.
+func (p PackageInfo) EVR() string {
+	evr := p.Version + "-" + p.Release
+	if p.Epoch != "" && p.Epoch != "0" {
+		evr = p.Epoch + ":" + evr
+	}
+	return evr
+}
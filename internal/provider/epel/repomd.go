@@ -57,6 +57,16 @@ func (r *RepomdXML) FindPrimaryLocation() (string, error) {
 	return "", fmt.Errorf("primary data not found in repomd.xml")
 }
 
+// primaryRepomdData returns the primary data entry, or an empty one.
+func primaryRepomdData(r *RepomdXML) RepomdData {
+	for _, data := range r.Data {
+		if data.Type == "primary" {
+			return data
+		}
+	}
+	return RepomdData{Type: "primary"}
+}
+
 // FindPrimaryChecksum finds the checksum of primary.xml.gz in the repomd data
 func (r *RepomdXML) FindPrimaryChecksum() (string, error) {
 	for _, data := range r.Data {
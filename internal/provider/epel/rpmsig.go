@@ -0,0 +1,213 @@
+package epel
+
+import (
+	"bufio"
+	"bytes"
+	"crypto"
+	"encoding/binary"
+	"encoding/hex"
+	"errors"
+	"fmt"
+	"io"
+	"os"
+
+	"github.com/ProtonMail/go-crypto/openpgp"
+
+	"github.com/BadgerOps/airgap/internal/gpg"
+)
+
+const (
+	rpmLeadSize       = 96
+	rpmMaxIndex       = 0x10000
+	rpmMaxHeaderStore = 256 * 1024 * 1024
+
+	rpmTypeInt32       = 4
+	rpmTypeString      = 6
+	rpmTypeBin         = 7
+	rpmTypeStringArray = 8
+
+	// Signature header tags. DSA and RSA sign the main header only; PGP and
+	// GPG (legacy) sign the main header and payload.
+	rpmSigTagDSA = 267
+	rpmSigTagRSA = 268
+	rpmSigTagPGP = 1002
+	rpmSigTagGPG = 1005
+
+	rpmTagPayloadDigest     = 5092
+	rpmTagPayloadDigestAlgo = 5093
+)
+
+var (
+	rpmLeadMagic   = []byte{0xed, 0xab, 0xee, 0xdb}
+	rpmHeaderMagic = []byte{0x8e, 0xad, 0xe8, 0x01, 0, 0, 0, 0}
+
+	// rpmDigestAlgos maps RPM (OpenPGP) hash algorithm IDs to hashes.
+	rpmDigestAlgos = map[uint32]crypto.Hash{
+		2:  crypto.SHA1,
+		8:  crypto.SHA256,
+		9:  crypto.SHA384,
+		10: crypto.SHA512,
+		11: crypto.SHA224,
+	}
+
+	errRPMUnsigned = errors.New("package is not signed")
+)
+
+type rpmEntry struct {
+	typ, offset, count uint32
+}
+
+// rpmHeader is a parsed RPM header structure. raw holds its bytes from the
+// magic through the data store, which is what header signatures cover.
+type rpmHeader struct {
+	raw     []byte
+	entries map[uint32]rpmEntry
+	store   []byte
+}
+
+func readRPMHeader(r io.Reader) (*rpmHeader, error) {
+	intro := make([]byte, 16)
+	if _, err := io.ReadFull(r, intro); err != nil {
+		return nil, fmt.Errorf("reading header: %w", err)
+	}
+	if !bytes.Equal(intro[:8], rpmHeaderMagic) {
+		return nil, fmt.Errorf("bad header magic")
+	}
+	nindex := binary.BigEndian.Uint32(intro[8:])
+	hsize := binary.BigEndian.Uint32(intro[12:])
+	if nindex == 0 || nindex > rpmMaxIndex || hsize > rpmMaxHeaderStore {
+		return nil, fmt.Errorf("header size out of range (%d entries, %d bytes)", nindex, hsize)
+	}
+	rest := make([]byte, int(nindex)*16+int(hsize))
+	if _, err := io.ReadFull(r, rest); err != nil {
+		return nil, fmt.Errorf("reading header: %w", err)
+	}
+
+	h := &rpmHeader{
+		raw:     append(intro, rest...),
+		entries: make(map[uint32]rpmEntry, nindex),
+		store:   rest[int(nindex)*16:],
+	}
+	for i := 0; i < int(nindex); i++ {
+		e := rest[i*16 : i*16+16]
+		tag := binary.BigEndian.Uint32(e)
+		entry := rpmEntry{
+			typ:    binary.BigEndian.Uint32(e[4:]),
+			offset: binary.BigEndian.Uint32(e[8:]),
+			count:  binary.BigEndian.Uint32(e[12:]),
+		}
+		if entry.offset > hsize {
+			return nil, fmt.Errorf("header tag %d points outside the data store", tag)
+		}
+		h.entries[tag] = entry
+	}
+	return h, nil
+}
+
+// bin returns a BIN tag's bytes.
+func (h *rpmHeader) bin(tag uint32) ([]byte, bool) {
+	e, ok := h.entries[tag]
+	if !ok || e.typ != rpmTypeBin || uint64(e.offset)+uint64(e.count) > uint64(len(h.store)) {
+		return nil, false
+	}
+	return h.store[e.offset : e.offset+e.count], true
+}
+
+// str returns a STRING tag or the first element of a STRING_ARRAY tag.
+func (h *rpmHeader) str(tag uint32) (string, bool) {
+	e, ok := h.entries[tag]
+	if !ok || (e.typ != rpmTypeString && e.typ != rpmTypeStringArray) {
+		return "", false
+	}
+	end := bytes.IndexByte(h.store[e.offset:], 0)
+	if end < 0 {
+		return "", false
+	}
+	return string(h.store[e.offset : int(e.offset)+end]), true
+}
+
+// int32 returns the first value of an INT32 tag.
+func (h *rpmHeader) int32(tag uint32) (uint32, bool) {
+	e, ok := h.entries[tag]
+	if !ok || e.typ != rpmTypeInt32 || e.count == 0 || uint64(e.offset)+4 > uint64(len(h.store)) {
+		return 0, false
+	}
+	return binary.BigEndian.Uint32(h.store[e.offset:]), true
+}
+
+// verifyRPMSignature checks the OpenPGP signature of an RPM against ring and
+// returns the signing key ID. The header-only (RSA/DSA) signature is used
+// with the header's payload digest; packages without one fall back to the
+// legacy header+payload signature.
+func verifyRPMSignature(ring openpgp.EntityList, path string) (string, error) {
+	f, err := os.Open(path)
+	if err != nil {
+		return "", err
+	}
+	defer func() {
+		_ = f.Close()
+	}()
+	r := bufio.NewReaderSize(f, 1<<20)
+
+	lead := make([]byte, rpmLeadSize)
+	if _, err := io.ReadFull(r, lead); err != nil || !bytes.Equal(lead[:4], rpmLeadMagic) {
+		return "", fmt.Errorf("not an RPM package")
+	}
+	sigHeader, err := readRPMHeader(r)
+	if err != nil {
+		return "", fmt.Errorf("signature header: %w", err)
+	}
+	// The signature header is padded to a multiple of 8 bytes.
+	if pad := (8 - len(sigHeader.raw)%8) % 8; pad > 0 {
+		if _, err := io.CopyN(io.Discard, r, int64(pad)); err != nil {
+			return "", fmt.Errorf("signature header: %w", err)
+		}
+	}
+	header, err := readRPMHeader(r)
+	if err != nil {
+		return "", fmt.Errorf("main header: %w", err)
+	}
+
+	headerSig, ok := sigHeader.bin(rpmSigTagRSA)
+	if !ok {
+		headerSig, ok = sigHeader.bin(rpmSigTagDSA)
+	}
+	digest, hasDigest := header.str(rpmTagPayloadDigest)
+	if ok && hasDigest {
+		keyID, err := checkRPMSignature(ring, bytes.NewReader(header.raw), headerSig)
+		if err != nil {
+			return "", err
+		}
+		algo, _ := header.int32(rpmTagPayloadDigestAlgo)
+		hash, known := rpmDigestAlgos[algo]
+		if !known || !hash.Available() {
+			return "", fmt.Errorf("unsupported payload digest algorithm %d", algo)
+		}
+		hw := hash.New()
+		if _, err := io.Copy(hw, r); err != nil {
+			return "", fmt.Errorf("reading payload: %w", err)
+		}
+		if hex.EncodeToString(hw.Sum(nil)) != digest {
+			return "", fmt.Errorf("payload digest mismatch")
+		}
+		return keyID, nil
+	}
+
+	legacySig, ok := sigHeader.bin(rpmSigTagPGP)
+	if !ok {
+		legacySig, ok = sigHeader.bin(rpmSigTagGPG)
+	}
+	if !ok {
+		return "", errRPMUnsigned
+	}
+	return checkRPMSignature(ring, io.MultiReader(bytes.NewReader(header.raw), r), legacySig)
+}
+
+// checkRPMSignature verifies a binary OpenPGP signature packet over signed.
+func checkRPMSignature(ring openpgp.EntityList, signed io.Reader, sig []byte) (string, error) {
+	signer, err := openpgp.CheckDetachedSignature(ring, signed, bytes.NewReader(sig), nil)
+	if err != nil {
+		return "", gpg.DescribeError(err)
+	}
+	return signer.PrimaryKey.KeyIdString(), nil
+}
@@ -0,0 +1,107 @@
+package ocp
+
+import (
+	"fmt"
+	"path"
+	"regexp"
+	"strings"
+
+	"github.com/BadgerOps/airgap/internal/safety"
+)
+
+// archPlaceholder is replaced by each architecture in a base_url template.
+const archPlaceholder = "{arch}"
+
+var (
+	archNameRegexp    = regexp.MustCompile(`^[a-z0-9_]+$`)
+	archSegmentRegexp = regexp.MustCompile(`/(x86_64|aarch64|ppc64le|s390x|multi)/`)
+	archAliases       = map[string]string{"amd64": "x86_64", "arm64": "aarch64"}
+)
+
+// archTarget is one architecture to mirror: its expanded base URL and the
+// architecture directory under output_dir. Arch is empty for the
+// single-architecture <output_dir>/<version> layout.
+type archTarget struct {
+	Arch    string
+	BaseURL string
+}
+
+// dir returns the directory of version relative to output_dir.
+func (t archTarget) dir(version string) string {
+	if t.Arch == "" {
+		return version
+	}
+	return path.Join(t.Arch, version)
+}
+
+// NormalizeArchitectures lower-cases and de-duplicates mirror architecture
+// names, mapping the Go names amd64 and arm64 to x86_64 and aarch64.
+func NormalizeArchitectures(raw []string) ([]string, error) {
+	seen := make(map[string]struct{})
+	out := make([]string, 0, len(raw))
+	for _, r := range raw {
+		arch := strings.ToLower(strings.TrimSpace(r))
+		if arch == "" {
+			continue
+		}
+		if alias, ok := archAliases[arch]; ok {
+			arch = alias
+		}
+		if !archNameRegexp.MatchString(arch) {
+			return nil, fmt.Errorf("invalid architecture %q", r)
+		}
+		if _, ok := seen[arch]; ok {
+			continue
+		}
+		seen[arch] = struct{}{}
+		out = append(out, arch)
+	}
+	return out, nil
+}
+
+// graphArch returns the update graph name of mirror architecture arch:
+// amd64 and arm64 for x86_64 and aarch64, others unchanged. An empty arch
+// stays empty, selecting the graph API's default.
+func graphArch(arch string) string {
+	for graph, mirror := range archAliases {
+		if mirror == arch {
+			return graph
+		}
+	}
+	return arch
+}
+
+// expandArchitectures returns the targets for baseURL. Each architecture
+// replaces {arch} in the URL, or else its architecture path segment (such as
+// /x86_64/). Without architectures the URL is used as-is.
+func expandArchitectures(baseURL string, arches []string) ([]archTarget, error) {
+	if len(arches) == 0 {
+		if strings.Contains(baseURL, archPlaceholder) {
+			return nil, fmt.Errorf("base_url contains %s but no architectures are set", archPlaceholder)
+		}
+		if _, err := safety.ValidateHTTPURL(baseURL); err != nil {
+			return nil, fmt.Errorf("invalid base_url: %w", err)
+		}
+		return []archTarget{{BaseURL: baseURL}}, nil
+	}
+
+	hasPlaceholder := strings.Contains(baseURL, archPlaceholder)
+	if !hasPlaceholder && archSegmentRegexp.FindStringIndex(baseURL) == nil {
+		return nil, fmt.Errorf("base_url must contain %s or an architecture path segment to expand architectures", archPlaceholder)
+	}
+	targets := make([]archTarget, 0, len(arches))
+	for _, arch := range arches {
+		var expanded string
+		if hasPlaceholder {
+			expanded = strings.ReplaceAll(baseURL, archPlaceholder, arch)
+		} else {
+			loc := archSegmentRegexp.FindStringIndex(baseURL)
+			expanded = baseURL[:loc[0]] + "/" + arch + "/" + baseURL[loc[1]:]
+		}
+		if _, err := safety.ValidateHTTPURL(expanded); err != nil {
+			return nil, fmt.Errorf("invalid base_url for %s: %w", arch, err)
+		}
+		targets = append(targets, archTarget{Arch: arch, BaseURL: expanded})
+	}
+	return targets, nil
+}
@@ -0,0 +1,156 @@
+package ocp
+
+import (
+	"context"
+	"fmt"
+	"net/http"
+	"net/http/httptest"
+	"os"
+	"path/filepath"
+	"testing"
+
+	"github.com/BadgerOps/airgap/internal/provider"
+)
+
+func TestNormalizeArchitectures(t *testing.T) {
+	got, err := NormalizeArchitectures([]string{"amd64", " x86_64 ", "ARM64", "", "s390x"})
+	if err != nil {
+		t.Fatalf("NormalizeArchitectures() failed: %v", err)
+	}
+	want := []string{"x86_64", "aarch64", "s390x"}
+	if fmt.Sprint(got) != fmt.Sprint(want) {
+		t.Errorf("NormalizeArchitectures() = %v, want %v", got, want)
+	}
+
+	if _, err := NormalizeArchitectures([]string{"x86_64/../.."}); err == nil {
+		t.Error("expected invalid architecture to be rejected")
+	}
+}
+
+func TestExpandArchitectures(t *testing.T) {
+	tests := []struct {
+		name    string
+		baseURL string
+		arches  []string
+		want    []string
+		wantErr bool
+	}{
+		{
+			name:    "no architectures",
+			baseURL: "https://mirror.example.com/pub/openshift-v4/x86_64/clients/ocp",
+			want:    []string{"https://mirror.example.com/pub/openshift-v4/x86_64/clients/ocp"},
+		},
+		{
+			name:    "placeholder",
+			baseURL: "https://mirror.example.com/pub/openshift-v4/{arch}/clients/ocp",
+			arches:  []string{"x86_64", "aarch64"},
+			want: []string{
+				"https://mirror.example.com/pub/openshift-v4/x86_64/clients/ocp",
+				"https://mirror.example.com/pub/openshift-v4/aarch64/clients/ocp",
+			},
+		},
+		{
+			name:    "architecture segment",
+			baseURL: "https://mirror.example.com/pub/openshift-v4/x86_64/dependencies/rhcos",
+			arches:  []string{"ppc64le"},
+			want:    []string{"https://mirror.example.com/pub/openshift-v4/ppc64le/dependencies/rhcos"},
+		},
+		{
+			name:    "nothing to expand",
+			baseURL: "https://mirror.example.com/ocp",
+			arches:  []string{"aarch64"},
+			wantErr: true,
+		},
+		{
+			name:    "placeholder without architectures",
+			baseURL: "https://mirror.example.com/{arch}/ocp",
+			wantErr: true,
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			targets, err := expandArchitectures(tt.baseURL, tt.arches)
+			if tt.wantErr {
+				if err == nil {
+					t.Fatal("expected error")
+				}
+				return
+			}
+			if err != nil {
+				t.Fatalf("expandArchitectures() failed: %v", err)
+			}
+			var got []string
+			for _, target := range targets {
+				got = append(got, target.BaseURL)
+			}
+			if fmt.Sprint(got) != fmt.Sprint(tt.want) {
+				t.Errorf("expandArchitectures() = %v, want %v", got, tt.want)
+			}
+		})
+	}
+}
+
+func TestBinariesProviderPlanArchitectures(t *testing.T) {
+	x86Content := []byte("oc-x86_64")
+	armContent := []byte("oc-aarch64")
+
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		switch r.URL.Path {
+		case "/x86_64/clients/ocp/4.18.1/sha256sum.txt":
+			fmt.Fprintf(w, "%s  openshift-client-linux.tar.gz\n", computeSHA256(x86Content))
+		case "/aarch64/clients/ocp/4.18.1/sha256sum.txt":
+			fmt.Fprintf(w, "%s  openshift-client-linux.tar.gz\n", computeSHA256(armContent))
+		default:
+			http.NotFound(w, r)
+		}
+	}))
+	defer server.Close()
+
+	dataDir := t.TempDir()
+	p := NewBinariesProvider(dataDir, testLogger())
+	err := p.Configure(provider.ProviderConfig{
+		"base_url":      server.URL + "/{arch}/clients/ocp",
+		"versions":      []interface{}{"4.18.1"},
+		"architectures": []interface{}{"amd64", "aarch64"},
+		"output_dir":    "ocp",
+	})
+	if err != nil {
+		t.Fatalf("Configure() failed: %v", err)
+	}
+
+	armPath := filepath.Join(dataDir, "ocp", "aarch64", "4.18.1", "openshift-client-linux.tar.gz")
+	if err := os.MkdirAll(filepath.Dir(armPath), 0o755); err != nil {
+		t.Fatal(err)
+	}
+	if err := os.WriteFile(armPath, armContent, 0o644); err != nil {
+		t.Fatal(err)
+	}
+
+	plan, err := p.Plan(context.Background())
+	if err != nil {
+		t.Fatalf("Plan() failed: %v", err)
+	}
+	byPath := make(map[string]provider.SyncAction)
+	for _, a := range plan.Actions {
+		byPath[a.Path] = a
+	}
+	x86 := byPath["x86_64/4.18.1/openshift-client-linux.tar.gz"]
+	if x86.Action != provider.ActionDownload || x86.URL != server.URL+"/x86_64/clients/ocp/4.18.1/openshift-client-linux.tar.gz" {
+		t.Errorf("unexpected x86_64 action: %+v", x86)
+	}
+	if arm := byPath["aarch64/4.18.1/openshift-client-linux.tar.gz"]; arm.Action != provider.ActionSkip {
+		t.Errorf("expected current aarch64 file to be skipped, got %+v", arm)
+	}
+
+	report, err := p.Validate(context.Background())
+	if err != nil {
+		t.Fatalf("Validate() failed: %v", err)
+	}
+	if report.TotalFiles != 2 || report.ValidFiles != 1 {
+		t.Errorf("unexpected validation report: total=%d valid=%d", report.TotalFiles, report.ValidFiles)
+	}
+	if len(report.InvalidFiles) != 1 || report.InvalidFiles[0].Path != "x86_64/4.18.1/openshift-client-linux.tar.gz" {
+		t.Errorf("unexpected invalid files: %+v", report.InvalidFiles)
+	}
+}
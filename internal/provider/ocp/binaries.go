@@ -2,14 +2,17 @@ package ocp
 
 import (
 	"context"
+	"errors"
 	"fmt"
 	"log/slog"
-	"os"
-	"path/filepath"
+	"path"
 	"strings"
 	"time"
 
+	"github.com/ProtonMail/go-crypto/openpgp"
+
 	"github.com/BadgerOps/airgap/internal/config"
+	ocpsvc "github.com/BadgerOps/airgap/internal/ocp"
 	"github.com/BadgerOps/airgap/internal/provider"
 	"github.com/BadgerOps/airgap/internal/safety"
 )
@@ -21,6 +24,14 @@ type BinariesProvider struct {
 	dataDir              string
 	logger               *slog.Logger
 	validationProgressFn provider.ValidationProgressFn
+	targets              []archTarget
+	keyring              openpgp.EntityList // trusted sha256sum.txt signers, nil when unchecked
+	exprs                []versionExpr
+	failures             []provider.FailedFile
+	// releasesFn lists the releases of an update channel for a graph
+	// architecture; it defaults to the Cincinnati graph via
+	// ocp.ClientService.
+	releasesFn func(ctx context.Context, channel, arch string) ([]string, error)
 }
 
 // SetValidationProgress sets the callback for per-file validation progress.
@@ -57,13 +68,45 @@ func (p *BinariesProvider) Configure(rawCfg provider.ProviderConfig) error {
 	if err != nil {
 		return fmt.Errorf("parsing OCP binaries config: %w", err)
 	}
+	arches, err := NormalizeArchitectures(cfg.Architectures)
+	if err != nil {
+		return err
+	}
+	cfg.Architectures = arches
+	targets, err := expandArchitectures(cfg.BaseURL, cfg.Architectures)
+	if err != nil {
+		return err
+	}
+	exprs := make([]versionExpr, 0, len(cfg.Versions))
+	for _, raw := range cfg.Versions {
+		expr, err := parseVersionExpr(raw)
+		if err != nil {
+			return err
+		}
+		exprs = append(exprs, expr)
+	}
+	keyring, err := loadChecksumKeyring(cfg.GPGKeys)
+	if err != nil {
+		return err
+	}
 	p.cfg = cfg
-	if _, err := safety.ValidateHTTPURL(p.cfg.BaseURL); err != nil {
-		return fmt.Errorf("invalid base_url: %w", err)
+	p.targets = targets
+	p.exprs = exprs
+	p.keyring = keyring
+	if p.releasesFn == nil {
+		clients := ocpsvc.NewClientService(p.logger)
+		p.releasesFn = func(ctx context.Context, channel, arch string) ([]string, error) {
+			result, err := clients.FetchArchReleases(ctx, channel, arch)
+			if err != nil {
+				return nil, err
+			}
+			return result.Releases, nil
+		}
 	}
 
 	p.logger.Debug("configured OCP binaries provider",
 		slog.String("base_url", p.cfg.BaseURL),
+		slog.String("architectures", strings.Join(p.cfg.Architectures, ",")),
 		slog.Int("versions", len(p.cfg.Versions)),
 		slog.String("output_dir", p.cfg.OutputDir),
 	)
@@ -81,52 +124,58 @@ func (p *BinariesProvider) Plan(ctx context.Context) (*provider.SyncPlan, error)
 		Provider:  p.Name(),
 		Actions:   []provider.SyncAction{},
 		Timestamp: time.Now(),
+		Resolved:  make(map[string][]string),
+		// Plan fails below unless every sha256sum.txt verifies.
+		SignatureStatus: signatureStatus(p.keyring),
 	}
 
-	for _, version := range p.cfg.Versions {
-		p.logger.Debug("planning sync for version",
-			slog.String("version", version))
-
-		checksumURL := fmt.Sprintf("%s/%s/sha256sum.txt", strings.TrimRight(p.cfg.BaseURL, "/"), version)
+	outputRoot, err := safety.SafeJoinUnder(p.dataDir, p.cfg.OutputDir)
+	if err != nil {
+		return nil, fmt.Errorf("invalid output directory %q: %w", p.cfg.OutputDir, err)
+	}
 
-		// Fetch checksum file
-		checksumData, err := p.fetchChecksumFile(ctx, checksumURL)
-		if err != nil {
-			p.logger.Error("failed to fetch checksum file",
-				slog.String("version", version),
-				slog.String("url", checksumURL),
-				slog.String("error", err.Error()))
-			continue
-		}
+	p.failures = nil
+	for _, target := range p.targets {
+		versions, failures := p.resolveVersions(ctx, target, plan.Resolved)
+		p.failures = append(p.failures, failures...)
+		for _, version := range versions {
+			p.logger.Debug("planning sync for version",
+				slog.String("arch", target.Arch),
+				slog.String("version", version))
+
+			files, err := p.versionFiles(ctx, target, version)
+			if errors.Is(err, errChecksumSignature) {
+				return nil, fmt.Errorf("version %s: %w", target.dir(version), err)
+			}
+			if err != nil {
+				p.logger.Error("failed to fetch checksum file",
+					slog.String("arch", target.Arch),
+					slog.String("version", version),
+					slog.String("error", err.Error()))
+				p.failures = append(p.failures, provider.FailedFile{
+					Path:  path.Join(target.dir(version), "sha256sum.txt"),
+					Error: err.Error(),
+				})
+				continue
+			}
 
-		// Parse checksum file
-		remoteFiles := parseChecksumFile(checksumData)
-		p.logger.Debug("parsed checksum file",
-			slog.String("version", version),
-			slog.Int("files", len(remoteFiles)))
-
-		// Filter by ignored patterns
-		filteredFiles := filterFiles(remoteFiles, p.cfg.IgnoredPatterns)
-		p.logger.Debug("filtered files",
-			slog.String("version", version),
-			slog.Int("before", len(remoteFiles)),
-			slog.Int("after", len(filteredFiles)))
-
-		// Build sync plan for this version
-		versionActions, err := buildSyncPlan(p.Name(), p.cfg.BaseURL, version, p.cfg.OutputDir, p.dataDir, filteredFiles, p.logger)
-		if err != nil {
-			p.logger.Error("failed to build sync plan for version",
-				slog.String("version", version),
-				slog.String("error", err.Error()))
-			continue
-		}
+			// Build sync plan for this version
+			versionActions, err := buildFileActions(p.Name(), outputRoot, files, p.logger)
+			if err != nil {
+				p.logger.Error("failed to build sync plan for version",
+					slog.String("arch", target.Arch),
+					slog.String("version", version),
+					slog.String("error", err.Error()))
+				continue
+			}
 
-		// Aggregate actions and calculate totals
-		for _, action := range versionActions {
-			plan.Actions = append(plan.Actions, action)
-			plan.TotalFiles++
-			if action.Action == provider.ActionDownload || action.Action == provider.ActionUpdate {
-				plan.TotalSize += action.Size
+			// Aggregate actions and calculate totals
+			for _, action := range versionActions {
+				plan.Actions = append(plan.Actions, action)
+				plan.TotalFiles++
+				if action.Action == provider.ActionDownload || action.Action == provider.ActionUpdate {
+					plan.TotalSize += action.Size
+				}
 			}
 		}
 	}
@@ -204,126 +253,28 @@ func (p *BinariesProvider) Validate(ctx context.Context) (*provider.ValidationRe
 		return nil, fmt.Errorf("invalid output_dir %q: %w", p.cfg.OutputDir, err)
 	}
 
-	checked := 0
-
-	for _, version := range p.cfg.Versions {
-		checksumURL := fmt.Sprintf("%s/%s/sha256sum.txt", strings.TrimRight(p.cfg.BaseURL, "/"), version)
-
-		checksumData, err := p.fetchChecksumFile(ctx, checksumURL)
-		if err != nil {
-			p.logger.Warn("failed to fetch checksum file for validation",
-				slog.String("version", version),
-				slog.String("url", checksumURL),
-				slog.String("error", err.Error()))
-			continue
-		}
-
-		remoteFiles := parseChecksumFile(checksumData)
-		filteredFiles := filterFiles(remoteFiles, p.cfg.IgnoredPatterns)
-
-		versionDir, err := safety.SafeJoinUnder(outputRoot, version)
-		if err != nil {
-			return nil, fmt.Errorf("invalid version %q: %w", version, err)
-		}
-
-		for filename, expectedHash := range filteredFiles {
-			localPath, pathErr := safety.SafeJoinUnder(versionDir, filename)
-			if pathErr != nil {
-				report.TotalFiles++
-				report.InvalidFiles = append(report.InvalidFiles, provider.ValidationResult{
-					Path:     filepath.ToSlash(filepath.Join(version, filename)),
-					Expected: expectedHash,
-					Actual:   "error: unsafe path: " + pathErr.Error(),
-					Valid:    false,
-				})
-				checked++
-				if p.validationProgressFn != nil {
-					p.validationProgressFn(checked, report.TotalFiles, filepath.ToSlash(filepath.Join(version, filename)), false)
-				}
-				continue
-			}
-
-			relPath, err := filepath.Rel(outputRoot, localPath)
-			if err != nil {
-				return nil, fmt.Errorf("building relative path for %q: %w", filename, err)
-			}
-			relPath = filepath.ToSlash(relPath)
-
-			downloadURL := fmt.Sprintf("%s/%s/%s", strings.TrimRight(p.cfg.BaseURL, "/"), version, filename)
-			report.TotalFiles++
-
-			// Check if file exists
-			fileInfo, statErr := os.Stat(localPath)
-			if os.IsNotExist(statErr) {
-				report.InvalidFiles = append(report.InvalidFiles, provider.ValidationResult{
-					Path:      relPath,
-					LocalPath: localPath,
-					Expected:  expectedHash,
-					Actual:    "missing",
-					Valid:     false,
-					URL:       downloadURL,
-				})
-				checked++
-				if p.validationProgressFn != nil {
-					p.validationProgressFn(checked, report.TotalFiles, relPath, false)
-				}
-				continue
+	v := &fileValidator{report: report, outputRoot: outputRoot, progressFn: p.validationProgressFn}
+	for _, target := range p.targets {
+		versions, _ := p.resolveVersions(ctx, target, nil)
+		for _, version := range versions {
+			if _, err := safety.SafeJoinUnder(outputRoot, target.dir(version)); err != nil {
+				return nil, fmt.Errorf("invalid version %q: %w", version, err)
 			}
-			if statErr != nil {
-				report.InvalidFiles = append(report.InvalidFiles, provider.ValidationResult{
-					Path:      relPath,
-					LocalPath: localPath,
-					Expected:  expectedHash,
-					Actual:    "error: " + statErr.Error(),
-					Valid:     false,
-					URL:       downloadURL,
-				})
-				checked++
-				if p.validationProgressFn != nil {
-					p.validationProgressFn(checked, report.TotalFiles, relPath, false)
-				}
+			files, err := p.versionFiles(ctx, target, version)
+			if errors.Is(err, errChecksumSignature) {
+				versionURL := fmt.Sprintf("%s/%s", strings.TrimRight(target.BaseURL, "/"), version)
+				v.rejectManifest(target.dir(version), versionURL+"/sha256sum.txt", err)
 				continue
 			}
-
-			// Compute checksum and compare
-			actualHash, hashErr := checksumLocalFile(localPath)
-			if hashErr != nil {
-				report.InvalidFiles = append(report.InvalidFiles, provider.ValidationResult{
-					Path:      relPath,
-					LocalPath: localPath,
-					Expected:  expectedHash,
-					Actual:    "error: " + hashErr.Error(),
-					Valid:     false,
-					Size:      fileInfo.Size(),
-					URL:       downloadURL,
-				})
-				checked++
-				if p.validationProgressFn != nil {
-					p.validationProgressFn(checked, report.TotalFiles, relPath, false)
-				}
+			if err != nil {
+				p.logger.Warn("failed to fetch checksum file for validation",
+					slog.String("arch", target.Arch),
+					slog.String("version", version),
+					slog.String("error", err.Error()))
 				continue
 			}
-
-			if actualHash == expectedHash {
-				report.ValidFiles++
-				checked++
-				if p.validationProgressFn != nil {
-					p.validationProgressFn(checked, report.TotalFiles, relPath, true)
-				}
-			} else {
-				report.InvalidFiles = append(report.InvalidFiles, provider.ValidationResult{
-					Path:      relPath,
-					LocalPath: localPath,
-					Expected:  expectedHash,
-					Actual:    actualHash,
-					Valid:     false,
-					Size:      fileInfo.Size(),
-					URL:       downloadURL,
-				})
-				checked++
-				if p.validationProgressFn != nil {
-					p.validationProgressFn(checked, report.TotalFiles, relPath, false)
-				}
+			for _, f := range files {
+				v.validate(f)
 			}
 		}
 	}
@@ -337,12 +288,97 @@ func (p *BinariesProvider) Validate(ctx context.Context) (*provider.ValidationRe
 	return report, nil
 }
 
-// fetchChecksumFile downloads a sha256sum.txt file from the given URL.
+// resolveVersions expands the configured version expressions for target into
+// de-duplicated concrete versions. Expressions that fail to resolve or match
+// no releases are logged and returned as failures. When resolved is non-nil,
+// each non-literal expression's result is recorded in it.
+func (p *BinariesProvider) resolveVersions(ctx context.Context, target archTarget, resolved map[string][]string) ([]string, []provider.FailedFile) {
+	r := &versionResolver{baseURL: target.BaseURL, arch: graphArch(target.Arch), releases: p.releasesFn}
+	seen := make(map[string]struct{})
+	var versions []string
+	var failures []provider.FailedFile
+	for _, expr := range p.exprs {
+		key := expr.raw
+		if target.Arch != "" {
+			key = fmt.Sprintf("%s (%s)", expr.raw, target.Arch)
+		}
+		matched, err := r.resolve(ctx, expr)
+		if err != nil {
+			p.logger.Error("failed to resolve version expression",
+				slog.String("arch", target.Arch),
+				slog.String("expression", expr.raw),
+				slog.String("error", err.Error()))
+			failures = append(failures, provider.FailedFile{
+				Path:  key,
+				Error: fmt.Sprintf("resolving version expression: %v", err),
+			})
+			continue
+		}
+		if expr.literal == "" {
+			if resolved != nil {
+				resolved[key] = matched
+			}
+			p.logger.Info("resolved version expression",
+				slog.String("arch", target.Arch),
+				slog.String("expression", expr.raw),
+				slog.String("versions", strings.Join(matched, ",")))
+			if len(matched) == 0 {
+				p.logger.Warn("version expression matched no releases",
+					slog.String("arch", target.Arch),
+					slog.String("expression", expr.raw))
+				failures = append(failures, provider.FailedFile{
+					Path:  key,
+					Error: fmt.Sprintf("version expression matched no releases at %s", target.BaseURL),
+				})
+			}
+		}
+		for _, v := range matched {
+			if _, ok := seen[v]; ok {
+				continue
+			}
+			seen[v] = struct{}{}
+			versions = append(versions, v)
+		}
+	}
+	return versions, failures
+}
+
+// PlanFailures returns the version expressions and checksum files the last
+// Plan could not resolve or fetch.
+func (p *BinariesProvider) PlanFailures() []provider.FailedFile {
+	return p.failures
+}
+
+// fetchChecksumFile downloads a sha256sum.txt file from the given URL and,
+// with gpg_keys configured, verifies its detached signature.
 func (p *BinariesProvider) fetchChecksumFile(ctx context.Context, url string) ([]byte, error) {
 	data, err := fetchWithStatusOK(ctx, url)
 	if err != nil {
 		return nil, fmt.Errorf("fetching checksum file: %w", err)
 	}
+	if p.keyring != nil {
+		if err := verifyChecksumSignature(ctx, url, data, p.keyring, p.logger); err != nil {
+			return nil, err
+		}
+	}
 
 	return data, nil
 }
+
+// versionFiles fetches sha256sum.txt of version for target and returns the
+// files not matching ignored_patterns.
+func (p *BinariesProvider) versionFiles(ctx context.Context, target archTarget, version string) ([]remoteFile, error) {
+	versionURL := fmt.Sprintf("%s/%s", strings.TrimRight(target.BaseURL, "/"), version)
+	checksumData, err := p.fetchChecksumFile(ctx, versionURL+"/sha256sum.txt")
+	if err != nil {
+		return nil, err
+	}
+	remoteFiles := parseChecksumFile(checksumData)
+	filteredFiles := filterFiles(remoteFiles, p.cfg.IgnoredPatterns)
+	p.logger.Debug("filtered files",
+		slog.String("arch", target.Arch),
+		slog.String("version", version),
+		slog.Int("before", len(remoteFiles)),
+		slog.Int("after", len(filteredFiles)))
+	return checksumFiles(versionURL, target.dir(version), filteredFiles), nil
+}
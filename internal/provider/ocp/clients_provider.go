@@ -5,6 +5,7 @@ import (
 	"fmt"
 	"log/slog"
 	"os"
+	"path"
 	"path/filepath"
 	"time"
 
@@ -24,6 +25,8 @@ type ClientsProvider struct {
 	logger               *slog.Logger
 	clientSvc            *ocpsvc.ClientService
 	validationProgressFn provider.ValidationProgressFn
+	generated            []provider.GeneratedFile
+	failures             []provider.FailedFile
 }
 
 // SetValidationProgress sets the callback for per-file validation progress.
@@ -69,6 +72,9 @@ func (p *ClientsProvider) Configure(rawCfg provider.ProviderConfig) error {
 	if len(cfg.Platforms) == 0 {
 		cfg.Platforms = []string{"linux", "linux-arm64"}
 	}
+	if len(cfg.GraphArchitectures) == 0 {
+		cfg.GraphArchitectures = []string{"amd64"}
+	}
 
 	p.cfg = cfg
 	p.clientSvc = ocpsvc.NewClientService(p.logger)
@@ -78,6 +84,7 @@ func (p *ClientsProvider) Configure(rawCfg provider.ProviderConfig) error {
 		slog.Int("versions", len(p.cfg.Versions)),
 		slog.Any("platforms", p.cfg.Platforms),
 		slog.String("output_dir", p.cfg.OutputDir),
+		slog.Bool("update_graph", p.cfg.UpdateGraph),
 	)
 
 	return nil
@@ -98,6 +105,18 @@ func (p *ClientsProvider) Plan(ctx context.Context) (*provider.SyncPlan, error)
 		Timestamp: time.Now(),
 	}
 
+	outputRoot, err := safety.SafeJoinUnder(p.dataDir, p.cfg.OutputDir)
+	if err != nil {
+		return nil, fmt.Errorf("invalid output_dir %q: %w", p.cfg.OutputDir, err)
+	}
+
+	p.generated, p.failures = nil, nil
+	if p.cfg.UpdateGraph {
+		if err := p.captureGraphs(ctx, outputRoot); err != nil {
+			return nil, err
+		}
+	}
+
 	// Collect all versions to sync (from channels + pinned versions)
 	versions, err := p.resolveVersions(ctx)
 	if err != nil {
@@ -110,10 +129,6 @@ func (p *ClientsProvider) Plan(ctx context.Context) (*provider.SyncPlan, error)
 	}
 
 	// For each version, fetch sha256sum.txt manifest and use it as the source of truth
-	outputRoot, err := safety.SafeJoinUnder(p.dataDir, p.cfg.OutputDir)
-	if err != nil {
-		return nil, fmt.Errorf("invalid output_dir %q: %w", p.cfg.OutputDir, err)
-	}
 	for _, version := range versions {
 		versionDir, err := safety.SafeJoinUnder(outputRoot, version)
 		if err != nil {
@@ -163,6 +178,51 @@ func (p *ClientsProvider) Plan(ctx context.Context) (*provider.SyncPlan, error)
 	return plan, nil
 }
 
+// captureGraphs fetches the upstream update graph for every configured
+// channel and architecture. The graphs are reported through GeneratedFiles,
+// so the engine writes them to graph/<arch>/<channel>.json after the sync;
+// graphs that cannot be fetched are reported through PlanFailures.
+func (p *ClientsProvider) captureGraphs(ctx context.Context, outputRoot string) error {
+	for _, arch := range p.cfg.GraphArchitectures {
+		for _, channel := range p.cfg.Channels {
+			relPath := path.Join("graph", arch, channel+".json")
+			localPath, err := safety.SafeJoinUnder(outputRoot, relPath)
+			if err != nil {
+				return fmt.Errorf("invalid graph path for channel %q arch %q: %w", channel, arch, err)
+			}
+
+			data, err := p.clientSvc.FetchGraph(ctx, channel, arch)
+			if err != nil {
+				p.logger.Error("failed to fetch update graph",
+					"channel", channel, "arch", arch, "error", err)
+				p.failures = append(p.failures, provider.FailedFile{
+					Path:  relPath,
+					Error: fmt.Sprintf("fetching update graph: %v", err),
+				})
+				continue
+			}
+
+			p.generated = append(p.generated, provider.GeneratedFile{
+				Path:      relPath,
+				LocalPath: localPath,
+				Data:      data,
+				Reason:    "update graph captured",
+			})
+		}
+	}
+	return nil
+}
+
+// GeneratedFiles returns the update graphs fetched by the last Plan.
+func (p *ClientsProvider) GeneratedFiles() []provider.GeneratedFile {
+	return p.generated
+}
+
+// PlanFailures returns the update graphs the last Plan could not fetch.
+func (p *ClientsProvider) PlanFailures() []provider.FailedFile {
+	return p.failures
+}
+
 // planArtifact creates a SyncAction for a single artifact by comparing against local state.
 func (p *ClientsProvider) planArtifact(artifact ocpsvc.ClientArtifact, localPath, relPath, expectedHash string) provider.SyncAction {
 	// Check if local file exists
@@ -3,6 +3,8 @@ package ocp
 import (
 	"context"
 	"log/slog"
+	"os"
+	"path/filepath"
 	"testing"
 
 	"github.com/BadgerOps/airgap/internal/provider"
@@ -66,6 +68,9 @@ func TestClientsProviderConfigureDefaults(t *testing.T) {
 	if len(p.cfg.Platforms) != 2 {
 		t.Errorf("expected default 2 platforms (linux, linux-arm64), got %d: %v", len(p.cfg.Platforms), p.cfg.Platforms)
 	}
+	if len(p.cfg.GraphArchitectures) != 1 || p.cfg.GraphArchitectures[0] != "amd64" {
+		t.Errorf("expected default graph architectures [amd64], got %v", p.cfg.GraphArchitectures)
+	}
 }
 
 func TestClientsProviderPlanNotConfigured(t *testing.T) {
@@ -75,3 +80,32 @@ func TestClientsProviderPlanNotConfigured(t *testing.T) {
 		t.Error("expected error when not configured")
 	}
 }
+
+func TestClientsProviderCaptureGraphsReportsFailures(t *testing.T) {
+	dataDir := t.TempDir()
+	p := NewClientsProvider(dataDir, slog.Default())
+	if err := p.Configure(provider.ProviderConfig{
+		"channels":     []interface{}{"stable-4.16"},
+		"update_graph": true,
+	}); err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+
+	// A cancelled context fails the graph fetch without reaching the network.
+	ctx, cancel := context.WithCancel(context.Background())
+	cancel()
+	if err := p.captureGraphs(ctx, filepath.Join(dataDir, p.cfg.OutputDir)); err != nil {
+		t.Fatalf("captureGraphs failed: %v", err)
+	}
+
+	failures := p.PlanFailures()
+	if len(failures) != 1 || failures[0].Path != "graph/amd64/stable-4.16.json" {
+		t.Errorf("expected the graph fetch failure to be reported, got %+v", failures)
+	}
+	if len(p.GeneratedFiles()) != 0 {
+		t.Errorf("expected no generated graphs, got %+v", p.GeneratedFiles())
+	}
+	if entries, _ := os.ReadDir(dataDir); len(entries) != 0 {
+		t.Errorf("expected nothing written during plan, got %v", entries)
+	}
+}
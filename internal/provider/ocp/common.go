@@ -11,7 +11,9 @@ import (
 	"log/slog"
 	"net/http"
 	"os"
+	"path"
 	"path/filepath"
+	"sort"
 	"strings"
 	"time"
 
@@ -116,98 +118,190 @@ func checksumLocalFile(path string) (string, error) {
 	return hex.EncodeToString(hash.Sum(nil)), nil
 }
 
+// remoteFile is an upstream file stored as <Dir>/<Name> under output_dir.
+type remoteFile struct {
+	Dir    string
+	Name   string
+	SHA256 string // empty for detached signatures, which have no published checksum
+	URL    string
+}
+
+// relPath returns the file path relative to output_dir for reporting.
+func (f remoteFile) relPath() string {
+	return filepath.ToSlash(filepath.Join(f.Dir, f.Name))
+}
+
+// localPath resolves the file under outputRoot, rejecting names that escape Dir.
+func (f remoteFile) localPath(outputRoot string) (string, error) {
+	dir, err := safety.SafeJoinUnder(outputRoot, f.Dir)
+	if err != nil {
+		return "", err
+	}
+	return safety.SafeJoinUnder(dir, f.Name)
+}
+
+// checksumFiles lists the entries of a sha256sum.txt published at versionURL,
+// stored under dir.
+func checksumFiles(versionURL, dir string, sums map[string]string) []remoteFile {
+	files := make([]remoteFile, 0, len(sums))
+	for name, hash := range sums {
+		files = append(files, remoteFile{
+			Dir:    dir,
+			Name:   name,
+			SHA256: hash,
+			URL:    fmt.Sprintf("%s/%s", strings.TrimRight(versionURL, "/"), name),
+		})
+	}
+	sort.Slice(files, func(i, j int) bool { return files[i].Name < files[j].Name })
+	return files
+}
+
 // buildSyncPlan compares remote manifest against local files and returns a plan.
 func buildSyncPlan(providerName, baseURL, version, outputDir, dataDir string, remoteFiles map[string]string, logger *slog.Logger) ([]provider.SyncAction, error) {
-	var actions []provider.SyncAction
-
 	outputRoot, err := safety.SafeJoinUnder(dataDir, outputDir)
 	if err != nil {
 		return nil, fmt.Errorf("invalid output directory %q: %w", outputDir, err)
 	}
-	versionDir, err := safety.SafeJoinUnder(outputRoot, version)
-	if err != nil {
+	if _, err := safety.SafeJoinUnder(outputRoot, version); err != nil {
 		return nil, fmt.Errorf("invalid version %q: %w", version, err)
 	}
+	versionURL := fmt.Sprintf("%s/%s", strings.TrimRight(baseURL, "/"), version)
+	return buildFileActions(providerName, outputRoot, checksumFiles(versionURL, version, remoteFiles), logger)
+}
 
-	for filename, expectedHash := range remoteFiles {
-		localPath, err := safety.SafeJoinUnder(versionDir, filename)
+// buildFileActions compares files against local state under outputRoot.
+// Files without a checksum are only downloaded when missing.
+func buildFileActions(providerName, outputRoot string, files []remoteFile, logger *slog.Logger) ([]provider.SyncAction, error) {
+	actions := make([]provider.SyncAction, 0, len(files))
+	for _, f := range files {
+		localPath, err := f.localPath(outputRoot)
 		if err != nil {
-			return nil, fmt.Errorf("unsafe remote filename %q: %w", filename, err)
+			return nil, fmt.Errorf("unsafe remote filename %q: %w", f.Name, err)
 		}
 		relPath, err := filepath.Rel(outputRoot, localPath)
 		if err != nil {
-			return nil, fmt.Errorf("building relative path for %q: %w", filename, err)
+			return nil, fmt.Errorf("building relative path for %q: %w", f.Name, err)
+		}
+		action := provider.SyncAction{
+			Path:      filepath.ToSlash(relPath),
+			LocalPath: localPath,
+			Action:    provider.ActionDownload,
+			Checksum:  f.SHA256,
+			Reason:    "new file",
+			URL:       f.URL,
 		}
-		relPath = filepath.ToSlash(relPath)
-		downloadURL := fmt.Sprintf("%s/%s/%s", strings.TrimRight(baseURL, "/"), version, filename)
 
 		// Check if local file exists and has matching checksum
-		if fileInfo, err := os.Stat(localPath); err == nil {
+		fileInfo, err := os.Stat(localPath)
+		switch {
+		case os.IsNotExist(err):
+		case err != nil:
+			logger.Warn("error checking local file",
+				slog.String("provider", providerName),
+				slog.String("file", f.relPath()),
+				slog.String("error", err.Error()))
+			action.Reason = "error checking file"
+		case f.SHA256 == "":
+			action.Action = provider.ActionSkip
+			action.Size = fileInfo.Size()
+			action.Reason = "file present"
+		default:
+			action.Size = fileInfo.Size()
 			actualHash, err := checksumLocalFile(localPath)
-			if err != nil {
+			switch {
+			case err != nil:
 				logger.Warn("failed to compute checksum for local file",
 					slog.String("provider", providerName),
-					slog.String("file", filename),
+					slog.String("file", f.relPath()),
 					slog.String("error", err.Error()))
-				actions = append(actions, provider.SyncAction{
-					Path:      relPath,
-					LocalPath: localPath,
-					Action:    provider.ActionUpdate,
-					Size:      fileInfo.Size(),
-					Checksum:  expectedHash,
-					Reason:    "checksum verification failed",
-					URL:       downloadURL,
-				})
-				continue
+				action.Action = provider.ActionUpdate
+				action.Reason = "checksum verification failed"
+			case actualHash == f.SHA256:
+				action.Action = provider.ActionSkip
+				action.Reason = "checksum matches"
+			default:
+				action.Action = provider.ActionUpdate
+				action.Reason = "checksum mismatch"
 			}
+		}
+		actions = append(actions, action)
+	}
+	return actions, nil
+}
 
-			if actualHash == expectedHash {
-				actions = append(actions, provider.SyncAction{
-					Path:      relPath,
-					LocalPath: localPath,
-					Action:    provider.ActionSkip,
-					Size:      fileInfo.Size(),
-					Checksum:  expectedHash,
-					Reason:    "checksum matches",
-					URL:       downloadURL,
-				})
-			} else {
-				actions = append(actions, provider.SyncAction{
-					Path:      relPath,
-					LocalPath: localPath,
-					Action:    provider.ActionUpdate,
-					Size:      fileInfo.Size(),
-					Checksum:  expectedHash,
-					Reason:    "checksum mismatch",
-					URL:       downloadURL,
-				})
-			}
-		} else if os.IsNotExist(err) {
-			actions = append(actions, provider.SyncAction{
-				Path:      relPath,
-				LocalPath: localPath,
-				Action:    provider.ActionDownload,
-				Size:      0,
-				Checksum:  expectedHash,
-				Reason:    "new file",
-				URL:       downloadURL,
-			})
+// fileValidator accumulates per-file validation results into a report.
+type fileValidator struct {
+	report     *provider.ValidationReport
+	outputRoot string
+	progressFn provider.ValidationProgressFn
+	checked    int
+}
+
+// validate checks a local file against its expected checksum. Files without
+// a checksum only need to exist.
+func (v *fileValidator) validate(f remoteFile) {
+	v.report.TotalFiles++
+	localPath, pathErr := f.localPath(v.outputRoot)
+	if pathErr != nil {
+		v.record(provider.ValidationResult{
+			Path:     f.relPath(),
+			Expected: f.SHA256,
+			Actual:   "error: unsafe path: " + pathErr.Error(),
+		})
+		return
+	}
+	relPath, err := filepath.Rel(v.outputRoot, localPath)
+	if err != nil {
+		relPath = f.relPath()
+	}
+	result := provider.ValidationResult{
+		Path:      filepath.ToSlash(relPath),
+		LocalPath: localPath,
+		Expected:  f.SHA256,
+		URL:       f.URL,
+	}
+
+	fileInfo, statErr := os.Stat(localPath)
+	switch {
+	case os.IsNotExist(statErr):
+		result.Actual = "missing"
+	case statErr != nil:
+		result.Actual = "error: " + statErr.Error()
+	case f.SHA256 == "":
+		result.Valid = true
+	default:
+		result.Size = fileInfo.Size()
+		actualHash, hashErr := checksumLocalFile(localPath)
+		if hashErr != nil {
+			result.Actual = "error: " + hashErr.Error()
 		} else {
-			logger.Warn("error checking local file",
-				slog.String("provider", providerName),
-				slog.String("file", filename),
-				slog.String("error", err.Error()))
-			actions = append(actions, provider.SyncAction{
-				Path:      relPath,
-				LocalPath: localPath,
-				Action:    provider.ActionDownload,
-				Size:      0,
-				Checksum:  expectedHash,
-				Reason:    "error checking file",
-				URL:       downloadURL,
-			})
+			result.Actual = actualHash
+			result.Valid = actualHash == f.SHA256
 		}
 	}
+	v.record(result)
+}
 
-	return actions, nil
+// rejectManifest records a sha256sum.txt that failed verification, whose
+// files cannot be checked.
+func (v *fileValidator) rejectManifest(dir, url string, err error) {
+	v.report.TotalFiles++
+	v.record(provider.ValidationResult{
+		Path:   path.Join(dir, "sha256sum.txt"),
+		URL:    url,
+		Actual: "error: " + err.Error(),
+		Reason: err.Error(),
+	})
+}
+
+func (v *fileValidator) record(result provider.ValidationResult) {
+	v.checked++
+	if result.Valid {
+		v.report.ValidFiles++
+	} else {
+		v.report.InvalidFiles = append(v.report.InvalidFiles, result)
+	}
+	if v.progressFn != nil {
+		v.progressFn(v.checked, v.report.TotalFiles, result.Path, result.Valid)
+	}
 }
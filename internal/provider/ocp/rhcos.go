@@ -2,13 +2,14 @@ package ocp
 
 import (
 	"context"
+	"errors"
 	"fmt"
 	"log/slog"
-	"os"
-	"path/filepath"
 	"strings"
 	"time"
 
+	"github.com/ProtonMail/go-crypto/openpgp"
+
 	"github.com/BadgerOps/airgap/internal/config"
 	"github.com/BadgerOps/airgap/internal/provider"
 	"github.com/BadgerOps/airgap/internal/safety"
@@ -21,6 +22,9 @@ type RHCOSProvider struct {
 	dataDir              string
 	logger               *slog.Logger
 	validationProgressFn provider.ValidationProgressFn
+	generated            []provider.GeneratedFile
+	targets              []archTarget
+	keyring              openpgp.EntityList // trusted sha256sum.txt signers, nil when unchecked
 }
 
 // SetValidationProgress sets the callback for per-file validation progress.
@@ -57,13 +61,34 @@ func (p *RHCOSProvider) Configure(rawCfg provider.ProviderConfig) error {
 	if err != nil {
 		return fmt.Errorf("parsing RHCOS config: %w", err)
 	}
-	p.cfg = cfg
-	if _, err := safety.ValidateHTTPURL(p.cfg.BaseURL); err != nil {
-		return fmt.Errorf("invalid base_url: %w", err)
+	arches, err := NormalizeArchitectures(cfg.Architectures)
+	if err != nil {
+		return err
+	}
+	cfg.Architectures = arches
+	var targets []archTarget
+	if cfg.StreamURL != "" {
+		if err := validateStreamConfig(cfg); err != nil {
+			return err
+		}
+		if len(cfg.GPGKeys) > 0 {
+			return fmt.Errorf("gpg_keys verify sha256sum.txt and cannot be used with stream_url")
+		}
+	} else if targets, err = expandArchitectures(cfg.BaseURL, cfg.Architectures); err != nil {
+		return err
+	}
+	keyring, err := loadChecksumKeyring(cfg.GPGKeys)
+	if err != nil {
+		return err
 	}
+	p.cfg = cfg
+	p.targets = targets
+	p.keyring = keyring
 
 	p.logger.Debug("configured RHCOS provider",
 		slog.String("base_url", p.cfg.BaseURL),
+		slog.String("stream_url", p.cfg.StreamURL),
+		slog.String("architectures", strings.Join(p.cfg.Architectures, ",")),
 		slog.Int("versions", len(p.cfg.Versions)),
 		slog.String("output_dir", p.cfg.OutputDir),
 	)
@@ -81,52 +106,56 @@ func (p *RHCOSProvider) Plan(ctx context.Context) (*provider.SyncPlan, error) {
 		Provider:  p.Name(),
 		Actions:   []provider.SyncAction{},
 		Timestamp: time.Now(),
+		// Plan fails below unless every sha256sum.txt verifies.
+		SignatureStatus: signatureStatus(p.keyring),
 	}
+	p.generated = nil
 
-	for _, version := range p.cfg.Versions {
-		p.logger.Debug("planning sync for version",
-			slog.String("version", version))
+	outputRoot, err := safety.SafeJoinUnder(p.dataDir, p.cfg.OutputDir)
+	if err != nil {
+		return nil, fmt.Errorf("invalid output directory %q: %w", p.cfg.OutputDir, err)
+	}
 
-		checksumURL := fmt.Sprintf("%s/%s/sha256sum.txt", strings.TrimRight(p.cfg.BaseURL, "/"), version)
+	// Stream mode has no base_url targets.
+	if p.cfg.StreamURL != "" {
+		p.planStream(ctx, outputRoot, plan)
+	}
 
-		// Fetch checksum file
-		checksumData, err := p.fetchChecksumFile(ctx, checksumURL)
-		if err != nil {
-			p.logger.Error("failed to fetch checksum file",
-				slog.String("version", version),
-				slog.String("url", checksumURL),
-				slog.String("error", err.Error()))
-			continue
-		}
+	for _, target := range p.targets {
+		for _, version := range p.cfg.Versions {
+			p.logger.Debug("planning sync for version",
+				slog.String("arch", target.Arch),
+				slog.String("version", version))
 
-		// Parse checksum file
-		remoteFiles := parseChecksumFile(checksumData)
-		p.logger.Debug("parsed checksum file",
-			slog.String("version", version),
-			slog.Int("files", len(remoteFiles)))
-
-		// Filter by ignored patterns
-		filteredFiles := filterFiles(remoteFiles, p.cfg.IgnoredPatterns)
-		p.logger.Debug("filtered files",
-			slog.String("version", version),
-			slog.Int("before", len(remoteFiles)),
-			slog.Int("after", len(filteredFiles)))
-
-		// Build sync plan for this version
-		versionActions, err := buildSyncPlan(p.Name(), p.cfg.BaseURL, version, p.cfg.OutputDir, p.dataDir, filteredFiles, p.logger)
-		if err != nil {
-			p.logger.Error("failed to build sync plan for version",
-				slog.String("version", version),
-				slog.String("error", err.Error()))
-			continue
-		}
+			files, err := p.versionFiles(ctx, target, version)
+			if errors.Is(err, errChecksumSignature) {
+				return nil, fmt.Errorf("version %s: %w", target.dir(version), err)
+			}
+			if err != nil {
+				p.logger.Error("failed to fetch checksum file",
+					slog.String("arch", target.Arch),
+					slog.String("version", version),
+					slog.String("error", err.Error()))
+				continue
+			}
 
-		// Aggregate actions and calculate totals
-		for _, action := range versionActions {
-			plan.Actions = append(plan.Actions, action)
-			plan.TotalFiles++
-			if action.Action == provider.ActionDownload || action.Action == provider.ActionUpdate {
-				plan.TotalSize += action.Size
+			// Build sync plan for this version
+			versionActions, err := buildFileActions(p.Name(), outputRoot, files, p.logger)
+			if err != nil {
+				p.logger.Error("failed to build sync plan for version",
+					slog.String("arch", target.Arch),
+					slog.String("version", version),
+					slog.String("error", err.Error()))
+				continue
+			}
+
+			// Aggregate actions and calculate totals
+			for _, action := range versionActions {
+				plan.Actions = append(plan.Actions, action)
+				plan.TotalFiles++
+				if action.Action == provider.ActionDownload || action.Action == provider.ActionUpdate {
+					plan.TotalSize += action.Size
+				}
 			}
 		}
 	}
@@ -204,126 +233,43 @@ func (p *RHCOSProvider) Validate(ctx context.Context) (*provider.ValidationRepor
 		return nil, fmt.Errorf("invalid output_dir %q: %w", p.cfg.OutputDir, err)
 	}
 
-	checked := 0
-
-	for _, version := range p.cfg.Versions {
-		checksumURL := fmt.Sprintf("%s/%s/sha256sum.txt", strings.TrimRight(p.cfg.BaseURL, "/"), version)
-
-		checksumData, err := p.fetchChecksumFile(ctx, checksumURL)
-		if err != nil {
-			p.logger.Warn("failed to fetch checksum file for validation",
-				slog.String("version", version),
-				slog.String("url", checksumURL),
-				slog.String("error", err.Error()))
-			continue
-		}
-
-		remoteFiles := parseChecksumFile(checksumData)
-		filteredFiles := filterFiles(remoteFiles, p.cfg.IgnoredPatterns)
-
-		versionDir, err := safety.SafeJoinUnder(outputRoot, version)
-		if err != nil {
-			return nil, fmt.Errorf("invalid version %q: %w", version, err)
-		}
-
-		for filename, expectedHash := range filteredFiles {
-			localPath, pathErr := safety.SafeJoinUnder(versionDir, filename)
-			if pathErr != nil {
-				report.TotalFiles++
-				report.InvalidFiles = append(report.InvalidFiles, provider.ValidationResult{
-					Path:     filepath.ToSlash(filepath.Join(version, filename)),
-					Expected: expectedHash,
-					Actual:   "error: unsafe path: " + pathErr.Error(),
-					Valid:    false,
-				})
-				checked++
-				if p.validationProgressFn != nil {
-					p.validationProgressFn(checked, report.TotalFiles, filepath.ToSlash(filepath.Join(version, filename)), false)
-				}
+	v := &fileValidator{report: report, outputRoot: outputRoot, progressFn: p.validationProgressFn}
+	if p.cfg.StreamURL != "" {
+		for _, version := range p.cfg.Versions {
+			files, err := p.streamVersionFiles(ctx, outputRoot, version, false)
+			if err != nil {
+				p.logger.Warn("failed to read stream metadata for validation",
+					slog.String("version", version),
+					slog.String("stream", p.streamSource(version)),
+					slog.String("error", err.Error()))
 				continue
 			}
-
-			relPath, err := filepath.Rel(outputRoot, localPath)
-			if err != nil {
-				return nil, fmt.Errorf("building relative path for %q: %w", filename, err)
+			for _, f := range files {
+				v.validate(f)
 			}
-			relPath = filepath.ToSlash(relPath)
-
-			downloadURL := fmt.Sprintf("%s/%s/%s", strings.TrimRight(p.cfg.BaseURL, "/"), version, filename)
-			report.TotalFiles++
-
-			// Check if file exists
-			fileInfo, statErr := os.Stat(localPath)
-			if os.IsNotExist(statErr) {
-				report.InvalidFiles = append(report.InvalidFiles, provider.ValidationResult{
-					Path:      relPath,
-					LocalPath: localPath,
-					Expected:  expectedHash,
-					Actual:    "missing",
-					Valid:     false,
-					URL:       downloadURL,
-				})
-				checked++
-				if p.validationProgressFn != nil {
-					p.validationProgressFn(checked, report.TotalFiles, relPath, false)
-				}
-				continue
+		}
+	}
+
+	for _, target := range p.targets {
+		for _, version := range p.cfg.Versions {
+			if _, err := safety.SafeJoinUnder(outputRoot, target.dir(version)); err != nil {
+				return nil, fmt.Errorf("invalid version %q: %w", version, err)
 			}
-			if statErr != nil {
-				report.InvalidFiles = append(report.InvalidFiles, provider.ValidationResult{
-					Path:      relPath,
-					LocalPath: localPath,
-					Expected:  expectedHash,
-					Actual:    "error: " + statErr.Error(),
-					Valid:     false,
-					URL:       downloadURL,
-				})
-				checked++
-				if p.validationProgressFn != nil {
-					p.validationProgressFn(checked, report.TotalFiles, relPath, false)
-				}
+			files, err := p.versionFiles(ctx, target, version)
+			if errors.Is(err, errChecksumSignature) {
+				versionURL := fmt.Sprintf("%s/%s", strings.TrimRight(target.BaseURL, "/"), version)
+				v.rejectManifest(target.dir(version), versionURL+"/sha256sum.txt", err)
 				continue
 			}
-
-			// Compute checksum and compare
-			actualHash, hashErr := checksumLocalFile(localPath)
-			if hashErr != nil {
-				report.InvalidFiles = append(report.InvalidFiles, provider.ValidationResult{
-					Path:      relPath,
-					LocalPath: localPath,
-					Expected:  expectedHash,
-					Actual:    "error: " + hashErr.Error(),
-					Valid:     false,
-					Size:      fileInfo.Size(),
-					URL:       downloadURL,
-				})
-				checked++
-				if p.validationProgressFn != nil {
-					p.validationProgressFn(checked, report.TotalFiles, relPath, false)
-				}
+			if err != nil {
+				p.logger.Warn("failed to fetch checksum file for validation",
+					slog.String("arch", target.Arch),
+					slog.String("version", version),
+					slog.String("error", err.Error()))
 				continue
 			}
-
-			if actualHash == expectedHash {
-				report.ValidFiles++
-				checked++
-				if p.validationProgressFn != nil {
-					p.validationProgressFn(checked, report.TotalFiles, relPath, true)
-				}
-			} else {
-				report.InvalidFiles = append(report.InvalidFiles, provider.ValidationResult{
-					Path:      relPath,
-					LocalPath: localPath,
-					Expected:  expectedHash,
-					Actual:    actualHash,
-					Valid:     false,
-					Size:      fileInfo.Size(),
-					URL:       downloadURL,
-				})
-				checked++
-				if p.validationProgressFn != nil {
-					p.validationProgressFn(checked, report.TotalFiles, relPath, false)
-				}
+			for _, f := range files {
+				v.validate(f)
 			}
 		}
 	}
@@ -337,12 +283,102 @@ func (p *RHCOSProvider) Validate(ctx context.Context) (*provider.ValidationRepor
 	return report, nil
 }
 
-// fetchChecksumFile downloads a sha256sum.txt file from the given URL.
+// fetchChecksumFile downloads a sha256sum.txt file from the given URL and,
+// with gpg_keys configured, verifies its detached signature.
 func (p *RHCOSProvider) fetchChecksumFile(ctx context.Context, url string) ([]byte, error) {
 	data, err := fetchWithStatusOK(ctx, url)
 	if err != nil {
 		return nil, fmt.Errorf("fetching checksum file: %w", err)
 	}
+	if p.keyring != nil {
+		if err := verifyChecksumSignature(ctx, url, data, p.keyring, p.logger); err != nil {
+			return nil, err
+		}
+	}
 
 	return data, nil
 }
+
+// planStream adds the stream metadata artifacts of every version to plan.
+func (p *RHCOSProvider) planStream(ctx context.Context, outputRoot string, plan *provider.SyncPlan) {
+	for _, version := range p.cfg.Versions {
+		files, err := p.streamVersionFiles(ctx, outputRoot, version, true)
+		if err != nil {
+			p.logger.Error("failed to read stream metadata",
+				slog.String("version", version),
+				slog.String("stream", p.streamSource(version)),
+				slog.String("error", err.Error()))
+			continue
+		}
+
+		versionActions, err := buildFileActions(p.Name(), outputRoot, files, p.logger)
+		if err != nil {
+			p.logger.Error("failed to build sync plan for version",
+				slog.String("version", version),
+				slog.String("error", err.Error()))
+			continue
+		}
+		for _, action := range versionActions {
+			plan.Actions = append(plan.Actions, action)
+			plan.TotalFiles++
+			if action.Action == provider.ActionDownload || action.Action == provider.ActionUpdate {
+				plan.TotalSize += action.Size
+			}
+		}
+	}
+}
+
+// GeneratedFiles returns the stream metadata fetched by the last Plan.
+func (p *RHCOSProvider) GeneratedFiles() []provider.GeneratedFile {
+	return p.generated
+}
+
+// validateStreamConfig checks stream mode settings and applies defaults.
+func validateStreamConfig(cfg *config.RHCOSProviderConfig) error {
+	if isHTTPSource(cfg.StreamURL) {
+		if _, err := safety.ValidateHTTPURL(strings.ReplaceAll(cfg.StreamURL, "{version}", "0")); err != nil {
+			return fmt.Errorf("invalid stream_url: %w", err)
+		}
+	}
+	if len(cfg.Versions) == 0 {
+		return fmt.Errorf("stream_url requires at least one version")
+	}
+	if len(cfg.Versions) > 1 && !strings.Contains(cfg.StreamURL, "{version}") {
+		return fmt.Errorf("stream_url must contain {version} when more than one version is configured")
+	}
+	for _, v := range cfg.Versions {
+		if _, err := safety.CleanRelativePath(v); err != nil || strings.Contains(v, "/") {
+			return fmt.Errorf("invalid version %q for stream mode", v)
+		}
+	}
+	if len(cfg.Architectures) == 0 {
+		cfg.Architectures = []string{defaultRHCOSArchitecture}
+	}
+	if len(cfg.Artifacts) == 0 {
+		cfg.Artifacts = []config.RHCOSArtifactSelector{{Platform: "metal"}}
+	}
+	for _, sel := range cfg.Artifacts {
+		if strings.TrimSpace(sel.Platform) == "" {
+			return fmt.Errorf("artifacts: platform is required")
+		}
+	}
+	return nil
+}
+
+// versionFiles fetches sha256sum.txt of version for target and returns the
+// files not matching ignored_patterns.
+func (p *RHCOSProvider) versionFiles(ctx context.Context, target archTarget, version string) ([]remoteFile, error) {
+	versionURL := fmt.Sprintf("%s/%s", strings.TrimRight(target.BaseURL, "/"), version)
+	checksumData, err := p.fetchChecksumFile(ctx, versionURL+"/sha256sum.txt")
+	if err != nil {
+		return nil, err
+	}
+	remoteFiles := parseChecksumFile(checksumData)
+	filteredFiles := filterFiles(remoteFiles, p.cfg.IgnoredPatterns)
+	p.logger.Debug("filtered files",
+		slog.String("arch", target.Arch),
+		slog.String("version", version),
+		slog.Int("before", len(remoteFiles)),
+		slog.Int("after", len(filteredFiles)))
+	return checksumFiles(versionURL, target.dir(version), filteredFiles), nil
+}
@@ -0,0 +1,175 @@
+package ocp
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+	"log/slog"
+	"net/url"
+	"os"
+	"path"
+	"sort"
+	"strings"
+
+	"github.com/BadgerOps/airgap/internal/config"
+	"github.com/BadgerOps/airgap/internal/provider"
+	"github.com/BadgerOps/airgap/internal/safety"
+)
+
+const defaultRHCOSArchitecture = "x86_64"
+
+// coreosStream is the subset of the CoreOS stream metadata format used to
+// select artifacts.
+type coreosStream struct {
+	Stream        string `json:"stream"`
+	Architectures map[string]struct {
+		Artifacts map[string]struct {
+			Release string                                  `json:"release"`
+			Formats map[string]map[string]streamArtifactRef `json:"formats"`
+		} `json:"artifacts"`
+	} `json:"architectures"`
+}
+
+// streamArtifactRef is one file of an artifact format (disk, kernel, ...).
+type streamArtifactRef struct {
+	Location  string `json:"location"`
+	Signature string `json:"signature"`
+	SHA256    string `json:"sha256"`
+}
+
+// streamSource returns the stream metadata location for version.
+func (p *RHCOSProvider) streamSource(version string) string {
+	return strings.ReplaceAll(p.cfg.StreamURL, "{version}", version)
+}
+
+// fetchStream reads stream metadata from a URL or a local file.
+func (p *RHCOSProvider) fetchStream(ctx context.Context, source string) ([]byte, *coreosStream, error) {
+	var data []byte
+	var err error
+	if isHTTPSource(source) {
+		data, err = fetchWithStatusOK(ctx, source)
+	} else {
+		data, err = os.ReadFile(source)
+	}
+	if err != nil {
+		return nil, nil, fmt.Errorf("fetching stream metadata: %w", err)
+	}
+	var stream coreosStream
+	if err := json.Unmarshal(data, &stream); err != nil {
+		return nil, nil, fmt.Errorf("parsing stream metadata: %w", err)
+	}
+	return data, &stream, nil
+}
+
+// streamFiles selects the configured architectures, platforms and formats of
+// a stream. Files land under <arch>/<version>/, each followed by its detached
+// signature when the stream lists one. Selectors that match nothing are
+// returned in missing.
+func streamFiles(stream *coreosStream, version string, arches []string, selectors []config.RHCOSArtifactSelector) (files []remoteFile, missing []string, err error) {
+	seen := make(map[string]struct{})
+	add := func(dir, location, sha string) error {
+		u, err := url.Parse(location)
+		if err != nil || u.Scheme == "" {
+			return fmt.Errorf("invalid artifact location %q", location)
+		}
+		name := path.Base(u.Path)
+		if _, ok := seen[dir+"/"+name]; ok {
+			return nil
+		}
+		seen[dir+"/"+name] = struct{}{}
+		files = append(files, remoteFile{Dir: dir, Name: name, SHA256: sha, URL: location})
+		return nil
+	}
+
+	for _, arch := range arches {
+		a, ok := stream.Architectures[arch]
+		if !ok {
+			missing = append(missing, arch)
+			continue
+		}
+		dir := path.Join(arch, version)
+		for _, sel := range selectors {
+			artifact, ok := a.Artifacts[sel.Platform]
+			if !ok {
+				missing = append(missing, arch+"/"+sel.Platform)
+				continue
+			}
+			formats := sel.Formats
+			if len(formats) == 0 {
+				for f := range artifact.Formats {
+					formats = append(formats, f)
+				}
+				sort.Strings(formats)
+			}
+			for _, format := range formats {
+				refs, ok := artifact.Formats[format]
+				if !ok {
+					missing = append(missing, arch+"/"+sel.Platform+"/"+format)
+					continue
+				}
+				names := make([]string, 0, len(refs))
+				for name := range refs {
+					names = append(names, name)
+				}
+				sort.Strings(names)
+				for _, name := range names {
+					ref := refs[name]
+					if ref.Location == "" {
+						continue
+					}
+					if err := add(dir, ref.Location, ref.SHA256); err != nil {
+						return nil, nil, err
+					}
+					if ref.Signature != "" {
+						if err := add(dir, ref.Signature, ""); err != nil {
+							return nil, nil, err
+						}
+					}
+				}
+			}
+		}
+	}
+	return files, missing, nil
+}
+
+// streamVersionFiles fetches the stream of version, keeps it for
+// GeneratedFiles to be written to streams/<version>.json when keep is set,
+// and returns the selected files.
+func (p *RHCOSProvider) streamVersionFiles(ctx context.Context, outputRoot, version string, keep bool) ([]remoteFile, error) {
+	source := p.streamSource(version)
+	data, stream, err := p.fetchStream(ctx, source)
+	if err != nil {
+		return nil, err
+	}
+
+	files, missing, err := streamFiles(stream, version, p.cfg.Architectures, p.cfg.Artifacts)
+	if err != nil {
+		return nil, err
+	}
+	if len(missing) > 0 {
+		p.logger.Warn("stream metadata lacks selected artifacts",
+			slog.String("version", version),
+			slog.String("stream", stream.Stream),
+			slog.String("missing", strings.Join(missing, ", ")))
+	}
+
+	if keep {
+		relPath := path.Join("streams", version+".json")
+		localPath, err := safety.SafeJoinUnder(outputRoot, relPath)
+		if err != nil {
+			return nil, fmt.Errorf("invalid stream path for version %q: %w", version, err)
+		}
+		p.generated = append(p.generated, provider.GeneratedFile{
+			Path:      relPath,
+			LocalPath: localPath,
+			Data:      data,
+			Reason:    "stream metadata captured",
+		})
+	}
+	return files, nil
+}
+
+func isHTTPSource(source string) bool {
+	lower := strings.ToLower(source)
+	return strings.HasPrefix(lower, "http://") || strings.HasPrefix(lower, "https://")
+}
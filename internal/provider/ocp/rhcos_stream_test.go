@@ -0,0 +1,179 @@
+package ocp
+
+import (
+	"context"
+	"fmt"
+	"net/http"
+	"net/http/httptest"
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+
+	"github.com/BadgerOps/airgap/internal/provider"
+)
+
+func testStreamJSON(baseURL string, isoHash, kernelHash, ovaHash string) string {
+	return fmt.Sprintf(`{
+  "stream": "rhcos-4.16",
+  "architectures": {
+    "x86_64": {
+      "artifacts": {
+        "metal": {
+          "release": "416.94.1",
+          "formats": {
+            "iso": {"disk": {"location": "%[1]s/rhcos-live.x86_64.iso", "signature": "%[1]s/rhcos-live.x86_64.iso.sig", "sha256": "%[2]s"}},
+            "pxe": {"kernel": {"location": "%[1]s/rhcos-live-kernel-x86_64", "sha256": "%[3]s"}}
+          }
+        },
+        "vmware": {
+          "release": "416.94.1",
+          "formats": {"ova": {"disk": {"location": "%[1]s/rhcos-vmware.x86_64.ova", "sha256": "%[4]s"}}}
+        }
+      }
+    },
+    "aarch64": {"artifacts": {}}
+  }
+}`, baseURL, isoHash, kernelHash, ovaHash)
+}
+
+func TestRHCOSProviderStreamPlan(t *testing.T) {
+	isoContent := []byte("rhcos-iso")
+	kernelContent := []byte("rhcos-kernel")
+
+	var server *httptest.Server
+	server = httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		switch r.URL.Path {
+		case "/4.16/stream.json":
+			_, _ = w.Write([]byte(testStreamJSON(server.URL+"/art", computeSHA256(isoContent), computeSHA256(kernelContent), computeSHA256([]byte("ova")))))
+		default:
+			http.NotFound(w, r)
+		}
+	}))
+	defer server.Close()
+
+	dataDir := t.TempDir()
+	p := NewRHCOSProvider(dataDir, testLogger())
+	err := p.Configure(provider.ProviderConfig{
+		"stream_url": server.URL + "/{version}/stream.json",
+		"versions":   []interface{}{"4.16"},
+		"output_dir": "rhcos",
+		"artifacts": []interface{}{
+			map[string]interface{}{"platform": "metal", "formats": []interface{}{"iso", "pxe"}},
+		},
+	})
+	if err != nil {
+		t.Fatalf("Configure() failed: %v", err)
+	}
+
+	// The kernel is already present and current.
+	kernelPath := filepath.Join(dataDir, "rhcos", "x86_64", "4.16", "rhcos-live-kernel-x86_64")
+	if err := os.MkdirAll(filepath.Dir(kernelPath), 0o755); err != nil {
+		t.Fatal(err)
+	}
+	if err := os.WriteFile(kernelPath, kernelContent, 0o644); err != nil {
+		t.Fatal(err)
+	}
+
+	plan, err := p.Plan(context.Background())
+	if err != nil {
+		t.Fatalf("Plan() failed: %v", err)
+	}
+
+	byPath := make(map[string]provider.SyncAction)
+	for _, a := range plan.Actions {
+		byPath[a.Path] = a
+	}
+	if len(byPath) != 3 {
+		t.Fatalf("expected 3 actions (iso, iso signature, kernel), got %d: %v", len(byPath), plan.Actions)
+	}
+
+	iso := byPath["x86_64/4.16/rhcos-live.x86_64.iso"]
+	if iso.Action != provider.ActionDownload || iso.Checksum != computeSHA256(isoContent) {
+		t.Errorf("unexpected iso action: %+v", iso)
+	}
+	if !strings.HasSuffix(iso.URL, "/art/rhcos-live.x86_64.iso") {
+		t.Errorf("unexpected iso URL %q", iso.URL)
+	}
+	sig := byPath["x86_64/4.16/rhcos-live.x86_64.iso.sig"]
+	if sig.Action != provider.ActionDownload || sig.Checksum != "" {
+		t.Errorf("unexpected signature action: %+v", sig)
+	}
+	if kernel := byPath["x86_64/4.16/rhcos-live-kernel-x86_64"]; kernel.Action != provider.ActionSkip {
+		t.Errorf("expected current kernel to be skipped, got %+v", kernel)
+	}
+
+	generated := p.GeneratedFiles()
+	if len(generated) != 1 || generated[0].Path != "streams/4.16.json" {
+		t.Fatalf("expected stream metadata copy, got %+v", generated)
+	}
+	if len(generated[0].Data) == 0 {
+		t.Error("stream metadata copy is empty")
+	}
+	if _, err := os.Stat(generated[0].LocalPath); !os.IsNotExist(err) {
+		t.Errorf("expected Plan not to write the stream metadata copy, got %v", err)
+	}
+
+	report, err := p.Validate(context.Background())
+	if err != nil {
+		t.Fatalf("Validate() failed: %v", err)
+	}
+	if report.TotalFiles != 3 || report.ValidFiles != 1 || len(report.InvalidFiles) != 2 {
+		t.Errorf("unexpected validation report: total=%d valid=%d invalid=%d",
+			report.TotalFiles, report.ValidFiles, len(report.InvalidFiles))
+	}
+}
+
+func TestStreamFilesReportsMissingSelectors(t *testing.T) {
+	dir := t.TempDir()
+	path := filepath.Join(dir, "stream.json")
+	if err := os.WriteFile(path, []byte(testStreamJSON("https://example.com", "a", "b", "c")), 0o644); err != nil {
+		t.Fatal(err)
+	}
+	p := NewRHCOSProvider(dir, testLogger())
+	if err := p.Configure(provider.ProviderConfig{
+		"stream_url": path,
+		"versions":   []interface{}{"4.16"},
+	}); err != nil {
+		t.Fatalf("Configure() failed: %v", err)
+	}
+	_, stream, err := p.fetchStream(context.Background(), path)
+	if err != nil {
+		t.Fatalf("fetchStream() failed: %v", err)
+	}
+
+	files, missing, err := streamFiles(stream, "4.16", []string{"x86_64", "aarch64", "s390x"}, p.cfg.Artifacts)
+	if err != nil {
+		t.Fatalf("streamFiles() failed: %v", err)
+	}
+	// Default selector: every metal format of x86_64.
+	if len(files) != 3 {
+		t.Errorf("expected 3 metal files, got %d: %+v", len(files), files)
+	}
+	want := "aarch64/metal, s390x"
+	if got := strings.Join(missing, ", "); got != want {
+		t.Errorf("missing = %q, want %q", got, want)
+	}
+}
+
+func TestRHCOSProviderStreamConfigure(t *testing.T) {
+	p := NewRHCOSProvider(t.TempDir(), testLogger())
+	err := p.Configure(provider.ProviderConfig{
+		"stream_url": "https://example.com/stream.json",
+		"versions":   []interface{}{"4.16", "4.17"},
+	})
+	if err == nil {
+		t.Fatal("expected error for multiple versions without {version}")
+	}
+
+	err = p.Configure(provider.ProviderConfig{
+		"stream_url": "https://example.com/{version}.json",
+		"versions":   []interface{}{"4.16"},
+	})
+	if err != nil {
+		t.Fatalf("Configure() failed: %v", err)
+	}
+	if len(p.cfg.Architectures) != 1 || p.cfg.Architectures[0] != "x86_64" {
+		t.Errorf("expected default architecture x86_64, got %v", p.cfg.Architectures)
+	}
+}
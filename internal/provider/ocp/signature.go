@@ -0,0 +1,59 @@
+package ocp
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"log/slog"
+	"path"
+
+	"github.com/ProtonMail/go-crypto/openpgp"
+
+	"github.com/BadgerOps/airgap/internal/gpg"
+	"github.com/BadgerOps/airgap/internal/provider"
+)
+
+// errChecksumSignature marks a sha256sum.txt whose detached signature is
+// missing or does not verify against gpg_keys.
+var errChecksumSignature = errors.New("sha256sum.txt signature")
+
+// verifyChecksumSignature checks the sha256sum.txt at checksumURL against its
+// detached <checksumURL>.gpg signature.
+func verifyChecksumSignature(ctx context.Context, checksumURL string, data []byte, ring openpgp.EntityList, logger *slog.Logger) error {
+	sig, err := fetchWithStatusOK(ctx, checksumURL+".gpg")
+	if err != nil {
+		return fmt.Errorf("%w: fetching %s.gpg: %v", errChecksumSignature, path.Base(checksumURL), err)
+	}
+	keyID, err := gpg.VerifyDetached(ring, data, sig)
+	if err != nil {
+		return fmt.Errorf("%w: %v", errChecksumSignature, err)
+	}
+	logger.Info("checksum signature verified",
+		slog.String("url", checksumURL),
+		slog.String("key_id", keyID))
+	return nil
+}
+
+// loadChecksumKeyring loads gpg_keys, returning nil when none are configured.
+func loadChecksumKeyring(keys []string) (openpgp.EntityList, error) {
+	if len(keys) == 0 {
+		return nil, nil
+	}
+	ring, err := gpg.LoadKeyring(keys)
+	if err != nil {
+		return nil, fmt.Errorf("gpg_keys: %w", err)
+	}
+	if len(ring) == 0 {
+		return nil, fmt.Errorf("gpg_keys contain no keys")
+	}
+	return ring, nil
+}
+
+// signatureStatus is the SyncPlan status of providers that verify
+// sha256sum.txt when ring is set.
+func signatureStatus(ring openpgp.EntityList) string {
+	if ring != nil {
+		return provider.SignatureVerified
+	}
+	return provider.SignatureUnverified
+}
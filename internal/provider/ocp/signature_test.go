@@ -0,0 +1,171 @@
+package ocp
+
+import (
+	"bytes"
+	"context"
+	"errors"
+	"fmt"
+	"net/http"
+	"net/http/httptest"
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+
+	"github.com/ProtonMail/go-crypto/openpgp"
+	"github.com/ProtonMail/go-crypto/openpgp/armor"
+	"github.com/ProtonMail/go-crypto/openpgp/packet"
+
+	"github.com/BadgerOps/airgap/internal/provider"
+)
+
+func newReleaseKey(t *testing.T) (*openpgp.Entity, string) {
+	t.Helper()
+	e, err := openpgp.NewEntity("Release", "", "release@example.com", &packet.Config{Algorithm: packet.PubKeyAlgoEdDSA})
+	if err != nil {
+		t.Fatalf("NewEntity: %v", err)
+	}
+	var buf bytes.Buffer
+	w, err := armor.Encode(&buf, openpgp.PublicKeyType, nil)
+	if err != nil {
+		t.Fatalf("armor.Encode: %v", err)
+	}
+	if err := e.Serialize(w); err != nil {
+		t.Fatalf("Serialize: %v", err)
+	}
+	_ = w.Close()
+	return e, buf.String()
+}
+
+// signedChecksumServer serves /4.18/sha256sum.txt with a binary detached
+// signature by signer, or no signature when signer is nil.
+func signedChecksumServer(t *testing.T, signer *openpgp.Entity, content []byte) *httptest.Server {
+	t.Helper()
+	sums := []byte(fmt.Sprintf("%s  openshift-client-linux.tar.gz\n", computeSHA256(content)))
+	var sig bytes.Buffer
+	if signer != nil {
+		if err := openpgp.DetachSign(&sig, signer, bytes.NewReader(sums), nil); err != nil {
+			t.Fatalf("DetachSign: %v", err)
+		}
+	}
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		switch r.URL.Path {
+		case "/4.18/sha256sum.txt":
+			_, _ = w.Write(sums)
+		case "/4.18/sha256sum.txt.gpg":
+			if signer == nil {
+				w.WriteHeader(http.StatusNotFound)
+				return
+			}
+			_, _ = w.Write(sig.Bytes())
+		default:
+			w.WriteHeader(http.StatusNotFound)
+		}
+	}))
+	t.Cleanup(server.Close)
+	return server
+}
+
+func TestBinariesProviderPlanVerifiesChecksumSignature(t *testing.T) {
+	key, armored := newReleaseKey(t)
+	other, _ := newReleaseKey(t)
+	content := []byte("openshift-client")
+
+	tests := []struct {
+		name    string
+		signer  *openpgp.Entity
+		keys    []interface{}
+		wantErr string
+		status  string
+	}{
+		{name: "trusted signature", signer: key, keys: []interface{}{armored}, status: provider.SignatureVerified},
+		{name: "unknown key", signer: other, keys: []interface{}{armored}, wantErr: "not in gpg_keys"},
+		{name: "missing signature", signer: nil, keys: []interface{}{armored}, wantErr: "sha256sum.txt.gpg"},
+		{name: "verification disabled", signer: nil, status: provider.SignatureUnverified},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			server := signedChecksumServer(t, tt.signer, content)
+			p := NewBinariesProvider(t.TempDir(), testLogger())
+			cfg := provider.ProviderConfig{
+				"base_url":   server.URL,
+				"versions":   []interface{}{"4.18"},
+				"output_dir": "ocp-binaries",
+			}
+			if tt.keys != nil {
+				cfg["gpg_keys"] = tt.keys
+			}
+			if err := p.Configure(cfg); err != nil {
+				t.Fatalf("Configure() failed: %v", err)
+			}
+
+			plan, err := p.Plan(context.Background())
+			if tt.wantErr != "" {
+				if err == nil || !errors.Is(err, errChecksumSignature) || !strings.Contains(err.Error(), tt.wantErr) {
+					t.Fatalf("Plan() error = %v, want signature error containing %q", err, tt.wantErr)
+				}
+				return
+			}
+			if err != nil {
+				t.Fatalf("Plan() failed: %v", err)
+			}
+			if len(plan.Actions) != 1 {
+				t.Errorf("Plan.Actions length = %d, want 1", len(plan.Actions))
+			}
+			if plan.SignatureStatus != tt.status {
+				t.Errorf("Plan.SignatureStatus = %q, want %q", plan.SignatureStatus, tt.status)
+			}
+		})
+	}
+}
+
+func TestRHCOSProviderValidateRejectsUnsignedChecksums(t *testing.T) {
+	_, armored := newReleaseKey(t)
+	other, _ := newReleaseKey(t)
+	content := []byte("rhcos-live-kernel")
+	server := signedChecksumServer(t, other, content)
+
+	dataDir := t.TempDir()
+	p := NewRHCOSProvider(dataDir, testLogger())
+	if err := p.Configure(provider.ProviderConfig{
+		"base_url":   server.URL,
+		"versions":   []interface{}{"4.18"},
+		"output_dir": "rhcos",
+		"gpg_keys":   []interface{}{armored},
+	}); err != nil {
+		t.Fatalf("Configure() failed: %v", err)
+	}
+	outputPath := filepath.Join(dataDir, "rhcos", "4.18")
+	if err := os.MkdirAll(outputPath, 0755); err != nil {
+		t.Fatal(err)
+	}
+	if err := os.WriteFile(filepath.Join(outputPath, "openshift-client-linux.tar.gz"), content, 0644); err != nil {
+		t.Fatal(err)
+	}
+
+	report, err := p.Validate(context.Background())
+	if err != nil {
+		t.Fatalf("Validate() failed: %v", err)
+	}
+	if report.ValidFiles != 0 || len(report.InvalidFiles) != 1 {
+		t.Fatalf("valid = %d, invalid = %+v", report.ValidFiles, report.InvalidFiles)
+	}
+	inv := report.InvalidFiles[0]
+	if inv.Path != "4.18/sha256sum.txt" || !strings.Contains(inv.Reason, "not in gpg_keys") {
+		t.Errorf("invalid file = %+v, want rejected 4.18/sha256sum.txt", inv)
+	}
+}
+
+func TestRHCOSProviderStreamRejectsGPGKeys(t *testing.T) {
+	_, armored := newReleaseKey(t)
+	p := NewRHCOSProvider(t.TempDir(), testLogger())
+	err := p.Configure(provider.ProviderConfig{
+		"stream_url": "https://example.com/rhcos.json",
+		"versions":   []interface{}{"4.18"},
+		"output_dir": "rhcos",
+		"gpg_keys":   []interface{}{armored},
+	})
+	if err == nil || !strings.Contains(err.Error(), "stream_url") {
+		t.Fatalf("Configure() error = %v, want gpg_keys/stream_url conflict", err)
+	}
+}
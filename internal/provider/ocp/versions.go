@@ -0,0 +1,146 @@
+package ocp
+
+import (
+	"context"
+	"fmt"
+	"regexp"
+	"strconv"
+	"strings"
+
+	"github.com/BadgerOps/airgap/internal/mirror"
+	ocpsvc "github.com/BadgerOps/airgap/internal/ocp"
+)
+
+var (
+	comparatorRegexp = regexp.MustCompile(`^(>=|<=|>|<|=)\s*(\d+\.\d+(?:\.\d+)?)$`)
+	latestOfRegexp   = regexp.MustCompile(`^latest\s+(\d+)\s+of\s+(\S+)$`)
+	allZRegexp       = regexp.MustCompile(`^all\s+(\d+\.\d+)\.z$`)
+	operatorSpace    = regexp.MustCompile(`(>=|<=|>|<|=)\s+`)
+)
+
+// versionExpr is one entry of ocp_binaries versions. Literal entries such as
+// "latest-4.17" or "4.18.3" are mirror directory names used as-is.
+type versionExpr struct {
+	raw string
+
+	literal     string
+	comparators []versionComparator // ">=4.16.0 <4.19.0"
+	latestN     int                 // "latest 3 of stable-4.18"
+	channel     string
+	minor       string // "all 4.17.z"
+}
+
+type versionComparator struct {
+	op      string
+	version string
+}
+
+// parseVersionExpr recognizes range, "latest N of <channel>" and "all X.Y.z"
+// expressions; anything else is a literal directory name.
+func parseVersionExpr(raw string) (versionExpr, error) {
+	expr := versionExpr{raw: raw}
+	s := strings.Join(strings.Fields(raw), " ")
+
+	if m := latestOfRegexp.FindStringSubmatch(s); m != nil {
+		n, err := strconv.Atoi(m[1])
+		if err != nil || n < 1 {
+			return expr, fmt.Errorf("invalid version expression %q: count must be at least 1", raw)
+		}
+		expr.latestN, expr.channel = n, m[2]
+		return expr, nil
+	}
+	if m := allZRegexp.FindStringSubmatch(s); m != nil {
+		expr.minor = m[1]
+		return expr, nil
+	}
+	if strings.ContainsAny(s, "<>=") {
+		// Allow ">= 4.16.0" as well as ">=4.16.0".
+		tokens := strings.Fields(operatorSpace.ReplaceAllString(s, "$1"))
+		for _, tok := range tokens {
+			m := comparatorRegexp.FindStringSubmatch(tok)
+			if m == nil {
+				return expr, fmt.Errorf("invalid version range %q: unexpected %q", raw, tok)
+			}
+			expr.comparators = append(expr.comparators, versionComparator{op: m[1], version: m[2]})
+		}
+		return expr, nil
+	}
+	if strings.HasPrefix(s, "latest ") || strings.HasPrefix(s, "all ") {
+		return expr, fmt.Errorf("invalid version expression %q: expected \"latest N of <channel>\" or \"all X.Y.z\"", raw)
+	}
+	expr.literal = strings.TrimSpace(raw)
+	return expr, nil
+}
+
+// matches reports whether version satisfies a range or "all X.Y.z" expression.
+func (e versionExpr) matches(version string) bool {
+	if e.minor != "" {
+		return strings.HasPrefix(version, e.minor+".")
+	}
+	for _, c := range e.comparators {
+		cmp := ocpsvc.CompareVersions(version, c.version)
+		ok := false
+		switch c.op {
+		case ">=":
+			ok = cmp >= 0
+		case ">":
+			ok = cmp > 0
+		case "<=":
+			ok = cmp <= 0
+		case "<":
+			ok = cmp < 0
+		case "=":
+			ok = cmp == 0
+		}
+		if !ok {
+			return false
+		}
+	}
+	return true
+}
+
+// versionResolver resolves expressions for one base URL and update graph
+// architecture, caching its directory listing.
+type versionResolver struct {
+	baseURL  string
+	arch     string
+	releases func(ctx context.Context, channel, arch string) ([]string, error)
+	listing  []string
+	listed   bool
+}
+
+// resolve returns the concrete versions of e in ascending order.
+func (r *versionResolver) resolve(ctx context.Context, e versionExpr) ([]string, error) {
+	switch {
+	case e.literal != "":
+		return []string{e.literal}, nil
+	case e.latestN > 0:
+		releases, err := r.releases(ctx, e.channel, r.arch)
+		if err != nil {
+			return nil, fmt.Errorf("fetching releases of %s: %w", e.channel, err)
+		}
+		sorted := append([]string(nil), releases...)
+		ocpsvc.SortVersions(sorted)
+		if len(sorted) > e.latestN {
+			sorted = sorted[len(sorted)-e.latestN:]
+		}
+		return sorted, nil
+	}
+
+	if !r.listed {
+		data, err := fetchWithStatusOK(ctx, strings.TrimRight(r.baseURL, "/")+"/")
+		if err != nil {
+			return nil, fmt.Errorf("listing %s: %w", r.baseURL, err)
+		}
+		r.listing = mirror.OCPReleases(data)
+		r.listed = true
+	}
+	var out []string
+	for _, v := range r.listing {
+		if e.matches(v) {
+			out = append(out, v)
+		}
+	}
+	ocpsvc.SortVersions(out)
+	return out, nil
+}
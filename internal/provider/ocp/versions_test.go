@@ -0,0 +1,227 @@
+package ocp
+
+import (
+	"context"
+	"fmt"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"testing"
+
+	"github.com/BadgerOps/airgap/internal/provider"
+)
+
+func TestParseVersionExpr(t *testing.T) {
+	tests := []struct {
+		raw     string
+		wantErr bool
+		check   func(versionExpr) bool
+	}{
+		{raw: "4.18.3", check: func(e versionExpr) bool { return e.literal == "4.18.3" }},
+		{raw: "latest-4.17", check: func(e versionExpr) bool { return e.literal == "latest-4.17" }},
+		{raw: ">=4.16.0 <4.19.0", check: func(e versionExpr) bool { return len(e.comparators) == 2 }},
+		{raw: ">= 4.16 < 4.17", check: func(e versionExpr) bool { return len(e.comparators) == 2 }},
+		{raw: "latest 3 of stable-4.18", check: func(e versionExpr) bool { return e.latestN == 3 && e.channel == "stable-4.18" }},
+		{raw: "all 4.17.z", check: func(e versionExpr) bool { return e.minor == "4.17" }},
+		{raw: "latest 0 of stable-4.18", wantErr: true},
+		{raw: "latest three of stable-4.18", wantErr: true},
+		{raw: ">=4.16.0 <banana", wantErr: true},
+	}
+	for _, tt := range tests {
+		t.Run(tt.raw, func(t *testing.T) {
+			expr, err := parseVersionExpr(tt.raw)
+			if tt.wantErr {
+				if err == nil {
+					t.Fatalf("expected error, got %+v", expr)
+				}
+				return
+			}
+			if err != nil {
+				t.Fatalf("parseVersionExpr() failed: %v", err)
+			}
+			if !tt.check(expr) {
+				t.Errorf("unexpected expression: %+v", expr)
+			}
+		})
+	}
+}
+
+func TestBinariesProviderPlanVersionExpressions(t *testing.T) {
+	listing := `<html><body>
+<a href="4.16.2/">4.16.2/</a>
+<a href="4.17.1/">4.17.1/</a>
+<a href="4.17.10/">4.17.10/</a>
+<a href="4.19.0/">4.19.0/</a>
+<a href="stable-4.17/">stable-4.17/</a>
+<a href="4.18.0-rc.1/">4.18.0-rc.1/</a>
+</body></html>`
+
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		switch {
+		case r.URL.Path == "/ocp/":
+			_, _ = w.Write([]byte(listing))
+		case strings.HasSuffix(r.URL.Path, "/sha256sum.txt"):
+			version := strings.Split(strings.TrimPrefix(r.URL.Path, "/ocp/"), "/")[0]
+			fmt.Fprintf(w, "%s  openshift-client-linux.tar.gz\n", computeSHA256([]byte(version)))
+		default:
+			http.NotFound(w, r)
+		}
+	}))
+	defer server.Close()
+
+	p := NewBinariesProvider(t.TempDir(), testLogger())
+	p.releasesFn = func(_ context.Context, channel, arch string) ([]string, error) {
+		if channel != "stable-4.18" || arch != "" {
+			return nil, fmt.Errorf("unexpected channel %q arch %q", channel, arch)
+		}
+		return []string{"4.18.1", "4.18.10", "4.18.2", "4.18.3"}, nil
+	}
+	err := p.Configure(provider.ProviderConfig{
+		"base_url":   server.URL + "/ocp",
+		"versions":   []interface{}{">=4.16.0 <4.19.0", "all 4.17.z", "latest 2 of stable-4.18"},
+		"output_dir": "ocp",
+	})
+	if err != nil {
+		t.Fatalf("Configure() failed: %v", err)
+	}
+
+	plan, err := p.Plan(context.Background())
+	if err != nil {
+		t.Fatalf("Plan() failed: %v", err)
+	}
+
+	want := map[string]string{
+		">=4.16.0 <4.19.0":        "[4.16.2 4.17.1 4.17.10]",
+		"all 4.17.z":              "[4.17.1 4.17.10]",
+		"latest 2 of stable-4.18": "[4.18.3 4.18.10]",
+	}
+	for expr, versions := range want {
+		if got := fmt.Sprint(plan.Resolved[expr]); got != versions {
+			t.Errorf("Resolved[%q] = %s, want %s", expr, got, versions)
+		}
+	}
+
+	// Overlapping expressions plan each version once.
+	var paths []string
+	for _, a := range plan.Actions {
+		paths = append(paths, a.Path)
+	}
+	wantPaths := []string{
+		"4.16.2/openshift-client-linux.tar.gz",
+		"4.17.1/openshift-client-linux.tar.gz",
+		"4.17.10/openshift-client-linux.tar.gz",
+		"4.18.3/openshift-client-linux.tar.gz",
+		"4.18.10/openshift-client-linux.tar.gz",
+	}
+	if fmt.Sprint(paths) != fmt.Sprint(wantPaths) {
+		t.Errorf("planned paths = %v, want %v", paths, wantPaths)
+	}
+}
+
+func TestBinariesProviderResolvesPerArchitecture(t *testing.T) {
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		if !strings.HasSuffix(r.URL.Path, "/sha256sum.txt") {
+			http.NotFound(w, r)
+			return
+		}
+		fmt.Fprintf(w, "%s  openshift-client-linux.tar.gz\n", computeSHA256([]byte(r.URL.Path)))
+	}))
+	defer server.Close()
+
+	p := NewBinariesProvider(t.TempDir(), testLogger())
+	latest := map[string]string{"amd64": "4.18.10", "arm64": "4.18.9"}
+	p.releasesFn = func(_ context.Context, channel, arch string) ([]string, error) {
+		v, ok := latest[arch]
+		if !ok {
+			return nil, fmt.Errorf("unexpected arch %q", arch)
+		}
+		return []string{"4.18.1", v}, nil
+	}
+	err := p.Configure(provider.ProviderConfig{
+		"base_url":      server.URL + "/ocp/{arch}",
+		"architectures": []interface{}{"x86_64", "aarch64"},
+		"versions":      []interface{}{"latest 1 of stable-4.18"},
+		"output_dir":    "ocp",
+	})
+	if err != nil {
+		t.Fatalf("Configure() failed: %v", err)
+	}
+
+	plan, err := p.Plan(context.Background())
+	if err != nil {
+		t.Fatalf("Plan() failed: %v", err)
+	}
+	want := map[string]string{
+		"latest 1 of stable-4.18 (x86_64)":  "[4.18.10]",
+		"latest 1 of stable-4.18 (aarch64)": "[4.18.9]",
+	}
+	for expr, versions := range want {
+		if got := fmt.Sprint(plan.Resolved[expr]); got != versions {
+			t.Errorf("Resolved[%q] = %s, want %s", expr, got, versions)
+		}
+	}
+}
+
+func TestBinariesProviderReportsUnresolvedVersions(t *testing.T) {
+	listing := `<a href="4.17.1/">4.17.1/</a>`
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		switch r.URL.Path {
+		case "/ocp/":
+			_, _ = w.Write([]byte(listing))
+		case "/ocp/4.17.1/sha256sum.txt":
+			fmt.Fprintf(w, "%s  openshift-client-linux.tar.gz\n", computeSHA256([]byte("4.17.1")))
+		default:
+			http.NotFound(w, r)
+		}
+	}))
+	defer server.Close()
+
+	p := NewBinariesProvider(t.TempDir(), testLogger())
+	p.releasesFn = func(_ context.Context, channel, arch string) ([]string, error) {
+		return nil, fmt.Errorf("graph unavailable")
+	}
+	err := p.Configure(provider.ProviderConfig{
+		"base_url":   server.URL + "/ocp",
+		"versions":   []interface{}{"4.17.1", "4.17.99", "all 4.99.z", "latest 2 of stable-4.18"},
+		"output_dir": "ocp",
+	})
+	if err != nil {
+		t.Fatalf("Configure() failed: %v", err)
+	}
+
+	plan, err := p.Plan(context.Background())
+	if err != nil {
+		t.Fatalf("Plan() failed: %v", err)
+	}
+	if len(plan.Actions) != 1 {
+		t.Errorf("planned %d actions, want 1", len(plan.Actions))
+	}
+
+	got := make(map[string]string)
+	for _, f := range p.PlanFailures() {
+		got[f.Path] = f.Error
+	}
+	if len(got) != 3 {
+		t.Fatalf("PlanFailures() = %v, want 3 failures", p.PlanFailures())
+	}
+	if !strings.Contains(got["4.17.99/sha256sum.txt"], "404") {
+		t.Errorf("missing checksum failure, got %v", got)
+	}
+	if !strings.Contains(got["all 4.99.z"], "matched no releases") {
+		t.Errorf("missing no-match failure, got %v", got)
+	}
+	if !strings.Contains(got["latest 2 of stable-4.18"], "graph unavailable") {
+		t.Errorf("missing resolve failure, got %v", got)
+	}
+}
+
+func TestBinariesProviderConfigureRejectsBadExpression(t *testing.T) {
+	p := NewBinariesProvider(t.TempDir(), testLogger())
+	err := p.Configure(provider.ProviderConfig{
+		"base_url": "https://mirror.example.com/ocp",
+		"versions": []interface{}{"latest 0 of stable-4.18"},
+	})
+	if err == nil {
+		t.Fatal("expected invalid version expression to be rejected")
+	}
+}
@@ -0,0 +1,551 @@
+package operatorcatalog
+
+import (
+	"archive/tar"
+	"bufio"
+	"bytes"
+	"compress/gzip"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"io"
+	"os"
+	"path"
+	"sort"
+	"strings"
+
+	"github.com/klauspost/compress/zstd"
+	"gopkg.in/yaml.v3"
+
+	"github.com/BadgerOps/airgap/internal/config"
+	"github.com/BadgerOps/airgap/internal/ocp"
+)
+
+const (
+	schemaPackage = "olm.package"
+	schemaChannel = "olm.channel"
+	schemaBundle  = "olm.bundle"
+
+	maxCatalogFileBytes int64 = 256 * 1024 * 1024
+)
+
+// fbcObject is a single file-based catalog blob. Fields are kept raw so
+// unknown properties survive pruning unchanged.
+type fbcObject map[string]json.RawMessage
+
+func (o fbcObject) str(key string) string {
+	var s string
+	if raw, ok := o[key]; ok {
+		_ = json.Unmarshal(raw, &s)
+	}
+	return s
+}
+
+type channelEntry struct {
+	Name      string   `json:"name"`
+	Replaces  string   `json:"replaces,omitempty"`
+	Skips     []string `json:"skips,omitempty"`
+	SkipRange string   `json:"skipRange,omitempty"`
+}
+
+type fbcChannel struct {
+	obj     fbcObject
+	name    string
+	entries []channelEntry
+}
+
+type fbcBundle struct {
+	obj     fbcObject
+	name    string
+	image   string
+	version string
+	related []string
+}
+
+type fbcPackage struct {
+	name           string
+	defaultChannel string
+	obj            fbcObject
+	channels       map[string]*fbcChannel
+	bundles        map[string]*fbcBundle
+	others         []fbcObject
+}
+
+// fbcCatalog is a parsed file-based catalog keyed by package name.
+type fbcCatalog struct {
+	packages map[string]*fbcPackage
+}
+
+// packageSelection is the pruned view of one package.
+type packageSelection struct {
+	pkg            *fbcPackage
+	defaultChannel string
+	channels       []string
+	bundles        map[string]struct{}
+}
+
+// readCatalogLayers applies image layers in order and returns the regular
+// files found under configsDir, honouring OCI whiteouts.
+func readCatalogLayers(layerPaths []string, configsDir string) (map[string][]byte, error) {
+	prefix := strings.Trim(path.Clean("/"+configsDir), "/") + "/"
+	files := make(map[string][]byte)
+
+	for _, layerPath := range layerPaths {
+		if err := readCatalogLayer(layerPath, prefix, files); err != nil {
+			return nil, fmt.Errorf("reading layer %s: %w", path.Base(layerPath), err)
+		}
+	}
+
+	out := make(map[string][]byte, len(files))
+	for name, data := range files {
+		out[strings.TrimPrefix(name, prefix)] = data
+	}
+	return out, nil
+}
+
+func readCatalogLayer(layerPath, prefix string, files map[string][]byte) error {
+	f, err := os.Open(layerPath)
+	if err != nil {
+		return err
+	}
+	defer func() {
+		_ = f.Close()
+	}()
+
+	r, closeFn, err := decompressLayer(f)
+	if err != nil {
+		return err
+	}
+	defer closeFn()
+
+	tr := tar.NewReader(r)
+	for {
+		hdr, err := tr.Next()
+		if errors.Is(err, io.EOF) {
+			return nil
+		}
+		if err != nil {
+			return err
+		}
+
+		name := strings.TrimPrefix(path.Clean("/"+hdr.Name), "/")
+		dir, base := path.Split(name)
+		if strings.HasPrefix(base, ".wh.") {
+			target := dir
+			if base != ".wh..wh..opq" {
+				target = dir + strings.TrimPrefix(base, ".wh.")
+			}
+			for p := range files {
+				if p == target || strings.HasPrefix(p, strings.TrimSuffix(target, "/")+"/") {
+					delete(files, p)
+				}
+			}
+			continue
+		}
+		if !strings.HasPrefix(name, prefix) || hdr.Typeflag != tar.TypeReg {
+			continue
+		}
+		data, err := io.ReadAll(io.LimitReader(tr, maxCatalogFileBytes+1))
+		if err != nil {
+			return err
+		}
+		if int64(len(data)) > maxCatalogFileBytes {
+			return fmt.Errorf("catalog file %s exceeds %d bytes", name, maxCatalogFileBytes)
+		}
+		files[name] = data
+	}
+}
+
+// decompressLayer sniffs the layer compression (gzip, zstd or none).
+func decompressLayer(r io.Reader) (io.Reader, func(), error) {
+	br := bufio.NewReader(r)
+	magic, _ := br.Peek(4)
+	switch {
+	case len(magic) >= 2 && magic[0] == 0x1f && magic[1] == 0x8b:
+		gz, err := gzip.NewReader(br)
+		if err != nil {
+			return nil, nil, err
+		}
+		return gz, func() { _ = gz.Close() }, nil
+	case bytes.Equal(magic, []byte{0x28, 0xb5, 0x2f, 0xfd}):
+		zr, err := zstd.NewReader(br)
+		if err != nil {
+			return nil, nil, err
+		}
+		return zr, zr.Close, nil
+	default:
+		return br, func() {}, nil
+	}
+}
+
+// parseCatalog decodes JSON streams and YAML documents into packages.
+func parseCatalog(files map[string][]byte) (*fbcCatalog, error) {
+	cat := &fbcCatalog{packages: make(map[string]*fbcPackage)}
+
+	names := make([]string, 0, len(files))
+	for name := range files {
+		names = append(names, name)
+	}
+	sort.Strings(names)
+
+	for _, name := range names {
+		var objs []fbcObject
+		var err error
+		switch strings.ToLower(path.Ext(name)) {
+		case ".json":
+			objs, err = decodeJSONStream(files[name])
+		case ".yaml", ".yml":
+			objs, err = decodeYAMLStream(files[name])
+		default:
+			continue
+		}
+		if err != nil {
+			return nil, fmt.Errorf("parsing %s: %w", name, err)
+		}
+		for _, obj := range objs {
+			if err := cat.add(obj); err != nil {
+				return nil, fmt.Errorf("parsing %s: %w", name, err)
+			}
+		}
+	}
+	return cat, nil
+}
+
+func decodeJSONStream(data []byte) ([]fbcObject, error) {
+	var objs []fbcObject
+	dec := json.NewDecoder(bytes.NewReader(data))
+	for {
+		var obj fbcObject
+		if err := dec.Decode(&obj); errors.Is(err, io.EOF) {
+			return objs, nil
+		} else if err != nil {
+			return nil, err
+		}
+		objs = append(objs, obj)
+	}
+}
+
+func decodeYAMLStream(data []byte) ([]fbcObject, error) {
+	var objs []fbcObject
+	dec := yaml.NewDecoder(bytes.NewReader(data))
+	for {
+		var doc map[string]interface{}
+		if err := dec.Decode(&doc); errors.Is(err, io.EOF) {
+			return objs, nil
+		} else if err != nil {
+			return nil, err
+		}
+		if doc == nil {
+			continue
+		}
+		raw, err := json.Marshal(doc)
+		if err != nil {
+			return nil, err
+		}
+		var obj fbcObject
+		if err := json.Unmarshal(raw, &obj); err != nil {
+			return nil, err
+		}
+		objs = append(objs, obj)
+	}
+}
+
+func (c *fbcCatalog) pkg(name string) *fbcPackage {
+	p, ok := c.packages[name]
+	if !ok {
+		p = &fbcPackage{
+			name:     name,
+			channels: make(map[string]*fbcChannel),
+			bundles:  make(map[string]*fbcBundle),
+		}
+		c.packages[name] = p
+	}
+	return p
+}
+
+func (c *fbcCatalog) add(obj fbcObject) error {
+	switch obj.str("schema") {
+	case schemaPackage:
+		name := obj.str("name")
+		if name == "" {
+			return fmt.Errorf("olm.package missing name")
+		}
+		p := c.pkg(name)
+		p.obj = obj
+		p.defaultChannel = obj.str("defaultChannel")
+	case schemaChannel:
+		ch := &fbcChannel{obj: obj, name: obj.str("name")}
+		if raw, ok := obj["entries"]; ok {
+			if err := json.Unmarshal(raw, &ch.entries); err != nil {
+				return fmt.Errorf("olm.channel %s entries: %w", ch.name, err)
+			}
+		}
+		c.pkg(obj.str("package")).channels[ch.name] = ch
+	case schemaBundle:
+		b, err := parseBundle(obj)
+		if err != nil {
+			return err
+		}
+		c.pkg(obj.str("package")).bundles[b.name] = b
+	default:
+		if pkgName := obj.str("package"); pkgName != "" {
+			p := c.pkg(pkgName)
+			p.others = append(p.others, obj)
+		}
+	}
+	return nil
+}
+
+func parseBundle(obj fbcObject) (*fbcBundle, error) {
+	b := &fbcBundle{obj: obj, name: obj.str("name"), image: obj.str("image")}
+
+	var props []struct {
+		Type  string          `json:"type"`
+		Value json.RawMessage `json:"value"`
+	}
+	if raw, ok := obj["properties"]; ok {
+		if err := json.Unmarshal(raw, &props); err != nil {
+			return nil, fmt.Errorf("olm.bundle %s properties: %w", b.name, err)
+		}
+	}
+	for _, prop := range props {
+		if prop.Type != schemaPackage {
+			continue
+		}
+		var v struct {
+			Version string `json:"version"`
+		}
+		if err := json.Unmarshal(prop.Value, &v); err == nil {
+			b.version = v.Version
+		}
+	}
+
+	var related []struct {
+		Image string `json:"image"`
+	}
+	if raw, ok := obj["relatedImages"]; ok {
+		if err := json.Unmarshal(raw, &related); err != nil {
+			return nil, fmt.Errorf("olm.bundle %s relatedImages: %w", b.name, err)
+		}
+	}
+	for _, r := range related {
+		if img := strings.TrimSpace(r.Image); img != "" {
+			b.related = append(b.related, img)
+		}
+	}
+	return b, nil
+}
+
+// selectPackages applies the package filters to the catalog.
+func selectPackages(cat *fbcCatalog, filters []config.OperatorPackageFilter) ([]*packageSelection, error) {
+	var out []*packageSelection
+	for _, f := range filters {
+		pkg, ok := cat.packages[f.Name]
+		if !ok || pkg.obj == nil {
+			return nil, fmt.Errorf("package %q not found in catalog", f.Name)
+		}
+
+		channels := f.Channels
+		if len(channels) == 0 {
+			if pkg.defaultChannel == "" {
+				return nil, fmt.Errorf("package %q has no default channel", f.Name)
+			}
+			channels = []string{pkg.defaultChannel}
+		}
+
+		sel := &packageSelection{pkg: pkg, bundles: make(map[string]struct{})}
+		for _, chName := range channels {
+			ch, ok := pkg.channels[chName]
+			if !ok {
+				return nil, fmt.Errorf("channel %q not found in package %q", chName, f.Name)
+			}
+			kept, err := selectChannelBundles(pkg, ch, f.MinVersion, f.MaxVersion)
+			if err != nil {
+				return nil, fmt.Errorf("package %q channel %q: %w", f.Name, chName, err)
+			}
+			if len(kept) == 0 {
+				return nil, fmt.Errorf("package %q channel %q: no bundles match version range", f.Name, chName)
+			}
+			for _, name := range kept {
+				sel.bundles[name] = struct{}{}
+			}
+			sel.channels = append(sel.channels, chName)
+		}
+
+		sel.defaultChannel = pkg.defaultChannel
+		if !containsString(sel.channels, sel.defaultChannel) {
+			sel.defaultChannel = sel.channels[0]
+		}
+		out = append(out, sel)
+	}
+	return out, nil
+}
+
+// selectChannelBundles returns the bundles of a channel within the version
+// range, or only the channel head when no range is given.
+func selectChannelBundles(pkg *fbcPackage, ch *fbcChannel, minVersion, maxVersion string) ([]string, error) {
+	if minVersion == "" && maxVersion == "" {
+		head, err := channelHead(pkg, ch)
+		if err != nil {
+			return nil, err
+		}
+		return []string{head}, nil
+	}
+
+	var kept []string
+	for _, e := range ch.entries {
+		b, ok := pkg.bundles[e.Name]
+		if !ok {
+			continue
+		}
+		if minVersion != "" && ocp.CompareVersions(b.version, minVersion) < 0 {
+			continue
+		}
+		if maxVersion != "" && ocp.CompareVersions(b.version, maxVersion) > 0 {
+			continue
+		}
+		kept = append(kept, e.Name)
+	}
+	return kept, nil
+}
+
+// channelHead finds the entry no other entry replaces or skips. Ties are
+// broken by the highest bundle version.
+func channelHead(pkg *fbcPackage, ch *fbcChannel) (string, error) {
+	replaced := make(map[string]struct{})
+	for _, e := range ch.entries {
+		if e.Replaces != "" {
+			replaced[e.Replaces] = struct{}{}
+		}
+		for _, s := range e.Skips {
+			replaced[s] = struct{}{}
+		}
+	}
+
+	head := ""
+	for _, e := range ch.entries {
+		if _, ok := replaced[e.Name]; ok {
+			continue
+		}
+		if head == "" || ocp.CompareVersions(bundleVersion(pkg, e.Name), bundleVersion(pkg, head)) > 0 {
+			head = e.Name
+		}
+	}
+	if head == "" {
+		return "", fmt.Errorf("unable to determine channel head")
+	}
+	return head, nil
+}
+
+func bundleVersion(pkg *fbcPackage, name string) string {
+	if b, ok := pkg.bundles[name]; ok {
+		return b.version
+	}
+	return ""
+}
+
+// images returns the bundle and related images for the selection.
+func (s *packageSelection) images() []string {
+	var out []string
+	for name := range s.bundles {
+		b, ok := s.pkg.bundles[name]
+		if !ok {
+			continue
+		}
+		if b.image != "" {
+			out = append(out, b.image)
+		}
+		out = append(out, b.related...)
+	}
+	return out
+}
+
+// render writes the pruned package as a JSON stream in opm's layout.
+func (s *packageSelection) render() ([]byte, error) {
+	var buf bytes.Buffer
+	enc := json.NewEncoder(&buf)
+	enc.SetIndent("", "    ")
+	enc.SetEscapeHTML(false)
+
+	pkgObj := cloneObject(s.pkg.obj)
+	if err := setField(pkgObj, "defaultChannel", s.defaultChannel); err != nil {
+		return nil, err
+	}
+	if err := enc.Encode(pkgObj); err != nil {
+		return nil, err
+	}
+
+	for _, chName := range s.channels {
+		ch := s.pkg.channels[chName]
+		var entries []channelEntry
+		for _, e := range ch.entries {
+			if _, ok := s.bundles[e.Name]; !ok {
+				continue
+			}
+			if _, ok := s.bundles[e.Replaces]; !ok {
+				e.Replaces = ""
+			}
+			var skips []string
+			for _, skip := range e.Skips {
+				if _, ok := s.bundles[skip]; ok {
+					skips = append(skips, skip)
+				}
+			}
+			e.Skips = skips
+			entries = append(entries, e)
+		}
+		chObj := cloneObject(ch.obj)
+		if err := setField(chObj, "entries", entries); err != nil {
+			return nil, err
+		}
+		if err := enc.Encode(chObj); err != nil {
+			return nil, err
+		}
+	}
+
+	bundleNames := make([]string, 0, len(s.bundles))
+	for name := range s.bundles {
+		bundleNames = append(bundleNames, name)
+	}
+	sort.Strings(bundleNames)
+	for _, name := range bundleNames {
+		if b, ok := s.pkg.bundles[name]; ok {
+			if err := enc.Encode(b.obj); err != nil {
+				return nil, err
+			}
+		}
+	}
+
+	for _, obj := range s.pkg.others {
+		if err := enc.Encode(obj); err != nil {
+			return nil, err
+		}
+	}
+	return buf.Bytes(), nil
+}
+
+func cloneObject(obj fbcObject) fbcObject {
+	out := make(fbcObject, len(obj))
+	for k, v := range obj {
+		out[k] = v
+	}
+	return out
+}
+
+func setField(obj fbcObject, key string, value interface{}) error {
+	raw, err := json.Marshal(value)
+	if err != nil {
+		return err
+	}
+	obj[key] = raw
+	return nil
+}
+
+func containsString(list []string, s string) bool {
+	for _, v := range list {
+		if v == s {
+			return true
+		}
+	}
+	return false
+}
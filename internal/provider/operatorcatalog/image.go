@@ -0,0 +1,295 @@
+package operatorcatalog
+
+import (
+	"archive/tar"
+	"bytes"
+	"compress/gzip"
+	"crypto/sha256"
+	"encoding/hex"
+	"encoding/json"
+	"fmt"
+	"path"
+	"sort"
+	"strings"
+	"time"
+)
+
+const (
+	mediaTypeDockerManifest = "application/vnd.docker.distribution.manifest.v2+json"
+	mediaTypeDockerLayer    = "application/vnd.docker.image.rootfs.diff.tar.gzip"
+	mediaTypeOCIManifest    = "application/vnd.oci.image.manifest.v1+json"
+	mediaTypeOCILayer       = "application/vnd.oci.image.layer.v1.tar+gzip"
+
+	// opm keeps a pre-built serving cache here; it is stale once /configs changes.
+	opmCacheDir = "tmp/cache"
+)
+
+type descriptor struct {
+	MediaType string `json:"mediaType"`
+	Digest    string `json:"digest"`
+	Size      int64  `json:"size"`
+}
+
+// baseManifest is a single-platform image manifest with raw fields retained.
+type baseManifest struct {
+	raw       map[string]json.RawMessage
+	mediaType string
+	config    descriptor
+	layers    []descriptor
+}
+
+func parseBaseManifest(mediaType string, body []byte) (*baseManifest, error) {
+	m := &baseManifest{mediaType: mediaType}
+	if err := json.Unmarshal(body, &m.raw); err != nil {
+		return nil, fmt.Errorf("parsing manifest: %w", err)
+	}
+	if raw, ok := m.raw["mediaType"]; ok {
+		_ = json.Unmarshal(raw, &m.mediaType)
+	}
+	if raw, ok := m.raw["config"]; ok {
+		if err := json.Unmarshal(raw, &m.config); err != nil {
+			return nil, fmt.Errorf("parsing manifest config: %w", err)
+		}
+	}
+	if raw, ok := m.raw["layers"]; ok {
+		if err := json.Unmarshal(raw, &m.layers); err != nil {
+			return nil, fmt.Errorf("parsing manifest layers: %w", err)
+		}
+	}
+	if m.config.Digest == "" || len(m.layers) == 0 {
+		return nil, fmt.Errorf("unsupported manifest type %q", m.mediaType)
+	}
+	if m.mediaType == "" {
+		m.mediaType = mediaTypeOCIManifest
+	}
+	return m, nil
+}
+
+// selectPlatformManifest picks the index child matching "os/arch[/variant]".
+func selectPlatformManifest(body []byte, platform string) (descriptor, error) {
+	var idx struct {
+		Manifests []struct {
+			descriptor
+			Platform struct {
+				OS           string `json:"os"`
+				Architecture string `json:"architecture"`
+				Variant      string `json:"variant"`
+			} `json:"platform"`
+		} `json:"manifests"`
+	}
+	if err := json.Unmarshal(body, &idx); err != nil {
+		return descriptor{}, fmt.Errorf("parsing image index: %w", err)
+	}
+	for _, m := range idx.Manifests {
+		p := m.Platform.OS + "/" + m.Platform.Architecture
+		if m.Platform.Variant != "" && strings.Count(platform, "/") == 2 {
+			p += "/" + m.Platform.Variant
+		}
+		if p == platform {
+			return m.descriptor, nil
+		}
+	}
+	return descriptor{}, fmt.Errorf("no manifest for platform %s in image index", platform)
+}
+
+// catalogConfigsDir reads the configs location label from an image config.
+func catalogConfigsDir(configBody []byte) string {
+	var cfg struct {
+		Config struct {
+			Labels map[string]string `json:"Labels"`
+		} `json:"config"`
+	}
+	if err := json.Unmarshal(configBody, &cfg); err == nil {
+		if dir := cfg.Config.Labels[configsLabel]; dir != "" {
+			return dir
+		}
+	}
+	return "/configs"
+}
+
+// prunedImage is a rebuilt catalog image: the base layers plus one layer
+// that replaces the configs directory with the pruned catalog.
+type prunedImage struct {
+	Manifest  []byte
+	Config    []byte
+	Layer     []byte
+	MediaType string
+}
+
+// buildPrunedImage appends a layer to base that opaquely replaces configsDir
+// with files and hides the stale opm cache. The image command is adjusted so
+// opm rebuilds the cache instead of refusing to serve.
+func buildPrunedImage(base *baseManifest, baseConfig []byte, configsDir string, files map[string][]byte) (*prunedImage, error) {
+	layer, diffID, err := buildConfigsLayer(configsDir, files)
+	if err != nil {
+		return nil, err
+	}
+
+	var cfg map[string]interface{}
+	if err := json.Unmarshal(baseConfig, &cfg); err != nil {
+		return nil, fmt.Errorf("parsing image config: %w", err)
+	}
+	rootfs, _ := cfg["rootfs"].(map[string]interface{})
+	if rootfs == nil {
+		return nil, fmt.Errorf("image config missing rootfs")
+	}
+	diffIDs, _ := rootfs["diff_ids"].([]interface{})
+	rootfs["diff_ids"] = append(diffIDs, diffID)
+	if history, ok := cfg["history"].([]interface{}); ok {
+		cfg["history"] = append(history, map[string]interface{}{
+			"created_by": "airgap operator_catalog: prune " + path.Clean("/"+configsDir),
+		})
+	}
+	if runtime, ok := cfg["config"].(map[string]interface{}); ok {
+		runtime["Cmd"] = disableCacheIntegrity(runtime["Cmd"])
+	}
+	configBytes, err := json.Marshal(cfg)
+	if err != nil {
+		return nil, fmt.Errorf("marshaling image config: %w", err)
+	}
+
+	layerMediaType := mediaTypeOCILayer
+	if base.mediaType == mediaTypeDockerManifest {
+		layerMediaType = mediaTypeDockerLayer
+	}
+
+	manifest := make(map[string]json.RawMessage, len(base.raw))
+	for k, v := range base.raw {
+		manifest[k] = v
+	}
+	configDesc := base.config
+	configDesc.Digest = digestOf(configBytes)
+	configDesc.Size = int64(len(configBytes))
+	if err := setRaw(manifest, "config", configDesc); err != nil {
+		return nil, err
+	}
+	var layers []json.RawMessage
+	if err := json.Unmarshal(base.raw["layers"], &layers); err != nil {
+		return nil, fmt.Errorf("parsing manifest layers: %w", err)
+	}
+	newLayer, err := json.Marshal(descriptor{MediaType: layerMediaType, Digest: digestOf(layer), Size: int64(len(layer))})
+	if err != nil {
+		return nil, err
+	}
+	if err := setRaw(manifest, "layers", append(layers, newLayer)); err != nil {
+		return nil, err
+	}
+	manifestBytes, err := json.Marshal(manifest)
+	if err != nil {
+		return nil, fmt.Errorf("marshaling manifest: %w", err)
+	}
+
+	return &prunedImage{
+		Manifest:  manifestBytes,
+		Config:    configBytes,
+		Layer:     layer,
+		MediaType: base.mediaType,
+	}, nil
+}
+
+// buildConfigsLayer returns a gzipped tar layer and its uncompressed diff ID.
+func buildConfigsLayer(configsDir string, files map[string][]byte) ([]byte, string, error) {
+	root := strings.Trim(path.Clean("/"+configsDir), "/")
+	epoch := time.Unix(0, 0)
+
+	var tarBuf bytes.Buffer
+	tw := tar.NewWriter(&tarBuf)
+	writeDir := func(name string) error {
+		return tw.WriteHeader(&tar.Header{Typeflag: tar.TypeDir, Name: name + "/", Mode: 0o755, ModTime: epoch})
+	}
+	writeFile := func(name string, data []byte) error {
+		if err := tw.WriteHeader(&tar.Header{Typeflag: tar.TypeReg, Name: name, Mode: 0o644, Size: int64(len(data)), ModTime: epoch}); err != nil {
+			return err
+		}
+		_, err := tw.Write(data)
+		return err
+	}
+
+	if err := writeDir(root); err != nil {
+		return nil, "", err
+	}
+	if err := writeFile(root+"/.wh..wh..opq", nil); err != nil {
+		return nil, "", err
+	}
+
+	names := make([]string, 0, len(files))
+	for name := range files {
+		names = append(names, name)
+	}
+	sort.Strings(names)
+	seenDirs := map[string]bool{root: true}
+	for _, name := range names {
+		full := root + "/" + strings.TrimPrefix(path.Clean("/"+name), "/")
+		var parents []string
+		for dir := path.Dir(full); !seenDirs[dir]; dir = path.Dir(dir) {
+			parents = append([]string{dir}, parents...)
+			seenDirs[dir] = true
+		}
+		for _, dir := range parents {
+			if err := writeDir(dir); err != nil {
+				return nil, "", err
+			}
+		}
+		if err := writeFile(full, files[name]); err != nil {
+			return nil, "", err
+		}
+	}
+
+	if err := writeDir(opmCacheDir); err != nil {
+		return nil, "", err
+	}
+	if err := writeFile(opmCacheDir+"/.wh..wh..opq", nil); err != nil {
+		return nil, "", err
+	}
+	if err := tw.Close(); err != nil {
+		return nil, "", err
+	}
+	diffID := digestOf(tarBuf.Bytes())
+
+	var gzBuf bytes.Buffer
+	gz := gzip.NewWriter(&gzBuf)
+	if _, err := gz.Write(tarBuf.Bytes()); err != nil {
+		return nil, "", err
+	}
+	if err := gz.Close(); err != nil {
+		return nil, "", err
+	}
+	return gzBuf.Bytes(), diffID, nil
+}
+
+// disableCacheIntegrity appends --cache-enforce-integrity=false to an opm
+// serve command that uses a cache directory.
+func disableCacheIntegrity(cmd interface{}) interface{} {
+	args, ok := cmd.([]interface{})
+	if !ok {
+		return cmd
+	}
+	usesCache := false
+	for _, a := range args {
+		s, _ := a.(string)
+		if strings.HasPrefix(s, "--cache-enforce-integrity") {
+			return cmd
+		}
+		if strings.HasPrefix(s, "--cache-dir") {
+			usesCache = true
+		}
+	}
+	if !usesCache {
+		return cmd
+	}
+	return append(args, "--cache-enforce-integrity=false")
+}
+
+func setRaw(obj map[string]json.RawMessage, key string, value interface{}) error {
+	raw, err := json.Marshal(value)
+	if err != nil {
+		return err
+	}
+	obj[key] = raw
+	return nil
+}
+
+func digestOf(data []byte) string {
+	sum := sha256.Sum256(data)
+	return "sha256:" + hex.EncodeToString(sum[:])
+}
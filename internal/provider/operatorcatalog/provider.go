@@ -0,0 +1,682 @@
+package operatorcatalog
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"io/fs"
+	"log/slog"
+	"net/http"
+	"os"
+	"path"
+	"path/filepath"
+	"sort"
+	"strings"
+	"time"
+
+	"github.com/BadgerOps/airgap/internal/config"
+	"github.com/BadgerOps/airgap/internal/ocp"
+	"github.com/BadgerOps/airgap/internal/provider"
+	"github.com/BadgerOps/airgap/internal/provider/containerimages"
+	"github.com/BadgerOps/airgap/internal/safety"
+)
+
+const (
+	defaultOutputDir = "operators"
+	defaultPlatform  = "linux/amd64"
+	catalogsDir      = "catalogs"
+	imageListFile    = "images.json"
+	configsLabel     = "operators.operatorframework.io.index.configs.v1"
+)
+
+// containerfileTemplate rebuilds the pruned catalog from its configs directory.
+const containerfileTemplate = `# Rebuild the pruned catalog for %s with:
+#   podman build -t <registry>/<repository>:<tag> -f Containerfile .
+ARG OPM_IMAGE=quay.io/operator-framework/opm:latest
+FROM ${OPM_IMAGE}
+ENTRYPOINT ["/bin/opm"]
+CMD ["serve", "/configs", "--cache-dir=/tmp/cache"]
+ADD configs /configs
+RUN ["/bin/opm", "serve", "/configs", "--cache-dir=/tmp/cache", "--cache-only"]
+LABEL operators.operatorframework.io.index.configs.v1=/configs
+`
+
+// ImageList is the per-catalog record of images selected by the last plan.
+// Catalog is the pruned catalog image, stored under the index reference.
+type ImageList struct {
+	Catalog string   `json:"catalog"`
+	Images  []string `json:"images"`
+}
+
+// Provider mirrors operator catalogs pruned to selected packages, along with
+// every bundle and related image those packages reference.
+type Provider struct {
+	name    string
+	cfg     *config.OperatorCatalogProviderConfig
+	dataDir string
+	logger  *slog.Logger
+	http    *http.Client
+
+	// State of the last Plan, completed by PostSync.
+	images    *containerimages.Provider
+	catalogs  []*catalogState
+	planned   map[string]struct{}
+	generated []provider.GeneratedFile
+	failures  []provider.FailedFile
+}
+
+// catalogState is a catalog index planned by the last Plan.
+type catalogState struct {
+	entry    config.OperatorCatalogEntry
+	ref      containerimages.ImageReference
+	imageRel string
+	base     *baseManifest
+	// pruned is set once the index layers are local.
+	pruned *prunedCatalog
+}
+
+// prunedCatalog is a catalog pruned to the selected packages.
+type prunedCatalog struct {
+	packages int
+	configs  map[string][]byte
+	list     *ImageList
+	image    *prunedImage
+}
+
+// NewProvider creates a new operator catalog provider.
+func NewProvider(dataDir string, logger *slog.Logger) *Provider {
+	if logger == nil {
+		logger = slog.Default()
+	}
+	return &Provider{
+		name:    "operator_catalog",
+		dataDir: dataDir,
+		logger:  logger,
+		http:    safety.NewHTTPClient(90 * time.Second),
+	}
+}
+
+func (p *Provider) Name() string { return p.name }
+
+func (p *Provider) SetName(name string) { p.name = name }
+
+func (p *Provider) Type() string { return "operator_catalog" }
+
+func (p *Provider) Configure(rawCfg provider.ProviderConfig) error {
+	cfg, err := config.ParseProviderConfig[config.OperatorCatalogProviderConfig](rawCfg)
+	if err != nil {
+		return fmt.Errorf("parsing operator catalog config: %w", err)
+	}
+
+	if cfg.OutputDir == "" {
+		cfg.OutputDir = defaultOutputDir
+	}
+	if _, err := safety.CleanRelativePath(cfg.OutputDir); err != nil {
+		return fmt.Errorf("invalid output_dir: %w", err)
+	}
+	if cfg.Platform == "" {
+		cfg.Platform = defaultPlatform
+	}
+	if n := strings.Count(cfg.Platform, "/"); n < 1 || n > 2 {
+		return fmt.Errorf("invalid platform %q: expected os/arch[/variant]", cfg.Platform)
+	}
+
+	for i, cat := range cfg.Catalogs {
+		ref, err := containerimages.ParseReference(cat.Index)
+		if err != nil {
+			return fmt.Errorf("invalid catalog index %q: %w", cat.Index, err)
+		}
+		if ref.IsDigest {
+			return fmt.Errorf("catalog index %q must be referenced by tag so the pruned catalog can replace it", cat.Index)
+		}
+		if len(cat.Packages) == 0 {
+			return fmt.Errorf("catalog %q: at least one package is required", cat.Index)
+		}
+		for j, pkg := range cat.Packages {
+			if strings.TrimSpace(pkg.Name) == "" {
+				return fmt.Errorf("catalog %q: package name is required", cat.Index)
+			}
+			for _, v := range []string{pkg.MinVersion, pkg.MaxVersion} {
+				if v != "" && !ocp.ValidVersion(v) {
+					return fmt.Errorf("catalog %q package %q: invalid version %q", cat.Index, pkg.Name, v)
+				}
+			}
+			cfg.Catalogs[i].Packages[j].Name = strings.TrimSpace(pkg.Name)
+		}
+	}
+
+	p.cfg = cfg
+	p.logger.Debug("configured operator catalog provider",
+		slog.Int("catalogs", len(cfg.Catalogs)),
+		slog.String("platform", cfg.Platform),
+		slog.String("output_dir", cfg.OutputDir),
+	)
+	return nil
+}
+
+// Plan fetches each catalog index manifest and plans its config and layers
+// for download. Catalogs whose layers are already local are pruned in memory
+// and their bundle and related images planned as well; the others are
+// pruned by PostSync once the sync has downloaded their layers. Plan writes
+// nothing to disk.
+func (p *Provider) Plan(ctx context.Context) (*provider.SyncPlan, error) {
+	if p.cfg == nil {
+		return nil, fmt.Errorf("provider not configured")
+	}
+
+	p.catalogs, p.generated, p.failures = nil, nil, nil
+	p.planned = make(map[string]struct{})
+	p.images = containerimages.NewProvider(p.dataDir, p.logger)
+	p.images.SetName(p.Name())
+	p.images.SetHTTPClient(p.http)
+	if err := p.images.Configure(p.imagesConfig(nil)); err != nil {
+		return nil, fmt.Errorf("configuring image planner: %w", err)
+	}
+
+	var actions []provider.SyncAction
+	var refs []string
+	for _, entry := range p.cfg.Catalogs {
+		cat, catActions, err := p.planCatalog(ctx, entry)
+		if err != nil {
+			p.logger.Error("failed to plan operator catalog", "index", entry.Index, "error", err)
+			p.failures = append(p.failures, provider.FailedFile{Path: entry.Index, Error: err.Error()})
+			continue
+		}
+		p.catalogs = append(p.catalogs, cat)
+		actions = append(actions, catActions...)
+		if cat.pruned == nil {
+			p.logger.Info("catalog index layers not downloaded yet; bundle images are planned after they are", "index", entry.Index)
+			continue
+		}
+		actions = append(actions, p.catalogOutput(cat)...)
+		refs = append(refs, cat.pruned.list.Images...)
+	}
+
+	imageActions, err := p.planImages(ctx, refs)
+	if err != nil {
+		return nil, err
+	}
+	plan := p.newPlan(append(actions, imageActions...))
+	for _, cat := range p.catalogs {
+		if cat.pruned == nil {
+			plan.Incomplete = true
+		}
+	}
+	return plan, nil
+}
+
+// PostSync prunes the catalogs whose index layers the sync downloaded and
+// returns the plan for their bundle and related images, along with deletes
+// for the images no catalog references any more.
+func (p *Provider) PostSync(ctx context.Context) (*provider.SyncPlan, error) {
+	var actions []provider.SyncAction
+	var refs []string
+	var errs []error
+	for _, cat := range p.catalogs {
+		if cat.pruned != nil {
+			continue
+		}
+		pruned, err := p.pruneCatalog(cat)
+		if err != nil {
+			errs = append(errs, fmt.Errorf("catalog %s: %w", cat.entry.Index, err))
+			continue
+		}
+		cat.pruned = pruned
+		actions = append(actions, p.catalogOutput(cat)...)
+		refs = append(refs, pruned.list.Images...)
+	}
+
+	imageActions, err := p.planImages(ctx, refs)
+	if err != nil {
+		errs = append(errs, err)
+	}
+	actions = append(actions, imageActions...)
+	if len(errs) == 0 {
+		actions = append(actions, p.staleImages()...)
+	}
+	return p.newPlan(actions), errors.Join(errs...)
+}
+
+// GeneratedFiles returns the pruned catalogs and catalog images built by
+// the last Plan and PostSync.
+func (p *Provider) GeneratedFiles() []provider.GeneratedFile {
+	return p.generated
+}
+
+// PlanFailures returns the catalogs the last Plan could not read.
+func (p *Provider) PlanFailures() []provider.FailedFile {
+	return p.failures
+}
+
+// planImages plans the images of refs that were not planned before.
+func (p *Provider) planImages(ctx context.Context, refs []string) ([]provider.SyncAction, error) {
+	var pending []string
+	for _, ref := range refs {
+		if _, ok := p.planned[ref]; ok {
+			continue
+		}
+		p.planned[ref] = struct{}{}
+		pending = append(pending, ref)
+	}
+	if len(pending) == 0 {
+		return nil, nil
+	}
+	if err := p.images.Configure(p.imagesConfig(pending)); err != nil {
+		return nil, fmt.Errorf("configuring image planner: %w", err)
+	}
+	plan, err := p.images.Plan(ctx)
+	if err != nil {
+		return nil, err
+	}
+	p.generated = append(p.generated, p.images.GeneratedFiles()...)
+	return plan.Actions, nil
+}
+
+// newPlan returns a plan of actions sorted by path, keeping the first action
+// for each path.
+func (p *Provider) newPlan(actions []provider.SyncAction) *provider.SyncPlan {
+	plan := &provider.SyncPlan{
+		Provider:  p.Name(),
+		Actions:   []provider.SyncAction{},
+		Timestamp: time.Now(),
+	}
+
+	actionsByPath := make(map[string]provider.SyncAction, len(actions))
+	for _, action := range actions {
+		if _, ok := actionsByPath[action.Path]; !ok {
+			actionsByPath[action.Path] = action
+		}
+	}
+	keys := make([]string, 0, len(actionsByPath))
+	for path := range actionsByPath {
+		keys = append(keys, path)
+	}
+	sort.Strings(keys)
+
+	for _, path := range keys {
+		action := actionsByPath[path]
+		plan.Actions = append(plan.Actions, action)
+		plan.TotalFiles++
+		if action.Action == provider.ActionDownload || action.Action == provider.ActionUpdate {
+			plan.TotalSize += action.Size
+		}
+	}
+	return plan
+}
+
+func (p *Provider) Sync(ctx context.Context, plan *provider.SyncPlan, opts provider.SyncOptions) (*provider.SyncReport, error) {
+	report := &provider.SyncReport{
+		Provider:  p.Name(),
+		StartTime: time.Now(),
+		Failed:    []provider.FailedFile{},
+	}
+	if opts.DryRun {
+		report.EndTime = time.Now()
+		return report, nil
+	}
+
+	for _, action := range plan.Actions {
+		switch action.Action {
+		case provider.ActionSkip:
+			report.Skipped++
+		case provider.ActionDownload, provider.ActionUpdate:
+			report.Downloaded++
+			report.BytesTransferred += action.Size
+		case provider.ActionDelete:
+			report.Deleted++
+		}
+	}
+	report.EndTime = time.Now()
+	return report, nil
+}
+
+// Validate checks every digest-addressed manifest and blob under output_dir.
+func (p *Provider) Validate(ctx context.Context) (*provider.ValidationReport, error) {
+	if p.cfg == nil {
+		return nil, fmt.Errorf("provider not configured")
+	}
+	images := containerimages.NewProvider(p.dataDir, p.logger)
+	images.SetName(p.Name())
+	if err := images.Configure(p.imagesConfig(nil)); err != nil {
+		return nil, err
+	}
+	return images.Validate(ctx)
+}
+
+// imagesConfig returns the container_images config used to fetch catalog
+// and bundle images, carrying over the pull credentials.
+func (p *Provider) imagesConfig(refs []string) provider.ProviderConfig {
+	credentials := make([]interface{}, 0, len(p.cfg.Credentials))
+	for _, c := range p.cfg.Credentials {
+		credentials = append(credentials, map[string]interface{}{
+			"registry": c.Registry,
+			"username": c.Username,
+			"password": c.Password,
+			"helper":   c.Helper,
+		})
+	}
+	return provider.ProviderConfig{
+		"images":      refs,
+		"output_dir":  p.cfg.OutputDir,
+		"auth_file":   p.cfg.AuthFile,
+		"credentials": credentials,
+	}
+}
+
+// planCatalog fetches the index manifest, plans its config and layers and,
+// when they are all local, prunes the catalog.
+func (p *Provider) planCatalog(ctx context.Context, entry config.OperatorCatalogEntry) (*catalogState, []provider.SyncAction, error) {
+	ref, err := containerimages.ParseReference(entry.Index)
+	if err != nil {
+		return nil, nil, err
+	}
+
+	mediaType, _, body, err := p.images.FetchManifest(ctx, ref, ref.Reference)
+	if err != nil {
+		return nil, nil, fmt.Errorf("fetching index manifest: %w", err)
+	}
+	if strings.Contains(mediaType, "index") || strings.Contains(mediaType, "manifest.list") {
+		child, err := selectPlatformManifest(body, p.cfg.Platform)
+		if err != nil {
+			return nil, nil, err
+		}
+		mediaType, _, body, err = p.images.FetchManifest(ctx, ref, child.Digest)
+		if err != nil {
+			return nil, nil, fmt.Errorf("fetching %s manifest: %w", p.cfg.Platform, err)
+		}
+	}
+	base, err := parseBaseManifest(mediaType, body)
+	if err != nil {
+		return nil, nil, err
+	}
+
+	imageID := containerimages.LocalImageID(ref)
+	cat := &catalogState{entry: entry, ref: ref, imageRel: path.Join(p.cfg.OutputDir, imageID), base: base}
+	local := true
+	actions := make([]provider.SyncAction, 0, len(base.layers)+1)
+	for _, desc := range append([]descriptor{base.config}, base.layers...) {
+		action, err := p.images.BlobAction(ref, imageID, desc.Digest, desc.Size)
+		if err != nil {
+			return nil, nil, err
+		}
+		if action.Action != provider.ActionSkip {
+			local = false
+		}
+		actions = append(actions, action)
+	}
+
+	if local {
+		if cat.pruned, err = p.pruneCatalog(cat); err != nil {
+			return nil, nil, err
+		}
+	}
+	return cat, actions, nil
+}
+
+// pruneCatalog reads the local index config and layers, prunes the catalog
+// to the selected packages and builds the rebuilt catalog image.
+func (p *Provider) pruneCatalog(cat *catalogState) (*prunedCatalog, error) {
+	configPath, err := p.localPath(blobRelPath(cat.imageRel, cat.base.config.Digest))
+	if err != nil {
+		return nil, err
+	}
+	baseConfig, err := os.ReadFile(configPath)
+	if err != nil {
+		return nil, fmt.Errorf("reading index config: %w", err)
+	}
+
+	layerPaths := make([]string, 0, len(cat.base.layers))
+	for _, layer := range cat.base.layers {
+		layerPath, err := p.localPath(blobRelPath(cat.imageRel, layer.Digest))
+		if err != nil {
+			return nil, err
+		}
+		layerPaths = append(layerPaths, layerPath)
+	}
+
+	configsDir := catalogConfigsDir(baseConfig)
+	files, err := readCatalogLayers(layerPaths, configsDir)
+	if err != nil {
+		return nil, err
+	}
+	parsed, err := parseCatalog(files)
+	if err != nil {
+		return nil, err
+	}
+	selections, err := selectPackages(parsed, cat.entry.Packages)
+	if err != nil {
+		return nil, err
+	}
+
+	pruned := &prunedCatalog{
+		packages: len(selections),
+		configs:  make(map[string][]byte, len(selections)),
+		list:     &ImageList{Catalog: cat.ref.Raw},
+	}
+	for _, sel := range selections {
+		data, err := sel.render()
+		if err != nil {
+			return nil, fmt.Errorf("rendering package %s: %w", sel.pkg.name, err)
+		}
+		pruned.configs[path.Join(sel.pkg.name, "catalog.json")] = data
+		pruned.list.Images = append(pruned.list.Images, sel.images()...)
+	}
+	pruned.list.Images = uniqueSorted(pruned.list.Images)
+
+	pruned.image, err = buildPrunedImage(cat.base, baseConfig, configsDir, pruned.configs)
+	if err != nil {
+		return nil, fmt.Errorf("building pruned catalog image: %w", err)
+	}
+
+	p.logger.Info("pruned operator catalog",
+		"index", cat.entry.Index,
+		"packages", pruned.packages,
+		"images", len(pruned.list.Images),
+	)
+	return pruned, nil
+}
+
+// catalogOutput adds the pruned catalog of cat and its rebuilt image, stored
+// in the same manifests/blobs layout as mirrored images so registry push can
+// load it, to the generated files. It returns deletes for the files of an
+// earlier pruned catalog that are no longer part of it.
+func (p *Provider) catalogOutput(cat *catalogState) []provider.SyncAction {
+	pruned := cat.pruned
+	catalogRel := path.Join(p.cfg.OutputDir, catalogsDir, containerimages.LocalImageID(cat.ref))
+	keep := make(map[string]bool)
+	add := func(relPath string, data []byte, reason string) {
+		p.generated = append(p.generated, provider.GeneratedFile{Path: relPath, Data: data, Reason: reason})
+		keep[relPath] = true
+	}
+
+	names := make([]string, 0, len(pruned.configs))
+	for name := range pruned.configs {
+		names = append(names, name)
+	}
+	sort.Strings(names)
+	for _, name := range names {
+		add(path.Join(catalogRel, "configs", name), pruned.configs[name], "pruned catalog")
+	}
+	add(path.Join(catalogRel, "Containerfile"), []byte(fmt.Sprintf(containerfileTemplate, cat.ref.Raw)), "pruned catalog")
+	listBytes, _ := json.MarshalIndent(pruned.list, "", "  ")
+	add(path.Join(catalogRel, imageListFile), listBytes, "pruned catalog")
+
+	for _, blob := range [][]byte{pruned.image.Config, pruned.image.Layer} {
+		add(blobRelPath(cat.imageRel, digestOf(blob)), blob, "pruned catalog image")
+	}
+	_, hash, _ := strings.Cut(digestOf(pruned.image.Manifest), ":")
+	add(path.Join(cat.imageRel, "manifests", "sha256", hash+".json"), pruned.image.Manifest, "pruned catalog image")
+
+	for _, desc := range append([]descriptor{cat.base.config}, cat.base.layers...) {
+		keep[blobRelPath(cat.imageRel, desc.Digest)] = true
+	}
+
+	stale := p.staleFiles(path.Join(catalogRel, "configs"), keep)
+	stale = append(stale, p.staleFiles(path.Join(cat.imageRel, "manifests"), keep)...)
+	return append(stale, p.staleFiles(path.Join(cat.imageRel, "blobs"), keep)...)
+}
+
+// staleImages returns deletes for the image and pruned catalog directories
+// under output_dir that no configured catalog references any more, such as
+// the bundles of versions a catalog no longer selects. It returns nothing
+// unless every catalog was read and pruned, since the images of the others
+// are unknown.
+func (p *Provider) staleImages() []provider.SyncAction {
+	if len(p.failures) > 0 {
+		return nil
+	}
+	keep := make(map[string]bool)
+	for _, cat := range p.catalogs {
+		if cat.pruned == nil {
+			return nil
+		}
+		id := containerimages.LocalImageID(cat.ref)
+		keep[id] = true
+		keep[path.Join(catalogsDir, id)] = true
+		for _, raw := range cat.pruned.list.Images {
+			ref, err := containerimages.ParseReference(raw)
+			if err != nil {
+				return nil
+			}
+			keep[containerimages.LocalImageID(ref)] = true
+		}
+	}
+
+	var actions []provider.SyncAction
+	for _, dirRel := range []string{"", catalogsDir} {
+		dir, err := p.localPath(path.Join(p.cfg.OutputDir, dirRel))
+		if err != nil {
+			return nil
+		}
+		entries, err := os.ReadDir(dir)
+		if err != nil {
+			continue
+		}
+		for _, entry := range entries {
+			name := path.Join(dirRel, entry.Name())
+			if !entry.IsDir() || name == catalogsDir || keep[name] {
+				continue
+			}
+			actions = append(actions, p.staleFiles(path.Join(p.cfg.OutputDir, name), nil)...)
+		}
+	}
+	return actions
+}
+
+// staleFiles returns deletes for the local files under dirRel not in keep.
+func (p *Provider) staleFiles(dirRel string, keep map[string]bool) []provider.SyncAction {
+	dir, err := p.localPath(dirRel)
+	if err != nil {
+		return nil
+	}
+	var actions []provider.SyncAction
+	_ = filepath.WalkDir(dir, func(localPath string, d fs.DirEntry, err error) error {
+		if err != nil || d.IsDir() {
+			return nil
+		}
+		rel, err := filepath.Rel(dir, localPath)
+		if err != nil {
+			return nil
+		}
+		relPath := path.Join(dirRel, filepath.ToSlash(rel))
+		if !keep[relPath] {
+			actions = append(actions, provider.SyncAction{
+				Path:      relPath,
+				LocalPath: localPath,
+				Action:    provider.ActionDelete,
+				Reason:    "no longer in pruned catalog",
+			})
+		}
+		return nil
+	})
+	return actions
+}
+
+func (p *Provider) localPath(relPath string) (string, error) {
+	providerRoot := filepath.Join(p.dataDir, p.Name())
+	return safety.SafeJoinUnder(providerRoot, relPath)
+}
+
+func blobRelPath(imageRel, digest string) string {
+	algo, hash, _ := strings.Cut(digest, ":")
+	return path.Join(imageRel, "blobs", algo, hash)
+}
+
+func uniqueSorted(in []string) []string {
+	seen := make(map[string]struct{}, len(in))
+	out := make([]string, 0, len(in))
+	for _, v := range in {
+		if _, ok := seen[v]; ok {
+			continue
+		}
+		seen[v] = struct{}{}
+		out = append(out, v)
+	}
+	sort.Strings(out)
+	return out
+}
+
+// MirroredImages returns the image references recorded by the last plan of an
+// operator catalog provider, listing each pruned catalog before its images.
+func MirroredImages(providerRoot, outputDir string) ([]string, error) {
+	lists, err := readImageLists(providerRoot, outputDir)
+	if err != nil {
+		return nil, err
+	}
+
+	seen := make(map[string]struct{})
+	var out []string
+	for _, list := range lists {
+		for _, ref := range append([]string{list.Catalog}, list.Images...) {
+			if _, ok := seen[ref]; ok || ref == "" {
+				continue
+			}
+			seen[ref] = struct{}{}
+			out = append(out, ref)
+		}
+	}
+	return out, nil
+}
+
+// MirroredCatalogs returns the pruned catalog references recorded by the last
+// plan of an operator catalog provider.
+func MirroredCatalogs(providerRoot, outputDir string) ([]string, error) {
+	lists, err := readImageLists(providerRoot, outputDir)
+	if err != nil {
+		return nil, err
+	}
+	var out []string
+	for _, list := range lists {
+		if list.Catalog != "" {
+			out = append(out, list.Catalog)
+		}
+	}
+	return out, nil
+}
+
+func readImageLists(providerRoot, outputDir string) ([]ImageList, error) {
+	if outputDir == "" {
+		outputDir = defaultOutputDir
+	}
+	pattern := filepath.Join(providerRoot, outputDir, catalogsDir, "*", imageListFile)
+	matches, err := filepath.Glob(pattern)
+	if err != nil {
+		return nil, fmt.Errorf("listing catalog image lists: %w", err)
+	}
+	sort.Strings(matches)
+
+	lists := make([]ImageList, 0, len(matches))
+	for _, m := range matches {
+		data, err := os.ReadFile(m)
+		if err != nil {
+			return nil, fmt.Errorf("reading %s: %w", m, err)
+		}
+		var list ImageList
+		if err := json.Unmarshal(data, &list); err != nil {
+			return nil, fmt.Errorf("parsing %s: %w", m, err)
+		}
+		lists = append(lists, list)
+	}
+	return lists, nil
+}
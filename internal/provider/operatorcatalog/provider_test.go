@@ -0,0 +1,493 @@
+package operatorcatalog
+
+import (
+	"archive/tar"
+	"bytes"
+	"compress/gzip"
+	"context"
+	"encoding/json"
+	"io"
+	"log/slog"
+	"net/http"
+	"net/http/httptest"
+	"net/url"
+	"os"
+	"path"
+	"path/filepath"
+	"strings"
+	"testing"
+
+	"github.com/BadgerOps/airgap/internal/config"
+	"github.com/BadgerOps/airgap/internal/provider"
+	"github.com/BadgerOps/airgap/internal/provider/containerimages"
+)
+
+const testCatalog = `{"schema":"olm.package","name":"foo","defaultChannel":"stable"}
+{"schema":"olm.channel","package":"foo","name":"stable","entries":[
+  {"name":"foo.v1.0.0"},
+  {"name":"foo.v1.1.0","replaces":"foo.v1.0.0"},
+  {"name":"foo.v1.2.0","replaces":"foo.v1.1.0","skips":["foo.v1.0.0"]}
+]}
+{"schema":"olm.channel","package":"foo","name":"fast","entries":[{"name":"foo.v1.3.0","replaces":"foo.v1.2.0"}]}
+{"schema":"olm.bundle","package":"foo","name":"foo.v1.0.0","image":"HOST/ns/foo-bundle:1.0.0","properties":[{"type":"olm.package","value":{"packageName":"foo","version":"1.0.0"}}]}
+{"schema":"olm.bundle","package":"foo","name":"foo.v1.1.0","image":"HOST/ns/foo-bundle:1.1.0","properties":[{"type":"olm.package","value":{"packageName":"foo","version":"1.1.0"}}]}
+{"schema":"olm.bundle","package":"foo","name":"foo.v1.2.0","image":"HOST/ns/foo-bundle:1.2.0","properties":[{"type":"olm.package","value":{"packageName":"foo","version":"1.2.0"}}],"relatedImages":[{"name":"operator","image":"HOST/ns/foo-operator:1.2.0"}]}
+{"schema":"olm.bundle","package":"foo","name":"foo.v1.3.0","image":"HOST/ns/foo-bundle:1.3.0","properties":[{"type":"olm.package","value":{"packageName":"foo","version":"1.3.0"}}]}
+`
+
+const testBarCatalog = `---
+schema: olm.package
+name: bar
+defaultChannel: alpha
+---
+schema: olm.channel
+package: bar
+name: alpha
+entries:
+  - name: bar.v0.1.0
+---
+schema: olm.bundle
+package: bar
+name: bar.v0.1.0
+image: HOST/ns/bar-bundle:0.1.0
+properties:
+  - type: olm.package
+    value:
+      packageName: bar
+      version: 0.1.0
+`
+
+func testLogger() *slog.Logger {
+	return slog.New(slog.NewTextHandler(io.Discard, nil))
+}
+
+func TestProviderTypeAndName(t *testing.T) {
+	p := NewProvider(t.TempDir(), testLogger())
+	if p.Type() != "operator_catalog" {
+		t.Fatalf("expected type operator_catalog, got %q", p.Type())
+	}
+	p.SetName("redhat-operators")
+	if p.Name() != "redhat-operators" {
+		t.Fatalf("expected overridden name, got %q", p.Name())
+	}
+}
+
+func TestConfigureValidation(t *testing.T) {
+	tests := []struct {
+		name string
+		cfg  provider.ProviderConfig
+	}{
+		{"digest index", provider.ProviderConfig{"catalogs": []interface{}{
+			map[string]interface{}{"index": "quay.io/org/index@sha256:" + strings.Repeat("a", 64), "packages": []interface{}{map[string]interface{}{"name": "foo"}}},
+		}}},
+		{"no packages", provider.ProviderConfig{"catalogs": []interface{}{
+			map[string]interface{}{"index": "quay.io/org/index:v1"},
+		}}},
+		{"bad version", provider.ProviderConfig{"catalogs": []interface{}{
+			map[string]interface{}{"index": "quay.io/org/index:v1", "packages": []interface{}{map[string]interface{}{"name": "foo", "min_version": "latest"}}},
+		}}},
+		{"bad platform", provider.ProviderConfig{"platform": "amd64"}},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			if err := NewProvider(t.TempDir(), testLogger()).Configure(tt.cfg); err == nil {
+				t.Fatal("expected configure error")
+			}
+		})
+	}
+}
+
+func TestSelectPackagesChannelHeadAndRange(t *testing.T) {
+	cat, err := parseCatalog(map[string][]byte{
+		"foo/catalog.json": []byte(testCatalog),
+		"bar/catalog.yaml": []byte(testBarCatalog),
+	})
+	if err != nil {
+		t.Fatalf("parse failed: %v", err)
+	}
+
+	sels, err := selectPackages(cat, []config.OperatorPackageFilter{{Name: "foo"}})
+	if err != nil {
+		t.Fatalf("select failed: %v", err)
+	}
+	if _, ok := sels[0].bundles["foo.v1.2.0"]; !ok || len(sels[0].bundles) != 1 {
+		t.Fatalf("expected only stable head foo.v1.2.0, got %v", sels[0].bundles)
+	}
+
+	sels, err = selectPackages(cat, []config.OperatorPackageFilter{{Name: "foo", Channels: []string{"stable"}, MinVersion: "1.1.0"}})
+	if err != nil {
+		t.Fatalf("select failed: %v", err)
+	}
+	if len(sels[0].bundles) != 2 {
+		t.Fatalf("expected 2 bundles >=1.1.0, got %v", sels[0].bundles)
+	}
+	rendered, err := sels[0].render()
+	if err != nil {
+		t.Fatalf("render failed: %v", err)
+	}
+	if strings.Contains(string(rendered), `"replaces": "foo.v1.0.0"`) || strings.Contains(string(rendered), "foo.v1.3.0") {
+		t.Fatalf("rendered catalog references pruned bundles:\n%s", rendered)
+	}
+
+	sels, err = selectPackages(cat, []config.OperatorPackageFilter{{Name: "foo", Channels: []string{"fast"}}})
+	if err != nil {
+		t.Fatalf("select failed: %v", err)
+	}
+	if sels[0].defaultChannel != "fast" {
+		t.Fatalf("expected default channel to move to fast, got %q", sels[0].defaultChannel)
+	}
+
+	if _, err := selectPackages(cat, []config.OperatorPackageFilter{{Name: "missing"}}); err == nil {
+		t.Fatal("expected error for unknown package")
+	}
+	if _, err := selectPackages(cat, []config.OperatorPackageFilter{{Name: "bar"}}); err != nil {
+		t.Fatalf("expected YAML package to be selectable: %v", err)
+	}
+}
+
+// testRegistry serves manifests and blobs keyed by repository path.
+type testRegistry struct {
+	manifests map[string][]byte // "<repo>/<ref>" -> body
+	types     map[string]string
+	blobs     map[string][]byte // digest -> body
+}
+
+func (r *testRegistry) addManifest(repo string, refs []string, mediaType string, body []byte) string {
+	d := digestOf(body)
+	for _, ref := range append(refs, d) {
+		r.manifests[repo+"/"+ref] = body
+		r.types[repo+"/"+ref] = mediaType
+	}
+	return d
+}
+
+func (r *testRegistry) addImage(repo, tag string, layers ...[]byte) string {
+	cfg := []byte(`{"architecture":"amd64","os":"linux","config":{"Labels":{"` + configsLabel + `":"/configs"},"Cmd":["serve","/configs","--cache-dir=/tmp/cache"]},"rootfs":{"type":"layers","diff_ids":[]}}`)
+	r.blobs[digestOf(cfg)] = cfg
+	var layerDescs []map[string]interface{}
+	for _, l := range layers {
+		r.blobs[digestOf(l)] = l
+		layerDescs = append(layerDescs, map[string]interface{}{
+			"mediaType": mediaTypeOCILayer, "digest": digestOf(l), "size": len(l),
+		})
+	}
+	body, _ := json.Marshal(map[string]interface{}{
+		"schemaVersion": 2,
+		"mediaType":     mediaTypeOCIManifest,
+		"config":        map[string]interface{}{"mediaType": "application/vnd.oci.image.config.v1+json", "digest": digestOf(cfg), "size": len(cfg)},
+		"layers":        layerDescs,
+	})
+	return r.addManifest(repo, []string{tag}, mediaTypeOCIManifest, body)
+}
+
+func (r *testRegistry) ServeHTTP(w http.ResponseWriter, req *http.Request) {
+	p := strings.TrimPrefix(req.URL.Path, "/v2/")
+	if i := strings.LastIndex(p, "/manifests/"); i >= 0 {
+		key := p[:i] + "/" + p[i+len("/manifests/"):]
+		if body, ok := r.manifests[key]; ok {
+			w.Header().Set("Content-Type", r.types[key])
+			w.Header().Set("Docker-Content-Digest", digestOf(body))
+			_, _ = w.Write(body)
+			return
+		}
+	}
+	if i := strings.LastIndex(p, "/blobs/"); i >= 0 {
+		if body, ok := r.blobs[p[i+len("/blobs/"):]]; ok {
+			_, _ = w.Write(body)
+			return
+		}
+	}
+	w.WriteHeader(http.StatusNotFound)
+}
+
+func tarGz(t *testing.T, files map[string]string) []byte {
+	t.Helper()
+	var buf bytes.Buffer
+	gz := gzip.NewWriter(&buf)
+	tw := tar.NewWriter(gz)
+	for name, content := range files {
+		if err := tw.WriteHeader(&tar.Header{Name: name, Mode: 0o644, Size: int64(len(content)), Typeflag: tar.TypeReg}); err != nil {
+			t.Fatal(err)
+		}
+		if _, err := tw.Write([]byte(content)); err != nil {
+			t.Fatal(err)
+		}
+	}
+	if err := tw.Close(); err != nil {
+		t.Fatal(err)
+	}
+	if err := gz.Close(); err != nil {
+		t.Fatal(err)
+	}
+	return buf.Bytes()
+}
+
+func TestPlanPrunesCatalogAndPlansImages(t *testing.T) {
+	reg := &testRegistry{manifests: map[string][]byte{}, types: map[string]string{}, blobs: map[string][]byte{}}
+	server := httptest.NewTLSServer(reg)
+	defer server.Close()
+	u, _ := url.Parse(server.URL)
+	host := u.Host
+
+	layer := tarGz(t, map[string]string{
+		"configs/foo/catalog.json": strings.ReplaceAll(testCatalog, "HOST", host),
+		"configs/bar/catalog.yaml": strings.ReplaceAll(testBarCatalog, "HOST", host),
+		"tmp/cache/cache.json":     "{}",
+	})
+	childDigest := reg.addImage("ns/index", "child", layer)
+	childBody := reg.manifests["ns/index/"+childDigest]
+	index, _ := json.Marshal(map[string]interface{}{
+		"schemaVersion": 2,
+		"mediaType":     "application/vnd.oci.image.index.v1+json",
+		"manifests": []map[string]interface{}{{
+			"mediaType": mediaTypeOCIManifest, "digest": childDigest, "size": len(childBody),
+			"platform": map[string]string{"os": "linux", "architecture": "amd64"},
+		}},
+	})
+	reg.addManifest("ns/index", []string{"v4.16"}, "application/vnd.oci.image.index.v1+json", index)
+	reg.addImage("ns/foo-bundle", "1.2.0", []byte("bundle"))
+	reg.addImage("ns/foo-operator", "1.2.0", []byte("operator"))
+
+	dataDir := t.TempDir()
+	p := NewProvider(dataDir, testLogger())
+	p.SetName("ops")
+	p.http = server.Client()
+	if err := p.Configure(provider.ProviderConfig{
+		"catalogs": []interface{}{map[string]interface{}{
+			"index":    host + "/ns/index:v4.16",
+			"packages": []interface{}{map[string]interface{}{"name": "foo"}},
+		}},
+	}); err != nil {
+		t.Fatalf("configure failed: %v", err)
+	}
+
+	plan, err := p.Plan(context.Background())
+	if err != nil {
+		t.Fatalf("plan failed: %v", err)
+	}
+	if entries, _ := os.ReadDir(dataDir); len(entries) != 0 {
+		t.Fatalf("plan wrote to the data dir: %v", entries)
+	}
+	if len(plan.Actions) != 2 {
+		t.Fatalf("expected the index config and layer to be planned, got %+v", plan.Actions)
+	}
+	for _, a := range plan.Actions {
+		if a.Action != provider.ActionDownload || !strings.Contains(a.Path, "ns_index") {
+			t.Errorf("unexpected action %s %s", a.Action, a.Path)
+		}
+	}
+
+	// Simulate the engine downloading the index blobs.
+	providerRoot := filepath.Join(dataDir, "ops")
+	for _, a := range plan.Actions {
+		blob := provider.GeneratedFile{Path: a.Path, Data: reg.blobs["sha256:"+path.Base(a.Path)]}
+		writeGeneratedFiles(t, providerRoot, []provider.GeneratedFile{blob})
+	}
+
+	next, err := p.PostSync(context.Background())
+	if err != nil {
+		t.Fatalf("post sync failed: %v", err)
+	}
+	var downloads int
+	for _, a := range next.Actions {
+		if a.Action == provider.ActionDownload {
+			downloads++
+			if !strings.Contains(a.Path, "foo-bundle") && !strings.Contains(a.Path, "foo-operator") {
+				t.Errorf("unexpected download %s", a.Path)
+			}
+		}
+	}
+	if downloads != 6 {
+		t.Fatalf("expected manifest, config and layer for 2 images, got %d downloads", downloads)
+	}
+
+	writeGeneratedFiles(t, providerRoot, p.GeneratedFiles())
+	images, err := MirroredImages(providerRoot, "")
+	if err != nil {
+		t.Fatalf("MirroredImages failed: %v", err)
+	}
+	want := []string{host + "/ns/index:v4.16", host + "/ns/foo-bundle:1.2.0", host + "/ns/foo-operator:1.2.0"}
+	if strings.Join(images, ",") != strings.Join(want, ",") {
+		t.Fatalf("images = %v, want %v", images, want)
+	}
+
+	matches, _ := filepath.Glob(filepath.Join(providerRoot, "operators", "catalogs", "*", "configs", "*", "catalog.json"))
+	if len(matches) != 1 || !strings.Contains(matches[0], filepath.Join("configs", "foo")) {
+		t.Fatalf("expected only foo in pruned configs, got %v", matches)
+	}
+
+	ref, _ := containerimages.ParseReference(host + "/ns/index:v4.16")
+	imageRel := filepath.ToSlash(filepath.Join("operators", containerimages.LocalImageID(ref)))
+	manifests, _ := filepath.Glob(filepath.Join(providerRoot, imageRel, "manifests", "sha256", "*.json"))
+	if len(manifests) != 1 {
+		t.Fatalf("expected a single pruned catalog manifest, got %v", manifests)
+	}
+	prunedManifest, err := os.ReadFile(manifests[0])
+	if err != nil {
+		t.Fatal(err)
+	}
+	pruned, err := parseBaseManifest("", prunedManifest)
+	if err != nil {
+		t.Fatalf("pruned manifest invalid: %v", err)
+	}
+	if len(pruned.layers) != 2 {
+		t.Fatalf("expected base layer plus pruned layer, got %d layers", len(pruned.layers))
+	}
+	configBody, err := os.ReadFile(filepath.Join(providerRoot, blobRelPath(imageRel, pruned.config.Digest)))
+	if err != nil {
+		t.Fatalf("reading pruned config: %v", err)
+	}
+	if !strings.Contains(string(configBody), "--cache-enforce-integrity=false") {
+		t.Fatalf("expected cache integrity check to be disabled: %s", configBody)
+	}
+
+	for _, d := range pruned.layers {
+		if _, err := os.Stat(filepath.Join(providerRoot, blobRelPath(imageRel, d.Digest))); err != nil {
+			t.Errorf("layer %s missing: %v", d.Digest, err)
+		}
+	}
+
+	// A second plan finds the index layers local and plans everything at once.
+	plan, err = p.Plan(context.Background())
+	if err != nil {
+		t.Fatalf("second plan failed: %v", err)
+	}
+	if plan.TotalSize == 0 || len(p.GeneratedFiles()) == 0 {
+		t.Fatalf("expected bundle images and generated files on second plan, got %+v", plan)
+	}
+	if next, err := p.PostSync(context.Background()); err != nil || len(next.Actions) != 0 {
+		t.Fatalf("expected nothing left for post sync, got %+v, %v", next, err)
+	}
+}
+
+func TestPostSyncDeletesImagesNoLongerSelected(t *testing.T) {
+	reg := &testRegistry{manifests: map[string][]byte{}, types: map[string]string{}, blobs: map[string][]byte{}}
+	server := httptest.NewTLSServer(reg)
+	defer server.Close()
+	u, _ := url.Parse(server.URL)
+	host := u.Host
+
+	layer := tarGz(t, map[string]string{
+		"configs/foo/catalog.json": strings.ReplaceAll(testCatalog, "HOST", host),
+	})
+	reg.addImage("ns/index", "v4.16", layer)
+	reg.addImage("ns/foo-bundle", "1.2.0", []byte("bundle"))
+	reg.addImage("ns/foo-operator", "1.2.0", []byte("operator"))
+
+	dataDir := t.TempDir()
+	providerRoot := filepath.Join(dataDir, "ops")
+	imageDir := func(raw string) string {
+		ref, _ := containerimages.ParseReference(raw)
+		return path.Join("operators", containerimages.LocalImageID(ref))
+	}
+	// Left behind by an earlier sync: a bundle the catalog no longer
+	// selects, a catalog removed from the config and an old index blob.
+	writeGeneratedFiles(t, providerRoot, []provider.GeneratedFile{
+		{Path: imageDir(host+"/ns/foo-bundle:1.1.0") + "/blobs/sha256/old", Data: []byte("old")},
+		{Path: imageDir(host+"/ns/foo-bundle:1.2.0") + "/blobs/sha256/kept", Data: []byte("kept")},
+		{Path: "operators/catalogs/removed_index/images.json", Data: []byte("{}")},
+		{Path: imageDir(host+"/ns/index:v4.16") + "/blobs/sha256/stale", Data: []byte("stale")},
+	})
+
+	configure := func(p *Provider, indexes ...string) {
+		t.Helper()
+		var catalogs []interface{}
+		for _, index := range indexes {
+			catalogs = append(catalogs, map[string]interface{}{
+				"index":    index,
+				"packages": []interface{}{map[string]interface{}{"name": "foo"}},
+			})
+		}
+		if err := p.Configure(provider.ProviderConfig{"catalogs": catalogs}); err != nil {
+			t.Fatalf("configure failed: %v", err)
+		}
+	}
+	sync := func(p *Provider) map[string]bool {
+		t.Helper()
+		plan, err := p.Plan(context.Background())
+		if err != nil {
+			t.Fatalf("plan failed: %v", err)
+		}
+		for _, a := range plan.Actions {
+			if a.Action == provider.ActionDownload {
+				blob := provider.GeneratedFile{Path: a.Path, Data: reg.blobs["sha256:"+path.Base(a.Path)]}
+				writeGeneratedFiles(t, providerRoot, []provider.GeneratedFile{blob})
+			}
+		}
+		next, _ := p.PostSync(context.Background())
+		deletes := make(map[string]bool)
+		for _, a := range append(plan.Actions, next.Actions...) {
+			if a.Action == provider.ActionDelete {
+				deletes[a.Path] = true
+			}
+		}
+		return deletes
+	}
+
+	// With a catalog that cannot be read, its images are unknown and
+	// nothing outside the readable catalog is deleted.
+	p := NewProvider(dataDir, testLogger())
+	p.SetName("ops")
+	p.http = server.Client()
+	configure(p, host+"/ns/index:v4.16", host+"/ns/missing:v1")
+	deletes := sync(p)
+	if deletes[imageDir(host+"/ns/foo-bundle:1.1.0")+"/blobs/sha256/old"] || deletes["operators/catalogs/removed_index/images.json"] {
+		t.Fatalf("expected no image deletes while a catalog is unreadable, got %v", deletes)
+	}
+
+	configure(p, host+"/ns/index:v4.16")
+	deletes = sync(p)
+	want := []string{
+		imageDir(host+"/ns/foo-bundle:1.1.0") + "/blobs/sha256/old",
+		"operators/catalogs/removed_index/images.json",
+		imageDir(host+"/ns/index:v4.16") + "/blobs/sha256/stale",
+	}
+	if len(deletes) != len(want) {
+		t.Fatalf("deletes = %v, want %v", deletes, want)
+	}
+	for _, w := range want {
+		if !deletes[w] {
+			t.Errorf("expected %s to be deleted, got %v", w, deletes)
+		}
+	}
+}
+
+func TestPlanReportsFailingCatalog(t *testing.T) {
+	reg := &testRegistry{manifests: map[string][]byte{}, types: map[string]string{}, blobs: map[string][]byte{}}
+	server := httptest.NewTLSServer(reg)
+	defer server.Close()
+	u, _ := url.Parse(server.URL)
+
+	p := NewProvider(t.TempDir(), testLogger())
+	p.http = server.Client()
+	if err := p.Configure(provider.ProviderConfig{
+		"catalogs": []interface{}{map[string]interface{}{
+			"index":    u.Host + "/ns/missing:v1",
+			"packages": []interface{}{map[string]interface{}{"name": "foo"}},
+		}},
+	}); err != nil {
+		t.Fatalf("configure failed: %v", err)
+	}
+
+	if _, err := p.Plan(context.Background()); err != nil {
+		t.Fatalf("plan failed: %v", err)
+	}
+	failures := p.PlanFailures()
+	if len(failures) != 1 || failures[0].Path != u.Host+"/ns/missing:v1" {
+		t.Fatalf("expected the missing catalog to be reported, got %+v", failures)
+	}
+}
+
+// writeGeneratedFiles writes files under root the way the engine does.
+func writeGeneratedFiles(t *testing.T, root string, files []provider.GeneratedFile) {
+	t.Helper()
+	for _, f := range files {
+		target := filepath.Join(root, filepath.FromSlash(f.Path))
+		if err := os.MkdirAll(filepath.Dir(target), 0o755); err != nil {
+			t.Fatal(err)
+		}
+		if err := os.WriteFile(target, f.Data, 0o644); err != nil {
+			t.Fatal(err)
+		}
+	}
+}
@@ -2,6 +2,7 @@ package provider
 
 import (
 	"context"
+	"net/http"
 	"time"
 
 	"github.com/BadgerOps/airgap/internal/config"
@@ -26,7 +27,10 @@ type SyncAction struct {
 	Checksum  string            // expected SHA256
 	Reason    string            // human-readable reason (e.g. "new file", "checksum mismatch")
 	URL       string            // download URL (for download/update actions)
-	Headers   map[string]string // optional HTTP headers required for URL fetch (e.g. Authorization)
+	Headers   map[string]string // optional non-secret HTTP headers required for URL fetch (e.g. Accept)
+	// Authorize adds credentials to the download request. Secrets live only
+	// in this closure so they never reach a serialized plan or log.
+	Authorize func(*http.Request) `json:"-" yaml:"-"`
 }
 
 // SyncPlan is the output of Plan() — what will change without executing
@@ -36,7 +40,22 @@ type SyncPlan struct {
 	TotalSize  int64 // bytes to download
 	TotalFiles int
 	Timestamp  time.Time
-}
+	// Resolved maps version expressions to the concrete versions they
+	// selected, for providers that accept them.
+	Resolved map[string][]string
+	// SignatureStatus reports whether the upstream checksum manifests behind
+	// the plan were signature-checked; empty when the provider has none.
+	SignatureStatus string
+	// Incomplete is set when the provider plans more actions once these are
+	// downloaded (see PostSyncer), so the totals are a lower bound.
+	Incomplete bool
+}
+
+// Signature statuses of a SyncPlan.
+const (
+	SignatureVerified   = "verified"
+	SignatureUnverified = "unverified"
+)
 
 // SyncOptions controls how Sync() executes
 type SyncOptions struct {
@@ -48,42 +67,43 @@ type SyncOptions struct {
 
 // FailedFile records a file that failed all retries
 type FailedFile struct {
-	Path     string
-	URL      string
-	Error    string
-	Attempts int
+	Path     string `json:"path"`
+	URL      string `json:"url"`
+	Error    string `json:"error"`
+	Attempts int    `json:"attempts"`
 }
 
 // SyncReport is the result of Sync()
 type SyncReport struct {
-	Provider         string
-	StartTime        time.Time
-	EndTime          time.Time
-	Downloaded       int
-	Deleted          int
-	Skipped          int
-	Failed           []FailedFile
-	BytesTransferred int64
+	Provider         string       `json:"provider"`
+	StartTime        time.Time    `json:"start_time"`
+	EndTime          time.Time    `json:"end_time"`
+	Downloaded       int          `json:"downloaded"`
+	Deleted          int          `json:"deleted"`
+	Skipped          int          `json:"skipped"`
+	Failed           []FailedFile `json:"failed"`
+	BytesTransferred int64        `json:"bytes_transferred"`
 }
 
 // ValidationResult represents one file's validation outcome
 type ValidationResult struct {
-	Path      string
-	LocalPath string // absolute filesystem path
-	Expected  string
-	Actual    string
-	Valid     bool
-	Size      int64
-	URL       string // download URL for retry if invalid
+	Path      string `json:"path"`
+	LocalPath string `json:"local_path"` // absolute filesystem path
+	Expected  string `json:"expected"`
+	Actual    string `json:"actual"`
+	Valid     bool   `json:"valid"`
+	Size      int64  `json:"size"`
+	URL       string `json:"url"`    // download URL for retry if invalid
+	Reason    string `json:"reason"` // why the file is invalid when not a checksum mismatch
 }
 
 // ValidationReport is the result of Validate()
 type ValidationReport struct {
-	Provider     string
-	TotalFiles   int
-	ValidFiles   int
-	InvalidFiles []ValidationResult
-	Timestamp    time.Time
+	Provider     string             `json:"provider"`
+	TotalFiles   int                `json:"total_files"`
+	ValidFiles   int                `json:"valid_files"`
+	InvalidFiles []ValidationResult `json:"invalid_files"`
+	Timestamp    time.Time          `json:"timestamp"`
 }
 
 // ValidationProgressFn is called during validation for each file checked.
@@ -107,6 +127,40 @@ type ValidationProgressSetter interface {
 	SetValidationProgress(fn ValidationProgressFn)
 }
 
+// GeneratedFile is a file a provider builds itself, for example from
+// upstream metadata, instead of downloading it. Path is relative to the
+// provider root unless LocalPath is set.
+type GeneratedFile struct {
+	Path      string
+	LocalPath string
+	Data      []byte
+	Reason    string
+}
+
+// FileGenerator is an optional interface for providers that build files
+// during a sync. Plan must not write them; the engine writes GeneratedFiles
+// at the end of a sync that is not a dry run and records them so they are
+// exported.
+type FileGenerator interface {
+	GeneratedFiles() []GeneratedFile
+}
+
+// PostSyncer is an optional interface for providers that build content from
+// the files a sync downloaded, such as a catalog pruned from its index
+// layers. The engine calls PostSync after the plan's actions, except in dry
+// runs, and executes the returned follow-up plan the same way. An error
+// counts as a failed file, so the run ends as partial.
+type PostSyncer interface {
+	PostSync(ctx context.Context) (*SyncPlan, error)
+}
+
+// PlanFailureReporter is an optional interface for providers that reject
+// content while planning (for example metadata with a bad signature). The
+// engine records these failures with the failed downloads.
+type PlanFailureReporter interface {
+	PlanFailures() []FailedFile
+}
+
 // Provider is the core interface that all content types implement
 type Provider interface {
 	// Name returns the provider identifier (e.g., "epel", "ocp-binaries")
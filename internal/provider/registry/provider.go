@@ -9,7 +9,6 @@ import (
 	"io"
 	"log/slog"
 	"net/http"
-	"net/url"
 	"os"
 	"path/filepath"
 	"regexp"
@@ -19,12 +18,13 @@ import (
 
 	"github.com/BadgerOps/airgap/internal/config"
 	"github.com/BadgerOps/airgap/internal/provider"
+	"github.com/BadgerOps/airgap/internal/provider/containerimages"
+	"github.com/BadgerOps/airgap/internal/registryauth"
 	"github.com/BadgerOps/airgap/internal/safety"
 )
 
 const (
 	maxManifestBytes  int64 = 16 * 1024 * 1024
-	maxTokenBodyBytes int64 = 1 * 1024 * 1024
 	maxTagListBytes   int64 = 4 * 1024 * 1024
 	defaultOutputDir        = "registry-images"
 )
@@ -37,7 +37,6 @@ var (
 		"application/vnd.docker.distribution.manifest.v2+json",
 		"application/vnd.docker.distribution.manifest.v1+json",
 	}, ", ")
-	authParamRegexp = regexp.MustCompile(`([a-zA-Z_]+)="([^"]*)"`)
 	slugRegexp      = regexp.MustCompile(`[^a-zA-Z0-9._-]+`)
 )
 
@@ -50,7 +49,8 @@ type Provider struct {
 	dataDir    string
 	logger     *slog.Logger
 	http       *http.Client
-	tokenByKey map[string]string
+	auth       *registryauth.Resolver
+	authByKey  map[string]string // Authorization header per scope
 }
 
 // NewProvider creates a new registry sync source provider.
@@ -63,7 +63,7 @@ func NewProvider(dataDir string, logger *slog.Logger) *Provider {
 		dataDir:    dataDir,
 		logger:     logger,
 		http:       safety.NewHTTPClient(90 * time.Second),
-		tokenByKey: make(map[string]string),
+		authByKey:  make(map[string]string),
 	}
 }
 
@@ -106,11 +106,34 @@ func (p *Provider) Configure(rawCfg provider.ProviderConfig) error {
 	}
 	cfg.Repositories = repos
 
+	platforms, err := containerimages.NormalizePlatforms(cfg.Platforms)
+	if err != nil {
+		return err
+	}
+	cfg.Platforms = platforms
+
+	// Username/Password authenticate against the endpoint itself and take
+	// precedence over the auth file.
+	credentials := cfg.Credentials
+	if cfg.Username != "" {
+		credentials = append([]config.RegistryCredential{{
+			Registry: cfg.Endpoint,
+			Username: cfg.Username,
+			Password: cfg.Password,
+		}}, credentials...)
+	}
+	auth := registryauth.NewResolver(credentials, cfg.AuthFile)
+	if err := auth.Validate(); err != nil {
+		return err
+	}
+
 	p.cfg = cfg
+	p.auth = auth
 	p.logger.Debug("configured registry sync source",
 		slog.String("endpoint", cfg.Endpoint),
 		slog.Int("repositories", len(cfg.Repositories)),
 		slog.Int("tag_filters", len(cfg.Tags)),
+		slog.Any("platforms", cfg.Platforms),
 		slog.String("output_dir", cfg.OutputDir),
 	)
 	return nil
@@ -293,7 +316,7 @@ func (p *Provider) listTags(ctx context.Context, endpointHost, repo string) ([]s
 	tagsURL := fmt.Sprintf("https://%s/v2/%s/tags/list", endpointHost, strings.Trim(repo, "/"))
 	scope := fmt.Sprintf("repository:%s:pull", repo)
 
-	body, _, _, err := p.registryGET(ctx, tagsURL, "application/json", scope)
+	body, _, _, err := p.registryGET(ctx, repo, tagsURL, "application/json", scope)
 	if err != nil {
 		return nil, fmt.Errorf("listing tags for %s: %w", repo, err)
 	}
@@ -338,9 +361,16 @@ type imageReference struct {
 }
 
 type descriptor struct {
-	MediaType string `json:"mediaType"`
-	Digest    string `json:"digest"`
-	Size      int64  `json:"size"`
+	MediaType string    `json:"mediaType"`
+	Digest    string    `json:"digest"`
+	Size      int64     `json:"size"`
+	Platform  *platform `json:"platform,omitempty"`
+}
+
+type platform struct {
+	OS           string `json:"os"`
+	Architecture string `json:"architecture"`
+	Variant      string `json:"variant,omitempty"`
 }
 
 type imageIndexManifest struct {
@@ -406,6 +436,11 @@ func (p *Provider) planImage(ctx context.Context, ref imageReference) ([]provide
 			if _, ok := seenManifest[child.Digest]; ok {
 				continue
 			}
+			if child.Platform != nil && !containerimages.MatchPlatform(p.cfg.Platforms, child.Platform.OS, child.Platform.Architecture, child.Platform.Variant) {
+				p.logger.Debug("skipping index child for unselected platform",
+					"repo", ref.Repository, "tag", ref.Reference, "digest", child.Digest)
+				continue
+			}
 			childDesc, childBody, childAuth, err := p.fetchManifest(ctx, ref, child.Digest)
 			if err != nil {
 				return nil, fmt.Errorf("fetching child manifest %s: %w", child.Digest, err)
@@ -491,7 +526,7 @@ func (p *Provider) fetchManifest(ctx context.Context, ref imageReference, manife
 	scope := fmt.Sprintf("repository:%s:pull", ref.Repository)
 	manifestURL := fmt.Sprintf("https://%s/v2/%s/manifests/%s",
 		ref.EndpointHost, strings.Trim(ref.Repository, "/"), manifestRef)
-	body, headers, authHeader, err := p.registryGET(ctx, manifestURL, manifestAcceptHeader, scope)
+	body, headers, authHeader, err := p.registryGET(ctx, ref.Repository, manifestURL, manifestAcceptHeader, scope)
 	if err != nil {
 		return descriptor{}, nil, "", err
 	}
@@ -513,16 +548,10 @@ func (p *Provider) fetchManifest(ctx context.Context, ref imageReference, manife
 	}, body, authHeader, nil
 }
 
-func (p *Provider) registryGET(ctx context.Context, endpoint, accept, scope string) ([]byte, http.Header, string, error) {
-	tokenKey := endpoint + "|" + scope
-	var authHeader string
-	if token := p.tokenByKey[tokenKey]; token != "" {
-		authHeader = "Bearer " + token
-	}
-	// If basic auth is configured, prefer it over cached bearer tokens
-	if p.cfg != nil && p.cfg.Username != "" && authHeader == "" {
-		authHeader = ""
-	}
+// registryGET issues a GET against the configured endpoint, answering the
+// first 401 challenge with the credential configured for repo.
+func (p *Provider) registryGET(ctx context.Context, repo, endpoint, accept, scope string) ([]byte, http.Header, string, error) {
+	authHeader := p.authByKey[scope]
 
 	for attempt := 0; attempt < 2; attempt++ {
 		req, err := http.NewRequestWithContext(ctx, http.MethodGet, endpoint, nil)
@@ -534,8 +563,6 @@ func (p *Provider) registryGET(ctx context.Context, endpoint, accept, scope stri
 		}
 		if authHeader != "" {
 			req.Header.Set("Authorization", authHeader)
-		} else if p.cfg != nil && p.cfg.Username != "" {
-			req.SetBasicAuth(p.cfg.Username, p.cfg.Password)
 		}
 
 		resp, err := p.http.Do(req)
@@ -548,12 +575,15 @@ func (p *Provider) registryGET(ctx context.Context, endpoint, accept, scope stri
 			if closeErr := resp.Body.Close(); closeErr != nil {
 				p.logger.Warn("failed to close unauthorized response body", "error", closeErr)
 			}
-			token, err := p.fetchBearerToken(ctx, challenge, scope)
+			cred, err := p.auth.Lookup(ctx, req.URL.Host, repo)
 			if err != nil {
-				return nil, nil, "", fmt.Errorf("fetching bearer token: %w", err)
+				return nil, nil, "", fmt.Errorf("resolving credentials for %s: %w", req.URL.Host, err)
 			}
-			authHeader = "Bearer " + token
-			p.tokenByKey[tokenKey] = token
+			authHeader, err = registryauth.Authenticate(ctx, p.http, challenge, scope, cred)
+			if err != nil {
+				return nil, nil, "", fmt.Errorf("authenticating to %s: %w", req.URL.Host, err)
+			}
+			p.authByKey[scope] = authHeader
 			continue
 		}
 
@@ -578,97 +608,6 @@ func (p *Provider) registryGET(ctx context.Context, endpoint, accept, scope stri
 	return nil, nil, "", fmt.Errorf("registry authentication failed")
 }
 
-func (p *Provider) fetchBearerToken(ctx context.Context, challenge, scope string) (string, error) {
-	if challenge == "" {
-		return "", fmt.Errorf("missing WWW-Authenticate challenge")
-	}
-	if !strings.HasPrefix(strings.ToLower(challenge), "bearer ") {
-		return "", fmt.Errorf("unsupported auth challenge: %q", challenge)
-	}
-
-	params := parseAuthParams(challenge)
-	realm := params["realm"]
-	if realm == "" {
-		return "", fmt.Errorf("bearer challenge missing realm")
-	}
-
-	values := url.Values{}
-	if service := params["service"]; service != "" {
-		values.Set("service", service)
-	}
-	tokenScope := params["scope"]
-	if tokenScope == "" {
-		tokenScope = scope
-	}
-	if tokenScope != "" {
-		values.Set("scope", tokenScope)
-	}
-
-	tokenURL := realm
-	if encoded := values.Encode(); encoded != "" {
-		if strings.Contains(tokenURL, "?") {
-			tokenURL += "&" + encoded
-		} else {
-			tokenURL += "?" + encoded
-		}
-	}
-
-	req, err := http.NewRequestWithContext(ctx, http.MethodGet, tokenURL, nil)
-	if err != nil {
-		return "", fmt.Errorf("creating token request: %w", err)
-	}
-	// Pass basic auth to token endpoint if configured (for private registries)
-	if p.cfg != nil && p.cfg.Username != "" {
-		req.SetBasicAuth(p.cfg.Username, p.cfg.Password)
-	}
-
-	resp, err := p.http.Do(req)
-	if err != nil {
-		return "", fmt.Errorf("executing token request: %w", err)
-	}
-	defer func() { _ = resp.Body.Close() }()
-
-	if resp.StatusCode < 200 || resp.StatusCode > 299 {
-		body, _ := io.ReadAll(io.LimitReader(resp.Body, 4096))
-		return "", fmt.Errorf("token endpoint returned %s: %s", resp.Status, strings.TrimSpace(string(body)))
-	}
-
-	data, err := safety.ReadAllWithLimit(resp.Body, maxTokenBodyBytes)
-	if err != nil {
-		return "", fmt.Errorf("reading token response: %w", err)
-	}
-	var tokenResp struct {
-		Token       string `json:"token"`
-		AccessToken string `json:"access_token"`
-	}
-	if err := json.Unmarshal(data, &tokenResp); err != nil {
-		return "", fmt.Errorf("parsing token response: %w", err)
-	}
-	token := tokenResp.Token
-	if token == "" {
-		token = tokenResp.AccessToken
-	}
-	if token == "" {
-		return "", fmt.Errorf("token response did not include token")
-	}
-	return token, nil
-}
-
-func parseAuthParams(challenge string) map[string]string {
-	result := make(map[string]string)
-	trimmed := strings.TrimSpace(challenge)
-	if strings.HasPrefix(strings.ToLower(trimmed), "bearer ") {
-		trimmed = strings.TrimSpace(trimmed[len("bearer "):])
-	}
-	matches := authParamRegexp.FindAllStringSubmatch(trimmed, -1)
-	for _, m := range matches {
-		if len(m) == 3 {
-			result[strings.ToLower(m[1])] = m[2]
-		}
-	}
-	return result
-}
-
 // --- Action builders ---
 
 func (p *Provider) newManifestAction(ref imageReference, imageID string, desc descriptor, authHeader string) (provider.SyncAction, error) {
@@ -677,15 +616,13 @@ func (p *Provider) newManifestAction(ref imageReference, imageID string, desc de
 		return provider.SyncAction{}, fmt.Errorf("invalid manifest digest %q: %w", desc.Digest, err)
 	}
 	relPath := filepath.ToSlash(filepath.Join(p.cfg.OutputDir, imageID, "manifests", algo, hash+".json"))
-	headers := make(map[string]string)
-	if authHeader != "" {
-		headers["Authorization"] = authHeader
-	}
-	headers["Accept"] = manifestAcceptHeader
+	headers := map[string]string{"Accept": manifestAcceptHeader}
 	manifestURL := fmt.Sprintf("https://%s/v2/%s/manifests/%s",
 		ref.EndpointHost, strings.Trim(ref.Repository, "/"), desc.Digest)
 	expectedChecksum := expectedChecksumForDigest(algo, hash)
-	return p.buildAction(relPath, manifestURL, expectedChecksum, desc.Size, headers), nil
+	action := p.buildAction(relPath, manifestURL, expectedChecksum, desc.Size, headers)
+	action.Authorize = registryauth.Authorizer(authHeader)
+	return action, nil
 }
 
 func (p *Provider) newBlobAction(ref imageReference, imageID string, desc descriptor, authHeader string) (provider.SyncAction, error) {
@@ -694,14 +631,12 @@ func (p *Provider) newBlobAction(ref imageReference, imageID string, desc descri
 		return provider.SyncAction{}, fmt.Errorf("invalid blob digest %q: %w", desc.Digest, err)
 	}
 	relPath := filepath.ToSlash(filepath.Join(p.cfg.OutputDir, imageID, "blobs", algo, hash))
-	headers := make(map[string]string)
-	if authHeader != "" {
-		headers["Authorization"] = authHeader
-	}
 	blobURL := fmt.Sprintf("https://%s/v2/%s/blobs/%s",
 		ref.EndpointHost, strings.Trim(ref.Repository, "/"), desc.Digest)
 	expectedChecksum := expectedChecksumForDigest(algo, hash)
-	return p.buildAction(relPath, blobURL, expectedChecksum, desc.Size, headers), nil
+	action := p.buildAction(relPath, blobURL, expectedChecksum, desc.Size, map[string]string{})
+	action.Authorize = registryauth.Authorizer(authHeader)
+	return action, nil
 }
 
 func (p *Provider) buildAction(relPath, sourceURL, expectedChecksum string, expectedSize int64, headers map[string]string) provider.SyncAction {
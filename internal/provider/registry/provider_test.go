@@ -464,6 +464,42 @@ func TestListTags_WithMockServer(t *testing.T) {
 	}
 }
 
+func TestListTags_BasicChallengeUsesConfiguredCredentials(t *testing.T) {
+	mux := http.NewServeMux()
+	mux.HandleFunc("/v2/org/repo/tags/list", func(w http.ResponseWriter, r *http.Request) {
+		if user, pass, ok := r.BasicAuth(); !ok || user != "user" || pass != "secret" {
+			w.Header().Set("WWW-Authenticate", `Basic realm="registry"`)
+			w.WriteHeader(http.StatusUnauthorized)
+			return
+		}
+		w.Header().Set("Content-Type", "application/json")
+		_, _ = fmt.Fprint(w, `{"name":"org/repo","tags":["v1.0"]}`)
+	})
+
+	server := httptest.NewTLSServer(mux)
+	defer server.Close()
+
+	host := strings.TrimPrefix(server.URL, "https://")
+	p := NewProvider(t.TempDir(), nil)
+	p.http = server.Client()
+	if err := p.Configure(provider.ProviderConfig{
+		"endpoint":     host,
+		"repositories": []interface{}{"org/repo"},
+		"username":     "user",
+		"password":     "secret",
+	}); err != nil {
+		t.Fatalf("configure error: %v", err)
+	}
+
+	tags, err := p.listTags(context.Background(), host, "org/repo")
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	if len(tags) != 1 || tags[0] != "v1.0" {
+		t.Errorf("unexpected tags %v", tags)
+	}
+}
+
 func sha256Hex(data []byte) string {
 	h := sha256.Sum256(data)
 	return hex.EncodeToString(h[:])
@@ -0,0 +1,120 @@
+// Package pxe generates iPXE boot scripts for mirrored RHCOS live artifacts
+// and provides a read-only TFTP server for chainloading iPXE.
+package pxe
+
+import (
+	"path"
+	"sort"
+	"strings"
+)
+
+// Image is one bootable RHCOS build: a directory of an rhcos provider that
+// holds a live kernel, initramfs and rootfs. Artifact paths are relative to
+// the provider's output directory.
+type Image struct {
+	ID        string `json:"id"`
+	Provider  string `json:"provider"`
+	Dir       string `json:"dir"`
+	Arch      string `json:"arch,omitempty"`
+	Kernel    string `json:"kernel"`
+	Initramfs string `json:"initramfs"`
+	Rootfs    string `json:"rootfs"`
+}
+
+// Artifacts returns the image's file paths.
+func (img Image) Artifacts() []string {
+	return []string{img.Kernel, img.Initramfs, img.Rootfs}
+}
+
+var knownArches = []string{"x86_64", "aarch64", "ppc64le", "s390x"}
+
+// artifactKind classifies an RHCOS file name as "kernel", "initramfs" or
+// "rootfs" of a live PXE boot, or "" for anything else.
+func artifactKind(name string) string {
+	switch {
+	case strings.Contains(name, "-live-kernel"):
+		return "kernel"
+	case strings.Contains(name, "-live-initramfs"):
+		return "initramfs"
+	case strings.Contains(name, "-live-rootfs"):
+		return "rootfs"
+	}
+	return ""
+}
+
+// FindImages groups the files of an rhcos provider by directory and returns
+// the directories holding a complete kernel, initramfs and rootfs, sorted by
+// ID. When a directory has several candidates of a kind (mirror "latest"
+// directories list both versioned and unversioned names), the first by name
+// is used.
+func FindImages(provider string, paths []string) []Image {
+	byDir := make(map[string]*Image)
+	sorted := append([]string(nil), paths...)
+	sort.Strings(sorted)
+	for _, p := range sorted {
+		dir, name := path.Split(p)
+		kind := artifactKind(name)
+		if kind == "" {
+			continue
+		}
+		dir = strings.TrimSuffix(dir, "/")
+		img, ok := byDir[dir]
+		if !ok {
+			img = &Image{Provider: provider, Dir: dir, ID: path.Join(provider, dir)}
+			byDir[dir] = img
+		}
+		switch {
+		case kind == "kernel" && img.Kernel == "":
+			img.Kernel = p
+		case kind == "initramfs" && img.Initramfs == "":
+			img.Initramfs = p
+		case kind == "rootfs" && img.Rootfs == "":
+			img.Rootfs = p
+		}
+	}
+
+	images := make([]Image, 0, len(byDir))
+	for _, img := range byDir {
+		if img.Kernel == "" || img.Initramfs == "" || img.Rootfs == "" {
+			continue
+		}
+		img.Arch = imageArch(img.Dir, path.Base(img.Kernel))
+		images = append(images, *img)
+	}
+	sort.Slice(images, func(i, j int) bool { return images[i].ID < images[j].ID })
+	return images
+}
+
+// imageArch takes the architecture from a directory segment, falling back to
+// the kernel file name.
+func imageArch(dir, kernel string) string {
+	for _, seg := range strings.Split(dir, "/") {
+		for _, arch := range knownArches {
+			if seg == arch {
+				return arch
+			}
+		}
+	}
+	for _, arch := range knownArches {
+		if strings.Contains(kernel, arch) {
+			return arch
+		}
+	}
+	return ""
+}
+
+// FindImage returns the image whose ID or directory is ref.
+func FindImage(images []Image, ref string) (Image, bool) {
+	ref = strings.Trim(ref, "/")
+	for _, img := range images {
+		if img.ID == ref {
+			return img, true
+		}
+	}
+	for _, img := range images {
+		if img.Dir == ref {
+			return img, true
+		}
+	}
+	return Image{}, false
+}
@@ -0,0 +1,115 @@
+package pxe
+
+import (
+	"strings"
+	"testing"
+
+	"github.com/BadgerOps/airgap/internal/config"
+)
+
+func TestFindImages(t *testing.T) {
+	paths := []string{
+		"4.17/latest/rhcos-live-kernel-x86_64",
+		"4.17/latest/rhcos-4.17.3-x86_64-live-kernel-x86_64",
+		"4.17/latest/rhcos-4.17.3-x86_64-live-initramfs.x86_64.img",
+		"4.17/latest/rhcos-4.17.3-x86_64-live-rootfs.x86_64.img",
+		"4.17/latest/rhcos-live-initramfs.x86_64.img",
+		"4.17/latest/rhcos-live-rootfs.x86_64.img",
+		"4.17/latest/sha256sum.txt",
+		"aarch64/4.16/rhcos-live-kernel-aarch64",
+		"aarch64/4.16/rhcos-live-initramfs.aarch64.img",
+		// Incomplete: no rootfs.
+		"x86_64/4.16/rhcos-live-kernel-x86_64",
+		"x86_64/4.16/rhcos-live-initramfs.x86_64.img",
+	}
+	paths = append(paths, "aarch64/4.16/rhcos-live-rootfs.aarch64.img")
+
+	images := FindImages("rhcos", paths)
+	if len(images) != 2 {
+		t.Fatalf("expected 2 images, got %+v", images)
+	}
+
+	latest := images[0]
+	if latest.ID != "rhcos/4.17/latest" || latest.Arch != "x86_64" {
+		t.Errorf("unexpected image: %+v", latest)
+	}
+	// The versioned names sort first and are used together.
+	for _, a := range latest.Artifacts() {
+		if !strings.Contains(a, "rhcos-4.17.3-") {
+			t.Errorf("expected versioned artifact, got %q", a)
+		}
+	}
+	if images[1].ID != "rhcos/aarch64/4.16" || images[1].Arch != "aarch64" {
+		t.Errorf("unexpected image: %+v", images[1])
+	}
+
+	if img, ok := FindImage(images, "aarch64/4.16"); !ok || img.ID != "rhcos/aarch64/4.16" {
+		t.Errorf("FindImage by directory = %+v, %v", img, ok)
+	}
+	if _, ok := FindImage(images, "rhcos/4.99"); ok {
+		t.Error("expected unknown image to be missing")
+	}
+}
+
+func TestBootScript(t *testing.T) {
+	img := Image{
+		ID:        "rhcos/x86_64/4.16",
+		Provider:  "rhcos",
+		Kernel:    "x86_64/4.16/rhcos-live-kernel-x86_64",
+		Initramfs: "x86_64/4.16/rhcos-live-initramfs.x86_64.img",
+		Rootfs:    "x86_64/4.16/rhcos-live-rootfs.x86_64.img",
+	}
+	base := "http://10.0.0.5:8080/"
+
+	script := BootScript(base, img, "console=ttyS0", nil)
+	for _, want := range []string{
+		"#!ipxe\n",
+		"kernel http://10.0.0.5:8080/boot/files/rhcos/x86_64/4.16/rhcos-live-kernel-x86_64 initrd=main coreos.live.rootfs_url=http://10.0.0.5:8080/boot/files/rhcos/x86_64/4.16/rhcos-live-rootfs.x86_64.img console=ttyS0\n",
+		"initrd --name main http://10.0.0.5:8080/boot/files/rhcos/x86_64/4.16/rhcos-live-initramfs.x86_64.img\n",
+		"boot\n",
+	} {
+		if !strings.Contains(script, want) {
+			t.Errorf("script missing %q:\n%s", want, script)
+		}
+	}
+
+	install := BootScript(base, img, "", &config.BootHost{
+		InstallDev: "/dev/sda", IgnitionURL: "http://10.0.0.5/worker.ign", KernelArgs: "ip=dhcp",
+	})
+	if !strings.Contains(install, "coreos.inst.install_dev=/dev/sda coreos.inst.ignition_url=http://10.0.0.5/worker.ign ip=dhcp") {
+		t.Errorf("unexpected install script:\n%s", install)
+	}
+
+	live := BootScript(base, img, "", &config.BootHost{IgnitionURL: "http://10.0.0.5/live.ign"})
+	if !strings.Contains(live, "ignition.firstboot ignition.platform.id=metal ignition.config.url=http://10.0.0.5/live.ign") {
+		t.Errorf("unexpected live script:\n%s", live)
+	}
+}
+
+func TestMenuScript(t *testing.T) {
+	images := []Image{{ID: "rhcos/4.16"}, {ID: "rhcos/4.17", Arch: "x86_64"}}
+	script := MenuScript("http://airgap:8080", images, "rhcos/4.17", 10)
+	for _, want := range []string{
+		"item img1 rhcos/4.17 (x86_64)\n",
+		"choose --default img1 --timeout 10000 target || goto shell\n",
+		":img0\nchain http://airgap:8080/boot/ipxe/rhcos/4.16?mac=${net0/mac}\n",
+	} {
+		if !strings.Contains(script, want) {
+			t.Errorf("menu missing %q:\n%s", want, script)
+		}
+	}
+
+	if empty := MenuScript("http://airgap:8080", nil, "", 10); !strings.Contains(empty, "shell") {
+		t.Errorf("expected shell fallback without images:\n%s", empty)
+	}
+}
+
+func TestFindHost(t *testing.T) {
+	hosts := []config.BootHost{{MAC: "52-54-00-AA-BB-CC", Image: "4.16"}}
+	if h := FindHost(hosts, "52:54:00:aa:bb:cc"); h == nil || h.Image != "4.16" {
+		t.Errorf("FindHost() = %+v", h)
+	}
+	if h := FindHost(hosts, ""); h != nil {
+		t.Errorf("expected no host for empty MAC, got %+v", h)
+	}
+}
@@ -0,0 +1,128 @@
+package pxe
+
+import (
+	"fmt"
+	"net/url"
+	"path"
+	"strings"
+
+	"github.com/BadgerOps/airgap/internal/config"
+)
+
+// HTTP paths of the boot menu, per-image iPXE scripts and boot artifacts
+// served by airgap serve.
+const (
+	MenuPath   = "/boot/ipxe"
+	ScriptPath = "/boot/ipxe/"
+	FilesPath  = "/boot/files/"
+)
+
+// NormalizeMAC lower-cases a MAC address and uses ":" separators, so
+// "52-54-00-AA-BB-CC" matches iPXE's ${net0/mac}.
+func NormalizeMAC(mac string) string {
+	return strings.ReplaceAll(strings.ToLower(strings.TrimSpace(mac)), "-", ":")
+}
+
+// FindHost returns the host entry for mac, or nil.
+func FindHost(hosts []config.BootHost, mac string) *config.BootHost {
+	mac = NormalizeMAC(mac)
+	if mac == "" {
+		return nil
+	}
+	for i := range hosts {
+		if NormalizeMAC(hosts[i].MAC) == mac {
+			return &hosts[i]
+		}
+	}
+	return nil
+}
+
+// fileURL returns the HTTP URL of an artifact of img.
+func fileURL(baseURL string, img Image, rel string) string {
+	return strings.TrimRight(baseURL, "/") + FilesPath + escapePath(path.Join(img.Provider, rel))
+}
+
+func escapePath(p string) string {
+	segs := strings.Split(p, "/")
+	for i, s := range segs {
+		segs[i] = url.PathEscape(s)
+	}
+	return strings.Join(segs, "/")
+}
+
+// ChainScript is served over TFTP as autoexec.ipxe/boot.ipxe and hands the
+// booting node over to the HTTP menu, passing its MAC for host matching.
+func ChainScript(baseURL string) string {
+	return fmt.Sprintf("#!ipxe\ndhcp\nchain %s%s?mac=${net0/mac}\n", strings.TrimRight(baseURL, "/"), MenuPath)
+}
+
+// MenuScript renders an iPXE menu of images. The default image is booted
+// after timeoutSec seconds.
+func MenuScript(baseURL string, images []Image, defaultID string, timeoutSec int) string {
+	base := strings.TrimRight(baseURL, "/")
+	var b strings.Builder
+	b.WriteString("#!ipxe\n")
+	if len(images) == 0 {
+		b.WriteString("echo No RHCOS images with a live kernel, initramfs and rootfs are mirrored.\nshell\n")
+		return b.String()
+	}
+
+	def := "img0"
+	for i, img := range images {
+		if img.ID == defaultID {
+			def = fmt.Sprintf("img%d", i)
+		}
+	}
+	b.WriteString("menu airgap RHCOS boot\n")
+	for i, img := range images {
+		label := img.ID
+		if img.Arch != "" && !strings.Contains(img.ID, img.Arch) {
+			label += " (" + img.Arch + ")"
+		}
+		fmt.Fprintf(&b, "item img%d %s\n", i, label)
+	}
+	b.WriteString("item shell iPXE shell\n")
+	fmt.Fprintf(&b, "choose --default %s --timeout %d target || goto shell\n", def, timeoutSec*1000)
+	b.WriteString("goto ${target}\n")
+	for i, img := range images {
+		fmt.Fprintf(&b, ":img%d\nchain %s%s%s?mac=${net0/mac}\n", i, base, ScriptPath, escapePath(img.ID))
+	}
+	b.WriteString(":shell\nshell\n")
+	return b.String()
+}
+
+// BootScript renders the iPXE script that boots img. The rootfs is fetched
+// by the live initramfs from coreos.live.rootfs_url. With a host, its
+// ignition_url is passed to coreos-installer when install_dev is set and to
+// the live system otherwise.
+func BootScript(baseURL string, img Image, kernelArgs string, host *config.BootHost) string {
+	args := []string{
+		"initrd=main",
+		"coreos.live.rootfs_url=" + fileURL(baseURL, img, img.Rootfs),
+	}
+	if host != nil {
+		switch {
+		case host.InstallDev != "":
+			args = append(args, "coreos.inst.install_dev="+host.InstallDev)
+			if host.IgnitionURL != "" {
+				args = append(args, "coreos.inst.ignition_url="+host.IgnitionURL)
+			}
+		case host.IgnitionURL != "":
+			args = append(args, "ignition.firstboot", "ignition.platform.id=metal", "ignition.config.url="+host.IgnitionURL)
+		}
+	}
+	if kernelArgs = strings.TrimSpace(kernelArgs); kernelArgs != "" {
+		args = append(args, kernelArgs)
+	}
+	if host != nil && strings.TrimSpace(host.KernelArgs) != "" {
+		args = append(args, strings.TrimSpace(host.KernelArgs))
+	}
+
+	var b strings.Builder
+	b.WriteString("#!ipxe\n")
+	fmt.Fprintf(&b, "echo Booting %s\n", img.ID)
+	fmt.Fprintf(&b, "kernel %s %s\n", fileURL(baseURL, img, img.Kernel), strings.Join(args, " "))
+	fmt.Fprintf(&b, "initrd --name main %s\n", fileURL(baseURL, img, img.Initramfs))
+	b.WriteString("boot\n")
+	return b.String()
+}
@@ -0,0 +1,309 @@
+package pxe
+
+import (
+	"bytes"
+	"encoding/binary"
+	"errors"
+	"fmt"
+	"io"
+	"log/slog"
+	"net"
+	"os"
+	"strconv"
+	"strings"
+	"sync"
+	"time"
+
+	"github.com/BadgerOps/airgap/internal/safety"
+)
+
+const (
+	tftpRRQ   = 1
+	tftpWRQ   = 2
+	tftpDATA  = 3
+	tftpACK   = 4
+	tftpERROR = 5
+	tftpOACK  = 6
+
+	tftpErrNotFound     = 1
+	tftpErrAccess       = 2
+	tftpErrIllegal      = 4
+	tftpDefaultBlksize  = 512
+	tftpMaxBlksize      = 65464
+	tftpMinBlksize      = 8
+	tftpDefaultTimeout  = 3 * time.Second
+	tftpDefaultRetries  = 5
+	tftpMaxRequestBytes = 1024
+)
+
+// TFTPServer is a read-only TFTP server (RFC 1350) supporting the blksize
+// and tsize options (RFC 2347-2349) used by PXE ROMs and iPXE. Files are
+// served from root, with virtual files taking precedence.
+type TFTPServer struct {
+	root    string
+	virtual map[string][]byte
+	logger  *slog.Logger
+
+	// Timeout and Retries control retransmission of unacknowledged blocks.
+	Timeout time.Duration
+	Retries int
+
+	mu     sync.Mutex
+	conn   net.PacketConn
+	closed bool
+	wg     sync.WaitGroup
+}
+
+// NewTFTPServer creates a TFTP server for root (may be empty to serve only
+// virtual files).
+func NewTFTPServer(root string, virtual map[string][]byte, logger *slog.Logger) *TFTPServer {
+	if logger == nil {
+		logger = slog.Default()
+	}
+	return &TFTPServer{
+		root:    root,
+		virtual: virtual,
+		logger:  logger,
+		Timeout: tftpDefaultTimeout,
+		Retries: tftpDefaultRetries,
+	}
+}
+
+// ListenAndServe listens on the UDP address addr and serves requests until
+// Close is called.
+func (s *TFTPServer) ListenAndServe(addr string) error {
+	conn, err := net.ListenPacket("udp", addr)
+	if err != nil {
+		return fmt.Errorf("listening on %s: %w", addr, err)
+	}
+	return s.Serve(conn)
+}
+
+// Serve handles requests arriving on conn. Each transfer uses its own UDP
+// port as the protocol requires.
+func (s *TFTPServer) Serve(conn net.PacketConn) error {
+	s.mu.Lock()
+	if s.closed {
+		s.mu.Unlock()
+		_ = conn.Close()
+		return nil
+	}
+	s.conn = conn
+	s.mu.Unlock()
+
+	s.logger.Info("starting TFTP server", "addr", conn.LocalAddr().String(), "root", s.root)
+	buf := make([]byte, tftpMaxRequestBytes)
+	for {
+		n, addr, err := conn.ReadFrom(buf)
+		if err != nil {
+			s.mu.Lock()
+			closed := s.closed
+			s.mu.Unlock()
+			if closed {
+				return nil
+			}
+			return fmt.Errorf("reading TFTP request: %w", err)
+		}
+		req := append([]byte(nil), buf[:n]...)
+		s.wg.Add(1)
+		go func() {
+			defer s.wg.Done()
+			s.handle(conn.LocalAddr(), req, addr)
+		}()
+	}
+}
+
+// Close stops the server and waits for running transfers to finish.
+func (s *TFTPServer) Close() error {
+	s.mu.Lock()
+	s.closed = true
+	conn := s.conn
+	s.mu.Unlock()
+	var err error
+	if conn != nil {
+		err = conn.Close()
+	}
+	s.wg.Wait()
+	return err
+}
+
+// handle serves one request from a new transfer socket.
+func (s *TFTPServer) handle(local net.Addr, req []byte, client net.Addr) {
+	bindAddr := ":0"
+	if u, ok := local.(*net.UDPAddr); ok && u.IP != nil && !u.IP.IsUnspecified() {
+		bindAddr = net.JoinHostPort(u.IP.String(), "0")
+	}
+	conn, err := net.ListenPacket("udp", bindAddr)
+	if err != nil {
+		s.logger.Error("failed to open TFTP transfer socket", "error", err)
+		return
+	}
+	defer func() {
+		_ = conn.Close()
+	}()
+
+	if len(req) < 2 {
+		return
+	}
+	switch binary.BigEndian.Uint16(req) {
+	case tftpRRQ:
+	case tftpWRQ:
+		sendTFTPError(conn, client, tftpErrAccess, "write not supported")
+		return
+	default:
+		sendTFTPError(conn, client, tftpErrIllegal, "illegal operation")
+		return
+	}
+
+	fields := strings.Split(string(req[2:]), "\x00")
+	if len(fields) < 2 {
+		sendTFTPError(conn, client, tftpErrIllegal, "malformed request")
+		return
+	}
+	name, mode := fields[0], strings.ToLower(fields[1])
+	if mode != "octet" && mode != "netascii" {
+		sendTFTPError(conn, client, tftpErrIllegal, "unsupported mode")
+		return
+	}
+	opts := make(map[string]string)
+	for i := 2; i+1 < len(fields); i += 2 {
+		opts[strings.ToLower(fields[i])] = fields[i+1]
+	}
+
+	r, size, err := s.open(name)
+	if err != nil {
+		s.logger.Warn("TFTP request rejected", "client", client.String(), "file", name, "error", err)
+		sendTFTPError(conn, client, tftpErrNotFound, "file not found")
+		return
+	}
+	defer func() {
+		_ = r.Close()
+	}()
+
+	sent, err := s.transfer(conn, client, r, size, opts)
+	if err != nil {
+		s.logger.Warn("TFTP transfer failed", "client", client.String(), "file", name, "error", err)
+		return
+	}
+	s.logger.Info("TFTP transfer complete", "client", client.String(), "file", name, "bytes", sent)
+}
+
+// open resolves a requested file name to a virtual file or a file under root.
+func (s *TFTPServer) open(name string) (io.ReadCloser, int64, error) {
+	clean := strings.TrimLeft(strings.ReplaceAll(name, `\`, "/"), "/")
+	if data, ok := s.virtual[clean]; ok {
+		return io.NopCloser(bytes.NewReader(data)), int64(len(data)), nil
+	}
+	if s.root == "" {
+		return nil, 0, os.ErrNotExist
+	}
+	localPath, err := safety.SafeJoinUnder(s.root, clean)
+	if err != nil {
+		return nil, 0, err
+	}
+	f, err := os.Open(localPath)
+	if err != nil {
+		return nil, 0, err
+	}
+	info, err := f.Stat()
+	if err != nil || !info.Mode().IsRegular() {
+		_ = f.Close()
+		return nil, 0, fmt.Errorf("%s is not a regular file", clean)
+	}
+	return f, info.Size(), nil
+}
+
+// transfer negotiates options and sends r in DATA blocks, returning the
+// number of bytes sent.
+func (s *TFTPServer) transfer(conn net.PacketConn, client net.Addr, r io.Reader, size int64, opts map[string]string) (int64, error) {
+	blksize := tftpDefaultBlksize
+	oack := make([]string, 0, 4)
+	if v, ok := opts["blksize"]; ok {
+		if n, err := strconv.Atoi(v); err == nil && n >= tftpMinBlksize {
+			if n > tftpMaxBlksize {
+				n = tftpMaxBlksize
+			}
+			blksize = n
+			oack = append(oack, "blksize", strconv.Itoa(n))
+		}
+	}
+	if _, ok := opts["tsize"]; ok {
+		oack = append(oack, "tsize", strconv.FormatInt(size, 10))
+	}
+
+	if len(oack) > 0 {
+		pkt := []byte{0, tftpOACK}
+		for _, f := range oack {
+			pkt = append(pkt, f...)
+			pkt = append(pkt, 0)
+		}
+		if err := s.sendAndWait(conn, client, pkt, 0); err != nil {
+			return 0, fmt.Errorf("option negotiation: %w", err)
+		}
+	}
+
+	buf := make([]byte, 4+blksize)
+	var sent int64
+	for block := uint16(1); ; block++ {
+		n, err := io.ReadFull(r, buf[4:])
+		if err != nil && !errors.Is(err, io.EOF) && !errors.Is(err, io.ErrUnexpectedEOF) {
+			sendTFTPError(conn, client, tftpErrAccess, "read error")
+			return sent, fmt.Errorf("reading file: %w", err)
+		}
+		binary.BigEndian.PutUint16(buf[0:], tftpDATA)
+		binary.BigEndian.PutUint16(buf[2:], block)
+		if err := s.sendAndWait(conn, client, buf[:4+n], block); err != nil {
+			return sent, fmt.Errorf("block %d: %w", block, err)
+		}
+		sent += int64(n)
+		if n < blksize {
+			return sent, nil
+		}
+	}
+}
+
+// sendAndWait sends pkt and retransmits it until the client acknowledges
+// block or the retries run out.
+func (s *TFTPServer) sendAndWait(conn net.PacketConn, client net.Addr, pkt []byte, block uint16) error {
+	ack := make([]byte, tftpMaxRequestBytes)
+	for attempt := 0; attempt <= s.Retries; attempt++ {
+		if _, err := conn.WriteTo(pkt, client); err != nil {
+			return fmt.Errorf("sending: %w", err)
+		}
+		deadline := time.Now().Add(s.Timeout)
+		for {
+			if err := conn.SetReadDeadline(deadline); err != nil {
+				return err
+			}
+			n, addr, err := conn.ReadFrom(ack)
+			if err != nil {
+				var netErr net.Error
+				if errors.As(err, &netErr) && netErr.Timeout() {
+					break
+				}
+				return fmt.Errorf("waiting for ack: %w", err)
+			}
+			if addr.String() != client.String() || n < 4 {
+				continue
+			}
+			switch binary.BigEndian.Uint16(ack) {
+			case tftpACK:
+				if binary.BigEndian.Uint16(ack[2:]) == block {
+					return nil
+				}
+			case tftpERROR:
+				return fmt.Errorf("client aborted: %s", strings.TrimRight(string(ack[4:n]), "\x00"))
+			}
+		}
+	}
+	return fmt.Errorf("no acknowledgement after %d attempts", s.Retries+1)
+}
+
+func sendTFTPError(conn net.PacketConn, client net.Addr, code uint16, msg string) {
+	pkt := make([]byte, 4, 5+len(msg))
+	binary.BigEndian.PutUint16(pkt[0:], tftpERROR)
+	binary.BigEndian.PutUint16(pkt[2:], code)
+	pkt = append(pkt, msg...)
+	pkt = append(pkt, 0)
+	_, _ = conn.WriteTo(pkt, client)
+}
@@ -0,0 +1,120 @@
+package pxe
+
+import (
+	"bytes"
+	"encoding/binary"
+	"log/slog"
+	"net"
+	"os"
+	"path/filepath"
+	"strconv"
+	"strings"
+	"testing"
+	"time"
+)
+
+// tftpGet fetches name from addr, optionally with a blksize option, and
+// returns the file contents or the server's error message.
+func tftpGet(t *testing.T, addr net.Addr, name, blksize string) ([]byte, string) {
+	t.Helper()
+	conn, err := net.ListenPacket("udp", "127.0.0.1:0")
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer conn.Close()
+
+	req := []byte{0, tftpRRQ}
+	req = append(req, name+"\x00octet\x00"...)
+	if blksize != "" {
+		req = append(req, "blksize\x00"+blksize+"\x00tsize\x000\x00"...)
+	}
+	if _, err := conn.WriteTo(req, addr); err != nil {
+		t.Fatal(err)
+	}
+
+	var out bytes.Buffer
+	size := tftpDefaultBlksize
+	buf := make([]byte, tftpMaxBlksize+4)
+	for {
+		_ = conn.SetReadDeadline(time.Now().Add(5 * time.Second))
+		n, from, err := conn.ReadFrom(buf)
+		if err != nil {
+			t.Fatalf("reading reply: %v", err)
+		}
+		ack := func(block uint16) {
+			pkt := []byte{0, tftpACK, 0, 0}
+			binary.BigEndian.PutUint16(pkt[2:], block)
+			if _, err := conn.WriteTo(pkt, from); err != nil {
+				t.Fatal(err)
+			}
+		}
+		switch binary.BigEndian.Uint16(buf) {
+		case tftpERROR:
+			return nil, strings.TrimRight(string(buf[4:n]), "\x00")
+		case tftpOACK:
+			fields := strings.Split(string(buf[2:n]), "\x00")
+			for i := 0; i+1 < len(fields); i += 2 {
+				if fields[i] == "blksize" {
+					if size, err = strconv.Atoi(fields[i+1]); err != nil {
+						t.Fatalf("invalid blksize in OACK: %q", fields[i+1])
+					}
+				}
+			}
+			ack(0)
+		case tftpDATA:
+			out.Write(buf[4:n])
+			ack(binary.BigEndian.Uint16(buf[2:]))
+			if n-4 < size {
+				return out.Bytes(), ""
+			}
+		}
+	}
+}
+
+func TestTFTPServer(t *testing.T) {
+	root := t.TempDir()
+	kpxe := bytes.Repeat([]byte("undionly"), 300) // 2400 bytes, several blocks
+	if err := os.WriteFile(filepath.Join(root, "undionly.kpxe"), kpxe, 0o644); err != nil {
+		t.Fatal(err)
+	}
+	exact := bytes.Repeat([]byte("x"), 1024) // multiple of the block size
+	if err := os.WriteFile(filepath.Join(root, "exact.bin"), exact, 0o644); err != nil {
+		t.Fatal(err)
+	}
+
+	conn, err := net.ListenPacket("udp", "127.0.0.1:0")
+	if err != nil {
+		t.Fatal(err)
+	}
+	chain := []byte(ChainScript("http://127.0.0.1:8080"))
+	srv := NewTFTPServer(root, map[string][]byte{"autoexec.ipxe": chain}, slog.New(slog.NewTextHandler(os.Stderr, &slog.HandlerOptions{Level: slog.LevelError})))
+	done := make(chan error, 1)
+	go func() { done <- srv.Serve(conn) }()
+	defer func() {
+		if err := srv.Close(); err != nil {
+			t.Errorf("Close() failed: %v", err)
+		}
+		if err := <-done; err != nil {
+			t.Errorf("Serve() failed: %v", err)
+		}
+	}()
+
+	if got, msg := tftpGet(t, conn.LocalAddr(), "undionly.kpxe", ""); !bytes.Equal(got, kpxe) {
+		t.Errorf("undionly.kpxe: got %d bytes (error %q), want %d", len(got), msg, len(kpxe))
+	}
+	if got, msg := tftpGet(t, conn.LocalAddr(), "/undionly.kpxe", "1432"); !bytes.Equal(got, kpxe) {
+		t.Errorf("undionly.kpxe with blksize: got %d bytes (error %q), want %d", len(got), msg, len(kpxe))
+	}
+	if got, _ := tftpGet(t, conn.LocalAddr(), "exact.bin", ""); !bytes.Equal(got, exact) {
+		t.Errorf("exact.bin: got %d bytes, want %d", len(got), len(exact))
+	}
+	if got, _ := tftpGet(t, conn.LocalAddr(), "autoexec.ipxe", ""); !bytes.Equal(got, chain) {
+		t.Errorf("autoexec.ipxe = %q, want %q", got, chain)
+	}
+	if _, msg := tftpGet(t, conn.LocalAddr(), "../etc/passwd", ""); msg == "" {
+		t.Error("expected traversal to be rejected")
+	}
+	if _, msg := tftpGet(t, conn.LocalAddr(), "missing.efi", ""); msg != "file not found" {
+		t.Errorf("missing file error = %q", msg)
+	}
+}
@@ -0,0 +1,163 @@
+package registryauth
+
+import (
+	"context"
+	"encoding/base64"
+	"encoding/json"
+	"fmt"
+	"io"
+	"net/http"
+	"net/url"
+	"regexp"
+	"strings"
+
+	"github.com/BadgerOps/airgap/internal/safety"
+)
+
+const maxTokenBodyBytes int64 = 1 * 1024 * 1024
+
+var authParamRegexp = regexp.MustCompile(`([a-zA-Z_]+)="([^"]*)"`)
+
+// Authenticate answers a WWW-Authenticate challenge and returns the
+// Authorization header value to retry with. Basic challenges use cred
+// directly; Bearer challenges request a token from the realm, sending cred
+// when set and anonymously otherwise.
+func Authenticate(ctx context.Context, client *http.Client, challenge, scope string, cred *Credential) (string, error) {
+	scheme, _, _ := strings.Cut(strings.TrimSpace(challenge), " ")
+	switch strings.ToLower(scheme) {
+	case "basic":
+		if cred == nil || cred.Username == "" {
+			return "", fmt.Errorf("registry requires credentials")
+		}
+		return BasicHeader(cred), nil
+	case "bearer":
+		token, err := fetchToken(ctx, client, challenge, scope, cred)
+		if err != nil {
+			return "", err
+		}
+		return "Bearer " + token, nil
+	case "":
+		return "", fmt.Errorf("missing WWW-Authenticate challenge")
+	default:
+		return "", fmt.Errorf("unsupported auth challenge scheme %q", scheme)
+	}
+}
+
+// BasicHeader returns the Basic Authorization header value for cred.
+func BasicHeader(cred *Credential) string {
+	return "Basic " + base64.StdEncoding.EncodeToString([]byte(cred.Username+":"+cred.Password))
+}
+
+// Authorizer returns a request hook that sets header as Authorization, or
+// nil when header is empty.
+func Authorizer(header string) func(*http.Request) {
+	if header == "" {
+		return nil
+	}
+	return func(req *http.Request) {
+		req.Header.Set("Authorization", header)
+	}
+}
+
+func fetchToken(ctx context.Context, client *http.Client, challenge, scope string, cred *Credential) (string, error) {
+	params := parseAuthParams(challenge)
+	realm := params["realm"]
+	if realm == "" {
+		return "", fmt.Errorf("bearer challenge missing realm")
+	}
+	tokenScope := params["scope"]
+	if tokenScope == "" {
+		tokenScope = scope
+	}
+
+	var req *http.Request
+	var err error
+	if cred != nil && cred.IdentityToken != "" {
+		// OAuth2 refresh token grant, as used by docker login identity tokens.
+		form := url.Values{}
+		form.Set("grant_type", "refresh_token")
+		form.Set("refresh_token", cred.IdentityToken)
+		form.Set("client_id", "airgap")
+		if service := params["service"]; service != "" {
+			form.Set("service", service)
+		}
+		if tokenScope != "" {
+			form.Set("scope", tokenScope)
+		}
+		req, err = http.NewRequestWithContext(ctx, http.MethodPost, realm, strings.NewReader(form.Encode()))
+		if err == nil {
+			req.Header.Set("Content-Type", "application/x-www-form-urlencoded")
+		}
+	} else {
+		values := url.Values{}
+		if service := params["service"]; service != "" {
+			values.Set("service", service)
+		}
+		if tokenScope != "" {
+			values.Set("scope", tokenScope)
+		}
+		tokenURL := realm
+		if encoded := values.Encode(); encoded != "" {
+			if strings.Contains(tokenURL, "?") {
+				tokenURL += "&" + encoded
+			} else {
+				tokenURL += "?" + encoded
+			}
+		}
+		req, err = http.NewRequestWithContext(ctx, http.MethodGet, tokenURL, nil)
+		if err == nil && cred != nil && cred.Username != "" {
+			req.SetBasicAuth(cred.Username, cred.Password)
+		}
+	}
+	if err != nil {
+		return "", fmt.Errorf("creating token request: %w", err)
+	}
+
+	resp, err := client.Do(req)
+	if err != nil {
+		return "", fmt.Errorf("executing token request: %w", err)
+	}
+	defer func() {
+		_ = resp.Body.Close()
+	}()
+
+	if resp.StatusCode < 200 || resp.StatusCode > 299 {
+		body, _ := io.ReadAll(io.LimitReader(resp.Body, 4096))
+		return "", fmt.Errorf("token endpoint returned %s: %s", resp.Status, strings.TrimSpace(string(body)))
+	}
+
+	data, err := safety.ReadAllWithLimit(resp.Body, maxTokenBodyBytes)
+	if err != nil {
+		return "", fmt.Errorf("reading token response: %w", err)
+	}
+	var tokenResp struct {
+		Token       string `json:"token"`
+		AccessToken string `json:"access_token"`
+	}
+	if err := json.Unmarshal(data, &tokenResp); err != nil {
+		return "", fmt.Errorf("parsing token response: %w", err)
+	}
+	token := tokenResp.Token
+	if token == "" {
+		token = tokenResp.AccessToken
+	}
+	if token == "" {
+		return "", fmt.Errorf("token response did not include token")
+	}
+	return token, nil
+}
+
+func parseAuthParams(challenge string) map[string]string {
+	result := make(map[string]string)
+	trimmed := strings.TrimSpace(challenge)
+	if strings.HasPrefix(strings.ToLower(trimmed), "bearer ") {
+		trimmed = strings.TrimSpace(trimmed[len("bearer "):])
+	}
+	matches := authParamRegexp.FindAllStringSubmatch(trimmed, -1)
+	for _, m := range matches {
+		if len(m) == 3 {
+			result[strings.ToLower(m[1])] = m[2]
+		}
+	}
+	return result
+}
@@ -0,0 +1,80 @@
+package registryauth
+
+import (
+	"context"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+)
+
+func TestAuthenticateBasic(t *testing.T) {
+	cred := &Credential{Username: "user", Password: "pass"}
+	header, err := Authenticate(context.Background(), http.DefaultClient, `Basic realm="registry"`, "", cred)
+	if err != nil {
+		t.Fatalf("Authenticate: %v", err)
+	}
+	req := httptest.NewRequest(http.MethodGet, "/", nil)
+	Authorizer(header)(req)
+	user, pass, ok := req.BasicAuth()
+	if !ok || user != "user" || pass != "pass" {
+		t.Fatalf("unexpected basic auth %q %q %v", user, pass, ok)
+	}
+
+	if _, err := Authenticate(context.Background(), http.DefaultClient, `Basic realm="registry"`, "", nil); err == nil {
+		t.Fatal("expected error for basic challenge without credentials")
+	}
+}
+
+func TestAuthenticateBearer(t *testing.T) {
+	srv := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		if r.URL.Query().Get("scope") != "repository:ns/repo:pull" || r.URL.Query().Get("service") != "registry" {
+			http.Error(w, "bad query", http.StatusBadRequest)
+			return
+		}
+		if user, pass, ok := r.BasicAuth(); !ok || user != "user" || pass != "pass" {
+			http.Error(w, "unauthorized", http.StatusUnauthorized)
+			return
+		}
+		_, _ = w.Write([]byte(`{"token":"abc"}`))
+	}))
+	defer srv.Close()
+
+	challenge := `Bearer realm="` + srv.URL + `/token",service="registry"`
+	header, err := Authenticate(context.Background(), srv.Client(), challenge, "repository:ns/repo:pull", &Credential{Username: "user", Password: "pass"})
+	if err != nil {
+		t.Fatalf("Authenticate: %v", err)
+	}
+	if header != "Bearer abc" {
+		t.Fatalf("expected bearer header, got %q", header)
+	}
+
+	if _, err := Authenticate(context.Background(), srv.Client(), challenge, "repository:ns/repo:pull", nil); err == nil {
+		t.Fatal("expected anonymous token request to be rejected")
+	}
+}
+
+func TestAuthenticateBearerIdentityToken(t *testing.T) {
+	srv := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		if err := r.ParseForm(); err != nil || r.Method != http.MethodPost ||
+			r.PostForm.Get("grant_type") != "refresh_token" || r.PostForm.Get("refresh_token") != "refresh" {
+			http.Error(w, "bad request", http.StatusBadRequest)
+			return
+		}
+		_, _ = w.Write([]byte(`{"access_token":"xyz"}`))
+	}))
+	defer srv.Close()
+
+	header, err := Authenticate(context.Background(), srv.Client(), `Bearer realm="`+srv.URL+`"`, "repository:ns/repo:pull", &Credential{IdentityToken: "refresh"})
+	if err != nil {
+		t.Fatalf("Authenticate: %v", err)
+	}
+	if header != "Bearer xyz" {
+		t.Fatalf("expected bearer header, got %q", header)
+	}
+}
+
+func TestAuthorizerEmpty(t *testing.T) {
+	if Authorizer("") != nil {
+		t.Fatal("expected nil authorizer for empty header")
+	}
+}
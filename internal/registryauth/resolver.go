@@ -0,0 +1,265 @@
+// Package registryauth resolves container registry pull credentials and
+// answers registry authentication challenges.
+package registryauth
+
+import (
+	"bytes"
+	"context"
+	"encoding/base64"
+	"encoding/json"
+	"fmt"
+	"log/slog"
+	"os"
+	"os/exec"
+	"strings"
+	"sync"
+
+	"github.com/BadgerOps/airgap/internal/config"
+)
+
+// Credential is a resolved registry login. Its secrets are never rendered
+// by fmt or slog.
+type Credential struct {
+	Username string
+	Password string
+	// IdentityToken is an OAuth2 refresh token used instead of Password.
+	IdentityToken string
+}
+
+func (c Credential) String() string {
+	return fmt.Sprintf("Credential{Username: %q, secret: [redacted]}", c.Username)
+}
+
+func (c Credential) GoString() string { return c.String() }
+
+// LogValue implements slog.LogValuer.
+func (c Credential) LogValue() slog.Value {
+	return slog.GroupValue(slog.String("username", c.Username), slog.String("secret", "[redacted]"))
+}
+
+// Resolver finds the credential for a registry repository. Sources are
+// checked in order: provider config entries, the auth file's "auths", then
+// its "credHelpers" and "credsStore" helpers. The most specific
+// host[/namespace] key wins within each source.
+type Resolver struct {
+	entries  []config.RegistryCredential
+	authFile string
+
+	mu     sync.Mutex
+	loaded bool
+	file   *authFile
+	cache  map[string]*Credential
+}
+
+type authFile struct {
+	Auths map[string]struct {
+		Auth          string `json:"auth"`
+		Username      string `json:"username"`
+		Password      string `json:"password"`
+		IdentityToken string `json:"identitytoken"`
+	} `json:"auths"`
+	CredHelpers map[string]string `json:"credHelpers"`
+	CredsStore  string            `json:"credsStore"`
+}
+
+// NewResolver creates a resolver. An empty authFile falls back to
+// $REGISTRY_AUTH_FILE; no file at all is not an error.
+func NewResolver(entries []config.RegistryCredential, authFile string) *Resolver {
+	if authFile == "" {
+		authFile = os.Getenv("REGISTRY_AUTH_FILE")
+	}
+	return &Resolver{
+		entries:  entries,
+		authFile: authFile,
+		cache:    make(map[string]*Credential),
+	}
+}
+
+// Validate checks that the auth file, when set, can be read and parsed.
+func (r *Resolver) Validate() error {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+	return r.load()
+}
+
+// Lookup returns the credential for repository on registry, or nil when
+// none is configured.
+func (r *Resolver) Lookup(ctx context.Context, registry, repository string) (*Credential, error) {
+	if r == nil {
+		return nil, nil
+	}
+	keys := lookupKeys(registry, repository)
+	if len(keys) == 0 {
+		return nil, nil
+	}
+
+	r.mu.Lock()
+	defer r.mu.Unlock()
+	if cred, ok := r.cache[keys[0]]; ok {
+		return cred, nil
+	}
+	cred, err := r.resolve(ctx, keys)
+	if err != nil {
+		return nil, err
+	}
+	r.cache[keys[0]] = cred
+	return cred, nil
+}
+
+func (r *Resolver) resolve(ctx context.Context, keys []string) (*Credential, error) {
+	for _, key := range keys {
+		for _, e := range r.entries {
+			if normalizeKey(e.Registry) != key {
+				continue
+			}
+			if e.Helper != "" {
+				return runHelper(ctx, e.Helper, helperServer(keyHost(key)))
+			}
+			return &Credential{Username: e.Username, Password: e.Password}, nil
+		}
+	}
+
+	if err := r.load(); err != nil {
+		return nil, err
+	}
+	if r.file == nil {
+		return nil, nil
+	}
+	for _, key := range keys {
+		for k, a := range r.file.Auths {
+			if normalizeKey(k) != key {
+				continue
+			}
+			cred := &Credential{Username: a.Username, Password: a.Password, IdentityToken: a.IdentityToken}
+			if a.Auth != "" {
+				decoded, err := base64.StdEncoding.DecodeString(a.Auth)
+				if err != nil {
+					return nil, fmt.Errorf("auth file entry %s: invalid auth encoding", k)
+				}
+				user, pass, ok := strings.Cut(string(decoded), ":")
+				if !ok {
+					return nil, fmt.Errorf("auth file entry %s: auth is not user:password", k)
+				}
+				cred.Username, cred.Password = user, pass
+			}
+			return cred, nil
+		}
+	}
+	host := keys[len(keys)-1]
+	for k, helper := range r.file.CredHelpers {
+		if normalizeKey(k) == host {
+			return runHelper(ctx, helper, helperServer(host))
+		}
+	}
+	if r.file.CredsStore != "" {
+		return runHelper(ctx, r.file.CredsStore, helperServer(host))
+	}
+	return nil, nil
+}
+
+// load reads the auth file once. The caller holds r.mu.
+func (r *Resolver) load() error {
+	if r.loaded {
+		return nil
+	}
+	if r.authFile == "" {
+		r.loaded = true
+		return nil
+	}
+	data, err := os.ReadFile(r.authFile)
+	if err != nil {
+		return fmt.Errorf("reading auth file: %w", err)
+	}
+	var f authFile
+	if err := json.Unmarshal(data, &f); err != nil {
+		return fmt.Errorf("parsing auth file %s: %w", r.authFile, err)
+	}
+	r.file = &f
+	r.loaded = true
+	return nil
+}
+
+// runHelper asks a docker-credential-helpers executable for the credential
+// of server. Helper output is never included in errors as it may hold the
+// secret.
+func runHelper(ctx context.Context, helper, server string) (*Credential, error) {
+	bin := helper
+	if !strings.ContainsRune(helper, os.PathSeparator) {
+		bin = "docker-credential-" + helper
+	}
+	cmd := exec.CommandContext(ctx, bin, "get")
+	cmd.Stdin = strings.NewReader(server)
+	var stdout bytes.Buffer
+	cmd.Stdout = &stdout
+	if err := cmd.Run(); err != nil {
+		if strings.Contains(stdout.String(), "credentials not found") {
+			return nil, nil
+		}
+		return nil, fmt.Errorf("credential helper %s failed for %s: %w", helper, server, err)
+	}
+	var out struct {
+		Username string `json:"Username"`
+		Secret   string `json:"Secret"`
+	}
+	if err := json.Unmarshal(stdout.Bytes(), &out); err != nil {
+		return nil, fmt.Errorf("credential helper %s returned invalid output for %s", helper, server)
+	}
+	if out.Username == "<token>" {
+		return &Credential{IdentityToken: out.Secret}, nil
+	}
+	return &Credential{Username: out.Username, Password: out.Secret}, nil
+}
+
+// lookupKeys returns host/repository prefixes from most to least specific,
+// ending with the host.
+func lookupKeys(registry, repository string) []string {
+	host := normalizeKey(registry)
+	if host == "" {
+		return nil
+	}
+	keys := []string{host}
+	parts := strings.Split(strings.ToLower(strings.Trim(repository, "/")), "/")
+	for i := range parts {
+		if parts[i] == "" {
+			break
+		}
+		keys = append(keys, host+"/"+strings.Join(parts[:i+1], "/"))
+	}
+	for i, j := 0, len(keys)-1; i < j; i, j = i+1, j-1 {
+		keys[i], keys[j] = keys[j], keys[i]
+	}
+	return keys
+}
+
+// normalizeKey strips schemes and the legacy /v1/ suffix and folds Docker
+// Hub aliases to docker.io.
+func normalizeKey(key string) string {
+	k := strings.TrimSpace(strings.ToLower(key))
+	k = strings.TrimPrefix(k, "https://")
+	k = strings.TrimPrefix(k, "http://")
+	k = strings.TrimSuffix(k, "/")
+	k = strings.TrimSuffix(k, "/v1")
+	k = strings.TrimSuffix(k, "/v2")
+	host, rest, _ := strings.Cut(k, "/")
+	switch host {
+	case "index.docker.io", "registry-1.docker.io":
+		host = "docker.io"
+	}
+	if rest != "" {
+		return host + "/" + rest
+	}
+	return host
+}
+
+// helperServer is the server URL credential helpers store host under.
+func helperServer(host string) string {
+	if host == "docker.io" {
+		return "https://index.docker.io/v1/"
+	}
+	return host
+}
+
+func keyHost(key string) string {
+	host, _, _ := strings.Cut(key, "/")
+	return host
+}
@@ -0,0 +1,144 @@
+package registryauth
+
+import (
+	"context"
+	"encoding/base64"
+	"fmt"
+	"log/slog"
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+
+	"github.com/BadgerOps/airgap/internal/config"
+)
+
+func writeAuthFile(t *testing.T, content string) string {
+	t.Helper()
+	path := filepath.Join(t.TempDir(), "auth.json")
+	if err := os.WriteFile(path, []byte(content), 0o600); err != nil {
+		t.Fatalf("writing auth file: %v", err)
+	}
+	return path
+}
+
+func TestLookupAuthFile(t *testing.T) {
+	auth := base64.StdEncoding.EncodeToString([]byte("host-user:host-pass"))
+	nsAuth := base64.StdEncoding.EncodeToString([]byte("ns-user:ns-pass"))
+	path := writeAuthFile(t, fmt.Sprintf(`{"auths": {
+		"quay.io": {"auth": %q},
+		"quay.io/openshift-release-dev": {"auth": %q},
+		"https://index.docker.io/v1/": {"username": "hub-user", "password": "hub-pass"}
+	}}`, auth, nsAuth))
+
+	r := NewResolver(nil, path)
+	if err := r.Validate(); err != nil {
+		t.Fatalf("Validate: %v", err)
+	}
+
+	tests := []struct {
+		registry, repo, wantUser string
+	}{
+		{"quay.io", "openshift-release-dev/ocp-release", "ns-user"},
+		{"quay.io", "other/image", "host-user"},
+		{"registry-1.docker.io", "library/alpine", "hub-user"},
+		{"docker.io", "library/alpine", "hub-user"},
+	}
+	for _, tt := range tests {
+		cred, err := r.Lookup(context.Background(), tt.registry, tt.repo)
+		if err != nil {
+			t.Fatalf("Lookup(%s, %s): %v", tt.registry, tt.repo, err)
+		}
+		if cred == nil || cred.Username != tt.wantUser {
+			t.Fatalf("Lookup(%s, %s) = %v, want user %s", tt.registry, tt.repo, cred, tt.wantUser)
+		}
+	}
+
+	cred, err := r.Lookup(context.Background(), "registry.example.com", "foo")
+	if err != nil || cred != nil {
+		t.Fatalf("expected no credential for unknown registry, got %v, %v", cred, err)
+	}
+}
+
+func TestLookupConfigEntriesWinOverAuthFile(t *testing.T) {
+	path := writeAuthFile(t, `{"auths": {"quay.io": {"username": "file", "password": "x"}}}`)
+	r := NewResolver([]config.RegistryCredential{
+		{Registry: "quay.io", Username: "config", Password: "y"},
+	}, path)
+	cred, err := r.Lookup(context.Background(), "quay.io", "ns/repo")
+	if err != nil {
+		t.Fatalf("Lookup: %v", err)
+	}
+	if cred == nil || cred.Username != "config" {
+		t.Fatalf("expected config entry credential, got %v", cred)
+	}
+}
+
+func TestLookupAuthFileFromEnv(t *testing.T) {
+	path := writeAuthFile(t, `{"auths": {"quay.io": {"username": "env", "password": "x"}}}`)
+	t.Setenv("REGISTRY_AUTH_FILE", path)
+	cred, err := NewResolver(nil, "").Lookup(context.Background(), "quay.io", "ns/repo")
+	if err != nil {
+		t.Fatalf("Lookup: %v", err)
+	}
+	if cred == nil || cred.Username != "env" {
+		t.Fatalf("expected credential from $REGISTRY_AUTH_FILE, got %v", cred)
+	}
+}
+
+func TestValidateRejectsInvalidAuthFile(t *testing.T) {
+	if err := NewResolver(nil, filepath.Join(t.TempDir(), "missing.json")).Validate(); err == nil {
+		t.Fatal("expected error for missing auth file")
+	}
+	if err := NewResolver(nil, writeAuthFile(t, "{not json")).Validate(); err == nil {
+		t.Fatal("expected error for malformed auth file")
+	}
+}
+
+func TestLookupCredentialHelper(t *testing.T) {
+	dir := t.TempDir()
+	helper := filepath.Join(dir, "helper")
+	script := `#!/bin/sh
+read server
+if [ "$server" = "registry.example.com" ]; then
+  echo '{"ServerURL":"registry.example.com","Username":"helper-user","Secret":"helper-secret"}'
+  exit 0
+fi
+echo "credentials not found in native keychain"
+exit 1
+`
+	if err := os.WriteFile(helper, []byte(script), 0o755); err != nil {
+		t.Fatalf("writing helper: %v", err)
+	}
+
+	r := NewResolver([]config.RegistryCredential{
+		{Registry: "registry.example.com", Helper: helper},
+		{Registry: "other.example.com", Helper: helper},
+	}, "")
+	cred, err := r.Lookup(context.Background(), "registry.example.com", "ns/repo")
+	if err != nil {
+		t.Fatalf("Lookup: %v", err)
+	}
+	if cred == nil || cred.Username != "helper-user" || cred.Password != "helper-secret" {
+		t.Fatalf("unexpected helper credential %v", cred)
+	}
+
+	cred, err = r.Lookup(context.Background(), "other.example.com", "ns/repo")
+	if err != nil || cred != nil {
+		t.Fatalf("expected no credential when helper has none, got %v, %v", cred, err)
+	}
+}
+
+func TestCredentialRedaction(t *testing.T) {
+	cred := Credential{Username: "user", Password: "s3cret", IdentityToken: "tok3n"}
+
+	var logs strings.Builder
+	logger := slog.New(slog.NewTextHandler(&logs, nil))
+	logger.Info("resolved", "cred", cred)
+
+	for _, out := range []string{fmt.Sprint(cred), fmt.Sprintf("%+v", cred), fmt.Sprintf("%#v", cred), logs.String()} {
+		if strings.Contains(out, "s3cret") || strings.Contains(out, "tok3n") {
+			t.Fatalf("secret leaked in %q", out)
+		}
+	}
+}
@@ -0,0 +1,189 @@
+package server
+
+import (
+	"errors"
+	"log/slog"
+	"net"
+	"net/http"
+	"strconv"
+	"strings"
+	"time"
+
+	"github.com/BadgerOps/airgap/internal/audit"
+	"github.com/BadgerOps/airgap/internal/store"
+)
+
+// errOperationRunning is recorded when an action is refused because a sync,
+// scan or retry is already running.
+var errOperationRunning = errors.New("another sync or scan is running")
+
+// Headers set by an authenticating reverse proxy to name the user.
+var actorHeaders = []string{"X-Remote-User", "X-Forwarded-User"}
+
+// requestActor names the user behind r: the user asserted by a trusted
+// authenticating proxy, then the basic auth user it passed on, else
+// "anonymous". Requests from other peers cannot choose their actor.
+func (s *Server) requestActor(r *http.Request) string {
+	if !s.fromTrustedProxy(r) {
+		return "anonymous"
+	}
+	for _, h := range actorHeaders {
+		if v := r.Header.Get(h); v != "" {
+			return v
+		}
+	}
+	if user, _, ok := r.BasicAuth(); ok && user != "" {
+		return user
+	}
+	return "anonymous"
+}
+
+// fromTrustedProxy reports whether r came from one of audit.trusted_proxies.
+func (s *Server) fromTrustedProxy(r *http.Request) bool {
+	ip := net.ParseIP(requestIP(r))
+	if ip == nil {
+		return false
+	}
+	for _, n := range s.trustedProxies {
+		if n.Contains(ip) {
+			return true
+		}
+	}
+	return false
+}
+
+// parseTrustedProxies parses addresses and CIDRs, skipping invalid entries.
+func parseTrustedProxies(entries []string, logger *slog.Logger) []*net.IPNet {
+	var nets []*net.IPNet
+	for _, e := range entries {
+		if !strings.Contains(e, "/") {
+			ip := net.ParseIP(e)
+			if ip == nil {
+				logger.Warn("ignoring invalid audit.trusted_proxies entry", "entry", e)
+				continue
+			}
+			bits := 8 * net.IPv6len
+			if ip4 := ip.To4(); ip4 != nil {
+				ip, bits = ip4, 8*net.IPv4len
+			}
+			nets = append(nets, &net.IPNet{IP: ip, Mask: net.CIDRMask(bits, bits)})
+			continue
+		}
+		_, n, err := net.ParseCIDR(e)
+		if err != nil {
+			logger.Warn("ignoring invalid audit.trusted_proxies entry", "entry", e, "error", err)
+			continue
+		}
+		nets = append(nets, n)
+	}
+	return nets
+}
+
+// requestIP returns the host part of r's remote address.
+func requestIP(r *http.Request) string {
+	host, _, err := net.SplitHostPort(r.RemoteAddr)
+	if err != nil {
+		return r.RemoteAddr
+	}
+	return host
+}
+
+// auditEvent starts an audit event for action on target requested by r.
+func (s *Server) auditEvent(r *http.Request, action, target string) store.AuditEvent {
+	return store.AuditEvent{
+		Actor:    s.requestActor(r),
+		SourceIP: requestIP(r),
+		Source:   audit.SourceAPI,
+		Action:   action,
+		Target:   target,
+	}
+}
+
+// recordAudit records action on target with the outcome of err. detail
+// describes a successful action; a failure records the error instead.
+func (s *Server) recordAudit(r *http.Request, action, target, detail string, err error) {
+	ev := s.auditEvent(r, action, target)
+	ev.Outcome, ev.Detail = audit.Outcome(err)
+	if err == nil {
+		ev.Detail = detail
+	}
+	s.audit.Record(ev)
+}
+
+// auditEventJSON is the JSON shape of an audit event.
+type auditEventJSON struct {
+	ID       int64     `json:"id"`
+	Time     time.Time `json:"time"`
+	Actor    string    `json:"actor"`
+	SourceIP string    `json:"source_ip,omitempty"`
+	Source   string    `json:"source"`
+	Action   string    `json:"action"`
+	Target   string    `json:"target,omitempty"`
+	Before   string    `json:"before,omitempty"`
+	After    string    `json:"after,omitempty"`
+	Outcome  string    `json:"outcome"`
+	Detail   string    `json:"detail,omitempty"`
+	PrevHash string    `json:"prev_hash,omitempty"`
+	Hash     string    `json:"hash,omitempty"`
+}
+
+// handleAPIAudit lists audit events, newest first. actor, action (exact, or
+// a prefix ending in "."), target and outcome filter exactly; since and
+// until take RFC 3339 times.
+func (s *Server) handleAPIAudit(w http.ResponseWriter, r *http.Request) {
+	params := r.URL.Query()
+	f := store.AuditFilter{
+		Actor:   params.Get("actor"),
+		Action:  params.Get("action"),
+		Target:  params.Get("target"),
+		Outcome: params.Get("outcome"),
+		Limit:   100,
+	}
+	for _, p := range []struct {
+		name string
+		dst  *time.Time
+	}{{"since", &f.Since}, {"until", &f.Until}} {
+		if v := params.Get(p.name); v != "" {
+			t, err := time.Parse(time.RFC3339, v)
+			if err != nil {
+				jsonError(w, http.StatusBadRequest, p.name+" must be an RFC 3339 time")
+				return
+			}
+			*p.dst = t
+		}
+	}
+	if v := params.Get("limit"); v != "" {
+		limit, err := strconv.Atoi(v)
+		if err != nil || limit < 1 {
+			jsonError(w, http.StatusBadRequest, "limit must be a positive integer")
+			return
+		}
+		f.Limit = limit
+	}
+
+	events, err := s.store.ListAuditEvents(f)
+	if err != nil {
+		s.logger.Error("failed to list audit events", "error", err)
+		jsonError(w, http.StatusInternalServerError, "failed to list audit events")
+		return
+	}
+
+	result := make([]auditEventJSON, 0, len(events))
+	for _, e := range events {
+		result = append(result, auditEventJSON(e))
+	}
+	w.Header().Set("Content-Type", "application/json")
+	s.writeJSON(w, result)
+}
+
+// handleAPIAuditVerify checks the audit log's hash chain.
+func (s *Server) handleAPIAuditVerify(w http.ResponseWriter, r *http.Request) {
+	result, err := audit.Verify(s.store)
+	if err != nil {
+		s.logger.Error("failed to verify audit log", "error", err)
+		jsonError(w, http.StatusInternalServerError, "failed to verify audit log")
+		return
+	}
+	w.Header().Set("Content-Type", "application/json")
+	s.writeJSON(w, result)
+}
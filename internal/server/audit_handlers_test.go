@@ -0,0 +1,163 @@
+package server
+
+import (
+	"bytes"
+	"encoding/json"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/BadgerOps/airgap/internal/store"
+)
+
+func TestProviderConfigChangesAreAudited(t *testing.T) {
+	srv := setupTestServer(t)
+	srv.trustedProxies = parseTrustedProxies([]string{"10.0.0.0/8"}, srv.logger)
+
+	body := `{"name":"mirror","type":"registry","enabled":true,"config":{"endpoint":"r.example","password":"hunter2"}}`
+	req := httptest.NewRequest("POST", "/api/providers/config", bytes.NewBufferString(body))
+	req.Header.Set("X-Remote-User", "alice")
+	req.RemoteAddr = "10.0.0.7:51234"
+	w := httptest.NewRecorder()
+	srv.handleCreateProviderConfig(w, req)
+	if w.Code != http.StatusCreated {
+		t.Fatalf("create failed: %d %s", w.Code, w.Body.String())
+	}
+
+	req = httptest.NewRequest("PUT", "/api/providers/config/mirror",
+		bytes.NewBufferString(`{"enabled":false,"config":{"endpoint":"r2.example","password":"hunter3"}}`))
+	req.SetPathValue("name", "mirror")
+	w = httptest.NewRecorder()
+	srv.handleUpdateProviderConfig(w, req)
+	if w.Code != http.StatusOK {
+		t.Fatalf("update failed: %d %s", w.Code, w.Body.String())
+	}
+
+	req = httptest.NewRequest("GET", "/api/audit?action=provider.", nil)
+	w = httptest.NewRecorder()
+	srv.handleAPIAudit(w, req)
+	if w.Code != http.StatusOK {
+		t.Fatalf("GET /api/audit: %d %s", w.Code, w.Body.String())
+	}
+	if strings.Contains(w.Body.String(), "hunter") {
+		t.Fatalf("audit log leaked a password: %s", w.Body.String())
+	}
+
+	var events []auditEventJSON
+	if err := json.NewDecoder(w.Body).Decode(&events); err != nil {
+		t.Fatalf("decode: %v", err)
+	}
+	if len(events) != 2 {
+		t.Fatalf("got %d events, want 2", len(events))
+	}
+	update, create := events[0], events[1]
+	if create.Action != "provider.create" || create.Actor != "alice" || create.SourceIP != "10.0.0.7" ||
+		create.Source != "api" || create.Outcome != "success" || create.Before != "" {
+		t.Errorf("create event = %+v", create)
+	}
+	if update.Action != "provider.update" || update.Actor != "anonymous" || update.Target != "mirror" {
+		t.Errorf("update event = %+v", update)
+	}
+	if !strings.Contains(update.Before, `"endpoint":"r.example"`) || !strings.Contains(update.After, `"endpoint":"r2.example"`) ||
+		!strings.Contains(update.After, `"password":"[REDACTED]"`) || !strings.Contains(update.After, `"enabled":false`) {
+		t.Errorf("update before/after = %s / %s", update.Before, update.After)
+	}
+}
+
+func TestRequestActorTrustsOnlyConfiguredProxies(t *testing.T) {
+	srv := setupTestServer(t)
+	srv.trustedProxies = parseTrustedProxies([]string{"10.0.0.0/8", "192.168.1.5", "not-an-ip"}, srv.logger)
+
+	tests := []struct {
+		remoteAddr string
+		header     string
+		basicUser  string
+		want       string
+	}{
+		{"10.1.2.3:5000", "alice", "", "alice"},
+		{"192.168.1.5:5000", "", "bob", "bob"},
+		{"192.168.1.6:5000", "mallory", "", "anonymous"},
+		{"203.0.113.9:5000", "", "mallory", "anonymous"},
+		{"10.1.2.3:5000", "", "", "anonymous"},
+	}
+	for _, tt := range tests {
+		req := httptest.NewRequest("GET", "/", nil)
+		req.RemoteAddr = tt.remoteAddr
+		if tt.header != "" {
+			req.Header.Set("X-Remote-User", tt.header)
+		}
+		if tt.basicUser != "" {
+			req.SetBasicAuth(tt.basicUser, "secret")
+		}
+		if got := srv.requestActor(req); got != tt.want {
+			t.Errorf("requestActor(%s, %q, %q) = %q, want %q", tt.remoteAddr, tt.header, tt.basicUser, got, tt.want)
+		}
+	}
+
+	// A forged header from an untrusted peer does not reach the audit log.
+	req := httptest.NewRequest("POST", "/api/providers/config",
+		bytes.NewBufferString(`{"name":"epel","type":"epel","enabled":true,"config":{}}`))
+	req.RemoteAddr = "203.0.113.9:5000"
+	req.Header.Set("X-Forwarded-User", "admin")
+	w := httptest.NewRecorder()
+	srv.handleCreateProviderConfig(w, req)
+	if w.Code != http.StatusCreated {
+		t.Fatalf("create failed: %d %s", w.Code, w.Body.String())
+	}
+	events, err := srv.store.ListAuditEvents(store.AuditFilter{Action: "provider.create"})
+	if err != nil {
+		t.Fatal(err)
+	}
+	if len(events) != 1 || events[0].Actor != "anonymous" || events[0].SourceIP != "203.0.113.9" {
+		t.Errorf("audit events = %+v, want anonymous from 203.0.113.9", events)
+	}
+}
+
+func TestHandleAPIAuditRejectsBadFilters(t *testing.T) {
+	srv := setupTestServer(t)
+
+	for _, query := range []string{"since=yesterday", "until=2026-01-01", "limit=0"} {
+		req := httptest.NewRequest("GET", "/api/audit?"+query, nil)
+		w := httptest.NewRecorder()
+		srv.handleAPIAudit(w, req)
+		if w.Code != http.StatusBadRequest {
+			t.Errorf("GET /api/audit?%s = %d, want 400", query, w.Code)
+		}
+	}
+}
+
+func TestDryRunSyncIsNotAudited(t *testing.T) {
+	srv := setupTestServer(t)
+
+	for _, body := range []string{`{"provider":"all","dry_run":true}`, `{"provider":"all"}`} {
+		req := httptest.NewRequest("POST", "/api/sync", bytes.NewBufferString(body))
+		req.Header.Set("Content-Type", "application/json")
+		w := httptest.NewRecorder()
+		srv.handleAPISync(w, req)
+		if w.Code != http.StatusOK {
+			t.Fatalf("POST /api/sync %s: %d %s", body, w.Code, w.Body.String())
+		}
+		for deadline := time.Now().Add(5 * time.Second); ; {
+			srv.syncMu.Lock()
+			running := srv.syncRunning
+			srv.syncMu.Unlock()
+			if !running {
+				break
+			}
+			if time.Now().After(deadline) {
+				t.Fatal("sync did not finish")
+			}
+			time.Sleep(10 * time.Millisecond)
+		}
+	}
+
+	events, err := srv.store.ListAuditEvents(store.AuditFilter{Action: "sync.start"})
+	if err != nil {
+		t.Fatal(err)
+	}
+	if len(events) != 1 || events[0].Detail != "force=false" {
+		t.Errorf("sync.start events = %+v, want only the real sync", events)
+	}
+}
@@ -0,0 +1,205 @@
+package server
+
+import (
+	"encoding/json"
+	"fmt"
+	"net/http"
+	"os"
+	"path"
+	"strings"
+
+	"github.com/BadgerOps/airgap/internal/config"
+	"github.com/BadgerOps/airgap/internal/pxe"
+	"github.com/BadgerOps/airgap/internal/safety"
+)
+
+// bootMenuTimeout is how long the iPXE menu waits before booting the default
+// image, in seconds.
+const bootMenuTimeout = 10
+
+// handleBootMenu serves the iPXE entry script. A node whose ?mac= matches a
+// configured host boots its image directly; others get a menu of mirrored
+// RHCOS images.
+func (s *Server) handleBootMenu(w http.ResponseWriter, r *http.Request) {
+	images, _, err := s.bootImages()
+	if err != nil {
+		s.logger.Error("failed to list boot images", "error", err)
+		http.Error(w, "failed to list boot images", http.StatusInternalServerError)
+		return
+	}
+	boot := s.config.Boot
+	base := s.bootBaseURL(r)
+
+	mac := r.URL.Query().Get("mac")
+	if host := pxe.FindHost(boot.Hosts, mac); host != nil {
+		ref := host.Image
+		if ref == "" {
+			ref = boot.Default
+		}
+		if img, ok := pxe.FindImage(images, ref); ok {
+			s.logger.Info("serving host boot script", "mac", pxe.NormalizeMAC(mac), "image", img.ID)
+			writeIPXE(w, pxe.BootScript(base, img, boot.KernelArgs, host))
+			return
+		}
+		s.logger.Warn("boot image of host not found, serving menu", "mac", pxe.NormalizeMAC(mac), "image", ref)
+	}
+
+	defaultID := ""
+	if img, ok := pxe.FindImage(images, boot.Default); ok {
+		defaultID = img.ID
+	}
+	writeIPXE(w, pxe.MenuScript(base, images, defaultID, bootMenuTimeout))
+}
+
+// handleBootScript serves the iPXE script of one image. ?mac= adds the
+// matching host's ignition and kernel arguments.
+func (s *Server) handleBootScript(w http.ResponseWriter, r *http.Request) {
+	images, _, err := s.bootImages()
+	if err != nil {
+		s.logger.Error("failed to list boot images", "error", err)
+		http.Error(w, "failed to list boot images", http.StatusInternalServerError)
+		return
+	}
+	img, ok := pxe.FindImage(images, r.PathValue("id"))
+	if !ok {
+		http.Error(w, fmt.Sprintf("boot image %s not found", r.PathValue("id")), http.StatusNotFound)
+		return
+	}
+	host := pxe.FindHost(s.config.Boot.Hosts, r.URL.Query().Get("mac"))
+	writeIPXE(w, pxe.BootScript(s.bootBaseURL(r), img, s.config.Boot.KernelArgs, host))
+}
+
+// handleBootFile serves the kernel, initramfs and rootfs of discovered boot
+// images. Other provider files are not exposed.
+func (s *Server) handleBootFile(w http.ResponseWriter, r *http.Request) {
+	providerName := r.PathValue("provider")
+	rel := r.PathValue("path")
+
+	images, roots, err := s.bootImages()
+	if err != nil {
+		s.logger.Error("failed to list boot images", "error", err)
+		http.Error(w, "failed to list boot images", http.StatusInternalServerError)
+		return
+	}
+	found := false
+	for _, img := range images {
+		if img.Provider != providerName {
+			continue
+		}
+		for _, a := range img.Artifacts() {
+			if a == rel {
+				found = true
+			}
+		}
+	}
+	root, ok := roots[providerName]
+	if !found || !ok {
+		http.NotFound(w, r)
+		return
+	}
+	localPath, err := safety.SafeJoinUnder(root, rel)
+	if err != nil {
+		http.NotFound(w, r)
+		return
+	}
+	s.logger.Debug("serving boot file", "provider", providerName, "path", rel, "remote", r.RemoteAddr)
+	http.ServeFile(w, r, localPath)
+}
+
+// handleAPIBootImages lists the bootable RHCOS images as JSON.
+func (s *Server) handleAPIBootImages(w http.ResponseWriter, r *http.Request) {
+	images, _, err := s.bootImages()
+	if err != nil {
+		s.logger.Error("failed to list boot images", "error", err)
+		jsonError(w, http.StatusInternalServerError, "failed to list boot images")
+		return
+	}
+	defaultID := ""
+	if img, ok := pxe.FindImage(images, s.config.Boot.Default); ok {
+		defaultID = img.ID
+	} else if len(images) > 0 {
+		defaultID = images[0].ID
+	}
+	w.Header().Set("Content-Type", "application/json")
+	s.writeJSON(w, map[string]interface{}{
+		"images":   images,
+		"default":  defaultID,
+		"menu_url": s.bootBaseURL(r) + pxe.MenuPath,
+	})
+}
+
+// bootImages finds complete RHCOS live images in the file records of rhcos
+// providers and returns them with each provider's local content root.
+func (s *Server) bootImages() ([]pxe.Image, map[string]string, error) {
+	configs, err := s.store.ListProviderConfigs()
+	if err != nil {
+		return nil, nil, fmt.Errorf("listing provider configs: %w", err)
+	}
+
+	var images []pxe.Image
+	roots := make(map[string]string)
+	for _, pc := range configs {
+		if pc.Type != "rhcos" {
+			continue
+		}
+		root, ok := s.rhcosRoot(pc.Name, pc.ConfigJSON)
+		if !ok {
+			continue
+		}
+		records, err := s.store.ListFileRecords(pc.Name)
+		if err != nil {
+			return nil, nil, fmt.Errorf("listing files for %s: %w", pc.Name, err)
+		}
+		paths := make([]string, 0, len(records))
+		for _, rec := range records {
+			paths = append(paths, rec.Path)
+		}
+		found := pxe.FindImages(pc.Name, paths)
+		if len(found) > 0 {
+			roots[pc.Name] = root
+			images = append(images, found...)
+		}
+	}
+	return images, roots, nil
+}
+
+// rhcosRoot returns the content directory of an rhcos provider: output_dir
+// for synced files, or the provider name for imported ones.
+func (s *Server) rhcosRoot(name, configJSON string) (string, bool) {
+	candidates := []string{}
+	var raw map[string]interface{}
+	if err := json.Unmarshal([]byte(configJSON), &raw); err == nil {
+		if cfg, err := config.ParseProviderConfig[config.RHCOSProviderConfig](raw); err == nil && cfg.OutputDir != "" {
+			candidates = append(candidates, cfg.OutputDir)
+		}
+	}
+	candidates = append(candidates, name)
+	for _, c := range candidates {
+		root, err := safety.SafeJoinUnder(s.config.Server.DataDir, path.Clean(c))
+		if err != nil {
+			continue
+		}
+		if info, err := os.Stat(root); err == nil && info.IsDir() {
+			return root, true
+		}
+	}
+	return "", false
+}
+
+// bootBaseURL is the airgap URL written into boot scripts: boot.base_url, or
+// the scheme and host the node used for this request.
+func (s *Server) bootBaseURL(r *http.Request) string {
+	if s.config.Boot.BaseURL != "" {
+		return strings.TrimRight(s.config.Boot.BaseURL, "/")
+	}
+	scheme := "http"
+	if r.TLS != nil {
+		scheme = "https"
+	}
+	return scheme + "://" + r.Host
+}
+
+func writeIPXE(w http.ResponseWriter, script string) {
+	w.Header().Set("Content-Type", "text/plain; charset=utf-8")
+	_, _ = w.Write([]byte(script))
+}
@@ -0,0 +1,110 @@
+package server
+
+import (
+	"net/http"
+	"net/http/httptest"
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+
+	"github.com/BadgerOps/airgap/internal/config"
+	"github.com/BadgerOps/airgap/internal/store"
+)
+
+func setupBootServer(t *testing.T) (*Server, http.Handler) {
+	t.Helper()
+	srv := setupTestServer(t)
+	srv.config.Boot = config.BootConfig{
+		Enabled:    true,
+		KernelArgs: "console=ttyS0",
+		Hosts: []config.BootHost{
+			{MAC: "52:54:00:aa:bb:cc", Image: "4.16", InstallDev: "/dev/vda", IgnitionURL: "http://10.0.0.5/worker.ign"},
+		},
+	}
+
+	if err := srv.store.CreateProviderConfig(&store.ProviderConfig{
+		Name: "rhcos", Type: "rhcos", Enabled: true,
+		ConfigJSON: `{"output_dir":"rhcos-out"}`,
+	}); err != nil {
+		t.Fatal(err)
+	}
+	for _, name := range []string{"rhcos-live-kernel-x86_64", "rhcos-live-initramfs.x86_64.img", "rhcos-live-rootfs.x86_64.img", "sha256sum.txt"} {
+		rel := "4.16/" + name
+		localPath := filepath.Join(srv.config.Server.DataDir, "rhcos-out", "4.16", name)
+		if err := os.MkdirAll(filepath.Dir(localPath), 0o755); err != nil {
+			t.Fatal(err)
+		}
+		if err := os.WriteFile(localPath, []byte(name), 0o644); err != nil {
+			t.Fatal(err)
+		}
+		if err := srv.store.UpsertFileRecord(&store.FileRecord{Provider: "rhcos", Path: rel}); err != nil {
+			t.Fatal(err)
+		}
+	}
+	return srv, srv.setupRoutes()
+}
+
+func bootGet(t *testing.T, h http.Handler, target string) *httptest.ResponseRecorder {
+	t.Helper()
+	req := httptest.NewRequest(http.MethodGet, target, nil)
+	req.Host = "airgap.local:8080"
+	w := httptest.NewRecorder()
+	h.ServeHTTP(w, req)
+	return w
+}
+
+func TestBootMenuAndScripts(t *testing.T) {
+	_, h := setupBootServer(t)
+
+	w := bootGet(t, h, "/boot/ipxe")
+	if w.Code != http.StatusOK {
+		t.Fatalf("menu: expected 200, got %d: %s", w.Code, w.Body.String())
+	}
+	if !strings.Contains(w.Body.String(), "chain http://airgap.local:8080/boot/ipxe/rhcos/4.16?mac=${net0/mac}") {
+		t.Errorf("unexpected menu:\n%s", w.Body.String())
+	}
+
+	// A configured host boots its image directly with its ignition.
+	w = bootGet(t, h, "/boot/ipxe?mac=52-54-00-AA-BB-CC")
+	body := w.Body.String()
+	for _, want := range []string{
+		"coreos.live.rootfs_url=http://airgap.local:8080/boot/files/rhcos/4.16/rhcos-live-rootfs.x86_64.img",
+		"coreos.inst.install_dev=/dev/vda coreos.inst.ignition_url=http://10.0.0.5/worker.ign console=ttyS0",
+	} {
+		if !strings.Contains(body, want) {
+			t.Errorf("host script missing %q:\n%s", want, body)
+		}
+	}
+
+	w = bootGet(t, h, "/boot/ipxe/rhcos/4.16")
+	if w.Code != http.StatusOK || strings.Contains(w.Body.String(), "coreos.inst") {
+		t.Errorf("unexpected image script (%d):\n%s", w.Code, w.Body.String())
+	}
+	if w := bootGet(t, h, "/boot/ipxe/rhcos/4.99"); w.Code != http.StatusNotFound {
+		t.Errorf("unknown image: expected 404, got %d", w.Code)
+	}
+}
+
+func TestBootFiles(t *testing.T) {
+	_, h := setupBootServer(t)
+
+	w := bootGet(t, h, "/boot/files/rhcos/4.16/rhcos-live-kernel-x86_64")
+	if w.Code != http.StatusOK || w.Body.String() != "rhcos-live-kernel-x86_64" {
+		t.Errorf("kernel: got %d %q", w.Code, w.Body.String())
+	}
+	// Only boot artifacts are exposed.
+	if w := bootGet(t, h, "/boot/files/rhcos/4.16/sha256sum.txt"); w.Code != http.StatusNotFound {
+		t.Errorf("non-boot file: expected 404, got %d", w.Code)
+	}
+	if w := bootGet(t, h, "/boot/files/other/4.16/rhcos-live-kernel-x86_64"); w.Code != http.StatusNotFound {
+		t.Errorf("unknown provider: expected 404, got %d", w.Code)
+	}
+}
+
+func TestBootRoutesDisabled(t *testing.T) {
+	srv := setupTestServer(t)
+	if w := bootGet(t, srv.setupRoutes(), "/boot/ipxe"); w.Code != http.StatusNotFound {
+		t.Errorf("expected boot routes to be off by default, got %d", w.Code)
+	}
+}
@@ -0,0 +1,162 @@
+package server
+
+import (
+	"encoding/json"
+	"errors"
+	"fmt"
+	"net/http"
+	"os"
+	"path"
+	"strings"
+
+	"github.com/BadgerOps/airgap/internal/config"
+	"github.com/BadgerOps/airgap/internal/ocp"
+	"github.com/BadgerOps/airgap/internal/provider/containerimages"
+	"github.com/BadgerOps/airgap/internal/safety"
+)
+
+// handleCincinnatiGraph serves a Cincinnati-compatible update graph built from
+// the graphs captured by ocp_clients providers (update_graph: true), limited to
+// releases that were mirrored. Clusters use it via spec.upstream on the
+// ClusterVersion resource.
+func (s *Server) handleCincinnatiGraph(w http.ResponseWriter, r *http.Request) {
+	channel := r.URL.Query().Get("channel")
+	if channel == "" {
+		jsonError(w, http.StatusBadRequest, "channel query parameter is required")
+		return
+	}
+	arch := r.URL.Query().Get("arch")
+	if arch == "" {
+		arch = "amd64"
+	}
+	if strings.ContainsAny(channel, `/\`) || strings.ContainsAny(arch, `/\`) {
+		jsonError(w, http.StatusBadRequest, "invalid channel or arch")
+		return
+	}
+
+	graph, err := s.loadCapturedGraph(channel, arch)
+	if err != nil {
+		if errors.Is(err, os.ErrNotExist) {
+			jsonError(w, http.StatusNotFound, fmt.Sprintf("no update graph captured for channel %s arch %s", channel, arch))
+			return
+		}
+		s.logger.Error("failed to load update graph", "channel", channel, "arch", arch, "error", err)
+		jsonError(w, http.StatusInternalServerError, "failed to load update graph")
+		return
+	}
+
+	mirrored, err := s.mirroredReleaseVersions()
+	if err != nil {
+		s.logger.Error("failed to collect mirrored releases", "error", err)
+		jsonError(w, http.StatusInternalServerError, "failed to collect mirrored releases")
+		return
+	}
+
+	filtered := ocp.FilterGraph(graph, func(version string) bool {
+		return mirrored[version]
+	})
+	w.Header().Set("Content-Type", "application/json")
+	s.writeJSON(w, filtered)
+}
+
+// loadCapturedGraph returns the stored graph for channel/arch from the first
+// ocp_clients provider that recorded one. Missing graphs wrap os.ErrNotExist.
+func (s *Server) loadCapturedGraph(channel, arch string) (*ocp.Graph, error) {
+	configs, err := s.store.ListProviderConfigs()
+	if err != nil {
+		return nil, fmt.Errorf("listing provider configs: %w", err)
+	}
+
+	relPath := path.Join("graph", arch, channel+".json")
+	for _, pc := range configs {
+		if pc.Type != "ocp_clients" {
+			continue
+		}
+		// Imported files live under the provider name; locally synced ones
+		// under output_dir. Both are usually the same directory.
+		roots := []string{pc.Name}
+		var raw map[string]interface{}
+		if err := json.Unmarshal([]byte(pc.ConfigJSON), &raw); err == nil {
+			if cfg, err := config.ParseProviderConfig[config.OCPClientsProviderConfig](raw); err == nil && cfg.OutputDir != "" && cfg.OutputDir != pc.Name {
+				roots = append(roots, cfg.OutputDir)
+			}
+		}
+		for _, root := range roots {
+			graphPath, err := safety.SafeJoinUnder(s.config.Server.DataDir, path.Join(root, relPath))
+			if err != nil {
+				continue
+			}
+			data, err := os.ReadFile(graphPath)
+			if errors.Is(err, os.ErrNotExist) {
+				continue
+			}
+			if err != nil {
+				return nil, fmt.Errorf("reading %s: %w", graphPath, err)
+			}
+			graph, err := ocp.ParseGraph(data)
+			if err != nil {
+				return nil, fmt.Errorf("parsing %s: %w", graphPath, err)
+			}
+			return graph, nil
+		}
+	}
+	return nil, fmt.Errorf("graph %s: %w", relPath, os.ErrNotExist)
+}
+
+// mirroredReleaseVersions returns the OCP versions whose release payload is
+// mirrored: ocp-release images listed by container_images providers with at
+// least one manifest recorded. Client binaries alone do not make a release
+// installable, so ocp_clients downloads are not counted.
+func (s *Server) mirroredReleaseVersions() (map[string]bool, error) {
+	configs, err := s.store.ListProviderConfigs()
+	if err != nil {
+		return nil, fmt.Errorf("listing provider configs: %w", err)
+	}
+
+	versions := make(map[string]bool)
+	for _, pc := range configs {
+		if pc.Type != "container_images" {
+			continue
+		}
+		var raw map[string]interface{}
+		if err := json.Unmarshal([]byte(pc.ConfigJSON), &raw); err != nil {
+			s.logger.Warn("skipping unreadable provider config", "provider", pc.Name, "error", err)
+			continue
+		}
+		cfg, err := config.ParseProviderConfig[config.ContainerImagesProviderConfig](raw)
+		if err != nil {
+			s.logger.Warn("skipping unreadable provider config", "provider", pc.Name, "error", err)
+			continue
+		}
+
+		// Manifest directories of release images, by image directory.
+		releases := make(map[string]string)
+		for _, raw := range cfg.Images {
+			v, ok := ocp.ReleaseVersionFromImage(strings.TrimSpace(raw))
+			if !ok {
+				continue
+			}
+			ref, err := containerimages.ParseReference(raw)
+			if err != nil {
+				continue
+			}
+			releases["/"+containerimages.LocalImageID(ref)+"/manifests/"] = v
+		}
+		if len(releases) == 0 {
+			continue
+		}
+
+		records, err := s.store.ListFileRecords(pc.Name)
+		if err != nil {
+			return nil, fmt.Errorf("listing files for %s: %w", pc.Name, err)
+		}
+		for _, rec := range records {
+			for dir, v := range releases {
+				if strings.Contains("/"+rec.Path, dir) {
+					versions[v] = true
+				}
+			}
+		}
+	}
+	return versions, nil
+}
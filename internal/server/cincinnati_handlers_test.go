@@ -0,0 +1,106 @@
+package server
+
+import (
+	"encoding/json"
+	"net/http"
+	"net/http/httptest"
+	"os"
+	"path/filepath"
+	"testing"
+
+	"github.com/BadgerOps/airgap/internal/ocp"
+	"github.com/BadgerOps/airgap/internal/store"
+)
+
+const capturedGraph = `{
+  "version": 1,
+  "nodes": [
+    {"version": "4.16.1", "payload": "quay.io/openshift-release-dev/ocp-release@sha256:aaa"},
+    {"version": "4.16.2", "payload": "quay.io/openshift-release-dev/ocp-release@sha256:bbb"},
+    {"version": "4.16.3", "payload": "quay.io/openshift-release-dev/ocp-release@sha256:ccc"}
+  ],
+  "edges": [[0, 1], [1, 2], [0, 2]]
+}`
+
+func TestHandleCincinnatiGraph(t *testing.T) {
+	srv := setupTestServer(t)
+
+	if err := srv.store.CreateProviderConfig(&store.ProviderConfig{
+		Name: "ocp-clients", Type: "ocp_clients", Enabled: true,
+		ConfigJSON: `{"channels":["stable-4.16"],"update_graph":true}`,
+	}); err != nil {
+		t.Fatal(err)
+	}
+	if err := srv.store.CreateProviderConfig(&store.ProviderConfig{
+		Name: "images", Type: "container_images", Enabled: true,
+		ConfigJSON: `{"images":[
+			"quay.io/openshift-release-dev/ocp-release:4.16.1-x86_64",
+			"quay.io/openshift-release-dev/ocp-release:4.16.2-x86_64",
+			"docker://quay.io/openshift-release-dev/ocp-release:4.16.3-x86_64"]}`,
+	}); err != nil {
+		t.Fatal(err)
+	}
+	// Client binaries and the graph directory do not make a release
+	// available; only mirrored release payloads (4.16.1 and 4.16.3) do.
+	records := []store.FileRecord{
+		{Provider: "ocp-clients", Path: "4.16.2/openshift-client-linux.tar.gz"},
+		{Provider: "ocp-clients", Path: "graph/amd64/stable-4.16.json"},
+		{Provider: "images", Path: "images/quay.io_openshift-release-dev_ocp-release_4.16.1-x86_64/manifests/sha256/aaa.json"},
+		{Provider: "images", Path: "images/quay.io_openshift-release-dev_ocp-release_4.16.3-x86_64/manifests/sha256/ccc.json"},
+	}
+	for i := range records {
+		if err := srv.store.UpsertFileRecord(&records[i]); err != nil {
+			t.Fatal(err)
+		}
+	}
+
+	graphPath := filepath.Join(srv.config.Server.DataDir, "ocp-clients", "graph", "amd64", "stable-4.16.json")
+	if err := os.MkdirAll(filepath.Dir(graphPath), 0o755); err != nil {
+		t.Fatal(err)
+	}
+	if err := os.WriteFile(graphPath, []byte(capturedGraph), 0o644); err != nil {
+		t.Fatal(err)
+	}
+
+	req := httptest.NewRequest(http.MethodGet, "/api/upgrades_info/v1/graph?channel=stable-4.16", nil)
+	w := httptest.NewRecorder()
+	srv.handleCincinnatiGraph(w, req)
+
+	if w.Code != http.StatusOK {
+		t.Fatalf("expected 200, got %d: %s", w.Code, w.Body.String())
+	}
+	if ct := w.Header().Get("Content-Type"); ct != "application/json" {
+		t.Errorf("expected application/json, got %q", ct)
+	}
+	var graph ocp.Graph
+	if err := json.NewDecoder(w.Body).Decode(&graph); err != nil {
+		t.Fatalf("failed to decode graph: %v", err)
+	}
+	if len(graph.Nodes) != 2 || graph.Nodes[0].Version != "4.16.1" || graph.Nodes[1].Version != "4.16.3" {
+		t.Fatalf("unexpected nodes: %+v", graph.Nodes)
+	}
+	if len(graph.Edges) != 1 || graph.Edges[0] != [2]int{0, 1} {
+		t.Fatalf("unexpected edges: %v", graph.Edges)
+	}
+}
+
+func TestHandleCincinnatiGraphErrors(t *testing.T) {
+	srv := setupTestServer(t)
+
+	tests := []struct {
+		query string
+		code  int
+	}{
+		{"", http.StatusBadRequest},
+		{"?channel=../etc", http.StatusBadRequest},
+		{"?channel=stable-4.16", http.StatusNotFound},
+	}
+	for _, tt := range tests {
+		req := httptest.NewRequest(http.MethodGet, "/api/upgrades_info/v1/graph"+tt.query, nil)
+		w := httptest.NewRecorder()
+		srv.handleCincinnatiGraph(w, req)
+		if w.Code != tt.code {
+			t.Errorf("query %q: expected %d, got %d", tt.query, tt.code, w.Code)
+		}
+	}
+}
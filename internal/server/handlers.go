@@ -76,6 +76,7 @@ func (s *Server) handleProviderDetail(w http.ResponseWriter, r *http.Request) {
 	var providerType string
 	var canPushToRegistry bool
 	var registryTargets []string
+	mirrorConfigs := make(map[string][]string)
 
 	// Check registry first, then fall back to store (covers disabled/unsupported providers)
 	_, inRegistry := s.registry.Get(providerName)
@@ -87,7 +88,7 @@ func (s *Server) handleProviderDetail(w http.ResponseWriter, r *http.Request) {
 		}
 		if err == nil {
 			providerType = pc.Type
-			canPushToRegistry = pc.Type == "container_images"
+			canPushToRegistry = pc.Type == "container_images" || pc.Type == "operator_catalog"
 		}
 
 		configs, err := s.store.ListProviderConfigs()
@@ -97,6 +98,9 @@ func (s *Server) handleProviderDetail(w http.ResponseWriter, r *http.Request) {
 			for _, cfg := range configs {
 				if cfg.Type == "registry" {
 					registryTargets = append(registryTargets, cfg.Name)
+					if files, err := s.mirrorConfigFiles(providerName, cfg.Name); err == nil && len(files) > 0 {
+						mirrorConfigs[cfg.Name] = files
+					}
 				}
 			}
 		}
@@ -126,6 +130,7 @@ func (s *Server) handleProviderDetail(w http.ResponseWriter, r *http.Request) {
 		"SyncRunning":       syncRunning,
 		"CanPushToRegistry": canPushToRegistry,
 		"RegistryTargets":   registryTargets,
+		"MirrorConfigs":     mirrorConfigs,
 		"SyncRuns":          syncRuns,
 	}
 
@@ -305,6 +310,7 @@ func (s *Server) handleAPISync(w http.ResponseWriter, r *http.Request) {
 	s.syncMu.Lock()
 	if s.syncRunning {
 		s.syncMu.Unlock()
+		s.recordAudit(r, "sync.start", req.Provider, "", errOperationRunning)
 		if htmx {
 			writeSyncFragment(w, false, "A sync is already running")
 		} else {
@@ -319,6 +325,7 @@ func (s *Server) handleAPISync(w http.ResponseWriter, r *http.Request) {
 	if req.Provider != "all" {
 		if _, ok := s.registry.Get(req.Provider); !ok {
 			s.syncMu.Unlock()
+			s.recordAudit(r, "sync.start", req.Provider, "", fmt.Errorf("provider not found"))
 			if htmx {
 				writeSyncFragment(w, false, "Provider not found: "+req.Provider)
 			} else {
@@ -341,6 +348,10 @@ func (s *Server) handleAPISync(w http.ResponseWriter, r *http.Request) {
 	s.syncCancel = cancel
 	s.syncRunning = true
 	s.syncMu.Unlock()
+	// Dry runs change nothing, so they are not audited.
+	if !opts.DryRun {
+		s.recordAudit(r, "sync.start", req.Provider, fmt.Sprintf("force=%t", opts.Force), nil)
+	}
 
 	// Launch sync in background goroutine
 	go func() {
@@ -538,7 +549,9 @@ func (s *Server) handleAPISyncFailureResolve(w http.ResponseWriter, r *http.Requ
 		return
 	}
 
-	if err := s.store.ResolveFailedFile(id); err != nil {
+	err = s.store.ResolveFailedFile(id)
+	s.recordAudit(r, "failure.resolve", idStr, "", err)
+	if err != nil {
 		w.Header().Set("Content-Type", "application/json")
 		if strings.Contains(err.Error(), "not found") {
 			w.WriteHeader(http.StatusNotFound)
@@ -616,6 +629,7 @@ func (s *Server) handleAPISyncFailuresResolve(w http.ResponseWriter, r *http.Req
 	resolved := 0
 	for id := range targetIDs {
 		if err := s.store.ResolveFailedFile(id); err != nil {
+			s.recordAudit(r, "failure.resolve", req.Provider, "", err)
 			w.Header().Set("Content-Type", "application/json")
 			w.WriteHeader(http.StatusInternalServerError)
 			s.writeJSON(w, map[string]string{"error": err.Error()})
@@ -624,6 +638,8 @@ func (s *Server) handleAPISyncFailuresResolve(w http.ResponseWriter, r *http.Req
 		resolved++
 	}
 
+	s.recordAudit(r, "failure.resolve", req.Provider, fmt.Sprintf("resolved %d failed files", resolved), nil)
+
 	remaining := len(records) - resolved
 	if remaining < 0 {
 		remaining = 0
@@ -662,6 +678,7 @@ func (s *Server) handleAPISyncRetry(w http.ResponseWriter, r *http.Request) {
 	s.syncMu.Lock()
 	if s.syncRunning {
 		s.syncMu.Unlock()
+		s.recordAudit(r, "failure.retry", req.Provider, "", errOperationRunning)
 		w.Header().Set("Content-Type", "application/json")
 		w.WriteHeader(http.StatusConflict)
 		s.writeJSON(w, map[string]string{"error": "a sync is already running"})
@@ -689,6 +706,7 @@ func (s *Server) handleAPISyncRetry(w http.ResponseWriter, r *http.Request) {
 	s.syncCancel = cancel
 	s.syncRunning = true
 	s.syncMu.Unlock()
+	s.recordAudit(r, "failure.retry", req.Provider, fmt.Sprintf("retrying %d failed files", len(records)), nil)
 
 	// Launch retry in background
 	go func() {
@@ -821,6 +839,7 @@ func (s *Server) handleAPIScan(w http.ResponseWriter, r *http.Request) {
 	s.syncMu.Lock()
 	if s.syncRunning {
 		s.syncMu.Unlock()
+		s.recordAudit(r, "scan.start", req.Provider, "", errOperationRunning)
 		w.Header().Set("Content-Type", "application/json")
 		w.WriteHeader(http.StatusConflict)
 		s.writeJSON(w, map[string]string{"error": "a sync or scan is already running"})
@@ -831,6 +850,7 @@ func (s *Server) handleAPIScan(w http.ResponseWriter, r *http.Request) {
 	s.syncCancel = cancel
 	s.syncRunning = true
 	s.syncMu.Unlock()
+	s.recordAudit(r, "scan.start", req.Provider, "", nil)
 
 	// Launch scan in background goroutine
 	go func() {
@@ -974,7 +994,9 @@ func (s *Server) runValidation(ctx context.Context, providerName string) {
 	if len(report.InvalidFiles) > 0 && s.store != nil {
 		for _, inv := range report.InvalidFiles {
 			reason := "validation: checksum mismatch"
-			if inv.Actual == "missing" {
+			if inv.Reason != "" {
+				reason = "validation: " + inv.Reason
+			} else if inv.Actual == "missing" {
 				reason = "validation: file missing"
 			}
 			rec := &store.FailedFileRecord{
@@ -1024,6 +1046,7 @@ func (s *Server) handleAPISyncCancel(w http.ResponseWriter, r *http.Request) {
 
 	cancel()
 	s.logger.Info("sync cancelled by user")
+	s.recordAudit(r, "sync.cancel", "", "", nil)
 
 	if isHTMX(r) {
 		writeSyncFragment(w, true, "Sync cancelled")
@@ -0,0 +1,74 @@
+package server
+
+import (
+	"encoding/json"
+	"net/http"
+
+	"github.com/BadgerOps/airgap/internal/audit"
+	"github.com/BadgerOps/airgap/internal/imageset"
+	"github.com/BadgerOps/airgap/internal/store"
+)
+
+type imagesetRequest struct {
+	YAML   string `json:"yaml"`
+	Prefix string `json:"prefix"`
+	DryRun bool   `json:"dry_run"`
+}
+
+type imagesetResponse struct {
+	*imageset.Translation
+	Created []string `json:"created"`
+}
+
+// handleImportImageset translates an oc-mirror ImageSetConfiguration into
+// provider configs. With dry_run it only reports the translation.
+func (s *Server) handleImportImageset(w http.ResponseWriter, r *http.Request) {
+	var req imagesetRequest
+	if err := json.NewDecoder(r.Body).Decode(&req); err != nil {
+		jsonError(w, http.StatusBadRequest, "invalid JSON: "+err.Error())
+		return
+	}
+
+	isc, err := imageset.Parse([]byte(req.YAML))
+	if err != nil {
+		jsonError(w, http.StatusBadRequest, err.Error())
+		return
+	}
+	resp := imagesetResponse{Translation: imageset.Translate(isc, req.Prefix), Created: []string{}}
+
+	if !req.DryRun {
+		for _, p := range resp.Providers {
+			if _, err := s.store.GetProviderConfig(p.Name); err == nil {
+				jsonError(w, http.StatusConflict, "provider with name '"+p.Name+"' already exists")
+				return
+			}
+		}
+		for _, p := range resp.Providers {
+			configBytes, _ := json.Marshal(p.Config)
+			pc := &store.ProviderConfig{
+				Name:       p.Name,
+				Type:       p.Type,
+				Enabled:    true,
+				ConfigJSON: string(configBytes),
+			}
+			err := s.store.CreateProviderConfig(pc)
+			ev := s.auditEvent(r, "provider.create", p.Name)
+			ev.Outcome, ev.Detail = audit.Outcome(err)
+			if err != nil {
+				s.audit.Record(ev)
+				jsonError(w, http.StatusInternalServerError, err.Error())
+				return
+			}
+			ev.After, ev.Detail = audit.ProviderState(pc), "imported from ImageSetConfiguration"
+			s.audit.Record(ev)
+			s.recordRevision(r, p.Name, "imported from ImageSetConfiguration")
+			resp.Created = append(resp.Created, p.Name)
+		}
+		if len(resp.Created) > 0 {
+			s.reloadProviders()
+		}
+	}
+
+	w.Header().Set("Content-Type", "application/json")
+	s.writeJSON(w, resp)
+}
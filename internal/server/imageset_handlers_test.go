@@ -0,0 +1,86 @@
+package server
+
+import (
+	"bytes"
+	"encoding/json"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+)
+
+const testImageset = `kind: ImageSetConfiguration
+apiVersion: mirror.openshift.io/v2alpha1
+mirror:
+  additionalImages:
+  - name: registry.access.redhat.com/ubi9/ubi:latest
+  helm:
+    local:
+    - name: chart
+      path: ./chart.tgz
+`
+
+func postImageset(t *testing.T, srv *Server, dryRun bool) *httptest.ResponseRecorder {
+	t.Helper()
+	body, _ := json.Marshal(imagesetRequest{YAML: testImageset, Prefix: "team", DryRun: dryRun})
+	req := httptest.NewRequest(http.MethodPost, "/api/providers/imageset", bytes.NewReader(body))
+	req.Header.Set("Content-Type", "application/json")
+	w := httptest.NewRecorder()
+	srv.handleImportImageset(w, req)
+	return w
+}
+
+func TestHandleImportImagesetDryRun(t *testing.T) {
+	srv := setupTestServer(t)
+
+	w := postImageset(t, srv, true)
+	if w.Code != http.StatusOK {
+		t.Fatalf("expected 200, got %d: %s", w.Code, w.Body.String())
+	}
+	var resp struct {
+		Providers []struct{ Name, Type string } `json:"providers"`
+		Entries   []struct{ Status string }     `json:"entries"`
+		Created   []string                      `json:"created"`
+	}
+	if err := json.NewDecoder(w.Body).Decode(&resp); err != nil {
+		t.Fatal(err)
+	}
+	if len(resp.Providers) != 1 || resp.Providers[0].Name != "team-images" || resp.Providers[0].Type != "container_images" {
+		t.Fatalf("unexpected providers: %+v", resp.Providers)
+	}
+	if len(resp.Entries) != 2 || len(resp.Created) != 0 {
+		t.Fatalf("unexpected response: %+v", resp)
+	}
+	if configs, _ := srv.store.ListProviderConfigs(); len(configs) != 0 {
+		t.Fatalf("dry run created %d providers", len(configs))
+	}
+}
+
+func TestHandleImportImagesetCreates(t *testing.T) {
+	srv := setupTestServer(t)
+
+	if w := postImageset(t, srv, false); w.Code != http.StatusOK {
+		t.Fatalf("expected 200, got %d: %s", w.Code, w.Body.String())
+	}
+	pc, err := srv.store.GetProviderConfig("team-images")
+	if err != nil {
+		t.Fatalf("provider not created: %v", err)
+	}
+	if pc.Type != "container_images" || !pc.Enabled {
+		t.Errorf("unexpected provider config: %+v", pc)
+	}
+
+	if w := postImageset(t, srv, false); w.Code != http.StatusConflict {
+		t.Fatalf("expected 409 on re-import, got %d", w.Code)
+	}
+}
+
+func TestHandleImportImagesetInvalid(t *testing.T) {
+	srv := setupTestServer(t)
+
+	req := httptest.NewRequest(http.MethodPost, "/api/providers/imageset", bytes.NewBufferString(`{"yaml":"kind: Other"}`))
+	w := httptest.NewRecorder()
+	srv.handleImportImageset(w, req)
+	if w.Code != http.StatusBadRequest {
+		t.Fatalf("expected 400, got %d", w.Code)
+	}
+}
@@ -263,5 +263,17 @@ func (s *Server) handleAPIOCPDownload(w http.ResponseWriter, r *http.Request) {
 
 	wg.Wait()
 
+	var failed int
+	for _, st := range statuses {
+		if st.Status == "error" {
+			failed++
+		}
+	}
+	var downloadErr error
+	if failed > 0 {
+		downloadErr = fmt.Errorf("%d of %d artifacts failed", failed, len(statuses))
+	}
+	s.recordAudit(r, "ocp.download", req.Version, fmt.Sprintf("%d artifacts", len(statuses)), downloadErr)
+
 	sendEvent("done", statuses)
 }
@@ -0,0 +1,32 @@
+package server
+
+import (
+	"errors"
+	"net/http"
+
+	"github.com/BadgerOps/airgap/internal/engine"
+)
+
+// handleAPIProvenance returns the provenance of the file records matching the
+// path query parameter.
+func (s *Server) handleAPIProvenance(w http.ResponseWriter, r *http.Request) {
+	path := r.URL.Query().Get("path")
+	if path == "" {
+		jsonError(w, http.StatusBadRequest, "path query parameter is required")
+		return
+	}
+
+	records, err := s.engine.Provenance(path)
+	if err != nil {
+		if errors.Is(err, engine.ErrNoFileRecord) {
+			jsonError(w, http.StatusNotFound, "no file record for "+path)
+			return
+		}
+		s.logger.Error("failed to look up provenance", "path", path, "error", err)
+		jsonError(w, http.StatusBadRequest, err.Error())
+		return
+	}
+
+	w.Header().Set("Content-Type", "application/json")
+	s.writeJSON(w, records)
+}
@@ -0,0 +1,62 @@
+package server
+
+import (
+	"encoding/json"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+	"time"
+
+	"github.com/BadgerOps/airgap/internal/engine"
+	"github.com/BadgerOps/airgap/internal/store"
+)
+
+func TestHandleAPIProvenance(t *testing.T) {
+	srv := setupTestServer(t)
+
+	run := &store.SyncRun{Provider: "epel", StartTime: time.Now(), Status: "success", SignatureStatus: "verified"}
+	if err := srv.store.CreateSyncRun(run); err != nil {
+		t.Fatal(err)
+	}
+	if err := srv.store.UpsertFileRecord(&store.FileRecord{
+		Provider: "epel", Path: "Packages/z/zsh.rpm", Size: 10, SHA256: "abc",
+		LastModified: time.Now(), LastVerified: time.Now(), SyncRunID: run.ID,
+		SourceURL: "https://dl.fedoraproject.org/pub/epel/9/Packages/z/zsh.rpm", MirrorHost: "mirror.example.com",
+		ETag: `"e1"`, ResponseTimeMS: 42,
+	}); err != nil {
+		t.Fatal(err)
+	}
+
+	req := httptest.NewRequest(http.MethodGet, "/api/provenance?path=epel/Packages/z/zsh.rpm", nil)
+	w := httptest.NewRecorder()
+	srv.handleAPIProvenance(w, req)
+
+	if w.Code != http.StatusOK {
+		t.Fatalf("expected 200, got %d: %s", w.Code, w.Body.String())
+	}
+	var got []engine.FileProvenance
+	if err := json.Unmarshal(w.Body.Bytes(), &got); err != nil {
+		t.Fatal(err)
+	}
+	if len(got) != 1 {
+		t.Fatalf("expected 1 record, got %d", len(got))
+	}
+	if got[0].MirrorHost != "mirror.example.com" || got[0].ETag != `"e1"` || got[0].ResponseTimeMS != 42 {
+		t.Errorf("unexpected provenance: %+v", got[0])
+	}
+	if got[0].SyncRunID != run.ID || got[0].SignatureStatus != "verified" {
+		t.Errorf("expected sync run %d with verified signatures, got %+v", run.ID, got[0])
+	}
+
+	for query, code := range map[string]int{
+		"":                       http.StatusBadRequest,
+		"?path=epel/missing.rpm": http.StatusNotFound,
+	} {
+		req := httptest.NewRequest(http.MethodGet, "/api/provenance"+query, nil)
+		w := httptest.NewRecorder()
+		srv.handleAPIProvenance(w, req)
+		if w.Code != code {
+			t.Errorf("%q: expected %d, got %d", query, code, w.Code)
+		}
+	}
+}
@@ -6,13 +6,15 @@ import (
 	"strings"
 	"time"
 
+	"github.com/BadgerOps/airgap/internal/audit"
+	"github.com/BadgerOps/airgap/internal/config"
 	"github.com/BadgerOps/airgap/internal/store"
 )
 
 // Valid provider types
 var validProviderTypes = map[string]bool{
 	"epel": true, "ocp_binaries": true, "ocp_clients": true, "rhcos": true,
-	"container_images": true, "registry": true, "custom_files": true,
+	"container_images": true, "operator_catalog": true, "registry": true, "custom_files": true,
 }
 
 type providerConfigJSON struct {
@@ -29,6 +31,7 @@ type providerConfigRequest struct {
 	Type    string                 `json:"type"`
 	Enabled bool                   `json:"enabled"`
 	Config  map[string]interface{} `json:"config"`
+	Comment string                 `json:"comment"` // recorded with the revision
 }
 
 func (s *Server) handleListProviderConfigs(w http.ResponseWriter, r *http.Request) {
@@ -59,7 +62,12 @@ func (s *Server) handleCreateProviderConfig(w http.ResponseWriter, r *http.Reque
 		return
 	}
 	if !validProviderTypes[req.Type] {
-		jsonError(w, http.StatusBadRequest, "invalid type: must be one of epel, ocp_binaries, ocp_clients, rhcos, container_images, registry, custom_files")
+		jsonError(w, http.StatusBadRequest, "invalid type: must be one of epel, ocp_binaries, ocp_clients, rhcos, container_images, operator_catalog, registry, custom_files")
+		return
+	}
+
+	if errs := config.ValidateProviderConfig(req.Type, req.Config); len(errs) > 0 {
+		jsonFieldErrors(w, errs)
 		return
 	}
 
@@ -72,7 +80,10 @@ func (s *Server) handleCreateProviderConfig(w http.ResponseWriter, r *http.Reque
 		ConfigJSON: string(configBytes),
 	}
 
+	ev := s.auditEvent(r, "provider.create", req.Name)
 	if err := s.store.CreateProviderConfig(pc); err != nil {
+		ev.Outcome, ev.Detail = audit.Outcome(err)
+		s.audit.Record(ev)
 		if strings.Contains(err.Error(), "UNIQUE constraint") {
 			jsonError(w, http.StatusConflict, "provider with name '"+req.Name+"' already exists")
 			return
@@ -81,10 +92,15 @@ func (s *Server) handleCreateProviderConfig(w http.ResponseWriter, r *http.Reque
 		return
 	}
 
+	s.recordRevision(r, req.Name, req.Comment)
+
 	// Hot-reload providers
 	s.reloadProviders()
 
 	got, _ := s.store.GetProviderConfig(req.Name)
+	ev.After, ev.Outcome = audit.ProviderState(got), audit.OutcomeSuccess
+	s.audit.Record(ev)
+
 	w.Header().Set("Content-Type", "application/json")
 	w.WriteHeader(http.StatusCreated)
 	s.writeJSON(w, dbToJSON(*got))
@@ -114,6 +130,25 @@ func (s *Server) handleUpdateProviderConfig(w http.ResponseWriter, r *http.Reque
 		return
 	}
 
+	newType, newConfig := existing.Type, req.Config
+	if req.Type != "" {
+		newType = req.Type
+	}
+	if newConfig == nil {
+		if err := json.Unmarshal([]byte(existing.ConfigJSON), &newConfig); err != nil {
+			jsonError(w, http.StatusInternalServerError,
+				"stored config of "+name+" is not valid JSON ("+err.Error()+"); send a config to replace it")
+			return
+		}
+	}
+	if errs := config.ValidateProviderConfig(newType, newConfig); len(errs) > 0 {
+		jsonFieldErrors(w, errs)
+		return
+	}
+
+	ev := s.auditEvent(r, "provider.update", name)
+	ev.Before = audit.ProviderState(existing)
+
 	if req.Type != "" {
 		existing.Type = req.Type
 	}
@@ -124,13 +159,19 @@ func (s *Server) handleUpdateProviderConfig(w http.ResponseWriter, r *http.Reque
 	}
 
 	if err := s.store.UpdateProviderConfig(existing); err != nil {
+		ev.Outcome, ev.Detail = audit.Outcome(err)
+		s.audit.Record(ev)
 		jsonError(w, http.StatusInternalServerError, err.Error())
 		return
 	}
 
+	s.recordRevision(r, name, req.Comment)
 	s.reloadProviders()
 
 	got, _ := s.store.GetProviderConfig(name)
+	ev.After, ev.Outcome = audit.ProviderState(got), audit.OutcomeSuccess
+	s.audit.Record(ev)
+
 	w.Header().Set("Content-Type", "application/json")
 	s.writeJSON(w, dbToJSON(*got))
 }
@@ -142,10 +183,21 @@ func (s *Server) handleDeleteProviderConfig(w http.ResponseWriter, r *http.Reque
 		return
 	}
 
-	if err := s.store.DeleteProviderConfig(name); err != nil {
+	ev := s.auditEvent(r, "provider.delete", name)
+	existing, err := s.store.GetProviderConfig(name)
+	if err == nil {
+		ev.Before = audit.ProviderState(existing)
+		err = s.store.DeleteProviderConfig(name)
+	}
+	ev.Outcome, ev.Detail = audit.Outcome(err)
+	s.audit.Record(ev)
+	if err != nil {
 		jsonError(w, http.StatusNotFound, "provider not found: "+name)
 		return
 	}
+	if err := s.store.ReplaceSearchEntries(name, nil); err != nil {
+		s.logger.Warn("failed to clear search index", "provider", name, "error", err)
+	}
 
 	s.reloadProviders()
 	w.WriteHeader(http.StatusNoContent)
@@ -158,14 +210,30 @@ func (s *Server) handleToggleProviderConfig(w http.ResponseWriter, r *http.Reque
 		return
 	}
 
-	if err := s.store.ToggleProviderConfig(name); err != nil {
+	ev := s.auditEvent(r, "provider.toggle", name)
+	existing, err := s.store.GetProviderConfig(name)
+	if err == nil {
+		ev.Before = audit.ProviderState(existing)
+		err = s.store.ToggleProviderConfig(name)
+	}
+	if err != nil {
+		ev.Outcome, ev.Detail = audit.Outcome(err)
+		s.audit.Record(ev)
 		jsonError(w, http.StatusNotFound, "provider not found: "+name)
 		return
 	}
 
+	comment := "disabled"
+	if !existing.Enabled {
+		comment = "enabled"
+	}
+	s.recordRevision(r, name, comment)
 	s.reloadProviders()
 
 	got, _ := s.store.GetProviderConfig(name)
+	ev.After, ev.Outcome = audit.ProviderState(got), audit.OutcomeSuccess
+	s.audit.Record(ev)
+
 	w.Header().Set("Content-Type", "application/json")
 	s.writeJSON(w, dbToJSON(*got))
 }
@@ -201,6 +269,35 @@ func dbToJSON(pc store.ProviderConfig) providerConfigJSON {
 	}
 }
 
+// handleProviderSchema returns the JSON Schema of a provider type's config.
+func (s *Server) handleProviderSchema(w http.ResponseWriter, r *http.Request) {
+	schema, ok := config.ProviderSchema(r.PathValue("type"))
+	if !ok {
+		jsonError(w, http.StatusNotFound, "unknown provider type: "+r.PathValue("type"))
+		return
+	}
+	w.Header().Set("Content-Type", "application/schema+json")
+	s.writeJSON(w, schema)
+}
+
+// jsonFieldErrors writes a 400 response listing provider config validation
+// errors per field.
+func jsonFieldErrors(w http.ResponseWriter, errs []config.FieldError) {
+	msgs := make([]string, 0, len(errs))
+	for _, e := range errs {
+		msgs = append(msgs, e.Error())
+	}
+	w.Header().Set("Content-Type", "application/json")
+	w.WriteHeader(http.StatusBadRequest)
+	body := map[string]interface{}{
+		"error":  "invalid provider config: " + strings.Join(msgs, "; "),
+		"fields": errs,
+	}
+	if err := json.NewEncoder(w).Encode(body); err != nil {
+		http.Error(w, http.StatusText(http.StatusInternalServerError), http.StatusInternalServerError)
+	}
+}
+
 // jsonError writes a JSON error response.
 func jsonError(w http.ResponseWriter, code int, message string) {
 	w.Header().Set("Content-Type", "application/json")
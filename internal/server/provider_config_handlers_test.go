@@ -7,6 +7,7 @@ import (
 	"log/slog"
 	"net/http"
 	"net/http/httptest"
+	"strings"
 	"testing"
 
 	"github.com/BadgerOps/airgap/internal/config"
@@ -161,7 +162,7 @@ func TestHandleUpdateProviderConfig(t *testing.T) {
 	srv := setupTestServer(t)
 
 	// Create first
-	body := `{"name":"epel","type":"epel","enabled":true,"config":{"key":"old"}}`
+	body := `{"name":"epel","type":"epel","enabled":true,"config":{"retry_attempts":1}}`
 	req := httptest.NewRequest("POST", "/api/providers/config", bytes.NewBufferString(body))
 	req.Header.Set("Content-Type", "application/json")
 	w := httptest.NewRecorder()
@@ -171,7 +172,7 @@ func TestHandleUpdateProviderConfig(t *testing.T) {
 	}
 
 	// Update
-	updateBody := `{"type":"epel","enabled":false,"config":{"key":"new"}}`
+	updateBody := `{"type":"epel","enabled":false,"config":{"retry_attempts":2}}`
 	updateReq := httptest.NewRequest("PUT", "/api/providers/config/epel", bytes.NewBufferString(updateBody))
 	updateReq.SetPathValue("name", "epel")
 	updateReq.Header.Set("Content-Type", "application/json")
@@ -189,7 +190,95 @@ func TestHandleUpdateProviderConfig(t *testing.T) {
 	if result.Enabled {
 		t.Error("expected enabled=false after update")
 	}
-	if result.Config["key"] != "new" {
-		t.Errorf("expected config key=new, got %v", result.Config["key"])
+	if result.Config["retry_attempts"] != float64(2) {
+		t.Errorf("expected config retry_attempts=2, got %v", result.Config["retry_attempts"])
+	}
+}
+
+func TestHandleUpdateProviderConfig_CorruptStoredConfig(t *testing.T) {
+	srv := setupTestServer(t)
+
+	pc := &store.ProviderConfig{Name: "epel", Type: "epel", Enabled: true, ConfigJSON: "{not json"}
+	if err := srv.store.CreateProviderConfig(pc); err != nil {
+		t.Fatal(err)
+	}
+
+	req := httptest.NewRequest("PUT", "/api/providers/config/epel", bytes.NewBufferString(`{"enabled":false}`))
+	req.SetPathValue("name", "epel")
+	w := httptest.NewRecorder()
+	srv.handleUpdateProviderConfig(w, req)
+	if w.Code != http.StatusInternalServerError {
+		t.Fatalf("expected 500, got %d: %s", w.Code, w.Body.String())
+	}
+	if !strings.Contains(w.Body.String(), "not valid JSON") {
+		t.Errorf("expected corrupt config error, got %s", w.Body.String())
+	}
+
+	got, err := srv.store.GetProviderConfig("epel")
+	if err != nil {
+		t.Fatal(err)
+	}
+	if !got.Enabled || got.ConfigJSON != "{not json" {
+		t.Errorf("stored config changed: %+v", got)
+	}
+}
+
+func TestHandleProviderSchema(t *testing.T) {
+	srv := setupTestServer(t)
+
+	req := httptest.NewRequest("GET", "/api/providers/schema/epel", nil)
+	req.SetPathValue("type", "epel")
+	w := httptest.NewRecorder()
+	srv.handleProviderSchema(w, req)
+	if w.Code != http.StatusOK {
+		t.Fatalf("expected 200, got %d", w.Code)
+	}
+	var schema struct {
+		Type       string                     `json:"type"`
+		Properties map[string]json.RawMessage `json:"properties"`
+	}
+	if err := json.NewDecoder(w.Body).Decode(&schema); err != nil {
+		t.Fatalf("failed to decode schema: %v", err)
+	}
+	if schema.Type != "object" || schema.Properties["repos"] == nil {
+		t.Errorf("unexpected schema: %+v", schema)
+	}
+
+	req = httptest.NewRequest("GET", "/api/providers/schema/bogus", nil)
+	req.SetPathValue("type", "bogus")
+	w = httptest.NewRecorder()
+	srv.handleProviderSchema(w, req)
+	if w.Code != http.StatusNotFound {
+		t.Errorf("expected 404 for unknown type, got %d", w.Code)
+	}
+}
+
+func TestHandleCreateProviderConfig_FieldErrors(t *testing.T) {
+	srv := setupTestServer(t)
+
+	body := `{"name":"epel","type":"epel","enabled":true,"config":{"repos":[{"name":"epel-9","base_url":"ftp://x"}],"retry_attempt":3}}`
+	req := httptest.NewRequest("POST", "/api/providers/config", bytes.NewBufferString(body))
+	w := httptest.NewRecorder()
+	srv.handleCreateProviderConfig(w, req)
+	if w.Code != http.StatusBadRequest {
+		t.Fatalf("expected 400, got %d", w.Code)
+	}
+
+	var resp struct {
+		Error  string `json:"error"`
+		Fields []struct {
+			Field   string `json:"field"`
+			Message string `json:"message"`
+		} `json:"fields"`
+	}
+	if err := json.NewDecoder(w.Body).Decode(&resp); err != nil {
+		t.Fatalf("failed to decode response: %v", err)
+	}
+	if len(resp.Fields) != 2 || resp.Fields[0].Field != "repos[0].base_url" || resp.Fields[1].Field != "retry_attempt" ||
+		!strings.Contains(resp.Fields[1].Message, `did you mean "retry_attempts"`) {
+		t.Errorf("unexpected field errors: %+v", resp)
+	}
+	if _, err := srv.store.GetProviderConfig("epel"); err == nil {
+		t.Error("invalid provider config was stored")
 	}
 }
@@ -5,8 +5,11 @@ import (
 	"encoding/json"
 	"fmt"
 	"net/http"
+	"os"
+	"path/filepath"
 	"strings"
 
+	"github.com/BadgerOps/airgap/internal/audit"
 	"github.com/BadgerOps/airgap/internal/engine"
 )
 
@@ -63,10 +66,14 @@ func (s *Server) handleAPIRegistryPush(w http.ResponseWriter, r *http.Request) {
 		return
 	}
 
+	ev := s.auditEvent(r, "registry.push", req.SourceProvider+" -> "+req.TargetProvider)
+
 	// Guard against concurrent operations.
 	s.syncMu.Lock()
 	if s.syncRunning {
 		s.syncMu.Unlock()
+		ev.Outcome, ev.Detail = audit.Outcome(errOperationRunning)
+		s.audit.Record(ev)
 		if htmx {
 			writeSyncFragment(w, false, "A sync/push operation is already running")
 		} else {
@@ -102,6 +109,12 @@ func (s *Server) handleAPIRegistryPush(w http.ResponseWriter, r *http.Request) {
 			TargetProvider: req.TargetProvider,
 			DryRun:         req.DryRun,
 		})
+		ev.Outcome, ev.Detail = audit.Outcome(pushErr)
+		if report != nil && pushErr == nil {
+			ev.Detail = fmt.Sprintf("dry_run=%t pushed %d/%d images", req.DryRun, report.ImagesPushed, report.ImagesTotal)
+		}
+		s.audit.Record(ev)
+
 		if pushErr != nil {
 			tracker.SetPhase(engine.PhaseFailed)
 			msg := "Registry push failed"
@@ -135,3 +148,85 @@ func (s *Server) handleAPIRegistryPush(w http.ResponseWriter, r *http.Request) {
 		"target_provider": req.TargetProvider,
 	})
 }
+
+// handleAPIMirrorConfig lists the cluster mirror manifests generated by the
+// last push from source_provider to target_provider.
+func (s *Server) handleAPIMirrorConfig(w http.ResponseWriter, r *http.Request) {
+	source := r.URL.Query().Get("source_provider")
+	target := r.URL.Query().Get("target_provider")
+	if source == "" || target == "" {
+		jsonError(w, http.StatusBadRequest, "source_provider and target_provider are required")
+		return
+	}
+
+	files, err := s.mirrorConfigFiles(source, target)
+	if err != nil {
+		jsonError(w, http.StatusBadRequest, err.Error())
+		return
+	}
+
+	w.Header().Set("Content-Type", "application/json")
+	s.writeJSON(w, map[string]interface{}{
+		"source_provider": source,
+		"target_provider": target,
+		"files":           files,
+	})
+}
+
+// handleAPIMirrorConfigFile downloads one generated mirror manifest.
+func (s *Server) handleAPIMirrorConfigFile(w http.ResponseWriter, r *http.Request) {
+	source := r.URL.Query().Get("source_provider")
+	target := r.URL.Query().Get("target_provider")
+	name := r.PathValue("file")
+	if source == "" || target == "" {
+		jsonError(w, http.StatusBadRequest, "source_provider and target_provider are required")
+		return
+	}
+
+	files, err := s.mirrorConfigFiles(source, target)
+	if err != nil {
+		jsonError(w, http.StatusBadRequest, err.Error())
+		return
+	}
+	found := false
+	for _, f := range files {
+		if f == name {
+			found = true
+			break
+		}
+	}
+	if !found {
+		jsonError(w, http.StatusNotFound, "mirror config file not found: "+name)
+		return
+	}
+
+	dir, _ := s.engine.MirrorConfigDir(source, target)
+	data, err := os.ReadFile(filepath.Join(dir, name))
+	if err != nil {
+		jsonError(w, http.StatusInternalServerError, "failed to read mirror config file")
+		return
+	}
+	w.Header().Set("Content-Type", "application/yaml")
+	w.Header().Set("Content-Disposition", fmt.Sprintf("attachment; filename=%q", name))
+	_, _ = w.Write(data)
+}
+
+// mirrorConfigFiles returns the generated YAML files for a source/target pair.
+// A pair that was never pushed yields an empty list.
+func (s *Server) mirrorConfigFiles(source, target string) ([]string, error) {
+	dir, err := s.engine.MirrorConfigDir(source, target)
+	if err != nil {
+		return nil, fmt.Errorf("invalid provider names")
+	}
+	entries, err := os.ReadDir(dir)
+	if err != nil && !os.IsNotExist(err) {
+		return nil, err
+	}
+	files := []string{}
+	for _, e := range entries {
+		if !e.IsDir() && strings.HasSuffix(e.Name(), ".yaml") {
+			files = append(files, e.Name())
+		}
+	}
+	return files, nil
+}
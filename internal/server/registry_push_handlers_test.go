@@ -6,6 +6,8 @@ import (
 	"net/http"
 	"net/http/httptest"
 	"net/url"
+	"os"
+	"path/filepath"
 	"strings"
 	"testing"
 )
@@ -85,3 +87,56 @@ func TestHandleAPIRegistryPushConflictHTMX(t *testing.T) {
 		t.Fatalf("expected conflict fragment body, got %q", body)
 	}
 }
+
+func TestHandleAPIMirrorConfig(t *testing.T) {
+	srv := setupTestServer(t)
+
+	dir, err := srv.engine.MirrorConfigDir("images", "quay")
+	if err != nil {
+		t.Fatal(err)
+	}
+	if err := os.MkdirAll(dir, 0o755); err != nil {
+		t.Fatal(err)
+	}
+	if err := os.WriteFile(filepath.Join(dir, "imagedigestmirrorset.yaml"), []byte("kind: ImageDigestMirrorSet\n"), 0o644); err != nil {
+		t.Fatal(err)
+	}
+
+	req := httptest.NewRequest(http.MethodGet, "/api/registry/mirror-config?source_provider=images&target_provider=quay", nil)
+	w := httptest.NewRecorder()
+	srv.handleAPIMirrorConfig(w, req)
+	if w.Code != http.StatusOK {
+		t.Fatalf("expected 200, got %d: %s", w.Code, w.Body.String())
+	}
+	var payload struct {
+		Files []string `json:"files"`
+	}
+	if err := json.NewDecoder(w.Body).Decode(&payload); err != nil {
+		t.Fatal(err)
+	}
+	if len(payload.Files) != 1 || payload.Files[0] != "imagedigestmirrorset.yaml" {
+		t.Fatalf("unexpected files: %v", payload.Files)
+	}
+
+	req = httptest.NewRequest(http.MethodGet, "/api/registry/mirror-config/imagedigestmirrorset.yaml?source_provider=images&target_provider=quay", nil)
+	req.SetPathValue("file", "imagedigestmirrorset.yaml")
+	w = httptest.NewRecorder()
+	srv.handleAPIMirrorConfigFile(w, req)
+	if w.Code != http.StatusOK {
+		t.Fatalf("expected 200, got %d: %s", w.Code, w.Body.String())
+	}
+	if !strings.Contains(w.Header().Get("Content-Disposition"), "imagedigestmirrorset.yaml") {
+		t.Errorf("missing attachment header: %q", w.Header().Get("Content-Disposition"))
+	}
+	if w.Body.String() != "kind: ImageDigestMirrorSet\n" {
+		t.Errorf("unexpected body %q", w.Body.String())
+	}
+
+	req = httptest.NewRequest(http.MethodGet, "/api/registry/mirror-config/..%2Fsecret?source_provider=images&target_provider=quay", nil)
+	req.SetPathValue("file", "../secret")
+	w = httptest.NewRecorder()
+	srv.handleAPIMirrorConfigFile(w, req)
+	if w.Code != http.StatusNotFound {
+		t.Fatalf("expected 404 for unknown file, got %d", w.Code)
+	}
+}
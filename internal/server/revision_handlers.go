@@ -0,0 +1,155 @@
+package server
+
+import (
+	"encoding/json"
+	"fmt"
+	"net/http"
+	"strconv"
+	"time"
+
+	"github.com/BadgerOps/airgap/internal/audit"
+	"github.com/BadgerOps/airgap/internal/store"
+)
+
+// providerRevisionJSON is the JSON shape of a provider config revision.
+// Config is only included when a single revision is requested, with
+// credential values redacted.
+type providerRevisionJSON struct {
+	Provider  string                 `json:"provider"`
+	Revision  int                    `json:"revision"`
+	Type      string                 `json:"type"`
+	Enabled   bool                   `json:"enabled"`
+	Author    string                 `json:"author"`
+	Comment   string                 `json:"comment,omitempty"`
+	CreatedAt time.Time              `json:"created_at"`
+	Config    map[string]interface{} `json:"config,omitempty"`
+}
+
+type rollbackRequest struct {
+	Revision int    `json:"revision"`
+	Comment  string `json:"comment"`
+}
+
+func revisionToJSON(rev store.ProviderConfigRevision, withConfig bool) providerRevisionJSON {
+	out := providerRevisionJSON{
+		Provider:  rev.Provider,
+		Revision:  rev.Revision,
+		Type:      rev.Type,
+		Enabled:   rev.Enabled,
+		Author:    rev.Author,
+		Comment:   rev.Comment,
+		CreatedAt: rev.CreatedAt,
+	}
+	if withConfig {
+		if err := json.Unmarshal([]byte(audit.RedactConfig(rev.ConfigJSON)), &out.Config); err != nil || out.Config == nil {
+			out.Config = make(map[string]interface{})
+		}
+	}
+	return out
+}
+
+// recordRevision snapshots provider name after a change made by r.
+func (s *Server) recordRevision(r *http.Request, name, comment string) {
+	if _, err := s.store.AddProviderConfigRevision(name, s.requestActor(r), comment); err != nil {
+		s.logger.Warn("failed to record provider config revision", "provider", name, "error", err)
+	}
+}
+
+// handleListProviderRevisions lists the revisions of a provider config,
+// newest first.
+func (s *Server) handleListProviderRevisions(w http.ResponseWriter, r *http.Request) {
+	name := r.PathValue("name")
+	revs, err := s.store.ListProviderConfigRevisions(name)
+	if err != nil {
+		jsonError(w, http.StatusInternalServerError, err.Error())
+		return
+	}
+	if len(revs) == 0 {
+		jsonError(w, http.StatusNotFound, "no revisions for provider: "+name)
+		return
+	}
+
+	result := make([]providerRevisionJSON, 0, len(revs))
+	for _, rev := range revs {
+		result = append(result, revisionToJSON(rev, false))
+	}
+	w.Header().Set("Content-Type", "application/json")
+	s.writeJSON(w, result)
+}
+
+// handleGetProviderRevision returns one revision including its config.
+func (s *Server) handleGetProviderRevision(w http.ResponseWriter, r *http.Request) {
+	name := r.PathValue("name")
+	revision, err := strconv.Atoi(r.PathValue("rev"))
+	if err != nil || revision < 1 {
+		jsonError(w, http.StatusBadRequest, "invalid revision")
+		return
+	}
+	rev, err := s.store.GetProviderConfigRevision(name, revision)
+	if err != nil {
+		jsonError(w, http.StatusNotFound, err.Error())
+		return
+	}
+	w.Header().Set("Content-Type", "application/json")
+	s.writeJSON(w, revisionToJSON(*rev, true))
+}
+
+// handleDiffProviderRevisions compares revisions from and to of a provider.
+func (s *Server) handleDiffProviderRevisions(w http.ResponseWriter, r *http.Request) {
+	name := r.PathValue("name")
+	from, errFrom := strconv.Atoi(r.URL.Query().Get("from"))
+	to, errTo := strconv.Atoi(r.URL.Query().Get("to"))
+	if errFrom != nil || errTo != nil || from < 1 || to < 1 {
+		jsonError(w, http.StatusBadRequest, "from and to must be revision numbers")
+		return
+	}
+	diff, err := s.engine.DiffProviderConfigRevisions(name, from, to)
+	if err != nil {
+		jsonError(w, http.StatusNotFound, err.Error())
+		return
+	}
+	w.Header().Set("Content-Type", "application/json")
+	s.writeJSON(w, diff)
+}
+
+// handleRollbackProviderConfig restores a provider config to an earlier
+// revision and reloads providers.
+func (s *Server) handleRollbackProviderConfig(w http.ResponseWriter, r *http.Request) {
+	name := r.PathValue("name")
+	var req rollbackRequest
+	if err := json.NewDecoder(r.Body).Decode(&req); err != nil {
+		jsonError(w, http.StatusBadRequest, "invalid JSON: "+err.Error())
+		return
+	}
+	if req.Revision < 1 {
+		jsonError(w, http.StatusBadRequest, "revision is required")
+		return
+	}
+	if _, err := s.store.GetProviderConfigRevision(name, req.Revision); err != nil {
+		jsonError(w, http.StatusNotFound, err.Error())
+		return
+	}
+
+	ev := s.auditEvent(r, "provider.rollback", name)
+	ev.Detail = fmt.Sprintf("to revision %d", req.Revision)
+	if existing, err := s.store.GetProviderConfig(name); err == nil {
+		ev.Before = audit.ProviderState(existing)
+	}
+
+	rev, err := s.engine.RollbackProviderConfig(name, req.Revision, s.requestActor(r), req.Comment)
+	if err != nil {
+		ev.Outcome, ev.Detail = audit.Outcome(err)
+		s.audit.Record(ev)
+		jsonError(w, http.StatusInternalServerError, err.Error())
+		return
+	}
+
+	s.reloadProviders()
+
+	got, _ := s.store.GetProviderConfig(name)
+	ev.After, ev.Outcome = audit.ProviderState(got), audit.OutcomeSuccess
+	s.audit.Record(ev)
+
+	w.Header().Set("Content-Type", "application/json")
+	s.writeJSON(w, revisionToJSON(*rev, true))
+}
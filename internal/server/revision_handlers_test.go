@@ -0,0 +1,129 @@
+package server
+
+import (
+	"bytes"
+	"encoding/json"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"testing"
+
+	"github.com/BadgerOps/airgap/internal/config"
+)
+
+func TestProviderConfigRevisionsAndRollback(t *testing.T) {
+	srv := setupTestServer(t)
+	srv.trustedProxies = parseTrustedProxies([]string{"192.0.2.1"}, srv.logger)
+
+	send := func(handler http.HandlerFunc, method, path, name, body string) *httptest.ResponseRecorder {
+		t.Helper()
+		req := httptest.NewRequest(method, path, bytes.NewBufferString(body))
+		req.SetPathValue("name", name)
+		req.Header.Set("X-Remote-User", "alice")
+		w := httptest.NewRecorder()
+		handler(w, req)
+		return w
+	}
+
+	if w := send(srv.handleCreateProviderConfig, "POST", "/api/providers/config", "",
+		`{"name":"ocp","type":"ocp_binaries","enabled":true,"config":{"base_url":"https://good"},"comment":"first"}`); w.Code != http.StatusCreated {
+		t.Fatalf("create: %d %s", w.Code, w.Body.String())
+	}
+	if w := send(srv.handleUpdateProviderConfig, "PUT", "/api/providers/config/ocp", "ocp",
+		`{"enabled":true,"config":{"base_url":"https://broken"},"comment":"switch mirror"}`); w.Code != http.StatusOK {
+		t.Fatalf("update: %d %s", w.Code, w.Body.String())
+	}
+	if w := send(srv.handleToggleProviderConfig, "POST", "/api/providers/config/ocp/toggle", "ocp", ""); w.Code != http.StatusOK {
+		t.Fatalf("toggle: %d %s", w.Code, w.Body.String())
+	}
+
+	w := send(srv.handleListProviderRevisions, "GET", "/api/providers/config/ocp/revisions", "ocp", "")
+	var revs []providerRevisionJSON
+	if err := json.NewDecoder(w.Body).Decode(&revs); err != nil {
+		t.Fatalf("decode revisions: %v", err)
+	}
+	if len(revs) != 3 || revs[0].Comment != "disabled" || revs[1].Comment != "switch mirror" || revs[2].Author != "alice" {
+		t.Fatalf("revisions = %+v", revs)
+	}
+
+	req := httptest.NewRequest("GET", "/api/providers/config/ocp/diff?from=1&to=3", nil)
+	req.SetPathValue("name", "ocp")
+	w = httptest.NewRecorder()
+	srv.handleDiffProviderRevisions(w, req)
+	var diff struct {
+		Changes []struct {
+			Path   string `json:"path"`
+			Before any    `json:"before"`
+			After  any    `json:"after"`
+		} `json:"changes"`
+	}
+	if err := json.NewDecoder(w.Body).Decode(&diff); err != nil {
+		t.Fatalf("decode diff: %v", err)
+	}
+	if len(diff.Changes) != 2 || diff.Changes[0].Path != "config.base_url" || diff.Changes[1].Path != "enabled" {
+		t.Errorf("diff = %+v", diff)
+	}
+
+	if w := send(srv.handleRollbackProviderConfig, "POST", "/api/providers/config/ocp/rollback", "ocp",
+		`{"revision":1,"comment":"mirror is down"}`); w.Code != http.StatusOK {
+		t.Fatalf("rollback: %d %s", w.Code, w.Body.String())
+	}
+	pc, err := srv.store.GetProviderConfig("ocp")
+	if err != nil {
+		t.Fatal(err)
+	}
+	if !pc.Enabled || pc.ConfigJSON != `{"base_url":"https://good"}` {
+		t.Errorf("config after rollback = %+v", pc)
+	}
+	latest, err := srv.store.GetProviderConfigRevision("ocp", 4)
+	if err != nil || latest.Comment != "rollback to revision 1: mirror is down" || latest.Author != "alice" {
+		t.Errorf("rollback revision = %+v, %v", latest, err)
+	}
+
+	if w := send(srv.handleRollbackProviderConfig, "POST", "/api/providers/config/ocp/rollback", "ocp",
+		`{"revision":42}`); w.Code != http.StatusNotFound {
+		t.Errorf("rollback to missing revision = %d, want 404", w.Code)
+	}
+}
+
+func TestProviderRevisionRedactsSecrets(t *testing.T) {
+	srv := setupTestServer(t)
+
+	send := func(handler http.HandlerFunc, method, path, rev, body string) *httptest.ResponseRecorder {
+		t.Helper()
+		req := httptest.NewRequest(method, path, bytes.NewBufferString(body))
+		req.SetPathValue("name", "mirror")
+		req.SetPathValue("rev", rev)
+		w := httptest.NewRecorder()
+		handler(w, req)
+		return w
+	}
+
+	if w := send(srv.handleCreateProviderConfig, "POST", "/api/providers/config", "",
+		`{"name":"mirror","type":"registry","enabled":true,"config":{"endpoint":"r.example","password":"hunter2"}}`); w.Code != http.StatusCreated {
+		t.Fatalf("create: %d %s", w.Code, w.Body.String())
+	}
+	if w := send(srv.handleUpdateProviderConfig, "PUT", "/api/providers/config/mirror", "",
+		`{"enabled":true,"config":{"endpoint":"r2.example","password":"hunter3"}}`); w.Code != http.StatusOK {
+		t.Fatalf("update: %d %s", w.Code, w.Body.String())
+	}
+
+	for _, w := range []*httptest.ResponseRecorder{
+		send(srv.handleGetProviderRevision, "GET", "/api/providers/config/mirror/revisions/1", "1", ""),
+		send(srv.handleRollbackProviderConfig, "POST", "/api/providers/config/mirror/rollback", "", `{"revision":1}`),
+	} {
+		if w.Code != http.StatusOK {
+			t.Fatalf("got %d %s", w.Code, w.Body.String())
+		}
+		if strings.Contains(w.Body.String(), "hunter") {
+			t.Errorf("response leaked a password: %s", w.Body.String())
+		}
+		var rev providerRevisionJSON
+		if err := json.NewDecoder(w.Body).Decode(&rev); err != nil {
+			t.Fatalf("decode: %v", err)
+		}
+		if rev.Config["endpoint"] != "r.example" || rev.Config["password"] != config.RedactedValue {
+			t.Errorf("revision config = %v", rev.Config)
+		}
+	}
+}
@@ -0,0 +1,51 @@
+package server
+
+import (
+	"net/http"
+	"strconv"
+
+	"github.com/BadgerOps/airgap/internal/store"
+)
+
+// handleSearch renders the inventory search page.
+func (s *Server) handleSearch(w http.ResponseWriter, r *http.Request) {
+	data := map[string]interface{}{
+		"Title":     "Search",
+		"Providers": s.registry.Names(),
+	}
+	s.renderTemplate(w, "templates/search.html", data)
+}
+
+// handleAPISearch searches the mirrored inventory. q matches package, image
+// and artifact names; provider, type and version filter the results.
+func (s *Server) handleAPISearch(w http.ResponseWriter, r *http.Request) {
+	params := r.URL.Query()
+	q := store.SearchQuery{
+		Text:     params.Get("q"),
+		Provider: params.Get("provider"),
+		Type:     params.Get("type"),
+		Version:  params.Get("version"),
+	}
+	if q.Text == "" && q.Provider == "" && q.Type == "" && q.Version == "" {
+		jsonError(w, http.StatusBadRequest, "q or a provider, type or version filter is required")
+		return
+	}
+	if v := params.Get("limit"); v != "" {
+		limit, err := strconv.Atoi(v)
+		if err != nil || limit < 1 {
+			jsonError(w, http.StatusBadRequest, "limit must be a positive integer")
+			return
+		}
+		q.Limit = limit
+	}
+
+	results, err := s.engine.Search(q)
+	if err != nil {
+		s.logger.Error("failed to search inventory", "query", q.Text, "error", err)
+		jsonError(w, http.StatusInternalServerError, "search failed")
+		return
+	}
+
+	w.Header().Set("Content-Type", "application/json")
+	s.writeJSON(w, results)
+}
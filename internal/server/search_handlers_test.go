@@ -0,0 +1,66 @@
+package server
+
+import (
+	"encoding/json"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+
+	"github.com/BadgerOps/airgap/internal/engine"
+	"github.com/BadgerOps/airgap/internal/store"
+)
+
+func TestHandleAPISearch(t *testing.T) {
+	srv := setupTestServer(t)
+
+	if err := srv.store.ReplaceSearchEntries("epel", []store.SearchEntry{
+		{Type: "rpm", Name: "zsh", Version: "5.8-9.el9", Arch: "x86_64", Path: "Packages/z/zsh-5.8-9.el9.x86_64.rpm"},
+		{Type: "rpm", Name: "htop", Version: "3.3.0-1.el9", Arch: "x86_64", Path: "Packages/h/htop-3.3.0-1.el9.x86_64.rpm"},
+	}); err != nil {
+		t.Fatal(err)
+	}
+	if err := srv.store.ReplaceSearchEntries("images", []store.SearchEntry{
+		{Type: "image", Name: "quay.io/openshift/origin-cli", Version: "4.17", Digest: "sha256:abc"},
+	}); err != nil {
+		t.Fatal(err)
+	}
+
+	req := httptest.NewRequest(http.MethodGet, "/api/search?q=zsh&type=rpm", nil)
+	w := httptest.NewRecorder()
+	srv.handleAPISearch(w, req)
+
+	if w.Code != http.StatusOK {
+		t.Fatalf("expected 200, got %d: %s", w.Code, w.Body.String())
+	}
+	var got []engine.SearchResult
+	if err := json.Unmarshal(w.Body.Bytes(), &got); err != nil {
+		t.Fatal(err)
+	}
+	if len(got) != 1 || got[0].Provider != "epel" || got[0].Version != "5.8-9.el9" {
+		t.Fatalf("unexpected results: %+v", got)
+	}
+
+	req = httptest.NewRequest(http.MethodGet, "/api/search?provider=images&version=4.17", nil)
+	w = httptest.NewRecorder()
+	srv.handleAPISearch(w, req)
+	got = nil
+	if err := json.Unmarshal(w.Body.Bytes(), &got); err != nil {
+		t.Fatal(err)
+	}
+	if len(got) != 1 || got[0].Digest != "sha256:abc" {
+		t.Errorf("unexpected filtered results: %+v", got)
+	}
+
+	for query, code := range map[string]int{
+		"":                  http.StatusBadRequest,
+		"?q=zsh&limit=zero": http.StatusBadRequest,
+		"?q=nothing":        http.StatusOK,
+	} {
+		req := httptest.NewRequest(http.MethodGet, "/api/search"+query, nil)
+		w := httptest.NewRecorder()
+		srv.handleAPISearch(w, req)
+		if w.Code != code {
+			t.Errorf("%q: expected %d, got %d", query, code, w.Code)
+		}
+	}
+}
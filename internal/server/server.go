@@ -6,10 +6,12 @@ import (
 	"fmt"
 	"html/template"
 	"log/slog"
+	"net"
 	"net/http"
 	"sync"
 	"time"
 
+	"github.com/BadgerOps/airgap/internal/audit"
 	"github.com/BadgerOps/airgap/internal/config"
 	"github.com/BadgerOps/airgap/internal/engine"
 	"github.com/BadgerOps/airgap/internal/mirror"
@@ -28,14 +30,17 @@ var staticFS embed.FS
 type Server struct {
 	engine     *engine.SyncManager
 	registry   *provider.Registry
-	store      *store.Store
+	store      store.Store
 	config     *config.Config
 	logger     *slog.Logger
 	discovery  *mirror.Discovery
 	ocpClients *ocp.ClientService
-	httpServer *http.Server
-	templates  map[string]*template.Template
-	version    string
+	audit      *audit.Recorder
+	// trustedProxies may name the actor of a request; see requestActor.
+	trustedProxies []*net.IPNet
+	httpServer     *http.Server
+	templates      map[string]*template.Template
+	version        string
 
 	// Active sync state
 	syncMu      sync.Mutex
@@ -52,7 +57,7 @@ func (s *Server) SetVersion(v string) {
 func NewServer(
 	eng *engine.SyncManager,
 	reg *provider.Registry,
-	st *store.Store,
+	st store.Store,
 	cfg *config.Config,
 	logger *slog.Logger,
 ) *Server {
@@ -60,14 +65,21 @@ func NewServer(
 		logger = slog.Default()
 	}
 	discovery := mirror.NewDiscovery(logger)
+	hashChain := cfg != nil && cfg.Audit.HashChain
+	var trustedProxies []*net.IPNet
+	if cfg != nil {
+		trustedProxies = parseTrustedProxies(cfg.Audit.TrustedProxies, logger)
+	}
 	return &Server{
-		engine:     eng,
-		registry:   reg,
-		store:      st,
-		config:     cfg,
-		logger:     logger,
-		discovery:  discovery,
-		ocpClients: ocp.NewClientService(logger),
+		engine:         eng,
+		registry:       reg,
+		store:          st,
+		config:         cfg,
+		logger:         logger,
+		discovery:      discovery,
+		ocpClients:     ocp.NewClientService(logger),
+		audit:          audit.NewRecorder(st, hashChain, logger),
+		trustedProxies: trustedProxies,
 	}
 }
 
@@ -121,6 +133,7 @@ func (s *Server) parseTemplates() error {
 		"templates/provider_detail.html",
 		"templates/transfer.html",
 		"templates/ocp_clients.html",
+		"templates/search.html",
 	}
 
 	for _, page := range pages {
@@ -165,6 +178,7 @@ func (s *Server) setupRoutes() *http.ServeMux {
 	mux.HandleFunc("GET /providers/{name}", s.handleProviderDetail)
 	mux.HandleFunc("GET /providers", s.handleProviders)
 	mux.HandleFunc("GET /sync", s.handleSync)
+	mux.HandleFunc("GET /search", s.handleSearch)
 
 	// API routes
 	mux.HandleFunc("GET /api/status", s.handleAPIStatus)
@@ -179,7 +193,13 @@ func (s *Server) setupRoutes() *http.ServeMux {
 	mux.HandleFunc("DELETE /api/sync/failures/{id}", s.handleAPISyncFailureResolve)
 	mux.HandleFunc("POST /api/sync/failures/resolve", s.handleAPISyncFailuresResolve)
 	mux.HandleFunc("POST /api/sync/retry", s.handleAPISyncRetry)
+	mux.HandleFunc("GET /api/provenance", s.handleAPIProvenance)
+	mux.HandleFunc("GET /api/search", s.handleAPISearch)
+	mux.HandleFunc("GET /api/audit", s.handleAPIAudit)
+	mux.HandleFunc("GET /api/audit/verify", s.handleAPIAuditVerify)
 	mux.HandleFunc("POST /api/registry/push", s.handleAPIRegistryPush)
+	mux.HandleFunc("GET /api/registry/mirror-config", s.handleAPIMirrorConfig)
+	mux.HandleFunc("GET /api/registry/mirror-config/{file}", s.handleAPIMirrorConfigFile)
 
 	// Provider config CRUD routes
 	mux.HandleFunc("GET /api/providers/config", s.handleListProviderConfigs)
@@ -187,12 +207,20 @@ func (s *Server) setupRoutes() *http.ServeMux {
 	mux.HandleFunc("PUT /api/providers/config/{name}", s.handleUpdateProviderConfig)
 	mux.HandleFunc("DELETE /api/providers/config/{name}", s.handleDeleteProviderConfig)
 	mux.HandleFunc("POST /api/providers/config/{name}/toggle", s.handleToggleProviderConfig)
+	mux.HandleFunc("GET /api/providers/config/{name}/revisions", s.handleListProviderRevisions)
+	mux.HandleFunc("GET /api/providers/config/{name}/revisions/{rev}", s.handleGetProviderRevision)
+	mux.HandleFunc("GET /api/providers/config/{name}/diff", s.handleDiffProviderRevisions)
+	mux.HandleFunc("POST /api/providers/config/{name}/rollback", s.handleRollbackProviderConfig)
+	mux.HandleFunc("GET /api/providers/schema/{type}", s.handleProviderSchema)
+	mux.HandleFunc("POST /api/providers/imageset", s.handleImportImageset)
 
 	// Transfer routes
 	mux.HandleFunc("GET /transfer", s.handleTransfer)
 	mux.HandleFunc("POST /api/transfer/export", s.handleAPITransferExport)
 	mux.HandleFunc("POST /api/transfer/import", s.handleAPITransferImport)
 	mux.HandleFunc("GET /api/transfers", s.handleAPITransfers)
+	mux.HandleFunc("GET /api/transfers/diff", s.handleAPITransferDiff)
+	mux.HandleFunc("POST /api/manifests/diff", s.handleAPIManifestDiff)
 
 	// Mirror discovery routes
 	mux.HandleFunc("GET /api/mirrors/epel/versions", s.handleEPELVersions)
@@ -207,6 +235,17 @@ func (s *Server) setupRoutes() *http.ServeMux {
 	mux.HandleFunc("GET /api/ocp/artifacts", s.handleAPIOCPArtifacts)
 	mux.HandleFunc("POST /api/ocp/download", s.handleAPIOCPDownload)
 
+	// Cincinnati update graph for disconnected clusters
+	mux.HandleFunc("GET /api/upgrades_info/v1/graph", s.handleCincinnatiGraph)
+
+	// PXE/iPXE boot of mirrored RHCOS images
+	if s.config != nil && s.config.Boot.Enabled {
+		mux.HandleFunc("GET /boot/ipxe", s.handleBootMenu)
+		mux.HandleFunc("GET /boot/ipxe/{id...}", s.handleBootScript)
+		mux.HandleFunc("GET /boot/files/{provider}/{path...}", s.handleBootFile)
+		mux.HandleFunc("GET /api/boot/images", s.handleAPIBootImages)
+	}
+
 	// Root redirect
 	mux.HandleFunc("GET /{$}", s.handleRedirectDashboard)
 
@@ -39,6 +39,11 @@ func TestTemplateIsolationEndToEnd(t *testing.T) {
 			mustContain:    "providerManager",
 			mustNotContain: "Start Export",
 		},
+		{
+			path:           "/search",
+			mustContain:    "inventorySearch",
+			mustNotContain: "Start Export",
+		},
 	}
 
 	for _, tt := range tests {
@@ -2,9 +2,12 @@ package server
 
 import (
 	"context"
+	"encoding/json"
 	"fmt"
 	"html"
 	"net/http"
+	"strconv"
+	"strings"
 	"time"
 
 	"github.com/BadgerOps/airgap/internal/engine"
@@ -122,11 +125,17 @@ func (s *Server) handleAPITransferExport(w http.ResponseWriter, r *http.Request)
 	defer cancel()
 
 	report, err := s.engine.Export(ctx, engine.ExportOptions{
-		OutputDir:   outputDir,
-		Providers:   providers,
-		SplitSize:   splitSize,
-		Compression: "zstd",
+		OutputDir:    outputDir,
+		Providers:    providers,
+		SplitSize:    splitSize,
+		Compression:  "zstd",
+		IncludeState: r.FormValue("include_state") == "on",
 	})
+	var detail string
+	if report != nil {
+		detail = fmt.Sprintf("providers=%s archives=%d files=%d", strings.Join(providers, ","), len(report.Archives), report.TotalFiles)
+	}
+	s.recordAudit(r, "transfer.export", outputDir, detail, err)
 	if err != nil {
 		writeTransferFragment(w, false, "Export failed: "+err.Error())
 		return
@@ -164,6 +173,12 @@ func (s *Server) handleAPITransferImport(w http.ResponseWriter, r *http.Request)
 		Force:         force,
 		SkipValidated: skipValidated,
 	})
+	var detail string
+	if report != nil {
+		detail = fmt.Sprintf("verify_only=%t force=%t validated=%d files=%d",
+			verifyOnly, force, report.ArchivesValidated, report.FilesExtracted)
+	}
+	s.recordAudit(r, "transfer.import", sourceDir, detail, err)
 	if err != nil {
 		errMsg := "Import failed: " + err.Error()
 		if report != nil {
@@ -195,3 +210,57 @@ func writeTransferFragment(w http.ResponseWriter, success bool, message string)
 		return
 	}
 }
+
+// handleAPITransferDiff compares the manifests of transfers from and to.
+// With format=csv it returns a CSV report instead of JSON.
+func (s *Server) handleAPITransferDiff(w http.ResponseWriter, r *http.Request) {
+	q := r.URL.Query()
+	from, errFrom := strconv.ParseInt(q.Get("from"), 10, 64)
+	to, errTo := strconv.ParseInt(q.Get("to"), 10, 64)
+	if errFrom != nil || errTo != nil {
+		jsonError(w, http.StatusBadRequest, "from and to must be transfer IDs")
+		return
+	}
+	fromManifest, fromLabel, err := s.engine.TransferManifest(from)
+	if err != nil {
+		jsonError(w, http.StatusNotFound, err.Error())
+		return
+	}
+	toManifest, toLabel, err := s.engine.TransferManifest(to)
+	if err != nil {
+		jsonError(w, http.StatusNotFound, err.Error())
+		return
+	}
+	s.writeManifestDiff(w, r, engine.DiffManifests(fromManifest, toManifest, fromLabel, toLabel))
+}
+
+// handleAPIManifestDiff compares two manifests posted as
+// {"from": {...}, "to": {...}}.
+func (s *Server) handleAPIManifestDiff(w http.ResponseWriter, r *http.Request) {
+	var req struct {
+		From *engine.TransferManifest `json:"from"`
+		To   *engine.TransferManifest `json:"to"`
+	}
+	if err := json.NewDecoder(r.Body).Decode(&req); err != nil {
+		jsonError(w, http.StatusBadRequest, "invalid request body: "+err.Error())
+		return
+	}
+	if req.From == nil || req.To == nil {
+		jsonError(w, http.StatusBadRequest, "from and to manifests are required")
+		return
+	}
+	s.writeManifestDiff(w, r, engine.DiffManifests(req.From, req.To, "from", "to"))
+}
+
+func (s *Server) writeManifestDiff(w http.ResponseWriter, r *http.Request, diff *engine.ManifestDiff) {
+	if r.URL.Query().Get("format") == "csv" {
+		w.Header().Set("Content-Type", "text/csv")
+		w.Header().Set("Content-Disposition", `attachment; filename="manifest-diff.csv"`)
+		if err := diff.WriteCSV(w); err != nil {
+			s.logger.Error("failed to write manifest diff", "error", err)
+		}
+		return
+	}
+	w.Header().Set("Content-Type", "application/json")
+	s.writeJSON(w, diff)
+}
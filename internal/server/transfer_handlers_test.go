@@ -0,0 +1,62 @@
+package server
+
+import (
+	"bytes"
+	"encoding/json"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/BadgerOps/airgap/internal/engine"
+	"github.com/BadgerOps/airgap/internal/store"
+)
+
+func TestHandleAPITransferDiff(t *testing.T) {
+	srv := setupTestServer(t)
+
+	for _, inventory := range [][]engine.ManifestFile{
+		{{Provider: "epel", Path: "9/Packages/zsh-5.8-9.el9.x86_64.rpm", Size: 100, SHA256: "a"}},
+		{{Provider: "epel", Path: "9/Packages/zsh-5.8-10.el9.x86_64.rpm", Size: 120, SHA256: "b"}},
+	} {
+		data, _ := json.Marshal(&engine.TransferManifest{Version: "1.0", FileInventory: inventory})
+		tr := &store.Transfer{Direction: "export", Path: t.TempDir(), Manifest: string(data), Status: "completed", StartTime: time.Now()}
+		if err := srv.store.CreateTransfer(tr); err != nil {
+			t.Fatal(err)
+		}
+	}
+
+	w := httptest.NewRecorder()
+	srv.handleAPITransferDiff(w, httptest.NewRequest("GET", "/api/transfers/diff?from=1&to=2", nil))
+	if w.Code != http.StatusOK {
+		t.Fatalf("status = %d: %s", w.Code, w.Body.String())
+	}
+	var diff engine.ManifestDiff
+	if err := json.NewDecoder(w.Body).Decode(&diff); err != nil {
+		t.Fatal(err)
+	}
+	if diff.Totals.Added != 1 || diff.Totals.Removed != 1 || diff.Totals.SizeDelta != 20 ||
+		len(diff.Providers) != 1 || diff.Providers[0].Packages[0].Change != engine.ChangeUpgraded {
+		t.Errorf("diff = %+v", diff)
+	}
+
+	w = httptest.NewRecorder()
+	srv.handleAPITransferDiff(w, httptest.NewRequest("GET", "/api/transfers/diff?from=1&to=2&format=csv", nil))
+	if ct := w.Header().Get("Content-Type"); ct != "text/csv" || !strings.Contains(w.Body.String(), "epel,rpm,upgraded,zsh,x86_64,5.8-9.el9,5.8-10.el9") {
+		t.Errorf("CSV response (%s) = %s", ct, w.Body.String())
+	}
+
+	w = httptest.NewRecorder()
+	srv.handleAPITransferDiff(w, httptest.NewRequest("GET", "/api/transfers/diff?from=1&to=9", nil))
+	if w.Code != http.StatusNotFound {
+		t.Errorf("missing transfer status = %d, want 404", w.Code)
+	}
+
+	body := `{"from":{"file_inventory":[{"provider":"files","path":"a.iso","size":5,"sha256":"x"}]},"to":{"file_inventory":[]}}`
+	w = httptest.NewRecorder()
+	srv.handleAPIManifestDiff(w, httptest.NewRequest("POST", "/api/manifests/diff", bytes.NewBufferString(body)))
+	if w.Code != http.StatusOK || !strings.Contains(w.Body.String(), `"removed":1`) {
+		t.Errorf("posted manifest diff = %d %s", w.Code, w.Body.String())
+	}
+}
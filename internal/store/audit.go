@@ -0,0 +1,208 @@
+package store
+
+import (
+	"context"
+	"crypto/sha256"
+	"database/sql"
+	"encoding/hex"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"strings"
+	"time"
+
+	"modernc.org/sqlite"
+	sqlite3 "modernc.org/sqlite/lib"
+)
+
+// ComputeHash returns the SHA-256 chaining hash of e: its fields other than
+// ID and Hash, including PrevHash, encoded unambiguously.
+func (e AuditEvent) ComputeHash() string {
+	fields, _ := json.Marshal([]string{
+		e.Time.UTC().Format(time.RFC3339Nano),
+		e.Actor, e.SourceIP, e.Source, e.Action, e.Target,
+		e.Before, e.After, e.Outcome, e.Detail, e.PrevHash,
+	})
+	sum := sha256.Sum256(fields)
+	return hex.EncodeToString(sum[:])
+}
+
+// prepareAuditEvent normalizes ev before it is stored. Times are kept to
+// the microsecond so hashes survive a round trip through either backend.
+func prepareAuditEvent(ev *AuditEvent) {
+	if ev.Time.IsZero() {
+		ev.Time = time.Now()
+	}
+	ev.Time = ev.Time.UTC().Truncate(time.Microsecond)
+	ev.PrevHash = ""
+	ev.Hash = ""
+}
+
+// auditWhere builds the WHERE clause for f. placeholder appends a value to
+// the query arguments and returns its placeholder.
+func auditWhere(f AuditFilter, placeholder func(v interface{}) string) string {
+	var where []string
+	if f.Actor != "" {
+		where = append(where, "actor = "+placeholder(f.Actor))
+	}
+	if f.Action != "" {
+		if strings.HasSuffix(f.Action, ".") {
+			escaped := strings.NewReplacer(`\`, `\\`, `%`, `\%`, `_`, `\_`).Replace(f.Action)
+			where = append(where, "action LIKE "+placeholder(escaped+"%")+` ESCAPE '\'`)
+		} else {
+			where = append(where, "action = "+placeholder(f.Action))
+		}
+	}
+	if f.Target != "" {
+		where = append(where, "target = "+placeholder(f.Target))
+	}
+	if f.Outcome != "" {
+		where = append(where, "outcome = "+placeholder(f.Outcome))
+	}
+	if !f.Since.IsZero() {
+		where = append(where, "time >= "+placeholder(f.Since.UTC()))
+	}
+	if !f.Until.IsZero() {
+		where = append(where, "time < "+placeholder(f.Until.UTC()))
+	}
+	if len(where) == 0 {
+		return ""
+	}
+	return " WHERE " + strings.Join(where, " AND ")
+}
+
+const auditColumns = `id, time, actor, source_ip, source, action, target,
+	before_json, after_json, outcome, detail, prev_hash, hash`
+
+// collectAuditEvents scans and closes rows of audit event columns.
+func collectAuditEvents(rows *sql.Rows) ([]AuditEvent, error) {
+	defer func() {
+		_ = rows.Close()
+	}()
+
+	var events []AuditEvent
+	for rows.Next() {
+		var e AuditEvent
+		if err := rows.Scan(
+			&e.ID, &e.Time, &e.Actor, &e.SourceIP, &e.Source, &e.Action, &e.Target,
+			&e.Before, &e.After, &e.Outcome, &e.Detail, &e.PrevHash, &e.Hash,
+		); err != nil {
+			return nil, fmt.Errorf("failed to scan audit event: %w", err)
+		}
+		e.Time = e.Time.UTC()
+		events = append(events, e)
+	}
+	if err := rows.Err(); err != nil {
+		return nil, fmt.Errorf("error iterating audit events: %w", err)
+	}
+	return events, nil
+}
+
+// Appends retry while another process holds the SQLite write lock, waiting
+// about ten seconds in all before giving up.
+const (
+	auditAppendAttempts = 20
+	auditRetryDelay     = 50 * time.Millisecond
+)
+
+// AppendAuditEvent stores ev and sets its ID. With chain set, ev is linked
+// to the newest stored event and its Hash and PrevHash are set.
+func (s *SQLiteStore) AppendAuditEvent(ev *AuditEvent, chain bool) error {
+	// The mutex orders appends within this process; BEGIN IMMEDIATE in
+	// appendAuditEvent orders them against other processes (the CLI while
+	// airgap serve runs) so two events cannot chain to the same predecessor.
+	s.auditMu.Lock()
+	defer s.auditMu.Unlock()
+
+	prepareAuditEvent(ev)
+
+	var err error
+	for attempt := 1; attempt <= auditAppendAttempts; attempt++ {
+		if err = s.appendAuditEvent(ev, chain); !isBusy(err) {
+			return err
+		}
+		time.Sleep(time.Duration(attempt) * auditRetryDelay)
+	}
+	return err
+}
+
+// appendAuditEvent inserts ev in a transaction that takes the write lock
+// before reading the chain head.
+func (s *SQLiteStore) appendAuditEvent(ev *AuditEvent, chain bool) error {
+	ctx := context.Background()
+	conn, err := s.db.Conn(ctx)
+	if err != nil {
+		return fmt.Errorf("failed to get connection: %w", err)
+	}
+	defer func() {
+		_ = conn.Close()
+	}()
+
+	if _, err := conn.ExecContext(ctx, "BEGIN IMMEDIATE"); err != nil {
+		return fmt.Errorf("failed to begin transaction: %w", err)
+	}
+	committed := false
+	defer func() {
+		if !committed {
+			_, _ = conn.ExecContext(ctx, "ROLLBACK")
+		}
+	}()
+
+	ev.PrevHash, ev.Hash = "", ""
+	if chain {
+		if err := conn.QueryRowContext(ctx, "SELECT hash FROM audit_events ORDER BY id DESC LIMIT 1").Scan(&ev.PrevHash); err != nil && err != sql.ErrNoRows {
+			return fmt.Errorf("failed to read last audit hash: %w", err)
+		}
+		ev.Hash = ev.ComputeHash()
+	}
+
+	result, err := conn.ExecContext(ctx, `
+		INSERT INTO audit_events (
+			time, actor, source_ip, source, action, target,
+			before_json, after_json, outcome, detail, prev_hash, hash
+		) VALUES (?, ?, ?, ?, ?, ?, ?, ?, ?, ?, ?, ?)
+	`,
+		ev.Time, ev.Actor, ev.SourceIP, ev.Source, ev.Action, ev.Target,
+		ev.Before, ev.After, ev.Outcome, ev.Detail, ev.PrevHash, ev.Hash,
+	)
+	if err != nil {
+		return fmt.Errorf("failed to insert audit event: %w", err)
+	}
+	id, err := result.LastInsertId()
+	if err != nil {
+		return fmt.Errorf("failed to get last insert id: %w", err)
+	}
+
+	if _, err := conn.ExecContext(ctx, "COMMIT"); err != nil {
+		return fmt.Errorf("failed to commit audit event: %w", err)
+	}
+	committed = true
+	ev.ID = id
+	return nil
+}
+
+// isBusy reports whether err is SQLite's "database is locked" error.
+func isBusy(err error) bool {
+	var se *sqlite.Error
+	return errors.As(err, &se) && se.Code()&0xff == sqlite3.SQLITE_BUSY
+}
+
+// ListAuditEvents returns the audit events matching f, newest first. A zero
+// Limit returns all matching events.
+func (s *SQLiteStore) ListAuditEvents(f AuditFilter) ([]AuditEvent, error) {
+	var args []interface{}
+	query := "SELECT " + auditColumns + " FROM audit_events" + auditWhere(f, func(v interface{}) string {
+		args = append(args, v)
+		return "?"
+	}) + " ORDER BY id DESC"
+	if f.Limit > 0 {
+		query += " LIMIT ?"
+		args = append(args, f.Limit)
+	}
+
+	rows, err := s.db.Query(query, args...)
+	if err != nil {
+		return nil, fmt.Errorf("failed to query audit events: %w", err)
+	}
+	return collectAuditEvents(rows)
+}
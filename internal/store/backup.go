@@ -0,0 +1,189 @@
+package store
+
+import (
+	"context"
+	"database/sql"
+	"fmt"
+	"os"
+	"path/filepath"
+
+	"modernc.org/sqlite"
+)
+
+// backupStepPages is the number of pages copied per backup step. Locks are
+// released between steps so writers are not blocked for the whole copy.
+const backupStepPages = 256
+
+// sqliteBackuper is implemented by the modernc driver connection.
+type sqliteBackuper interface {
+	NewBackup(dstURI string) (*sqlite.Backup, error)
+	NewRestore(srcURI string) (*sqlite.Backup, error)
+}
+
+// Backup writes a consistent copy of the database to destPath with the
+// SQLite online backup API, so it is safe while other processes use the
+// database. The copy is written next to destPath and renamed into place.
+func (s *SQLiteStore) Backup(ctx context.Context, destPath string) error {
+	if _, err := os.Stat(destPath); err == nil {
+		return fmt.Errorf("backup destination already exists: %s", destPath)
+	}
+	if err := os.MkdirAll(filepath.Dir(destPath), 0o755); err != nil {
+		return fmt.Errorf("failed to create backup directory: %w", err)
+	}
+
+	tmpPath := destPath + ".tmp"
+	_ = os.Remove(tmpPath)
+	err := s.withBackuper(ctx, func(b sqliteBackuper) (*sqlite.Backup, error) {
+		return b.NewBackup(tmpPath)
+	})
+	if err != nil {
+		_ = os.Remove(tmpPath)
+		return fmt.Errorf("failed to back up database: %w", err)
+	}
+	if err := os.Rename(tmpPath, destPath); err != nil {
+		_ = os.Remove(tmpPath)
+		return fmt.Errorf("failed to move backup into place: %w", err)
+	}
+	return nil
+}
+
+// Restore replaces the contents of the database with the backup at srcPath,
+// then applies any migrations the backup predates. The backup must pass an
+// integrity check and must not be from a newer schema.
+func (s *SQLiteStore) Restore(ctx context.Context, srcPath string) error {
+	if err := checkBackup(srcPath); err != nil {
+		return err
+	}
+	err := s.withBackuper(ctx, func(b sqliteBackuper) (*sqlite.Backup, error) {
+		return b.NewRestore(srcPath)
+	})
+	if err != nil {
+		return fmt.Errorf("failed to restore database: %w", err)
+	}
+	if err := s.migrate(); err != nil {
+		return fmt.Errorf("failed to migrate restored database: %w", err)
+	}
+	return nil
+}
+
+// DiscardedAuditEvents returns how many audit events a restore from srcPath
+// would discard: those recorded after the backup's newest event, or all of
+// them when the backup's log is not a prefix of the database's.
+func (s *SQLiteStore) DiscardedAuditEvents(ctx context.Context, srcPath string) (int, error) {
+	if err := checkBackup(srcPath); err != nil {
+		return 0, err
+	}
+	backup, err := sql.Open("sqlite", "file:"+srcPath+"?mode=ro")
+	if err != nil {
+		return 0, fmt.Errorf("failed to open backup: %w", err)
+	}
+	defer func() {
+		_ = backup.Close()
+	}()
+
+	// Backups from before the audit log have no events to match.
+	var tables int
+	if err := backup.QueryRowContext(ctx,
+		"SELECT COUNT(*) FROM sqlite_master WHERE type = 'table' AND name = 'audit_events'").Scan(&tables); err != nil {
+		return 0, fmt.Errorf("failed to read backup audit log: %w", err)
+	}
+	var last AuditEvent
+	if tables > 0 {
+		err := backup.QueryRowContext(ctx,
+			"SELECT id, actor, action, target, hash FROM audit_events ORDER BY id DESC LIMIT 1").
+			Scan(&last.ID, &last.Actor, &last.Action, &last.Target, &last.Hash)
+		if err != nil && err != sql.ErrNoRows {
+			return 0, fmt.Errorf("failed to read backup audit log: %w", err)
+		}
+	}
+
+	var total, after, matched int
+	if err := s.db.QueryRowContext(ctx, "SELECT COUNT(*) FROM audit_events").Scan(&total); err != nil {
+		return 0, fmt.Errorf("failed to count audit events: %w", err)
+	}
+	if last.ID == 0 {
+		return total, nil
+	}
+	if err := s.db.QueryRowContext(ctx,
+		"SELECT COUNT(*) FROM audit_events WHERE id = ? AND actor = ? AND action = ? AND target = ? AND hash = ?",
+		last.ID, last.Actor, last.Action, last.Target, last.Hash).Scan(&matched); err != nil {
+		return 0, fmt.Errorf("failed to match backup audit log: %w", err)
+	}
+	if matched == 0 {
+		return total, nil
+	}
+	if err := s.db.QueryRowContext(ctx, "SELECT COUNT(*) FROM audit_events WHERE id > ?", last.ID).Scan(&after); err != nil {
+		return 0, fmt.Errorf("failed to count audit events: %w", err)
+	}
+	return after, nil
+}
+
+// withBackuper runs the backup started by start to completion on a
+// dedicated connection.
+func (s *SQLiteStore) withBackuper(ctx context.Context, start func(sqliteBackuper) (*sqlite.Backup, error)) error {
+	conn, err := s.db.Conn(ctx)
+	if err != nil {
+		return err
+	}
+	defer func() {
+		_ = conn.Close()
+	}()
+
+	return conn.Raw(func(driverConn interface{}) error {
+		b, ok := driverConn.(sqliteBackuper)
+		if !ok {
+			return fmt.Errorf("database driver does not support online backup")
+		}
+		bk, err := start(b)
+		if err != nil {
+			return err
+		}
+		for {
+			if err := ctx.Err(); err != nil {
+				_ = bk.Finish()
+				return err
+			}
+			more, err := bk.Step(backupStepPages)
+			if err != nil {
+				_ = bk.Finish()
+				return err
+			}
+			if !more {
+				break
+			}
+		}
+		return bk.Finish()
+	})
+}
+
+// checkBackup verifies that path is an intact airgap database whose schema
+// this build understands.
+func checkBackup(path string) error {
+	if _, err := os.Stat(path); err != nil {
+		return fmt.Errorf("backup not readable: %w", err)
+	}
+	db, err := sql.Open("sqlite", "file:"+path+"?mode=ro")
+	if err != nil {
+		return fmt.Errorf("failed to open backup: %w", err)
+	}
+	defer func() {
+		_ = db.Close()
+	}()
+
+	var integrity string
+	if err := db.QueryRow("PRAGMA integrity_check").Scan(&integrity); err != nil {
+		return fmt.Errorf("failed to check backup integrity: %w", err)
+	}
+	if integrity != "ok" {
+		return fmt.Errorf("backup failed integrity check: %s", integrity)
+	}
+
+	var version int
+	if err := db.QueryRow("SELECT COALESCE(MAX(version), 0) FROM migrations").Scan(&version); err != nil {
+		return fmt.Errorf("backup is not an airgap database: %w", err)
+	}
+	if latest := latestMigration(); version > latest {
+		return fmt.Errorf("backup schema version %d is newer than this build (%d)", version, latest)
+	}
+	return nil
+}
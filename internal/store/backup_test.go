@@ -0,0 +1,181 @@
+package store
+
+import (
+	"context"
+	"log/slog"
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+	"time"
+)
+
+func newFileStore(t *testing.T, path string) *SQLiteStore {
+	t.Helper()
+	s, err := New(path, slog.New(slog.NewTextHandler(os.Stderr, nil)))
+	if err != nil {
+		t.Fatalf("New(%s) failed: %v", path, err)
+	}
+	t.Cleanup(func() {
+		_ = s.Close()
+	})
+	return s
+}
+
+func TestBackupAndRestore(t *testing.T) {
+	dir := t.TempDir()
+	s := newFileStore(t, filepath.Join(dir, "airgap.db"))
+	ctx := context.Background()
+
+	if err := s.CreateProviderConfig(&ProviderConfig{Name: "epel-9", Type: "epel", Enabled: true, ConfigJSON: "{}"}); err != nil {
+		t.Fatalf("CreateProviderConfig() failed: %v", err)
+	}
+	if err := s.CreateSyncRun(&SyncRun{Provider: "epel-9", StartTime: time.Now(), Status: "success"}); err != nil {
+		t.Fatalf("CreateSyncRun() failed: %v", err)
+	}
+
+	backupPath := filepath.Join(dir, "backups", "airgap-backup.db")
+	if err := s.Backup(ctx, backupPath); err != nil {
+		t.Fatalf("Backup() failed: %v", err)
+	}
+	if err := s.Backup(ctx, backupPath); err == nil || !strings.Contains(err.Error(), "already exists") {
+		t.Errorf("Backup() over existing file error = %v, want already exists", err)
+	}
+
+	// Change the live database, then restore the backup over it.
+	if err := s.CreateSyncRun(&SyncRun{Provider: "epel-9", StartTime: time.Now(), Status: "failed"}); err != nil {
+		t.Fatalf("CreateSyncRun() failed: %v", err)
+	}
+	if err := s.Restore(ctx, backupPath); err != nil {
+		t.Fatalf("Restore() failed: %v", err)
+	}
+
+	runs, err := s.ListSyncRuns("epel-9", 0)
+	if err != nil {
+		t.Fatalf("ListSyncRuns() failed: %v", err)
+	}
+	if len(runs) != 1 || runs[0].Status != "success" {
+		t.Errorf("runs after restore = %+v, want the single backed-up run", runs)
+	}
+	if _, err := s.GetProviderConfig("epel-9"); err != nil {
+		t.Errorf("GetProviderConfig() after restore failed: %v", err)
+	}
+}
+
+func TestDiscardedAuditEvents(t *testing.T) {
+	dir := t.TempDir()
+	s := newFileStore(t, filepath.Join(dir, "airgap.db"))
+	ctx := context.Background()
+
+	appendEvent := func(st *SQLiteStore, action string) {
+		t.Helper()
+		if err := st.AppendAuditEvent(&AuditEvent{Actor: "alice", Action: action, Outcome: "success"}, true); err != nil {
+			t.Fatalf("AppendAuditEvent() failed: %v", err)
+		}
+	}
+
+	appendEvent(s, "provider.create")
+	backupPath := filepath.Join(dir, "backup.db")
+	if err := s.Backup(ctx, backupPath); err != nil {
+		t.Fatalf("Backup() failed: %v", err)
+	}
+	if n, err := s.DiscardedAuditEvents(ctx, backupPath); err != nil || n != 0 {
+		t.Errorf("DiscardedAuditEvents() right after backup = %d, %v; want 0", n, err)
+	}
+
+	appendEvent(s, "provider.update")
+	appendEvent(s, "db.maintain")
+	if n, err := s.DiscardedAuditEvents(ctx, backupPath); err != nil || n != 2 {
+		t.Errorf("DiscardedAuditEvents() after two events = %d, %v; want 2", n, err)
+	}
+
+	// A backup of another database shares no chain with this one.
+	other := newFileStore(t, filepath.Join(dir, "other.db"))
+	appendEvent(other, "sync.run")
+	otherBackup := filepath.Join(dir, "other-backup.db")
+	if err := other.Backup(ctx, otherBackup); err != nil {
+		t.Fatalf("Backup() failed: %v", err)
+	}
+	if n, err := s.DiscardedAuditEvents(ctx, otherBackup); err != nil || n != 3 {
+		t.Errorf("DiscardedAuditEvents() for unrelated backup = %d, %v; want 3", n, err)
+	}
+}
+
+func TestRestoreRejectsInvalidBackups(t *testing.T) {
+	dir := t.TempDir()
+	s := newFileStore(t, filepath.Join(dir, "airgap.db"))
+	ctx := context.Background()
+
+	garbage := filepath.Join(dir, "garbage.db")
+	if err := os.WriteFile(garbage, []byte("not a database"), 0o644); err != nil {
+		t.Fatal(err)
+	}
+	if err := s.Restore(ctx, garbage); err == nil {
+		t.Error("Restore() of a non-database succeeded, want error")
+	}
+
+	newer := filepath.Join(dir, "newer.db")
+	future := newFileStore(t, newer)
+	if _, err := future.db.Exec("INSERT INTO migrations (version) VALUES (?)", latestMigration()+1); err != nil {
+		t.Fatal(err)
+	}
+	if err := s.Restore(ctx, newer); err == nil || !strings.Contains(err.Error(), "newer than this build") {
+		t.Errorf("Restore() of newer schema error = %v, want version error", err)
+	}
+
+	if err := s.Restore(ctx, filepath.Join(dir, "missing.db")); err == nil {
+		t.Error("Restore() of a missing file succeeded, want error")
+	}
+}
+
+func TestImportSyncRunIsIdempotent(t *testing.T) {
+	s := newTestStore(t)
+	run := &SyncRun{Provider: "epel-9", StartTime: time.Now(), Status: "partial", SourceHost: "high-side", UpstreamID: 42}
+	if err := s.ImportSyncRun(run); err != nil {
+		t.Fatalf("ImportSyncRun() failed: %v", err)
+	}
+	firstID := run.ID
+
+	again := &SyncRun{Provider: "epel-9", StartTime: run.StartTime, Status: "success", SourceHost: "high-side", UpstreamID: 42}
+	if err := s.ImportSyncRun(again); err != nil {
+		t.Fatalf("ImportSyncRun() again failed: %v", err)
+	}
+	if again.ID != firstID {
+		t.Errorf("re-imported run ID = %d, want %d", again.ID, firstID)
+	}
+
+	got, err := s.GetSyncRun(firstID)
+	if err != nil {
+		t.Fatalf("GetSyncRun() failed: %v", err)
+	}
+	if got.Status != "success" || got.SourceHost != "high-side" || got.UpstreamID != 42 {
+		t.Errorf("imported run = %+v", got)
+	}
+
+	if err := s.ImportSyncRun(&SyncRun{Provider: "epel-9", StartTime: time.Now()}); err == nil {
+		t.Error("ImportSyncRun() without source host succeeded, want error")
+	}
+}
+
+func TestUpsertUpstreamProviderConfig(t *testing.T) {
+	s := newTestStore(t)
+	pc := &UpstreamProviderConfig{SourceHost: "high-side", Name: "epel-9", Type: "epel", ConfigJSON: `{"a":1}`, ImportedAt: time.Now()}
+	if err := s.UpsertUpstreamProviderConfig(pc); err != nil {
+		t.Fatalf("UpsertUpstreamProviderConfig() failed: %v", err)
+	}
+	pc.ConfigJSON = `{"a":2}`
+	if err := s.UpsertUpstreamProviderConfig(pc); err != nil {
+		t.Fatalf("UpsertUpstreamProviderConfig() failed: %v", err)
+	}
+
+	got, err := s.GetUpstreamProviderConfig("high-side", "epel-9")
+	if err != nil {
+		t.Fatalf("GetUpstreamProviderConfig() failed: %v", err)
+	}
+	if got.ConfigJSON != `{"a":2}` || got.Type != "epel" {
+		t.Errorf("upstream config = %+v", got)
+	}
+	if _, err := s.GetUpstreamProviderConfig("high-side", "missing"); err == nil {
+		t.Error("GetUpstreamProviderConfig() for missing provider succeeded, want error")
+	}
+}
@@ -0,0 +1,670 @@
+package store
+
+import (
+	"database/sql"
+	"fmt"
+	"log/slog"
+	"os"
+	"reflect"
+	"sort"
+	"strings"
+	"sync/atomic"
+	"testing"
+	"time"
+)
+
+// postgresDSNEnv names the connection string used by the PostgreSQL
+// conformance run; `make test-postgres` sets it against a throwaway container.
+const postgresDSNEnv = "AIRGAP_TEST_POSTGRES_DSN"
+
+func TestSQLiteConformance(t *testing.T) {
+	runConformance(t, func(t *testing.T) Store {
+		return newTestStore(t)
+	})
+}
+
+var postgresSchemaSeq atomic.Int64
+
+func TestPostgresConformance(t *testing.T) {
+	dsn := os.Getenv(postgresDSNEnv)
+	if dsn == "" {
+		t.Skipf("%s not set", postgresDSNEnv)
+	}
+
+	runConformance(t, func(t *testing.T) Store {
+		t.Helper()
+		// Each test gets its own schema so tests start from an empty database.
+		schema := fmt.Sprintf("airgap_test_%d_%d", os.Getpid(), postgresSchemaSeq.Add(1))
+		admin, err := sql.Open("pgx", dsn)
+		if err != nil {
+			t.Fatalf("open admin connection: %v", err)
+		}
+		t.Cleanup(func() {
+			_, _ = admin.Exec("DROP SCHEMA IF EXISTS " + schema + " CASCADE")
+			_ = admin.Close()
+		})
+		if _, err := admin.Exec("CREATE SCHEMA " + schema); err != nil {
+			t.Fatalf("create schema: %v", err)
+		}
+
+		s, err := NewPostgres(withSearchPath(dsn, schema), slog.New(slog.NewTextHandler(os.Stderr, nil)))
+		if err != nil {
+			t.Fatalf("NewPostgres() failed: %v", err)
+		}
+		t.Cleanup(func() {
+			_ = s.Close()
+		})
+		return s
+	})
+}
+
+// withSearchPath adds a search_path runtime parameter to dsn, which may be a
+// URL or a keyword/value connection string.
+func withSearchPath(dsn, schema string) string {
+	if strings.Contains(dsn, "://") {
+		sep := "?"
+		if strings.Contains(dsn, "?") {
+			sep = "&"
+		}
+		return dsn + sep + "search_path=" + schema
+	}
+	return dsn + " search_path=" + schema
+}
+
+// runConformance checks the behavior every Store implementation must share.
+// newStore returns an empty store that is closed when the test ends.
+func runConformance(t *testing.T, newStore func(t *testing.T) Store) {
+	// Whole seconds survive every backend's timestamp precision.
+	base := time.Now().UTC().Truncate(time.Second)
+
+	t.Run("SyncRuns", func(t *testing.T) {
+		s := newStore(t)
+		for i, provider := range []string{"epel", "epel", "rhcos"} {
+			run := &SyncRun{Provider: provider, StartTime: base.Add(time.Duration(i) * time.Minute), Status: "running"}
+			if err := s.CreateSyncRun(run); err != nil {
+				t.Fatalf("CreateSyncRun() failed: %v", err)
+			}
+			if run.ID == 0 {
+				t.Fatal("CreateSyncRun() did not set ID")
+			}
+		}
+
+		runs, err := s.ListSyncRuns("epel", 0)
+		if err != nil {
+			t.Fatalf("ListSyncRuns() failed: %v", err)
+		}
+		if len(runs) != 2 {
+			t.Fatalf("ListSyncRuns(epel) = %d runs, want 2", len(runs))
+		}
+		if !runs[0].StartTime.After(runs[1].StartTime) {
+			t.Error("ListSyncRuns() not ordered newest first")
+		}
+		if all, _ := s.ListSyncRuns("", 1); len(all) != 1 {
+			t.Errorf("ListSyncRuns(limit 1) = %d runs, want 1", len(all))
+		}
+
+		run := runs[0]
+		run.Status = "success"
+		run.EndTime = base.Add(time.Hour)
+		run.FilesDownloaded = 7
+		run.BytesTransferred = 1 << 40
+		run.SignatureStatus = "verified"
+		if err := s.UpdateSyncRun(&run); err != nil {
+			t.Fatalf("UpdateSyncRun() failed: %v", err)
+		}
+		got, err := s.GetSyncRun(run.ID)
+		if err != nil {
+			t.Fatalf("GetSyncRun() failed: %v", err)
+		}
+		if got.Status != "success" || got.FilesDownloaded != 7 || got.BytesTransferred != 1<<40 ||
+			got.SignatureStatus != "verified" || !got.EndTime.Equal(run.EndTime) {
+			t.Errorf("GetSyncRun() = %+v, want updated run", got)
+		}
+
+		if _, err := s.GetSyncRun(99999); err == nil {
+			t.Error("GetSyncRun(missing) should fail")
+		}
+		if err := s.UpdateSyncRun(&SyncRun{ID: 99999}); err == nil {
+			t.Error("UpdateSyncRun(missing) should fail")
+		}
+	})
+
+	t.Run("ImportSyncRun", func(t *testing.T) {
+		s := newStore(t)
+		run := &SyncRun{Provider: "epel", StartTime: base, Status: "success", SourceHost: "connected", UpstreamID: 42}
+		if err := s.ImportSyncRun(run); err != nil {
+			t.Fatalf("ImportSyncRun() failed: %v", err)
+		}
+		again := &SyncRun{Provider: "epel", StartTime: base, Status: "failed", SourceHost: "connected", UpstreamID: 42}
+		if err := s.ImportSyncRun(again); err != nil {
+			t.Fatalf("ImportSyncRun() again failed: %v", err)
+		}
+		if again.ID != run.ID {
+			t.Errorf("re-import ID = %d, want %d", again.ID, run.ID)
+		}
+		got, err := s.GetSyncRun(run.ID)
+		if err != nil {
+			t.Fatalf("GetSyncRun() failed: %v", err)
+		}
+		if got.Status != "failed" || got.SourceHost != "connected" || got.UpstreamID != 42 {
+			t.Errorf("GetSyncRun() = %+v, want re-imported run", got)
+		}
+		if err := s.ImportSyncRun(&SyncRun{Provider: "epel", StartTime: base}); err == nil {
+			t.Error("ImportSyncRun() without source host should fail")
+		}
+	})
+
+	t.Run("FileRecords", func(t *testing.T) {
+		s := newStore(t)
+		for _, path := range []string{"b.rpm", "a.rpm"} {
+			rec := &FileRecord{Provider: "epel", Path: path, Size: 100, SHA256: "old", LastModified: base, LastVerified: base}
+			if err := s.UpsertFileRecord(rec); err != nil {
+				t.Fatalf("UpsertFileRecord() failed: %v", err)
+			}
+			if rec.ID == 0 {
+				t.Fatal("UpsertFileRecord() did not set ID")
+			}
+		}
+		if err := s.UpsertFileRecord(&FileRecord{
+			Provider: "epel", Path: "a.rpm", Size: 250, SHA256: "new", LastModified: base, LastVerified: base,
+			SourceURL: "https://mirror/a.rpm", MirrorHost: "mirror", ETag: `"e"`,
+			UpstreamLastModified: "Tue, 01 Jul 2025 10:00:00 GMT", ResponseTimeMS: 12,
+		}); err != nil {
+			t.Fatalf("UpsertFileRecord() update failed: %v", err)
+		}
+
+		recs, err := s.ListFileRecords("epel")
+		if err != nil {
+			t.Fatalf("ListFileRecords() failed: %v", err)
+		}
+		if len(recs) != 2 || recs[0].Path != "a.rpm" {
+			t.Fatalf("ListFileRecords() = %+v, want a.rpm and b.rpm in order", recs)
+		}
+		got, err := s.GetFileRecord("epel", "a.rpm")
+		if err != nil {
+			t.Fatalf("GetFileRecord() failed: %v", err)
+		}
+		if got.SHA256 != "new" || got.Size != 250 || !got.LastVerified.Equal(base) {
+			t.Errorf("GetFileRecord() = %+v, want upserted values", got)
+		}
+		if got.SourceURL != "https://mirror/a.rpm" || got.MirrorHost != "mirror" || got.ETag != `"e"` ||
+			got.UpstreamLastModified == "" || got.ResponseTimeMS != 12 {
+			t.Errorf("GetFileRecord() = %+v, want upserted provenance", got)
+		}
+
+		if err := s.UpsertFileRecord(&FileRecord{Provider: "rhcos", Path: "a.rpm", LastModified: base, LastVerified: base}); err != nil {
+			t.Fatalf("UpsertFileRecord() failed: %v", err)
+		}
+		for path, want := range map[string]int{"a.rpm": 2, "epel/a.rpm": 1, "epel/b.rpm": 1, "missing": 0} {
+			found, err := s.FindFileRecords(path)
+			if err != nil {
+				t.Fatalf("FindFileRecords(%q) failed: %v", path, err)
+			}
+			if len(found) != want {
+				t.Errorf("FindFileRecords(%q) = %d records, want %d", path, len(found), want)
+			}
+		}
+
+		if n, err := s.CountFileRecords("epel"); err != nil || n != 2 {
+			t.Errorf("CountFileRecords() = %d, %v; want 2", n, err)
+		}
+		if total, err := s.SumFileSize("epel"); err != nil || total != 350 {
+			t.Errorf("SumFileSize() = %d, %v; want 350", total, err)
+		}
+		if total, err := s.SumFileSize("none"); err != nil || total != 0 {
+			t.Errorf("SumFileSize(empty) = %d, %v; want 0", total, err)
+		}
+
+		if err := s.DeleteFileRecord("epel", "a.rpm"); err != nil {
+			t.Fatalf("DeleteFileRecord() failed: %v", err)
+		}
+		if _, err := s.GetFileRecord("epel", "a.rpm"); err == nil {
+			t.Error("GetFileRecord() after delete should fail")
+		}
+		if err := s.DeleteFileRecord("epel", "a.rpm"); err == nil {
+			t.Error("DeleteFileRecord(missing) should fail")
+		}
+	})
+
+	t.Run("FailedFiles", func(t *testing.T) {
+		s := newStore(t)
+		rec := &FailedFileRecord{
+			Provider: "epel", FilePath: "x.rpm", URL: "https://mirror/x.rpm", ExpectedSize: 5 << 30,
+			Error: "timeout", RetryCount: 1, FirstFailure: base, LastFailure: base,
+		}
+		if err := s.AddFailedFile(rec); err != nil {
+			t.Fatalf("AddFailedFile() failed: %v", err)
+		}
+		// A repeat failure updates the unresolved record instead of adding one.
+		if err := s.AddFailedFile(&FailedFileRecord{
+			Provider: "epel", FilePath: "x.rpm", Error: "404", FirstFailure: base, LastFailure: base.Add(time.Minute),
+		}); err != nil {
+			t.Fatalf("AddFailedFile() repeat failed: %v", err)
+		}
+
+		failed, err := s.ListFailedFiles("epel")
+		if err != nil {
+			t.Fatalf("ListFailedFiles() failed: %v", err)
+		}
+		if len(failed) != 1 {
+			t.Fatalf("ListFailedFiles() = %d records, want 1", len(failed))
+		}
+		got := failed[0]
+		if got.Error != "404" || got.RetryCount != 2 || got.URL != "https://mirror/x.rpm" || got.ExpectedSize != 5<<30 {
+			t.Errorf("ListFailedFiles()[0] = %+v, want merged record", got)
+		}
+
+		if err := s.IncrementFailedRetry(got.ID); err != nil {
+			t.Fatalf("IncrementFailedRetry() failed: %v", err)
+		}
+		if failed, _ := s.ListFailedFiles("epel"); len(failed) != 1 || failed[0].RetryCount != 3 {
+			t.Errorf("RetryCount after increment = %+v, want 3", failed)
+		}
+		if err := s.ResolveFailedFile(got.ID); err != nil {
+			t.Fatalf("ResolveFailedFile() failed: %v", err)
+		}
+		if failed, _ := s.ListFailedFiles("epel"); len(failed) != 0 {
+			t.Errorf("ListFailedFiles() after resolve = %d, want 0", len(failed))
+		}
+		if err := s.ResolveFailedFile(99999); err == nil {
+			t.Error("ResolveFailedFile(missing) should fail")
+		}
+	})
+
+	t.Run("Jobs", func(t *testing.T) {
+		s := newStore(t)
+		later := &Job{Type: "sync", Provider: "epel", Status: "scheduled", NextRun: base.Add(time.Hour), CreatedAt: base, UpdatedAt: base}
+		sooner := &Job{Type: "sync", Provider: "rhcos", Status: "scheduled", NextRun: base, CreatedAt: base, UpdatedAt: base}
+		for _, job := range []*Job{later, sooner} {
+			if err := s.CreateJob(job); err != nil {
+				t.Fatalf("CreateJob() failed: %v", err)
+			}
+		}
+		later.Status = "running"
+		if err := s.UpdateJob(later); err != nil {
+			t.Fatalf("UpdateJob() failed: %v", err)
+		}
+
+		jobs, err := s.ListJobs("", 0)
+		if err != nil {
+			t.Fatalf("ListJobs() failed: %v", err)
+		}
+		if len(jobs) != 2 || jobs[0].ID != sooner.ID {
+			t.Errorf("ListJobs() = %+v, want soonest next_run first", jobs)
+		}
+		if running, _ := s.ListJobs("running", 10); len(running) != 1 || running[0].ID != later.ID {
+			t.Errorf("ListJobs(running) = %+v, want the updated job", running)
+		}
+		if err := s.UpdateJob(&Job{ID: 99999}); err == nil {
+			t.Error("UpdateJob(missing) should fail")
+		}
+	})
+
+	t.Run("Transfers", func(t *testing.T) {
+		s := newStore(t)
+		tr := &Transfer{Direction: "export", Path: "/mnt/usb", Providers: "epel", Manifest: `{"version":"1.0"}`, Status: "running", StartTime: base}
+		if err := s.CreateTransfer(tr); err != nil {
+			t.Fatalf("CreateTransfer() failed: %v", err)
+		}
+		tr.Status = "completed"
+		tr.ArchiveCount = 1
+		tr.EndTime = base.Add(time.Minute)
+		if err := s.UpdateTransfer(tr); err != nil {
+			t.Fatalf("UpdateTransfer() failed: %v", err)
+		}
+		transfers, err := s.ListTransfers(0)
+		if err != nil {
+			t.Fatalf("ListTransfers() failed: %v", err)
+		}
+		if len(transfers) != 1 || transfers[0].Status != "completed" || transfers[0].ArchiveCount != 1 {
+			t.Errorf("ListTransfers() = %+v, want the updated transfer", transfers)
+		}
+		got, err := s.GetTransfer(tr.ID)
+		if err != nil || got.Manifest != `{"version":"1.0"}` || got.Status != "completed" {
+			t.Errorf("GetTransfer() = %+v, %v; want the transfer with its manifest", got, err)
+		}
+		if _, err := s.GetTransfer(99999); err == nil {
+			t.Error("GetTransfer(missing) should fail")
+		}
+
+		archive := &TransferArchive{TransferID: tr.ID, ArchiveName: "airgap-001.tar.zst", SHA256: "abc", Size: 10}
+		if err := s.CreateTransferArchive(archive); err != nil {
+			t.Fatalf("CreateTransferArchive() failed: %v", err)
+		}
+		if ok, err := s.IsArchiveValidated("/mnt/usb", "airgap-001.tar.zst", "abc"); err != nil || ok {
+			t.Errorf("IsArchiveValidated() before validation = %v, %v; want false", ok, err)
+		}
+		if err := s.MarkArchiveValidated(archive.ID); err != nil {
+			t.Fatalf("MarkArchiveValidated() failed: %v", err)
+		}
+		if ok, err := s.IsArchiveValidated("/mnt/usb", "airgap-001.tar.zst", "abc"); err != nil || !ok {
+			t.Errorf("IsArchiveValidated() = %v, %v; want true", ok, err)
+		}
+		archives, err := s.ListTransferArchives(tr.ID)
+		if err != nil {
+			t.Fatalf("ListTransferArchives() failed: %v", err)
+		}
+		if len(archives) != 1 || !archives[0].Validated {
+			t.Errorf("ListTransferArchives() = %+v, want one validated archive", archives)
+		}
+		if err := s.MarkArchiveValidated(99999); err == nil {
+			t.Error("MarkArchiveValidated(missing) should fail")
+		}
+	})
+
+	t.Run("ProviderConfigs", func(t *testing.T) {
+		s := newStore(t)
+		yaml := map[string]map[string]interface{}{
+			"epel":      {"enabled": true},
+			"my-mirror": {"enabled": false},
+		}
+		if err := s.SeedProviderConfigs(yaml); err != nil {
+			t.Fatalf("SeedProviderConfigs() failed: %v", err)
+		}
+		// Seeding a populated table is a no-op.
+		if err := s.SeedProviderConfigs(map[string]map[string]interface{}{"rhcos": {}}); err != nil {
+			t.Fatalf("SeedProviderConfigs() again failed: %v", err)
+		}
+		if n, err := s.CountProviderConfigs(); err != nil || n != 2 {
+			t.Fatalf("CountProviderConfigs() = %d, %v; want 2", n, err)
+		}
+
+		custom, err := s.GetProviderConfig("my-mirror")
+		if err != nil {
+			t.Fatalf("GetProviderConfig() failed: %v", err)
+		}
+		if custom.Type != "custom_files" || custom.Enabled {
+			t.Errorf("seeded my-mirror = %+v, want disabled custom_files", custom)
+		}
+
+		if err := s.ToggleProviderConfig("my-mirror"); err != nil {
+			t.Fatalf("ToggleProviderConfig() failed: %v", err)
+		}
+		custom.ConfigJSON = `{"enabled":true,"files":[]}`
+		custom.Enabled = true
+		if err := s.UpdateProviderConfig(custom); err != nil {
+			t.Fatalf("UpdateProviderConfig() failed: %v", err)
+		}
+		configs, err := s.ListProviderConfigs()
+		if err != nil {
+			t.Fatalf("ListProviderConfigs() failed: %v", err)
+		}
+		if len(configs) != 2 || configs[0].Name != "epel" || !configs[1].Enabled || configs[1].ConfigJSON != custom.ConfigJSON {
+			t.Errorf("ListProviderConfigs() = %+v, want epel then updated my-mirror", configs)
+		}
+
+		if err := s.CreateProviderConfig(&ProviderConfig{Name: "epel", Type: "epel", ConfigJSON: "{}"}); err == nil {
+			t.Error("CreateProviderConfig() with a duplicate name should fail")
+		}
+		if err := s.DeleteProviderConfig("epel"); err != nil {
+			t.Fatalf("DeleteProviderConfig() failed: %v", err)
+		}
+		if _, err := s.GetProviderConfig("epel"); err == nil {
+			t.Error("GetProviderConfig() after delete should fail")
+		}
+		for name, fn := range map[string]func(string) error{
+			"DeleteProviderConfig": s.DeleteProviderConfig,
+			"ToggleProviderConfig": s.ToggleProviderConfig,
+		} {
+			if err := fn("missing"); err == nil {
+				t.Errorf("%s(missing) should fail", name)
+			}
+		}
+	})
+
+	t.Run("ProviderConfigRevisions", func(t *testing.T) {
+		s := newStore(t)
+		if err := s.SeedProviderConfigs(map[string]map[string]interface{}{"epel": {"enabled": true}}); err != nil {
+			t.Fatalf("SeedProviderConfigs() failed: %v", err)
+		}
+
+		pc, err := s.GetProviderConfig("epel")
+		if err != nil {
+			t.Fatalf("GetProviderConfig() failed: %v", err)
+		}
+		pc.ConfigJSON = `{"repos":[]}`
+		pc.Enabled = false
+		if err := s.UpdateProviderConfig(pc); err != nil {
+			t.Fatalf("UpdateProviderConfig() failed: %v", err)
+		}
+		rev, err := s.AddProviderConfigRevision("epel", "alice", "drop repos")
+		if err != nil {
+			t.Fatalf("AddProviderConfigRevision() failed: %v", err)
+		}
+		if rev.Revision != 2 || rev.Author != "alice" || rev.Comment != "drop repos" ||
+			rev.ConfigJSON != pc.ConfigJSON || rev.Enabled || rev.CreatedAt.IsZero() {
+			t.Errorf("AddProviderConfigRevision() = %+v", rev)
+		}
+
+		revs, err := s.ListProviderConfigRevisions("epel")
+		if err != nil {
+			t.Fatalf("ListProviderConfigRevisions() failed: %v", err)
+		}
+		if len(revs) != 2 || revs[0].Revision != 2 || revs[1].Revision != 1 || revs[1].Author != "system" || !revs[1].Enabled {
+			t.Fatalf("ListProviderConfigRevisions() = %+v, want seeded revision 1 and revision 2", revs)
+		}
+
+		got, err := s.GetProviderConfigRevision("epel", 1)
+		if err != nil {
+			t.Fatalf("GetProviderConfigRevision() failed: %v", err)
+		}
+		if got.ConfigJSON != revs[1].ConfigJSON {
+			t.Errorf("GetProviderConfigRevision(1) = %+v, want %+v", got, revs[1])
+		}
+		if _, err := s.GetProviderConfigRevision("epel", 3); err == nil {
+			t.Error("GetProviderConfigRevision(missing) should fail")
+		}
+		if _, err := s.AddProviderConfigRevision("missing", "alice", ""); err == nil {
+			t.Error("AddProviderConfigRevision(missing) should fail")
+		}
+
+		// History outlives the provider and continues if it is recreated.
+		if err := s.DeleteProviderConfig("epel"); err != nil {
+			t.Fatalf("DeleteProviderConfig() failed: %v", err)
+		}
+		if err := s.CreateProviderConfig(&ProviderConfig{Name: "epel", Type: "epel", ConfigJSON: "{}"}); err != nil {
+			t.Fatalf("CreateProviderConfig() failed: %v", err)
+		}
+		if rev, err := s.AddProviderConfigRevision("epel", "bob", ""); err != nil || rev.Revision != 3 {
+			t.Errorf("AddProviderConfigRevision() after recreate = %+v, %v; want revision 3", rev, err)
+		}
+	})
+
+	t.Run("UpstreamProviderConfigs", func(t *testing.T) {
+		s := newStore(t)
+		for _, cfg := range []string{`{"v":1}`, `{"v":2}`} {
+			if err := s.UpsertUpstreamProviderConfig(&UpstreamProviderConfig{
+				SourceHost: "connected", Name: "epel", Type: "epel", ConfigJSON: cfg, ImportedAt: base,
+			}); err != nil {
+				t.Fatalf("UpsertUpstreamProviderConfig() failed: %v", err)
+			}
+		}
+		got, err := s.GetUpstreamProviderConfig("connected", "epel")
+		if err != nil {
+			t.Fatalf("GetUpstreamProviderConfig() failed: %v", err)
+		}
+		if got.ConfigJSON != `{"v":2}` || !got.ImportedAt.Equal(base) {
+			t.Errorf("GetUpstreamProviderConfig() = %+v, want latest upsert", got)
+		}
+		if _, err := s.GetUpstreamProviderConfig("other", "epel"); err == nil {
+			t.Error("GetUpstreamProviderConfig(missing) should fail")
+		}
+	})
+
+	t.Run("Search", func(t *testing.T) {
+		s := newStore(t)
+		if err := s.ReplaceSearchEntries("epel", []SearchEntry{
+			{Type: "rpm", Name: "zsh", Version: "5.8-9.el9", Arch: "x86_64", Path: "epel/9/Packages/z/zsh-5.8-9.el9.x86_64.rpm"},
+			{Type: "rpm", Name: "zstd", Version: "1.5.5-1.el9", Arch: "x86_64", Path: "epel/9/Packages/z/zstd-1.5.5-1.el9.x86_64.rpm"},
+		}); err != nil {
+			t.Fatalf("ReplaceSearchEntries(epel) failed: %v", err)
+		}
+		if err := s.ReplaceSearchEntries("ocp", []SearchEntry{
+			{Type: "ocp_binary", Name: "openshift-client-linux.tar.gz", Version: "4.17.3", Path: "4.17.3/openshift-client-linux.tar.gz"},
+			{Type: "ocp_binary", Name: "openshift-client-linux.tar.gz", Version: "4.17.10", Path: "4.17.10/openshift-client-linux.tar.gz"},
+			{Type: "ocp_binary", Name: "openshift-client-linux.tar.gz", Version: "4.1.0", Path: "4.1.0/openshift-client-linux.tar.gz"},
+		}); err != nil {
+			t.Fatalf("ReplaceSearchEntries(ocp) failed: %v", err)
+		}
+
+		names := func(entries []SearchEntry) []string {
+			var out []string
+			for _, e := range entries {
+				out = append(out, e.Name+" "+e.Version)
+			}
+			sort.Strings(out)
+			return out
+		}
+		tests := []struct {
+			query SearchQuery
+			want  []string
+		}{
+			{SearchQuery{Text: "zs"}, []string{"zsh 5.8-9.el9", "zstd 1.5.5-1.el9"}},
+			{SearchQuery{Text: "zsh-5.8"}, []string{"zsh 5.8-9.el9"}},
+			{SearchQuery{Text: "ZSTD"}, []string{"zstd 1.5.5-1.el9"}},
+			{SearchQuery{Text: "openshift client", Version: "4.17"}, []string{
+				"openshift-client-linux.tar.gz 4.17.10", "openshift-client-linux.tar.gz 4.17.3",
+			}},
+			{SearchQuery{Provider: "epel", Version: "5.8"}, []string{"zsh 5.8-9.el9"}},
+			{SearchQuery{Type: "rpm", Limit: 1}, []string{"zsh 5.8-9.el9"}},
+			{SearchQuery{Text: "nothing"}, nil},
+		}
+		for _, tt := range tests {
+			got, err := s.Search(tt.query)
+			if err != nil {
+				t.Fatalf("Search(%+v) failed: %v", tt.query, err)
+			}
+			if g := names(got); !reflect.DeepEqual(g, tt.want) {
+				t.Errorf("Search(%+v) = %v, want %v", tt.query, g, tt.want)
+			}
+		}
+
+		if err := s.ReplaceSearchEntries("epel", nil); err != nil {
+			t.Fatalf("ReplaceSearchEntries(epel, nil) failed: %v", err)
+		}
+		if got, err := s.Search(SearchQuery{Provider: "epel"}); err != nil || len(got) != 0 {
+			t.Errorf("Search(epel) after clearing = %v, %v; want none", got, err)
+		}
+		if got, err := s.Search(SearchQuery{Provider: "ocp"}); err != nil || len(got) != 3 {
+			t.Errorf("Search(ocp) = %d entries, %v; want 3", len(got), err)
+		}
+	})
+
+	t.Run("AuditLog", func(t *testing.T) {
+		s := newStore(t)
+		base := time.Date(2026, 3, 1, 12, 0, 0, 123456789, time.UTC)
+
+		events := []AuditEvent{
+			{Time: base, Actor: "alice", Source: "api", Action: "provider.create", Target: "epel", After: `{"enabled":true}`, Outcome: "success"},
+			{Time: base.Add(time.Minute), Actor: "bob", Source: "cli", Action: "sync.start", Target: "epel", Outcome: "failure", Detail: "boom"},
+			{Time: base.Add(2 * time.Minute), Actor: "alice", Source: "api", Action: "provider.update", Target: "ocp", Outcome: "success"},
+		}
+		for i := range events {
+			if err := s.AppendAuditEvent(&events[i], true); err != nil {
+				t.Fatalf("AppendAuditEvent(%d) failed: %v", i, err)
+			}
+			if events[i].ID == 0 || events[i].Hash == "" {
+				t.Fatalf("event %d: ID = %d, Hash = %q; want both set", i, events[i].ID, events[i].Hash)
+			}
+		}
+		if events[0].PrevHash != "" || events[1].PrevHash != events[0].Hash || events[2].PrevHash != events[1].Hash {
+			t.Errorf("events are not chained: %+v", events)
+		}
+
+		all, err := s.ListAuditEvents(AuditFilter{})
+		if err != nil {
+			t.Fatalf("ListAuditEvents failed: %v", err)
+		}
+		if len(all) != 3 || all[0].ID != events[2].ID {
+			t.Fatalf("ListAuditEvents = %d events starting at %d, want 3 newest first", len(all), all[0].ID)
+		}
+		for _, e := range all {
+			if e.Hash != e.ComputeHash() {
+				t.Errorf("event %d hash does not verify after round trip", e.ID)
+			}
+		}
+		if !all[2].Time.Equal(base.Truncate(time.Microsecond)) || all[2].After != `{"enabled":true}` {
+			t.Errorf("first event = %+v", all[2])
+		}
+
+		tests := []struct {
+			filter AuditFilter
+			want   int
+		}{
+			{AuditFilter{Actor: "alice"}, 2},
+			{AuditFilter{Action: "provider."}, 2},
+			{AuditFilter{Action: "provider"}, 0},
+			{AuditFilter{Target: "epel", Outcome: "failure"}, 1},
+			{AuditFilter{Since: base.Add(30 * time.Second)}, 2},
+			{AuditFilter{Until: base.Add(30 * time.Second)}, 1},
+			{AuditFilter{Limit: 1}, 1},
+		}
+		for _, tt := range tests {
+			got, err := s.ListAuditEvents(tt.filter)
+			if err != nil {
+				t.Fatalf("ListAuditEvents(%+v) failed: %v", tt.filter, err)
+			}
+			if len(got) != tt.want {
+				t.Errorf("ListAuditEvents(%+v) = %d events, want %d", tt.filter, len(got), tt.want)
+			}
+		}
+
+		var db *sql.DB
+		switch st := s.(type) {
+		case *SQLiteStore:
+			db = st.db
+		case *PostgresStore:
+			db = st.db
+		}
+		if _, err := db.Exec("UPDATE audit_events SET actor = 'mallory'"); err == nil {
+			t.Error("UPDATE audit_events succeeded, want append-only error")
+		}
+		if _, err := db.Exec("DELETE FROM audit_events"); err == nil {
+			t.Error("DELETE FROM audit_events succeeded, want append-only error")
+		}
+	})
+
+	t.Run("Maintenance", func(t *testing.T) {
+		s := newStore(t)
+		old := base.Add(-40 * 24 * time.Hour)
+		for i := 0; i < 3; i++ {
+			if err := s.CreateSyncRun(&SyncRun{Provider: "epel", StartTime: old.Add(time.Duration(i) * time.Hour), Status: "success"}); err != nil {
+				t.Fatalf("CreateSyncRun() failed: %v", err)
+			}
+		}
+		tr := &Transfer{Direction: "export", Status: "completed", StartTime: old, EndTime: old}
+		if err := s.CreateTransfer(tr); err != nil {
+			t.Fatalf("CreateTransfer() failed: %v", err)
+		}
+		if err := s.CreateTransferArchive(&TransferArchive{TransferID: tr.ID, ArchiveName: "a.tar.zst"}); err != nil {
+			t.Fatalf("CreateTransferArchive() failed: %v", err)
+		}
+		if err := s.CreateJob(&Job{Type: "sync", Status: "completed", CreatedAt: old, UpdatedAt: old}); err != nil {
+			t.Fatalf("CreateJob() failed: %v", err)
+		}
+		if err := s.AddFailedFile(&FailedFileRecord{Provider: "epel", FilePath: "x.rpm", FirstFailure: old, LastFailure: old, Resolved: true}); err != nil {
+			t.Fatalf("AddFailedFile() failed: %v", err)
+		}
+
+		result, err := s.Prune(RetentionPolicy{
+			SyncRunsPerProvider: 1,
+			ResolvedFailuresAge: 30 * 24 * time.Hour,
+			TransfersAge:        30 * 24 * time.Hour,
+			JobsAge:             30 * 24 * time.Hour,
+		}, base)
+		if err != nil {
+			t.Fatalf("Prune() failed: %v", err)
+		}
+		want := PruneResult{SyncRuns: 2, FailedFiles: 1, Transfers: 1, TransferArchives: 1, Jobs: 1}
+		if *result != want {
+			t.Errorf("Prune() = %+v, want %+v", *result, want)
+		}
+
+		if size, err := s.Size(); err != nil || size <= 0 {
+			t.Errorf("Size() = %d, %v; want > 0", size, err)
+		}
+		if err := s.Optimize(); err != nil {
+			t.Errorf("Optimize() failed: %v", err)
+		}
+	})
+}
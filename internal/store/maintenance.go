@@ -0,0 +1,173 @@
+package store
+
+import (
+	"database/sql"
+	"fmt"
+	"strings"
+	"time"
+)
+
+// RetentionPolicy selects the history Prune deletes. Zero values keep
+// everything.
+type RetentionPolicy struct {
+	SyncRunsPerProvider int           // newest runs kept per provider
+	ResolvedFailuresAge time.Duration // resolved failed files, by last failure
+	TransfersAge        time.Duration // finished transfers, by start time
+	JobsAge             time.Duration // completed or failed jobs, by last update
+}
+
+// PruneResult counts the rows Prune deleted.
+type PruneResult struct {
+	SyncRuns         int64
+	FailedFiles      int64
+	Transfers        int64
+	TransferArchives int64
+	Jobs             int64
+}
+
+// Total returns the number of deleted rows.
+func (r *PruneResult) Total() int64 {
+	return r.SyncRuns + r.FailedFiles + r.Transfers + r.TransferArchives + r.Jobs
+}
+
+// Prune deletes history outside policy as of now in one transaction. Running
+// sync runs and runs still referenced by file records are kept.
+func (s *SQLiteStore) Prune(policy RetentionPolicy, now time.Time) (*PruneResult, error) {
+	tx, err := s.db.Begin()
+	if err != nil {
+		return nil, fmt.Errorf("failed to begin transaction: %w", err)
+	}
+	defer func() {
+		_ = tx.Rollback()
+	}()
+
+	result := &PruneResult{}
+
+	if policy.SyncRunsPerProvider > 0 {
+		res, err := tx.Exec(`
+			DELETE FROM sync_runs WHERE id IN (
+				SELECT id FROM (
+					SELECT id, status, ROW_NUMBER() OVER (
+						PARTITION BY provider ORDER BY start_time DESC, id DESC
+					) AS rn FROM sync_runs
+				) WHERE rn > ? AND status != 'running'
+			) AND id NOT IN (
+				SELECT sync_run_id FROM file_records WHERE sync_run_id IS NOT NULL
+			)
+		`, policy.SyncRunsPerProvider)
+		if err != nil {
+			return nil, fmt.Errorf("failed to prune sync runs: %w", err)
+		}
+		result.SyncRuns, _ = res.RowsAffected()
+	}
+
+	// Timestamps are compared in Go: the driver stores them as text with
+	// the writer's zone, which does not sort reliably in SQL.
+	if policy.ResolvedFailuresAge > 0 {
+		ids, err := selectOlderThan(tx, `SELECT id, last_failure FROM failed_files WHERE resolved = 1`, now.Add(-policy.ResolvedFailuresAge))
+		if err != nil {
+			return nil, fmt.Errorf("failed to select resolved failures: %w", err)
+		}
+		if result.FailedFiles, err = deleteIDs(tx, "failed_files", "id", ids); err != nil {
+			return nil, fmt.Errorf("failed to prune failed files: %w", err)
+		}
+	}
+
+	if policy.TransfersAge > 0 {
+		ids, err := selectOlderThan(tx, `SELECT id, start_time FROM transfers WHERE status != 'running'`, now.Add(-policy.TransfersAge))
+		if err != nil {
+			return nil, fmt.Errorf("failed to select transfers: %w", err)
+		}
+		if result.TransferArchives, err = deleteIDs(tx, "transfer_archives", "transfer_id", ids); err != nil {
+			return nil, fmt.Errorf("failed to prune transfer archives: %w", err)
+		}
+		if result.Transfers, err = deleteIDs(tx, "transfers", "id", ids); err != nil {
+			return nil, fmt.Errorf("failed to prune transfers: %w", err)
+		}
+	}
+
+	if policy.JobsAge > 0 {
+		ids, err := selectOlderThan(tx, `SELECT id, updated_at FROM jobs WHERE status IN ('completed', 'failed')`, now.Add(-policy.JobsAge))
+		if err != nil {
+			return nil, fmt.Errorf("failed to select jobs: %w", err)
+		}
+		if result.Jobs, err = deleteIDs(tx, "jobs", "id", ids); err != nil {
+			return nil, fmt.Errorf("failed to prune jobs: %w", err)
+		}
+	}
+
+	if err := tx.Commit(); err != nil {
+		return nil, fmt.Errorf("failed to commit prune: %w", err)
+	}
+	return result, nil
+}
+
+// selectOlderThan returns the IDs of rows of query (selecting id and a
+// timestamp) whose timestamp is before cutoff.
+func selectOlderThan(tx *sql.Tx, query string, cutoff time.Time) ([]int64, error) {
+	rows, err := tx.Query(query)
+	if err != nil {
+		return nil, err
+	}
+	defer func() {
+		_ = rows.Close()
+	}()
+
+	var ids []int64
+	for rows.Next() {
+		var id int64
+		var ts sql.NullTime
+		if err := rows.Scan(&id, &ts); err != nil {
+			return nil, err
+		}
+		if ts.Valid && ts.Time.Before(cutoff) {
+			ids = append(ids, id)
+		}
+	}
+	return ids, rows.Err()
+}
+
+// deleteIDs deletes the rows of table whose column is one of ids.
+func deleteIDs(tx *sql.Tx, table, column string, ids []int64) (int64, error) {
+	const batch = 500
+	var deleted int64
+	for start := 0; start < len(ids); start += batch {
+		end := min(start+batch, len(ids))
+		args := make([]interface{}, 0, end-start)
+		for _, id := range ids[start:end] {
+			args = append(args, id)
+		}
+		placeholders := strings.TrimSuffix(strings.Repeat("?,", len(args)), ",")
+		res, err := tx.Exec(fmt.Sprintf("DELETE FROM %s WHERE %s IN (%s)", table, column, placeholders), args...)
+		if err != nil {
+			return deleted, err
+		}
+		n, _ := res.RowsAffected()
+		deleted += n
+	}
+	return deleted, nil
+}
+
+// Size returns the database file size in bytes (page count times page size).
+func (s *SQLiteStore) Size() (int64, error) {
+	var pages, pageSize int64
+	if err := s.db.QueryRow("PRAGMA page_count").Scan(&pages); err != nil {
+		return 0, fmt.Errorf("failed to read page count: %w", err)
+	}
+	if err := s.db.QueryRow("PRAGMA page_size").Scan(&pageSize); err != nil {
+		return 0, fmt.Errorf("failed to read page size: %w", err)
+	}
+	return pages * pageSize, nil
+}
+
+// Optimize rebuilds the database file with VACUUM and refreshes query
+// planner statistics with ANALYZE.
+func (s *SQLiteStore) Optimize() error {
+	if _, err := s.db.Exec("VACUUM"); err != nil {
+		return fmt.Errorf("failed to vacuum database: %w", err)
+	}
+	if _, err := s.db.Exec("ANALYZE"); err != nil {
+		return fmt.Errorf("failed to analyze database: %w", err)
+	}
+	return nil
+}
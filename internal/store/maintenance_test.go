@@ -0,0 +1,171 @@
+package store
+
+import (
+	"testing"
+	"time"
+)
+
+func countRows(t *testing.T, s *SQLiteStore, table string) int {
+	t.Helper()
+	var n int
+	if err := s.db.QueryRow("SELECT COUNT(*) FROM " + table).Scan(&n); err != nil {
+		t.Fatalf("count %s: %v", table, err)
+	}
+	return n
+}
+
+func TestPruneSyncRunsPerProvider(t *testing.T) {
+	s := newTestStore(t)
+	now := time.Now()
+
+	var runs []*SyncRun
+	for i := 0; i < 5; i++ {
+		status := "success"
+		if i == 0 {
+			status = "running"
+		}
+		run := &SyncRun{Provider: "epel", StartTime: now.Add(time.Duration(i-10) * time.Hour), Status: status}
+		if err := s.CreateSyncRun(run); err != nil {
+			t.Fatalf("CreateSyncRun() failed: %v", err)
+		}
+		runs = append(runs, run)
+	}
+	if err := s.CreateSyncRun(&SyncRun{Provider: "rhcos", StartTime: now.Add(-48 * time.Hour), Status: "success"}); err != nil {
+		t.Fatalf("CreateSyncRun() failed: %v", err)
+	}
+	// The second-oldest epel run still owns a file record.
+	if err := s.UpsertFileRecord(&FileRecord{Provider: "epel", Path: "a.rpm", SyncRunID: runs[1].ID}); err != nil {
+		t.Fatalf("UpsertFileRecord() failed: %v", err)
+	}
+
+	result, err := s.Prune(RetentionPolicy{SyncRunsPerProvider: 2}, now)
+	if err != nil {
+		t.Fatalf("Prune() failed: %v", err)
+	}
+	if result.SyncRuns != 1 {
+		t.Errorf("SyncRuns pruned = %d, want 1", result.SyncRuns)
+	}
+
+	kept, err := s.ListSyncRuns("epel", 0)
+	if err != nil {
+		t.Fatalf("ListSyncRuns() failed: %v", err)
+	}
+	keptIDs := make(map[int64]bool)
+	for _, r := range kept {
+		keptIDs[r.ID] = true
+	}
+	for i, want := range []bool{true, true, false, true, true} {
+		if keptIDs[runs[i].ID] != want {
+			t.Errorf("run %d kept = %v, want %v", i, keptIDs[runs[i].ID], want)
+		}
+	}
+	if rhcos, _ := s.ListSyncRuns("rhcos", 0); len(rhcos) != 1 {
+		t.Errorf("rhcos runs = %d, want 1", len(rhcos))
+	}
+}
+
+func TestPruneByAge(t *testing.T) {
+	s := newTestStore(t)
+	now := time.Now()
+	old := now.Add(-40 * 24 * time.Hour)
+	recent := now.Add(-time.Hour)
+
+	for _, rec := range []*FailedFileRecord{
+		{Provider: "epel", FilePath: "old-resolved.rpm", FirstFailure: old, LastFailure: old, Resolved: true},
+		{Provider: "epel", FilePath: "new-resolved.rpm", FirstFailure: recent, LastFailure: recent, Resolved: true},
+		{Provider: "epel", FilePath: "old-unresolved.rpm", FirstFailure: old, LastFailure: old},
+	} {
+		if err := s.AddFailedFile(rec); err != nil {
+			t.Fatalf("AddFailedFile() failed: %v", err)
+		}
+	}
+
+	oldTransfer := &Transfer{Direction: "export", Status: "completed", StartTime: old, EndTime: old}
+	runningTransfer := &Transfer{Direction: "import", Status: "running", StartTime: old}
+	newTransfer := &Transfer{Direction: "export", Status: "completed", StartTime: recent, EndTime: recent}
+	for _, tr := range []*Transfer{oldTransfer, runningTransfer, newTransfer} {
+		if err := s.CreateTransfer(tr); err != nil {
+			t.Fatalf("CreateTransfer() failed: %v", err)
+		}
+	}
+	for _, name := range []string{"a.tar.zst", "b.tar.zst"} {
+		if err := s.CreateTransferArchive(&TransferArchive{TransferID: oldTransfer.ID, ArchiveName: name}); err != nil {
+			t.Fatalf("CreateTransferArchive() failed: %v", err)
+		}
+	}
+
+	for _, job := range []*Job{
+		{Type: "sync", Status: "completed", CreatedAt: old, UpdatedAt: old},
+		{Type: "sync", Status: "scheduled", CreatedAt: old, UpdatedAt: old},
+		{Type: "sync", Status: "failed", CreatedAt: recent, UpdatedAt: recent},
+	} {
+		if err := s.CreateJob(job); err != nil {
+			t.Fatalf("CreateJob() failed: %v", err)
+		}
+	}
+
+	policy := RetentionPolicy{
+		ResolvedFailuresAge: 30 * 24 * time.Hour,
+		TransfersAge:        30 * 24 * time.Hour,
+		JobsAge:             30 * 24 * time.Hour,
+	}
+	result, err := s.Prune(policy, now)
+	if err != nil {
+		t.Fatalf("Prune() failed: %v", err)
+	}
+
+	want := PruneResult{FailedFiles: 1, Transfers: 1, TransferArchives: 2, Jobs: 1}
+	if *result != want {
+		t.Errorf("Prune() = %+v, want %+v", *result, want)
+	}
+	if result.Total() != 5 {
+		t.Errorf("Total() = %d, want 5", result.Total())
+	}
+	if n := countRows(t, s, "failed_files"); n != 2 {
+		t.Errorf("failed_files = %d, want 2", n)
+	}
+	if n := countRows(t, s, "transfers"); n != 2 {
+		t.Errorf("transfers = %d, want 2", n)
+	}
+	if n := countRows(t, s, "transfer_archives"); n != 0 {
+		t.Errorf("transfer_archives = %d, want 0", n)
+	}
+	if n := countRows(t, s, "jobs"); n != 2 {
+		t.Errorf("jobs = %d, want 2", n)
+	}
+}
+
+func TestPruneZeroPolicyKeepsEverything(t *testing.T) {
+	s := newTestStore(t)
+	old := time.Now().Add(-1000 * 24 * time.Hour)
+	for i := 0; i < 3; i++ {
+		if err := s.CreateSyncRun(&SyncRun{Provider: "epel", StartTime: old, Status: "success"}); err != nil {
+			t.Fatalf("CreateSyncRun() failed: %v", err)
+		}
+	}
+	if err := s.CreateJob(&Job{Type: "sync", Status: "completed", CreatedAt: old, UpdatedAt: old}); err != nil {
+		t.Fatalf("CreateJob() failed: %v", err)
+	}
+
+	result, err := s.Prune(RetentionPolicy{}, time.Now())
+	if err != nil {
+		t.Fatalf("Prune() failed: %v", err)
+	}
+	if result.Total() != 0 {
+		t.Errorf("Prune() deleted %+v, want nothing", *result)
+	}
+}
+
+func TestSizeAndOptimize(t *testing.T) {
+	s := newTestStore(t)
+	size, err := s.Size()
+	if err != nil {
+		t.Fatalf("Size() failed: %v", err)
+	}
+	if size <= 0 {
+		t.Errorf("Size() = %d, want > 0", size)
+	}
+	if err := s.Optimize(); err != nil {
+		t.Fatalf("Optimize() failed: %v", err)
+	}
+}
@@ -5,7 +5,7 @@ import (
 )
 
 // migrate runs all pending migrations
-func (s *Store) migrate() error {
+func (s *SQLiteStore) migrate() error {
 	// Create migrations table if it doesn't exist
 	createMigrationsTableSQL := `
 		CREATE TABLE IF NOT EXISTS migrations (
@@ -28,155 +28,6 @@ func (s *Store) migrate() error {
 
 	s.logger.Info("Current schema version", "version", currentVersion)
 
-	// Define all migrations
-	migrations := []struct {
-		version int
-		sql     string
-	}{
-		{
-			version: 1,
-			sql: `
-				CREATE TABLE sync_runs (
-					id INTEGER PRIMARY KEY AUTOINCREMENT,
-					provider TEXT NOT NULL,
-					start_time DATETIME NOT NULL,
-					end_time DATETIME,
-					files_downloaded INTEGER DEFAULT 0,
-					files_deleted INTEGER DEFAULT 0,
-					files_skipped INTEGER DEFAULT 0,
-					files_failed INTEGER DEFAULT 0,
-					bytes_transferred INTEGER DEFAULT 0,
-					status TEXT DEFAULT 'running',
-					error_message TEXT
-				);
-
-				CREATE TABLE file_records (
-					id INTEGER PRIMARY KEY AUTOINCREMENT,
-					provider TEXT NOT NULL,
-					path TEXT NOT NULL,
-					size INTEGER DEFAULT 0,
-					sha256 TEXT,
-					last_modified DATETIME,
-					last_verified DATETIME,
-					sync_run_id INTEGER,
-					UNIQUE(provider, path),
-					FOREIGN KEY(sync_run_id) REFERENCES sync_runs(id)
-				);
-
-				CREATE TABLE jobs (
-					id INTEGER PRIMARY KEY AUTOINCREMENT,
-					type TEXT NOT NULL,
-					provider TEXT,
-					cron_expr TEXT,
-					status TEXT DEFAULT 'scheduled',
-					last_run DATETIME,
-					next_run DATETIME,
-					created_at DATETIME DEFAULT CURRENT_TIMESTAMP,
-					updated_at DATETIME DEFAULT CURRENT_TIMESTAMP
-				);
-
-				CREATE TABLE transfers (
-					id INTEGER PRIMARY KEY AUTOINCREMENT,
-					direction TEXT NOT NULL,
-					path TEXT NOT NULL,
-					providers TEXT,
-					archive_count INTEGER DEFAULT 0,
-					total_size INTEGER DEFAULT 0,
-					manifest_hash TEXT,
-					status TEXT DEFAULT 'running',
-					error_message TEXT,
-					start_time DATETIME NOT NULL,
-					end_time DATETIME
-				);
-
-				CREATE TABLE failed_files (
-					id INTEGER PRIMARY KEY AUTOINCREMENT,
-					provider TEXT NOT NULL,
-					file_path TEXT NOT NULL,
-					url TEXT,
-					expected_checksum TEXT,
-					error TEXT,
-					retry_count INTEGER DEFAULT 0,
-					first_failure DATETIME NOT NULL,
-					last_failure DATETIME NOT NULL,
-					resolved BOOLEAN DEFAULT 0
-				);
-			`,
-		},
-		{
-			version: 2,
-			sql: `
-				CREATE TABLE transfer_archives (
-					id INTEGER PRIMARY KEY AUTOINCREMENT,
-					transfer_id INTEGER NOT NULL,
-					archive_name TEXT NOT NULL,
-					sha256 TEXT NOT NULL,
-					size INTEGER DEFAULT 0,
-					validated BOOLEAN DEFAULT 0,
-					validated_at DATETIME,
-					FOREIGN KEY(transfer_id) REFERENCES transfers(id)
-				);
-			`,
-		},
-		{
-			version: 3,
-			sql: `
-				CREATE TABLE provider_configs (
-					id          INTEGER PRIMARY KEY AUTOINCREMENT,
-					name        TEXT NOT NULL UNIQUE,
-					type        TEXT NOT NULL,
-					enabled     INTEGER NOT NULL DEFAULT 0,
-					config_json TEXT NOT NULL DEFAULT '{}',
-					created_at  DATETIME NOT NULL DEFAULT CURRENT_TIMESTAMP,
-					updated_at  DATETIME NOT NULL DEFAULT CURRENT_TIMESTAMP
-				);
-			`,
-		},
-		{
-			version: 4,
-			sql: `
-				ALTER TABLE failed_files ADD COLUMN expected_size INTEGER DEFAULT 0;
-			`,
-		},
-		{
-			// Rename provider references in all tables from the old hardcoded
-			// provider Name() (which matched the type, e.g. "epel") to the
-			// user-chosen config name stored in provider_configs (e.g. "epel-10").
-			version: 5,
-			sql: `
-				UPDATE failed_files SET provider = (
-					SELECT pc.name FROM provider_configs pc WHERE pc.type = failed_files.provider
-				) WHERE EXISTS (
-					SELECT 1 FROM provider_configs pc WHERE pc.type = failed_files.provider AND pc.name != failed_files.provider
-				);
-
-				UPDATE file_records SET provider = (
-					SELECT pc.name FROM provider_configs pc WHERE pc.type = file_records.provider
-				) WHERE EXISTS (
-					SELECT 1 FROM provider_configs pc WHERE pc.type = file_records.provider AND pc.name != file_records.provider
-				);
-
-				UPDATE sync_runs SET provider = (
-					SELECT pc.name FROM provider_configs pc WHERE pc.type = sync_runs.provider
-				) WHERE EXISTS (
-					SELECT 1 FROM provider_configs pc WHERE pc.type = sync_runs.provider AND pc.name != sync_runs.provider
-				);
-
-				UPDATE jobs SET provider = (
-					SELECT pc.name FROM provider_configs pc WHERE pc.type = jobs.provider
-				) WHERE EXISTS (
-					SELECT 1 FROM provider_configs pc WHERE pc.type = jobs.provider AND pc.name != jobs.provider
-				);
-			`,
-		},
-		{
-			version: 6,
-			sql: `
-				ALTER TABLE failed_files ADD COLUMN dest_path TEXT DEFAULT '';
-			`,
-		},
-	}
-
 	// Run pending migrations
 	for _, mig := range migrations {
 		if mig.version > currentVersion {
@@ -194,7 +45,7 @@ func (s *Store) migrate() error {
 }
 
 // runMigration executes a migration and records it
-func (s *Store) runMigration(version int, sql string) error {
+func (s *SQLiteStore) runMigration(version int, sql string) error {
 	tx, err := s.db.Begin()
 	if err != nil {
 		return fmt.Errorf("failed to begin transaction: %w", err)
@@ -220,3 +71,264 @@ func (s *Store) runMigration(version int, sql string) error {
 
 	return nil
 }
+
+// migration is one versioned schema change.
+type migration struct {
+	version int
+	sql     string
+}
+
+// migrations lists every schema change in order.
+var migrations = []migration{
+	{
+		version: 1,
+		sql: `
+			CREATE TABLE sync_runs (
+				id INTEGER PRIMARY KEY AUTOINCREMENT,
+				provider TEXT NOT NULL,
+				start_time DATETIME NOT NULL,
+				end_time DATETIME,
+				files_downloaded INTEGER DEFAULT 0,
+				files_deleted INTEGER DEFAULT 0,
+				files_skipped INTEGER DEFAULT 0,
+				files_failed INTEGER DEFAULT 0,
+				bytes_transferred INTEGER DEFAULT 0,
+				status TEXT DEFAULT 'running',
+				error_message TEXT
+			);
+
+			CREATE TABLE file_records (
+				id INTEGER PRIMARY KEY AUTOINCREMENT,
+				provider TEXT NOT NULL,
+				path TEXT NOT NULL,
+				size INTEGER DEFAULT 0,
+				sha256 TEXT,
+				last_modified DATETIME,
+				last_verified DATETIME,
+				sync_run_id INTEGER,
+				UNIQUE(provider, path),
+				FOREIGN KEY(sync_run_id) REFERENCES sync_runs(id)
+			);
+
+			CREATE TABLE jobs (
+				id INTEGER PRIMARY KEY AUTOINCREMENT,
+				type TEXT NOT NULL,
+				provider TEXT,
+				cron_expr TEXT,
+				status TEXT DEFAULT 'scheduled',
+				last_run DATETIME,
+				next_run DATETIME,
+				created_at DATETIME DEFAULT CURRENT_TIMESTAMP,
+				updated_at DATETIME DEFAULT CURRENT_TIMESTAMP
+			);
+
+			CREATE TABLE transfers (
+				id INTEGER PRIMARY KEY AUTOINCREMENT,
+				direction TEXT NOT NULL,
+				path TEXT NOT NULL,
+				providers TEXT,
+				archive_count INTEGER DEFAULT 0,
+				total_size INTEGER DEFAULT 0,
+				manifest_hash TEXT,
+				status TEXT DEFAULT 'running',
+				error_message TEXT,
+				start_time DATETIME NOT NULL,
+				end_time DATETIME
+			);
+
+			CREATE TABLE failed_files (
+				id INTEGER PRIMARY KEY AUTOINCREMENT,
+				provider TEXT NOT NULL,
+				file_path TEXT NOT NULL,
+				url TEXT,
+				expected_checksum TEXT,
+				error TEXT,
+				retry_count INTEGER DEFAULT 0,
+				first_failure DATETIME NOT NULL,
+				last_failure DATETIME NOT NULL,
+				resolved BOOLEAN DEFAULT 0
+			);
+		`,
+	},
+	{
+		version: 2,
+		sql: `
+			CREATE TABLE transfer_archives (
+				id INTEGER PRIMARY KEY AUTOINCREMENT,
+				transfer_id INTEGER NOT NULL,
+				archive_name TEXT NOT NULL,
+				sha256 TEXT NOT NULL,
+				size INTEGER DEFAULT 0,
+				validated BOOLEAN DEFAULT 0,
+				validated_at DATETIME,
+				FOREIGN KEY(transfer_id) REFERENCES transfers(id)
+			);
+		`,
+	},
+	{
+		version: 3,
+		sql: `
+			CREATE TABLE provider_configs (
+				id          INTEGER PRIMARY KEY AUTOINCREMENT,
+				name        TEXT NOT NULL UNIQUE,
+				type        TEXT NOT NULL,
+				enabled     INTEGER NOT NULL DEFAULT 0,
+				config_json TEXT NOT NULL DEFAULT '{}',
+				created_at  DATETIME NOT NULL DEFAULT CURRENT_TIMESTAMP,
+				updated_at  DATETIME NOT NULL DEFAULT CURRENT_TIMESTAMP
+			);
+		`,
+	},
+	{
+		version: 4,
+		sql: `
+			ALTER TABLE failed_files ADD COLUMN expected_size INTEGER DEFAULT 0;
+		`,
+	},
+	{
+		// Rename provider references in all tables from the old hardcoded
+		// provider Name() (which matched the type, e.g. "epel") to the
+		// user-chosen config name stored in provider_configs (e.g. "epel-10").
+		version: 5,
+		sql: `
+			UPDATE failed_files SET provider = (
+				SELECT pc.name FROM provider_configs pc WHERE pc.type = failed_files.provider
+			) WHERE EXISTS (
+				SELECT 1 FROM provider_configs pc WHERE pc.type = failed_files.provider AND pc.name != failed_files.provider
+			);
+
+			UPDATE file_records SET provider = (
+				SELECT pc.name FROM provider_configs pc WHERE pc.type = file_records.provider
+			) WHERE EXISTS (
+				SELECT 1 FROM provider_configs pc WHERE pc.type = file_records.provider AND pc.name != file_records.provider
+			);
+
+			UPDATE sync_runs SET provider = (
+				SELECT pc.name FROM provider_configs pc WHERE pc.type = sync_runs.provider
+			) WHERE EXISTS (
+				SELECT 1 FROM provider_configs pc WHERE pc.type = sync_runs.provider AND pc.name != sync_runs.provider
+			);
+
+			UPDATE jobs SET provider = (
+				SELECT pc.name FROM provider_configs pc WHERE pc.type = jobs.provider
+			) WHERE EXISTS (
+				SELECT 1 FROM provider_configs pc WHERE pc.type = jobs.provider AND pc.name != jobs.provider
+			);
+		`,
+	},
+	{
+		version: 6,
+		sql: `
+			ALTER TABLE failed_files ADD COLUMN dest_path TEXT DEFAULT '';
+		`,
+	},
+	{
+		version: 7,
+		sql: `
+			ALTER TABLE sync_runs ADD COLUMN signature_status TEXT DEFAULT '';
+		`,
+	},
+	{
+		// Sync history and sanitized provider configs shipped from the
+		// high side in a transfer's state snapshot.
+		version: 8,
+		sql: `
+			ALTER TABLE sync_runs ADD COLUMN source_host TEXT DEFAULT '';
+			ALTER TABLE sync_runs ADD COLUMN upstream_id INTEGER DEFAULT 0;
+			CREATE UNIQUE INDEX idx_sync_runs_upstream ON sync_runs(source_host, upstream_id)
+				WHERE source_host != '';
+
+			CREATE TABLE upstream_provider_configs (
+				source_host TEXT NOT NULL,
+				name TEXT NOT NULL,
+				type TEXT NOT NULL,
+				config_json TEXT NOT NULL DEFAULT '{}',
+				imported_at DATETIME NOT NULL,
+				PRIMARY KEY (source_host, name)
+			);
+		`,
+	},
+	{
+		version: 9,
+		sql: `
+			ALTER TABLE file_records ADD COLUMN source_url TEXT DEFAULT '';
+			ALTER TABLE file_records ADD COLUMN mirror_host TEXT DEFAULT '';
+			ALTER TABLE file_records ADD COLUMN etag TEXT DEFAULT '';
+			ALTER TABLE file_records ADD COLUMN upstream_last_modified TEXT DEFAULT '';
+			ALTER TABLE file_records ADD COLUMN response_time_ms INTEGER DEFAULT 0;
+		`,
+	},
+	{
+		// Full-text index over mirrored packages, images and artifacts.
+		version: 10,
+		sql: `
+			CREATE VIRTUAL TABLE search_index USING fts5(
+				provider UNINDEXED, type UNINDEXED, name, version, arch, path, digest
+			);
+		`,
+	},
+	{
+		// Append-only audit log; the triggers reject edits and deletes.
+		version: 11,
+		sql: `
+			CREATE TABLE audit_events (
+				id INTEGER PRIMARY KEY AUTOINCREMENT,
+				time DATETIME NOT NULL,
+				actor TEXT NOT NULL DEFAULT '',
+				source_ip TEXT NOT NULL DEFAULT '',
+				source TEXT NOT NULL DEFAULT '',
+				action TEXT NOT NULL,
+				target TEXT NOT NULL DEFAULT '',
+				before_json TEXT NOT NULL DEFAULT '',
+				after_json TEXT NOT NULL DEFAULT '',
+				outcome TEXT NOT NULL,
+				detail TEXT NOT NULL DEFAULT '',
+				prev_hash TEXT NOT NULL DEFAULT '',
+				hash TEXT NOT NULL DEFAULT ''
+			);
+			CREATE INDEX idx_audit_events_time ON audit_events(time);
+
+			CREATE TRIGGER audit_events_no_update BEFORE UPDATE ON audit_events
+			BEGIN
+				SELECT RAISE(ABORT, 'audit_events is append-only');
+			END;
+			CREATE TRIGGER audit_events_no_delete BEFORE DELETE ON audit_events
+			BEGIN
+				SELECT RAISE(ABORT, 'audit_events is append-only');
+			END;
+		`,
+	},
+	{
+		// Provider config history; existing configs become revision 1.
+		version: 12,
+		sql: `
+			CREATE TABLE provider_config_revisions (
+				id INTEGER PRIMARY KEY AUTOINCREMENT,
+				provider TEXT NOT NULL,
+				revision INTEGER NOT NULL,
+				type TEXT NOT NULL,
+				enabled INTEGER NOT NULL DEFAULT 0,
+				config_json TEXT NOT NULL DEFAULT '{}',
+				author TEXT NOT NULL DEFAULT '',
+				comment TEXT NOT NULL DEFAULT '',
+				created_at DATETIME NOT NULL DEFAULT CURRENT_TIMESTAMP,
+				UNIQUE (provider, revision)
+			);
+
+			INSERT INTO provider_config_revisions (provider, revision, type, enabled, config_json, author, comment, created_at)
+			SELECT name, 1, type, enabled, config_json, 'system', 'initial revision', updated_at FROM provider_configs;
+		`,
+	},
+	{
+		// Transfer manifests, kept for manifest diffs after the media is gone.
+		version: 13,
+		sql: `
+			ALTER TABLE transfers ADD COLUMN manifest_json TEXT NOT NULL DEFAULT '';
+		`,
+	},
+}
+
+// latestMigration returns the newest schema version this build knows.
+func latestMigration() int {
+	return migrations[len(migrations)-1].version
+}
@@ -15,6 +15,9 @@ type SyncRun struct {
 	BytesTransferred int64
 	Status           string // "success", "partial", "failed"
 	ErrorMessage     string
+	SignatureStatus  string // "verified", "unverified", or "" when not applicable
+	SourceHost       string // upstream host for runs imported with a transfer; "" for local runs
+	UpstreamID       int64  // run ID on SourceHost
 }
 
 // FileRecord tracks a downloaded file
@@ -27,6 +30,13 @@ type FileRecord struct {
 	LastModified time.Time
 	LastVerified time.Time
 	SyncRunID    int64
+
+	// Provenance of the download; empty for generated or scanned files
+	SourceURL            string // URL requested from the provider
+	MirrorHost           string // host that served the bytes, after redirects
+	ETag                 string // upstream ETag header
+	UpstreamLastModified string // upstream Last-Modified header
+	ResponseTimeMS       int64  // time until response headers arrived
 }
 
 // Job represents a scheduled or completed job
@@ -51,6 +61,9 @@ type Transfer struct {
 	ArchiveCount int
 	TotalSize    int64
 	ManifestHash string
+	// Manifest is the airgap-manifest.json of the transfer. ListTransfers
+	// leaves it empty; GetTransfer loads it.
+	Manifest     string
 	Status       string // "running", "completed", "failed"
 	ErrorMessage string
 	StartTime    time.Time
@@ -88,9 +101,88 @@ type FailedFileRecord struct {
 type ProviderConfig struct {
 	ID         int64
 	Name       string
-	Type       string // "epel", "ocp_binaries", "ocp_clients", "rhcos", "container_images", "registry", "custom_files"
+	Type       string // "epel", "ocp_binaries", "ocp_clients", "rhcos", "container_images", "operator_catalog", "registry", "custom_files"
 	Enabled    bool
 	ConfigJSON string
 	CreatedAt  time.Time
 	UpdatedAt  time.Time
 }
+
+// UpstreamProviderConfig is a sanitized provider configuration shipped from
+// the high side in a transfer's state snapshot.
+type UpstreamProviderConfig struct {
+	SourceHost string
+	Name       string
+	Type       string
+	ConfigJSON string
+	ImportedAt time.Time
+}
+
+// SearchEntry is one item of mirrored inventory in the search index: an RPM,
+// a container image tag or an OpenShift artifact.
+type SearchEntry struct {
+	Provider string
+	Type     string // "rpm", "image", "ocp_binary", "ocp_client", "rhcos"
+	Name     string
+	Version  string
+	Arch     string
+	Path     string // file record path; empty for images
+	Digest   string // manifest digest of an image tag
+}
+
+// SearchQuery selects search index entries. Text matches words in the name,
+// version, arch, path and digest; the other fields filter exactly, except
+// Version, which also matches longer versions (4.17 matches 4.17.3).
+type SearchQuery struct {
+	Text     string
+	Provider string
+	Type     string
+	Version  string
+	Limit    int
+}
+
+// AuditEvent records one mutating action taken through the API, UI or CLI.
+// Events are append-only; when hash chaining is enabled each event's Hash
+// covers its fields and the previous event's hash.
+type AuditEvent struct {
+	ID       int64
+	Time     time.Time
+	Actor    string
+	SourceIP string
+	Source   string // "api" or "cli"
+	Action   string // e.g. "provider.update", "sync.start"
+	Target   string
+	Before   string // redacted JSON state before the action
+	After    string // redacted JSON state after the action
+	Outcome  string // "success" or "failure"
+	Detail   string
+	PrevHash string
+	Hash     string
+}
+
+// AuditFilter selects audit events. Action matches exactly, or as a prefix
+// when it ends in "." (e.g. "provider."). Zero values do not filter.
+type AuditFilter struct {
+	Actor   string
+	Action  string
+	Target  string
+	Outcome string
+	Since   time.Time
+	Until   time.Time
+	Limit   int
+}
+
+// ProviderConfigRevision is a snapshot of a provider config taken after
+// each change. Revisions are numbered from 1 per provider name and are kept
+// when the provider is deleted.
+type ProviderConfigRevision struct {
+	ID         int64
+	Provider   string
+	Revision   int
+	Type       string
+	Enabled    bool
+	ConfigJSON string
+	Author     string
+	Comment    string
+	CreatedAt  time.Time
+}
@@ -0,0 +1,1167 @@
+package store
+
+import (
+	"database/sql"
+	"encoding/json"
+	"fmt"
+	"log/slog"
+	"strconv"
+	"strings"
+	"time"
+
+	_ "github.com/jackc/pgx/v5/stdlib"
+)
+
+// PostgresStore is the PostgreSQL implementation of Store, for central
+// instances backed by an HA database.
+type PostgresStore struct {
+	db     *sql.DB
+	logger *slog.Logger
+}
+
+// NewPostgres creates a PostgresStore, connecting with dsn (a libpq
+// connection string or postgres:// URL) and running migrations.
+func NewPostgres(dsn string, logger *slog.Logger) (*PostgresStore, error) {
+	db, err := sql.Open("pgx", dsn)
+	if err != nil {
+		return nil, fmt.Errorf("failed to open database: %w", err)
+	}
+
+	if err := db.Ping(); err != nil {
+		_ = db.Close()
+		return nil, fmt.Errorf("failed to ping database: %w", err)
+	}
+
+	s := &PostgresStore{
+		db:     db,
+		logger: logger,
+	}
+
+	if err := s.migrate(); err != nil {
+		_ = db.Close()
+		return nil, fmt.Errorf("failed to run migrations: %w", err)
+	}
+
+	logger.Info("Store initialized successfully", "driver", DriverPostgres)
+	return s, nil
+}
+
+// Close closes the database connection
+func (s *PostgresStore) Close() error {
+	if err := s.db.Close(); err != nil {
+		return fmt.Errorf("failed to close database: %w", err)
+	}
+	return nil
+}
+
+// expectRows returns notFound when res affected no rows.
+func expectRows(res sql.Result, notFound error) error {
+	n, err := res.RowsAffected()
+	if err != nil {
+		return fmt.Errorf("failed to get rows affected: %w", err)
+	}
+	if n == 0 {
+		return notFound
+	}
+	return nil
+}
+
+// ============================================================================
+// SyncRun Operations
+// ============================================================================
+
+const pgSyncRunColumns = `
+	id, provider, start_time, end_time, files_downloaded, files_deleted,
+	files_skipped, files_failed, bytes_transferred, status, error_message,
+	signature_status, source_host, upstream_id
+`
+
+func scanSyncRun(row rowScanner, run *SyncRun) error {
+	return row.Scan(
+		&run.ID, &run.Provider, &run.StartTime, &run.EndTime,
+		&run.FilesDownloaded, &run.FilesDeleted, &run.FilesSkipped,
+		&run.FilesFailed, &run.BytesTransferred, &run.Status, &run.ErrorMessage,
+		&run.SignatureStatus, &run.SourceHost, &run.UpstreamID,
+	)
+}
+
+// CreateSyncRun inserts a new SyncRun and sets its ID
+func (s *PostgresStore) CreateSyncRun(run *SyncRun) error {
+	const query = `
+		INSERT INTO sync_runs (
+			provider, start_time, end_time, files_downloaded, files_deleted,
+			files_skipped, files_failed, bytes_transferred, status, error_message,
+			signature_status, source_host, upstream_id
+		) VALUES ($1, $2, $3, $4, $5, $6, $7, $8, $9, $10, $11, $12, $13)
+		RETURNING id
+	`
+	err := s.db.QueryRow(
+		query,
+		run.Provider, run.StartTime, run.EndTime, run.FilesDownloaded,
+		run.FilesDeleted, run.FilesSkipped, run.FilesFailed,
+		run.BytesTransferred, run.Status, run.ErrorMessage, run.SignatureStatus,
+		run.SourceHost, run.UpstreamID,
+	).Scan(&run.ID)
+	if err != nil {
+		return fmt.Errorf("failed to insert sync run: %w", err)
+	}
+	return nil
+}
+
+// UpdateSyncRun updates an existing SyncRun by ID
+func (s *PostgresStore) UpdateSyncRun(run *SyncRun) error {
+	const query = `
+		UPDATE sync_runs SET
+			provider = $1, start_time = $2, end_time = $3, files_downloaded = $4,
+			files_deleted = $5, files_skipped = $6, files_failed = $7,
+			bytes_transferred = $8, status = $9, error_message = $10,
+			signature_status = $11
+		WHERE id = $12
+	`
+	res, err := s.db.Exec(
+		query,
+		run.Provider, run.StartTime, run.EndTime, run.FilesDownloaded,
+		run.FilesDeleted, run.FilesSkipped, run.FilesFailed,
+		run.BytesTransferred, run.Status, run.ErrorMessage, run.SignatureStatus, run.ID,
+	)
+	if err != nil {
+		return fmt.Errorf("failed to update sync run: %w", err)
+	}
+	return expectRows(res, fmt.Errorf("sync run not found: %d", run.ID))
+}
+
+// GetSyncRun retrieves a SyncRun by ID
+func (s *PostgresStore) GetSyncRun(id int64) (*SyncRun, error) {
+	run := &SyncRun{}
+	err := scanSyncRun(s.db.QueryRow("SELECT "+pgSyncRunColumns+" FROM sync_runs WHERE id = $1", id), run)
+	if err != nil {
+		if err == sql.ErrNoRows {
+			return nil, fmt.Errorf("sync run not found: %d", id)
+		}
+		return nil, fmt.Errorf("failed to query sync run: %w", err)
+	}
+	return run, nil
+}
+
+// ListSyncRuns retrieves SyncRuns, optionally filtered by provider
+func (s *PostgresStore) ListSyncRuns(provider string, limit int) ([]SyncRun, error) {
+	query := "SELECT " + pgSyncRunColumns + " FROM sync_runs"
+	var args []interface{}
+	if provider != "" {
+		args = append(args, provider)
+		query += " WHERE provider = $1"
+	}
+	query += " ORDER BY start_time DESC"
+	if limit > 0 {
+		args = append(args, limit)
+		query += " LIMIT $" + strconv.Itoa(len(args))
+	}
+
+	rows, err := s.db.Query(query, args...)
+	if err != nil {
+		return nil, fmt.Errorf("failed to query sync runs: %w", err)
+	}
+	defer func() {
+		_ = rows.Close()
+	}()
+
+	var runs []SyncRun
+	for rows.Next() {
+		run := SyncRun{}
+		if err := scanSyncRun(rows, &run); err != nil {
+			return nil, fmt.Errorf("failed to scan sync run: %w", err)
+		}
+		runs = append(runs, run)
+	}
+	if err := rows.Err(); err != nil {
+		return nil, fmt.Errorf("error iterating sync runs: %w", err)
+	}
+	return runs, nil
+}
+
+// ImportSyncRun records a sync run from run.SourceHost, keyed by its
+// UpstreamID, and sets run.ID to the local row.
+func (s *PostgresStore) ImportSyncRun(run *SyncRun) error {
+	if run.SourceHost == "" || run.UpstreamID == 0 {
+		return fmt.Errorf("imported sync run needs a source host and upstream id")
+	}
+
+	const query = `
+		INSERT INTO sync_runs (
+			provider, start_time, end_time, files_downloaded, files_deleted,
+			files_skipped, files_failed, bytes_transferred, status, error_message,
+			signature_status, source_host, upstream_id
+		) VALUES ($1, $2, $3, $4, $5, $6, $7, $8, $9, $10, $11, $12, $13)
+		ON CONFLICT (source_host, upstream_id) WHERE source_host != '' DO UPDATE SET
+			provider = excluded.provider, start_time = excluded.start_time,
+			end_time = excluded.end_time, files_downloaded = excluded.files_downloaded,
+			files_deleted = excluded.files_deleted, files_skipped = excluded.files_skipped,
+			files_failed = excluded.files_failed, bytes_transferred = excluded.bytes_transferred,
+			status = excluded.status, error_message = excluded.error_message,
+			signature_status = excluded.signature_status
+		RETURNING id
+	`
+	err := s.db.QueryRow(
+		query,
+		run.Provider, run.StartTime, run.EndTime, run.FilesDownloaded,
+		run.FilesDeleted, run.FilesSkipped, run.FilesFailed,
+		run.BytesTransferred, run.Status, run.ErrorMessage, run.SignatureStatus,
+		run.SourceHost, run.UpstreamID,
+	).Scan(&run.ID)
+	if err != nil {
+		return fmt.Errorf("failed to import sync run: %w", err)
+	}
+	return nil
+}
+
+// ============================================================================
+// FileRecord Operations
+// ============================================================================
+
+const pgFileRecordColumns = `
+	id, provider, path, size, sha256, last_modified, last_verified, sync_run_id,
+	source_url, mirror_host, etag, upstream_last_modified, response_time_ms
+`
+
+// UpsertFileRecord inserts a FileRecord or updates the one with the same
+// provider and path
+func (s *PostgresStore) UpsertFileRecord(rec *FileRecord) error {
+	const query = `
+		INSERT INTO file_records (
+			provider, path, size, sha256, last_modified, last_verified, sync_run_id,
+			source_url, mirror_host, etag, upstream_last_modified, response_time_ms
+		) VALUES ($1, $2, $3, $4, $5, $6, $7, $8, $9, $10, $11, $12)
+		ON CONFLICT (provider, path) DO UPDATE SET
+			size = excluded.size, sha256 = excluded.sha256,
+			last_modified = excluded.last_modified, last_verified = excluded.last_verified,
+			sync_run_id = excluded.sync_run_id, source_url = excluded.source_url,
+			mirror_host = excluded.mirror_host, etag = excluded.etag,
+			upstream_last_modified = excluded.upstream_last_modified,
+			response_time_ms = excluded.response_time_ms
+		RETURNING id
+	`
+	err := s.db.QueryRow(
+		query,
+		rec.Provider, rec.Path, rec.Size, rec.SHA256,
+		rec.LastModified, rec.LastVerified, rec.SyncRunID,
+		rec.SourceURL, rec.MirrorHost, rec.ETag, rec.UpstreamLastModified, rec.ResponseTimeMS,
+	).Scan(&rec.ID)
+	if err != nil {
+		return fmt.Errorf("failed to upsert file record: %w", err)
+	}
+	return nil
+}
+
+// GetFileRecord retrieves a FileRecord by provider and path
+func (s *PostgresStore) GetFileRecord(provider, path string) (*FileRecord, error) {
+	rec := &FileRecord{}
+	err := scanFileRecord(s.db.QueryRow(
+		"SELECT "+pgFileRecordColumns+" FROM file_records WHERE provider = $1 AND path = $2",
+		provider, path,
+	), rec)
+	if err != nil {
+		if err == sql.ErrNoRows {
+			return nil, fmt.Errorf("file record not found: %s/%s", provider, path)
+		}
+		return nil, fmt.Errorf("failed to query file record: %w", err)
+	}
+	return rec, nil
+}
+
+// ListFileRecords retrieves all FileRecords for a provider
+func (s *PostgresStore) ListFileRecords(provider string) ([]FileRecord, error) {
+	rows, err := s.db.Query(
+		"SELECT "+pgFileRecordColumns+" FROM file_records WHERE provider = $1 ORDER BY path",
+		provider,
+	)
+	if err != nil {
+		return nil, fmt.Errorf("failed to query file records: %w", err)
+	}
+	return collectFileRecords(rows)
+}
+
+// FindFileRecords retrieves the FileRecords whose path, or provider/path,
+// equals path, across all providers
+func (s *PostgresStore) FindFileRecords(path string) ([]FileRecord, error) {
+	rows, err := s.db.Query(
+		"SELECT "+pgFileRecordColumns+" FROM file_records WHERE path = $1 OR provider || '/' || path = $1 ORDER BY provider, path",
+		path,
+	)
+	if err != nil {
+		return nil, fmt.Errorf("failed to query file records: %w", err)
+	}
+	return collectFileRecords(rows)
+}
+
+// DeleteFileRecord deletes a FileRecord by provider and path
+func (s *PostgresStore) DeleteFileRecord(provider, path string) error {
+	res, err := s.db.Exec("DELETE FROM file_records WHERE provider = $1 AND path = $2", provider, path)
+	if err != nil {
+		return fmt.Errorf("failed to delete file record: %w", err)
+	}
+	return expectRows(res, fmt.Errorf("file record not found: %s/%s", provider, path))
+}
+
+// CountFileRecords returns the count of FileRecords for a provider
+func (s *PostgresStore) CountFileRecords(provider string) (int, error) {
+	var count int
+	if err := s.db.QueryRow("SELECT COUNT(*) FROM file_records WHERE provider = $1", provider).Scan(&count); err != nil {
+		return 0, fmt.Errorf("failed to count file records: %w", err)
+	}
+	return count, nil
+}
+
+// SumFileSize returns the total size of all files for a provider
+func (s *PostgresStore) SumFileSize(provider string) (int64, error) {
+	var totalSize int64
+	if err := s.db.QueryRow("SELECT COALESCE(SUM(size), 0)::BIGINT FROM file_records WHERE provider = $1", provider).Scan(&totalSize); err != nil {
+		return 0, fmt.Errorf("failed to sum file size: %w", err)
+	}
+	return totalSize, nil
+}
+
+// ============================================================================
+// FailedFileRecord Operations (Dead Letter Queue)
+// ============================================================================
+
+// AddFailedFile updates the unresolved record for the same provider and file,
+// or inserts a new one
+func (s *PostgresStore) AddFailedFile(rec *FailedFileRecord) error {
+	const updateQuery = `
+		UPDATE failed_files
+		SET error = $1, retry_count = retry_count + 1, last_failure = $2,
+		    url = COALESCE(NULLIF($3, ''), url),
+		    dest_path = COALESCE(NULLIF($4, ''), dest_path),
+		    expected_checksum = COALESCE(NULLIF($5, ''), expected_checksum),
+		    expected_size = CASE WHEN $6::BIGINT > 0 THEN $6::BIGINT ELSE expected_size END
+		WHERE provider = $7 AND file_path = $8 AND NOT resolved
+	`
+	res, err := s.db.Exec(
+		updateQuery,
+		rec.Error, rec.LastFailure,
+		rec.URL, rec.DestPath, rec.ExpectedChecksum, rec.ExpectedSize,
+		rec.Provider, rec.FilePath,
+	)
+	if err != nil {
+		return fmt.Errorf("failed to update failed file record: %w", err)
+	}
+	if n, _ := res.RowsAffected(); n > 0 {
+		return nil // existing record updated
+	}
+
+	const insertQuery = `
+		INSERT INTO failed_files (
+			provider, file_path, url, dest_path, expected_checksum, expected_size, error,
+			retry_count, first_failure, last_failure, resolved
+		) VALUES ($1, $2, $3, $4, $5, $6, $7, $8, $9, $10, $11)
+		RETURNING id
+	`
+	err = s.db.QueryRow(
+		insertQuery,
+		rec.Provider, rec.FilePath, rec.URL, rec.DestPath, rec.ExpectedChecksum,
+		rec.ExpectedSize, rec.Error, rec.RetryCount, rec.FirstFailure, rec.LastFailure,
+		rec.Resolved,
+	).Scan(&rec.ID)
+	if err != nil {
+		return fmt.Errorf("failed to add failed file record: %w", err)
+	}
+	return nil
+}
+
+// ListFailedFiles retrieves all unresolved FailedFileRecords for a provider
+func (s *PostgresStore) ListFailedFiles(provider string) ([]FailedFileRecord, error) {
+	const query = `
+		SELECT id, provider, file_path, url, dest_path, expected_checksum, expected_size, error,
+		       retry_count, first_failure, last_failure, resolved
+		FROM failed_files WHERE provider = $1 AND NOT resolved ORDER BY last_failure DESC
+	`
+	rows, err := s.db.Query(query, provider)
+	if err != nil {
+		return nil, fmt.Errorf("failed to query failed files: %w", err)
+	}
+	defer func() {
+		_ = rows.Close()
+	}()
+
+	var records []FailedFileRecord
+	for rows.Next() {
+		rec := FailedFileRecord{}
+		err := rows.Scan(
+			&rec.ID, &rec.Provider, &rec.FilePath, &rec.URL, &rec.DestPath,
+			&rec.ExpectedChecksum, &rec.ExpectedSize, &rec.Error, &rec.RetryCount,
+			&rec.FirstFailure, &rec.LastFailure, &rec.Resolved,
+		)
+		if err != nil {
+			return nil, fmt.Errorf("failed to scan failed file record: %w", err)
+		}
+		records = append(records, rec)
+	}
+	if err := rows.Err(); err != nil {
+		return nil, fmt.Errorf("error iterating failed file records: %w", err)
+	}
+	return records, nil
+}
+
+// ResolveFailedFile marks a FailedFileRecord as resolved
+func (s *PostgresStore) ResolveFailedFile(id int64) error {
+	res, err := s.db.Exec("UPDATE failed_files SET resolved = true WHERE id = $1", id)
+	if err != nil {
+		return fmt.Errorf("failed to resolve failed file: %w", err)
+	}
+	return expectRows(res, fmt.Errorf("failed file record not found: %d", id))
+}
+
+// IncrementFailedRetry increments the retry count and updates last_failure
+func (s *PostgresStore) IncrementFailedRetry(id int64) error {
+	res, err := s.db.Exec(`
+		UPDATE failed_files SET retry_count = retry_count + 1, last_failure = now()
+		WHERE id = $1
+	`, id)
+	if err != nil {
+		return fmt.Errorf("failed to increment failed retry: %w", err)
+	}
+	return expectRows(res, fmt.Errorf("failed file record not found: %d", id))
+}
+
+// ============================================================================
+// Job Operations
+// ============================================================================
+
+// CreateJob inserts a new Job and sets its ID
+func (s *PostgresStore) CreateJob(job *Job) error {
+	const query = `
+		INSERT INTO jobs (
+			type, provider, cron_expr, status, last_run, next_run, created_at, updated_at
+		) VALUES ($1, $2, $3, $4, $5, $6, $7, $8)
+		RETURNING id
+	`
+	err := s.db.QueryRow(
+		query,
+		job.Type, job.Provider, job.CronExpr, job.Status,
+		job.LastRun, job.NextRun, job.CreatedAt, job.UpdatedAt,
+	).Scan(&job.ID)
+	if err != nil {
+		return fmt.Errorf("failed to insert job: %w", err)
+	}
+	return nil
+}
+
+// UpdateJob updates an existing Job by ID
+func (s *PostgresStore) UpdateJob(job *Job) error {
+	const query = `
+		UPDATE jobs SET
+			type = $1, provider = $2, cron_expr = $3, status = $4,
+			last_run = $5, next_run = $6, created_at = $7, updated_at = $8
+		WHERE id = $9
+	`
+	res, err := s.db.Exec(
+		query,
+		job.Type, job.Provider, job.CronExpr, job.Status,
+		job.LastRun, job.NextRun, job.CreatedAt, job.UpdatedAt, job.ID,
+	)
+	if err != nil {
+		return fmt.Errorf("failed to update job: %w", err)
+	}
+	return expectRows(res, fmt.Errorf("job not found: %d", job.ID))
+}
+
+// ListJobs retrieves Jobs, optionally filtered by status
+func (s *PostgresStore) ListJobs(status string, limit int) ([]Job, error) {
+	query := `
+		SELECT id, type, provider, cron_expr, status, last_run, next_run, created_at, updated_at
+		FROM jobs
+	`
+	var args []interface{}
+	if status != "" {
+		args = append(args, status)
+		query += " WHERE status = $1"
+	}
+	query += " ORDER BY next_run ASC"
+	if limit > 0 {
+		args = append(args, limit)
+		query += " LIMIT $" + strconv.Itoa(len(args))
+	}
+
+	rows, err := s.db.Query(query, args...)
+	if err != nil {
+		return nil, fmt.Errorf("failed to query jobs: %w", err)
+	}
+	defer func() {
+		_ = rows.Close()
+	}()
+
+	var jobs []Job
+	for rows.Next() {
+		job := Job{}
+		err := rows.Scan(
+			&job.ID, &job.Type, &job.Provider, &job.CronExpr, &job.Status,
+			&job.LastRun, &job.NextRun, &job.CreatedAt, &job.UpdatedAt,
+		)
+		if err != nil {
+			return nil, fmt.Errorf("failed to scan job: %w", err)
+		}
+		jobs = append(jobs, job)
+	}
+	if err := rows.Err(); err != nil {
+		return nil, fmt.Errorf("error iterating jobs: %w", err)
+	}
+	return jobs, nil
+}
+
+// ============================================================================
+// Transfer Operations
+// ============================================================================
+
+// CreateTransfer inserts a new Transfer and sets its ID
+func (s *PostgresStore) CreateTransfer(t *Transfer) error {
+	const query = `
+		INSERT INTO transfers (
+			direction, path, providers, archive_count, total_size,
+			manifest_hash, manifest_json, status, error_message, start_time, end_time
+		) VALUES ($1, $2, $3, $4, $5, $6, $7, $8, $9, $10, $11)
+		RETURNING id
+	`
+	err := s.db.QueryRow(
+		query,
+		t.Direction, t.Path, t.Providers, t.ArchiveCount, t.TotalSize,
+		t.ManifestHash, t.Manifest, t.Status, t.ErrorMessage, t.StartTime, t.EndTime,
+	).Scan(&t.ID)
+	if err != nil {
+		return fmt.Errorf("failed to insert transfer: %w", err)
+	}
+	return nil
+}
+
+// UpdateTransfer updates an existing Transfer by ID
+func (s *PostgresStore) UpdateTransfer(t *Transfer) error {
+	const query = `
+		UPDATE transfers SET
+			direction = $1, path = $2, providers = $3, archive_count = $4,
+			total_size = $5, manifest_hash = $6, manifest_json = $7, status = $8,
+			error_message = $9, start_time = $10, end_time = $11
+		WHERE id = $12
+	`
+	res, err := s.db.Exec(
+		query,
+		t.Direction, t.Path, t.Providers, t.ArchiveCount, t.TotalSize,
+		t.ManifestHash, t.Manifest, t.Status, t.ErrorMessage, t.StartTime, t.EndTime, t.ID,
+	)
+	if err != nil {
+		return fmt.Errorf("failed to update transfer: %w", err)
+	}
+	return expectRows(res, fmt.Errorf("transfer not found: %d", t.ID))
+}
+
+// GetTransfer retrieves a Transfer with its manifest by ID
+func (s *PostgresStore) GetTransfer(id int64) (*Transfer, error) {
+	const query = `
+		SELECT id, direction, path, providers, archive_count, total_size,
+		       manifest_hash, manifest_json, status, error_message, start_time, end_time
+		FROM transfers WHERE id = $1
+	`
+	t := &Transfer{}
+	err := s.db.QueryRow(query, id).Scan(
+		&t.ID, &t.Direction, &t.Path, &t.Providers, &t.ArchiveCount,
+		&t.TotalSize, &t.ManifestHash, &t.Manifest, &t.Status, &t.ErrorMessage,
+		&t.StartTime, &t.EndTime,
+	)
+	if err == sql.ErrNoRows {
+		return nil, fmt.Errorf("transfer not found: %d", id)
+	}
+	if err != nil {
+		return nil, fmt.Errorf("failed to query transfer: %w", err)
+	}
+	return t, nil
+}
+
+// ListTransfers retrieves Transfers, optionally limited
+func (s *PostgresStore) ListTransfers(limit int) ([]Transfer, error) {
+	query := `
+		SELECT id, direction, path, providers, archive_count, total_size,
+		       manifest_hash, status, error_message, start_time, end_time
+		FROM transfers ORDER BY start_time DESC
+	`
+	var args []interface{}
+	if limit > 0 {
+		query += " LIMIT $1"
+		args = append(args, limit)
+	}
+
+	rows, err := s.db.Query(query, args...)
+	if err != nil {
+		return nil, fmt.Errorf("failed to query transfers: %w", err)
+	}
+	defer func() {
+		_ = rows.Close()
+	}()
+
+	var transfers []Transfer
+	for rows.Next() {
+		t := Transfer{}
+		err := rows.Scan(
+			&t.ID, &t.Direction, &t.Path, &t.Providers, &t.ArchiveCount,
+			&t.TotalSize, &t.ManifestHash, &t.Status, &t.ErrorMessage,
+			&t.StartTime, &t.EndTime,
+		)
+		if err != nil {
+			return nil, fmt.Errorf("failed to scan transfer: %w", err)
+		}
+		transfers = append(transfers, t)
+	}
+	if err := rows.Err(); err != nil {
+		return nil, fmt.Errorf("error iterating transfers: %w", err)
+	}
+	return transfers, nil
+}
+
+// CreateTransferArchive inserts a new TransferArchive and sets its ID
+func (s *PostgresStore) CreateTransferArchive(a *TransferArchive) error {
+	const query = `
+		INSERT INTO transfer_archives (
+			transfer_id, archive_name, sha256, size, validated, validated_at
+		) VALUES ($1, $2, $3, $4, $5, $6)
+		RETURNING id
+	`
+	err := s.db.QueryRow(
+		query,
+		a.TransferID, a.ArchiveName, a.SHA256, a.Size, a.Validated, a.ValidatedAt,
+	).Scan(&a.ID)
+	if err != nil {
+		return fmt.Errorf("failed to insert transfer archive: %w", err)
+	}
+	return nil
+}
+
+// MarkArchiveValidated marks a TransferArchive as validated
+func (s *PostgresStore) MarkArchiveValidated(id int64) error {
+	res, err := s.db.Exec("UPDATE transfer_archives SET validated = true, validated_at = now() WHERE id = $1", id)
+	if err != nil {
+		return fmt.Errorf("failed to mark archive validated: %w", err)
+	}
+	return expectRows(res, fmt.Errorf("transfer archive not found: %d", id))
+}
+
+// ListTransferArchives retrieves all archives for a transfer
+func (s *PostgresStore) ListTransferArchives(transferID int64) ([]TransferArchive, error) {
+	const query = `
+		SELECT id, transfer_id, archive_name, sha256, size, validated, validated_at
+		FROM transfer_archives WHERE transfer_id = $1 ORDER BY archive_name
+	`
+	rows, err := s.db.Query(query, transferID)
+	if err != nil {
+		return nil, fmt.Errorf("failed to query transfer archives: %w", err)
+	}
+	defer func() {
+		_ = rows.Close()
+	}()
+
+	var archives []TransferArchive
+	for rows.Next() {
+		a := TransferArchive{}
+		if err := rows.Scan(&a.ID, &a.TransferID, &a.ArchiveName, &a.SHA256,
+			&a.Size, &a.Validated, &a.ValidatedAt); err != nil {
+			return nil, fmt.Errorf("failed to scan transfer archive: %w", err)
+		}
+		archives = append(archives, a)
+	}
+	if err := rows.Err(); err != nil {
+		return nil, fmt.Errorf("error iterating transfer archives: %w", err)
+	}
+	return archives, nil
+}
+
+// IsArchiveValidated checks whether an archive with the given path, name, and sha256
+// has been previously validated.
+func (s *PostgresStore) IsArchiveValidated(path, archiveName, sha256 string) (bool, error) {
+	const query = `
+		SELECT EXISTS (
+			SELECT 1 FROM transfer_archives ta
+			JOIN transfers t ON ta.transfer_id = t.id
+			WHERE t.path = $1 AND ta.archive_name = $2 AND ta.sha256 = $3 AND ta.validated
+		)
+	`
+	var ok bool
+	if err := s.db.QueryRow(query, path, archiveName, sha256).Scan(&ok); err != nil {
+		return false, fmt.Errorf("failed to check archive validation: %w", err)
+	}
+	return ok, nil
+}
+
+// ============================================================================
+// ProviderConfig Operations
+// ============================================================================
+
+// CreateProviderConfig inserts a new ProviderConfig and sets its ID.
+func (s *PostgresStore) CreateProviderConfig(pc *ProviderConfig) error {
+	const query = `
+		INSERT INTO provider_configs (name, type, enabled, config_json)
+		VALUES ($1, $2, $3, $4)
+		RETURNING id
+	`
+	if err := s.db.QueryRow(query, pc.Name, pc.Type, pc.Enabled, pc.ConfigJSON).Scan(&pc.ID); err != nil {
+		return fmt.Errorf("failed to insert provider config: %w", err)
+	}
+	return nil
+}
+
+// GetProviderConfig retrieves a ProviderConfig by name.
+func (s *PostgresStore) GetProviderConfig(name string) (*ProviderConfig, error) {
+	const query = `
+		SELECT id, name, type, enabled, config_json, created_at, updated_at
+		FROM provider_configs WHERE name = $1
+	`
+	pc := &ProviderConfig{}
+	err := s.db.QueryRow(query, name).Scan(
+		&pc.ID, &pc.Name, &pc.Type, &pc.Enabled,
+		&pc.ConfigJSON, &pc.CreatedAt, &pc.UpdatedAt,
+	)
+	if err != nil {
+		return nil, fmt.Errorf("provider config not found: %s: %w", name, err)
+	}
+	return pc, nil
+}
+
+// ListProviderConfigs retrieves all ProviderConfigs ordered by name.
+func (s *PostgresStore) ListProviderConfigs() ([]ProviderConfig, error) {
+	const query = `
+		SELECT id, name, type, enabled, config_json, created_at, updated_at
+		FROM provider_configs ORDER BY name
+	`
+	rows, err := s.db.Query(query)
+	if err != nil {
+		return nil, fmt.Errorf("failed to query provider configs: %w", err)
+	}
+	defer func() {
+		_ = rows.Close()
+	}()
+
+	var configs []ProviderConfig
+	for rows.Next() {
+		pc := ProviderConfig{}
+		if err := rows.Scan(&pc.ID, &pc.Name, &pc.Type, &pc.Enabled,
+			&pc.ConfigJSON, &pc.CreatedAt, &pc.UpdatedAt); err != nil {
+			return nil, fmt.Errorf("failed to scan provider config: %w", err)
+		}
+		configs = append(configs, pc)
+	}
+	return configs, rows.Err()
+}
+
+// UpdateProviderConfig updates an existing ProviderConfig by ID.
+func (s *PostgresStore) UpdateProviderConfig(pc *ProviderConfig) error {
+	const query = `
+		UPDATE provider_configs SET
+			name = $1, type = $2, enabled = $3, config_json = $4, updated_at = now()
+		WHERE id = $5
+	`
+	res, err := s.db.Exec(query, pc.Name, pc.Type, pc.Enabled, pc.ConfigJSON, pc.ID)
+	if err != nil {
+		return fmt.Errorf("failed to update provider config: %w", err)
+	}
+	return expectRows(res, fmt.Errorf("provider config not found: %d", pc.ID))
+}
+
+// DeleteProviderConfig deletes a ProviderConfig by name.
+func (s *PostgresStore) DeleteProviderConfig(name string) error {
+	res, err := s.db.Exec("DELETE FROM provider_configs WHERE name = $1", name)
+	if err != nil {
+		return fmt.Errorf("failed to delete provider config: %w", err)
+	}
+	return expectRows(res, fmt.Errorf("provider config not found: %s", name))
+}
+
+// ToggleProviderConfig flips the enabled state of a provider.
+func (s *PostgresStore) ToggleProviderConfig(name string) error {
+	res, err := s.db.Exec("UPDATE provider_configs SET enabled = NOT enabled, updated_at = now() WHERE name = $1", name)
+	if err != nil {
+		return fmt.Errorf("failed to toggle provider config: %w", err)
+	}
+	return expectRows(res, fmt.Errorf("provider config not found: %s", name))
+}
+
+// CountProviderConfigs returns the number of provider configs.
+func (s *PostgresStore) CountProviderConfigs() (int, error) {
+	var count int
+	if err := s.db.QueryRow("SELECT COUNT(*) FROM provider_configs").Scan(&count); err != nil {
+		return 0, fmt.Errorf("failed to count provider configs: %w", err)
+	}
+	return count, nil
+}
+
+// SeedProviderConfigs populates provider_configs from a YAML providers map.
+// This is a no-op if the table already has rows.
+func (s *PostgresStore) SeedProviderConfigs(yamlProviders map[string]map[string]interface{}) error {
+	count, err := s.CountProviderConfigs()
+	if err != nil {
+		return err
+	}
+	if count > 0 {
+		s.logger.Info("provider_configs table already populated, skipping seed")
+		return nil
+	}
+
+	for name, rawCfg := range yamlProviders {
+		enabled, _ := rawCfg["enabled"].(bool)
+		configJSON, err := json.Marshal(rawCfg)
+		if err != nil {
+			s.logger.Warn("failed to marshal provider config for seeding", "name", name, "error", err)
+			continue
+		}
+		pc := &ProviderConfig{
+			Name:       name,
+			Type:       seedProviderType(name),
+			Enabled:    enabled,
+			ConfigJSON: string(configJSON),
+		}
+		if err := s.CreateProviderConfig(pc); err != nil {
+			s.logger.Warn("failed to seed provider config", "name", name, "error", err)
+			continue
+		}
+		if _, err := s.AddProviderConfigRevision(name, "system", "seeded from config file"); err != nil {
+			s.logger.Warn("failed to record seeded provider config revision", "name", name, "error", err)
+		}
+	}
+
+	s.logger.Info("seeded provider configs from YAML", "count", len(yamlProviders))
+	return nil
+}
+
+// UpsertUpstreamProviderConfig inserts or replaces the configuration of
+// provider pc.Name on pc.SourceHost.
+func (s *PostgresStore) UpsertUpstreamProviderConfig(pc *UpstreamProviderConfig) error {
+	const query = `
+		INSERT INTO upstream_provider_configs (source_host, name, type, config_json, imported_at)
+		VALUES ($1, $2, $3, $4, $5)
+		ON CONFLICT (source_host, name) DO UPDATE SET
+			type = excluded.type, config_json = excluded.config_json, imported_at = excluded.imported_at
+	`
+	if _, err := s.db.Exec(query, pc.SourceHost, pc.Name, pc.Type, pc.ConfigJSON, pc.ImportedAt); err != nil {
+		return fmt.Errorf("failed to upsert upstream provider config: %w", err)
+	}
+	return nil
+}
+
+// GetUpstreamProviderConfig retrieves the configuration of provider name as
+// last imported from sourceHost.
+func (s *PostgresStore) GetUpstreamProviderConfig(sourceHost, name string) (*UpstreamProviderConfig, error) {
+	const query = `
+		SELECT source_host, name, type, config_json, imported_at
+		FROM upstream_provider_configs WHERE source_host = $1 AND name = $2
+	`
+	pc := &UpstreamProviderConfig{}
+	err := s.db.QueryRow(query, sourceHost, name).Scan(
+		&pc.SourceHost, &pc.Name, &pc.Type, &pc.ConfigJSON, &pc.ImportedAt,
+	)
+	if err != nil {
+		if err == sql.ErrNoRows {
+			return nil, fmt.Errorf("upstream provider config not found: %s/%s", sourceHost, name)
+		}
+		return nil, fmt.Errorf("failed to query upstream provider config: %w", err)
+	}
+	return pc, nil
+}
+
+// ============================================================================
+// Provider config revisions
+// ============================================================================
+
+// AddProviderConfigRevision snapshots the current config of provider name
+// as its next revision.
+func (s *PostgresStore) AddProviderConfigRevision(name, author, comment string) (*ProviderConfigRevision, error) {
+	query := `
+		INSERT INTO provider_config_revisions (provider, revision, type, enabled, config_json, author, comment, created_at)
+		SELECT name,
+			(SELECT COALESCE(MAX(revision), 0) + 1 FROM provider_config_revisions WHERE provider = $1),
+			type, enabled, config_json, $2, $3, $4
+		FROM provider_configs WHERE name = $1
+		RETURNING ` + revisionColumns
+	rev := &ProviderConfigRevision{}
+	if err := scanRevision(s.db.QueryRow(query, name, author, comment, time.Now().UTC()), rev); err != nil {
+		if err == sql.ErrNoRows {
+			return nil, fmt.Errorf("provider config not found: %s", name)
+		}
+		return nil, fmt.Errorf("failed to insert provider config revision: %w", err)
+	}
+	return rev, nil
+}
+
+// ListProviderConfigRevisions returns the revisions of provider name,
+// newest first.
+func (s *PostgresStore) ListProviderConfigRevisions(name string) ([]ProviderConfigRevision, error) {
+	rows, err := s.db.Query("SELECT "+revisionColumns+" FROM provider_config_revisions WHERE provider = $1 ORDER BY revision DESC", name)
+	if err != nil {
+		return nil, fmt.Errorf("failed to query provider config revisions: %w", err)
+	}
+	return collectRevisions(rows)
+}
+
+// GetProviderConfigRevision retrieves one revision of provider name.
+func (s *PostgresStore) GetProviderConfigRevision(name string, revision int) (*ProviderConfigRevision, error) {
+	rev := &ProviderConfigRevision{}
+	err := scanRevision(s.db.QueryRow(
+		"SELECT "+revisionColumns+" FROM provider_config_revisions WHERE provider = $1 AND revision = $2",
+		name, revision), rev)
+	if err != nil {
+		if err == sql.ErrNoRows {
+			return nil, fmt.Errorf("revision %d of provider %s not found", revision, name)
+		}
+		return nil, fmt.Errorf("failed to query provider config revision: %w", err)
+	}
+	return rev, nil
+}
+
+// ============================================================================
+// Search index
+// ============================================================================
+
+// ReplaceSearchEntries replaces the search index entries of provider.
+func (s *PostgresStore) ReplaceSearchEntries(provider string, entries []SearchEntry) error {
+	tx, err := s.db.Begin()
+	if err != nil {
+		return fmt.Errorf("failed to begin transaction: %w", err)
+	}
+	defer func() {
+		_ = tx.Rollback()
+	}()
+
+	if _, err := tx.Exec("DELETE FROM search_index WHERE provider = $1", provider); err != nil {
+		return fmt.Errorf("failed to clear search entries: %w", err)
+	}
+
+	stmt, err := tx.Prepare(`
+		INSERT INTO search_index (provider, type, name, version, arch, path, digest, search_text)
+		VALUES ($1, $2, $3, $4, $5, $6, $7, $8)
+	`)
+	if err != nil {
+		return fmt.Errorf("failed to prepare search entry insert: %w", err)
+	}
+	defer func() {
+		_ = stmt.Close()
+	}()
+
+	for _, e := range entries {
+		text := strings.Join(searchTerms(strings.Join([]string{e.Name, e.Version, e.Arch, e.Path, e.Digest}, " ")), " ")
+		if _, err := stmt.Exec(provider, e.Type, e.Name, e.Version, e.Arch, e.Path, e.Digest, text); err != nil {
+			return fmt.Errorf("failed to insert search entry: %w", err)
+		}
+	}
+
+	if err := tx.Commit(); err != nil {
+		return fmt.Errorf("failed to commit search entries: %w", err)
+	}
+	return nil
+}
+
+// Search returns the index entries matching q. Each word of q.Text matches
+// anywhere in the searchable columns.
+func (s *PostgresStore) Search(q SearchQuery) ([]SearchEntry, error) {
+	var where []string
+	var args []interface{}
+	arg := func(v interface{}) string {
+		args = append(args, v)
+		return "$" + strconv.Itoa(len(args))
+	}
+
+	for _, term := range searchTerms(q.Text) {
+		where = append(where, "search_text LIKE '%' || "+arg(term)+" || '%'")
+	}
+	if q.Provider != "" {
+		where = append(where, "provider = "+arg(q.Provider))
+	}
+	if q.Type != "" {
+		where = append(where, "type = "+arg(q.Type))
+	}
+	if q.Version != "" {
+		dot, dash := versionPatterns(q.Version)
+		where = append(where, fmt.Sprintf(`(version = %s OR version LIKE %s ESCAPE '\' OR version LIKE %s ESCAPE '\')`,
+			arg(q.Version), arg(dot), arg(dash)))
+	}
+
+	query := "SELECT provider, type, name, version, arch, path, digest FROM search_index"
+	if len(where) > 0 {
+		query += " WHERE " + strings.Join(where, " AND ")
+	}
+	query += " ORDER BY provider, type, name, version LIMIT " + arg(searchLimit(q.Limit))
+
+	rows, err := s.db.Query(query, args...)
+	if err != nil {
+		return nil, fmt.Errorf("failed to search index: %w", err)
+	}
+	defer func() {
+		_ = rows.Close()
+	}()
+
+	var entries []SearchEntry
+	for rows.Next() {
+		var e SearchEntry
+		if err := rows.Scan(&e.Provider, &e.Type, &e.Name, &e.Version, &e.Arch, &e.Path, &e.Digest); err != nil {
+			return nil, fmt.Errorf("failed to scan search entry: %w", err)
+		}
+		entries = append(entries, e)
+	}
+	if err := rows.Err(); err != nil {
+		return nil, fmt.Errorf("error iterating search entries: %w", err)
+	}
+	return entries, nil
+}
+
+// ============================================================================
+// Audit log
+// ============================================================================
+
+// AppendAuditEvent stores ev and sets its ID. With chain set, ev is linked
+// to the newest stored event and its Hash and PrevHash are set. A
+// transaction-scoped advisory lock serializes appends across instances.
+func (s *PostgresStore) AppendAuditEvent(ev *AuditEvent, chain bool) error {
+	prepareAuditEvent(ev)
+
+	tx, err := s.db.Begin()
+	if err != nil {
+		return fmt.Errorf("failed to begin transaction: %w", err)
+	}
+	defer func() {
+		_ = tx.Rollback()
+	}()
+
+	if chain {
+		if _, err := tx.Exec("SELECT pg_advisory_xact_lock(hashtext('airgap_audit'))"); err != nil {
+			return fmt.Errorf("failed to lock audit log: %w", err)
+		}
+		if err := tx.QueryRow("SELECT hash FROM audit_events ORDER BY id DESC LIMIT 1").Scan(&ev.PrevHash); err != nil && err != sql.ErrNoRows {
+			return fmt.Errorf("failed to read last audit hash: %w", err)
+		}
+		ev.Hash = ev.ComputeHash()
+	}
+
+	err = tx.QueryRow(`
+		INSERT INTO audit_events (
+			time, actor, source_ip, source, action, target,
+			before_json, after_json, outcome, detail, prev_hash, hash
+		) VALUES ($1, $2, $3, $4, $5, $6, $7, $8, $9, $10, $11, $12)
+		RETURNING id
+	`,
+		ev.Time, ev.Actor, ev.SourceIP, ev.Source, ev.Action, ev.Target,
+		ev.Before, ev.After, ev.Outcome, ev.Detail, ev.PrevHash, ev.Hash,
+	).Scan(&ev.ID)
+	if err != nil {
+		return fmt.Errorf("failed to insert audit event: %w", err)
+	}
+
+	if err := tx.Commit(); err != nil {
+		return fmt.Errorf("failed to commit audit event: %w", err)
+	}
+	return nil
+}
+
+// ListAuditEvents returns the audit events matching f, newest first. A zero
+// Limit returns all matching events.
+func (s *PostgresStore) ListAuditEvents(f AuditFilter) ([]AuditEvent, error) {
+	var args []interface{}
+	arg := func(v interface{}) string {
+		args = append(args, v)
+		return "$" + strconv.Itoa(len(args))
+	}
+	query := "SELECT " + auditColumns + " FROM audit_events" + auditWhere(f, arg) + " ORDER BY id DESC"
+	if f.Limit > 0 {
+		query += " LIMIT " + arg(f.Limit)
+	}
+
+	rows, err := s.db.Query(query, args...)
+	if err != nil {
+		return nil, fmt.Errorf("failed to query audit events: %w", err)
+	}
+	return collectAuditEvents(rows)
+}
+
+// ============================================================================
+// Maintenance
+// ============================================================================
+
+// Prune deletes history outside policy as of now in one transaction. Running
+// sync runs and runs still referenced by file records are kept.
+func (s *PostgresStore) Prune(policy RetentionPolicy, now time.Time) (*PruneResult, error) {
+	tx, err := s.db.Begin()
+	if err != nil {
+		return nil, fmt.Errorf("failed to begin transaction: %w", err)
+	}
+	defer func() {
+		_ = tx.Rollback()
+	}()
+
+	result := &PruneResult{}
+	exec := func(count *int64, what, query string, args ...interface{}) error {
+		res, err := tx.Exec(query, args...)
+		if err != nil {
+			return fmt.Errorf("failed to prune %s: %w", what, err)
+		}
+		*count, _ = res.RowsAffected()
+		return nil
+	}
+
+	if policy.SyncRunsPerProvider > 0 {
+		if err := exec(&result.SyncRuns, "sync runs", `
+			DELETE FROM sync_runs WHERE id IN (
+				SELECT id FROM (
+					SELECT id, status, ROW_NUMBER() OVER (
+						PARTITION BY provider ORDER BY start_time DESC, id DESC
+					) AS rn FROM sync_runs
+				) ranked WHERE rn > $1 AND status != 'running'
+			) AND id NOT IN (SELECT sync_run_id FROM file_records)
+		`, policy.SyncRunsPerProvider); err != nil {
+			return nil, err
+		}
+	}
+
+	if policy.ResolvedFailuresAge > 0 {
+		if err := exec(&result.FailedFiles, "failed files",
+			`DELETE FROM failed_files WHERE resolved AND last_failure < $1`,
+			now.Add(-policy.ResolvedFailuresAge)); err != nil {
+			return nil, err
+		}
+	}
+
+	if policy.TransfersAge > 0 {
+		cutoff := now.Add(-policy.TransfersAge)
+		if err := exec(&result.TransferArchives, "transfer archives", `
+			DELETE FROM transfer_archives WHERE transfer_id IN (
+				SELECT id FROM transfers WHERE status != 'running' AND start_time < $1
+			)
+		`, cutoff); err != nil {
+			return nil, err
+		}
+		if err := exec(&result.Transfers, "transfers",
+			`DELETE FROM transfers WHERE status != 'running' AND start_time < $1`, cutoff); err != nil {
+			return nil, err
+		}
+	}
+
+	if policy.JobsAge > 0 {
+		if err := exec(&result.Jobs, "jobs",
+			`DELETE FROM jobs WHERE status IN ('completed', 'failed') AND updated_at < $1`,
+			now.Add(-policy.JobsAge)); err != nil {
+			return nil, err
+		}
+	}
+
+	if err := tx.Commit(); err != nil {
+		return nil, fmt.Errorf("failed to commit prune: %w", err)
+	}
+	return result, nil
+}
+
+// Size returns the size of the current database in bytes.
+func (s *PostgresStore) Size() (int64, error) {
+	var size int64
+	if err := s.db.QueryRow("SELECT pg_database_size(current_database())").Scan(&size); err != nil {
+		return 0, fmt.Errorf("failed to read database size: %w", err)
+	}
+	return size, nil
+}
+
+// Optimize reclaims dead tuples and refreshes planner statistics.
+func (s *PostgresStore) Optimize() error {
+	if _, err := s.db.Exec("VACUUM ANALYZE"); err != nil {
+		return fmt.Errorf("failed to vacuum database: %w", err)
+	}
+	return nil
+}
@@ -0,0 +1,251 @@
+package store
+
+import "fmt"
+
+// postgresMigrations lists every PostgreSQL schema change in order. The
+// PostgreSQL schema starts at the SQLite schema's version 8 and is
+// versioned separately from it.
+var postgresMigrations = []migration{
+	{
+		version: 1,
+		sql: `
+			CREATE TABLE sync_runs (
+				id BIGSERIAL PRIMARY KEY,
+				provider TEXT NOT NULL,
+				start_time TIMESTAMPTZ NOT NULL,
+				end_time TIMESTAMPTZ NOT NULL,
+				files_downloaded INTEGER NOT NULL DEFAULT 0,
+				files_deleted INTEGER NOT NULL DEFAULT 0,
+				files_skipped INTEGER NOT NULL DEFAULT 0,
+				files_failed INTEGER NOT NULL DEFAULT 0,
+				bytes_transferred BIGINT NOT NULL DEFAULT 0,
+				status TEXT NOT NULL DEFAULT 'running',
+				error_message TEXT NOT NULL DEFAULT '',
+				signature_status TEXT NOT NULL DEFAULT '',
+				source_host TEXT NOT NULL DEFAULT '',
+				upstream_id BIGINT NOT NULL DEFAULT 0
+			);
+			CREATE INDEX idx_sync_runs_provider ON sync_runs(provider, start_time DESC);
+			CREATE UNIQUE INDEX idx_sync_runs_upstream ON sync_runs(source_host, upstream_id)
+				WHERE source_host != '';
+
+			CREATE TABLE file_records (
+				id BIGSERIAL PRIMARY KEY,
+				provider TEXT NOT NULL,
+				path TEXT NOT NULL,
+				size BIGINT NOT NULL DEFAULT 0,
+				sha256 TEXT NOT NULL DEFAULT '',
+				last_modified TIMESTAMPTZ NOT NULL,
+				last_verified TIMESTAMPTZ NOT NULL,
+				sync_run_id BIGINT NOT NULL DEFAULT 0,
+				UNIQUE(provider, path)
+			);
+
+			CREATE TABLE jobs (
+				id BIGSERIAL PRIMARY KEY,
+				type TEXT NOT NULL,
+				provider TEXT NOT NULL DEFAULT '',
+				cron_expr TEXT NOT NULL DEFAULT '',
+				status TEXT NOT NULL DEFAULT 'scheduled',
+				last_run TIMESTAMPTZ NOT NULL,
+				next_run TIMESTAMPTZ NOT NULL,
+				created_at TIMESTAMPTZ NOT NULL DEFAULT now(),
+				updated_at TIMESTAMPTZ NOT NULL DEFAULT now()
+			);
+
+			CREATE TABLE transfers (
+				id BIGSERIAL PRIMARY KEY,
+				direction TEXT NOT NULL,
+				path TEXT NOT NULL,
+				providers TEXT NOT NULL DEFAULT '',
+				archive_count INTEGER NOT NULL DEFAULT 0,
+				total_size BIGINT NOT NULL DEFAULT 0,
+				manifest_hash TEXT NOT NULL DEFAULT '',
+				status TEXT NOT NULL DEFAULT 'running',
+				error_message TEXT NOT NULL DEFAULT '',
+				start_time TIMESTAMPTZ NOT NULL,
+				end_time TIMESTAMPTZ NOT NULL
+			);
+
+			CREATE TABLE transfer_archives (
+				id BIGSERIAL PRIMARY KEY,
+				transfer_id BIGINT NOT NULL REFERENCES transfers(id),
+				archive_name TEXT NOT NULL,
+				sha256 TEXT NOT NULL,
+				size BIGINT NOT NULL DEFAULT 0,
+				validated BOOLEAN NOT NULL DEFAULT false,
+				validated_at TIMESTAMPTZ NOT NULL
+			);
+
+			CREATE TABLE failed_files (
+				id BIGSERIAL PRIMARY KEY,
+				provider TEXT NOT NULL,
+				file_path TEXT NOT NULL,
+				url TEXT NOT NULL DEFAULT '',
+				dest_path TEXT NOT NULL DEFAULT '',
+				expected_checksum TEXT NOT NULL DEFAULT '',
+				expected_size BIGINT NOT NULL DEFAULT 0,
+				error TEXT NOT NULL DEFAULT '',
+				retry_count INTEGER NOT NULL DEFAULT 0,
+				first_failure TIMESTAMPTZ NOT NULL,
+				last_failure TIMESTAMPTZ NOT NULL,
+				resolved BOOLEAN NOT NULL DEFAULT false
+			);
+
+			CREATE TABLE provider_configs (
+				id BIGSERIAL PRIMARY KEY,
+				name TEXT NOT NULL UNIQUE,
+				type TEXT NOT NULL,
+				enabled BOOLEAN NOT NULL DEFAULT false,
+				config_json TEXT NOT NULL DEFAULT '{}',
+				created_at TIMESTAMPTZ NOT NULL DEFAULT now(),
+				updated_at TIMESTAMPTZ NOT NULL DEFAULT now()
+			);
+
+			CREATE TABLE upstream_provider_configs (
+				source_host TEXT NOT NULL,
+				name TEXT NOT NULL,
+				type TEXT NOT NULL,
+				config_json TEXT NOT NULL DEFAULT '{}',
+				imported_at TIMESTAMPTZ NOT NULL,
+				PRIMARY KEY (source_host, name)
+			);
+		`,
+	},
+	{
+		version: 2,
+		sql: `
+			ALTER TABLE file_records
+				ADD COLUMN source_url TEXT NOT NULL DEFAULT '',
+				ADD COLUMN mirror_host TEXT NOT NULL DEFAULT '',
+				ADD COLUMN etag TEXT NOT NULL DEFAULT '',
+				ADD COLUMN upstream_last_modified TEXT NOT NULL DEFAULT '',
+				ADD COLUMN response_time_ms BIGINT NOT NULL DEFAULT 0;
+		`,
+	},
+	{
+		// search_text holds the lower-cased searchable columns, matched
+		// with LIKE in place of SQLite's FTS5 index.
+		version: 3,
+		sql: `
+			CREATE TABLE search_index (
+				id BIGSERIAL PRIMARY KEY,
+				provider TEXT NOT NULL,
+				type TEXT NOT NULL,
+				name TEXT NOT NULL DEFAULT '',
+				version TEXT NOT NULL DEFAULT '',
+				arch TEXT NOT NULL DEFAULT '',
+				path TEXT NOT NULL DEFAULT '',
+				digest TEXT NOT NULL DEFAULT '',
+				search_text TEXT NOT NULL DEFAULT ''
+			);
+			CREATE INDEX idx_search_index_provider ON search_index(provider);
+		`,
+	},
+	{
+		version: 4,
+		sql: `
+			CREATE TABLE audit_events (
+				id BIGSERIAL PRIMARY KEY,
+				time TIMESTAMPTZ NOT NULL,
+				actor TEXT NOT NULL DEFAULT '',
+				source_ip TEXT NOT NULL DEFAULT '',
+				source TEXT NOT NULL DEFAULT '',
+				action TEXT NOT NULL,
+				target TEXT NOT NULL DEFAULT '',
+				before_json TEXT NOT NULL DEFAULT '',
+				after_json TEXT NOT NULL DEFAULT '',
+				outcome TEXT NOT NULL,
+				detail TEXT NOT NULL DEFAULT '',
+				prev_hash TEXT NOT NULL DEFAULT '',
+				hash TEXT NOT NULL DEFAULT ''
+			);
+			CREATE INDEX idx_audit_events_time ON audit_events(time);
+
+			CREATE FUNCTION audit_events_append_only() RETURNS trigger AS $$
+			BEGIN
+				RAISE EXCEPTION 'audit_events is append-only';
+			END;
+			$$ LANGUAGE plpgsql;
+
+			CREATE TRIGGER audit_events_append_only
+				BEFORE UPDATE OR DELETE ON audit_events
+				FOR EACH ROW EXECUTE FUNCTION audit_events_append_only();
+		`,
+	},
+	{
+		version: 5,
+		sql: `
+			CREATE TABLE provider_config_revisions (
+				id BIGSERIAL PRIMARY KEY,
+				provider TEXT NOT NULL,
+				revision INTEGER NOT NULL,
+				type TEXT NOT NULL,
+				enabled BOOLEAN NOT NULL DEFAULT false,
+				config_json TEXT NOT NULL DEFAULT '{}',
+				author TEXT NOT NULL DEFAULT '',
+				comment TEXT NOT NULL DEFAULT '',
+				created_at TIMESTAMPTZ NOT NULL DEFAULT now(),
+				UNIQUE (provider, revision)
+			);
+
+			INSERT INTO provider_config_revisions (provider, revision, type, enabled, config_json, author, comment, created_at)
+			SELECT name, 1, type, enabled, config_json, 'system', 'initial revision', updated_at FROM provider_configs;
+		`,
+	},
+	{
+		version: 6,
+		sql: `
+			ALTER TABLE transfers ADD COLUMN manifest_json TEXT NOT NULL DEFAULT '';
+		`,
+	},
+}
+
+// migrate runs all pending PostgreSQL migrations. An advisory lock keeps
+// instances starting together from migrating concurrently.
+func (s *PostgresStore) migrate() error {
+	if _, err := s.db.Exec(`
+		CREATE TABLE IF NOT EXISTS migrations (
+			version INTEGER PRIMARY KEY,
+			applied_at TIMESTAMPTZ NOT NULL DEFAULT now()
+		)
+	`); err != nil {
+		return fmt.Errorf("failed to create migrations table: %w", err)
+	}
+
+	tx, err := s.db.Begin()
+	if err != nil {
+		return fmt.Errorf("failed to begin transaction: %w", err)
+	}
+	defer func() {
+		_ = tx.Rollback()
+	}()
+
+	if _, err := tx.Exec("SELECT pg_advisory_xact_lock(hashtext('airgap_migrations'))"); err != nil {
+		return fmt.Errorf("failed to lock migrations: %w", err)
+	}
+
+	var currentVersion int
+	if err := tx.QueryRow("SELECT COALESCE(MAX(version), 0) FROM migrations").Scan(&currentVersion); err != nil {
+		return fmt.Errorf("failed to get current migration version: %w", err)
+	}
+	s.logger.Info("Current schema version", "version", currentVersion, "driver", DriverPostgres)
+
+	for _, mig := range postgresMigrations {
+		if mig.version <= currentVersion {
+			continue
+		}
+		s.logger.Info("Running migration", "version", mig.version, "driver", DriverPostgres)
+		if _, err := tx.Exec(mig.sql); err != nil {
+			return fmt.Errorf("failed to run migration %d: %w", mig.version, err)
+		}
+		if _, err := tx.Exec("INSERT INTO migrations (version) VALUES ($1)", mig.version); err != nil {
+			return fmt.Errorf("failed to record migration %d: %w", mig.version, err)
+		}
+	}
+
+	if err := tx.Commit(); err != nil {
+		return fmt.Errorf("failed to commit migrations: %w", err)
+	}
+	return nil
+}
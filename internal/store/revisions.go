@@ -0,0 +1,90 @@
+package store
+
+import (
+	"database/sql"
+	"fmt"
+	"time"
+)
+
+const revisionColumns = `id, provider, revision, type, enabled, config_json, author, comment, created_at`
+
+func scanRevision(row rowScanner, rev *ProviderConfigRevision) error {
+	return row.Scan(
+		&rev.ID, &rev.Provider, &rev.Revision, &rev.Type, &rev.Enabled,
+		&rev.ConfigJSON, &rev.Author, &rev.Comment, &rev.CreatedAt,
+	)
+}
+
+// collectRevisions scans and closes rows of revision columns.
+func collectRevisions(rows *sql.Rows) ([]ProviderConfigRevision, error) {
+	defer func() {
+		_ = rows.Close()
+	}()
+
+	var revs []ProviderConfigRevision
+	for rows.Next() {
+		var rev ProviderConfigRevision
+		if err := scanRevision(rows, &rev); err != nil {
+			return nil, fmt.Errorf("failed to scan provider config revision: %w", err)
+		}
+		revs = append(revs, rev)
+	}
+	if err := rows.Err(); err != nil {
+		return nil, fmt.Errorf("error iterating provider config revisions: %w", err)
+	}
+	return revs, nil
+}
+
+// AddProviderConfigRevision snapshots the current config of provider name
+// as its next revision.
+func (s *SQLiteStore) AddProviderConfigRevision(name, author, comment string) (*ProviderConfigRevision, error) {
+	const query = `
+		INSERT INTO provider_config_revisions (provider, revision, type, enabled, config_json, author, comment, created_at)
+		SELECT name,
+			(SELECT COALESCE(MAX(revision), 0) + 1 FROM provider_config_revisions WHERE provider = ?),
+			type, enabled, config_json, ?, ?, ?
+		FROM provider_configs WHERE name = ?
+	`
+	result, err := s.db.Exec(query, name, author, comment, time.Now().UTC(), name)
+	if err != nil {
+		return nil, fmt.Errorf("failed to insert provider config revision: %w", err)
+	}
+	if n, err := result.RowsAffected(); err == nil && n == 0 {
+		return nil, fmt.Errorf("provider config not found: %s", name)
+	}
+	id, err := result.LastInsertId()
+	if err != nil {
+		return nil, fmt.Errorf("failed to get last insert id: %w", err)
+	}
+
+	rev := &ProviderConfigRevision{}
+	if err := scanRevision(s.db.QueryRow("SELECT "+revisionColumns+" FROM provider_config_revisions WHERE id = ?", id), rev); err != nil {
+		return nil, fmt.Errorf("failed to read provider config revision: %w", err)
+	}
+	return rev, nil
+}
+
+// ListProviderConfigRevisions returns the revisions of provider name,
+// newest first.
+func (s *SQLiteStore) ListProviderConfigRevisions(name string) ([]ProviderConfigRevision, error) {
+	rows, err := s.db.Query("SELECT "+revisionColumns+" FROM provider_config_revisions WHERE provider = ? ORDER BY revision DESC", name)
+	if err != nil {
+		return nil, fmt.Errorf("failed to query provider config revisions: %w", err)
+	}
+	return collectRevisions(rows)
+}
+
+// GetProviderConfigRevision retrieves one revision of provider name.
+func (s *SQLiteStore) GetProviderConfigRevision(name string, revision int) (*ProviderConfigRevision, error) {
+	rev := &ProviderConfigRevision{}
+	err := scanRevision(s.db.QueryRow(
+		"SELECT "+revisionColumns+" FROM provider_config_revisions WHERE provider = ? AND revision = ?",
+		name, revision), rev)
+	if err != nil {
+		if err == sql.ErrNoRows {
+			return nil, fmt.Errorf("revision %d of provider %s not found", revision, name)
+		}
+		return nil, fmt.Errorf("failed to query provider config revision: %w", err)
+	}
+	return rev, nil
+}
@@ -0,0 +1,139 @@
+package store
+
+import (
+	"fmt"
+	"strings"
+	"unicode"
+)
+
+// Search result limits.
+const (
+	defaultSearchLimit = 50
+	maxSearchLimit     = 500
+)
+
+// searchTerms splits text into lower-cased words the way SQLite's default
+// FTS5 tokenizer does, so "zsh-5.8" becomes zsh, 5 and 8.
+func searchTerms(text string) []string {
+	return strings.FieldsFunc(strings.ToLower(text), func(r rune) bool {
+		return !unicode.IsLetter(r) && !unicode.IsDigit(r)
+	})
+}
+
+// searchLimit clamps a requested result limit.
+func searchLimit(limit int) int {
+	if limit <= 0 {
+		return defaultSearchLimit
+	}
+	if limit > maxSearchLimit {
+		return maxSearchLimit
+	}
+	return limit
+}
+
+// versionPatterns returns LIKE patterns matching versions that extend
+// version at a "." or "-" boundary.
+func versionPatterns(version string) (string, string) {
+	escaped := strings.NewReplacer(`\`, `\\`, `%`, `\%`, `_`, `\_`).Replace(version)
+	return escaped + ".%", escaped + "-%"
+}
+
+// ReplaceSearchEntries replaces the search index entries of provider.
+func (s *SQLiteStore) ReplaceSearchEntries(provider string, entries []SearchEntry) error {
+	tx, err := s.db.Begin()
+	if err != nil {
+		return fmt.Errorf("failed to begin transaction: %w", err)
+	}
+	defer func() {
+		_ = tx.Rollback()
+	}()
+
+	if _, err := tx.Exec("DELETE FROM search_index WHERE provider = ?", provider); err != nil {
+		return fmt.Errorf("failed to clear search entries: %w", err)
+	}
+
+	stmt, err := tx.Prepare(`
+		INSERT INTO search_index (provider, type, name, version, arch, path, digest)
+		VALUES (?, ?, ?, ?, ?, ?, ?)
+	`)
+	if err != nil {
+		return fmt.Errorf("failed to prepare search entry insert: %w", err)
+	}
+	defer func() {
+		_ = stmt.Close()
+	}()
+
+	for _, e := range entries {
+		if _, err := stmt.Exec(provider, e.Type, e.Name, e.Version, e.Arch, e.Path, e.Digest); err != nil {
+			return fmt.Errorf("failed to insert search entry: %w", err)
+		}
+	}
+
+	if err := tx.Commit(); err != nil {
+		return fmt.Errorf("failed to commit search entries: %w", err)
+	}
+	return nil
+}
+
+// Search returns the index entries matching q, best matches first. Each
+// word of q.Text matches as a prefix.
+func (s *SQLiteStore) Search(q SearchQuery) ([]SearchEntry, error) {
+	var where []string
+	var args []interface{}
+
+	terms := searchTerms(q.Text)
+	if len(terms) > 0 {
+		match := make([]string, len(terms))
+		for i, term := range terms {
+			match[i] = `"` + term + `"*`
+		}
+		where = append(where, "search_index MATCH ?")
+		args = append(args, strings.Join(match, " "))
+	}
+	if q.Provider != "" {
+		where = append(where, "provider = ?")
+		args = append(args, q.Provider)
+	}
+	if q.Type != "" {
+		where = append(where, "type = ?")
+		args = append(args, q.Type)
+	}
+	if q.Version != "" {
+		dot, dash := versionPatterns(q.Version)
+		where = append(where, `(version = ? OR version LIKE ? ESCAPE '\' OR version LIKE ? ESCAPE '\')`)
+		args = append(args, q.Version, dot, dash)
+	}
+
+	query := "SELECT provider, type, name, version, arch, path, digest FROM search_index"
+	if len(where) > 0 {
+		query += " WHERE " + strings.Join(where, " AND ")
+	}
+	if len(terms) > 0 {
+		query += " ORDER BY rank"
+	} else {
+		query += " ORDER BY provider, type, name, version"
+	}
+	query += " LIMIT ?"
+	args = append(args, searchLimit(q.Limit))
+
+	rows, err := s.db.Query(query, args...)
+	if err != nil {
+		return nil, fmt.Errorf("failed to search index: %w", err)
+	}
+	defer func() {
+		_ = rows.Close()
+	}()
+
+	var entries []SearchEntry
+	for rows.Next() {
+		var e SearchEntry
+		if err := rows.Scan(&e.Provider, &e.Type, &e.Name, &e.Version, &e.Arch, &e.Path, &e.Digest); err != nil {
+			return nil, fmt.Errorf("failed to scan search entry: %w", err)
+		}
+		entries = append(entries, e)
+	}
+	if err := rows.Err(); err != nil {
+		return nil, fmt.Errorf("error iterating search entries: %w", err)
+	}
+	return entries, nil
+}
@@ -5,18 +5,20 @@ import (
 	"encoding/json"
 	"fmt"
 	"log/slog"
+	"sync"
 
 	_ "modernc.org/sqlite"
 )
 
-// Store provides SQLite-backed persistence
-type Store struct {
-	db     *sql.DB
-	logger *slog.Logger
+// SQLiteStore is the SQLite implementation of Store
+type SQLiteStore struct {
+	db      *sql.DB
+	logger  *slog.Logger
+	auditMu sync.Mutex
 }
 
-// New creates a new Store, opening the SQLite database and running migrations
-func New(dbPath string, logger *slog.Logger) (*Store, error) {
+// New creates a SQLiteStore, opening the SQLite database and running migrations
+func New(dbPath string, logger *slog.Logger) (*SQLiteStore, error) {
 	db, err := sql.Open("sqlite", dbPath)
 	if err != nil {
 		return nil, fmt.Errorf("failed to open database: %w", err)
@@ -28,7 +30,7 @@ func New(dbPath string, logger *slog.Logger) (*Store, error) {
 		return nil, fmt.Errorf("failed to ping database: %w", err)
 	}
 
-	s := &Store{
+	s := &SQLiteStore{
 		db:     db,
 		logger: logger,
 	}
@@ -44,7 +46,7 @@ func New(dbPath string, logger *slog.Logger) (*Store, error) {
 }
 
 // Close closes the database connection
-func (s *Store) Close() error {
+func (s *SQLiteStore) Close() error {
 	if err := s.db.Close(); err != nil {
 		return fmt.Errorf("failed to close database: %w", err)
 	}
@@ -56,19 +58,21 @@ func (s *Store) Close() error {
 // ============================================================================
 
 // CreateSyncRun inserts a new SyncRun and sets its ID
-func (s *Store) CreateSyncRun(run *SyncRun) error {
+func (s *SQLiteStore) CreateSyncRun(run *SyncRun) error {
 	const query = `
 		INSERT INTO sync_runs (
 			provider, start_time, end_time, files_downloaded, files_deleted,
-			files_skipped, files_failed, bytes_transferred, status, error_message
-		) VALUES (?, ?, ?, ?, ?, ?, ?, ?, ?, ?)
+			files_skipped, files_failed, bytes_transferred, status, error_message,
+			signature_status, source_host, upstream_id
+		) VALUES (?, ?, ?, ?, ?, ?, ?, ?, ?, ?, ?, ?, ?)
 	`
 
 	result, err := s.db.Exec(
 		query,
 		run.Provider, run.StartTime, run.EndTime, run.FilesDownloaded,
 		run.FilesDeleted, run.FilesSkipped, run.FilesFailed,
-		run.BytesTransferred, run.Status, run.ErrorMessage,
+		run.BytesTransferred, run.Status, run.ErrorMessage, run.SignatureStatus,
+		run.SourceHost, run.UpstreamID,
 	)
 	if err != nil {
 		return fmt.Errorf("failed to insert sync run: %w", err)
@@ -84,12 +88,13 @@ func (s *Store) CreateSyncRun(run *SyncRun) error {
 }
 
 // UpdateSyncRun updates an existing SyncRun by ID
-func (s *Store) UpdateSyncRun(run *SyncRun) error {
+func (s *SQLiteStore) UpdateSyncRun(run *SyncRun) error {
 	const query = `
 		UPDATE sync_runs SET
 			provider = ?, start_time = ?, end_time = ?, files_downloaded = ?,
 			files_deleted = ?, files_skipped = ?, files_failed = ?,
-			bytes_transferred = ?, status = ?, error_message = ?
+			bytes_transferred = ?, status = ?, error_message = ?,
+			signature_status = ?
 		WHERE id = ?
 	`
 
@@ -97,7 +102,7 @@ func (s *Store) UpdateSyncRun(run *SyncRun) error {
 		query,
 		run.Provider, run.StartTime, run.EndTime, run.FilesDownloaded,
 		run.FilesDeleted, run.FilesSkipped, run.FilesFailed,
-		run.BytesTransferred, run.Status, run.ErrorMessage, run.ID,
+		run.BytesTransferred, run.Status, run.ErrorMessage, run.SignatureStatus, run.ID,
 	)
 	if err != nil {
 		return fmt.Errorf("failed to update sync run: %w", err)
@@ -116,10 +121,11 @@ func (s *Store) UpdateSyncRun(run *SyncRun) error {
 }
 
 // GetSyncRun retrieves a SyncRun by ID
-func (s *Store) GetSyncRun(id int64) (*SyncRun, error) {
+func (s *SQLiteStore) GetSyncRun(id int64) (*SyncRun, error) {
 	const query = `
 		SELECT id, provider, start_time, end_time, files_downloaded, files_deleted,
-		       files_skipped, files_failed, bytes_transferred, status, error_message
+		       files_skipped, files_failed, bytes_transferred, status, error_message,
+		       COALESCE(signature_status, ''), COALESCE(source_host, ''), COALESCE(upstream_id, 0)
 		FROM sync_runs WHERE id = ?
 	`
 
@@ -128,6 +134,7 @@ func (s *Store) GetSyncRun(id int64) (*SyncRun, error) {
 		&run.ID, &run.Provider, &run.StartTime, &run.EndTime,
 		&run.FilesDownloaded, &run.FilesDeleted, &run.FilesSkipped,
 		&run.FilesFailed, &run.BytesTransferred, &run.Status, &run.ErrorMessage,
+		&run.SignatureStatus, &run.SourceHost, &run.UpstreamID,
 	)
 	if err != nil {
 		if err == sql.ErrNoRows {
@@ -140,10 +147,11 @@ func (s *Store) GetSyncRun(id int64) (*SyncRun, error) {
 }
 
 // ListSyncRuns retrieves SyncRuns, optionally filtered by provider
-func (s *Store) ListSyncRuns(provider string, limit int) ([]SyncRun, error) {
+func (s *SQLiteStore) ListSyncRuns(provider string, limit int) ([]SyncRun, error) {
 	query := `
 		SELECT id, provider, start_time, end_time, files_downloaded, files_deleted,
-		       files_skipped, files_failed, bytes_transferred, status, error_message
+		       files_skipped, files_failed, bytes_transferred, status, error_message,
+		       COALESCE(signature_status, ''), COALESCE(source_host, ''), COALESCE(upstream_id, 0)
 		FROM sync_runs
 	`
 	var args []interface{}
@@ -175,6 +183,7 @@ func (s *Store) ListSyncRuns(provider string, limit int) ([]SyncRun, error) {
 			&run.ID, &run.Provider, &run.StartTime, &run.EndTime,
 			&run.FilesDownloaded, &run.FilesDeleted, &run.FilesSkipped,
 			&run.FilesFailed, &run.BytesTransferred, &run.Status, &run.ErrorMessage,
+			&run.SignatureStatus, &run.SourceHost, &run.UpstreamID,
 		)
 		if err != nil {
 			return nil, fmt.Errorf("failed to scan sync run: %w", err)
@@ -193,12 +202,19 @@ func (s *Store) ListSyncRuns(provider string, limit int) ([]SyncRun, error) {
 // FileRecord Operations
 // ============================================================================
 
+const sqliteFileRecordColumns = `
+	id, provider, path, size, sha256, last_modified, last_verified, sync_run_id,
+	COALESCE(source_url, ''), COALESCE(mirror_host, ''), COALESCE(etag, ''),
+	COALESCE(upstream_last_modified, ''), COALESCE(response_time_ms, 0)
+`
+
 // UpsertFileRecord inserts or replaces a FileRecord
-func (s *Store) UpsertFileRecord(rec *FileRecord) error {
+func (s *SQLiteStore) UpsertFileRecord(rec *FileRecord) error {
 	const query = `
 		INSERT OR REPLACE INTO file_records (
-			id, provider, path, size, sha256, last_modified, last_verified, sync_run_id
-		) VALUES (?, ?, ?, ?, ?, ?, ?, ?)
+			id, provider, path, size, sha256, last_modified, last_verified, sync_run_id,
+			source_url, mirror_host, etag, upstream_last_modified, response_time_ms
+		) VALUES (?, ?, ?, ?, ?, ?, ?, ?, ?, ?, ?, ?, ?)
 	`
 
 	// Pass nil for ID when 0 so SQLite uses AUTOINCREMENT
@@ -211,6 +227,7 @@ func (s *Store) UpsertFileRecord(rec *FileRecord) error {
 		query,
 		idVal, rec.Provider, rec.Path, rec.Size, rec.SHA256,
 		rec.LastModified, rec.LastVerified, rec.SyncRunID,
+		rec.SourceURL, rec.MirrorHost, rec.ETag, rec.UpstreamLastModified, rec.ResponseTimeMS,
 	)
 	if err != nil {
 		return fmt.Errorf("failed to upsert file record: %w", err)
@@ -226,17 +243,11 @@ func (s *Store) UpsertFileRecord(rec *FileRecord) error {
 }
 
 // GetFileRecord retrieves a FileRecord by provider and path
-func (s *Store) GetFileRecord(provider, path string) (*FileRecord, error) {
-	const query = `
-		SELECT id, provider, path, size, sha256, last_modified, last_verified, sync_run_id
-		FROM file_records WHERE provider = ? AND path = ?
-	`
+func (s *SQLiteStore) GetFileRecord(provider, path string) (*FileRecord, error) {
+	const query = `SELECT ` + sqliteFileRecordColumns + ` FROM file_records WHERE provider = ? AND path = ?`
 
 	rec := &FileRecord{}
-	err := s.db.QueryRow(query, provider, path).Scan(
-		&rec.ID, &rec.Provider, &rec.Path, &rec.Size, &rec.SHA256,
-		&rec.LastModified, &rec.LastVerified, &rec.SyncRunID,
-	)
+	err := scanFileRecord(s.db.QueryRow(query, provider, path), rec)
 	if err != nil {
 		if err == sql.ErrNoRows {
 			return nil, fmt.Errorf("file record not found: %s/%s", provider, path)
@@ -248,42 +259,31 @@ func (s *Store) GetFileRecord(provider, path string) (*FileRecord, error) {
 }
 
 // ListFileRecords retrieves all FileRecords for a provider
-func (s *Store) ListFileRecords(provider string) ([]FileRecord, error) {
-	const query = `
-		SELECT id, provider, path, size, sha256, last_modified, last_verified, sync_run_id
-		FROM file_records WHERE provider = ? ORDER BY path
-	`
+func (s *SQLiteStore) ListFileRecords(provider string) ([]FileRecord, error) {
+	const query = `SELECT ` + sqliteFileRecordColumns + ` FROM file_records WHERE provider = ? ORDER BY path`
 
 	rows, err := s.db.Query(query, provider)
 	if err != nil {
 		return nil, fmt.Errorf("failed to query file records: %w", err)
 	}
-	defer func() {
-		_ = rows.Close()
-	}()
+	return collectFileRecords(rows)
+}
 
-	var records []FileRecord
-	for rows.Next() {
-		rec := FileRecord{}
-		err := rows.Scan(
-			&rec.ID, &rec.Provider, &rec.Path, &rec.Size, &rec.SHA256,
-			&rec.LastModified, &rec.LastVerified, &rec.SyncRunID,
-		)
-		if err != nil {
-			return nil, fmt.Errorf("failed to scan file record: %w", err)
-		}
-		records = append(records, rec)
-	}
+// FindFileRecords retrieves the FileRecords whose path, or provider/path,
+// equals path, across all providers
+func (s *SQLiteStore) FindFileRecords(path string) ([]FileRecord, error) {
+	const query = `SELECT ` + sqliteFileRecordColumns + `
+		FROM file_records WHERE path = ? OR provider || '/' || path = ? ORDER BY provider, path`
 
-	if err := rows.Err(); err != nil {
-		return nil, fmt.Errorf("error iterating file records: %w", err)
+	rows, err := s.db.Query(query, path, path)
+	if err != nil {
+		return nil, fmt.Errorf("failed to query file records: %w", err)
 	}
-
-	return records, nil
+	return collectFileRecords(rows)
 }
 
 // DeleteFileRecord deletes a FileRecord by provider and path
-func (s *Store) DeleteFileRecord(provider, path string) error {
+func (s *SQLiteStore) DeleteFileRecord(provider, path string) error {
 	const query = "DELETE FROM file_records WHERE provider = ? AND path = ?"
 
 	result, err := s.db.Exec(query, provider, path)
@@ -304,7 +304,7 @@ func (s *Store) DeleteFileRecord(provider, path string) error {
 }
 
 // CountFileRecords returns the count of FileRecords for a provider
-func (s *Store) CountFileRecords(provider string) (int, error) {
+func (s *SQLiteStore) CountFileRecords(provider string) (int, error) {
 	const query = "SELECT COUNT(*) FROM file_records WHERE provider = ?"
 
 	var count int
@@ -317,7 +317,7 @@ func (s *Store) CountFileRecords(provider string) (int, error) {
 }
 
 // SumFileSize returns the total size of all files for a provider
-func (s *Store) SumFileSize(provider string) (int64, error) {
+func (s *SQLiteStore) SumFileSize(provider string) (int64, error) {
 	const query = "SELECT COALESCE(SUM(size), 0) FROM file_records WHERE provider = ?"
 
 	var totalSize int64
@@ -334,7 +334,7 @@ func (s *Store) SumFileSize(provider string) (int64, error) {
 // ============================================================================
 
 // AddFailedFile adds a new FailedFileRecord
-func (s *Store) AddFailedFile(rec *FailedFileRecord) error {
+func (s *SQLiteStore) AddFailedFile(rec *FailedFileRecord) error {
 	// Update existing unresolved record for the same provider+file, or insert new.
 	const upsertQuery = `
 		UPDATE failed_files
@@ -390,7 +390,7 @@ func (s *Store) AddFailedFile(rec *FailedFileRecord) error {
 }
 
 // ListFailedFiles retrieves all FailedFileRecords for a provider
-func (s *Store) ListFailedFiles(provider string) ([]FailedFileRecord, error) {
+func (s *SQLiteStore) ListFailedFiles(provider string) ([]FailedFileRecord, error) {
 	const query = `
 		SELECT id, provider, file_path, url, dest_path, expected_checksum, expected_size, error,
 		       retry_count, first_failure, last_failure, resolved
@@ -427,7 +427,7 @@ func (s *Store) ListFailedFiles(provider string) ([]FailedFileRecord, error) {
 }
 
 // ResolveFailedFile marks a FailedFileRecord as resolved
-func (s *Store) ResolveFailedFile(id int64) error {
+func (s *SQLiteStore) ResolveFailedFile(id int64) error {
 	const query = "UPDATE failed_files SET resolved = 1 WHERE id = ?"
 
 	result, err := s.db.Exec(query, id)
@@ -448,7 +448,7 @@ func (s *Store) ResolveFailedFile(id int64) error {
 }
 
 // IncrementFailedRetry increments the retry count and updates last_failure
-func (s *Store) IncrementFailedRetry(id int64) error {
+func (s *SQLiteStore) IncrementFailedRetry(id int64) error {
 	const query = `
 		UPDATE failed_files
 		SET retry_count = retry_count + 1, last_failure = CURRENT_TIMESTAMP
@@ -477,7 +477,7 @@ func (s *Store) IncrementFailedRetry(id int64) error {
 // ============================================================================
 
 // CreateJob inserts a new Job and sets its ID
-func (s *Store) CreateJob(job *Job) error {
+func (s *SQLiteStore) CreateJob(job *Job) error {
 	const query = `
 		INSERT INTO jobs (
 			type, provider, cron_expr, status, last_run, next_run, created_at, updated_at
@@ -503,7 +503,7 @@ func (s *Store) CreateJob(job *Job) error {
 }
 
 // UpdateJob updates an existing Job by ID
-func (s *Store) UpdateJob(job *Job) error {
+func (s *SQLiteStore) UpdateJob(job *Job) error {
 	const query = `
 		UPDATE jobs SET
 			type = ?, provider = ?, cron_expr = ?, status = ?,
@@ -533,7 +533,7 @@ func (s *Store) UpdateJob(job *Job) error {
 }
 
 // ListJobs retrieves Jobs, optionally filtered by status
-func (s *Store) ListJobs(status string, limit int) ([]Job, error) {
+func (s *SQLiteStore) ListJobs(status string, limit int) ([]Job, error) {
 	query := `
 		SELECT id, type, provider, cron_expr, status, last_run, next_run, created_at, updated_at
 		FROM jobs
@@ -585,18 +585,18 @@ func (s *Store) ListJobs(status string, limit int) ([]Job, error) {
 // ============================================================================
 
 // CreateTransfer inserts a new Transfer and sets its ID
-func (s *Store) CreateTransfer(t *Transfer) error {
+func (s *SQLiteStore) CreateTransfer(t *Transfer) error {
 	const query = `
 		INSERT INTO transfers (
 			direction, path, providers, archive_count, total_size,
-			manifest_hash, status, error_message, start_time, end_time
-		) VALUES (?, ?, ?, ?, ?, ?, ?, ?, ?, ?)
+			manifest_hash, manifest_json, status, error_message, start_time, end_time
+		) VALUES (?, ?, ?, ?, ?, ?, ?, ?, ?, ?, ?)
 	`
 
 	result, err := s.db.Exec(
 		query,
 		t.Direction, t.Path, t.Providers, t.ArchiveCount, t.TotalSize,
-		t.ManifestHash, t.Status, t.ErrorMessage, t.StartTime, t.EndTime,
+		t.ManifestHash, t.Manifest, t.Status, t.ErrorMessage, t.StartTime, t.EndTime,
 	)
 	if err != nil {
 		return fmt.Errorf("failed to insert transfer: %w", err)
@@ -612,11 +612,11 @@ func (s *Store) CreateTransfer(t *Transfer) error {
 }
 
 // UpdateTransfer updates an existing Transfer by ID
-func (s *Store) UpdateTransfer(t *Transfer) error {
+func (s *SQLiteStore) UpdateTransfer(t *Transfer) error {
 	const query = `
 		UPDATE transfers SET
 			direction = ?, path = ?, providers = ?, archive_count = ?,
-			total_size = ?, manifest_hash = ?, status = ?,
+			total_size = ?, manifest_hash = ?, manifest_json = ?, status = ?,
 			error_message = ?, start_time = ?, end_time = ?
 		WHERE id = ?
 	`
@@ -624,7 +624,7 @@ func (s *Store) UpdateTransfer(t *Transfer) error {
 	result, err := s.db.Exec(
 		query,
 		t.Direction, t.Path, t.Providers, t.ArchiveCount, t.TotalSize,
-		t.ManifestHash, t.Status, t.ErrorMessage, t.StartTime, t.EndTime, t.ID,
+		t.ManifestHash, t.Manifest, t.Status, t.ErrorMessage, t.StartTime, t.EndTime, t.ID,
 	)
 	if err != nil {
 		return fmt.Errorf("failed to update transfer: %w", err)
@@ -647,7 +647,7 @@ func (s *Store) UpdateTransfer(t *Transfer) error {
 // ============================================================================
 
 // CreateTransferArchive inserts a new TransferArchive and sets its ID
-func (s *Store) CreateTransferArchive(a *TransferArchive) error {
+func (s *SQLiteStore) CreateTransferArchive(a *TransferArchive) error {
 	const query = `
 		INSERT INTO transfer_archives (
 			transfer_id, archive_name, sha256, size, validated, validated_at
@@ -673,7 +673,7 @@ func (s *Store) CreateTransferArchive(a *TransferArchive) error {
 }
 
 // MarkArchiveValidated marks a TransferArchive as validated
-func (s *Store) MarkArchiveValidated(id int64) error {
+func (s *SQLiteStore) MarkArchiveValidated(id int64) error {
 	const query = `
 		UPDATE transfer_archives
 		SET validated = 1, validated_at = CURRENT_TIMESTAMP
@@ -698,7 +698,7 @@ func (s *Store) MarkArchiveValidated(id int64) error {
 }
 
 // ListTransferArchives retrieves all archives for a transfer
-func (s *Store) ListTransferArchives(transferID int64) ([]TransferArchive, error) {
+func (s *SQLiteStore) ListTransferArchives(transferID int64) ([]TransferArchive, error) {
 	const query = `
 		SELECT id, transfer_id, archive_name, sha256, size, validated, validated_at
 		FROM transfer_archives WHERE transfer_id = ? ORDER BY archive_name
@@ -734,7 +734,7 @@ func (s *Store) ListTransferArchives(transferID int64) ([]TransferArchive, error
 
 // IsArchiveValidated checks whether an archive with the given path, name, and sha256
 // has been previously validated in a completed transfer.
-func (s *Store) IsArchiveValidated(path, archiveName, sha256 string) (bool, error) {
+func (s *SQLiteStore) IsArchiveValidated(path, archiveName, sha256 string) (bool, error) {
 	const query = `
 		SELECT COUNT(*) FROM transfer_archives ta
 		JOIN transfers t ON ta.transfer_id = t.id
@@ -749,8 +749,31 @@ func (s *Store) IsArchiveValidated(path, archiveName, sha256 string) (bool, erro
 	return count > 0, nil
 }
 
+// GetTransfer retrieves a Transfer with its manifest by ID
+func (s *SQLiteStore) GetTransfer(id int64) (*Transfer, error) {
+	const query = `
+		SELECT id, direction, path, providers, archive_count, total_size,
+		       manifest_hash, manifest_json, status, error_message, start_time, end_time
+		FROM transfers WHERE id = ?
+	`
+
+	t := &Transfer{}
+	err := s.db.QueryRow(query, id).Scan(
+		&t.ID, &t.Direction, &t.Path, &t.Providers, &t.ArchiveCount,
+		&t.TotalSize, &t.ManifestHash, &t.Manifest, &t.Status, &t.ErrorMessage,
+		&t.StartTime, &t.EndTime,
+	)
+	if err == sql.ErrNoRows {
+		return nil, fmt.Errorf("transfer not found: %d", id)
+	}
+	if err != nil {
+		return nil, fmt.Errorf("failed to query transfer: %w", err)
+	}
+	return t, nil
+}
+
 // ListTransfers retrieves Transfers, optionally limited
-func (s *Store) ListTransfers(limit int) ([]Transfer, error) {
+func (s *SQLiteStore) ListTransfers(limit int) ([]Transfer, error) {
 	query := `
 		SELECT id, direction, path, providers, archive_count, total_size,
 		       manifest_hash, status, error_message, start_time, end_time
@@ -803,7 +826,7 @@ func (s *Store) ListTransfers(limit int) ([]Transfer, error) {
 // ============================================================================
 
 // CreateProviderConfig inserts a new ProviderConfig and sets its ID.
-func (s *Store) CreateProviderConfig(pc *ProviderConfig) error {
+func (s *SQLiteStore) CreateProviderConfig(pc *ProviderConfig) error {
 	const query = `
 		INSERT INTO provider_configs (name, type, enabled, config_json)
 		VALUES (?, ?, ?, ?)
@@ -821,7 +844,7 @@ func (s *Store) CreateProviderConfig(pc *ProviderConfig) error {
 }
 
 // GetProviderConfig retrieves a ProviderConfig by name.
-func (s *Store) GetProviderConfig(name string) (*ProviderConfig, error) {
+func (s *SQLiteStore) GetProviderConfig(name string) (*ProviderConfig, error) {
 	const query = `
 		SELECT id, name, type, enabled, config_json, created_at, updated_at
 		FROM provider_configs WHERE name = ?
@@ -838,7 +861,7 @@ func (s *Store) GetProviderConfig(name string) (*ProviderConfig, error) {
 }
 
 // ListProviderConfigs retrieves all ProviderConfigs ordered by name.
-func (s *Store) ListProviderConfigs() ([]ProviderConfig, error) {
+func (s *SQLiteStore) ListProviderConfigs() ([]ProviderConfig, error) {
 	const query = `
 		SELECT id, name, type, enabled, config_json, created_at, updated_at
 		FROM provider_configs ORDER BY name
@@ -864,7 +887,7 @@ func (s *Store) ListProviderConfigs() ([]ProviderConfig, error) {
 }
 
 // UpdateProviderConfig updates an existing ProviderConfig by ID.
-func (s *Store) UpdateProviderConfig(pc *ProviderConfig) error {
+func (s *SQLiteStore) UpdateProviderConfig(pc *ProviderConfig) error {
 	const query = `
 		UPDATE provider_configs SET
 			name = ?, type = ?, enabled = ?, config_json = ?, updated_at = CURRENT_TIMESTAMP
@@ -885,7 +908,7 @@ func (s *Store) UpdateProviderConfig(pc *ProviderConfig) error {
 }
 
 // DeleteProviderConfig deletes a ProviderConfig by name.
-func (s *Store) DeleteProviderConfig(name string) error {
+func (s *SQLiteStore) DeleteProviderConfig(name string) error {
 	const query = `DELETE FROM provider_configs WHERE name = ?`
 	result, err := s.db.Exec(query, name)
 	if err != nil {
@@ -902,7 +925,7 @@ func (s *Store) DeleteProviderConfig(name string) error {
 }
 
 // ToggleProviderConfig flips the enabled state of a provider.
-func (s *Store) ToggleProviderConfig(name string) error {
+func (s *SQLiteStore) ToggleProviderConfig(name string) error {
 	const query = `
 		UPDATE provider_configs
 		SET enabled = CASE WHEN enabled = 1 THEN 0 ELSE 1 END,
@@ -924,7 +947,7 @@ func (s *Store) ToggleProviderConfig(name string) error {
 }
 
 // CountProviderConfigs returns the number of provider configs.
-func (s *Store) CountProviderConfigs() (int, error) {
+func (s *SQLiteStore) CountProviderConfigs() (int, error) {
 	var count int
 	err := s.db.QueryRow("SELECT COUNT(*) FROM provider_configs").Scan(&count)
 	if err != nil {
@@ -935,7 +958,7 @@ func (s *Store) CountProviderConfigs() (int, error) {
 
 // SeedProviderConfigs populates provider_configs from a YAML providers map.
 // This is a no-op if the table already has rows.
-func (s *Store) SeedProviderConfigs(yamlProviders map[string]map[string]interface{}) error {
+func (s *SQLiteStore) SeedProviderConfigs(yamlProviders map[string]map[string]interface{}) error {
 	count, err := s.CountProviderConfigs()
 	if err != nil {
 		return err
@@ -945,17 +968,7 @@ func (s *Store) SeedProviderConfigs(yamlProviders map[string]map[string]interfac
 		return nil
 	}
 
-	knownTypes := map[string]bool{
-		"epel": true, "ocp_binaries": true, "ocp_clients": true, "rhcos": true,
-		"container_images": true, "registry": true, "custom_files": true,
-	}
-
 	for name, rawCfg := range yamlProviders {
-		provType := name
-		if !knownTypes[provType] {
-			provType = "custom_files"
-		}
-
 		enabled := false
 		if e, ok := rawCfg["enabled"].(bool); ok {
 			enabled = e
@@ -969,15 +982,30 @@ func (s *Store) SeedProviderConfigs(yamlProviders map[string]map[string]interfac
 
 		pc := &ProviderConfig{
 			Name:       name,
-			Type:       provType,
+			Type:       seedProviderType(name),
 			Enabled:    enabled,
 			ConfigJSON: string(configJSON),
 		}
 		if err := s.CreateProviderConfig(pc); err != nil {
 			s.logger.Warn("failed to seed provider config", "name", name, "error", err)
+			continue
+		}
+		if _, err := s.AddProviderConfigRevision(name, "system", "seeded from config file"); err != nil {
+			s.logger.Warn("failed to record seeded provider config revision", "name", name, "error", err)
 		}
 	}
 
 	s.logger.Info("seeded provider configs from YAML", "count", len(yamlProviders))
 	return nil
 }
+
+// seedProviderType maps a YAML provider name to its provider type; names
+// that are not a known type are seeded as custom_files.
+func seedProviderType(name string) string {
+	switch name {
+	case "epel", "ocp_binaries", "ocp_clients", "rhcos",
+		"container_images", "operator_catalog", "registry", "custom_files":
+		return name
+	}
+	return "custom_files"
+}
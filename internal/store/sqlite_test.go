@@ -1,14 +1,17 @@
 package store
 
 import (
+	"fmt"
 	"log/slog"
 	"os"
+	"path/filepath"
+	"sync"
 	"testing"
 	"time"
 )
 
 // newTestStore creates an in-memory SQLite store for testing
-func newTestStore(t *testing.T) *Store {
+func newTestStore(t *testing.T) *SQLiteStore {
 	t.Helper()
 	s, err := New(":memory:", slog.New(slog.NewTextHandler(os.Stderr, nil)))
 	if err != nil {
@@ -160,6 +163,7 @@ func TestUpdateSyncRun(t *testing.T) {
 	run.FilesDownloaded = 10
 	run.FilesFailed = 3
 	run.ErrorMessage = "Some files failed"
+	run.SignatureStatus = "verified"
 
 	err = store.UpdateSyncRun(run)
 	if err != nil {
@@ -187,6 +191,10 @@ func TestUpdateSyncRun(t *testing.T) {
 	if retrieved.ErrorMessage != "Some files failed" {
 		t.Errorf("ErrorMessage not updated: got %q, want %q", retrieved.ErrorMessage, "Some files failed")
 	}
+
+	if retrieved.SignatureStatus != "verified" {
+		t.Errorf("SignatureStatus not updated: got %q, want %q", retrieved.SignatureStatus, "verified")
+	}
 }
 
 func TestUpdateSyncRunNotFound(t *testing.T) {
@@ -1848,3 +1856,44 @@ func TestSeedProviderConfigs(t *testing.T) {
 		t.Fatalf("expected 2 configs after no-op seed, got %d", len(configs))
 	}
 }
+
+func TestAppendAuditEventAcrossStores(t *testing.T) {
+	// Two stores on one file stand in for airgap serve and a CLI command.
+	path := filepath.Join(t.TempDir(), "airgap.db")
+	stores := []*SQLiteStore{newFileStore(t, path), newFileStore(t, path)}
+
+	const perStore = 25
+	var wg sync.WaitGroup
+	errs := make(chan error, len(stores)*perStore)
+	for i, s := range stores {
+		wg.Add(1)
+		go func(i int, s *SQLiteStore) {
+			defer wg.Done()
+			for n := 0; n < perStore; n++ {
+				ev := &AuditEvent{Actor: fmt.Sprintf("store-%d", i), Action: "config.set", Target: fmt.Sprint(n), Outcome: "success"}
+				if err := s.AppendAuditEvent(ev, true); err != nil {
+					errs <- err
+				}
+			}
+		}(i, s)
+	}
+	wg.Wait()
+	close(errs)
+	for err := range errs {
+		t.Errorf("AppendAuditEvent() failed: %v", err)
+	}
+
+	events, err := stores[0].ListAuditEvents(AuditFilter{})
+	if err != nil {
+		t.Fatalf("ListAuditEvents() failed: %v", err)
+	}
+	if len(events) != len(stores)*perStore {
+		t.Fatalf("stored %d events, want %d", len(events), len(stores)*perStore)
+	}
+	// Events are newest first; each must chain to the one stored before it.
+	for i := 0; i < len(events)-1; i++ {
+		if events[i].PrevHash != events[i+1].Hash {
+			t.Fatalf("event %d chains to %q, want hash of event %d %q", events[i].ID, events[i].PrevHash, events[i+1].ID, events[i+1].Hash)
+		}
+	}
+}
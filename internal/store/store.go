@@ -0,0 +1,153 @@
+package store
+
+import (
+	"context"
+	"database/sql"
+	"fmt"
+	"log/slog"
+	"time"
+)
+
+// Store is the persistence layer used by the engine, server and CLI.
+// SQLiteStore is the default implementation; PostgresStore serves central
+// instances with an HA database.
+type Store interface {
+	Close() error
+
+	// Sync runs
+	CreateSyncRun(run *SyncRun) error
+	UpdateSyncRun(run *SyncRun) error
+	GetSyncRun(id int64) (*SyncRun, error)
+	ListSyncRuns(provider string, limit int) ([]SyncRun, error)
+	ImportSyncRun(run *SyncRun) error
+
+	// File records
+	UpsertFileRecord(rec *FileRecord) error
+	GetFileRecord(provider, path string) (*FileRecord, error)
+	ListFileRecords(provider string) ([]FileRecord, error)
+	FindFileRecords(path string) ([]FileRecord, error)
+	DeleteFileRecord(provider, path string) error
+	CountFileRecords(provider string) (int, error)
+	SumFileSize(provider string) (int64, error)
+
+	// Failed files (dead letter queue)
+	AddFailedFile(rec *FailedFileRecord) error
+	ListFailedFiles(provider string) ([]FailedFileRecord, error)
+	ResolveFailedFile(id int64) error
+	IncrementFailedRetry(id int64) error
+
+	// Jobs
+	CreateJob(job *Job) error
+	UpdateJob(job *Job) error
+	ListJobs(status string, limit int) ([]Job, error)
+
+	// Transfers and their archives
+	CreateTransfer(t *Transfer) error
+	UpdateTransfer(t *Transfer) error
+	ListTransfers(limit int) ([]Transfer, error)
+	GetTransfer(id int64) (*Transfer, error)
+	CreateTransferArchive(a *TransferArchive) error
+	MarkArchiveValidated(id int64) error
+	ListTransferArchives(transferID int64) ([]TransferArchive, error)
+	IsArchiveValidated(path, archiveName, sha256 string) (bool, error)
+
+	// Provider configs
+	CreateProviderConfig(pc *ProviderConfig) error
+	GetProviderConfig(name string) (*ProviderConfig, error)
+	ListProviderConfigs() ([]ProviderConfig, error)
+	UpdateProviderConfig(pc *ProviderConfig) error
+	DeleteProviderConfig(name string) error
+	ToggleProviderConfig(name string) error
+	CountProviderConfigs() (int, error)
+	SeedProviderConfigs(yamlProviders map[string]map[string]interface{}) error
+	UpsertUpstreamProviderConfig(pc *UpstreamProviderConfig) error
+	GetUpstreamProviderConfig(sourceHost, name string) (*UpstreamProviderConfig, error)
+
+	// Provider config revisions
+	AddProviderConfigRevision(name, author, comment string) (*ProviderConfigRevision, error)
+	ListProviderConfigRevisions(name string) ([]ProviderConfigRevision, error)
+	GetProviderConfigRevision(name string, revision int) (*ProviderConfigRevision, error)
+
+	// Search index
+	ReplaceSearchEntries(provider string, entries []SearchEntry) error
+	Search(q SearchQuery) ([]SearchEntry, error)
+
+	// Audit log
+	AppendAuditEvent(ev *AuditEvent, chain bool) error
+	ListAuditEvents(f AuditFilter) ([]AuditEvent, error)
+
+	// Maintenance
+	Prune(policy RetentionPolicy, now time.Time) (*PruneResult, error)
+	Size() (int64, error)
+	Optimize() error
+}
+
+// Backuper is implemented by stores that can copy themselves to and from a
+// file. PostgreSQL databases are backed up with pg_dump instead.
+type Backuper interface {
+	Backup(ctx context.Context, destPath string) error
+	Restore(ctx context.Context, srcPath string) error
+	DiscardedAuditEvents(ctx context.Context, srcPath string) (int, error)
+}
+
+// Supported database drivers.
+const (
+	DriverSQLite   = "sqlite"
+	DriverPostgres = "postgres"
+)
+
+// Open opens the store for driver. dsn is the database file path for SQLite
+// and a connection string for PostgreSQL.
+func Open(driver, dsn string, logger *slog.Logger) (Store, error) {
+	switch driver {
+	case "", DriverSQLite:
+		s, err := New(dsn, logger)
+		if err != nil {
+			return nil, err
+		}
+		return s, nil
+	case DriverPostgres:
+		s, err := NewPostgres(dsn, logger)
+		if err != nil {
+			return nil, err
+		}
+		return s, nil
+	default:
+		return nil, fmt.Errorf("unsupported database driver %q (supported: %s, %s)", driver, DriverSQLite, DriverPostgres)
+	}
+}
+
+// rowScanner is satisfied by *sql.Row and *sql.Rows.
+type rowScanner interface {
+	Scan(dest ...interface{}) error
+}
+
+// scanFileRecord scans file record columns in the order both backends
+// select them.
+func scanFileRecord(row rowScanner, rec *FileRecord) error {
+	return row.Scan(
+		&rec.ID, &rec.Provider, &rec.Path, &rec.Size, &rec.SHA256,
+		&rec.LastModified, &rec.LastVerified, &rec.SyncRunID,
+		&rec.SourceURL, &rec.MirrorHost, &rec.ETag, &rec.UpstreamLastModified, &rec.ResponseTimeMS,
+	)
+}
+
+// collectFileRecords scans and closes rows of file record columns.
+func collectFileRecords(rows *sql.Rows) ([]FileRecord, error) {
+	defer func() {
+		_ = rows.Close()
+	}()
+
+	var records []FileRecord
+	for rows.Next() {
+		rec := FileRecord{}
+		if err := scanFileRecord(rows, &rec); err != nil {
+			return nil, fmt.Errorf("failed to scan file record: %w", err)
+		}
+		records = append(records, rec)
+	}
+	if err := rows.Err(); err != nil {
+		return nil, fmt.Errorf("error iterating file records: %w", err)
+	}
+	return records, nil
+}
@@ -0,0 +1,77 @@
+package store
+
+import (
+	"database/sql"
+	"fmt"
+)
+
+// ImportSyncRun records a sync run from run.SourceHost, keyed by its
+// UpstreamID, and sets run.ID to the local row. Importing the same run again
+// updates it in place.
+func (s *SQLiteStore) ImportSyncRun(run *SyncRun) error {
+	if run.SourceHost == "" || run.UpstreamID == 0 {
+		return fmt.Errorf("imported sync run needs a source host and upstream id")
+	}
+
+	const query = `
+		INSERT INTO sync_runs (
+			provider, start_time, end_time, files_downloaded, files_deleted,
+			files_skipped, files_failed, bytes_transferred, status, error_message,
+			signature_status, source_host, upstream_id
+		) VALUES (?, ?, ?, ?, ?, ?, ?, ?, ?, ?, ?, ?, ?)
+		ON CONFLICT (source_host, upstream_id) WHERE source_host != '' DO UPDATE SET
+			provider = excluded.provider, start_time = excluded.start_time,
+			end_time = excluded.end_time, files_downloaded = excluded.files_downloaded,
+			files_deleted = excluded.files_deleted, files_skipped = excluded.files_skipped,
+			files_failed = excluded.files_failed, bytes_transferred = excluded.bytes_transferred,
+			status = excluded.status, error_message = excluded.error_message,
+			signature_status = excluded.signature_status
+		RETURNING id
+	`
+
+	err := s.db.QueryRow(
+		query,
+		run.Provider, run.StartTime, run.EndTime, run.FilesDownloaded,
+		run.FilesDeleted, run.FilesSkipped, run.FilesFailed,
+		run.BytesTransferred, run.Status, run.ErrorMessage, run.SignatureStatus,
+		run.SourceHost, run.UpstreamID,
+	).Scan(&run.ID)
+	if err != nil {
+		return fmt.Errorf("failed to import sync run: %w", err)
+	}
+	return nil
+}
+
+// UpsertUpstreamProviderConfig inserts or replaces the configuration of
+// provider pc.Name on pc.SourceHost.
+func (s *SQLiteStore) UpsertUpstreamProviderConfig(pc *UpstreamProviderConfig) error {
+	const query = `
+		INSERT OR REPLACE INTO upstream_provider_configs (
+			source_host, name, type, config_json, imported_at
+		) VALUES (?, ?, ?, ?, ?)
+	`
+	if _, err := s.db.Exec(query, pc.SourceHost, pc.Name, pc.Type, pc.ConfigJSON, pc.ImportedAt); err != nil {
+		return fmt.Errorf("failed to upsert upstream provider config: %w", err)
+	}
+	return nil
+}
+
+// GetUpstreamProviderConfig retrieves the configuration of provider name as
+// last imported from sourceHost.
+func (s *SQLiteStore) GetUpstreamProviderConfig(sourceHost, name string) (*UpstreamProviderConfig, error) {
+	const query = `
+		SELECT source_host, name, type, config_json, imported_at
+		FROM upstream_provider_configs WHERE source_host = ? AND name = ?
+	`
+	pc := &UpstreamProviderConfig{}
+	err := s.db.QueryRow(query, sourceHost, name).Scan(
+		&pc.SourceHost, &pc.Name, &pc.Type, &pc.ConfigJSON, &pc.ImportedAt,
+	)
+	if err != nil {
+		if err == sql.ErrNoRows {
+			return nil, fmt.Errorf("upstream provider config not found: %s/%s", sourceHost, name)
+		}
+		return nil, fmt.Errorf("failed to query upstream provider config: %w", err)
+	}
+	return pc, nil
+}